@@ -7,6 +7,7 @@ import (
 
 	"api/internal/config"
 	"api/internal/handler"
+	"api/internal/logic/training"
 	"api/internal/svc"
 	"api/pkg/docs"
 
@@ -48,6 +49,13 @@ func main() {
 	ctx := svc.NewServiceContext(c)
 	handler.RegisterHandlers(server, ctx)
 
+	// 超参数搜索等后台任务通过常规流程创建训练作业
+	ctx.RegisterJobCreator(training.NewJobCreator(ctx))
+
+	// 启动后台任务（作业派发等），后台任务只在api服务中运行
+	ctx.StartWorkers()
+	defer ctx.StopWorkers()
+
 	// 注册Swagger文档
 	docs.RegisterSwaggerHandler(server)
 
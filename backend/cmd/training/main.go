@@ -26,6 +26,8 @@ func main() {
 	ctx := svc.NewServiceContext(c)
 	handler.RegisterHandlers(server, ctx)
 
+	// 后台任务（作业派发、状态同步、超参数搜索、定时触发等）只由api服务启动，
+	// 避免多个进程重复运行同一组后台循环
 	fmt.Printf("Starting server at %s:%d...\n", c.Host, c.Port)
 	server.Start()
 }
@@ -12,6 +12,8 @@ type Config struct {
 	Security     SecurityConfig     `json:",optional"`
 	Storage      StorageConfig      `json:",optional"`
 	K8s          K8sConfig          `json:",optional"`
+	Training     TrainingConfig     `json:",optional"`
+	Gpu          GpuConfig          `json:",optional"`
 	Notification NotificationConfig `json:",optional"`
 }
 
@@ -82,6 +84,20 @@ type StorageConfig struct {
 	ModelPath      string `json:",default=/data/models"`
 	LogsPath       string `json:",default=/data/logs"`
 	CheckpointPath string `json:",default=/data/checkpoints"`
+
+	CheckpointBackend string   `json:",default=filesystem,options=filesystem|s3"` // 检查点存储，filesystem使用CheckpointPath
+	CheckpointS3      S3Config `json:",optional"`                                 // CheckpointBackend为s3时使用的对象存储
+	CheckpointPVC     string   `json:",optional"`                                 // 文件系统检查点存储所在的PVC，恢复训练时挂载到训练容器的CheckpointPath
+}
+
+// S3兼容对象存储配置
+type S3Config struct {
+	Endpoint  string `json:",optional"` // 服务地址，如 http://minio.volctrain:9000
+	Region    string `json:",default=us-east-1"`
+	Bucket    string `json:",optional"`
+	Prefix    string `json:",optional"` // 桶内路径前缀
+	AccessKey string `json:",optional"`
+	SecretKey string `json:",optional"`
 }
 
 // K8s配置
@@ -91,6 +107,78 @@ type K8sConfig struct {
 	EnablePodMonitoring bool   `json:",default=true"`
 }
 
+// 训练作业调度配置
+type TrainingConfig struct {
+	EnableDispatcher  bool `json:",default=true"`
+	DispatchInterval  int  `json:",default=5"`   // 派发轮询间隔(秒)
+	DispatchBatchSize int  `json:",default=20"`  // 每轮最多派发作业数
+	MaxSubmitAttempts int  `json:",default=10"`  // 瞬时错误最大重试次数，0表示不限制
+	SubmitBackoffBase int  `json:",default=2"`   // 重试退避基础时长(秒)
+	SubmitBackoffMax  int  `json:",default=300"` // 重试退避最大时长(秒)
+	EnableReconciler  bool `json:",default=true"`
+	ReconcileInterval int  `json:",default=60"`   // 状态全量同步间隔(秒)
+	RetryInterval     int  `json:",default=10"`   // 自动重试轮询间隔(秒)
+	RetryBackoffBase  int  `json:",default=30"`   // 自动重试退避基础时长(秒)
+	RetryBackoffMax   int  `json:",default=1800"` // 自动重试退避最大时长(秒)
+
+	EnableWatchdog         bool     `json:",default=true"`
+	WatchdogInterval       int      `json:",default=30"`  // 运行时长和空闲检查间隔(秒)
+	WatchdogWarnBefore     int      `json:",default=300"` // 终止前预警提前量(秒)
+	IdleGpuThreshold       float64  `json:",default=5"`   // GPU使用率低于该值(百分比)视为空闲，使用率由Gpu.EnableTelemetry采集
+	WatchdogNotifyChannels []string `json:",optional"`    // 默认预警通知渠道
+
+	EnableSweeps  bool `json:",default=true"`
+	SweepInterval int  `json:",default=15"` // 超参数搜索调度间隔(秒)
+
+	EnableTriggers  bool `json:",default=true"`
+	TriggerInterval int  `json:",default=30"` // 定时触发器检查间隔(秒)
+
+	EnableLogArchiver    bool `json:",default=true"`
+	LogArchiveInterval   int  `json:",default=60"`   // 日志归档间隔(秒)
+	LogArchiveChunkLines int  `json:",default=5000"` // 每个归档分片的最大行数
+	LogArchiveGrace      int  `json:",default=600"`  // 作业结束后继续归档的时长(秒)，用于读取结束前最后的日志
+
+	EnableTfeventsImport   bool `json:",default=true"`
+	TfeventsImportInterval int  `json:",default=30"` // TensorBoard事件文件导入间隔(秒)
+
+	EnableTensorboard   bool   `json:",default=true"`
+	TensorboardImage    string `json:",default=tensorflow/tensorflow:2.15.0"` // TensorBoard镜像
+	TensorboardInterval int    `json:",default=30"`                           // TensorBoard创建和回收检查间隔(秒)
+	TensorboardTTL      int    `json:",default=3600"`                         // 作业结束后TensorBoard保留的时长(秒)
+
+	EnableCheckpointGC   bool `json:",default=true"`
+	CheckpointGCInterval int  `json:",default=3600"`  // 检查点垃圾回收间隔(秒)
+	CheckpointGCDryRun   bool `json:",default=false"` // 定期回收只统计不删除
+
+	EnableEarlyStopping  bool `json:",default=true"`
+	EarlyStopInterval    int  `json:",default=60"`  // 提前停止检查间隔(秒)
+	EarlyStopGracePeriod int  `json:",default=600"` // 请求提前停止后等待保存最终检查点的时长(秒)
+
+	ResumeDownloaderImage string `json:",default=busybox:1.36"` // 从对象存储下载恢复用检查点的初始化容器镜像
+	ResumeURLExpires      int    `json:",default=86400"`        // 检查点下载地址的有效期(秒)，最长7天
+
+	MetricsEndpoint     string `json:",optional"`     // 训练容器访问指标上报接口的服务地址，如 http://volctrain-api.volctrain:8888
+	MetricsTokenSecret  string `json:",optional"`     // 生成作业指标上报令牌的密钥，为空时使用Auth.AccessSecret
+	MaxMetricsPerPush   int    `json:",default=5000"` // 单次上报的最大指标数
+	MetricsSeriesPoints int    `json:",default=1000"` // 指标曲线降采样后的最大点数
+}
+
+// GPU资源管理配置
+type GpuConfig struct {
+	EnableInventorySync   bool `json:",default=true"`
+	InventorySyncInterval int  `json:",default=300"` // 从K8s节点同步GPU清单的间隔(秒)
+	LeaseCheckInterval    int  `json:",default=60"`  // 检查GPU分配租约到期的间隔(秒)
+
+	// DCGM监控数据采集，DCGMExporterURLs和TelemetryPrometheusURL二选一，优先直接抓取exporter
+	EnableTelemetry        bool     `json:",default=false"`
+	TelemetryInterval      int      `json:",default=30"` // 采集间隔(秒)
+	DCGMExporterURLs       []string `json:",optional"`   // 各节点DCGM exporter的指标地址，如 http://10.0.0.1:9400/metrics
+	TelemetryPrometheusURL string   `json:",optional"`   // 已抓取DCGM exporter的Prometheus地址
+
+	// 检测到GPU故障时自动隔离节点并驱逐训练作业，需开启EnableTelemetry
+	AutoCordon bool `json:",default=true"`
+}
+
 // 通知配置
 type NotificationConfig struct {
 	Enabled  bool           `json:",default=false"`
@@ -0,0 +1,28 @@
+package gpu_cluster
+
+import (
+	"net/http"
+
+	"api/internal/logic/gpu_cluster"
+	"api/internal/svc"
+	"api/internal/types"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+func SyncGpuClusterHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.SyncGpuClusterReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := gpu_cluster.NewSyncGpuClusterLogic(r.Context(), svcCtx)
+		resp, err := l.SyncGpuCluster(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package gpu_node
+
+import (
+	"net/http"
+
+	"api/internal/logic/gpu_node"
+	"api/internal/svc"
+	"api/internal/types"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+func UncordonGpuNodeHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.UncordonGpuNodeReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := gpu_node.NewUncordonGpuNodeLogic(r.Context(), svcCtx)
+		resp, err := l.UncordonGpuNode(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
@@ -21,6 +21,8 @@ import (
 func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 	// 初始化自定义JWT认证中间件
 	jwtAuthMiddleware := middleware.NewJWTAuthMiddleware(serverCtx.JWTService, serverCtx.TokenBlacklist)
+	// 训练容器上报指标使用作业令牌认证，由接口自行校验
+	jwtAuthMiddleware.AddSkipPath("/api/v1/ingest/")
 
 	// 注册全局中间件
 	server.Use(middleware.ErrorHandlerMiddleware)
@@ -134,11 +136,21 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/:id/suspend",
 				Handler: training.SuspendTrainingJobHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/:id/clone",
+				Handler: training.CloneTrainingJobHandler(serverCtx),
+			},
 			{
 				Method:  http.MethodGet,
 				Path:    "/:jobId/checkpoints",
 				Handler: training.GetJobCheckpointsHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/:jobId/checkpoints",
+				Handler: training.CreateCheckpointHandler(serverCtx),
+			},
 			{
 				Method:  http.MethodGet,
 				Path:    "/:jobId/instances",
@@ -154,11 +166,31 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/:jobId/metrics",
 				Handler: training.GetJobMetricsHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/:jobId/metrics",
+				Handler: training.CreateJobMetricHandler(serverCtx),
+			},
 			{
 				Method:  http.MethodGet,
 				Path:    "/:jobId/relations",
 				Handler: training.GetJobRelationsHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/:jobId/relations",
+				Handler: training.CreateJobRelationHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/:jobId/pipeline",
+				Handler: training.GetJobPipelineHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/:jobId/pipeline/rerun",
+				Handler: training.RerunPipelineNodeHandler(serverCtx),
+			},
 			{
 				Method:  http.MethodGet,
 				Path:    "/options",
@@ -168,6 +200,183 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 		rest.WithPrefix("/api/v1/training/jobs"),
 	)
 
+	// 检查点路由（需要认证）
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodGet,
+				Path:    "/:id",
+				Handler: training.GetCheckpointHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPut,
+				Path:    "/:id",
+				Handler: training.UpdateCheckpointHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodDelete,
+				Path:    "/:id",
+				Handler: training.DeleteCheckpointHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/gc",
+				Handler: training.RunCheckpointGCHandler(serverCtx),
+			},
+		},
+		rest.WithPrefix("/api/v1/training/checkpoints"),
+	)
+
+	// 检查点保留策略路由（需要认证）
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodPost,
+				Path:    "/",
+				Handler: training.CreateCheckpointRetentionPolicyHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/",
+				Handler: training.ListCheckpointRetentionPoliciesHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/:id",
+				Handler: training.GetCheckpointRetentionPolicyHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPut,
+				Path:    "/:id",
+				Handler: training.UpdateCheckpointRetentionPolicyHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodDelete,
+				Path:    "/:id",
+				Handler: training.DeleteCheckpointRetentionPolicyHandler(serverCtx),
+			},
+		},
+		rest.WithPrefix("/api/v1/training/checkpoint-policies"),
+	)
+
+	// 作业关联关系路由（需要认证）
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodDelete,
+				Path:    "/:id",
+				Handler: training.DeleteJobRelationHandler(serverCtx),
+			},
+		},
+		rest.WithPrefix("/api/v1/training/relations"),
+	)
+
+	// 训练作业模板路由（需要认证）
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodPost,
+				Path:    "/",
+				Handler: training.CreateTrainingTemplateHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/",
+				Handler: training.ListTrainingTemplatesHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/:id",
+				Handler: training.GetTrainingTemplateHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPut,
+				Path:    "/:id",
+				Handler: training.UpdateTrainingTemplateHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodDelete,
+				Path:    "/:id",
+				Handler: training.DeleteTrainingTemplateHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/:id/instantiate",
+				Handler: training.InstantiateTrainingTemplateHandler(serverCtx),
+			},
+		},
+		rest.WithPrefix("/api/v1/training/templates"),
+	)
+
+	// 超参数搜索路由（需要认证）
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodPost,
+				Path:    "/",
+				Handler: training.CreateTrainingSweepHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/",
+				Handler: training.ListTrainingSweepsHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/:id",
+				Handler: training.GetTrainingSweepHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/:id/cancel",
+				Handler: training.CancelTrainingSweepHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/:id/leaderboard",
+				Handler: training.GetSweepLeaderboardHandler(serverCtx),
+			},
+		},
+		rest.WithPrefix("/api/v1/training/sweeps"),
+	)
+
+	// 定时触发器路由（需要认证）
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodPost,
+				Path:    "/",
+				Handler: training.CreateTrainingTriggerHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/",
+				Handler: training.ListTrainingTriggersHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/:id",
+				Handler: training.GetTrainingTriggerHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPut,
+				Path:    "/:id",
+				Handler: training.UpdateTrainingTriggerHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodDelete,
+				Path:    "/:id",
+				Handler: training.DeleteTrainingTriggerHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/:id/runs",
+				Handler: training.GetTriggerRunsHandler(serverCtx),
+			},
+		},
+		rest.WithPrefix("/api/v1/training/triggers"),
+	)
+
 	// 训练队列路由（需要认证）
 	server.AddRoutes(
 		[]rest.Route{
@@ -205,6 +414,49 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 		rest.WithPrefix("/api/v1/training/queues"),
 	)
 
+	// 训练日志检索路由（需要认证）
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodGet,
+				Path:    "/search",
+				Handler: training.SearchTrainingLogsHandler(serverCtx),
+			},
+		},
+		rest.WithPrefix("/api/v1/training/logs"),
+	)
+
+	// 归档日志下载路由（需要认证），响应体较大，单独放宽请求超时
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodGet,
+				Path:    "/:jobId/logs/archive",
+				Handler: training.DownloadJobLogsHandler(serverCtx),
+			},
+		},
+		rest.WithPrefix("/api/v1/training/jobs"),
+		rest.WithTimeout(600*time.Second),
+	)
+
+	// 作业TensorBoard代理路由（需要认证）
+	server.AddRoutes(
+		tensorboardRoutes(serverCtx),
+		rest.WithPrefix("/api/v1/training/jobs"),
+	)
+
+	// 指标上报路由（作业令牌认证）
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodPost,
+				Path:    "/jobs/:jobId/metrics",
+				Handler: training.PushJobMetricsHandler(serverCtx),
+			},
+		},
+		rest.WithPrefix("/api/v1/ingest"),
+	)
+
 	// GPU集群路由（需要认证）
 	server.AddRoutes(
 		[]rest.Route{
@@ -248,6 +500,11 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/nodes",
 				Handler: gpu_cluster.AddNodeToClusterHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/:id/sync",
+				Handler: gpu_cluster.SyncGpuClusterHandler(serverCtx),
+			},
 		},
 		rest.WithPrefix("/api/v1/gpuclusters"),
 	)
@@ -342,6 +599,11 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/devices",
 				Handler: gpu_node.AddDeviceToNodeHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/:id/uncordon",
+				Handler: gpu_node.UncordonGpuNodeHandler(serverCtx),
+			},
 		},
 		rest.WithPrefix("/api/v1/gpunodes"),
 	)
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"api/internal/handler/training"
+	"api/internal/svc"
+
+	"github.com/zeromicro/go-zero/rest"
+)
+
+// maxTensorboardPathDepth TensorBoard子路径的最大层级，如 experiment/<id>/data/plugin/scalars/tags
+const maxTensorboardPathDepth = 8
+
+// tensorboardRoutes 作业TensorBoard代理路由
+// go-zero路由不支持通配符，按层级为/:jobId/tensorboard之后的子路径逐一注册
+func tensorboardRoutes(serverCtx *svc.ServiceContext) []rest.Route {
+	handler := training.ProxyTensorboardHandler(serverCtx)
+
+	var routes []rest.Route
+	path := "/:jobId/tensorboard"
+	for depth := 0; depth <= maxTensorboardPathDepth; depth++ {
+		if depth > 0 {
+			path += fmt.Sprintf("/:p%d", depth)
+		}
+		for _, method := range []string{http.MethodGet, http.MethodPost} {
+			routes = append(routes, rest.Route{Method: method, Path: path, Handler: handler})
+		}
+	}
+	return routes
+}
@@ -0,0 +1,29 @@
+package training
+
+import (
+	"net/http"
+
+	"api/internal/logic/training"
+	"api/internal/svc"
+	"api/internal/types"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// 取消超参数搜索
+func CancelTrainingSweepHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.CancelTrainingSweepReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := training.NewCancelTrainingSweepLogic(r.Context(), svcCtx)
+		resp, err := l.CancelTrainingSweep(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
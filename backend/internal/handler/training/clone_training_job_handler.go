@@ -0,0 +1,29 @@
+package training
+
+import (
+	"net/http"
+
+	"api/internal/logic/training"
+	"api/internal/svc"
+	"api/internal/types"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// 克隆训练作业
+func CloneTrainingJobHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.CloneTrainingJobReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := training.NewCloneTrainingJobLogic(r.Context(), svcCtx)
+		resp, err := l.CloneTrainingJob(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package training
+
+import (
+	"net/http"
+
+	"api/internal/logic/training"
+	"api/internal/svc"
+	"api/internal/types"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// 创建检查点保留策略
+func CreateCheckpointRetentionPolicyHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.CreateCheckpointRetentionPolicyReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := training.NewCreateCheckpointRetentionPolicyLogic(r.Context(), svcCtx)
+		resp, err := l.CreateCheckpointRetentionPolicy(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
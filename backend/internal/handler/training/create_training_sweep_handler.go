@@ -0,0 +1,29 @@
+package training
+
+import (
+	"net/http"
+
+	"api/internal/logic/training"
+	"api/internal/svc"
+	"api/internal/types"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// 创建超参数搜索
+func CreateTrainingSweepHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.CreateTrainingSweepReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := training.NewCreateTrainingSweepLogic(r.Context(), svcCtx)
+		resp, err := l.CreateTrainingSweep(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
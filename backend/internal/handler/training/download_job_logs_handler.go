@@ -0,0 +1,26 @@
+package training
+
+import (
+	"net/http"
+
+	"api/internal/logic/training"
+	"api/internal/svc"
+	"api/internal/types"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// 下载作业归档日志（tar.gz）
+func DownloadJobLogsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.DownloadJobLogsReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := training.NewDownloadJobLogsLogic(r.Context(), svcCtx)
+		if err := l.DownloadJobLogs(w, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"api/internal/logic/training"
 	"api/internal/svc"
 	"api/internal/types"
+	"api/pkg/logstream"
 	"github.com/zeromicro/go-zero/rest/httpx"
 )
 
@@ -19,6 +20,14 @@ func GetJobInstanceLogsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 		}
 
 		l := training.NewGetJobInstanceLogsLogic(r.Context(), svcCtx)
+		// 客户端以SSE（Accept: text/event-stream）或WebSocket连接时推送实时日志
+		if logstream.IsStreamRequest(r) {
+			if err := l.StreamJobInstanceLogs(w, r, &req); err != nil {
+				httpx.ErrorCtx(r.Context(), w, err)
+			}
+			return
+		}
+
 		resp, err := l.GetJobInstanceLogs(&req)
 		if err != nil {
 			httpx.ErrorCtx(r.Context(), w, err)
@@ -0,0 +1,27 @@
+package training
+
+import (
+	"net/http"
+
+	"api/internal/logic/training"
+	"api/internal/svc"
+	"api/internal/types"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// 访问作业TensorBoard
+func ProxyTensorboardHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.ProxyTensorboardReq
+		// 只解析路径参数，请求体需要原样转发给TensorBoard
+		if err := httpx.ParsePath(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := training.NewProxyTensorboardLogic(r.Context(), svcCtx)
+		if err := l.ProxyTensorboard(w, r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		}
+	}
+}
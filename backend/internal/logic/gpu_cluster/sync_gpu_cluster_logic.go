@@ -0,0 +1,64 @@
+package gpu_cluster
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/model"
+	bizerrors "api/pkg/errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type SyncGpuClusterLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 从K8s节点同步GPU清单
+func NewSyncGpuClusterLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SyncGpuClusterLogic {
+	return &SyncGpuClusterLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// SyncGpuCluster 立即同步集群的GPU节点和设备，不受集群状态和定期同步开关影响
+func (l *SyncGpuClusterLogic) SyncGpuCluster(req *types.SyncGpuClusterReq) (resp *types.SyncGpuClusterResp, err error) {
+	cluster, err := l.svcCtx.VtGpuClustersModel.FindOne(req.ID)
+	if err == sql.ErrNoRows {
+		return nil, bizerrors.ErrGpuClusterNotFound
+	}
+	if err != nil {
+		return nil, bizerrors.WrapError(err, bizerrors.ErrCodeDatabaseError, "查询GPU集群失败")
+	}
+	if cluster.ClusterType != model.GpuClusterTypeK8s {
+		return nil, bizerrors.NewBizError(bizerrors.ErrCodeInvalidParam,
+			fmt.Sprintf("集群类型为 %s，只有k8s集群支持自动同步GPU清单", cluster.ClusterType), bizerrors.ErrorTypeValidation)
+	}
+
+	report, err := l.svcCtx.GpuInventory.SyncCluster(l.ctx, cluster, time.Now())
+	if err != nil {
+		return nil, bizerrors.WrapError(err, bizerrors.ErrCodeExternalService, "同步GPU清单失败")
+	}
+
+	l.Infof("GPU集群清单已同步: ID=%d, 节点%d个(新增%d, 离线%d), 设备%d个(新增%d, 离线%d)", cluster.Id,
+		report.Nodes, report.NodesCreated, report.NodesOffline, report.Devices, report.DevicesCreated, report.DevicesOffline)
+	return &types.SyncGpuClusterResp{
+		ClusterId:      cluster.Id,
+		Nodes:          report.Nodes,
+		NodesCreated:   report.NodesCreated,
+		NodesOffline:   report.NodesOffline,
+		Devices:        report.Devices,
+		DevicesCreated: report.DevicesCreated,
+		DevicesOffline: report.DevicesOffline,
+		TotalGpus:      cluster.TotalGpus,
+		AvailableGpus:  cluster.AvailableGpus,
+	}, nil
+}
@@ -2,9 +2,18 @@ package gpu_device
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
 
 	"api/internal/svc"
 	"api/internal/types"
+	"api/model"
+	bizerrors "api/pkg/errors"
+	"api/pkg/volcano"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -23,8 +32,115 @@ func NewAllocateGpuDeviceLogic(ctx context.Context, svcCtx *svc.ServiceContext)
 	}
 }
 
+// AllocateGpuDevice 为训练作业分配一组GPU设备，全部设备可分配时才会成功
+// 独占分配时并发请求同一设备只有一个请求成功，共享分配在每个设备上占用一个MIG实例、时间片副本或vGPU
 func (l *AllocateGpuDeviceLogic) AllocateGpuDevice(req *types.AllocateGpuDeviceReq) (resp *types.AllocateGpuDeviceResp, err error) {
-	// todo: add your logic here and delete this line
+	if len(req.DeviceIds) == 0 {
+		return nil, invalidAllocation("device_ids不能为空")
+	}
+	seen := make(map[int64]bool, len(req.DeviceIds))
+	for _, id := range req.DeviceIds {
+		if seen[id] {
+			return nil, invalidAllocation(fmt.Sprintf("device_ids中设备%d重复", id))
+		}
+		seen[id] = true
+	}
+
+	if err := validateSharing(req); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	duration, err := leaseDuration(req, now)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := l.svcCtx.VtTrainingJobsModel.FindOneDetail(req.JobId)
+	if err == sql.ErrNoRows {
+		return nil, bizerrors.ErrJobNotFound
+	}
+	if err != nil {
+		return nil, bizerrors.WrapError(err, bizerrors.ErrCodeDatabaseError, "查询训练作业失败")
+	}
+
+	metadata, err := json.Marshal(allocationMetadata{UserId: req.UserId, WorkspaceId: req.WorkspaceId, QueueName: req.QueueName})
+	if err != nil {
+		return nil, bizerrors.WrapError(err, bizerrors.ErrCodeInternalError, "序列化分配信息失败")
+	}
+	allocations, err := l.svcCtx.VtGpuDeviceAllocationsModel.Allocate(&model.GpuDeviceAllocationRequest{
+		DeviceIds:               req.DeviceIds,
+		JobId:                   req.JobId,
+		UserId:                  req.UserId,
+		Priority:                req.Priority,
+		ExpectedDurationSeconds: duration,
+		Metadata:                string(metadata),
+		AllocatedAt:             now,
+		SharingMode:             req.SharingMode,
+		MigProfile:              req.MigProfile,
+		MemoryMb:                req.MemoryMb,
+	})
+	var unavailable *model.GpuDeviceUnavailableError
+	if errors.As(err, &unavailable) {
+		if unavailable.Status == "" {
+			return nil, bizerrors.NewBizError(bizerrors.ErrCodeNotFound, fmt.Sprintf("GPU设备%d不存在", unavailable.DeviceId), bizerrors.ErrorTypeBusiness)
+		}
+		if unavailable.Reason != "" {
+			return nil, bizerrors.NewBizError(bizerrors.ErrCodeGpuDeviceUnavailable,
+				fmt.Sprintf("GPU设备%d无法分配: %s", unavailable.DeviceId, unavailable.Reason), bizerrors.ErrorTypeBusiness)
+		}
+		return nil, bizerrors.NewBizError(bizerrors.ErrCodeGpuDeviceUnavailable,
+			fmt.Sprintf("GPU设备%d当前状态为%s(健康状态%s)，无法分配", unavailable.DeviceId, unavailable.Status, unavailable.HealthStatus), bizerrors.ErrorTypeBusiness)
+	}
+	if err != nil {
+		return nil, bizerrors.WrapError(err, bizerrors.ErrCodeDatabaseError, "分配GPU设备失败")
+	}
+
+	builder := newAllocationInfoBuilder(l.svcCtx)
+	resp = &types.AllocateGpuDeviceResp{Allocations: make([]types.GpuAllocationInfo, 0, len(allocations))}
+	for _, allocation := range allocations {
+		info, err := builder.build(allocation, job.Name)
+		if err != nil {
+			return nil, bizerrors.WrapError(err, bizerrors.ErrCodeDatabaseError, "查询GPU设备失败")
+		}
+		resp.Allocations = append(resp.Allocations, info)
+	}
+
+	l.Infof("GPU设备已分配: 作业ID=%d, 设备%v, 预期时长%ds", req.JobId, req.DeviceIds, duration)
+	return resp, nil
+}
+
+// leaseDuration 计算分配的预期使用时长(秒)，expected_duration_seconds优先，0表示不限时长
+func leaseDuration(req *types.AllocateGpuDeviceReq, now time.Time) (int, error) {
+	if req.ExpectedDurationSeconds < 0 {
+		return 0, invalidAllocation("expected_duration_seconds不能小于0")
+	}
+	if req.ExpectedDurationSeconds > 0 || req.ExpiresAt == nil || *req.ExpiresAt == "" {
+		return req.ExpectedDurationSeconds, nil
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, *req.ExpiresAt)
+	if err != nil {
+		expiresAt, err = time.ParseInLocation(timeLayout, *req.ExpiresAt, time.Local)
+	}
+	if err != nil {
+		return 0, invalidAllocation("expires_at格式错误: " + *req.ExpiresAt)
+	}
+	if !expiresAt.After(now) {
+		return 0, invalidAllocation("expires_at必须晚于当前时间")
+	}
+	return int(math.Ceil(expiresAt.Sub(now).Seconds())), nil
+}
+
+// validateSharing 校验共享分配参数，mig必须指定规格，vgpu必须指定显存
+func validateSharing(req *types.AllocateGpuDeviceReq) error {
+	sharing := volcano.GPUSharing{Mode: req.SharingMode, MIGProfile: req.MigProfile, MemoryMB: int64(req.MemoryMb)}
+	if err := sharing.Validate(); err != nil {
+		return invalidAllocation(err.Error())
+	}
+	return nil
+}
 
-	return
+func invalidAllocation(message string) error {
+	return bizerrors.NewBizError(bizerrors.ErrCodeInvalidParam, message, bizerrors.ErrorTypeValidation)
 }
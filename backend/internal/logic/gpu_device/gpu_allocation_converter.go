@@ -0,0 +1,131 @@
+package gpu_device
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/model"
+)
+
+const timeLayout = "2006-01-02 15:04:05"
+
+// formatTime 格式化可空时间，为空时返回nil
+func formatTime(t *time.Time) *string {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	formatted := t.Format(timeLayout)
+	return &formatted
+}
+
+// allocationMetadata 分配记录metadata字段保存的申请信息
+type allocationMetadata struct {
+	UserId      int64  `json:"user_id"`
+	WorkspaceId int64  `json:"workspace_id,omitempty"`
+	QueueName   string `json:"queue_name,omitempty"`
+}
+
+// allocationInfoBuilder 将分配记录转换为接口返回结构，缓存查询过的设备、节点和集群名称
+type allocationInfoBuilder struct {
+	svcCtx   *svc.ServiceContext
+	devices  map[int64]*model.VtGpuDevices
+	nodes    map[int64]string
+	clusters map[int64]string
+}
+
+func newAllocationInfoBuilder(svcCtx *svc.ServiceContext) *allocationInfoBuilder {
+	return &allocationInfoBuilder{
+		svcCtx:   svcCtx,
+		devices:  make(map[int64]*model.VtGpuDevices),
+		nodes:    make(map[int64]string),
+		clusters: make(map[int64]string),
+	}
+}
+
+// build 转换分配记录，设备已删除时只返回分配本身的信息
+func (b *allocationInfoBuilder) build(allocation *model.VtGpuDeviceAllocations, jobName string) (types.GpuAllocationInfo, error) {
+	var metadata allocationMetadata
+	if allocation.Metadata != "" {
+		_ = json.Unmarshal([]byte(allocation.Metadata), &metadata)
+	}
+	info := types.GpuAllocationInfo{
+		ID:             allocation.Id,
+		DeviceId:       allocation.DeviceId,
+		JobId:          allocation.EntityId,
+		JobName:        jobName,
+		UserId:         metadata.UserId,
+		WorkspaceId:    metadata.WorkspaceId,
+		QueueName:      metadata.QueueName,
+		AllocationType: allocation.AllocationType,
+		SharingMode:    allocation.SharingMode,
+		MigProfile:     allocation.MigProfile,
+		MemoryMb:       allocation.MemoryMb,
+		Status:         allocation.Status,
+		Priority:       allocation.Priority,
+		ExpiresAt:      formatTime(allocation.ExpiresAt()),
+		AllocatedAt:    allocation.AllocatedAt.Format(timeLayout),
+		ReleasedAt:     formatTime(allocation.ReleasedAt),
+		CreatedAt:      allocation.CreatedAt.Format(timeLayout),
+		UpdatedAt:      allocation.UpdatedAt.Format(timeLayout),
+	}
+
+	device, err := b.device(allocation.DeviceId)
+	if err != nil || device == nil {
+		return info, err
+	}
+	info.DeviceUUID = device.DeviceUuid
+	info.DeviceName = device.DeviceName
+	if info.NodeName, err = b.nodeName(device.NodeId); err != nil {
+		return info, err
+	}
+	info.ClusterName, err = b.clusterName(device.ClusterId)
+	return info, err
+}
+
+func (b *allocationInfoBuilder) device(id int64) (*model.VtGpuDevices, error) {
+	if device, ok := b.devices[id]; ok {
+		return device, nil
+	}
+	device, err := b.svcCtx.VtGpuDevicesModel.FindOne(id)
+	if err == sql.ErrNoRows {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	b.devices[id] = device
+	return device, nil
+}
+
+func (b *allocationInfoBuilder) nodeName(id int64) (string, error) {
+	if name, ok := b.nodes[id]; ok {
+		return name, nil
+	}
+	node, err := b.svcCtx.VtGpuNodesModel.FindOne(id)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	b.nodes[id] = node.Name
+	return node.Name, nil
+}
+
+func (b *allocationInfoBuilder) clusterName(id int64) (string, error) {
+	if name, ok := b.clusters[id]; ok {
+		return name, nil
+	}
+	cluster, err := b.svcCtx.VtGpuClustersModel.FindOne(id)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	b.clusters[id] = cluster.Name
+	return cluster.Name, nil
+}
@@ -2,9 +2,13 @@ package gpu_device
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"api/internal/svc"
 	"api/internal/types"
+	"api/model"
+	bizerrors "api/pkg/errors"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -23,8 +27,19 @@ func NewReleaseGpuDeviceLogic(ctx context.Context, svcCtx *svc.ServiceContext) *
 	}
 }
 
+// ReleaseGpuDevice 结束分配并释放设备，分配已释放或已过期时返回冲突
 func (l *ReleaseGpuDeviceLogic) ReleaseGpuDevice(req *types.ReleaseGpuDeviceReq) (resp *types.EmptyResp, err error) {
-	// todo: add your logic here and delete this line
+	allocation, err := l.svcCtx.VtGpuDeviceAllocationsModel.Release(req.ID, model.GpuAllocationStatusReleased, time.Now())
+	if err == sql.ErrNoRows {
+		return nil, bizerrors.ErrGpuAllocationNotFound
+	}
+	if err == model.ErrGpuAllocationNotActive {
+		return nil, bizerrors.ErrGpuAllocationNotActive
+	}
+	if err != nil {
+		return nil, bizerrors.WrapError(err, bizerrors.ErrCodeDatabaseError, "释放GPU设备失败")
+	}
 
-	return
+	l.Infof("GPU设备已释放: 分配ID=%d, 设备ID=%d, 作业ID=%d", allocation.Id, allocation.DeviceId, allocation.EntityId)
+	return &types.EmptyResp{}, nil
 }
@@ -0,0 +1,50 @@
+package gpu_node
+
+import (
+	"context"
+	"database/sql"
+
+	"api/internal/svc"
+	"api/internal/types"
+	bizerrors "api/pkg/errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UncordonGpuNodeLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// GPU故障隔离的节点维修后解除隔离
+func NewUncordonGpuNodeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UncordonGpuNodeLogic {
+	return &UncordonGpuNodeLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UncordonGpuNode 恢复节点调度并将故障设备恢复为healthy，设备仍有故障时会被重新隔离
+func (l *UncordonGpuNodeLogic) UncordonGpuNode(req *types.UncordonGpuNodeReq) (resp *types.UncordonGpuNodeResp, err error) {
+	node, err := l.svcCtx.VtGpuNodesModel.FindOne(req.ID)
+	if err == sql.ErrNoRows {
+		return nil, bizerrors.ErrGpuNodeNotFound
+	}
+	if err != nil {
+		return nil, bizerrors.WrapError(err, bizerrors.ErrCodeDatabaseError, "查询GPU节点失败")
+	}
+
+	recovered, err := l.svcCtx.GpuRemediator.UncordonNode(l.ctx, node)
+	if err != nil {
+		return nil, bizerrors.WrapError(err, bizerrors.ErrCodeExternalService, "解除GPU节点隔离失败")
+	}
+
+	l.Infof("GPU节点已解除隔离: ID=%d, %s, 恢复设备%d个", node.Id, node.Name, recovered)
+	return &types.UncordonGpuNodeResp{
+		NodeId:           node.Id,
+		Status:           node.Status,
+		DevicesRecovered: recovered,
+	}, nil
+}
@@ -5,6 +5,8 @@ import (
 
 	"api/internal/svc"
 	"api/internal/types"
+	"api/pkg/middleware"
+	"api/pkg/scheduler"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -25,7 +27,17 @@ func NewCancelTrainingJobLogic(ctx context.Context, svcCtx *svc.ServiceContext)
 }
 
 func (l *CancelTrainingJobLogic) CancelTrainingJob(req *types.CancelTrainingJobReq) (resp *types.EmptyResp, err error) {
-	// todo: add your logic here and delete this line
+	operator := scheduler.JobOperator{
+		UserID:   middleware.GetUserIDFromContext(l.ctx),
+		Username: middleware.GetUsernameFromContext(l.ctx),
+	}
+
+	status, err := l.svcCtx.JobStateMachine.Cancel(req.Id, operator, req.Reason)
+	if err != nil {
+		l.Errorf("取消训练作业失败: ID=%d, %v", req.Id, err)
+		return nil, err
+	}
 
-	return
+	l.Infof("训练作业已取消: ID=%d, 当前状态=%s", req.Id, status)
+	return &types.EmptyResp{}, nil
 }
@@ -0,0 +1,64 @@
+package training
+
+import (
+	"context"
+	"fmt"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/model"
+	bizerrors "api/pkg/errors"
+	"api/pkg/middleware"
+	"api/pkg/scheduler"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CancelTrainingSweepLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 取消超参数搜索
+func NewCancelTrainingSweepLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CancelTrainingSweepLogic {
+	return &CancelTrainingSweepLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CancelTrainingSweepLogic) CancelTrainingSweep(req *types.CancelTrainingSweepReq) (resp *types.EmptyResp, err error) {
+	userId := middleware.GetUserIDFromContext(l.ctx)
+	sweep, err := findOwnedSweep(l.svcCtx, req.Id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	// 先结束搜索，避免控制器继续创建试验
+	finished, err := l.svcCtx.VtTrainingSweepsModel.Finish(sweep.Id, model.SweepStatusCancelled, req.Reason)
+	if err != nil {
+		l.Logger.Errorf("取消超参数搜索失败: ID=%d, %v", sweep.Id, err)
+		return nil, err
+	}
+	if !finished {
+		return nil, bizerrors.NewBusinessError(bizerrors.ErrCodeJobInvalidTransition,
+			fmt.Sprintf("超参数搜索当前状态为 %s，不允许取消", sweep.Status))
+	}
+
+	trials, err := l.svcCtx.SweepTracker.Trials(sweep)
+	if err != nil {
+		return nil, err
+	}
+	operator := scheduler.JobOperator{UserID: userId, Username: middleware.GetUsernameFromContext(l.ctx)}
+	for _, trial := range trials {
+		if scheduler.IsTerminalJobStatus(trial.Status) {
+			continue
+		}
+		if _, err := l.svcCtx.JobStateMachine.Cancel(trial.JobId, operator, "超参数搜索已取消"); err != nil {
+			l.Logger.Errorf("取消试验作业失败: 搜索ID=%d, 作业ID=%d, %v", sweep.Id, trial.JobId, err)
+		}
+	}
+	return &types.EmptyResp{}, nil
+}
@@ -0,0 +1,152 @@
+package training
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/model"
+	bizerrors "api/pkg/errors"
+	"api/pkg/jobtemplate"
+	"api/pkg/scheduler"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// maxJobNameLength vt_training_jobs.name的最大字符数
+	maxJobNameLength = 128
+	// maxCloneNameAttempts 生成克隆作业名称时最多尝试的序号
+	maxCloneNameAttempts = 1000
+)
+
+// cloneNameSuffix 克隆作业名称的序号后缀，克隆已克隆的作业时先去掉原有后缀
+var cloneNameSuffix = regexp.MustCompile(`-clone-\d+$`)
+
+// cloneMetadata 克隆关联关系中记录的元数据
+type cloneMetadata struct {
+	Overrides    map[string]interface{} `json:"overrides,omitempty"`
+	CheckpointId int64                  `json:"checkpointId,omitempty"`
+}
+
+type CloneTrainingJobLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 克隆训练作业
+func NewCloneTrainingJobLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CloneTrainingJobLogic {
+	return &CloneTrainingJobLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// CloneTrainingJob 复制源作业的规格创建新作业
+// overrides按JSON合并补丁修改规格，以JSON字符串保存的字段(如hyperparameters)可以直接传对象逐项修改；
+// 克隆作业通过与作业同一事务写入的cloned_from关联到源作业，fromBestCheckpoint时从源作业的最佳检查点开始训练
+func (l *CloneTrainingJobLogic) CloneTrainingJob(req *types.CloneTrainingJobReq) (resp *types.CloneTrainingJobResp, err error) {
+	origin, err := findJob(l.svcCtx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	createReq, err := cloneCreateTrainingJobReq(origin, req.Overrides)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != "" {
+		createReq.Name = req.Name
+	} else if createReq.Name == "" || createReq.Name == origin.Name {
+		if createReq.Name, err = l.uniqueName(origin.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.FromBestCheckpoint {
+		best, err := l.svcCtx.VtTrainingCheckpointsModel.FindBest(origin.Id)
+		if err == sql.ErrNoRows {
+			return nil, invalidCheckpoint(fmt.Sprintf("源作业 %s 没有可用的检查点", origin.Name))
+		}
+		if err != nil {
+			return nil, bizerrors.WrapError(err, bizerrors.ErrCodeDatabaseError, "查询源作业检查点失败")
+		}
+		createReq.ResumeCheckpointId = best.Id
+	}
+
+	metadata, err := json.Marshal(cloneMetadata{Overrides: req.Overrides, CheckpointId: createReq.ResumeCheckpointId})
+	if err != nil {
+		return nil, err
+	}
+	creator := NewCreateTrainingJobLogic(l.ctx, l.svcCtx)
+	creator.relations = append(creator.relations, &model.VtTrainingJobRelations{
+		EntityType:   scheduler.JobEntityType,
+		EntityId:     origin.Id,
+		RelationType: scheduler.ClonedFromRelation,
+		Metadata:     string(metadata),
+	})
+	created, err := creator.CreateTrainingJob(createReq)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Infof("训练作业已克隆: 源作业ID=%d, 作业ID=%d, Name=%s, 初始检查点ID=%d", origin.Id, created.Id, createReq.Name, createReq.ResumeCheckpointId)
+	return &types.CloneTrainingJobResp{
+		Id:                 created.Id,
+		Name:               createReq.Name,
+		ResumeCheckpointId: createReq.ResumeCheckpointId,
+	}, nil
+}
+
+// uniqueName 按源作业名称生成未被使用的克隆作业名称，如 resnet-clone-1
+func (l *CloneTrainingJobLogic) uniqueName(originName string) (string, error) {
+	base := cloneNameSuffix.ReplaceAllString(originName, "")
+	for i := 1; i <= maxCloneNameAttempts; i++ {
+		suffix := fmt.Sprintf("-clone-%d", i)
+		name := base
+		if utf8.RuneCountInString(name)+len(suffix) > maxJobNameLength {
+			// 按字符截断，避免截断多字节字符
+			name = string([]rune(name)[:maxJobNameLength-len(suffix)])
+		}
+		name += suffix
+
+		_, err := l.svcCtx.VtTrainingJobsModel.FindOneByName(name)
+		if err == sql.ErrNoRows {
+			return name, nil
+		}
+		if err != nil {
+			return "", bizerrors.WrapError(err, bizerrors.ErrCodeDatabaseError, "检查训练作业名称失败")
+		}
+	}
+	return "", bizerrors.NewBusinessError(bizerrors.ErrCodeDuplicateData, fmt.Sprintf("无法为作业 %s 生成克隆名称，请指定名称", originName))
+}
+
+// cloneCreateTrainingJobReq 将合并补丁应用到源作业的规格上
+func cloneCreateTrainingJobReq(origin *model.VtTrainingJobs, overrides map[string]interface{}) (*types.CreateTrainingJobReq, error) {
+	spec, err := marshalCreateTrainingJobReq(toCreateTrainingJobReq(origin))
+	if err != nil {
+		return nil, err
+	}
+	if len(overrides) > 0 {
+		patch, err := json.Marshal(overrides)
+		if err != nil {
+			return nil, err
+		}
+		if spec, err = jobtemplate.MergePatch(spec, patch); err != nil {
+			return nil, bizerrors.NewBizError(bizerrors.ErrCodeInvalidParam, err.Error(), bizerrors.ErrorTypeValidation)
+		}
+	}
+
+	req, err := parseCreateTrainingJobReq(spec)
+	if err != nil {
+		return nil, bizerrors.NewBizError(bizerrors.ErrCodeInvalidParam, err.Error(), bizerrors.ErrorTypeValidation)
+	}
+	return req, nil
+}
@@ -2,9 +2,12 @@ package training
 
 import (
 	"context"
+	"database/sql"
 
 	"api/internal/svc"
 	"api/internal/types"
+	"api/model"
+	bizerrors "api/pkg/errors"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -24,8 +27,62 @@ func NewCreateCheckpointLogic(ctx context.Context, svcCtx *svc.ServiceContext) *
 	}
 }
 
+// CreateCheckpoint 登记训练容器已写入存储的检查点
+// 文件必须已存在，未提供校验和时读取文件计算，恢复时按记录的校验和与压缩类型校验
 func (l *CreateCheckpointLogic) CreateCheckpoint(req *types.CreateCheckpointReq) (resp *types.CreateCheckpointResp, err error) {
-	// todo: add your logic here and delete this line
+	job, err := findJob(l.svcCtx, req.JobId)
+	if err != nil {
+		return nil, err
+	}
+	manager, err := checkpointManager(l.svcCtx)
+	if err != nil {
+		return nil, err
+	}
+	if req.CheckpointName == "" || req.StoragePath == "" {
+		return nil, invalidCheckpoint("检查点名称和存储路径不能为空")
+	}
+	if err := validateCheckpointEnum("检查点类型", req.CheckpointType, checkpointTypes); err != nil {
+		return nil, err
+	}
+	if err := validateCheckpointEnum("检查点格式", req.CheckpointFormat, checkpointFormats); err != nil {
+		return nil, err
+	}
 
-	return
+	existing, err := l.svcCtx.VtTrainingCheckpointsModel.FindOneByName(job.Id, req.CheckpointName)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if existing != nil && existing.Status != "deleted" {
+		return nil, bizerrors.ErrCheckpointExists
+	}
+
+	c := &model.VtTrainingCheckpoints{
+		JobId:            job.Id,
+		CheckpointName:   req.CheckpointName,
+		CheckpointType:   req.CheckpointType,
+		CheckpointFormat: req.CheckpointFormat,
+		Step:             req.Step,
+		Epoch:            int(req.Epoch),
+		GlobalStep:       req.GlobalStep,
+		StoragePath:      req.StoragePath,
+		Checksum:         req.Checksum,
+		CompressionType:  req.CompressionType,
+		Metrics:          req.Metrics,
+		LossValue:        req.LossValue,
+		Accuracy:         req.Accuracy,
+		ValidationScore:  req.ValidationScore,
+		ModelConfig:      req.ModelConfig,
+		OptimizerState:   req.OptimizerState,
+		SchedulerState:   req.SchedulerState,
+		IsBest:           req.IsBest,
+		IsLatest:         req.IsLatest,
+		Tags:             req.Tags,
+		Metadata:         req.Metadata,
+		Description:      req.Description,
+	}
+	if err := manager.Register(l.ctx, c); err != nil {
+		l.Errorf("登记检查点失败: 作业ID=%d, %s, %v", job.Id, req.CheckpointName, err)
+		return nil, toCheckpointError(err)
+	}
+	return &types.CreateCheckpointResp{Id: c.Id}, nil
 }
@@ -0,0 +1,75 @@
+package training
+
+import (
+	"context"
+	"database/sql"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/model"
+	bizerrors "api/pkg/errors"
+	"api/pkg/middleware"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateCheckpointRetentionPolicyLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 创建检查点保留策略
+func NewCreateCheckpointRetentionPolicyLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateCheckpointRetentionPolicyLogic {
+	return &CreateCheckpointRetentionPolicyLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateCheckpointRetentionPolicyLogic) CreateCheckpointRetentionPolicy(req *types.CreateCheckpointRetentionPolicyReq) (resp *types.CreateCheckpointRetentionPolicyResp, err error) {
+	if err := checkRetentionScope(l.svcCtx, req.ScopeType, req.ScopeId); err != nil {
+		return nil, err
+	}
+	policy := &model.VtCheckpointRetentionPolicies{
+		Name:             req.Name,
+		ScopeType:        req.ScopeType,
+		ScopeId:          req.ScopeId,
+		KeepLast:         int(req.KeepLast),
+		KeepBest:         int(req.KeepBest),
+		BestMetric:       req.BestMetric,
+		BestGoal:         req.BestGoal,
+		KeepEveryNEpochs: int(req.KeepEveryNEpochs),
+		Enabled:          req.Enabled,
+		Description:      req.Description,
+		CreatedBy:        middleware.GetUserIDFromContext(l.ctx),
+	}
+	if err := validateRetentionPolicy(policy); err != nil {
+		return nil, err
+	}
+
+	// 每个作用范围只能有一条策略
+	_, err = l.svcCtx.VtCheckpointRetentionPoliciesModel.FindOneByScope(req.ScopeType, req.ScopeId)
+	if err == nil {
+		return nil, bizerrors.ErrRetentionExists
+	}
+	if err != sql.ErrNoRows {
+		l.Logger.Errorf("检查保留策略作用范围失败: %v", err)
+		return nil, err
+	}
+
+	result, err := l.svcCtx.VtCheckpointRetentionPoliciesModel.Insert(policy)
+	if err != nil {
+		l.Logger.Errorf("创建检查点保留策略失败: %v", err)
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	l.Logger.Infof("检查点保留策略创建成功: ID=%d, 作用范围=%s/%d, keepLast=%d, keepBest=%d(%s), keepEveryNEpochs=%d",
+		id, policy.ScopeType, policy.ScopeId, policy.KeepLast, policy.KeepBest, policy.BestMetric, policy.KeepEveryNEpochs)
+	return &types.CreateCheckpointRetentionPolicyResp{Id: id}, nil
+}
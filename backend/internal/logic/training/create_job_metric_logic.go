@@ -2,9 +2,12 @@ package training
 
 import (
 	"context"
+	"encoding/json"
+	"strconv"
 
 	"api/internal/svc"
 	"api/internal/types"
+	"api/model"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -24,8 +27,53 @@ func NewCreateJobMetricLogic(ctx context.Context, svcCtx *svc.ServiceContext) *C
 	}
 }
 
+// CreateJobMetric 写入单条训练指标，校验规则与训练容器批量上报一致
+// 直方图指标的metricData为直方图JSON，文本指标的metricData为文本内容
 func (l *CreateJobMetricLogic) CreateJobMetric(req *types.CreateJobMetricReq) (resp *types.CreateJobMetricResp, err error) {
-	// todo: add your logic here and delete this line
+	job, err := findJob(l.svcCtx, req.JobId)
+	if err != nil {
+		return nil, err
+	}
 
-	return
+	sample := types.MetricSample{
+		Name:       req.MetricName,
+		Type:       req.MetricType,
+		Step:       req.Step,
+		Epoch:      req.Epoch,
+		GlobalStep: req.GlobalStep,
+		BatchIdx:   req.BatchIdx,
+		Phase:      req.Phase,
+		Tag:        req.Tag,
+		Category:   req.Category,
+	}
+	switch req.MetricType {
+	case model.MetricTypeHistogram:
+		sample.Histogram = &types.MetricHistogram{}
+		if err := json.Unmarshal([]byte(req.MetricData), sample.Histogram); err != nil {
+			return nil, invalidParam("直方图metricData格式错误: " + err.Error())
+		}
+	case model.MetricTypeText:
+		sample.Text = req.MetricData
+	default:
+		if sample.Value, err = strconv.ParseFloat(req.MetricValue, 64); err != nil {
+			return nil, invalidParam("指标值格式错误: " + req.MetricValue)
+		}
+	}
+	if req.WallTime != "" {
+		if sample.WallTime, err = strconv.ParseFloat(req.WallTime, 64); err != nil {
+			return nil, invalidParam("wallTime格式错误: " + req.WallTime)
+		}
+	}
+
+	metric, err := buildTrainingMetric(job, req.InstanceId, &sample)
+	if err != nil {
+		return nil, invalidParam(err.Error())
+	}
+	result, err := l.svcCtx.VtTrainingMetricsModel.Insert(metric)
+	if err != nil {
+		l.Errorf("写入训练指标失败: jobId=%d, %v", job.Id, err)
+		return nil, err
+	}
+	id, _ := result.LastInsertId()
+	return &types.CreateJobMetricResp{Id: id}, nil
 }
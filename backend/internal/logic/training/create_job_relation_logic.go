@@ -2,9 +2,15 @@ package training
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
 
 	"api/internal/svc"
 	"api/internal/types"
+	"api/model"
+	bizerrors "api/pkg/errors"
+	"api/pkg/scheduler"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -25,7 +31,77 @@ func NewCreateJobRelationLogic(ctx context.Context, svcCtx *svc.ServiceContext)
 }
 
 func (l *CreateJobRelationLogic) CreateJobRelation(req *types.CreateJobRelationReq) (resp *types.CreateJobRelationResp, err error) {
-	// todo: add your logic here and delete this line
+	if req.EntityType == "" || req.RelationType == "" || req.EntityId <= 0 {
+		return nil, bizerrors.NewBizError(bizerrors.ErrCodeInvalidParam, "实体类型、实体ID和关联类型不能为空", bizerrors.ErrorTypeValidation)
+	}
+
+	job, err := l.svcCtx.VtTrainingJobsModel.FindOneDetail(req.JobId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, bizerrors.ErrJobNotFound
+		}
+		return nil, bizerrors.WrapError(err, bizerrors.ErrCodeDatabaseError, "查询训练作业失败")
+	}
+
+	existing, err := l.svcCtx.VtTrainingJobRelationsModel.FindByJobId(req.JobId, req.EntityType, req.RelationType)
+	if err != nil {
+		return nil, bizerrors.WrapError(err, bizerrors.ErrCodeDatabaseError, "查询作业关联关系失败")
+	}
+	for _, relation := range existing {
+		if relation.EntityId == req.EntityId {
+			return nil, bizerrors.NewBusinessError(bizerrors.ErrCodeDuplicateData, "作业关联关系已存在")
+		}
+	}
+
+	if req.RelationType == scheduler.DependsOnRelation {
+		if err := l.validateDependency(job, req); err != nil {
+			return nil, err
+		}
+	} else if req.Metadata != "" && !json.Valid([]byte(req.Metadata)) {
+		return nil, bizerrors.NewBizError(bizerrors.ErrCodeInvalidParam, "关联元数据必须是JSON", bizerrors.ErrorTypeValidation)
+	}
+
+	result, err := l.svcCtx.VtTrainingJobRelationsModel.Insert(&model.VtTrainingJobRelations{
+		JobId:        req.JobId,
+		EntityType:   req.EntityType,
+		EntityId:     req.EntityId,
+		RelationType: req.RelationType,
+		IsPrimary:    req.IsPrimary,
+		SortOrder:    int(req.SortOrder),
+		Metadata:     req.Metadata,
+	})
+	if err != nil {
+		l.Errorf("创建作业关联关系失败: JobID=%d, %v", req.JobId, err)
+		return nil, bizerrors.WrapError(err, bizerrors.ErrCodeDatabaseError, "创建作业关联关系失败")
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, bizerrors.WrapError(err, bizerrors.ErrCodeDatabaseError, "获取作业关联关系ID失败")
+	}
 
-	return
+	l.Infof("作业关联关系已创建: ID=%d, JobID=%d, %s %s:%d", id, req.JobId, req.RelationType, req.EntityType, req.EntityId)
+	return &types.CreateJobRelationResp{Id: id}, nil
+}
+
+// validateDependency 校验流水线依赖：只能依赖已存在的训练作业、不能成环，且子作业尚未派发
+func (l *CreateJobRelationLogic) validateDependency(job *model.VtTrainingJobs, req *types.CreateJobRelationReq) error {
+	if req.EntityType != scheduler.JobEntityType {
+		return bizerrors.NewBizError(bizerrors.ErrCodePipelineInvalid,
+			fmt.Sprintf("%s 关联的实体类型必须为 %s", scheduler.DependsOnRelation, scheduler.JobEntityType), bizerrors.ErrorTypeValidation)
+	}
+	if job.Status != scheduler.JobStatusPending {
+		return bizerrors.NewBizError(bizerrors.ErrCodePipelineInvalid,
+			fmt.Sprintf("训练作业当前状态为 %s，只能为尚未派发的作业添加依赖", job.Status), bizerrors.ErrorTypeValidation)
+	}
+	if _, err := scheduler.ParseDependencyMetadata(req.Metadata); err != nil {
+		return bizerrors.NewBizError(bizerrors.ErrCodePipelineInvalid, err.Error(), bizerrors.ErrorTypeValidation)
+	}
+
+	if _, err := l.svcCtx.VtTrainingJobsModel.FindOneDetail(req.EntityId); err != nil {
+		if err == sql.ErrNoRows {
+			return bizerrors.NewBizError(bizerrors.ErrCodePipelineInvalid, fmt.Sprintf("上游作业 %d 不存在", req.EntityId), bizerrors.ErrorTypeValidation)
+		}
+		return bizerrors.WrapError(err, bizerrors.ErrCodeDatabaseError, "查询上游作业失败")
+	}
+	return l.svcCtx.JobPipeline.CheckDependency(req.JobId, req.EntityId)
 }
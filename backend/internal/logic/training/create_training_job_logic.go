@@ -3,13 +3,14 @@ package training
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"fmt"
 	"time"
 
 	"api/internal/svc"
 	"api/internal/types"
 	"api/model"
+	bizerrors "api/pkg/errors"
+	"api/pkg/scheduler"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -18,6 +19,9 @@ type CreateTrainingJobLogic struct {
 	logx.Logger
 	ctx    context.Context
 	svcCtx *svc.ServiceContext
+
+	// relations 与作业在同一事务中写入的其他关联，如克隆作业的cloned_from
+	relations []*model.VtTrainingJobRelations
 }
 
 // 创建训练作业
@@ -36,6 +40,19 @@ func (l *CreateTrainingJobLogic) CreateTrainingJob(req *types.CreateTrainingJobR
 		return nil, fmt.Errorf("请求参数验证失败: %w", err)
 	}
 
+	// 校验流水线上游作业
+	if err = l.checkDependencies(req.DependsOn); err != nil {
+		return nil, err
+	}
+
+	// 校验初始检查点
+	var resume *model.VtTrainingCheckpoints
+	if req.ResumeCheckpointId > 0 {
+		if resume, err = l.findResumeCheckpoint(req.ResumeCheckpointId); err != nil {
+			return nil, err
+		}
+	}
+
 	// 检查训练作业名称是否已存在
 	exists, err := l.checkJobNameExists(req.Name)
 	if err != nil {
@@ -52,7 +69,7 @@ func (l *CreateTrainingJobLogic) CreateTrainingJob(req *types.CreateTrainingJobR
 		l.Logger.Errorf("创建数据库事务失败: %v", err)
 		return nil, fmt.Errorf("创建数据库事务失败: %w", err)
 	}
-	
+
 	// 确保事务在函数结束时正确处理
 	defer func() {
 		if err != nil {
@@ -65,66 +82,93 @@ func (l *CreateTrainingJobLogic) CreateTrainingJob(req *types.CreateTrainingJobR
 
 	// 创建训练作业模型
 	trainingJob := &model.VtTrainingJobs{
-		Name:                req.Name,
-		DisplayName:         req.DisplayName,
-		Description:         req.Description,
-		JobType:             req.JobType,
-		Framework:           req.Framework,
-		FrameworkVersion:    req.FrameworkVersion,
-		PythonVersion:       req.PythonVersion,
-		CodeSourceType:      req.CodeSourceType,
-		CodeSourceConfig:    req.CodeSourceConfig,
-		EntryPoint:          req.EntryPoint,
-		WorkingDir:          req.WorkingDir,
-		Image:               req.Image,
-		ImagePullPolicy:     req.ImagePullPolicy,
-		ImagePullSecrets:    req.ImagePullSecrets,
-		DatasetMountConfigs: req.DatasetMountConfigs,
-		DataSourceConfig:    req.DataSourceConfig,
-		ModelConfig:         req.ModelConfig,
-		OutputModelName:     req.OutputModelName,
-		ModelSaveStrategy:   req.ModelSaveStrategy,
-		CpuCores:            req.CpuCores,
-		MemoryGb:            req.MemoryGb,
-		GpuCount:            int(req.GpuCount),
-		GpuType:             req.GpuType,
-		GpuMemoryGb:         req.GpuMemoryGb,
-		StorageGb:           req.StorageGb,
-		SharedMemoryGb:      req.SharedMemoryGb,
-		WorkerCount:         int(req.WorkerCount),
-		PsCount:             int(req.PsCount),
-		MasterCount:         int(req.MasterCount),
-		EnvVars:             req.EnvVars,
-		CommandArgs:         req.CommandArgs,
-		Secrets:             req.Secrets,
-		ConfigMaps:          req.ConfigMaps,
-		VolumeMounts:        req.VolumeMounts,
-		QueueName:           req.QueueName,
-		Priority:            int(req.Priority),
-		NodeSelector:        req.NodeSelector,
-		Tolerations:         req.Tolerations,
-		Affinity:            req.Affinity,
-		MaxRuntimeSeconds:   int(req.MaxRuntimeSeconds),
-		Status:              "pending",
-		SubmittedAt:         time.Now(),
+		Name:                      req.Name,
+		DisplayName:               req.DisplayName,
+		Description:               req.Description,
+		JobType:                   req.JobType,
+		Framework:                 req.Framework,
+		FrameworkVersion:          req.FrameworkVersion,
+		PythonVersion:             req.PythonVersion,
+		CodeSourceType:            req.CodeSourceType,
+		CodeSourceConfig:          req.CodeSourceConfig,
+		EntryPoint:                req.EntryPoint,
+		WorkingDir:                req.WorkingDir,
+		Image:                     req.Image,
+		ImagePullPolicy:           req.ImagePullPolicy,
+		ImagePullSecrets:          req.ImagePullSecrets,
+		DatasetMountConfigs:       req.DatasetMountConfigs,
+		DataSourceConfig:          req.DataSourceConfig,
+		ModelConfig:               req.ModelConfig,
+		OutputModelName:           req.OutputModelName,
+		ModelSaveStrategy:         req.ModelSaveStrategy,
+		CpuCores:                  req.CpuCores,
+		MemoryGb:                  req.MemoryGb,
+		GpuCount:                  int(req.GpuCount),
+		GpuType:                   req.GpuType,
+		GpuMemoryGb:               req.GpuMemoryGb,
+		GpuSharingMode:            req.GpuSharingMode,
+		GpuMigProfile:             req.GpuMigProfile,
+		StorageGb:                 req.StorageGb,
+		SharedMemoryGb:            req.SharedMemoryGb,
+		WorkerCount:               int(req.WorkerCount),
+		PsCount:                   int(req.PsCount),
+		MasterCount:               int(req.MasterCount),
+		EnvVars:                   req.EnvVars,
+		CommandArgs:               req.CommandArgs,
+		Secrets:                   req.Secrets,
+		ConfigMaps:                req.ConfigMaps,
+		VolumeMounts:              req.VolumeMounts,
+		QueueName:                 req.QueueName,
+		Priority:                  int(req.Priority),
+		NodeSelector:              req.NodeSelector,
+		Tolerations:               req.Tolerations,
+		Affinity:                  req.Affinity,
+		MaxRuntimeSeconds:         int(req.MaxRuntimeSeconds),
+		MaxIdleSeconds:            int(req.MaxIdleSeconds),
+		AutoRestart:               req.AutoRestart,
+		MaxRetryCount:             int(req.MaxRetryCount),
+		MinAvailable:              int(req.MinAvailable),
+		Hyperparameters:           req.Hyperparameters,
+		TrainingConfig:            req.TrainingConfig,
+		OptimizerConfig:           req.OptimizerConfig,
+		SchedulerConfig:           req.SchedulerConfig,
+		EnableTensorboard:         req.EnableTensorboard,
+		EnableProfiling:           req.EnableProfiling,
+		MetricsCollectionInterval: int(req.MetricsCollectionInterval),
+		NotificationConfig:        req.NotificationConfig,
+		Tags:                      req.Tags,
+		Annotations:               req.Annotations,
+		Metadata:                  req.Metadata,
+		Status:                    "pending",
+		SubmittedAt:               time.Now(),
+	}
+	if resume != nil {
+		trainingJob.ResumeCheckpointId = resume.Id
+		trainingJob.ResumeCheckpointPath = resume.StoragePath
 	}
 
-	// 保存到数据库（使用事务）
+	// 保存到数据库（使用事务），空字符串的JSON和数值列写入NULL
 	result, err := tx.Exec(
-		`INSERT INTO vt_training_jobs (name, display_name, description, job_type, framework, framework_version, python_version, code_source_type, code_source_config, entry_point, working_dir, image, image_pull_policy, image_pull_secrets, dataset_mount_configs, data_source_config, model_config, output_model_name, model_save_strategy, cpu_cores, memory_gb, gpu_count, gpu_type, gpu_memory_gb, storage_gb, shared_memory_gb, worker_count, ps_count, master_count, env_vars, command_args, secrets, config_maps, volume_mounts, queue_name, priority, node_selector, tolerations, affinity, max_runtime_seconds, status, submitted_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		trainingJob.Name, trainingJob.DisplayName, trainingJob.Description, trainingJob.JobType, 
-		trainingJob.Framework, trainingJob.FrameworkVersion, trainingJob.PythonVersion, 
-		trainingJob.CodeSourceType, trainingJob.CodeSourceConfig, trainingJob.EntryPoint, 
-		trainingJob.WorkingDir, trainingJob.Image, trainingJob.ImagePullPolicy, 
-		trainingJob.ImagePullSecrets, trainingJob.DatasetMountConfigs, trainingJob.DataSourceConfig, 
-		trainingJob.ModelConfig, trainingJob.OutputModelName, trainingJob.ModelSaveStrategy, 
-		trainingJob.CpuCores, trainingJob.MemoryGb, trainingJob.GpuCount, trainingJob.GpuType, 
-		trainingJob.GpuMemoryGb, trainingJob.StorageGb, trainingJob.SharedMemoryGb, 
-		trainingJob.WorkerCount, trainingJob.PsCount, trainingJob.MasterCount, 
-		trainingJob.EnvVars, trainingJob.CommandArgs, trainingJob.Secrets, trainingJob.ConfigMaps, 
-		trainingJob.VolumeMounts, trainingJob.QueueName, trainingJob.Priority, 
-		trainingJob.NodeSelector, trainingJob.Tolerations, trainingJob.Affinity, 
-		trainingJob.MaxRuntimeSeconds, trainingJob.Status, trainingJob.SubmittedAt,
+		`INSERT INTO vt_training_jobs (name, display_name, description, job_type, framework, framework_version, python_version, code_source_type, code_source_config, entry_point, working_dir, image, image_pull_policy, image_pull_secrets, dataset_mount_configs, data_source_config, model_config, output_model_name, model_save_strategy, cpu_cores, memory_gb, gpu_count, gpu_type, gpu_memory_gb, gpu_sharing_mode, gpu_mig_profile, storage_gb, shared_memory_gb, worker_count, ps_count, master_count, env_vars, command_args, secrets, config_maps, volume_mounts, queue_name, priority, node_selector, tolerations, affinity, max_runtime_seconds, max_idle_seconds, auto_restart, max_retry_count, min_available, hyperparameters, training_config, optimizer_config, scheduler_config, enable_tensorboard, enable_profiling, metrics_collection_interval, notification_config, tags, annotations, metadata, resume_checkpoint_id, resume_checkpoint_path, status, submitted_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		trainingJob.Name, trainingJob.DisplayName, trainingJob.Description, trainingJob.JobType,
+		trainingJob.Framework, trainingJob.FrameworkVersion, trainingJob.PythonVersion,
+		trainingJob.CodeSourceType, nullIfEmpty(trainingJob.CodeSourceConfig), trainingJob.EntryPoint,
+		trainingJob.WorkingDir, trainingJob.Image, trainingJob.ImagePullPolicy,
+		nullIfEmpty(trainingJob.ImagePullSecrets), nullIfEmpty(trainingJob.DatasetMountConfigs), nullIfEmpty(trainingJob.DataSourceConfig),
+		nullIfEmpty(trainingJob.ModelConfig), trainingJob.OutputModelName, trainingJob.ModelSaveStrategy,
+		nullIfEmpty(trainingJob.CpuCores), nullIfEmpty(trainingJob.MemoryGb), trainingJob.GpuCount, trainingJob.GpuType,
+		nullIfEmpty(trainingJob.GpuMemoryGb), nullIfEmpty(trainingJob.GpuSharingMode), nullIfEmpty(trainingJob.GpuMigProfile), nullIfEmpty(trainingJob.StorageGb), nullIfEmpty(trainingJob.SharedMemoryGb),
+		trainingJob.WorkerCount, trainingJob.PsCount, trainingJob.MasterCount,
+		nullIfEmpty(trainingJob.EnvVars), nullIfEmpty(trainingJob.CommandArgs), nullIfEmpty(trainingJob.Secrets), nullIfEmpty(trainingJob.ConfigMaps),
+		nullIfEmpty(trainingJob.VolumeMounts), trainingJob.QueueName, trainingJob.Priority,
+		nullIfEmpty(trainingJob.NodeSelector), nullIfEmpty(trainingJob.Tolerations), nullIfEmpty(trainingJob.Affinity),
+		trainingJob.MaxRuntimeSeconds, trainingJob.MaxIdleSeconds, trainingJob.AutoRestart,
+		trainingJob.MaxRetryCount, trainingJob.MinAvailable, nullIfEmpty(trainingJob.Hyperparameters),
+		nullIfEmpty(trainingJob.TrainingConfig), nullIfEmpty(trainingJob.OptimizerConfig), nullIfEmpty(trainingJob.SchedulerConfig),
+		trainingJob.EnableTensorboard, trainingJob.EnableProfiling, trainingJob.MetricsCollectionInterval,
+		nullIfEmpty(trainingJob.NotificationConfig), nullIfEmpty(trainingJob.Tags), nullIfEmpty(trainingJob.Annotations),
+		nullIfEmpty(trainingJob.Metadata), nullIfZero(trainingJob.ResumeCheckpointId), nullIfEmpty(trainingJob.ResumeCheckpointPath),
+		trainingJob.Status, trainingJob.SubmittedAt,
 	)
 	if err != nil {
 		l.Logger.Errorf("保存训练作业失败: %v", err)
@@ -138,18 +182,22 @@ func (l *CreateTrainingJobLogic) CreateTrainingJob(req *types.CreateTrainingJobR
 		return nil, fmt.Errorf("获取训练作业ID失败: %w", err)
 	}
 
-	// 如果需要GPU资源，尝试预分配
-	if req.GpuCount > 0 {
-		if err = l.preAllocateGPUResources(jobID, req); err != nil {
-			l.Logger.Infof("GPU资源预分配失败，作业将等待资源: %v", err)
-			// 不返回错误，让作业进入等待状态
+	// 依赖关联与作业在同一事务中写入，避免派发器在依赖写入前提交作业
+	for i, parentID := range req.DependsOn {
+		_, err = tx.Exec(`INSERT INTO vt_training_job_relations (job_id, entity_type, entity_id, relation_type, sort_order, status) VALUES (?, ?, ?, ?, ?, 'active')`,
+			jobID, scheduler.JobEntityType, parentID, scheduler.DependsOnRelation, i)
+		if err != nil {
+			l.Logger.Errorf("保存训练作业依赖失败: %v", err)
+			return nil, fmt.Errorf("保存训练作业依赖失败: %w", err)
 		}
 	}
-
-	// 提交作业到调度队列
-	if err = l.submitToScheduleQueue(jobID, req); err != nil {
-		l.Logger.Errorf("提交作业到调度队列失败: %v", err)
-		// 不返回错误，作业已创建，可以后续重新调度
+	for _, relation := range l.relations {
+		_, err = tx.Exec(`INSERT INTO vt_training_job_relations (job_id, entity_type, entity_id, relation_type, status, metadata) VALUES (?, ?, ?, ?, 'active', ?)`,
+			jobID, relation.EntityType, relation.EntityId, relation.RelationType, nullIfEmpty(relation.Metadata))
+		if err != nil {
+			l.Logger.Errorf("保存训练作业关联失败: %v", err)
+			return nil, fmt.Errorf("保存训练作业关联失败: %w", err)
+		}
 	}
 
 	// 提交事务
@@ -158,6 +206,13 @@ func (l *CreateTrainingJobLogic) CreateTrainingJob(req *types.CreateTrainingJobR
 		return nil, fmt.Errorf("提交事务失败: %w", err)
 	}
 
+	// 作业以pending状态持久化，由派发器提交到Volcano，这里仅唤醒派发器
+	if l.svcCtx.JobDispatcher != nil {
+		l.svcCtx.JobDispatcher.Notify()
+	} else {
+		l.Logger.Infof("Volcano派发器未启用，作业 %d 将保持pending状态", jobID)
+	}
+
 	l.Logger.Infof("训练作业创建成功: ID=%d, Name=%s", jobID, req.Name)
 
 	return &types.CreateTrainingJobResp{
@@ -187,6 +242,14 @@ func (l *CreateTrainingJobLogic) validateRequest(req *types.CreateTrainingJobReq
 	if req.GpuCount > 0 && req.GpuType == "" {
 		return fmt.Errorf("指定GPU数量时必须指定GPU类型")
 	}
+	if req.GpuSharingMode != "" || req.GpuMigProfile != "" {
+		if req.GpuCount <= 0 {
+			return fmt.Errorf("共享GPU时必须指定GPU数量")
+		}
+		if _, err := scheduler.JobGPUSharing(req.GpuSharingMode, req.GpuMigProfile, req.GpuMemoryGb); err != nil {
+			return err
+		}
+	}
 
 	// 验证分布式训练配置
 	if req.JobType == "distributed" {
@@ -203,6 +266,37 @@ func (l *CreateTrainingJobLogic) validateRequest(req *types.CreateTrainingJobReq
 	return nil
 }
 
+// checkDependencies 检查上游作业存在且不重复
+func (l *CreateTrainingJobLogic) checkDependencies(parentIDs []int64) error {
+	seen := make(map[int64]bool, len(parentIDs))
+	for _, parentID := range parentIDs {
+		if seen[parentID] {
+			return bizerrors.NewBizError(bizerrors.ErrCodePipelineInvalid, fmt.Sprintf("上游作业 %d 重复", parentID), bizerrors.ErrorTypeValidation)
+		}
+		seen[parentID] = true
+
+		if _, err := l.svcCtx.VtTrainingJobsModel.FindOneDetail(parentID); err != nil {
+			if err == sql.ErrNoRows {
+				return bizerrors.NewBizError(bizerrors.ErrCodePipelineInvalid, fmt.Sprintf("上游作业 %d 不存在", parentID), bizerrors.ErrorTypeValidation)
+			}
+			return bizerrors.WrapError(err, bizerrors.ErrCodeDatabaseError, "查询上游作业失败")
+		}
+	}
+	return nil
+}
+
+// findResumeCheckpoint 查询作为初始检查点的已保存检查点
+func (l *CreateTrainingJobLogic) findResumeCheckpoint(id int64) (*model.VtTrainingCheckpoints, error) {
+	c, err := findCheckpoint(l.svcCtx, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Status != "saved" {
+		return nil, invalidCheckpoint(fmt.Sprintf("检查点 %s 状态为 %s，不能作为初始检查点", c.CheckpointName, c.Status))
+	}
+	return c, nil
+}
+
 // checkJobNameExists 检查训练作业名称是否已存在
 func (l *CreateTrainingJobLogic) checkJobNameExists(name string) (bool, error) {
 	// 使用模型检查名称是否存在
@@ -223,50 +317,18 @@ func (l *CreateTrainingJobLogic) getUserIDFromContext() int64 {
 	return 1001
 }
 
-// preAllocateGPUResources 预分配GPU资源
-func (l *CreateTrainingJobLogic) preAllocateGPUResources(jobID int64, req *types.CreateTrainingJobReq) error {
-	// 简化实现，实际应该调用GPU管理服务
-	l.Logger.Infof("尝试为作业 %d 预分配 %d 个 %s GPU", jobID, req.GpuCount, req.GpuType)
-
-	// 这里可以调用GPU分配逻辑
-	// allocateReq := &types.AllocateGpuDeviceReq{
-	//     JobId: jobID,
-	//     GpuCount: req.GpuCount,
-	//     GpuType: req.GpuType,
-	// }
-	// return l.svcCtx.GpuAllocator.Allocate(allocateReq)
-
-	return nil
-}
-
-// submitToScheduleQueue 提交作业到调度队列
-func (l *CreateTrainingJobLogic) submitToScheduleQueue(jobID int64, req *types.CreateTrainingJobReq) error {
-	// 简化实现，实际应该调用调度服务
-	l.Logger.Infof("提交作业 %d 到调度队列 %s", jobID, req.QueueName)
-
-	// 创建调度任务
-	scheduleTask := map[string]interface{}{
-		"job_id":     jobID,
-		"queue_name": req.QueueName,
-		"priority":   req.Priority,
-		"resources": map[string]interface{}{
-			"cpu_cores":  req.CpuCores,
-			"memory_gb":  req.MemoryGb,
-			"gpu_count":  req.GpuCount,
-			"gpu_type":   req.GpuType,
-			"storage_gb": req.StorageGb,
-		},
-		"created_at": time.Now().Unix(),
+// nullIfEmpty 空字符串转换为NULL，避免写入JSON或DECIMAL列失败
+func nullIfEmpty(value string) interface{} {
+	if value == "" {
+		return nil
 	}
+	return value
+}
 
-	// 序列化任务信息
-	taskData, err := json.Marshal(scheduleTask)
-	if err != nil {
-		return fmt.Errorf("序列化调度任务失败: %w", err)
+// nullIfZero 0转换为NULL，用于可为空的ID列
+func nullIfZero(value int64) interface{} {
+	if value == 0 {
+		return nil
 	}
-
-	// 实际环境中应该发送到消息队列或调度服务
-	l.Logger.Infof("调度任务数据: %s", string(taskData))
-
-	return nil
+	return value
 }
@@ -0,0 +1,133 @@
+package training
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/model"
+	bizerrors "api/pkg/errors"
+	"api/pkg/middleware"
+	"api/pkg/sweep"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateTrainingSweepLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 创建超参数搜索
+func NewCreateTrainingSweepLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateTrainingSweepLogic {
+	return &CreateTrainingSweepLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateTrainingSweepLogic) CreateTrainingSweep(req *types.CreateTrainingSweepReq) (resp *types.CreateTrainingSweepResp, err error) {
+	if err := l.validateRequest(req); err != nil {
+		return nil, bizerrors.NewBizError(bizerrors.ErrCodeSweepInvalid, err.Error(), bizerrors.ErrorTypeValidation)
+	}
+
+	userId := middleware.GetUserIDFromContext(l.ctx)
+	_, err = l.svcCtx.VtTrainingSweepsModel.FindOneByName(userId, req.Name)
+	if err == nil {
+		return nil, bizerrors.NewBizError(bizerrors.ErrCodeDuplicateData,
+			fmt.Sprintf("超参数搜索名称 '%s' 已存在", req.Name), bizerrors.ErrorTypeBusiness)
+	}
+	if err != sql.ErrNoRows {
+		l.Logger.Errorf("检查超参数搜索名称失败: %v", err)
+		return nil, err
+	}
+
+	// 并行数不超过试验总数
+	parallelism := req.Parallelism
+	if parallelism > req.MaxTrials {
+		parallelism = req.MaxTrials
+	}
+
+	result, err := l.svcCtx.VtTrainingSweepsModel.Insert(&model.VtTrainingSweeps{
+		Name:            req.Name,
+		DisplayName:     req.DisplayName,
+		Description:     req.Description,
+		OwnerId:         userId,
+		OwnerName:       middleware.GetUsernameFromContext(l.ctx),
+		Algorithm:       req.Algorithm,
+		ObjectiveMetric: req.ObjectiveMetric,
+		ObjectiveGoal:   req.ObjectiveGoal,
+		SearchSpace:     req.SearchSpace,
+		BaseSpec:        req.BaseSpec,
+		MaxTrials:       int(req.MaxTrials),
+		Parallelism:     int(parallelism),
+		EarlyStopping:   req.EarlyStopping,
+	})
+	if err != nil {
+		l.Logger.Errorf("创建超参数搜索失败: %v", err)
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	l.Logger.Infof("超参数搜索创建成功: ID=%d, Name=%s, 算法=%s", id, req.Name, req.Algorithm)
+	return &types.CreateTrainingSweepResp{Id: id}, nil
+}
+
+// validateRequest 校验搜索配置，试验作业的基础规格按创建作业接口的规则校验
+func (l *CreateTrainingSweepLogic) validateRequest(req *types.CreateTrainingSweepReq) error {
+	if req.Name == "" {
+		return fmt.Errorf("超参数搜索名称不能为空")
+	}
+	if req.ObjectiveMetric == "" {
+		return fmt.Errorf("目标指标不能为空")
+	}
+	switch req.ObjectiveGoal {
+	case sweep.GoalMinimize, sweep.GoalMaximize:
+	default:
+		return fmt.Errorf("不支持的优化方向 '%s'", req.ObjectiveGoal)
+	}
+	if req.MaxTrials <= 0 || req.Parallelism <= 0 {
+		return fmt.Errorf("最大试验数和并行数必须大于0")
+	}
+
+	space, err := sweep.ParseSearchSpace(req.SearchSpace)
+	if err != nil {
+		return err
+	}
+	switch req.Algorithm {
+	case sweep.AlgorithmGrid:
+		if _, err := space.GridSize(); err != nil {
+			return err
+		}
+	case sweep.AlgorithmRandom, sweep.AlgorithmBayesian:
+	default:
+		return fmt.Errorf("不支持的搜索算法 '%s'", req.Algorithm)
+	}
+	if _, err := sweep.ParseEarlyStopping(req.EarlyStopping); err != nil {
+		return err
+	}
+
+	// 试验名称由控制器生成，校验时使用搜索名称占位
+	var spec map[string]interface{}
+	if err := json.Unmarshal([]byte(req.BaseSpec), &spec); err != nil {
+		return fmt.Errorf("试验基础规格必须是JSON对象: %v", err)
+	}
+	spec["name"] = req.Name
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	jobReq, err := parseCreateTrainingJobReq(data)
+	if err != nil {
+		return err
+	}
+	return NewCreateTrainingJobLogic(l.ctx, l.svcCtx).validateRequest(jobReq)
+}
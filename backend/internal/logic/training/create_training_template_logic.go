@@ -0,0 +1,86 @@
+package training
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/model"
+	bizerrors "api/pkg/errors"
+	"api/pkg/middleware"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateTrainingTemplateLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 创建训练作业模板
+func NewCreateTrainingTemplateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateTrainingTemplateLogic {
+	return &CreateTrainingTemplateLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateTrainingTemplateLogic) CreateTrainingTemplate(req *types.CreateTrainingTemplateReq) (resp *types.CreateTrainingTemplateResp, err error) {
+	if req.Name == "" {
+		return nil, bizerrors.NewBizError(bizerrors.ErrCodeTemplateInvalid, "模板名称不能为空", bizerrors.ErrorTypeValidation)
+	}
+	parameters, err := validateTemplate(req.Visibility, req.WorkspaceId, req.Spec, req.Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	userId := middleware.GetUserIDFromContext(l.ctx)
+	if req.WorkspaceId > 0 {
+		member, err := l.svcCtx.VtWorkspaceMembersModel.IsActiveMember(req.WorkspaceId, userId)
+		if err != nil {
+			l.Logger.Errorf("查询工作空间成员失败: %v", err)
+			return nil, err
+		}
+		if !member {
+			return nil, bizerrors.ErrPermissionDenied
+		}
+	}
+
+	// 同一用户在同一作用域内模板名称唯一
+	_, err = l.svcCtx.VtTrainingJobTemplatesModel.FindOneByScope(userId, req.WorkspaceId, req.Name)
+	if err == nil {
+		return nil, bizerrors.NewBizError(bizerrors.ErrCodeDuplicateData,
+			fmt.Sprintf("模板名称 '%s' 已存在", req.Name), bizerrors.ErrorTypeBusiness)
+	}
+	if err != sql.ErrNoRows {
+		l.Logger.Errorf("检查模板名称失败: %v", err)
+		return nil, err
+	}
+
+	result, err := l.svcCtx.VtTrainingJobTemplatesModel.Insert(&model.VtTrainingJobTemplates{
+		Name:        req.Name,
+		DisplayName: req.DisplayName,
+		Description: req.Description,
+		OwnerId:     userId,
+		OwnerName:   middleware.GetUsernameFromContext(l.ctx),
+		WorkspaceId: req.WorkspaceId,
+		Visibility:  req.Visibility,
+		Spec:        req.Spec,
+		Parameters:  parameters,
+	})
+	if err != nil {
+		l.Logger.Errorf("创建训练作业模板失败: %v", err)
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	l.Logger.Infof("训练作业模板创建成功: ID=%d, Name=%s", id, req.Name)
+	return &types.CreateTrainingTemplateResp{Id: id}, nil
+}
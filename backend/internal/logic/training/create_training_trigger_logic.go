@@ -0,0 +1,88 @@
+package training
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/model"
+	bizerrors "api/pkg/errors"
+	"api/pkg/middleware"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateTrainingTriggerLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 创建定时触发器
+func NewCreateTrainingTriggerLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateTrainingTriggerLogic {
+	return &CreateTrainingTriggerLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateTrainingTriggerLogic) CreateTrainingTrigger(req *types.CreateTrainingTriggerReq) (resp *types.CreateTrainingTriggerResp, err error) {
+	if req.Name == "" {
+		return nil, invalidTrigger("触发器名称不能为空")
+	}
+
+	userId := middleware.GetUserIDFromContext(l.ctx)
+	if err := checkTriggerTarget(l.svcCtx, req.TargetType, req.TargetId, userId); err != nil {
+		return nil, err
+	}
+
+	// 未指定时区时使用服务配置的时区
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = l.svcCtx.Config.MySQL.Loc
+	}
+	trigger := &model.VtTrainingTriggers{
+		Name:                    req.Name,
+		Description:             req.Description,
+		OwnerId:                 userId,
+		OwnerName:               middleware.GetUsernameFromContext(l.ctx),
+		TargetType:              req.TargetType,
+		TargetId:                req.TargetId,
+		Parameters:              req.Parameters,
+		Schedule:                req.Schedule,
+		Timezone:                timezone,
+		ConcurrencyPolicy:       req.ConcurrencyPolicy,
+		StartingDeadlineSeconds: int(req.StartingDeadlineSeconds),
+		Enabled:                 req.Enabled,
+	}
+	if err := validateTrigger(trigger, time.Now()); err != nil {
+		return nil, err
+	}
+
+	_, err = l.svcCtx.VtTrainingTriggersModel.FindOneByName(userId, req.Name)
+	if err == nil {
+		return nil, bizerrors.NewBizError(bizerrors.ErrCodeDuplicateData,
+			fmt.Sprintf("触发器名称 '%s' 已存在", req.Name), bizerrors.ErrorTypeBusiness)
+	}
+	if err != sql.ErrNoRows {
+		l.Logger.Errorf("检查触发器名称失败: %v", err)
+		return nil, err
+	}
+
+	result, err := l.svcCtx.VtTrainingTriggersModel.Insert(trigger)
+	if err != nil {
+		l.Logger.Errorf("创建定时触发器失败: %v", err)
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	l.Logger.Infof("定时触发器创建成功: ID=%d, Name=%s, 调度=%s(%s)", id, req.Name, req.Schedule, timezone)
+	return &types.CreateTrainingTriggerResp{Id: id, NextRunAt: formatTriggerTime(trigger.NextRunAt, timezone)}, nil
+}
@@ -24,8 +24,19 @@ func NewDeleteCheckpointLogic(ctx context.Context, svcCtx *svc.ServiceContext) *
 	}
 }
 
+// DeleteCheckpoint 删除检查点文件并软删除记录
 func (l *DeleteCheckpointLogic) DeleteCheckpoint(req *types.DeleteCheckpointReq) (resp *types.EmptyResp, err error) {
-	// todo: add your logic here and delete this line
-
-	return
+	c, err := findCheckpoint(l.svcCtx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	manager, err := checkpointManager(l.svcCtx)
+	if err != nil {
+		return nil, err
+	}
+	if err := manager.Delete(l.ctx, c); err != nil {
+		l.Errorf("删除检查点失败: ID=%d, %v", c.Id, err)
+		return nil, toCheckpointError(err)
+	}
+	return &types.EmptyResp{}, nil
 }
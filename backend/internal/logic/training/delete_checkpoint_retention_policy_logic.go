@@ -0,0 +1,40 @@
+package training
+
+import (
+	"context"
+
+	"api/internal/svc"
+	"api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteCheckpointRetentionPolicyLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 删除检查点保留策略
+func NewDeleteCheckpointRetentionPolicyLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteCheckpointRetentionPolicyLogic {
+	return &DeleteCheckpointRetentionPolicyLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteCheckpointRetentionPolicyLogic) DeleteCheckpointRetentionPolicy(req *types.DeleteCheckpointRetentionPolicyReq) (resp *types.EmptyResp, err error) {
+	policy, err := findRetentionPolicy(l.svcCtx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := l.svcCtx.VtCheckpointRetentionPoliciesModel.Delete(policy.Id); err != nil {
+		l.Logger.Errorf("删除检查点保留策略失败: ID=%d, %v", policy.Id, err)
+		return nil, err
+	}
+
+	l.Logger.Infof("检查点保留策略删除成功: ID=%d, 作用范围=%s/%d", policy.Id, policy.ScopeType, policy.ScopeId)
+	return &types.EmptyResp{}, nil
+}
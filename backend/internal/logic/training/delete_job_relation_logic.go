@@ -2,9 +2,12 @@ package training
 
 import (
 	"context"
+	"database/sql"
 
 	"api/internal/svc"
 	"api/internal/types"
+	bizerrors "api/pkg/errors"
+	"api/pkg/scheduler"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -25,7 +28,24 @@ func NewDeleteJobRelationLogic(ctx context.Context, svcCtx *svc.ServiceContext)
 }
 
 func (l *DeleteJobRelationLogic) DeleteJobRelation(req *types.DeleteJobRelationReq) (resp *types.EmptyResp, err error) {
-	// todo: add your logic here and delete this line
+	relation, err := l.svcCtx.VtTrainingJobRelationsModel.FindOne(req.Id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, bizerrors.ErrRelationNotFound
+		}
+		return nil, bizerrors.WrapError(err, bizerrors.ErrCodeDatabaseError, "查询作业关联关系失败")
+	}
+
+	if err := l.svcCtx.VtTrainingJobRelationsModel.Delete(relation.Id); err != nil {
+		l.Errorf("删除作业关联关系失败: ID=%d, %v", relation.Id, err)
+		return nil, bizerrors.WrapError(err, bizerrors.ErrCodeDatabaseError, "删除作业关联关系失败")
+	}
+
+	// 移除依赖后子作业可能已满足派发条件
+	if relation.RelationType == scheduler.DependsOnRelation && l.svcCtx.JobDispatcher != nil {
+		l.svcCtx.JobDispatcher.Notify()
+	}
 
-	return
+	l.Infof("作业关联关系已删除: ID=%d, JobID=%d", relation.Id, relation.JobId)
+	return &types.EmptyResp{}, nil
 }
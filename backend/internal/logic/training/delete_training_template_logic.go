@@ -0,0 +1,38 @@
+package training
+
+import (
+	"context"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/pkg/middleware"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteTrainingTemplateLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 删除训练作业模板
+func NewDeleteTrainingTemplateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteTrainingTemplateLogic {
+	return &DeleteTrainingTemplateLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteTrainingTemplateLogic) DeleteTrainingTemplate(req *types.DeleteTrainingTemplateReq) (resp *types.EmptyResp, err error) {
+	if _, err := findOwnedTemplate(l.svcCtx, req.Id, middleware.GetUserIDFromContext(l.ctx)); err != nil {
+		return nil, err
+	}
+
+	if err := l.svcCtx.VtTrainingJobTemplatesModel.Delete(req.Id); err != nil {
+		l.Logger.Errorf("删除训练作业模板失败: ID=%d, %v", req.Id, err)
+		return nil, err
+	}
+	return &types.EmptyResp{}, nil
+}
@@ -0,0 +1,41 @@
+package training
+
+import (
+	"context"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/pkg/middleware"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteTrainingTriggerLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 删除定时触发器
+func NewDeleteTrainingTriggerLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteTrainingTriggerLogic {
+	return &DeleteTrainingTriggerLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteTrainingTriggerLogic) DeleteTrainingTrigger(req *types.DeleteTrainingTriggerReq) (resp *types.EmptyResp, err error) {
+	trigger, err := findOwnedTrigger(l.svcCtx, req.Id, middleware.GetUserIDFromContext(l.ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := l.svcCtx.VtTrainingTriggersModel.Delete(trigger.Id); err != nil {
+		l.Logger.Errorf("删除定时触发器失败: ID=%d, %v", trigger.Id, err)
+		return nil, err
+	}
+
+	l.Logger.Infof("定时触发器删除成功: ID=%d, Name=%s", trigger.Id, trigger.Name)
+	return &types.EmptyResp{}, nil
+}
@@ -0,0 +1,53 @@
+package training
+
+import (
+	"context"
+	"mime"
+	"net/http"
+
+	"api/internal/svc"
+	"api/internal/types"
+	bizerrors "api/pkg/errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DownloadJobLogsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 下载作业归档日志（tar.gz）
+func NewDownloadJobLogsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DownloadJobLogsLogic {
+	return &DownloadJobLogsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DownloadJobLogs 将作业已归档的日志打包为tar.gz下载，每个实例一个日志文件
+// 开始写入响应后出错只能中断下载，错误在写入响应前尽量提前返回
+func (l *DownloadJobLogsLogic) DownloadJobLogs(w http.ResponseWriter, req *types.DownloadJobLogsReq) error {
+	job, err := findJob(l.svcCtx, req.JobId)
+	if err != nil {
+		return err
+	}
+	chunks, err := l.svcCtx.LogArchive.Chunks(job.Id)
+	if err != nil {
+		l.Errorf("读取归档日志失败: ID=%d, %v", job.Id, err)
+		return err
+	}
+	if len(chunks) == 0 {
+		return bizerrors.ErrLogArchiveNotFound
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": job.Name + "-logs.tar.gz"}))
+	w.WriteHeader(http.StatusOK)
+	if err := l.svcCtx.LogArchive.WriteTarball(w, job.Id, job.Name); err != nil {
+		l.Errorf("打包归档日志失败: ID=%d, %v", job.Id, err)
+	}
+	return nil
+}
@@ -25,7 +25,9 @@ func NewGetCheckpointLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Get
 }
 
 func (l *GetCheckpointLogic) GetCheckpoint(req *types.GetCheckpointReq) (resp *types.GetCheckpointResp, err error) {
-	// todo: add your logic here and delete this line
-
-	return
+	c, err := findCheckpoint(l.svcCtx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &types.GetCheckpointResp{Checkpoint: toTrainingCheckpointInfo(c)}, nil
 }
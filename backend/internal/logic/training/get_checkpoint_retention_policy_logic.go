@@ -0,0 +1,33 @@
+package training
+
+import (
+	"context"
+
+	"api/internal/svc"
+	"api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetCheckpointRetentionPolicyLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取检查点保留策略详情
+func NewGetCheckpointRetentionPolicyLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetCheckpointRetentionPolicyLogic {
+	return &GetCheckpointRetentionPolicyLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetCheckpointRetentionPolicyLogic) GetCheckpointRetentionPolicy(req *types.GetCheckpointRetentionPolicyReq) (resp *types.GetCheckpointRetentionPolicyResp, err error) {
+	policy, err := findRetentionPolicy(l.svcCtx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &types.GetCheckpointRetentionPolicyResp{Policy: toCheckpointRetentionPolicyInfo(policy)}, nil
+}
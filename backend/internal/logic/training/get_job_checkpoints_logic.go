@@ -5,6 +5,7 @@ import (
 
 	"api/internal/svc"
 	"api/internal/types"
+	"api/model"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -24,8 +25,38 @@ func NewGetJobCheckpointsLogic(ctx context.Context, svcCtx *svc.ServiceContext)
 	}
 }
 
+// GetJobCheckpoints 按步数从新到旧分页返回作业的检查点，不包括已删除的检查点
 func (l *GetJobCheckpointsLogic) GetJobCheckpoints(req *types.GetJobCheckpointsReq) (resp *types.GetJobCheckpointsResp, err error) {
-	// todo: add your logic here and delete this line
+	job, err := findJob(l.svcCtx, req.JobId)
+	if err != nil {
+		return nil, err
+	}
+	page, pageSize := int(req.Page), int(req.PageSize)
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 10
+	}
 
-	return
+	checkpoints, total, err := l.svcCtx.VtTrainingCheckpointsModel.List(model.TrainingCheckpointFilter{
+		JobId:          job.Id,
+		CheckpointType: req.CheckpointType,
+		Status:         req.Status,
+		IsBest:         req.IsBest,
+		IsLatest:       req.IsLatest,
+	}, page, pageSize)
+	if err != nil {
+		l.Errorf("查询检查点列表失败: 作业ID=%d, %v", job.Id, err)
+		return nil, err
+	}
+
+	resp = &types.GetJobCheckpointsResp{
+		Total:       total,
+		Checkpoints: make([]types.TrainingCheckpointInfo, 0, len(checkpoints)),
+	}
+	for _, c := range checkpoints {
+		resp.Checkpoints = append(resp.Checkpoints, toTrainingCheckpointInfo(c))
+	}
+	return resp, nil
 }
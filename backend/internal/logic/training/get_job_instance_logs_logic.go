@@ -2,13 +2,22 @@ package training
 
 import (
 	"context"
+	"database/sql"
+	"net/http"
+	"strings"
+	"time"
 
 	"api/internal/svc"
 	"api/internal/types"
+	bizerrors "api/pkg/errors"
+	"api/pkg/logstream"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
+// maxInstanceLogLines 一次返回的实例日志最大行数
+const maxInstanceLogLines = 10000
+
 type GetJobInstanceLogsLogic struct {
 	logx.Logger
 	ctx    context.Context
@@ -24,8 +33,81 @@ func NewGetJobInstanceLogsLogic(ctx context.Context, svcCtx *svc.ServiceContext)
 	}
 }
 
+// GetJobInstanceLogs 读取实例容器最近的日志
 func (l *GetJobInstanceLogsLogic) GetJobInstanceLogs(req *types.GetJobInstanceLogsReq) (resp *types.GetJobInstanceLogsResp, err error) {
-	// todo: add your logic here and delete this line
+	if req.Follow {
+		return nil, errFollowNeedsStream
+	}
+	opts, err := l.instanceLogOptions(req, "")
+	if err != nil {
+		return nil, err
+	}
+	if opts.TailLines <= 0 || opts.TailLines > maxInstanceLogLines {
+		opts.TailLines = maxInstanceLogLines
+	}
+
+	collector := &logCollector{timestamp: req.Timestamp}
+	if err := l.svcCtx.LogStreamer.Stream(l.ctx, opts, collector); err != nil {
+		l.Errorf("读取实例日志失败: ID=%d, %v", req.Id, err)
+		return nil, err
+	}
+	return &types.GetJobInstanceLogsResp{Logs: collector.String()}, nil
+}
 
-	return
+// StreamJobInstanceLogs 以SSE或WebSocket推送实例的实时日志
+func (l *GetJobInstanceLogsLogic) StreamJobInstanceLogs(w http.ResponseWriter, r *http.Request, req *types.GetJobInstanceLogsReq) error {
+	opts, err := l.instanceLogOptions(req, r.Header.Get("Last-Event-ID"))
+	if err != nil {
+		return err
+	}
+	serveLogStream(w, r, l.svcCtx.LogStreamer, opts, l.Logger)
+	return nil
 }
+
+// instanceLogOptions 构造读取单个实例日志的参数
+func (l *GetJobInstanceLogsLogic) instanceLogOptions(req *types.GetJobInstanceLogsReq, offset string) (logstream.Options, error) {
+	instance, err := l.svcCtx.VtTrainingJobInstancesModel.FindOne(req.Id)
+	if err == sql.ErrNoRows {
+		return logstream.Options{}, bizerrors.ErrInstanceNotFound
+	}
+	if err != nil {
+		return logstream.Options{}, err
+	}
+	job, err := findJob(l.svcCtx, instance.JobId)
+	if err != nil {
+		return logstream.Options{}, err
+	}
+
+	opts, err := newJobLogOptions(l.svcCtx, job, offset)
+	if err != nil {
+		return logstream.Options{}, err
+	}
+	opts.Pod = instance.PodName
+	opts.Follow = req.Follow
+	opts.SinceSeconds = req.SinceSeconds
+	opts.TailLines = req.Lines
+	opts.MinLevel = req.Level
+	return opts, nil
+}
+
+// logCollector 将日志行拼接为文本
+type logCollector struct {
+	timestamp bool
+	builder   strings.Builder
+}
+
+func (c *logCollector) WriteLine(line *logstream.Line) error {
+	if c.timestamp && !line.Time.IsZero() {
+		c.builder.WriteString(line.Time.Format(time.RFC3339Nano))
+		c.builder.WriteByte(' ')
+	}
+	c.builder.WriteString(line.Content)
+	c.builder.WriteByte('\n')
+	return nil
+}
+
+func (c *logCollector) Ping() error { return nil }
+
+func (c *logCollector) WriteEnd(string) error { return nil }
+
+func (c *logCollector) String() string { return c.builder.String() }
@@ -2,9 +2,13 @@ package training
 
 import (
 	"context"
+	"net/http"
 
 	"api/internal/svc"
 	"api/internal/types"
+	"api/model"
+	bizerrors "api/pkg/errors"
+	"api/pkg/logstream"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -24,8 +28,90 @@ func NewGetJobLogsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetJob
 	}
 }
 
+// GetJobLogs 分页查询已入库的作业日志，level为最低日志级别
 func (l *GetJobLogsLogic) GetJobLogs(req *types.GetJobLogsReq) (resp *types.GetJobLogsResp, err error) {
-	// todo: add your logic here and delete this line
+	if req.Follow {
+		return nil, errFollowNeedsStream
+	}
+	if _, err := findJob(l.svcCtx, req.JobId); err != nil {
+		return nil, err
+	}
+
+	filter := model.TrainingLogFilter{
+		JobId:    req.JobId,
+		Levels:   logstream.LevelsAtLeast(req.Level),
+		Source:   req.Source,
+		Category: req.Category,
+		Keyword:  req.Search,
+	}
+	if filter.StartTime, err = parseLogTime(req.StartTime); err != nil {
+		return nil, err
+	}
+	if filter.EndTime, err = parseLogTime(req.EndTime); err != nil {
+		return nil, err
+	}
+	if req.Instance != "" {
+		instance, err := l.findInstance(req.JobId, req.Instance)
+		if err != nil {
+			return nil, err
+		}
+		filter.InstanceId = instance.Id
+	}
+
+	logs, total, err := l.svcCtx.VtTrainingLogsModel.List(filter, int(req.Page), int(req.PageSize))
+	if err != nil {
+		l.Errorf("查询训练作业日志失败: ID=%d, %v", req.JobId, err)
+		return nil, err
+	}
+
+	resp = &types.GetJobLogsResp{
+		Total: total,
+		Logs:  make([]types.TrainingLogInfo, 0, len(logs)),
+	}
+	for _, log := range logs {
+		resp.Logs = append(resp.Logs, toTrainingLogInfo(log))
+	}
+	return resp, nil
+}
+
+// StreamJobLogs 通过Kubernetes Pod日志接口读取作业全部副本的实时日志，以SSE或WebSocket推送
+// follow为true时持续推送直到作业结束；断线重连时通过offset参数或Last-Event-ID从上次的位置继续
+func (l *GetJobLogsLogic) StreamJobLogs(w http.ResponseWriter, r *http.Request, req *types.GetJobLogsReq) error {
+	job, err := findJob(l.svcCtx, req.JobId)
+	if err != nil {
+		return err
+	}
 
-	return
+	offset := req.Offset
+	if offset == "" {
+		offset = r.Header.Get("Last-Event-ID")
+	}
+	opts, err := newJobLogOptions(l.svcCtx, job, offset)
+	if err != nil {
+		return err
+	}
+	opts.Pod = req.Instance
+	opts.Task = req.InstanceType
+	opts.Follow = req.Follow
+	opts.SinceSeconds = req.SinceSeconds
+	opts.TailLines = req.TailLines
+	opts.MinLevel = req.Level
+	opts.Keyword = req.Search
+
+	serveLogStream(w, r, l.svcCtx.LogStreamer, opts, l.Logger)
+	return nil
+}
+
+// findInstance 按实例名查询作业实例
+func (l *GetJobLogsLogic) findInstance(jobId int64, name string) (*model.VtTrainingJobInstances, error) {
+	instances, err := l.svcCtx.VtTrainingJobInstancesModel.FindByJobId(jobId)
+	if err != nil {
+		return nil, err
+	}
+	for _, instance := range instances {
+		if instance.InstanceName == name {
+			return instance, nil
+		}
+	}
+	return nil, bizerrors.ErrInstanceNotFound
 }
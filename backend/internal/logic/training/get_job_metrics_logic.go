@@ -5,6 +5,7 @@ import (
 
 	"api/internal/svc"
 	"api/internal/types"
+	"api/model"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -24,8 +25,56 @@ func NewGetJobMetricsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Get
 	}
 }
 
+// GetJobMetrics 分页返回原始指标，并返回按步数降采样后的标量曲线供图表展示
 func (l *GetJobMetricsLogic) GetJobMetrics(req *types.GetJobMetricsReq) (resp *types.GetJobMetricsResp, err error) {
-	// todo: add your logic here and delete this line
+	job, err := findJob(l.svcCtx, req.JobId)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := model.TrainingMetricFilter{
+		JobId:      job.Id,
+		MetricName: req.MetricName,
+		Category:   req.Category,
+		StartStep:  req.StartStep,
+		EndStep:    req.EndStep,
+	}
+	if filter.Phase, err = normalizeMetricPhase(req.Phase); err != nil {
+		return nil, invalidParam(err.Error())
+	}
+	if filter.StartTime, err = parseLogTime(req.StartTime); err != nil {
+		return nil, err
+	}
+	if filter.EndTime, err = parseLogTime(req.EndTime); err != nil {
+		return nil, err
+	}
+
+	metrics, total, err := l.svcCtx.VtTrainingMetricsModel.List(filter, int(req.Page), int(req.PageSize))
+	if err != nil {
+		l.Errorf("查询训练指标失败: %v", err)
+		return nil, err
+	}
+
+	maxPoints := int(req.MaxPoints)
+	if maxPoints <= 0 {
+		maxPoints = l.svcCtx.Config.Training.MetricsSeriesPoints
+	}
+	series, err := l.svcCtx.VtTrainingMetricsModel.FindSeries(filter, min(maxPoints, maxSeriesPoints))
+	if err != nil {
+		l.Errorf("查询训练指标曲线失败: %v", err)
+		return nil, err
+	}
 
-	return
+	resp = &types.GetJobMetricsResp{
+		Total:   total,
+		Metrics: make([]types.TrainingMetricInfo, 0, len(metrics)),
+		Series:  make([]types.MetricSeriesInfo, 0, len(series)),
+	}
+	for _, m := range metrics {
+		resp.Metrics = append(resp.Metrics, toTrainingMetricInfo(m))
+	}
+	for _, s := range series {
+		resp.Series = append(resp.Series, toMetricSeriesInfo(s))
+	}
+	return resp, nil
 }
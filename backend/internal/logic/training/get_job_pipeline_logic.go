@@ -0,0 +1,53 @@
+package training
+
+import (
+	"context"
+
+	"api/internal/svc"
+	"api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetJobPipelineLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取作业所在流水线
+func NewGetJobPipelineLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetJobPipelineLogic {
+	return &GetJobPipelineLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetJobPipelineLogic) GetJobPipeline(req *types.GetJobPipelineReq) (resp *types.GetJobPipelineResp, err error) {
+	pipeline, err := l.svcCtx.JobPipeline.Graph(req.JobId)
+	if err != nil {
+		l.Errorf("查询作业流水线失败: JobID=%d, %v", req.JobId, err)
+		return nil, err
+	}
+
+	resp = &types.GetJobPipelineResp{
+		Status: pipeline.Status,
+		Nodes:  make([]types.PipelineNodeInfo, 0, len(pipeline.Nodes)),
+	}
+	for _, node := range pipeline.Nodes {
+		dependsOn := node.DependsOn
+		if dependsOn == nil {
+			dependsOn = []int64{}
+		}
+		resp.Nodes = append(resp.Nodes, types.PipelineNodeInfo{
+			JobId:         node.JobId,
+			JobName:       node.JobName,
+			Status:        node.Status,
+			State:         node.State,
+			FailureReason: node.FailureReason,
+			DependsOn:     dependsOn,
+		})
+	}
+	return resp, nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"api/internal/svc"
 	"api/internal/types"
+	bizerrors "api/pkg/errors"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -25,7 +26,18 @@ func NewGetJobRelationsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *G
 }
 
 func (l *GetJobRelationsLogic) GetJobRelations(req *types.GetJobRelationsReq) (resp *types.GetJobRelationsResp, err error) {
-	// todo: add your logic here and delete this line
+	relations, err := l.svcCtx.VtTrainingJobRelationsModel.FindByJobId(req.JobId, req.EntityType, req.RelationType)
+	if err != nil {
+		l.Errorf("查询作业关联关系失败: JobID=%d, %v", req.JobId, err)
+		return nil, bizerrors.WrapError(err, bizerrors.ErrCodeDatabaseError, "查询作业关联关系失败")
+	}
 
-	return
+	resp = &types.GetJobRelationsResp{Relations: make([]types.TrainingJobRelationInfo, 0, len(relations))}
+	for _, relation := range relations {
+		if req.Status != "" && relation.Status != req.Status {
+			continue
+		}
+		resp.Relations = append(resp.Relations, toTrainingJobRelationInfo(relation))
+	}
+	return resp, nil
 }
@@ -0,0 +1,65 @@
+package training
+
+import (
+	"context"
+	"encoding/json"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/pkg/middleware"
+	"api/pkg/scheduler"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetSweepLeaderboardLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取超参数搜索排行榜
+func NewGetSweepLeaderboardLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetSweepLeaderboardLogic {
+	return &GetSweepLeaderboardLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetSweepLeaderboardLogic) GetSweepLeaderboard(req *types.GetSweepLeaderboardReq) (resp *types.GetSweepLeaderboardResp, err error) {
+	sweep, err := findOwnedSweep(l.svcCtx, req.Id, middleware.GetUserIDFromContext(l.ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	trials, err := l.svcCtx.SweepTracker.Trials(sweep)
+	if err != nil {
+		l.Logger.Errorf("查询超参数搜索试验失败: ID=%d, %v", sweep.Id, err)
+		return nil, err
+	}
+
+	resp = &types.GetSweepLeaderboardResp{
+		ObjectiveMetric: sweep.ObjectiveMetric,
+		ObjectiveGoal:   sweep.ObjectiveGoal,
+		Trials:          make([]types.SweepTrialInfo, 0, len(trials)),
+	}
+	for i, trial := range scheduler.Leaderboard(trials, sweep.ObjectiveGoal) {
+		params, _ := json.Marshal(trial.Params)
+		info := types.SweepTrialInfo{
+			Rank:     int64(i + 1),
+			Trial:    int64(trial.Index + 1),
+			JobId:    trial.JobId,
+			JobName:  trial.JobName,
+			Status:   trial.Status,
+			Pruned:   trial.Pruned(),
+			Params:   string(params),
+			LastStep: trial.LastStep,
+		}
+		if trial.Objective != nil {
+			info.Objective = formatFloat(*trial.Objective)
+		}
+		resp.Trials = append(resp.Trials, info)
+	}
+	return resp, nil
+}
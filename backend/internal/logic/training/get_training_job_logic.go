@@ -2,9 +2,12 @@ package training
 
 import (
 	"context"
+	"database/sql"
 
 	"api/internal/svc"
 	"api/internal/types"
+	bizerrors "api/pkg/errors"
+	"api/pkg/scheduler"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -25,7 +28,42 @@ func NewGetTrainingJobLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Ge
 }
 
 func (l *GetTrainingJobLogic) GetTrainingJob(req *types.GetTrainingJobReq) (resp *types.GetTrainingJobResp, err error) {
-	// todo: add your logic here and delete this line
+	job, err := l.svcCtx.VtTrainingJobsModel.FindOneDetail(req.Id)
+	if err == sql.ErrNoRows {
+		return nil, bizerrors.ErrJobNotFound
+	}
+	if err != nil {
+		l.Errorf("查询训练作业失败: ID=%d, %v", req.Id, err)
+		return nil, err
+	}
 
-	return
+	retries, err := l.svcCtx.VtTrainingJobRetriesModel.FindByJobId(req.Id)
+	if err != nil {
+		l.Errorf("查询训练作业重试记录失败: ID=%d, %v", req.Id, err)
+		return nil, err
+	}
+
+	transitions, err := l.svcCtx.VtTrainingJobTransitionsModel.FindByJobId(req.Id)
+	if err != nil {
+		l.Errorf("查询训练作业状态变更记录失败: ID=%d, %v", req.Id, err)
+		return nil, err
+	}
+
+	resp = &types.GetTrainingJobResp{
+		Job:        toTrainingJobInfo(job),
+		RetryCount: int64(len(retries)),
+		Retries:    make([]types.TrainingJobRetryInfo, 0, len(retries)),
+		Runs:       make([]types.TrainingJobRunInfo, 0),
+	}
+	for _, retry := range retries {
+		resp.Retries = append(resp.Retries, toTrainingJobRetryInfo(retry))
+	}
+	// 直接恢复原Volcano作业的resume不产生新的运行
+	for _, t := range transitions {
+		if t.Action == scheduler.JobActionRestart || t.Action == scheduler.JobActionRetry ||
+			t.Action == scheduler.JobActionResume && t.ResumeCheckpointId > 0 {
+			resp.Runs = append(resp.Runs, toTrainingJobRunInfo(t))
+		}
+	}
+	return resp, nil
 }
@@ -0,0 +1,34 @@
+package training
+
+import (
+	"context"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/pkg/middleware"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetTrainingSweepLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取超参数搜索详情
+func NewGetTrainingSweepLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetTrainingSweepLogic {
+	return &GetTrainingSweepLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetTrainingSweepLogic) GetTrainingSweep(req *types.GetTrainingSweepReq) (resp *types.GetTrainingSweepResp, err error) {
+	sweep, err := findOwnedSweep(l.svcCtx, req.Id, middleware.GetUserIDFromContext(l.ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &types.GetTrainingSweepResp{Sweep: toTrainingSweepInfo(sweep)}, nil
+}
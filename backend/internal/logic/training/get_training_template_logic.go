@@ -0,0 +1,34 @@
+package training
+
+import (
+	"context"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/pkg/middleware"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetTrainingTemplateLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取训练作业模板详情
+func NewGetTrainingTemplateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetTrainingTemplateLogic {
+	return &GetTrainingTemplateLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetTrainingTemplateLogic) GetTrainingTemplate(req *types.GetTrainingTemplateReq) (resp *types.GetTrainingTemplateResp, err error) {
+	template, err := findVisibleTemplate(l.svcCtx, req.Id, middleware.GetUserIDFromContext(l.ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &types.GetTrainingTemplateResp{Template: toTrainingTemplateInfo(template)}, nil
+}
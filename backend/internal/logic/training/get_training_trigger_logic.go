@@ -0,0 +1,34 @@
+package training
+
+import (
+	"context"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/pkg/middleware"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetTrainingTriggerLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取定时触发器详情
+func NewGetTrainingTriggerLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetTrainingTriggerLogic {
+	return &GetTrainingTriggerLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetTrainingTriggerLogic) GetTrainingTrigger(req *types.GetTrainingTriggerReq) (resp *types.GetTrainingTriggerResp, err error) {
+	trigger, err := findOwnedTrigger(l.svcCtx, req.Id, middleware.GetUserIDFromContext(l.ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &types.GetTrainingTriggerResp{Trigger: toTrainingTriggerInfo(trigger)}, nil
+}
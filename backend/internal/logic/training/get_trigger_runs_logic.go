@@ -0,0 +1,48 @@
+package training
+
+import (
+	"context"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/pkg/middleware"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetTriggerRunsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取定时触发记录
+func NewGetTriggerRunsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetTriggerRunsLogic {
+	return &GetTriggerRunsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetTriggerRunsLogic) GetTriggerRuns(req *types.GetTriggerRunsReq) (resp *types.GetTriggerRunsResp, err error) {
+	trigger, err := findOwnedTrigger(l.svcCtx, req.Id, middleware.GetUserIDFromContext(l.ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	runs, total, err := l.svcCtx.VtTrainingTriggerRunsModel.FindByTriggerId(trigger.Id, req.Status, int(req.Page), int(req.PageSize))
+	if err != nil {
+		l.Logger.Errorf("查询定时触发记录失败: ID=%d, %v", trigger.Id, err)
+		return nil, err
+	}
+
+	resp = &types.GetTriggerRunsResp{
+		Total: total,
+		Runs:  make([]types.TriggerRunInfo, 0, len(runs)),
+	}
+	for _, run := range runs {
+		resp.Runs = append(resp.Runs, toTriggerRunInfo(run, trigger.Timezone))
+	}
+	return resp, nil
+}
@@ -0,0 +1,42 @@
+package training
+
+import (
+	"context"
+
+	"api/internal/svc"
+	"api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListCheckpointRetentionPoliciesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取检查点保留策略列表
+func NewListCheckpointRetentionPoliciesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListCheckpointRetentionPoliciesLogic {
+	return &ListCheckpointRetentionPoliciesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListCheckpointRetentionPoliciesLogic) ListCheckpointRetentionPolicies(req *types.ListCheckpointRetentionPoliciesReq) (resp *types.ListCheckpointRetentionPoliciesResp, err error) {
+	policies, total, err := l.svcCtx.VtCheckpointRetentionPoliciesModel.List(req.ScopeType, int(req.Page), int(req.PageSize))
+	if err != nil {
+		l.Logger.Errorf("查询检查点保留策略列表失败: %v", err)
+		return nil, err
+	}
+
+	resp = &types.ListCheckpointRetentionPoliciesResp{
+		Total:    total,
+		Policies: make([]types.CheckpointRetentionPolicyInfo, 0, len(policies)),
+	}
+	for _, policy := range policies {
+		resp.Policies = append(resp.Policies, toCheckpointRetentionPolicyInfo(policy))
+	}
+	return resp, nil
+}
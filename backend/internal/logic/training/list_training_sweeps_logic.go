@@ -0,0 +1,44 @@
+package training
+
+import (
+	"context"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/pkg/middleware"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListTrainingSweepsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取超参数搜索列表
+func NewListTrainingSweepsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListTrainingSweepsLogic {
+	return &ListTrainingSweepsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListTrainingSweepsLogic) ListTrainingSweeps(req *types.ListTrainingSweepsReq) (resp *types.ListTrainingSweepsResp, err error) {
+	sweeps, total, err := l.svcCtx.VtTrainingSweepsModel.List(middleware.GetUserIDFromContext(l.ctx),
+		req.Status, req.Search, int(req.Page), int(req.PageSize))
+	if err != nil {
+		l.Logger.Errorf("查询超参数搜索列表失败: %v", err)
+		return nil, err
+	}
+
+	resp = &types.ListTrainingSweepsResp{
+		Total:  total,
+		Sweeps: make([]types.TrainingSweepInfo, 0, len(sweeps)),
+	}
+	for _, sweep := range sweeps {
+		resp.Sweeps = append(resp.Sweeps, toTrainingSweepInfo(sweep))
+	}
+	return resp, nil
+}
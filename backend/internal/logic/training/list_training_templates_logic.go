@@ -0,0 +1,44 @@
+package training
+
+import (
+	"context"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/pkg/middleware"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListTrainingTemplatesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取训练作业模板列表
+func NewListTrainingTemplatesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListTrainingTemplatesLogic {
+	return &ListTrainingTemplatesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListTrainingTemplatesLogic) ListTrainingTemplates(req *types.ListTrainingTemplatesReq) (resp *types.ListTrainingTemplatesResp, err error) {
+	templates, total, err := l.svcCtx.VtTrainingJobTemplatesModel.List(middleware.GetUserIDFromContext(l.ctx),
+		req.WorkspaceId, req.Search, int(req.Page), int(req.PageSize))
+	if err != nil {
+		l.Logger.Errorf("查询训练作业模板列表失败: %v", err)
+		return nil, err
+	}
+
+	resp = &types.ListTrainingTemplatesResp{
+		Total:     total,
+		Templates: make([]types.TrainingTemplateInfo, 0, len(templates)),
+	}
+	for _, template := range templates {
+		resp.Templates = append(resp.Templates, toTrainingTemplateInfo(template))
+	}
+	return resp, nil
+}
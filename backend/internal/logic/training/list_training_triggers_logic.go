@@ -0,0 +1,44 @@
+package training
+
+import (
+	"context"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/pkg/middleware"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListTrainingTriggersLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取定时触发器列表
+func NewListTrainingTriggersLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListTrainingTriggersLogic {
+	return &ListTrainingTriggersLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListTrainingTriggersLogic) ListTrainingTriggers(req *types.ListTrainingTriggersReq) (resp *types.ListTrainingTriggersResp, err error) {
+	triggers, total, err := l.svcCtx.VtTrainingTriggersModel.List(middleware.GetUserIDFromContext(l.ctx),
+		req.TargetType, req.Search, int(req.Page), int(req.PageSize))
+	if err != nil {
+		l.Logger.Errorf("查询定时触发器列表失败: %v", err)
+		return nil, err
+	}
+
+	resp = &types.ListTrainingTriggersResp{
+		Total:    total,
+		Triggers: make([]types.TrainingTriggerInfo, 0, len(triggers)),
+	}
+	for _, trigger := range triggers {
+		resp.Triggers = append(resp.Triggers, toTrainingTriggerInfo(trigger))
+	}
+	return resp, nil
+}
@@ -0,0 +1,89 @@
+package training
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"api/internal/svc"
+	"api/internal/types"
+	bizerrors "api/pkg/errors"
+	"api/pkg/scheduler"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// tensorboardTokenCookie JWT中间件从该Cookie读取令牌
+const tensorboardTokenCookie = "access_token"
+
+type ProxyTensorboardLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 访问作业TensorBoard
+func NewProxyTensorboardLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ProxyTensorboardLogic {
+	return &ProxyTensorboardLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ProxyTensorboard 将请求转发到作业的TensorBoard
+// 浏览器携带?token=打开页面时，令牌写入仅对该作业TensorBoard路径有效的Cookie后重定向去掉令牌，
+// TensorBoard页面随后发起的请求由JWT中间件从Cookie中读取令牌
+func (l *ProxyTensorboardLogic) ProxyTensorboard(w http.ResponseWriter, r *http.Request, req *types.ProxyTensorboardReq) error {
+	job, err := findJob(l.svcCtx, req.JobId)
+	if err != nil {
+		return err
+	}
+	if !job.EnableTensorboard || job.TensorboardPath == "" || l.svcCtx.JobManager == nil {
+		return bizerrors.ErrTensorboardNotFound
+	}
+
+	name := scheduler.TensorboardName(job)
+	exists, ready, err := l.svcCtx.JobManager.GetTensorboardStatus(job.Namespace, name)
+	if err != nil {
+		l.Errorf("查询TensorBoard状态失败: ID=%d, %v", job.Id, err)
+		return err
+	}
+	if !exists {
+		return bizerrors.ErrTensorboardNotFound
+	}
+	if !ready {
+		return bizerrors.ErrTensorboardStarting
+	}
+
+	prefix := scheduler.TensorboardPathPrefix(job.Id)
+	query := r.URL.Query()
+	token := query.Get("token")
+	if token != "" {
+		http.SetCookie(w, &http.Cookie{
+			Name:     tensorboardTokenCookie,
+			Value:    token,
+			Path:     prefix + "/",
+			HttpOnly: true,
+			Secure:   r.TLS != nil,
+			SameSite: http.SameSiteLaxMode,
+		})
+		query.Del("token")
+	}
+	// TensorBoard页面使用相对路径，入口地址需要以/结尾
+	if token != "" || r.URL.Path == prefix {
+		location := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+		if location.Path == prefix {
+			location.Path += "/"
+		}
+		http.Redirect(w, r, location.String(), http.StatusFound)
+		return nil
+	}
+
+	target, err := url.Parse(l.svcCtx.JobManager.TensorboardURL(job.Namespace, name))
+	if err != nil {
+		return err
+	}
+	scheduler.NewTensorboardProxy(target, l.Logger).ServeHTTP(w, r)
+	return nil
+}
@@ -0,0 +1,93 @@
+package training
+
+import (
+	"context"
+	"fmt"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/model"
+	bizerrors "api/pkg/errors"
+	"api/pkg/metrics"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// maxPushErrors 上报响应中最多返回的错误条数
+const maxPushErrors = 20
+
+type PushJobMetricsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 训练容器上报作业指标
+func NewPushJobMetricsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PushJobMetricsLogic {
+	return &PushJobMetricsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PushJobMetrics 批量写入训练容器上报的指标
+// 请求以作业令牌（环境变量VOLCTRAIN_METRICS_TOKEN）认证，不合法的指标被跳过并在响应中说明原因，其余指标仍然写入
+// 作业被请求提前停止时响应中的stopRequested为true，训练脚本应保存最终检查点后正常退出
+func (l *PushJobMetricsLogic) PushJobMetrics(req *types.PushJobMetricsReq) (resp *types.PushJobMetricsResp, err error) {
+	if !metrics.VerifyJobToken(l.svcCtx.MetricsTokenSecret(), req.JobId, req.Authorization) {
+		return nil, bizerrors.NewAuthError("指标上报令牌无效")
+	}
+	job, err := findJob(l.svcCtx, req.JobId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.Metrics) == 0 {
+		return nil, invalidParam("指标列表不能为空")
+	}
+	if limit := l.svcCtx.Config.Training.MaxMetricsPerPush; limit > 0 && len(req.Metrics) > limit {
+		return nil, invalidParam(fmt.Sprintf("单次最多上报%d条指标", limit))
+	}
+
+	var instanceId int64
+	if req.Instance != "" {
+		instances, err := l.svcCtx.VtTrainingJobInstancesModel.FindByJobId(job.Id)
+		if err != nil {
+			l.Errorf("查询作业实例失败: %v", err)
+			return nil, err
+		}
+		for _, instance := range instances {
+			if instance.InstanceName == req.Instance {
+				instanceId = instance.Id
+				break
+			}
+		}
+	}
+
+	resp = &types.PushJobMetricsResp{Errors: []string{}}
+	accepted := make([]*model.VtTrainingMetrics, 0, len(req.Metrics))
+	for i := range req.Metrics {
+		metric, err := buildTrainingMetric(job, instanceId, &req.Metrics[i])
+		if err != nil {
+			resp.Rejected++
+			if len(resp.Errors) < maxPushErrors {
+				resp.Errors = append(resp.Errors, fmt.Sprintf("metrics[%d]: %v", i, err))
+			}
+			continue
+		}
+		accepted = append(accepted, metric)
+	}
+
+	if len(accepted) > 0 {
+		if err := l.svcCtx.VtTrainingMetricsModel.BatchInsert(accepted); err != nil {
+			l.Errorf("写入训练指标失败: jobId=%d, %v", job.Id, err)
+			return nil, err
+		}
+	}
+	resp.Accepted = int64(len(accepted))
+	if l.svcCtx.EarlyStopper != nil {
+		resp.StopReason, resp.StopRequested = l.svcCtx.EarlyStopper.StopRequested(job)
+	}
+	return resp, nil
+}
@@ -0,0 +1,48 @@
+package training
+
+import (
+	"context"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/pkg/middleware"
+	"api/pkg/scheduler"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RerunPipelineNodeLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 重新运行流水线节点
+func NewRerunPipelineNodeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RerunPipelineNodeLogic {
+	return &RerunPipelineNodeLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RerunPipelineNodeLogic) RerunPipelineNode(req *types.RerunPipelineNodeReq) (resp *types.RerunPipelineNodeResp, err error) {
+	operator := scheduler.JobOperator{
+		UserID:   middleware.GetUserIDFromContext(l.ctx),
+		Username: middleware.GetUsernameFromContext(l.ctx),
+	}
+
+	restarted, err := l.svcCtx.JobPipeline.Rerun(req.JobId, req.Downstream, operator, req.Reason)
+
+	// 部分下游作业重启失败时已重启的作业同样需要派发
+	if len(restarted) > 0 && l.svcCtx.JobDispatcher != nil {
+		l.svcCtx.JobDispatcher.Notify()
+	}
+	if err != nil {
+		l.Errorf("重新运行流水线节点失败: JobID=%d, 已重启=%v, %v", req.JobId, restarted, err)
+		return nil, err
+	}
+
+	l.Infof("流水线节点已重新运行: JobID=%d, 重启作业=%v", req.JobId, restarted)
+	return &types.RerunPipelineNodeResp{RestartedJobIds: restarted}, nil
+}
@@ -5,6 +5,8 @@ import (
 
 	"api/internal/svc"
 	"api/internal/types"
+	"api/pkg/middleware"
+	"api/pkg/scheduler"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -25,7 +27,25 @@ func NewRestartTrainingJobLogic(ctx context.Context, svcCtx *svc.ServiceContext)
 }
 
 func (l *RestartTrainingJobLogic) RestartTrainingJob(req *types.RestartTrainingJobReq) (resp *types.EmptyResp, err error) {
-	// todo: add your logic here and delete this line
+	operator := scheduler.JobOperator{
+		UserID:   middleware.GetUserIDFromContext(l.ctx),
+		Username: middleware.GetUsernameFromContext(l.ctx),
+	}
+
+	status, err := l.svcCtx.JobStateMachine.Restart(req.Id, operator, req.Reason, scheduler.RestartOptions{
+		CheckpointId: req.CheckpointId,
+		FromScratch:  req.FromScratch,
+	})
+	if err != nil {
+		l.Errorf("重启训练作业失败: ID=%d, %v", req.Id, err)
+		return nil, err
+	}
+
+	// 需要重新提交的作业由派发器尽快处理
+	if l.svcCtx.JobDispatcher != nil {
+		l.svcCtx.JobDispatcher.Notify()
+	}
 
-	return
+	l.Infof("训练作业已重启: ID=%d, 当前状态=%s", req.Id, status)
+	return &types.EmptyResp{}, nil
 }
@@ -5,6 +5,8 @@ import (
 
 	"api/internal/svc"
 	"api/internal/types"
+	"api/pkg/middleware"
+	"api/pkg/scheduler"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -25,7 +27,22 @@ func NewResumeTrainingJobLogic(ctx context.Context, svcCtx *svc.ServiceContext)
 }
 
 func (l *ResumeTrainingJobLogic) ResumeTrainingJob(req *types.ResumeTrainingJobReq) (resp *types.EmptyResp, err error) {
-	// todo: add your logic here and delete this line
+	operator := scheduler.JobOperator{
+		UserID:   middleware.GetUserIDFromContext(l.ctx),
+		Username: middleware.GetUsernameFromContext(l.ctx),
+	}
+
+	status, err := l.svcCtx.JobStateMachine.Resume(req.Id, operator, req.Reason)
+	if err != nil {
+		l.Errorf("恢复训练作业失败: ID=%d, %v", req.Id, err)
+		return nil, err
+	}
+
+	// 需要重新提交的作业由派发器尽快处理
+	if l.svcCtx.JobDispatcher != nil {
+		l.svcCtx.JobDispatcher.Notify()
+	}
 
-	return
+	l.Infof("训练作业已恢复: ID=%d, 当前状态=%s", req.Id, status)
+	return &types.EmptyResp{}, nil
 }
@@ -0,0 +1,67 @@
+package training
+
+import (
+	"context"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/pkg/scheduler"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RunCheckpointGCLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 按保留策略回收检查点
+func NewRunCheckpointGCLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RunCheckpointGCLogic {
+	return &RunCheckpointGCLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RunCheckpointGCLogic) RunCheckpointGC(req *types.RunCheckpointGCReq) (resp *types.RunCheckpointGCResp, err error) {
+	gc, err := checkpointGC(l.svcCtx)
+	if err != nil {
+		return nil, err
+	}
+	if req.JobId > 0 {
+		if _, err := findJob(l.svcCtx, req.JobId); err != nil {
+			return nil, err
+		}
+	}
+
+	report, err := gc.Run(l.ctx, scheduler.CheckpointGCOptions{DryRun: req.DryRun, JobId: req.JobId})
+	if err != nil {
+		l.Logger.Errorf("检查点垃圾回收失败: %v", err)
+		return nil, err
+	}
+
+	resp = &types.RunCheckpointGCResp{
+		DryRun:         report.DryRun,
+		Jobs:           int64(report.Jobs),
+		Kept:           int64(report.Kept),
+		Removed:        make([]types.CheckpointGCItemInfo, 0, len(report.Removed)),
+		ReclaimedBytes: report.ReclaimedBytes,
+		Failed:         int64(report.Failed),
+	}
+	for _, item := range report.Removed {
+		resp.Removed = append(resp.Removed, types.CheckpointGCItemInfo{
+			JobId:          item.JobId,
+			CheckpointId:   item.CheckpointId,
+			CheckpointName: item.Name,
+			Status:         item.Status,
+			FileSize:       item.FileSize,
+			PolicyId:       item.PolicyId,
+		})
+	}
+
+	l.Logger.Infof("手动检查点垃圾回收完成: dry-run=%v, 作业%d个, 删除%d个, 释放%d字节, 失败%d个",
+		report.DryRun, report.Jobs, len(report.Removed), report.ReclaimedBytes, report.Failed)
+	return resp, nil
+}
@@ -0,0 +1,73 @@
+package training
+
+import (
+	"context"
+	"regexp"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/model"
+	"api/pkg/logstream"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type SearchTrainingLogsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 跨作业检索归档日志
+func NewSearchTrainingLogsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SearchTrainingLogsLogic {
+	return &SearchTrainingLogsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// SearchTrainingLogs 按关键字、正则、最低级别和时间范围检索已归档的日志，jobId为空时检索全部作业
+// instance为实例名（Pod名），regex由MySQL REGEXP执行，先用Go正则校验语法
+func (l *SearchTrainingLogsLogic) SearchTrainingLogs(req *types.SearchTrainingLogsReq) (resp *types.SearchTrainingLogsResp, err error) {
+	if req.Regex != "" {
+		if _, err := regexp.Compile(req.Regex); err != nil {
+			return nil, invalidLogQuery("正则表达式无效: " + err.Error())
+		}
+	}
+	if req.Level != "" && logstream.NormalizeLevel(req.Level) == "" {
+		return nil, invalidLogQuery("日志级别无效: " + req.Level)
+	}
+
+	filter := model.TrainingLogFilter{
+		JobId:   req.JobId,
+		Levels:  logstream.LevelsAtLeast(req.Level),
+		Source:  req.Instance,
+		Keyword: req.Keyword,
+		Regex:   req.Regex,
+	}
+	if filter.StartTime, err = parseLogTime(req.StartTime); err != nil {
+		return nil, err
+	}
+	if filter.EndTime, err = parseLogTime(req.EndTime); err != nil {
+		return nil, err
+	}
+	if filter.StartTime != nil && filter.EndTime != nil && filter.EndTime.Before(*filter.StartTime) {
+		return nil, invalidLogQuery("结束时间不能早于开始时间")
+	}
+
+	logs, total, err := l.svcCtx.VtTrainingLogsModel.List(filter, int(req.Page), int(req.PageSize))
+	if err != nil {
+		l.Errorf("检索训练日志失败: %v", err)
+		return nil, err
+	}
+
+	resp = &types.SearchTrainingLogsResp{
+		Total: total,
+		Logs:  make([]types.TrainingLogInfo, 0, len(logs)),
+	}
+	for _, log := range logs {
+		resp.Logs = append(resp.Logs, toTrainingLogInfo(log))
+	}
+	return resp, nil
+}
@@ -5,6 +5,8 @@ import (
 
 	"api/internal/svc"
 	"api/internal/types"
+	"api/pkg/middleware"
+	"api/pkg/scheduler"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -25,7 +27,17 @@ func NewSuspendTrainingJobLogic(ctx context.Context, svcCtx *svc.ServiceContext)
 }
 
 func (l *SuspendTrainingJobLogic) SuspendTrainingJob(req *types.SuspendTrainingJobReq) (resp *types.EmptyResp, err error) {
-	// todo: add your logic here and delete this line
+	operator := scheduler.JobOperator{
+		UserID:   middleware.GetUserIDFromContext(l.ctx),
+		Username: middleware.GetUsernameFromContext(l.ctx),
+	}
+
+	status, err := l.svcCtx.JobStateMachine.Suspend(req.Id, operator, req.Reason)
+	if err != nil {
+		l.Errorf("暂停训练作业失败: ID=%d, %v", req.Id, err)
+		return nil, err
+	}
 
-	return
+	l.Infof("训练作业已暂停: ID=%d, 当前状态=%s", req.Id, status)
+	return &types.EmptyResp{}, nil
 }
@@ -0,0 +1,102 @@
+package training
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/model"
+	"api/pkg/checkpoint"
+	bizerrors "api/pkg/errors"
+)
+
+var (
+	checkpointTypes   = []string{"auto", "manual", "best", "final", "scheduled"}
+	checkpointFormats = []string{"pytorch", "tensorflow", "onnx", "pickle", "hdf5"}
+)
+
+// findCheckpoint 查询未删除的检查点，不存在时返回ErrCheckpointNotFound
+func findCheckpoint(svcCtx *svc.ServiceContext, id int64) (*model.VtTrainingCheckpoints, error) {
+	c, err := svcCtx.VtTrainingCheckpointsModel.FindOne(id)
+	if err == sql.ErrNoRows {
+		return nil, bizerrors.ErrCheckpointNotFound
+	}
+	return c, err
+}
+
+// checkpointManager 返回检查点登记簿，存储配置无效时返回服务不可用
+func checkpointManager(svcCtx *svc.ServiceContext) (*checkpoint.CheckpointManager, error) {
+	if svcCtx.CheckpointManager == nil {
+		return nil, bizerrors.NewBizError(bizerrors.ErrCodeServiceUnavailable, "检查点存储未配置或不可用", bizerrors.ErrorTypeExternal)
+	}
+	return svcCtx.CheckpointManager, nil
+}
+
+// invalidCheckpoint 构造检查点参数错误
+func invalidCheckpoint(message string) error {
+	return bizerrors.NewBizError(bizerrors.ErrCodeCheckpointInvalid, message, bizerrors.ErrorTypeValidation)
+}
+
+// validateCheckpointEnum 校验检查点类型和格式，空值不校验
+func validateCheckpointEnum(field, value string, allowed []string) error {
+	if value == "" {
+		return nil
+	}
+	for _, v := range allowed {
+		if v == value {
+			return nil
+		}
+	}
+	return invalidCheckpoint(fmt.Sprintf("不支持的%s: %s", field, value))
+}
+
+// toCheckpointError 将检查点登记簿的错误转换为业务错误
+func toCheckpointError(err error) error {
+	switch {
+	case errors.Is(err, checkpoint.ErrInvalidCheckpoint), errors.Is(err, checkpoint.ErrUnsupportedCompression):
+		return invalidCheckpoint(err.Error())
+	case errors.Is(err, checkpoint.ErrObjectNotFound):
+		return invalidCheckpoint("检查点文件不存在，请先将检查点写入存储后再登记")
+	case errors.Is(err, checkpoint.ErrCorrupted):
+		return bizerrors.ErrCheckpointCorrupted
+	case errors.Is(err, sql.ErrNoRows):
+		return bizerrors.ErrCheckpointNotFound
+	}
+	return err
+}
+
+// toTrainingCheckpointInfo 将检查点模型转换为接口返回结构
+func toTrainingCheckpointInfo(c *model.VtTrainingCheckpoints) types.TrainingCheckpointInfo {
+	return types.TrainingCheckpointInfo{
+		Id:               c.Id,
+		JobId:            c.JobId,
+		CheckpointName:   c.CheckpointName,
+		CheckpointType:   c.CheckpointType,
+		CheckpointFormat: c.CheckpointFormat,
+		Step:             c.Step,
+		Epoch:            int64(c.Epoch),
+		GlobalStep:       c.GlobalStep,
+		StoragePath:      c.StoragePath,
+		FileSize:         c.FileSize,
+		Checksum:         c.Checksum,
+		CompressionType:  c.CompressionType,
+		Metrics:          c.Metrics,
+		LossValue:        c.LossValue,
+		Accuracy:         c.Accuracy,
+		ValidationScore:  c.ValidationScore,
+		ModelConfig:      c.ModelConfig,
+		OptimizerState:   c.OptimizerState,
+		SchedulerState:   c.SchedulerState,
+		Status:           c.Status,
+		IsBest:           c.IsBest,
+		IsLatest:         c.IsLatest,
+		Tags:             c.Tags,
+		Metadata:         c.Metadata,
+		Description:      c.Description,
+		CreatedAt:        c.CreatedAt.Format(timeLayout),
+		UpdatedAt:        c.UpdatedAt.Format(timeLayout),
+		SavedAt:          formatTime(c.SavedAt),
+	}
+}
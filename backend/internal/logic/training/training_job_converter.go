@@ -0,0 +1,237 @@
+package training
+
+import (
+	"encoding/json"
+	"time"
+
+	"api/internal/types"
+	"api/model"
+)
+
+const timeLayout = "2006-01-02 15:04:05"
+
+// formatTime 格式化可空时间，为空时返回空字符串
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(timeLayout)
+}
+
+// toTrainingJobInfo 将训练作业模型转换为接口返回结构
+func toTrainingJobInfo(job *model.VtTrainingJobs) types.TrainingJobInfo {
+	return types.TrainingJobInfo{
+		Id:                        job.Id,
+		Name:                      job.Name,
+		DisplayName:               job.DisplayName,
+		Description:               job.Description,
+		JobType:                   job.JobType,
+		Framework:                 job.Framework,
+		FrameworkVersion:          job.FrameworkVersion,
+		PythonVersion:             job.PythonVersion,
+		CodeSourceType:            job.CodeSourceType,
+		CodeSourceConfig:          job.CodeSourceConfig,
+		EntryPoint:                job.EntryPoint,
+		WorkingDir:                job.WorkingDir,
+		Image:                     job.Image,
+		ImagePullPolicy:           job.ImagePullPolicy,
+		ImagePullSecrets:          job.ImagePullSecrets,
+		DatasetMountConfigs:       job.DatasetMountConfigs,
+		DataSourceConfig:          job.DataSourceConfig,
+		ModelConfig:               job.ModelConfig,
+		OutputModelName:           job.OutputModelName,
+		ModelSaveStrategy:         job.ModelSaveStrategy,
+		CpuCores:                  job.CpuCores,
+		MemoryGb:                  job.MemoryGb,
+		GpuCount:                  int64(job.GpuCount),
+		GpuType:                   job.GpuType,
+		GpuMemoryGb:               job.GpuMemoryGb,
+		GpuSharingMode:            job.GpuSharingMode,
+		GpuMigProfile:             job.GpuMigProfile,
+		StorageGb:                 job.StorageGb,
+		SharedMemoryGb:            job.SharedMemoryGb,
+		WorkerCount:               int64(job.WorkerCount),
+		PsCount:                   int64(job.PsCount),
+		MasterCount:               int64(job.MasterCount),
+		EnvVars:                   job.EnvVars,
+		CommandArgs:               job.CommandArgs,
+		Secrets:                   job.Secrets,
+		ConfigMaps:                job.ConfigMaps,
+		VolumeMounts:              job.VolumeMounts,
+		QueueName:                 job.QueueName,
+		Priority:                  int64(job.Priority),
+		NodeSelector:              job.NodeSelector,
+		Tolerations:               job.Tolerations,
+		Affinity:                  job.Affinity,
+		MaxRuntimeSeconds:         int64(job.MaxRuntimeSeconds),
+		MaxIdleSeconds:            int64(job.MaxIdleSeconds),
+		AutoRestart:               job.AutoRestart,
+		MaxRetryCount:             int64(job.MaxRetryCount),
+		VolcanoJobName:            job.VolcanoJobName,
+		VolcanoQueue:              job.VolcanoQueue,
+		MinAvailable:              int64(job.MinAvailable),
+		Status:                    job.Status,
+		Phase:                     job.Phase,
+		Namespace:                 job.Namespace,
+		ClusterName:               job.ClusterName,
+		ErrorMessage:              job.ErrorMessage,
+		ErrorCode:                 job.ErrorCode,
+		ExitCode:                  int64(job.ExitCode),
+		FailureReason:             job.FailureReason,
+		SubmittedAt:               formatTime(&job.SubmittedAt),
+		QueuedAt:                  formatTime(job.QueuedAt),
+		ScheduledAt:               formatTime(job.ScheduledAt),
+		StartTime:                 formatTime(job.StartTime),
+		EndTime:                   formatTime(job.EndTime),
+		DurationSeconds:           int64(job.DurationSeconds),
+		ActualCpuUsage:            job.ActualCpuUsage,
+		ActualMemoryUsageGb:       job.ActualMemoryUsageGb,
+		ActualGpuUsage:            job.ActualGpuUsage,
+		PeakMemoryUsageGb:         job.PeakMemoryUsageGb,
+		TotalGpuHours:             job.TotalGpuHours,
+		WorkspacePath:             job.WorkspacePath,
+		LogsPath:                  job.LogsPath,
+		OutputPath:                job.OutputPath,
+		CheckpointPath:            job.CheckpointPath,
+		ResumeCheckpointId:        job.ResumeCheckpointId,
+		ResumeCheckpointPath:      job.ResumeCheckpointPath,
+		TensorboardPath:           job.TensorboardPath,
+		Hyperparameters:           job.Hyperparameters,
+		TrainingConfig:            job.TrainingConfig,
+		OptimizerConfig:           job.OptimizerConfig,
+		SchedulerConfig:           job.SchedulerConfig,
+		EnableTensorboard:         job.EnableTensorboard,
+		EnableProfiling:           job.EnableProfiling,
+		MetricsCollectionInterval: int64(job.MetricsCollectionInterval),
+		NotificationConfig:        job.NotificationConfig,
+		Tags:                      job.Tags,
+		Annotations:               job.Annotations,
+		Metadata:                  job.Metadata,
+		CreatedAt:                 formatTime(&job.CreatedAt),
+		UpdatedAt:                 formatTime(&job.UpdatedAt),
+	}
+}
+
+// toTrainingJobRetryInfo 将自动重试记录转换为接口返回结构
+func toTrainingJobRetryInfo(retry *model.VtTrainingJobRetries) types.TrainingJobRetryInfo {
+	return types.TrainingJobRetryInfo{
+		Attempt:              int64(retry.Attempt),
+		FailureReason:        retry.FailureReason,
+		ExitCode:             int64(retry.ExitCode),
+		ErrorMessage:         retry.ErrorMessage,
+		FailedVolcanoJobName: retry.FailedVolcanoJobName,
+		RetryVolcanoJobName:  retry.RetryVolcanoJobName,
+		CheckpointId:         retry.CheckpointId,
+		CheckpointPath:       retry.CheckpointPath,
+		BackoffSeconds:       int64(retry.BackoffSeconds),
+		FailedAt:             formatTime(retry.FailedAt),
+		RetriedAt:            formatTime(&retry.CreatedAt),
+	}
+}
+
+// toTrainingJobRunInfo 将开始新一次运行的状态变更记录转换为接口返回结构
+func toTrainingJobRunInfo(t *model.VtTrainingJobTransitions) types.TrainingJobRunInfo {
+	return types.TrainingJobRunInfo{
+		Action:             t.Action,
+		VolcanoJobName:     t.VolcanoJobName,
+		ResumeCheckpointId: t.ResumeCheckpointId,
+		Reason:             t.Reason,
+		OperatorName:       t.OperatorName,
+		CreatedAt:          formatTime(&t.CreatedAt),
+	}
+}
+
+// toTrainingJobRelationInfo 将作业关联关系模型转换为接口返回结构
+func toTrainingJobRelationInfo(relation *model.VtTrainingJobRelations) types.TrainingJobRelationInfo {
+	return types.TrainingJobRelationInfo{
+		Id:           relation.Id,
+		JobId:        relation.JobId,
+		EntityType:   relation.EntityType,
+		EntityId:     relation.EntityId,
+		RelationType: relation.RelationType,
+		IsPrimary:    relation.IsPrimary,
+		SortOrder:    int64(relation.SortOrder),
+		Status:       relation.Status,
+		Metadata:     relation.Metadata,
+		CreatedAt:    relation.CreatedAt.Format(timeLayout),
+		UpdatedAt:    relation.UpdatedAt.Format(timeLayout),
+	}
+}
+
+// toCreateTrainingJobReq 将已有作业的规格转换为创建作业请求，用于复制作业，不包含运行状态和流水线依赖
+func toCreateTrainingJobReq(job *model.VtTrainingJobs) *types.CreateTrainingJobReq {
+	return &types.CreateTrainingJobReq{
+		Name:                      job.Name,
+		DisplayName:               job.DisplayName,
+		Description:               job.Description,
+		JobType:                   job.JobType,
+		Framework:                 job.Framework,
+		FrameworkVersion:          job.FrameworkVersion,
+		PythonVersion:             job.PythonVersion,
+		CodeSourceType:            job.CodeSourceType,
+		CodeSourceConfig:          job.CodeSourceConfig,
+		EntryPoint:                job.EntryPoint,
+		WorkingDir:                job.WorkingDir,
+		Image:                     job.Image,
+		ImagePullPolicy:           job.ImagePullPolicy,
+		ImagePullSecrets:          job.ImagePullSecrets,
+		DatasetMountConfigs:       job.DatasetMountConfigs,
+		DataSourceConfig:          job.DataSourceConfig,
+		ModelConfig:               job.ModelConfig,
+		OutputModelName:           job.OutputModelName,
+		ModelSaveStrategy:         job.ModelSaveStrategy,
+		CpuCores:                  job.CpuCores,
+		MemoryGb:                  job.MemoryGb,
+		GpuCount:                  int64(job.GpuCount),
+		GpuType:                   job.GpuType,
+		GpuMemoryGb:               job.GpuMemoryGb,
+		GpuSharingMode:            job.GpuSharingMode,
+		GpuMigProfile:             job.GpuMigProfile,
+		StorageGb:                 job.StorageGb,
+		SharedMemoryGb:            job.SharedMemoryGb,
+		WorkerCount:               int64(job.WorkerCount),
+		PsCount:                   int64(job.PsCount),
+		MasterCount:               int64(job.MasterCount),
+		EnvVars:                   job.EnvVars,
+		CommandArgs:               job.CommandArgs,
+		Secrets:                   job.Secrets,
+		ConfigMaps:                job.ConfigMaps,
+		VolumeMounts:              job.VolumeMounts,
+		QueueName:                 job.QueueName,
+		Priority:                  int64(job.Priority),
+		NodeSelector:              job.NodeSelector,
+		Tolerations:               job.Tolerations,
+		Affinity:                  job.Affinity,
+		MaxRuntimeSeconds:         int64(job.MaxRuntimeSeconds),
+		MaxIdleSeconds:            int64(job.MaxIdleSeconds),
+		AutoRestart:               job.AutoRestart,
+		MaxRetryCount:             int64(job.MaxRetryCount),
+		MinAvailable:              int64(job.MinAvailable),
+		Hyperparameters:           job.Hyperparameters,
+		TrainingConfig:            job.TrainingConfig,
+		OptimizerConfig:           job.OptimizerConfig,
+		SchedulerConfig:           job.SchedulerConfig,
+		EnableTensorboard:         job.EnableTensorboard,
+		EnableProfiling:           job.EnableProfiling,
+		MetricsCollectionInterval: int64(job.MetricsCollectionInterval),
+		NotificationConfig:        job.NotificationConfig,
+		Tags:                      job.Tags,
+		Annotations:               job.Annotations,
+		Metadata:                  job.Metadata,
+	}
+}
+
+// marshalCreateTrainingJobReq 将创建作业请求编码为JSON，供合并覆盖项后按创建作业接口的规则重新解析
+// 未共享GPU的作业gpuSharingMode为空，空值不在该字段的可选值中，编码时省略
+func marshalCreateTrainingJobReq(req *types.CreateTrainingJobReq) ([]byte, error) {
+	data, err := json.Marshal(req)
+	if err != nil || req.GpuSharingMode != "" {
+		return data, err
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	delete(object, "gpuSharingMode")
+	return json.Marshal(object)
+}
@@ -0,0 +1,123 @@
+package training
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/model"
+	bizerrors "api/pkg/errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/mapping"
+)
+
+// JobCreator 供后台任务使用的训练作业创建入口，与创建作业接口走相同的校验和入库流程
+type JobCreator struct {
+	svcCtx *svc.ServiceContext
+}
+
+// NewJobCreator 创建训练作业创建入口
+func NewJobCreator(svcCtx *svc.ServiceContext) *JobCreator {
+	return &JobCreator{svcCtx: svcCtx}
+}
+
+// CreateTrainingJob 使用创建训练作业请求的JSON创建作业，返回作业ID
+func (c *JobCreator) CreateTrainingJob(ctx context.Context, spec []byte) (int64, error) {
+	req, err := parseCreateTrainingJobReq(spec)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := NewCreateTrainingJobLogic(ctx, c.svcCtx).CreateTrainingJob(req)
+	if err != nil {
+		return 0, err
+	}
+	return resp.Id, nil
+}
+
+// CreateTriggeredJob 按定时触发器的目标创建作业，返回作业ID
+// 目标为作业时复制其规格，参数为覆盖规格字段的JSON对象；目标为模板时参数为模板参数值，按触发器创建人的权限访问模板
+func (c *JobCreator) CreateTriggeredJob(ctx context.Context, trigger *model.VtTrainingTriggers, name string) (int64, error) {
+	var values map[string]interface{}
+	if trigger.Parameters != "" {
+		if err := json.Unmarshal([]byte(trigger.Parameters), &values); err != nil {
+			return 0, fmt.Errorf("触发器参数不是有效的JSON对象: %w", err)
+		}
+	}
+
+	var req *types.CreateTrainingJobReq
+	var templateId int64
+	switch trigger.TargetType {
+	case model.TriggerTargetJob:
+		job, err := c.svcCtx.VtTrainingJobsModel.FindOneDetail(trigger.TargetId)
+		if err == sql.ErrNoRows {
+			return 0, bizerrors.ErrJobNotFound
+		}
+		if err != nil {
+			return 0, err
+		}
+		if req, err = overrideCreateTrainingJobReq(toCreateTrainingJobReq(job), values); err != nil {
+			return 0, err
+		}
+		req.Name = name
+	case model.TriggerTargetTemplate:
+		template, err := findVisibleTemplate(c.svcCtx, trigger.TargetId, trigger.OwnerId)
+		if err != nil {
+			return 0, err
+		}
+		if req, err = renderTemplate(template.Spec, template.Parameters, values, name, name); err != nil {
+			return 0, err
+		}
+		templateId = template.Id
+	default:
+		return 0, fmt.Errorf("不支持的触发目标类型 '%s'", trigger.TargetType)
+	}
+
+	resp, err := NewCreateTrainingJobLogic(ctx, c.svcCtx).CreateTrainingJob(req)
+	if err != nil {
+		return 0, err
+	}
+
+	if templateId > 0 {
+		if err := c.svcCtx.VtTrainingJobTemplatesModel.IncrUseCount(templateId); err != nil {
+			logx.WithContext(ctx).Errorf("更新模板使用次数失败: ID=%d, %v", templateId, err)
+		}
+	}
+	return resp.Id, nil
+}
+
+// overrideCreateTrainingJobReq 使用values中的字段覆盖创建作业请求中的同名字段
+func overrideCreateTrainingJobReq(req *types.CreateTrainingJobReq, values map[string]interface{}) (*types.CreateTrainingJobReq, error) {
+	if len(values) == 0 {
+		return req, nil
+	}
+
+	data, err := marshalCreateTrainingJobReq(req)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	for key, value := range values {
+		object[key] = value
+	}
+	if data, err = json.Marshal(object); err != nil {
+		return nil, err
+	}
+	return parseCreateTrainingJobReq(data)
+}
+
+// parseCreateTrainingJobReq 按创建作业接口的规则解析请求JSON并填充默认值
+func parseCreateTrainingJobReq(spec []byte) (*types.CreateTrainingJobReq, error) {
+	var req types.CreateTrainingJobReq
+	if err := mapping.UnmarshalJsonBytes(spec, &req); err != nil {
+		return nil, fmt.Errorf("训练作业规格无效: %w", err)
+	}
+	return &req, nil
+}
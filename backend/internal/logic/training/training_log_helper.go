@@ -0,0 +1,120 @@
+package training
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/model"
+	bizerrors "api/pkg/errors"
+	"api/pkg/logstream"
+	"api/pkg/scheduler"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// findJob 查询训练作业，不存在时返回ErrJobNotFound
+func findJob(svcCtx *svc.ServiceContext, id int64) (*model.VtTrainingJobs, error) {
+	job, err := svcCtx.VtTrainingJobsModel.FindOneDetail(id)
+	if err == sql.ErrNoRows {
+		return nil, bizerrors.ErrJobNotFound
+	}
+	return job, err
+}
+
+// invalidParam 构造请求参数错误
+func invalidParam(message string) error {
+	return bizerrors.NewBizError(bizerrors.ErrCodeInvalidParam, message, bizerrors.ErrorTypeValidation)
+}
+
+// invalidLogQuery 构造日志查询参数错误
+func invalidLogQuery(message string) error {
+	return bizerrors.NewBizError(bizerrors.ErrCodeInvalidParam, message, bizerrors.ErrorTypeValidation)
+}
+
+// errFollowNeedsStream follow只能通过SSE或WebSocket连接使用，普通请求受服务端超时限制
+var errFollowNeedsStream = invalidLogQuery("follow=true需要以SSE（Accept: text/event-stream）或WebSocket方式请求")
+
+// parseLogTime 解析日志查询的时间参数，支持RFC3339和timeLayout格式
+func parseLogTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t, err = time.ParseInLocation(timeLayout, value, time.Local)
+	}
+	if err != nil {
+		return nil, invalidLogQuery("时间格式错误: " + value)
+	}
+	return &t, nil
+}
+
+// newJobLogOptions 构造读取作业实时日志的参数，offset优先取自SSE重连时的Last-Event-ID
+func newJobLogOptions(svcCtx *svc.ServiceContext, job *model.VtTrainingJobs, offset string) (logstream.Options, error) {
+	if svcCtx.LogStreamer == nil {
+		return logstream.Options{}, bizerrors.NewBizError(bizerrors.ErrCodeServiceUnavailable, "Kubernetes不可用，无法读取实时日志", bizerrors.ErrorTypeExternal)
+	}
+	if job.VolcanoJobName == "" {
+		return logstream.Options{}, invalidLogQuery("训练作业尚未提交到集群，没有实时日志")
+	}
+
+	cursor, err := logstream.ParseCursor(offset)
+	if err != nil {
+		return logstream.Options{}, invalidLogQuery(err.Error())
+	}
+
+	jobId := job.Id
+	return logstream.Options{
+		Namespace: job.Namespace,
+		Selector:  scheduler.JobPodSelector(job).String(),
+		Cursor:    cursor,
+		Finished: func() bool {
+			current, err := svcCtx.VtTrainingJobsModel.FindOneDetail(jobId)
+			return err == nil && scheduler.IsTerminalJobStatus(current.Status)
+		},
+	}, nil
+}
+
+// serveLogStream 以SSE或WebSocket推送实时日志，日志流结束时发送end事件
+func serveLogStream(w http.ResponseWriter, r *http.Request, streamer *logstream.Streamer, opts logstream.Options, logger logx.Logger) {
+	logstream.Serve(w, r, func(ctx context.Context, writer logstream.Writer) {
+		reason := "completed"
+		if err := streamer.Stream(ctx, opts, writer); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			logger.Errorf("推送实时日志失败: %s/%s, %v", opts.Namespace, opts.Selector, err)
+			reason = err.Error()
+		}
+		_ = writer.WriteEnd(reason)
+	})
+}
+
+// toTrainingLogInfo 将训练日志模型转换为接口返回结构
+func toTrainingLogInfo(l *model.VtTrainingLogs) types.TrainingLogInfo {
+	return types.TrainingLogInfo{
+		Id:            l.Id,
+		JobId:         l.JobId,
+		InstanceId:    l.InstanceId,
+		LogLevel:      l.LogLevel,
+		LogSource:     l.LogSource,
+		LogContent:    l.LogContent,
+		LogFormat:     l.LogFormat,
+		LogTime:       l.LogTime.Format(timeLayout),
+		CreatedAt:     l.CreatedAt.Format(timeLayout),
+		FileName:      l.FileName,
+		LineNumber:    int64(l.LineNumber),
+		FunctionName:  l.FunctionName,
+		ThreadId:      l.ThreadId,
+		ProcessId:     l.ProcessId,
+		Context:       l.Context,
+		CorrelationId: l.CorrelationId,
+		Category:      l.Category,
+		Tags:          l.Tags,
+	}
+}
@@ -0,0 +1,130 @@
+package training
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"api/internal/types"
+	"api/model"
+	"api/pkg/metrics"
+)
+
+// maxSeriesPoints 单条指标曲线允许请求的最大点数
+const maxSeriesPoints = 10000
+
+// normalizeMetricPhase 将阶段名转换为vt_training_metrics.phase的取值
+func normalizeMetricPhase(phase string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(phase)) {
+	case "":
+		return "", nil
+	case "train", "training":
+		return "train", nil
+	case "val", "valid", "validation", "eval", "evaluation":
+		return "val", nil
+	case "test", "testing":
+		return "test", nil
+	}
+	return "", fmt.Errorf("阶段 %q 无效，可选值为train、val、test", phase)
+}
+
+// buildTrainingMetric 校验上报的指标并转换为模型，相对时间以作业开始时间为起点
+func buildTrainingMetric(job *model.VtTrainingJobs, instanceId int64, sample *types.MetricSample) (*model.VtTrainingMetrics, error) {
+	if sample.Name == "" {
+		return nil, fmt.Errorf("指标名不能为空")
+	}
+	if utf8.RuneCountInString(sample.Name) > 128 || utf8.RuneCountInString(sample.Tag) > 128 || utf8.RuneCountInString(sample.Category) > 64 {
+		return nil, fmt.Errorf("指标 %s 的名称、标签或类别过长", sample.Name)
+	}
+	phase, err := normalizeMetricPhase(sample.Phase)
+	if err != nil {
+		return nil, err
+	}
+
+	metric := &model.VtTrainingMetrics{
+		JobId:      job.Id,
+		InstanceId: instanceId,
+		MetricName: sample.Name,
+		MetricType: sample.Type,
+		Step:       sample.Step,
+		Epoch:      int(sample.Epoch),
+		GlobalStep: sample.GlobalStep,
+		BatchIdx:   int(sample.BatchIdx),
+		Tag:        sample.Tag,
+		Category:   sample.Category,
+		Phase:      phase,
+	}
+	if metric.MetricType == "" {
+		metric.MetricType = model.MetricTypeScalar
+	}
+
+	switch metric.MetricType {
+	case model.MetricTypeScalar:
+		if err := metrics.ValidateValue(sample.Value); err != nil {
+			return nil, fmt.Errorf("指标 %s: %v", sample.Name, err)
+		}
+		metric.MetricValue = metrics.FormatValue(sample.Value)
+	case model.MetricTypeHistogram:
+		if sample.Histogram == nil {
+			return nil, fmt.Errorf("直方图指标 %s 缺少histogram", sample.Name)
+		}
+		histogram := metrics.Histogram(*sample.Histogram)
+		if err := metrics.SetHistogram(metric, &histogram); err != nil {
+			return nil, fmt.Errorf("直方图指标 %s: %v", sample.Name, err)
+		}
+	case model.MetricTypeText:
+		if sample.Text == "" {
+			return nil, fmt.Errorf("文本指标 %s 缺少text", sample.Name)
+		}
+		data, _ := json.Marshal(map[string]string{"text": sample.Text})
+		metric.MetricData = string(data)
+	default:
+		return nil, fmt.Errorf("指标类型 %q 无效，可选值为scalar、histogram、text", sample.Type)
+	}
+
+	metrics.SetWallTime(metric, sample.WallTime, job.StartTime)
+	return metric, nil
+}
+
+// toTrainingMetricInfo 将训练指标模型转换为接口返回结构
+func toTrainingMetricInfo(m *model.VtTrainingMetrics) types.TrainingMetricInfo {
+	return types.TrainingMetricInfo{
+		Id:                  m.Id,
+		JobId:               m.JobId,
+		InstanceId:          m.InstanceId,
+		MetricName:          m.MetricName,
+		MetricType:          m.MetricType,
+		MetricValue:         m.MetricValue,
+		MetricData:          m.MetricData,
+		Step:                m.Step,
+		Epoch:               int64(m.Epoch),
+		GlobalStep:          m.GlobalStep,
+		BatchIdx:            int64(m.BatchIdx),
+		Tag:                 m.Tag,
+		Category:            m.Category,
+		Phase:               m.Phase,
+		MetricTime:          m.MetricTime.Format(timeLayout),
+		WallTime:            m.WallTime,
+		RelativeTimeSeconds: m.RelativeTimeSeconds,
+		MinValue:            m.MinValue,
+		MaxValue:            m.MaxValue,
+		AvgValue:            m.AvgValue,
+		StdValue:            m.StdValue,
+		CreatedAt:           m.CreatedAt.Format(timeLayout),
+	}
+}
+
+// toMetricSeriesInfo 将指标曲线转换为接口返回结构
+func toMetricSeriesInfo(s *model.MetricSeries) types.MetricSeriesInfo {
+	info := types.MetricSeriesInfo{
+		MetricName: s.MetricName,
+		Phase:      s.Phase,
+		Total:      s.Total,
+		Points:     make([]types.MetricSeriesPointInfo, 0, len(s.Points)),
+	}
+	for _, p := range s.Points {
+		info.Points = append(info.Points, types.MetricSeriesPointInfo{Step: p.Step, Value: p.Value, Min: p.Min, Max: p.Max, WallTime: p.WallTime})
+	}
+	return info
+}
@@ -0,0 +1,98 @@
+package training
+
+import (
+	"database/sql"
+	"fmt"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/model"
+	"api/pkg/checkpoint"
+	bizerrors "api/pkg/errors"
+	"api/pkg/scheduler"
+)
+
+// findRetentionPolicy 查询检查点保留策略，不存在时返回ErrRetentionNotFound
+func findRetentionPolicy(svcCtx *svc.ServiceContext, id int64) (*model.VtCheckpointRetentionPolicies, error) {
+	policy, err := svcCtx.VtCheckpointRetentionPoliciesModel.FindOne(id)
+	if err == sql.ErrNoRows {
+		return nil, bizerrors.ErrRetentionNotFound
+	}
+	return policy, err
+}
+
+// invalidRetention 构造保留策略参数错误
+func invalidRetention(format string, args ...interface{}) error {
+	return bizerrors.NewBizError(bizerrors.ErrCodeRetentionInvalid, fmt.Sprintf(format, args...), bizerrors.ErrorTypeValidation)
+}
+
+// validateRetentionPolicy 校验保留规则，未指定优化方向时按最小化处理
+func validateRetentionPolicy(policy *model.VtCheckpointRetentionPolicies) error {
+	if policy.Name == "" {
+		return invalidRetention("策略名称不能为空")
+	}
+	if policy.BestGoal == "" {
+		policy.BestGoal = checkpoint.RetentionGoalMinimize
+	}
+	if err := checkpoint.NewRetentionPolicy(policy).Validate(); err != nil {
+		return invalidRetention("%v", err)
+	}
+	return nil
+}
+
+// checkRetentionScope 校验作用范围，全局策略的scopeId必须为0，队列和作业需存在
+func checkRetentionScope(svcCtx *svc.ServiceContext, scopeType string, scopeId int64) error {
+	switch scopeType {
+	case model.RetentionScopeGlobal:
+		if scopeId != 0 {
+			return invalidRetention("全局策略的scopeId必须为0")
+		}
+		return nil
+	case model.RetentionScopeWorkspace, model.RetentionScopeQueue, model.RetentionScopeJob:
+		if scopeId <= 0 {
+			return invalidRetention("%s策略需要指定scopeId", scopeType)
+		}
+	default:
+		return invalidRetention("不支持的作用范围 '%s'", scopeType)
+	}
+
+	var err error
+	switch scopeType {
+	case model.RetentionScopeQueue:
+		_, err = svcCtx.VtTrainingQueuesModel.FindOne(scopeId)
+		if err == sql.ErrNoRows {
+			return invalidRetention("队列 %d 不存在", scopeId)
+		}
+	case model.RetentionScopeJob:
+		_, err = findJob(svcCtx, scopeId)
+	}
+	return err
+}
+
+// checkpointGC 返回检查点垃圾回收，检查点存储不可用时返回服务不可用
+func checkpointGC(svcCtx *svc.ServiceContext) (*scheduler.CheckpointGC, error) {
+	if svcCtx.CheckpointGC == nil {
+		return nil, bizerrors.NewBizError(bizerrors.ErrCodeServiceUnavailable, "检查点存储未配置或不可用", bizerrors.ErrorTypeExternal)
+	}
+	return svcCtx.CheckpointGC, nil
+}
+
+// toCheckpointRetentionPolicyInfo 将保留策略模型转换为接口返回结构
+func toCheckpointRetentionPolicyInfo(p *model.VtCheckpointRetentionPolicies) types.CheckpointRetentionPolicyInfo {
+	return types.CheckpointRetentionPolicyInfo{
+		Id:               p.Id,
+		Name:             p.Name,
+		ScopeType:        p.ScopeType,
+		ScopeId:          p.ScopeId,
+		KeepLast:         int64(p.KeepLast),
+		KeepBest:         int64(p.KeepBest),
+		BestMetric:       p.BestMetric,
+		BestGoal:         p.BestGoal,
+		KeepEveryNEpochs: int64(p.KeepEveryNEpochs),
+		Enabled:          p.Enabled,
+		Description:      p.Description,
+		CreatedBy:        p.CreatedBy,
+		CreatedAt:        p.CreatedAt.Format(timeLayout),
+		UpdatedAt:        p.UpdatedAt.Format(timeLayout),
+	}
+}
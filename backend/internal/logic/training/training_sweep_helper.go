@@ -0,0 +1,64 @@
+package training
+
+import (
+	"database/sql"
+	"strconv"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/model"
+	bizerrors "api/pkg/errors"
+)
+
+// findOwnedSweep 查询用户创建的超参数搜索，其他用户视为不存在
+func findOwnedSweep(svcCtx *svc.ServiceContext, id, userId int64) (*model.VtTrainingSweeps, error) {
+	sweep, err := svcCtx.VtTrainingSweepsModel.FindOne(id)
+	if err == sql.ErrNoRows {
+		return nil, bizerrors.ErrSweepNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if sweep.OwnerId != userId {
+		return nil, bizerrors.ErrSweepNotFound
+	}
+	return sweep, nil
+}
+
+// formatFloat 格式化指标值
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// toTrainingSweepInfo 将超参数搜索模型转换为接口返回结构
+func toTrainingSweepInfo(sweep *model.VtTrainingSweeps) types.TrainingSweepInfo {
+	info := types.TrainingSweepInfo{
+		Id:              sweep.Id,
+		Name:            sweep.Name,
+		DisplayName:     sweep.DisplayName,
+		Description:     sweep.Description,
+		OwnerId:         sweep.OwnerId,
+		OwnerName:       sweep.OwnerName,
+		Algorithm:       sweep.Algorithm,
+		ObjectiveMetric: sweep.ObjectiveMetric,
+		ObjectiveGoal:   sweep.ObjectiveGoal,
+		SearchSpace:     sweep.SearchSpace,
+		BaseSpec:        sweep.BaseSpec,
+		MaxTrials:       int64(sweep.MaxTrials),
+		Parallelism:     int64(sweep.Parallelism),
+		EarlyStopping:   sweep.EarlyStopping,
+		Status:          sweep.Status,
+		TrialCount:      int64(sweep.TrialCount),
+		CompletedCount:  int64(sweep.CompletedCount),
+		PrunedCount:     int64(sweep.PrunedCount),
+		BestJobId:       sweep.BestJobId,
+		ErrorMessage:    sweep.ErrorMessage,
+		FinishedAt:      formatTime(sweep.FinishedAt),
+		CreatedAt:       sweep.CreatedAt.Format(timeLayout),
+		UpdatedAt:       sweep.UpdatedAt.Format(timeLayout),
+	}
+	if sweep.BestValue != nil {
+		info.BestValue = formatFloat(*sweep.BestValue)
+	}
+	return info
+}
@@ -0,0 +1,135 @@
+package training
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/model"
+	bizerrors "api/pkg/errors"
+	"api/pkg/jobtemplate"
+)
+
+// findVisibleTemplate 查询用户可见的模板
+// 模板创建人，以及共享到工作空间时该工作空间的有效成员可见，其他用户视为模板不存在
+func findVisibleTemplate(svcCtx *svc.ServiceContext, id, userId int64) (*model.VtTrainingJobTemplates, error) {
+	template, err := svcCtx.VtTrainingJobTemplatesModel.FindOne(id)
+	if err == sql.ErrNoRows {
+		return nil, bizerrors.ErrTemplateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if template.OwnerId == userId {
+		return template, nil
+	}
+
+	if template.Visibility == model.TemplateVisibilityWorkspace && template.WorkspaceId > 0 {
+		member, err := svcCtx.VtWorkspaceMembersModel.IsActiveMember(template.WorkspaceId, userId)
+		if err != nil {
+			return nil, err
+		}
+		if member {
+			return template, nil
+		}
+	}
+	return nil, bizerrors.ErrTemplateNotFound
+}
+
+// findOwnedTemplate 查询用户有权修改的模板，只有创建人可以修改或删除模板
+func findOwnedTemplate(svcCtx *svc.ServiceContext, id, userId int64) (*model.VtTrainingJobTemplates, error) {
+	template, err := findVisibleTemplate(svcCtx, id, userId)
+	if err != nil {
+		return nil, err
+	}
+	if template.OwnerId != userId {
+		return nil, bizerrors.ErrPermissionDenied
+	}
+	return template, nil
+}
+
+// validateTemplate 校验模板可见性、内容和参数定义，返回序列化后的参数定义
+func validateTemplate(visibility string, workspaceId int64, spec string, params []types.TrainingTemplateParameter) (string, error) {
+	switch visibility {
+	case model.TemplateVisibilityPrivate:
+	case model.TemplateVisibilityWorkspace:
+		if workspaceId <= 0 {
+			return "", bizerrors.NewBizError(bizerrors.ErrCodeTemplateInvalid, "共享到工作空间的模板必须指定工作空间", bizerrors.ErrorTypeValidation)
+		}
+	default:
+		return "", bizerrors.NewBizError(bizerrors.ErrCodeTemplateInvalid,
+			fmt.Sprintf("不支持的模板可见性 '%s'", visibility), bizerrors.ErrorTypeValidation)
+	}
+
+	definitions := toTemplateParameters(params)
+	if err := jobtemplate.Validate(spec, definitions); err != nil {
+		return "", bizerrors.NewBizError(bizerrors.ErrCodeTemplateInvalid, err.Error(), bizerrors.ErrorTypeValidation)
+	}
+
+	data, err := json.Marshal(definitions)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// toTemplateParameters 将接口参数定义转换为模板参数定义
+func toTemplateParameters(params []types.TrainingTemplateParameter) []jobtemplate.Parameter {
+	definitions := make([]jobtemplate.Parameter, 0, len(params))
+	for _, param := range params {
+		definitions = append(definitions, jobtemplate.Parameter{
+			Name:        param.Name,
+			Type:        param.Type,
+			Default:     param.Default,
+			Required:    param.Required,
+			Description: param.Description,
+		})
+	}
+	return definitions
+}
+
+// parseTemplateParameters 解析模板中保存的参数定义
+func parseTemplateParameters(data string) ([]jobtemplate.Parameter, error) {
+	var definitions []jobtemplate.Parameter
+	if data == "" {
+		return definitions, nil
+	}
+	if err := json.Unmarshal([]byte(data), &definitions); err != nil {
+		return nil, fmt.Errorf("解析模板参数定义失败: %w", err)
+	}
+	return definitions, nil
+}
+
+// toTrainingTemplateInfo 将训练作业模板模型转换为接口返回结构
+func toTrainingTemplateInfo(template *model.VtTrainingJobTemplates) types.TrainingTemplateInfo {
+	params := make([]types.TrainingTemplateParameter, 0)
+	definitions, _ := parseTemplateParameters(template.Parameters)
+	for _, definition := range definitions {
+		params = append(params, types.TrainingTemplateParameter{
+			Name:        definition.Name,
+			Type:        definition.Type,
+			Default:     definition.Default,
+			Required:    definition.Required,
+			Description: definition.Description,
+		})
+	}
+
+	return types.TrainingTemplateInfo{
+		Id:          template.Id,
+		Name:        template.Name,
+		DisplayName: template.DisplayName,
+		Description: template.Description,
+		OwnerId:     template.OwnerId,
+		OwnerName:   template.OwnerName,
+		WorkspaceId: template.WorkspaceId,
+		Visibility:  template.Visibility,
+		Spec:        template.Spec,
+		Parameters:  params,
+		UseCount:    int64(template.UseCount),
+		LastUsedAt:  formatTime(template.LastUsedAt),
+		CreatedAt:   template.CreatedAt.Format(timeLayout),
+		UpdatedAt:   template.UpdatedAt.Format(timeLayout),
+	}
+}
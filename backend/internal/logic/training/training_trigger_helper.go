@@ -0,0 +1,135 @@
+package training
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/model"
+	bizerrors "api/pkg/errors"
+	"api/pkg/scheduler"
+)
+
+// findOwnedTrigger 查询用户创建的定时触发器，其他用户视为不存在
+func findOwnedTrigger(svcCtx *svc.ServiceContext, id, userId int64) (*model.VtTrainingTriggers, error) {
+	trigger, err := svcCtx.VtTrainingTriggersModel.FindOne(id)
+	if err == sql.ErrNoRows {
+		return nil, bizerrors.ErrTriggerNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if trigger.OwnerId != userId {
+		return nil, bizerrors.ErrTriggerNotFound
+	}
+	return trigger, nil
+}
+
+// invalidTrigger 构造触发器配置错误
+func invalidTrigger(format string, args ...interface{}) error {
+	return bizerrors.NewBizError(bizerrors.ErrCodeTriggerInvalid, fmt.Sprintf(format, args...), bizerrors.ErrorTypeValidation)
+}
+
+// validateTrigger 校验触发器的调度配置，并按当前时间计算下一次触发时间
+// 未启用的触发器不计算触发时间
+func validateTrigger(trigger *model.VtTrainingTriggers, now time.Time) error {
+	switch trigger.ConcurrencyPolicy {
+	case model.TriggerConcurrencyAllow, model.TriggerConcurrencyForbid, model.TriggerConcurrencyReplace:
+	default:
+		return invalidTrigger("不支持的并发策略 '%s'", trigger.ConcurrencyPolicy)
+	}
+	if trigger.StartingDeadlineSeconds < 0 {
+		return invalidTrigger("补触发期限不能为负数")
+	}
+	if trigger.Parameters != "" {
+		var values map[string]interface{}
+		if err := json.Unmarshal([]byte(trigger.Parameters), &values); err != nil {
+			return invalidTrigger("触发参数必须是JSON对象: %v", err)
+		}
+	}
+
+	next, err := scheduler.NextTriggerTime(trigger.Schedule, trigger.Timezone, now)
+	if err != nil {
+		return invalidTrigger("%v", err)
+	}
+	if next == nil {
+		return invalidTrigger("cron表达式 '%s' 永远不会触发", trigger.Schedule)
+	}
+	trigger.NextRunAt = nil
+	if trigger.Enabled {
+		trigger.NextRunAt = next
+	}
+	return nil
+}
+
+// checkTriggerTarget 校验触发目标存在，模板目标需对触发器创建人可见
+func checkTriggerTarget(svcCtx *svc.ServiceContext, targetType string, targetId, userId int64) error {
+	switch targetType {
+	case model.TriggerTargetJob:
+		_, err := svcCtx.VtTrainingJobsModel.FindOneDetail(targetId)
+		if err == sql.ErrNoRows {
+			return bizerrors.ErrJobNotFound
+		}
+		return err
+	case model.TriggerTargetTemplate:
+		_, err := findVisibleTemplate(svcCtx, targetId, userId)
+		return err
+	default:
+		return invalidTrigger("不支持的触发目标类型 '%s'", targetType)
+	}
+}
+
+// toTrainingTriggerInfo 将定时触发器模型转换为接口返回结构
+func toTrainingTriggerInfo(trigger *model.VtTrainingTriggers) types.TrainingTriggerInfo {
+	return types.TrainingTriggerInfo{
+		Id:                      trigger.Id,
+		Name:                    trigger.Name,
+		Description:             trigger.Description,
+		OwnerId:                 trigger.OwnerId,
+		OwnerName:               trigger.OwnerName,
+		TargetType:              trigger.TargetType,
+		TargetId:                trigger.TargetId,
+		Parameters:              trigger.Parameters,
+		Schedule:                trigger.Schedule,
+		Timezone:                trigger.Timezone,
+		ConcurrencyPolicy:       trigger.ConcurrencyPolicy,
+		StartingDeadlineSeconds: int64(trigger.StartingDeadlineSeconds),
+		Enabled:                 trigger.Enabled,
+		NextRunAt:               formatTriggerTime(trigger.NextRunAt, trigger.Timezone),
+		LastRunAt:               formatTriggerTime(trigger.LastRunAt, trigger.Timezone),
+		LastJobId:               trigger.LastJobId,
+		RunCount:                int64(trigger.RunCount),
+		CreatedAt:               trigger.CreatedAt.Format(timeLayout),
+		UpdatedAt:               trigger.UpdatedAt.Format(timeLayout),
+	}
+}
+
+// formatTriggerTime 按触发器时区格式化触发时间
+func formatTriggerTime(t *time.Time, timezone string) string {
+	if t == nil {
+		return ""
+	}
+	if loc, err := time.LoadLocation(timezone); err == nil {
+		return t.In(loc).Format(timeLayout)
+	}
+	return t.Format(timeLayout)
+}
+
+// toTriggerRunInfo 将触发记录转换为接口返回结构
+func toTriggerRunInfo(run *model.VtTrainingTriggerRuns, timezone string) types.TriggerRunInfo {
+	info := types.TriggerRunInfo{
+		Id:          run.Id,
+		ScheduledAt: formatTriggerTime(&run.ScheduledAt, timezone),
+		Status:      run.Status,
+		JobId:       run.JobId,
+		Message:     run.Message,
+		CreatedAt:   run.CreatedAt.Format(timeLayout),
+	}
+	if run.ReplacedJobIds != "" {
+		_ = json.Unmarshal([]byte(run.ReplacedJobIds), &info.ReplacedJobIds)
+	}
+	return info
+}
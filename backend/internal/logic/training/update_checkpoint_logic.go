@@ -24,8 +24,45 @@ func NewUpdateCheckpointLogic(ctx context.Context, svcCtx *svc.ServiceContext) *
 	}
 }
 
+// UpdateCheckpoint 更新检查点的类型、标签、元数据和描述
+// isBest、isLatest为true时将该检查点设为作业唯一的最佳或最新检查点，为false时不修改
 func (l *UpdateCheckpointLogic) UpdateCheckpoint(req *types.UpdateCheckpointReq) (resp *types.EmptyResp, err error) {
-	// todo: add your logic here and delete this line
+	c, err := findCheckpoint(l.svcCtx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCheckpointEnum("检查点类型", req.CheckpointType, checkpointTypes); err != nil {
+		return nil, err
+	}
+	if (req.IsBest || req.IsLatest) && c.Status != "saved" {
+		return nil, invalidCheckpoint("只有已保存的检查点可以设为最佳或最新检查点")
+	}
 
-	return
+	if req.CheckpointType != "" {
+		c.CheckpointType = req.CheckpointType
+	}
+	if req.Tags != "" {
+		c.Tags = req.Tags
+	}
+	if req.Metadata != "" {
+		c.Metadata = req.Metadata
+	}
+	if req.Description != "" {
+		c.Description = req.Description
+	}
+	if err := l.svcCtx.VtTrainingCheckpointsModel.Update(c); err != nil {
+		l.Errorf("更新检查点失败: ID=%d, %v", c.Id, err)
+		return nil, err
+	}
+	if req.IsBest {
+		if err := l.svcCtx.VtTrainingCheckpointsModel.MarkBest(c.JobId, c.Id); err != nil {
+			return nil, err
+		}
+	}
+	if req.IsLatest {
+		if err := l.svcCtx.VtTrainingCheckpointsModel.MarkLatest(c.JobId, c.Id); err != nil {
+			return nil, err
+		}
+	}
+	return &types.EmptyResp{}, nil
 }
@@ -0,0 +1,69 @@
+package training
+
+import (
+	"context"
+
+	"api/internal/svc"
+	"api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UpdateCheckpointRetentionPolicyLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 更新检查点保留策略
+func NewUpdateCheckpointRetentionPolicyLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateCheckpointRetentionPolicyLogic {
+	return &UpdateCheckpointRetentionPolicyLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateCheckpointRetentionPolicyLogic) UpdateCheckpointRetentionPolicy(req *types.UpdateCheckpointRetentionPolicyReq) (resp *types.EmptyResp, err error) {
+	policy, err := findRetentionPolicy(l.svcCtx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		policy.Name = *req.Name
+	}
+	if req.KeepLast != nil {
+		policy.KeepLast = int(*req.KeepLast)
+	}
+	if req.KeepBest != nil {
+		policy.KeepBest = int(*req.KeepBest)
+	}
+	if req.BestMetric != nil {
+		policy.BestMetric = *req.BestMetric
+	}
+	if req.BestGoal != nil {
+		policy.BestGoal = *req.BestGoal
+	}
+	if req.KeepEveryNEpochs != nil {
+		policy.KeepEveryNEpochs = int(*req.KeepEveryNEpochs)
+	}
+	if req.Enabled != nil {
+		policy.Enabled = *req.Enabled
+	}
+	if req.Description != nil {
+		policy.Description = *req.Description
+	}
+
+	if err := validateRetentionPolicy(policy); err != nil {
+		return nil, err
+	}
+	if err := l.svcCtx.VtCheckpointRetentionPoliciesModel.Update(policy); err != nil {
+		l.Logger.Errorf("更新检查点保留策略失败: ID=%d, %v", policy.Id, err)
+		return nil, err
+	}
+
+	l.Logger.Infof("检查点保留策略更新成功: ID=%d, keepLast=%d, keepBest=%d(%s), keepEveryNEpochs=%d, 启用=%v",
+		policy.Id, policy.KeepLast, policy.KeepBest, policy.BestMetric, policy.KeepEveryNEpochs, policy.Enabled)
+	return &types.EmptyResp{}, nil
+}
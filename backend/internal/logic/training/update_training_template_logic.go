@@ -0,0 +1,63 @@
+package training
+
+import (
+	"context"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/pkg/middleware"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UpdateTrainingTemplateLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 更新训练作业模板
+func NewUpdateTrainingTemplateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateTrainingTemplateLogic {
+	return &UpdateTrainingTemplateLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateTrainingTemplateLogic) UpdateTrainingTemplate(req *types.UpdateTrainingTemplateReq) (resp *types.EmptyResp, err error) {
+	template, err := findOwnedTemplate(l.svcCtx, req.Id, middleware.GetUserIDFromContext(l.ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DisplayName != "" {
+		template.DisplayName = req.DisplayName
+	}
+	if req.Description != "" {
+		template.Description = req.Description
+	}
+	if req.Visibility != "" {
+		template.Visibility = req.Visibility
+	}
+	if req.Spec != "" {
+		template.Spec = req.Spec
+	}
+
+	// 内容和参数定义需要一起校验，未传入参数定义时沿用原有定义
+	params := req.Parameters
+	if params == nil {
+		params = toTrainingTemplateInfo(template).Parameters
+	}
+	parameters, err := validateTemplate(template.Visibility, template.WorkspaceId, template.Spec, params)
+	if err != nil {
+		return nil, err
+	}
+	template.Parameters = parameters
+
+	if err := l.svcCtx.VtTrainingJobTemplatesModel.Update(template); err != nil {
+		l.Logger.Errorf("更新训练作业模板失败: ID=%d, %v", req.Id, err)
+		return nil, err
+	}
+	return &types.EmptyResp{}, nil
+}
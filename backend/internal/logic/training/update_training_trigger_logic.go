@@ -0,0 +1,68 @@
+package training
+
+import (
+	"context"
+	"time"
+
+	"api/internal/svc"
+	"api/internal/types"
+	"api/pkg/middleware"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UpdateTrainingTriggerLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 更新定时触发器
+func NewUpdateTrainingTriggerLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateTrainingTriggerLogic {
+	return &UpdateTrainingTriggerLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateTrainingTriggerLogic) UpdateTrainingTrigger(req *types.UpdateTrainingTriggerReq) (resp *types.EmptyResp, err error) {
+	trigger, err := findOwnedTrigger(l.svcCtx, req.Id, middleware.GetUserIDFromContext(l.ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Description != nil {
+		trigger.Description = *req.Description
+	}
+	if req.Parameters != nil {
+		trigger.Parameters = *req.Parameters
+	}
+	if req.Schedule != nil {
+		trigger.Schedule = *req.Schedule
+	}
+	if req.Timezone != nil {
+		trigger.Timezone = *req.Timezone
+	}
+	if req.ConcurrencyPolicy != nil {
+		trigger.ConcurrencyPolicy = *req.ConcurrencyPolicy
+	}
+	if req.StartingDeadlineSeconds != nil {
+		trigger.StartingDeadlineSeconds = int(*req.StartingDeadlineSeconds)
+	}
+	if req.Enabled != nil {
+		trigger.Enabled = *req.Enabled
+	}
+
+	// 调度配置变化或重新启用后从当前时间重新计算下一次触发，不补触发修改前错过的时间
+	if err := validateTrigger(trigger, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := l.svcCtx.VtTrainingTriggersModel.Update(trigger); err != nil {
+		l.Logger.Errorf("更新定时触发器失败: ID=%d, %v", trigger.Id, err)
+		return nil, err
+	}
+
+	l.Logger.Infof("定时触发器更新成功: ID=%d, 调度=%s(%s), 启用=%v", trigger.Id, trigger.Schedule, trigger.Timezone, trigger.Enabled)
+	return &types.EmptyResp{}, nil
+}
@@ -2,12 +2,21 @@ package svc
 
 import (
 	"database/sql"
+	"fmt"
 	"log"
+	"path/filepath"
+	"time"
 
 	"api/internal/config"
 	"api/model"
 	"api/pkg/auth"
+	"api/pkg/checkpoint"
 	"api/pkg/database"
+	"api/pkg/logstream"
+	"api/pkg/monitoring"
+	"api/pkg/notification"
+	"api/pkg/scheduler"
+	"api/pkg/volcano"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -29,13 +38,35 @@ type ServiceContext struct {
 	VtPermissionsModel model.VtPermissionsModel
 
 	// 训练相关模型
-	VtTrainingQueuesModel model.VtTrainingQueuesModel
-	VtTrainingJobsModel   model.VtTrainingJobsModel
+	VtTrainingQueuesModel              model.VtTrainingQueuesModel
+	VtTrainingJobsModel                model.VtTrainingJobsModel
+	VtTrainingJobTransitionsModel      model.VtTrainingJobTransitionsModel
+	VtTrainingJobInstancesModel        model.VtTrainingJobInstancesModel
+	VtTrainingJobRetriesModel          model.VtTrainingJobRetriesModel
+	VtTrainingCheckpointsModel         model.VtTrainingCheckpointsModel
+	VtTrainingJobTemplatesModel        model.VtTrainingJobTemplatesModel
+	VtWorkspaceMembersModel            model.VtWorkspaceMembersModel
+	VtTrainingSweepsModel              model.VtTrainingSweepsModel
+	VtTrainingJobRelationsModel        model.VtTrainingJobRelationsModel
+	VtTrainingMetricsModel             model.VtTrainingMetricsModel
+	VtTrainingTriggersModel            model.VtTrainingTriggersModel
+	VtTrainingTriggerRunsModel         model.VtTrainingTriggerRunsModel
+	VtTrainingLogsModel                model.VtTrainingLogsModel
+	VtCheckpointRetentionPoliciesModel model.VtCheckpointRetentionPoliciesModel
 
 	// GPU相关模型
-	VtGpuClustersModel model.VtGpuClustersModel
-	VtGpuNodesModel    model.VtGpuNodesModel
-	VtGpuDevicesModel  model.VtGpuDevicesModel
+	VtGpuClustersModel          model.VtGpuClustersModel
+	VtGpuNodesModel             model.VtGpuNodesModel
+	VtGpuDevicesModel           model.VtGpuDevicesModel
+	VtGpuDeviceAllocationsModel model.VtGpuDeviceAllocationsModel
+	// GPU清单同步，手动同步接口始终可用，EnableInventorySync控制是否定期同步
+	GpuInventory *scheduler.GPUInventorySyncer
+	// GPU分配租约检查，释放超过预期使用时长的分配
+	GpuLeaseExpirer *scheduler.GPULeaseExpirer
+	// DCGM监控数据采集，EnableTelemetry关闭时为nil
+	GpuTelemetry *scheduler.GPUTelemetryCollector
+	// GPU故障节点隔离和维修后解除隔离
+	GpuRemediator *scheduler.GPUNodeRemediator
 
 	// 监控相关模型
 	VtMonitorDataModel           model.VtMonitorDataModel
@@ -44,6 +75,41 @@ type ServiceContext struct {
 	VtAlertRulesModel            model.VtAlertRulesModel
 	VtNotificationChannelsModel  model.VtNotificationChannelsModel
 	VtNotificationTemplatesModel model.VtNotificationTemplatesModel
+
+	// 通知管理器（未启用通知时为nil）
+	NotificationManager *notification.NotificationManager
+
+	// 训练作业状态机
+	JobStateMachine *scheduler.JobStateMachine
+
+	// 作业依赖流水线
+	JobPipeline *scheduler.JobPipeline
+
+	// 超参数搜索
+	SweepTracker      *scheduler.SweepTracker
+	SweepController   *scheduler.SweepController   // 由RegisterJobCreator创建，未启用时为nil
+	TriggerController *scheduler.TriggerController // 由RegisterJobCreator创建，未启用时为nil
+
+	// 训练日志归档，下载和检索归档日志不依赖K8s
+	LogArchive *logstream.Archive
+	// 检查点登记簿，检查点文件保存在配置的文件系统目录或对象存储中（存储配置无效时为nil）
+	CheckpointManager *checkpoint.CheckpointManager
+	// 检查点垃圾回收，手动回收接口始终可用，EnableCheckpointGC控制是否定期回收（检查点存储不可用时为nil）
+	CheckpointGC *scheduler.CheckpointGC
+	// TensorBoard事件导入，读取共享存储上的事件文件，不依赖K8s（未启用时为nil）
+	TfeventsImporter *scheduler.TfeventsImporter
+
+	// Volcano相关服务（K8s不可用时为nil）
+	VolcanoClient *volcano.Client
+	JobManager    *volcano.JobManager
+	JobDispatcher *scheduler.JobDispatcher
+	JobReconciler *scheduler.JobReconciler
+	JobRetrier    *scheduler.JobRetrier
+	JobWatchdog   *scheduler.JobWatchdog
+	EarlyStopper  *scheduler.EarlyStopper
+	LogStreamer   *logstream.Streamer
+	LogArchiver   *scheduler.LogArchiver
+	Tensorboards  *scheduler.TensorboardController
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
@@ -77,7 +143,7 @@ func NewServiceContext(c config.Config) *ServiceContext {
 	// 初始化JWT服务（go-zero 项目下本仓库提供的实现需要 4 个参数）
 	jwtService := auth.NewJWTService(c.Auth.AccessSecret, c.Auth.RefreshSecret, c.Auth.AccessExpire, c.Auth.RefreshExpire)
 
-	return &ServiceContext{
+	svcCtx := &ServiceContext{
 		Config:         c,
 		DB:             db,
 		DBManager:      dbManager,
@@ -91,12 +157,26 @@ func NewServiceContext(c config.Config) *ServiceContext {
 		VtRolesModel:       model.NewVtRolesModel(db),
 		VtPermissionsModel: model.NewVtPermissionsModel(db),
 
-		VtTrainingQueuesModel: model.NewVtTrainingQueuesModel(db),
-		VtTrainingJobsModel:   model.NewVtTrainingJobsModel(db),
+		VtTrainingQueuesModel:              model.NewVtTrainingQueuesModel(db),
+		VtTrainingJobsModel:                model.NewVtTrainingJobsModel(db),
+		VtTrainingJobTransitionsModel:      model.NewVtTrainingJobTransitionsModel(db),
+		VtTrainingJobInstancesModel:        model.NewVtTrainingJobInstancesModel(db),
+		VtTrainingJobRetriesModel:          model.NewVtTrainingJobRetriesModel(db),
+		VtTrainingCheckpointsModel:         model.NewVtTrainingCheckpointsModel(db),
+		VtTrainingJobTemplatesModel:        model.NewVtTrainingJobTemplatesModel(db),
+		VtWorkspaceMembersModel:            model.NewVtWorkspaceMembersModel(db),
+		VtTrainingSweepsModel:              model.NewVtTrainingSweepsModel(db),
+		VtTrainingJobRelationsModel:        model.NewVtTrainingJobRelationsModel(db),
+		VtTrainingMetricsModel:             model.NewVtTrainingMetricsModel(db),
+		VtTrainingTriggersModel:            model.NewVtTrainingTriggersModel(db),
+		VtTrainingTriggerRunsModel:         model.NewVtTrainingTriggerRunsModel(db),
+		VtTrainingLogsModel:                model.NewVtTrainingLogsModel(db),
+		VtCheckpointRetentionPoliciesModel: model.NewVtCheckpointRetentionPoliciesModel(db),
 
-		VtGpuClustersModel: model.NewVtGpuClustersModel(db),
-		VtGpuNodesModel:    model.NewVtGpuNodesModel(db),
-		VtGpuDevicesModel:  model.NewVtGpuDevicesModel(db),
+		VtGpuClustersModel:          model.NewVtGpuClustersModel(db),
+		VtGpuNodesModel:             model.NewVtGpuNodesModel(db),
+		VtGpuDevicesModel:           model.NewVtGpuDevicesModel(db),
+		VtGpuDeviceAllocationsModel: model.NewVtGpuDeviceAllocationsModel(db),
 
 		VtMonitorDataModel:           model.NewVtMonitorDataModel(db),
 		VtMonitorMetricsModel:        model.NewVtMonitorMetricsModel(db),
@@ -105,4 +185,343 @@ func NewServiceContext(c config.Config) *ServiceContext {
 		VtNotificationChannelsModel:  model.NewVtNotificationChannelsModel(db),
 		VtNotificationTemplatesModel: model.NewVtNotificationTemplatesModel(db),
 	}
+
+	if c.Notification.Enabled {
+		svcCtx.NotificationManager = notification.NewNotificationManager(db, &notification.NotificationConfig{
+			MaxQueueSize:         1000,
+			MaxConcurrentSenders: 2,
+			RetryMaxAttempts:     3,
+			RetryBackoffSeconds:  30,
+			RateLimitPerMinute:   60,
+			TimeoutSeconds:       30,
+			FailedRetentionDays:  7,
+			EnableDeduplication:  true,
+			DeduplicationWindow:  10 * time.Minute,
+		})
+	}
+
+	// 初始化Volcano客户端
+	volcanoClient, err := volcano.NewClient(c.K8s.ConfigPath, c.K8s.Namespace)
+	if err != nil {
+		log.Printf("Warning: Failed to create Volcano client: %v", err)
+		volcanoClient = nil // K8s不可用时作业只入库，不会提交到集群
+	}
+
+	// 状态机始终可用，K8s不可用时仅支持无需操作集群的状态变更
+	var controller scheduler.VolcanoJobController
+	if volcanoClient != nil {
+		controller = volcanoClient
+	}
+	svcCtx.JobStateMachine = scheduler.NewJobStateMachine(svcCtx.VtTrainingJobsModel, svcCtx.VtTrainingJobTransitionsModel, controller)
+	svcCtx.JobPipeline = scheduler.NewJobPipeline(svcCtx.VtTrainingJobsModel, svcCtx.VtTrainingJobRelationsModel, svcCtx.VtTrainingCheckpointsModel, svcCtx.JobStateMachine)
+	svcCtx.SweepTracker = scheduler.NewSweepTracker(svcCtx.VtTrainingJobsModel, svcCtx.VtTrainingJobRelationsModel, svcCtx.VtTrainingMetricsModel)
+	svcCtx.LogArchive = logstream.NewArchive(c.Storage.LogsPath)
+	if storage, err := newCheckpointStorage(c.Storage); err != nil {
+		log.Printf("Warning: Failed to create checkpoint storage: %v", err)
+	} else {
+		svcCtx.CheckpointManager = checkpoint.NewCheckpointManager(svcCtx.VtTrainingCheckpointsModel, storage)
+		svcCtx.CheckpointGC = scheduler.NewCheckpointGC(svcCtx.VtCheckpointRetentionPoliciesModel, svcCtx.VtTrainingCheckpointsModel, svcCtx.VtTrainingJobsModel,
+			svcCtx.VtTrainingQueuesModel, svcCtx.VtTrainingJobRelationsModel, svcCtx.CheckpointManager, scheduler.CheckpointGCConfig{
+				Interval: time.Duration(c.Training.CheckpointGCInterval) * time.Second,
+				DryRun:   c.Training.CheckpointGCDryRun,
+			})
+	}
+	// 检查点存储不可用时仍按记录选择恢复用的检查点，但不挂载或下载检查点文件
+	svcCtx.JobStateMachine.SetCheckpointResumer(scheduler.NewCheckpointResumer(svcCtx.VtTrainingCheckpointsModel, svcCtx.CheckpointManager, scheduler.ResumeConfig{
+		DownloaderImage: c.Training.ResumeDownloaderImage,
+		URLExpires:      time.Duration(c.Training.ResumeURLExpires) * time.Second,
+		PVCName:         c.Storage.CheckpointPVC,
+		MountPath:       c.Storage.CheckpointPath,
+	}))
+	var telemetry volcano.GPUTelemetry
+	if c.Gpu.EnableTelemetry {
+		source, err := newDCGMSource(c.Gpu)
+		if err != nil {
+			log.Printf("Warning: Failed to create DCGM telemetry source: %v", err)
+		} else {
+			svcCtx.GpuTelemetry = scheduler.NewGPUTelemetryCollector(source, svcCtx.VtGpuDevicesModel, svcCtx.VtGpuNodesModel,
+				svcCtx.VtMonitorMetricsModel, svcCtx.VtMonitorDataModel, scheduler.GPUTelemetryConfig{
+					Interval: time.Duration(c.Gpu.TelemetryInterval) * time.Second,
+				})
+			svcCtx.GpuTelemetry.SetInstanceUsageRecorder(svcCtx.VtTrainingJobInstancesModel)
+			telemetry = svcCtx.GpuTelemetry
+		}
+	}
+	gpuClients := newGPUClusterClient(volcanoClient, c.K8s.Namespace, telemetry)
+	svcCtx.GpuInventory = scheduler.NewGPUInventorySyncer(svcCtx.VtGpuClustersModel, svcCtx.VtGpuNodesModel, svcCtx.VtGpuDevicesModel,
+		gpuClients, scheduler.GPUInventoryConfig{
+			Interval: time.Duration(c.Gpu.InventorySyncInterval) * time.Second,
+		})
+	svcCtx.GpuRemediator = scheduler.NewGPUNodeRemediator(svcCtx.VtGpuClustersModel, svcCtx.VtGpuNodesModel, svcCtx.VtGpuDevicesModel,
+		svcCtx.VtTrainingJobsModel, svcCtx.JobStateMachine, gpuClients)
+	if svcCtx.GpuTelemetry != nil && c.Gpu.AutoCordon {
+		svcCtx.GpuTelemetry.SetFaultHandler(svcCtx.GpuRemediator)
+	}
+	svcCtx.GpuLeaseExpirer = scheduler.NewGPULeaseExpirer(svcCtx.VtGpuDeviceAllocationsModel, scheduler.GPULeaseConfig{
+		Interval: time.Duration(c.Gpu.LeaseCheckInterval) * time.Second,
+	})
+	if c.Training.EnableTfeventsImport {
+		svcCtx.TfeventsImporter = scheduler.NewTfeventsImporter(svcCtx.VtTrainingJobsModel, svcCtx.VtTrainingMetricsModel, scheduler.TfeventsImporterConfig{
+			Interval: time.Duration(c.Training.TfeventsImportInterval) * time.Second,
+			Grace:    time.Duration(c.Training.LogArchiveGrace) * time.Second,
+			Root:     c.Storage.WorkspacePath,
+			StateDir: filepath.Join(c.Storage.LogsPath, ".tfevents"),
+		})
+	}
+
+	if volcanoClient != nil {
+		svcCtx.VolcanoClient = volcanoClient
+		svcCtx.JobManager = volcano.NewJobManager(volcanoClient)
+		svcCtx.LogStreamer = logstream.NewStreamer(logstream.NewKubePodSource(volcanoClient.KubeClientset()), logstream.Config{})
+		if c.Training.EnableLogArchiver {
+			svcCtx.LogArchiver = scheduler.NewLogArchiver(svcCtx.VtTrainingJobsModel, svcCtx.VtTrainingJobInstancesModel, svcCtx.VtTrainingLogsModel,
+				svcCtx.LogStreamer, svcCtx.LogArchive, scheduler.LogArchiverConfig{
+					Interval:   time.Duration(c.Training.LogArchiveInterval) * time.Second,
+					ChunkLines: c.Training.LogArchiveChunkLines,
+					Grace:      time.Duration(c.Training.LogArchiveGrace) * time.Second,
+				})
+		}
+		if c.Training.EnableTensorboard {
+			svcCtx.Tensorboards = scheduler.NewTensorboardController(svcCtx.VtTrainingJobsModel, svcCtx.JobManager, scheduler.TensorboardConfig{
+				Interval: time.Duration(c.Training.TensorboardInterval) * time.Second,
+				TTL:      time.Duration(c.Training.TensorboardTTL) * time.Second,
+				Image:    c.Training.TensorboardImage,
+			})
+		}
+		if c.Training.EnableDispatcher {
+			svcCtx.JobDispatcher = scheduler.NewJobDispatcher(svcCtx.VtTrainingJobsModel, svcCtx.JobStateMachine, svcCtx.JobManager, svcCtx.JobPipeline, scheduler.DispatcherConfig{
+				Namespace:          c.K8s.Namespace,
+				Interval:           time.Duration(c.Training.DispatchInterval) * time.Second,
+				BatchSize:          c.Training.DispatchBatchSize,
+				MaxSubmitAttempts:  c.Training.MaxSubmitAttempts,
+				BackoffBase:        time.Duration(c.Training.SubmitBackoffBase) * time.Second,
+				BackoffMax:         time.Duration(c.Training.SubmitBackoffMax) * time.Second,
+				MetricsEndpoint:    c.Training.MetricsEndpoint,
+				MetricsTokenSecret: metricsTokenSecret(c),
+			})
+		}
+		if c.Training.EnableReconciler {
+			svcCtx.JobReconciler = scheduler.NewJobReconciler(svcCtx.VtTrainingJobsModel, svcCtx.VtTrainingJobInstancesModel, svcCtx.JobStateMachine,
+				volcanoClient.VolcanoClientset(), volcanoClient.KubeClientset(), scheduler.ReconcilerConfig{
+					Namespace:      c.K8s.Namespace,
+					ResyncInterval: time.Duration(c.Training.ReconcileInterval) * time.Second,
+				})
+		}
+		svcCtx.JobRetrier = scheduler.NewJobRetrier(svcCtx.VtTrainingJobsModel, svcCtx.VtTrainingJobRetriesModel, svcCtx.JobStateMachine,
+			svcCtx.JobDispatcher, scheduler.RetrierConfig{
+				Interval:    time.Duration(c.Training.RetryInterval) * time.Second,
+				BackoffBase: time.Duration(c.Training.RetryBackoffBase) * time.Second,
+				BackoffMax:  time.Duration(c.Training.RetryBackoffMax) * time.Second,
+			})
+		if c.Training.EnableWatchdog {
+			var notifier scheduler.JobNotifier
+			if svcCtx.NotificationManager != nil {
+				notifier = svcCtx.NotificationManager
+			}
+			svcCtx.JobWatchdog = scheduler.NewJobWatchdog(svcCtx.VtTrainingJobsModel, svcCtx.VtTrainingJobInstancesModel, svcCtx.JobStateMachine,
+				notifier, scheduler.WatchdogConfig{
+					Interval:         time.Duration(c.Training.WatchdogInterval) * time.Second,
+					WarnBefore:       time.Duration(c.Training.WatchdogWarnBefore) * time.Second,
+					IdleGPUThreshold: c.Training.IdleGpuThreshold,
+					NotifyChannels:   c.Training.WatchdogNotifyChannels,
+				})
+			if svcCtx.GpuTelemetry == nil && c.Training.IdleGpuThreshold > 0 {
+				log.Printf("Warning: GPU telemetry is disabled, idle GPU termination of training jobs will not take effect")
+			}
+		}
+		if c.Training.EnableEarlyStopping {
+			svcCtx.EarlyStopper = scheduler.NewEarlyStopper(svcCtx.VtTrainingJobsModel, svcCtx.VtTrainingMetricsModel, svcCtx.VtTrainingCheckpointsModel,
+				svcCtx.JobStateMachine, scheduler.EarlyStopConfig{
+					Interval:    time.Duration(c.Training.EarlyStopInterval) * time.Second,
+					GracePeriod: time.Duration(c.Training.EarlyStopGracePeriod) * time.Second,
+				})
+			svcCtx.JobStateMachine.SetEarlyStopper(svcCtx.EarlyStopper)
+		}
+	}
+
+	return svcCtx
+}
+
+// newCheckpointStorage 按配置创建检查点文件存储
+func newCheckpointStorage(c config.StorageConfig) (checkpoint.CheckpointStorage, error) {
+	if c.CheckpointBackend != "s3" {
+		return checkpoint.NewFileSystemCheckpointStorage(c.CheckpointPath), nil
+	}
+	return checkpoint.NewS3CheckpointStorage(checkpoint.S3Config{
+		Endpoint:  c.CheckpointS3.Endpoint,
+		Region:    c.CheckpointS3.Region,
+		Bucket:    c.CheckpointS3.Bucket,
+		Prefix:    c.CheckpointS3.Prefix,
+		AccessKey: c.CheckpointS3.AccessKey,
+		SecretKey: c.CheckpointS3.SecretKey,
+	})
+}
+
+// newGPUClusterClient 按集群登记的kubeconfig访问集群，未登记kubeconfig的集群使用本服务所在的K8s集群
+func newGPUClusterClient(defaultClient *volcano.Client, namespace string, telemetry volcano.GPUTelemetry) scheduler.GPUClusterClient {
+	return func(cluster *model.VtGpuClusters) (*volcano.GPUManager, error) {
+		client := defaultClient
+		if cluster.KubeConfig != "" {
+			var err error
+			if client, err = volcano.NewClientFromKubeConfig([]byte(cluster.KubeConfig), namespace); err != nil {
+				return nil, err
+			}
+		} else if client == nil {
+			return nil, fmt.Errorf("集群 %s 未配置kubeconfig且K8s客户端不可用", cluster.Name)
+		}
+		manager := volcano.NewGPUManager(client)
+		if telemetry != nil {
+			manager.SetTelemetry(telemetry)
+		}
+		return manager, nil
+	}
+}
+
+// newDCGMSource 创建DCGM监控数据源，优先直接抓取exporter
+func newDCGMSource(c config.GpuConfig) (monitoring.DCGMSource, error) {
+	if len(c.DCGMExporterURLs) > 0 {
+		return monitoring.NewDCGMExporterSource(c.DCGMExporterURLs, 0), nil
+	}
+	if c.TelemetryPrometheusURL == "" {
+		return nil, fmt.Errorf("未配置DCGMExporterURLs或TelemetryPrometheusURL")
+	}
+	prometheus, err := monitoring.NewPrometheusClient(c.TelemetryPrometheusURL)
+	if err != nil {
+		return nil, err
+	}
+	return monitoring.NewPrometheusDCGMSource(prometheus), nil
+}
+
+// metricsTokenSecret 作业指标上报令牌的密钥，未单独配置时使用JWT密钥
+func metricsTokenSecret(c config.Config) string {
+	if c.Training.MetricsTokenSecret != "" {
+		return c.Training.MetricsTokenSecret
+	}
+	return c.Auth.AccessSecret
+}
+
+// MetricsTokenSecret 校验作业指标上报令牌使用的密钥
+func (s *ServiceContext) MetricsTokenSecret() string {
+	return metricsTokenSecret(s.Config)
+}
+
+// RegisterJobCreator 注册常规训练作业创建流程，并创建依赖它的后台任务
+// 创建流程位于logic层，需要在服务上下文创建完成后由启动代码注册
+func (s *ServiceContext) RegisterJobCreator(creator scheduler.JobCreator) {
+	if s.Config.Training.EnableSweeps {
+		s.SweepController = scheduler.NewSweepController(s.VtTrainingSweepsModel, s.VtTrainingJobRelationsModel, s.SweepTracker,
+			s.JobStateMachine, creator, scheduler.SweepConfig{
+				Interval: time.Duration(s.Config.Training.SweepInterval) * time.Second,
+			})
+	}
+	if s.Config.Training.EnableTriggers {
+		s.TriggerController = scheduler.NewTriggerController(s.VtTrainingTriggersModel, s.VtTrainingTriggerRunsModel, s.VtTrainingJobsModel,
+			s.JobStateMachine, creator, scheduler.TriggerConfig{
+				Interval: time.Duration(s.Config.Training.TriggerInterval) * time.Second,
+			})
+	}
+}
+
+// StartWorkers 启动后台任务
+func (s *ServiceContext) StartWorkers() {
+	if s.NotificationManager != nil {
+		if err := s.NotificationManager.Start(); err != nil {
+			log.Printf("Warning: Failed to start notification manager: %v", err)
+		}
+	}
+	if s.JobDispatcher != nil {
+		s.JobDispatcher.Start()
+	}
+	if s.JobReconciler != nil {
+		s.JobReconciler.Start()
+	}
+	if s.JobRetrier != nil {
+		s.JobRetrier.Start()
+	}
+	if s.JobWatchdog != nil {
+		s.JobWatchdog.Start()
+	}
+	if s.EarlyStopper != nil {
+		s.EarlyStopper.Start()
+	}
+	if s.LogArchiver != nil {
+		s.LogArchiver.Start()
+	}
+	if s.Tensorboards != nil {
+		s.Tensorboards.Start()
+	}
+	if s.TfeventsImporter != nil {
+		s.TfeventsImporter.Start()
+	}
+	if s.CheckpointGC != nil && s.Config.Training.EnableCheckpointGC {
+		s.CheckpointGC.Start()
+	}
+	if s.GpuInventory != nil && s.Config.Gpu.EnableInventorySync {
+		s.GpuInventory.Start()
+	}
+	if s.GpuLeaseExpirer != nil {
+		s.GpuLeaseExpirer.Start()
+	}
+	if s.GpuTelemetry != nil {
+		s.GpuTelemetry.Start()
+	}
+	if s.SweepController != nil {
+		s.SweepController.Start()
+	} else if s.Config.Training.EnableSweeps {
+		log.Printf("Warning: Job creator not registered, hyperparameter sweeps are disabled")
+	}
+	if s.TriggerController != nil {
+		s.TriggerController.Start()
+	} else if s.Config.Training.EnableTriggers {
+		log.Printf("Warning: Job creator not registered, training triggers are disabled")
+	}
+}
+
+// StopWorkers 停止后台任务
+func (s *ServiceContext) StopWorkers() {
+	if s.JobDispatcher != nil {
+		s.JobDispatcher.Stop()
+	}
+	if s.JobReconciler != nil {
+		s.JobReconciler.Stop()
+	}
+	if s.JobRetrier != nil {
+		s.JobRetrier.Stop()
+	}
+	if s.JobWatchdog != nil {
+		s.JobWatchdog.Stop()
+	}
+	if s.EarlyStopper != nil {
+		s.EarlyStopper.Stop()
+	}
+	if s.LogArchiver != nil {
+		s.LogArchiver.Stop()
+	}
+	if s.Tensorboards != nil {
+		s.Tensorboards.Stop()
+	}
+	if s.TfeventsImporter != nil {
+		s.TfeventsImporter.Stop()
+	}
+	if s.CheckpointGC != nil && s.Config.Training.EnableCheckpointGC {
+		s.CheckpointGC.Stop()
+	}
+	if s.GpuInventory != nil && s.Config.Gpu.EnableInventorySync {
+		s.GpuInventory.Stop()
+	}
+	if s.GpuLeaseExpirer != nil {
+		s.GpuLeaseExpirer.Stop()
+	}
+	if s.GpuTelemetry != nil {
+		s.GpuTelemetry.Stop()
+	}
+	if s.SweepController != nil {
+		s.SweepController.Stop()
+	}
+	if s.TriggerController != nil {
+		s.TriggerController.Stop()
+	}
+	if s.NotificationManager != nil {
+		s.NotificationManager.Stop()
+	}
 }
@@ -100,13 +100,17 @@ type AddNodeToClusterResp struct {
 }
 
 type AllocateGpuDeviceReq struct {
-	DeviceIds   []int64 `json:"device_ids" validate:"required"`
-	JobId       int64   `json:"job_id" validate:"required"`
-	UserId      int64   `json:"user_id" validate:"required"`
-	WorkspaceId int64   `json:"workspace_id"`
-	QueueName   string  `json:"queue_name" validate:"required"`
-	Priority    int     `json:"priority"`
-	ExpiresAt   *string `json:"expires_at,omitempty"`
+	DeviceIds               []int64 `json:"device_ids" validate:"required"`
+	JobId                   int64   `json:"job_id" validate:"required"`
+	UserId                  int64   `json:"user_id" validate:"required"`
+	WorkspaceId             int64   `json:"workspace_id"`
+	QueueName               string  `json:"queue_name" validate:"required"`
+	Priority                int     `json:"priority"`
+	ExpiresAt               *string `json:"expires_at,omitempty"`
+	ExpectedDurationSeconds int     `json:"expected_duration_seconds,optional"` // 预期使用时长(秒)，到期自动释放，未设置时按expires_at计算
+	SharingMode             string  `json:"sharing_mode,optional"`              // exclusive, mig, time_slicing, vgpu，共享时每个设备占用一个共享单元
+	MigProfile              string  `json:"mig_profile,optional"`               // MIG规格，如1g.10gb
+	MemoryMb                int     `json:"memory_mb,optional"`                 // vGPU显存(MB)
 }
 
 type AllocateGpuDeviceResp struct {
@@ -251,26 +255,30 @@ type GetGpuUsageRecordResp struct {
 }
 
 type GpuAllocationInfo struct {
-	ID            int64   `json:"id"`
-	DeviceId      int64   `json:"device_id"`
-	DeviceUUID    string  `json:"device_uuid"`
-	DeviceName    string  `json:"device_name"`
-	NodeName      string  `json:"node_name"`
-	ClusterName   string  `json:"cluster_name"`
-	JobId         int64   `json:"job_id"`
-	JobName       string  `json:"job_name"`
-	UserId        int64   `json:"user_id"`
-	Username      string  `json:"username"`
-	WorkspaceId   int64   `json:"workspace_id"`
-	WorkspaceName string  `json:"workspace_name"`
-	QueueName     string  `json:"queue_name"`
-	Status        string  `json:"status"`               // allocated, released, expired
-	Priority      int     `json:"priority"`             // 分配优先级
-	ExpiresAt     *string `json:"expires_at,omitempty"` // 过期时间
-	AllocatedAt   string  `json:"allocated_at"`
-	ReleasedAt    *string `json:"released_at,omitempty"`
-	CreatedAt     string  `json:"created_at"`
-	UpdatedAt     string  `json:"updated_at"`
+	ID             int64   `json:"id"`
+	DeviceId       int64   `json:"device_id"`
+	DeviceUUID     string  `json:"device_uuid"`
+	DeviceName     string  `json:"device_name"`
+	NodeName       string  `json:"node_name"`
+	ClusterName    string  `json:"cluster_name"`
+	JobId          int64   `json:"job_id"`
+	JobName        string  `json:"job_name"`
+	UserId         int64   `json:"user_id"`
+	Username       string  `json:"username"`
+	WorkspaceId    int64   `json:"workspace_id"`
+	WorkspaceName  string  `json:"workspace_name"`
+	QueueName      string  `json:"queue_name"`
+	AllocationType string  `json:"allocation_type"`        // exclusive, shared
+	SharingMode    string  `json:"sharing_mode,omitempty"` // mig, time_slicing, vgpu
+	MigProfile     string  `json:"mig_profile,omitempty"`
+	MemoryMb       int     `json:"memory_mb,omitempty"`
+	Status         string  `json:"status"`               // allocated, released, expired
+	Priority       int     `json:"priority"`             // 分配优先级
+	ExpiresAt      *string `json:"expires_at,omitempty"` // 过期时间
+	AllocatedAt    string  `json:"allocated_at"`
+	ReleasedAt     *string `json:"released_at,omitempty"`
+	CreatedAt      string  `json:"created_at"`
+	UpdatedAt      string  `json:"updated_at"`
 }
 
 type GpuClusterInfo struct {
@@ -564,6 +572,32 @@ type RemoveNodeFromClusterReq struct {
 	NodeId    int64 `path:"nodeId" validate:"required"`
 }
 
+type SyncGpuClusterReq struct {
+	ID int64 `path:"id" validate:"required"`
+}
+
+type SyncGpuClusterResp struct {
+	ClusterId      int64 `json:"cluster_id"`
+	Nodes          int   `json:"nodes"`           // 本次发现的GPU节点数
+	NodesCreated   int   `json:"nodes_created"`   // 新增节点数
+	NodesOffline   int   `json:"nodes_offline"`   // 标记为离线的节点数
+	Devices        int   `json:"devices"`         // 本次发现的GPU设备数
+	DevicesCreated int   `json:"devices_created"` // 新增设备数
+	DevicesOffline int   `json:"devices_offline"` // 标记为离线的设备数
+	TotalGpus      int   `json:"total_gpus"`      // 同步后集群的GPU总数
+	AvailableGpus  int   `json:"available_gpus"`  // 同步后集群的可用GPU数
+}
+
+type UncordonGpuNodeReq struct {
+	ID int64 `path:"id" validate:"required"`
+}
+
+type UncordonGpuNodeResp struct {
+	NodeId           int64  `json:"node_id"`
+	Status           string `json:"status"`            // 解除隔离后的节点状态
+	DevicesRecovered int    `json:"devices_recovered"` // 恢复为healthy的故障设备数
+}
+
 type UpdateGpuClusterReq struct {
 	ID             int64                  `path:"id" validate:"required"`
 	DisplayName    string                 `json:"display_name,optional"`
@@ -623,11 +657,56 @@ type UpdateGpuUsageRecordReq struct {
 }
 
 type CancelTrainingJobReq struct {
-	Id int64 `path:"id"`
+	Id     int64  `path:"id"`
+	Reason string `json:"reason,optional"`
+}
+
+type CancelTrainingSweepReq struct {
+	Id     int64  `path:"id"`
+	Reason string `json:"reason,optional"`
+}
+
+type CheckpointGCItemInfo struct {
+	JobId          int64  `json:"jobId"`
+	CheckpointId   int64  `json:"checkpointId"`
+	CheckpointName string `json:"checkpointName"`
+	Status         string `json:"status"`
+	FileSize       int64  `json:"fileSize"`
+	PolicyId       int64  `json:"policyId"`
+}
+
+type CheckpointRetentionPolicyInfo struct {
+	Id               int64  `json:"id"`
+	Name             string `json:"name"`
+	ScopeType        string `json:"scopeType"`
+	ScopeId          int64  `json:"scopeId"`
+	KeepLast         int64  `json:"keepLast"`
+	KeepBest         int64  `json:"keepBest"`
+	BestMetric       string `json:"bestMetric"`
+	BestGoal         string `json:"bestGoal"`
+	KeepEveryNEpochs int64  `json:"keepEveryNEpochs"`
+	Enabled          bool   `json:"enabled"`
+	Description      string `json:"description,optional"`
+	CreatedBy        int64  `json:"createdBy,optional"`
+	CreatedAt        string `json:"createdAt"`
+	UpdatedAt        string `json:"updatedAt"`
+}
+
+type CloneTrainingJobReq struct {
+	Id                 int64                  `path:"id"`
+	Name               string                 `json:"name,optional"`               // 克隆作业名称，为空时按源作业名称生成
+	Overrides          map[string]interface{} `json:"overrides,optional"`          // 以JSON合并补丁修改的作业规格，字段与创建训练作业请求一致
+	FromBestCheckpoint bool                   `json:"fromBestCheckpoint,optional"` // 从源作业的最佳检查点开始训练
+}
+
+type CloneTrainingJobResp struct {
+	Id                 int64  `json:"id"`
+	Name               string `json:"name"`
+	ResumeCheckpointId int64  `json:"resumeCheckpointId"` // 0表示从头开始训练
 }
 
 type CreateCheckpointReq struct {
-	JobId            int64  `json:"jobId"`
+	JobId            int64  `path:"jobId"`
 	CheckpointName   string `json:"checkpointName"`
 	CheckpointType   string `json:"checkpointType,default=auto"`
 	CheckpointFormat string `json:"checkpointFormat,optional"`
@@ -635,6 +714,7 @@ type CreateCheckpointReq struct {
 	Epoch            int64  `json:"epoch,optional"`
 	GlobalStep       int64  `json:"globalStep,optional"`
 	StoragePath      string `json:"storagePath"`
+	Checksum         string `json:"checksum,optional"`
 	CompressionType  string `json:"compressionType,default=none"`
 	Metrics          string `json:"metrics,optional"`
 	LossValue        string `json:"lossValue,optional"`
@@ -654,6 +734,23 @@ type CreateCheckpointResp struct {
 	Id int64 `json:"id"`
 }
 
+type CreateCheckpointRetentionPolicyReq struct {
+	Name             string `json:"name"`
+	ScopeType        string `json:"scopeType"`
+	ScopeId          int64  `json:"scopeId,optional"`
+	KeepLast         int64  `json:"keepLast,optional"`
+	KeepBest         int64  `json:"keepBest,optional"`
+	BestMetric       string `json:"bestMetric,default=loss"`
+	BestGoal         string `json:"bestGoal,default=minimize"`
+	KeepEveryNEpochs int64  `json:"keepEveryNEpochs,optional"`
+	Enabled          bool   `json:"enabled,default=true"`
+	Description      string `json:"description,optional"`
+}
+
+type CreateCheckpointRetentionPolicyResp struct {
+	Id int64 `json:"id"`
+}
+
 type CreateJobLogReq struct {
 	JobId         int64  `json:"jobId"`
 	InstanceId    int64  `json:"instanceId,optional"`
@@ -677,7 +774,7 @@ type CreateJobLogResp struct {
 }
 
 type CreateJobMetricReq struct {
-	JobId               int64  `json:"jobId"`
+	JobId               int64  `path:"jobId"`
 	InstanceId          int64  `json:"instanceId,optional"`
 	MetricName          string `json:"metricName"`
 	MetricType          string `json:"metricType,default=scalar"`
@@ -698,8 +795,72 @@ type CreateJobMetricResp struct {
 	Id int64 `json:"id"`
 }
 
+type DeleteCheckpointRetentionPolicyReq struct {
+	Id int64 `path:"id"`
+}
+
+type GetCheckpointRetentionPolicyReq struct {
+	Id int64 `path:"id"`
+}
+
+type GetCheckpointRetentionPolicyResp struct {
+	Policy CheckpointRetentionPolicyInfo `json:"policy"`
+}
+
+type ListCheckpointRetentionPoliciesReq struct {
+	Page      int64  `form:"page,default=1"`
+	PageSize  int64  `form:"pageSize,default=20"`
+	ScopeType string `form:"scopeType,optional"`
+}
+
+type ListCheckpointRetentionPoliciesResp struct {
+	Total    int64                           `json:"total"`
+	Policies []CheckpointRetentionPolicyInfo `json:"policies"`
+}
+
+type MetricHistogram struct {
+	Min          float64   `json:"min"`
+	Max          float64   `json:"max"`
+	Num          float64   `json:"num"`
+	Sum          float64   `json:"sum"`
+	SumSquares   float64   `json:"sumSquares"`
+	BucketLimits []float64 `json:"bucketLimits"`
+	BucketCounts []float64 `json:"bucketCounts"`
+}
+
+type MetricSample struct {
+	Name       string           `json:"name"`
+	Type       string           `json:"type,default=scalar"` // scalar, histogram, text
+	Value      float64          `json:"value,optional"`
+	Histogram  *MetricHistogram `json:"histogram,optional"`
+	Text       string           `json:"text,optional"`
+	Step       int64            `json:"step,optional"`
+	Epoch      int64            `json:"epoch,optional"`
+	GlobalStep int64            `json:"globalStep,optional"`
+	BatchIdx   int64            `json:"batchIdx,optional"`
+	Phase      string           `json:"phase,optional"` // train, val, test
+	Tag        string           `json:"tag,optional"`
+	Category   string           `json:"category,optional"`
+	WallTime   float64          `json:"wallTime,optional"` // Unix时间戳(秒)
+}
+
+type PushJobMetricsReq struct {
+	JobId         int64          `path:"jobId"`
+	Authorization string         `header:"Authorization,optional"`
+	Instance      string         `json:"instance,optional"` // 上报指标的实例名（Pod名）
+	Metrics       []MetricSample `json:"metrics"`
+}
+
+type PushJobMetricsResp struct {
+	Accepted      int64    `json:"accepted"`
+	Rejected      int64    `json:"rejected"`
+	Errors        []string `json:"errors,optional"`
+	StopRequested bool     `json:"stopRequested"`       // 作业已被请求提前停止，训练脚本应保存最终检查点后退出
+	StopReason    string   `json:"stopReason,optional"` // 提前停止原因
+}
+
 type CreateJobRelationReq struct {
-	JobId        int64  `json:"jobId"`
+	JobId        int64  `path:"jobId"`
 	EntityType   string `json:"entityType"`
 	EntityId     int64  `json:"entityId"`
 	RelationType string `json:"relationType"`
@@ -713,61 +874,65 @@ type CreateJobRelationResp struct {
 }
 
 type CreateTrainingJobReq struct {
-	Name                      string `json:"name"`
-	DisplayName               string `json:"displayName,optional"`
-	Description               string `json:"description,optional"`
-	JobType                   string `json:"jobType,default=single"`
-	Framework                 string `json:"framework"`
-	FrameworkVersion          string `json:"frameworkVersion,optional"`
-	PythonVersion             string `json:"pythonVersion,default=3.8"`
-	CodeSourceType            string `json:"codeSourceType,default=upload"`
-	CodeSourceConfig          string `json:"codeSourceConfig,optional"`
-	EntryPoint                string `json:"entryPoint"`
-	WorkingDir                string `json:"workingDir,default=/workspace"`
-	Image                     string `json:"image"`
-	ImagePullPolicy           string `json:"imagePullPolicy,default=IfNotPresent"`
-	ImagePullSecrets          string `json:"imagePullSecrets,optional"`
-	DatasetMountConfigs       string `json:"datasetMountConfigs,optional"`
-	DataSourceConfig          string `json:"dataSourceConfig,optional"`
-	ModelConfig               string `json:"modelConfig,optional"`
-	OutputModelName           string `json:"outputModelName,optional"`
-	ModelSaveStrategy         string `json:"modelSaveStrategy,default=best"`
-	CpuCores                  string `json:"cpuCores,optional"`
-	MemoryGb                  string `json:"memoryGb,optional"`
-	GpuCount                  int64  `json:"gpuCount,default=0"`
-	GpuType                   string `json:"gpuType,optional"`
-	GpuMemoryGb               string `json:"gpuMemoryGb,optional"`
-	StorageGb                 string `json:"storageGb,optional"`
-	SharedMemoryGb            string `json:"sharedMemoryGb,optional"`
-	WorkerCount               int64  `json:"workerCount,default=1"`
-	PsCount                   int64  `json:"psCount,default=0"`
-	MasterCount               int64  `json:"masterCount,default=1"`
-	EnvVars                   string `json:"envVars,optional"`
-	CommandArgs               string `json:"commandArgs,optional"`
-	Secrets                   string `json:"secrets,optional"`
-	ConfigMaps                string `json:"configMaps,optional"`
-	VolumeMounts              string `json:"volumeMounts,optional"`
-	QueueName                 string `json:"queueName,default=default"`
-	Priority                  int64  `json:"priority,default=0"`
-	NodeSelector              string `json:"nodeSelector,optional"`
-	Tolerations               string `json:"tolerations,optional"`
-	Affinity                  string `json:"affinity,optional"`
-	MaxRuntimeSeconds         int64  `json:"maxRuntimeSeconds,default=86400"`
-	MaxIdleSeconds            int64  `json:"maxIdleSeconds,default=3600"`
-	AutoRestart               bool   `json:"autoRestart,default=false"`
-	MaxRetryCount             int64  `json:"maxRetryCount,default=3"`
-	MinAvailable              int64  `json:"minAvailable,default=1"`
-	Hyperparameters           string `json:"hyperparameters,optional"`
-	TrainingConfig            string `json:"trainingConfig,optional"`
-	OptimizerConfig           string `json:"optimizerConfig,optional"`
-	SchedulerConfig           string `json:"schedulerConfig,optional"`
-	EnableTensorboard         bool   `json:"enableTensorboard,default=true"`
-	EnableProfiling           bool   `json:"enableProfiling,default=false"`
-	MetricsCollectionInterval int64  `json:"metricsCollectionInterval,default=60"`
-	NotificationConfig        string `json:"notificationConfig,optional"`
-	Tags                      string `json:"tags,optional"`
-	Annotations               string `json:"annotations,optional"`
-	Metadata                  string `json:"metadata,optional"`
+	Name                      string  `json:"name"`
+	DisplayName               string  `json:"displayName,optional"`
+	Description               string  `json:"description,optional"`
+	JobType                   string  `json:"jobType,default=single"`
+	Framework                 string  `json:"framework"`
+	FrameworkVersion          string  `json:"frameworkVersion,optional"`
+	PythonVersion             string  `json:"pythonVersion,default=3.8"`
+	CodeSourceType            string  `json:"codeSourceType,default=upload"`
+	CodeSourceConfig          string  `json:"codeSourceConfig,optional"`
+	EntryPoint                string  `json:"entryPoint"`
+	WorkingDir                string  `json:"workingDir,default=/workspace"`
+	Image                     string  `json:"image"`
+	ImagePullPolicy           string  `json:"imagePullPolicy,default=IfNotPresent"`
+	ImagePullSecrets          string  `json:"imagePullSecrets,optional"`
+	DatasetMountConfigs       string  `json:"datasetMountConfigs,optional"`
+	DataSourceConfig          string  `json:"dataSourceConfig,optional"`
+	ModelConfig               string  `json:"modelConfig,optional"`
+	OutputModelName           string  `json:"outputModelName,optional"`
+	ModelSaveStrategy         string  `json:"modelSaveStrategy,default=best"`
+	CpuCores                  string  `json:"cpuCores,optional"`
+	MemoryGb                  string  `json:"memoryGb,optional"`
+	GpuCount                  int64   `json:"gpuCount,default=0"`
+	GpuType                   string  `json:"gpuType,optional"`
+	GpuMemoryGb               string  `json:"gpuMemoryGb,optional"`
+	GpuSharingMode            string  `json:"gpuSharingMode,optional,options=exclusive|mig|time_slicing|vgpu"`
+	GpuMigProfile             string  `json:"gpuMigProfile,optional"`
+	StorageGb                 string  `json:"storageGb,optional"`
+	SharedMemoryGb            string  `json:"sharedMemoryGb,optional"`
+	WorkerCount               int64   `json:"workerCount,default=1"`
+	PsCount                   int64   `json:"psCount,default=0"`
+	MasterCount               int64   `json:"masterCount,default=1"`
+	EnvVars                   string  `json:"envVars,optional"`
+	CommandArgs               string  `json:"commandArgs,optional"`
+	Secrets                   string  `json:"secrets,optional"`
+	ConfigMaps                string  `json:"configMaps,optional"`
+	VolumeMounts              string  `json:"volumeMounts,optional"`
+	QueueName                 string  `json:"queueName,default=default"`
+	Priority                  int64   `json:"priority,default=0"`
+	NodeSelector              string  `json:"nodeSelector,optional"`
+	Tolerations               string  `json:"tolerations,optional"`
+	Affinity                  string  `json:"affinity,optional"`
+	MaxRuntimeSeconds         int64   `json:"maxRuntimeSeconds,default=86400"`
+	MaxIdleSeconds            int64   `json:"maxIdleSeconds,default=3600"`
+	AutoRestart               bool    `json:"autoRestart,default=false"`
+	MaxRetryCount             int64   `json:"maxRetryCount,default=3"`
+	MinAvailable              int64   `json:"minAvailable,default=1"`
+	Hyperparameters           string  `json:"hyperparameters,optional"`
+	TrainingConfig            string  `json:"trainingConfig,optional"`
+	OptimizerConfig           string  `json:"optimizerConfig,optional"`
+	SchedulerConfig           string  `json:"schedulerConfig,optional"`
+	EnableTensorboard         bool    `json:"enableTensorboard,default=true"`
+	EnableProfiling           bool    `json:"enableProfiling,default=false"`
+	MetricsCollectionInterval int64   `json:"metricsCollectionInterval,default=60"`
+	NotificationConfig        string  `json:"notificationConfig,optional"`
+	Tags                      string  `json:"tags,optional"`
+	Annotations               string  `json:"annotations,optional"`
+	Metadata                  string  `json:"metadata,optional"`
+	DependsOn                 []int64 `json:"dependsOn,optional"`          // 依赖的上游作业ID，上游作业全部成功后才会派发
+	ResumeCheckpointId        int64   `json:"resumeCheckpointId,optional"` // 从已有作业的检查点开始训练
 }
 
 type CreateTrainingJobResp struct {
@@ -803,6 +968,56 @@ type CreateTrainingQueueResp struct {
 	Id int64 `json:"id"`
 }
 
+type CreateTrainingSweepReq struct {
+	Name            string `json:"name"`
+	DisplayName     string `json:"displayName,optional"`
+	Description     string `json:"description,optional"`
+	Algorithm       string `json:"algorithm,default=random"`
+	ObjectiveMetric string `json:"objectiveMetric"`
+	ObjectiveGoal   string `json:"objectiveGoal,default=minimize"`
+	SearchSpace     string `json:"searchSpace"`
+	BaseSpec        string `json:"baseSpec"`
+	MaxTrials       int64  `json:"maxTrials,default=10"`
+	Parallelism     int64  `json:"parallelism,default=2"`
+	EarlyStopping   string `json:"earlyStopping,optional"`
+}
+
+type CreateTrainingSweepResp struct {
+	Id int64 `json:"id"`
+}
+
+type CreateTrainingTemplateReq struct {
+	Name        string                      `json:"name"`
+	DisplayName string                      `json:"displayName,optional"`
+	Description string                      `json:"description,optional"`
+	WorkspaceId int64                       `json:"workspaceId,optional"`
+	Visibility  string                      `json:"visibility,default=private"`
+	Spec        string                      `json:"spec"`
+	Parameters  []TrainingTemplateParameter `json:"parameters,optional"`
+}
+
+type CreateTrainingTemplateResp struct {
+	Id int64 `json:"id"`
+}
+
+type CreateTrainingTriggerReq struct {
+	Name                    string `json:"name"`
+	Description             string `json:"description,optional"`
+	TargetType              string `json:"targetType"` // job, template
+	TargetId                int64  `json:"targetId"`
+	Parameters              string `json:"parameters,optional"` // 目标为模板时为模板参数值，目标为作业时为覆盖作业规格的字段
+	Schedule                string `json:"schedule"`            // 五段cron表达式或@daily等预定义表达式
+	Timezone                string `json:"timezone,optional"`   // 默认使用服务配置的时区
+	ConcurrencyPolicy       string `json:"concurrencyPolicy,default=Allow"`
+	StartingDeadlineSeconds int64  `json:"startingDeadlineSeconds,default=0"` // 错过触发时间后允许补触发的期限，0表示不限制
+	Enabled                 bool   `json:"enabled,default=true"`
+}
+
+type CreateTrainingTriggerResp struct {
+	Id        int64  `json:"id"`
+	NextRunAt string `json:"nextRunAt,optional"`
+}
+
 type DeleteCheckpointReq struct {
 	Id int64 `path:"id"`
 }
@@ -819,6 +1034,14 @@ type DeleteTrainingQueueReq struct {
 	Id int64 `path:"id"`
 }
 
+type DeleteTrainingTemplateReq struct {
+	Id int64 `path:"id"`
+}
+
+type DeleteTrainingTriggerReq struct {
+	Id int64 `path:"id"`
+}
+
 type GetCheckpointReq struct {
 	Id int64 `path:"id"`
 }
@@ -843,10 +1066,12 @@ type GetJobCheckpointsResp struct {
 }
 
 type GetJobInstanceLogsReq struct {
-	Id        int64 `path:"id"`
-	Lines     int64 `form:"lines,default=100"`
-	Follow    bool  `form:"follow,default=false"`
-	Timestamp bool  `form:"timestamp,default=true"`
+	Id           int64  `path:"id"`
+	Lines        int64  `form:"lines,default=100"`
+	Follow       bool   `form:"follow,default=false"`
+	Timestamp    bool   `form:"timestamp,default=true"`
+	SinceSeconds int64  `form:"sinceSeconds,optional"`
+	Level        string `form:"level,optional"`
 }
 
 type GetJobInstanceLogsResp struct {
@@ -870,22 +1095,67 @@ type GetJobInstancesResp struct {
 }
 
 type GetJobLogsReq struct {
-	JobId     int64  `path:"jobId"`
+	JobId        int64  `path:"jobId"`
+	Level        string `form:"level,optional"`
+	Source       string `form:"source,optional"`
+	Category     string `form:"category,optional"`
+	StartTime    string `form:"startTime,optional"`
+	EndTime      string `form:"endTime,optional"`
+	Search       string `form:"search,optional"`
+	Page         int64  `form:"page,default=1"`
+	PageSize     int64  `form:"pageSize,default=100"`
+	Follow       bool   `form:"follow,default=false"`
+	Instance     string `form:"instance,optional"`
+	InstanceType string `form:"instanceType,optional"`
+	SinceSeconds int64  `form:"sinceSeconds,optional"`
+	TailLines    int64  `form:"tailLines,optional"`
+	Offset       string `form:"offset,optional"`
+}
+
+type GetJobLogsResp struct {
+	Total int64             `json:"total"`
+	Logs  []TrainingLogInfo `json:"logs"`
+}
+
+type RunCheckpointGCReq struct {
+	DryRun bool  `json:"dryRun,default=true"`
+	JobId  int64 `json:"jobId,optional"`
+}
+
+type RunCheckpointGCResp struct {
+	DryRun         bool                   `json:"dryRun"`
+	Jobs           int64                  `json:"jobs"`
+	Kept           int64                  `json:"kept"`
+	Removed        []CheckpointGCItemInfo `json:"removed"`
+	ReclaimedBytes int64                  `json:"reclaimedBytes"`
+	Failed         int64                  `json:"failed"`
+}
+
+type SearchTrainingLogsReq struct {
+	JobId     int64  `form:"jobId,optional"`
+	Instance  string `form:"instance,optional"`
 	Level     string `form:"level,optional"`
-	Source    string `form:"source,optional"`
-	Category  string `form:"category,optional"`
+	Keyword   string `form:"keyword,optional"`
+	Regex     string `form:"regex,optional"`
 	StartTime string `form:"startTime,optional"`
 	EndTime   string `form:"endTime,optional"`
-	Search    string `form:"search,optional"`
 	Page      int64  `form:"page,default=1"`
 	PageSize  int64  `form:"pageSize,default=100"`
 }
 
-type GetJobLogsResp struct {
+type SearchTrainingLogsResp struct {
 	Total int64             `json:"total"`
 	Logs  []TrainingLogInfo `json:"logs"`
 }
 
+type DownloadJobLogsReq struct {
+	JobId int64 `path:"jobId"`
+}
+
+type ProxyTensorboardReq struct {
+	JobId int64 `path:"jobId"`
+}
+
 type GetJobMetricsReq struct {
 	JobId      int64  `path:"jobId"`
 	MetricName string `form:"metricName,optional"`
@@ -897,11 +1167,28 @@ type GetJobMetricsReq struct {
 	EndTime    string `form:"endTime,optional"`
 	Page       int64  `form:"page,default=1"`
 	PageSize   int64  `form:"pageSize,default=100"`
+	MaxPoints  int64  `form:"maxPoints,optional"` // 每条曲线的最大点数，默认取服务端配置
 }
 
 type GetJobMetricsResp struct {
 	Total   int64                `json:"total"`
 	Metrics []TrainingMetricInfo `json:"metrics"`
+	Series  []MetricSeriesInfo   `json:"series"`
+}
+
+type MetricSeriesInfo struct {
+	MetricName string                  `json:"metricName"`
+	Phase      string                  `json:"phase,optional"`
+	Total      int64                   `json:"total"` // 降采样前的数据点数
+	Points     []MetricSeriesPointInfo `json:"points"`
+}
+
+type MetricSeriesPointInfo struct {
+	Step     int64   `json:"step"`
+	Value    float64 `json:"value"`
+	Min      float64 `json:"min"`
+	Max      float64 `json:"max"`
+	WallTime float64 `json:"wallTime,optional"`
 }
 
 type GetJobOptionsResp struct {
@@ -913,6 +1200,15 @@ type GetJobOptionsResp struct {
 	PhaseOptions        []LabelValue `json:"phaseOptions"`
 }
 
+type GetJobPipelineReq struct {
+	JobId int64 `path:"jobId"`
+}
+
+type GetJobPipelineResp struct {
+	Status string             `json:"status"`
+	Nodes  []PipelineNodeInfo `json:"nodes"`
+}
+
 type GetJobRelationsReq struct {
 	JobId        int64  `path:"jobId"`
 	EntityType   string `form:"entityType,optional"`
@@ -930,12 +1226,25 @@ type GetQueueOptionsResp struct {
 	StatusOptions      []LabelValue `json:"statusOptions"`
 }
 
+type GetSweepLeaderboardReq struct {
+	Id int64 `path:"id"`
+}
+
+type GetSweepLeaderboardResp struct {
+	ObjectiveMetric string           `json:"objectiveMetric"`
+	ObjectiveGoal   string           `json:"objectiveGoal"`
+	Trials          []SweepTrialInfo `json:"trials"`
+}
+
 type GetTrainingJobReq struct {
 	Id int64 `path:"id"`
 }
 
 type GetTrainingJobResp struct {
-	Job TrainingJobInfo `json:"job"`
+	Job        TrainingJobInfo        `json:"job"`
+	RetryCount int64                  `json:"retryCount"`
+	Retries    []TrainingJobRetryInfo `json:"retries"`
+	Runs       []TrainingJobRunInfo   `json:"runs"` // 重新运行、自动重试和从检查点恢复产生的运行
 }
 
 type GetTrainingQueueReq struct {
@@ -946,6 +1255,53 @@ type GetTrainingQueueResp struct {
 	Queue TrainingQueueInfo `json:"queue"`
 }
 
+type GetTrainingSweepReq struct {
+	Id int64 `path:"id"`
+}
+
+type GetTrainingSweepResp struct {
+	Sweep TrainingSweepInfo `json:"sweep"`
+}
+
+type GetTrainingTemplateReq struct {
+	Id int64 `path:"id"`
+}
+
+type GetTrainingTemplateResp struct {
+	Template TrainingTemplateInfo `json:"template"`
+}
+
+type GetTrainingTriggerReq struct {
+	Id int64 `path:"id"`
+}
+
+type GetTrainingTriggerResp struct {
+	Trigger TrainingTriggerInfo `json:"trigger"`
+}
+
+type GetTriggerRunsReq struct {
+	Id       int64  `path:"id"`
+	Page     int64  `form:"page,default=1"`
+	PageSize int64  `form:"pageSize,default=20"`
+	Status   string `form:"status,optional"`
+}
+
+type GetTriggerRunsResp struct {
+	Total int64            `json:"total"`
+	Runs  []TriggerRunInfo `json:"runs"`
+}
+
+type InstantiateTrainingTemplateReq struct {
+	Id         int64                  `path:"id"`
+	Name       string                 `json:"name,optional"`
+	Parameters map[string]interface{} `json:"parameters,optional"`
+}
+
+type InstantiateTrainingTemplateResp struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
 type ListTrainingJobsReq struct {
 	Page      int64  `form:"page,default=1"`
 	PageSize  int64  `form:"pageSize,default=10"`
@@ -976,16 +1332,88 @@ type ListTrainingQueuesResp struct {
 	Queues []TrainingQueueInfo `json:"queues"`
 }
 
+type ListTrainingSweepsReq struct {
+	Page     int64  `form:"page,default=1"`
+	PageSize int64  `form:"pageSize,default=10"`
+	Status   string `form:"status,optional"`
+	Search   string `form:"search,optional"`
+}
+
+type ListTrainingSweepsResp struct {
+	Total  int64               `json:"total"`
+	Sweeps []TrainingSweepInfo `json:"sweeps"`
+}
+
+type ListTrainingTemplatesReq struct {
+	Page        int64  `form:"page,default=1"`
+	PageSize    int64  `form:"pageSize,default=10"`
+	WorkspaceId int64  `form:"workspaceId,optional"`
+	Search      string `form:"search,optional"`
+}
+
+type ListTrainingTemplatesResp struct {
+	Total     int64                  `json:"total"`
+	Templates []TrainingTemplateInfo `json:"templates"`
+}
+
+type ListTrainingTriggersReq struct {
+	Page       int64  `form:"page,default=1"`
+	PageSize   int64  `form:"pageSize,default=10"`
+	TargetType string `form:"targetType,optional"`
+	Search     string `form:"search,optional"`
+}
+
+type ListTrainingTriggersResp struct {
+	Total    int64                 `json:"total"`
+	Triggers []TrainingTriggerInfo `json:"triggers"`
+}
+
+type PipelineNodeInfo struct {
+	JobId         int64   `json:"jobId"`
+	JobName       string  `json:"jobName"`
+	Status        string  `json:"status"`
+	State         string  `json:"state"` // pending作业细分为ready/waiting/blocked，其余同status
+	FailureReason string  `json:"failureReason,optional"`
+	DependsOn     []int64 `json:"dependsOn"`
+}
+
+type RerunPipelineNodeReq struct {
+	JobId      int64  `path:"jobId"`
+	Downstream bool   `json:"downstream,default=false"`
+	Reason     string `json:"reason,optional"`
+}
+
+type RerunPipelineNodeResp struct {
+	RestartedJobIds []int64 `json:"restartedJobIds"`
+}
+
 type RestartTrainingJobReq struct {
-	Id int64 `path:"id"`
+	Id           int64  `path:"id"`
+	Reason       string `json:"reason,optional"`
+	CheckpointId int64  `json:"checkpointId,optional"` // 从指定检查点恢复，0表示自动选择最新或最佳的可用检查点
+	FromScratch  bool   `json:"fromScratch,optional"`  // 不从检查点恢复
 }
 
 type ResumeTrainingJobReq struct {
-	Id int64 `path:"id"`
+	Id     int64  `path:"id"`
+	Reason string `json:"reason,optional"`
 }
 
 type SuspendTrainingJobReq struct {
-	Id int64 `path:"id"`
+	Id     int64  `path:"id"`
+	Reason string `json:"reason,optional"`
+}
+
+type SweepTrialInfo struct {
+	Rank      int64  `json:"rank"`
+	Trial     int64  `json:"trial"`
+	JobId     int64  `json:"jobId"`
+	JobName   string `json:"jobName"`
+	Status    string `json:"status"`
+	Pruned    bool   `json:"pruned"`
+	Params    string `json:"params"`
+	Objective string `json:"objective,optional"`
+	LastStep  int64  `json:"lastStep"`
 }
 
 type TrainingCheckpointInfo struct {
@@ -1045,6 +1473,8 @@ type TrainingJobInfo struct {
 	GpuCount                  int64  `json:"gpuCount"`
 	GpuType                   string `json:"gpuType,optional"`
 	GpuMemoryGb               string `json:"gpuMemoryGb,optional"`
+	GpuSharingMode            string `json:"gpuSharingMode,optional"`
+	GpuMigProfile             string `json:"gpuMigProfile,optional"`
 	StorageGb                 string `json:"storageGb,optional"`
 	SharedMemoryGb            string `json:"sharedMemoryGb,optional"`
 	WorkerCount               int64  `json:"workerCount"`
@@ -1090,6 +1520,8 @@ type TrainingJobInfo struct {
 	LogsPath                  string `json:"logsPath,optional"`
 	OutputPath                string `json:"outputPath,optional"`
 	CheckpointPath            string `json:"checkpointPath,optional"`
+	ResumeCheckpointId        int64  `json:"resumeCheckpointId,optional"`
+	ResumeCheckpointPath      string `json:"resumeCheckpointPath,optional"`
 	TensorboardPath           string `json:"tensorboardPath,optional"`
 	Hyperparameters           string `json:"hyperparameters,optional"`
 	TrainingConfig            string `json:"trainingConfig,optional"`
@@ -1146,6 +1578,29 @@ type TrainingJobInstanceInfo struct {
 	Annotations         string `json:"annotations,optional"`
 }
 
+type TrainingJobRetryInfo struct {
+	Attempt              int64  `json:"attempt"`
+	FailureReason        string `json:"failureReason"`
+	ExitCode             int64  `json:"exitCode,optional"`
+	ErrorMessage         string `json:"errorMessage,optional"`
+	FailedVolcanoJobName string `json:"failedVolcanoJobName,optional"`
+	RetryVolcanoJobName  string `json:"retryVolcanoJobName,optional"`
+	CheckpointId         int64  `json:"checkpointId,optional"`
+	CheckpointPath       string `json:"checkpointPath,optional"`
+	BackoffSeconds       int64  `json:"backoffSeconds"`
+	FailedAt             string `json:"failedAt,optional"`
+	RetriedAt            string `json:"retriedAt"`
+}
+
+type TrainingJobRunInfo struct {
+	Action             string `json:"action"` // restart、retry或resume
+	VolcanoJobName     string `json:"volcanoJobName"`
+	ResumeCheckpointId int64  `json:"resumeCheckpointId,optional"` // 0表示从头开始训练
+	Reason             string `json:"reason,optional"`
+	OperatorName       string `json:"operatorName,optional"`
+	CreatedAt          string `json:"createdAt"`
+}
+
 type TrainingJobRelationInfo struct {
 	Id           int64  `json:"id"`
 	JobId        int64  `json:"jobId"`
@@ -1237,8 +1692,92 @@ type TrainingQueueInfo struct {
 	UpdatedAt           string `json:"updatedAt"`
 }
 
+type TrainingSweepInfo struct {
+	Id              int64  `json:"id"`
+	Name            string `json:"name"`
+	DisplayName     string `json:"displayName,optional"`
+	Description     string `json:"description,optional"`
+	OwnerId         int64  `json:"ownerId"`
+	OwnerName       string `json:"ownerName,optional"`
+	Algorithm       string `json:"algorithm"`
+	ObjectiveMetric string `json:"objectiveMetric"`
+	ObjectiveGoal   string `json:"objectiveGoal"`
+	SearchSpace     string `json:"searchSpace"`
+	BaseSpec        string `json:"baseSpec"`
+	MaxTrials       int64  `json:"maxTrials"`
+	Parallelism     int64  `json:"parallelism"`
+	EarlyStopping   string `json:"earlyStopping,optional"`
+	Status          string `json:"status"`
+	TrialCount      int64  `json:"trialCount"`
+	CompletedCount  int64  `json:"completedCount"`
+	PrunedCount     int64  `json:"prunedCount"`
+	BestJobId       int64  `json:"bestJobId,optional"`
+	BestValue       string `json:"bestValue,optional"`
+	ErrorMessage    string `json:"errorMessage,optional"`
+	FinishedAt      string `json:"finishedAt,optional"`
+	CreatedAt       string `json:"createdAt"`
+	UpdatedAt       string `json:"updatedAt"`
+}
+
+type TrainingTemplateInfo struct {
+	Id          int64                       `json:"id"`
+	Name        string                      `json:"name"`
+	DisplayName string                      `json:"displayName,optional"`
+	Description string                      `json:"description,optional"`
+	OwnerId     int64                       `json:"ownerId"`
+	OwnerName   string                      `json:"ownerName,optional"`
+	WorkspaceId int64                       `json:"workspaceId"`
+	Visibility  string                      `json:"visibility"`
+	Spec        string                      `json:"spec"`
+	Parameters  []TrainingTemplateParameter `json:"parameters"`
+	UseCount    int64                       `json:"useCount"`
+	LastUsedAt  string                      `json:"lastUsedAt,optional"`
+	CreatedAt   string                      `json:"createdAt"`
+	UpdatedAt   string                      `json:"updatedAt"`
+}
+
+type TrainingTemplateParameter struct {
+	Name        string `json:"name"`
+	Type        string `json:"type,default=string"`
+	Default     string `json:"default,optional"`
+	Required    bool   `json:"required,optional"`
+	Description string `json:"description,optional"`
+}
+
+type TrainingTriggerInfo struct {
+	Id                      int64  `json:"id"`
+	Name                    string `json:"name"`
+	Description             string `json:"description,optional"`
+	OwnerId                 int64  `json:"ownerId"`
+	OwnerName               string `json:"ownerName,optional"`
+	TargetType              string `json:"targetType"` // job, template
+	TargetId                int64  `json:"targetId"`
+	Parameters              string `json:"parameters,optional"`
+	Schedule                string `json:"schedule"`
+	Timezone                string `json:"timezone"`
+	ConcurrencyPolicy       string `json:"concurrencyPolicy"` // Allow, Forbid, Replace
+	StartingDeadlineSeconds int64  `json:"startingDeadlineSeconds"`
+	Enabled                 bool   `json:"enabled"`
+	NextRunAt               string `json:"nextRunAt,optional"`
+	LastRunAt               string `json:"lastRunAt,optional"`
+	LastJobId               int64  `json:"lastJobId,optional"`
+	RunCount                int64  `json:"runCount"`
+	CreatedAt               string `json:"createdAt"`
+	UpdatedAt               string `json:"updatedAt"`
+}
+
+type TriggerRunInfo struct {
+	Id             int64   `json:"id"`
+	ScheduledAt    string  `json:"scheduledAt"`
+	Status         string  `json:"status"` // created, skipped, missed, failed
+	JobId          int64   `json:"jobId,optional"`
+	ReplacedJobIds []int64 `json:"replacedJobIds,optional"`
+	Message        string  `json:"message,optional"`
+	CreatedAt      string  `json:"createdAt"`
+}
+
 type UpdateCheckpointReq struct {
-	Id             int64  `json:"id"`
+	Id             int64  `path:"id"`
 	CheckpointType string `json:"checkpointType,optional"`
 	IsBest         bool   `json:"isBest,optional"`
 	IsLatest       bool   `json:"isLatest,optional"`
@@ -1247,6 +1786,18 @@ type UpdateCheckpointReq struct {
 	Description    string `json:"description,optional"`
 }
 
+type UpdateCheckpointRetentionPolicyReq struct {
+	Id               int64   `path:"id"`
+	Name             *string `json:"name,optional"`
+	KeepLast         *int64  `json:"keepLast,optional"`
+	KeepBest         *int64  `json:"keepBest,optional"`
+	BestMetric       *string `json:"bestMetric,optional"`
+	BestGoal         *string `json:"bestGoal,optional"`
+	KeepEveryNEpochs *int64  `json:"keepEveryNEpochs,optional"`
+	Enabled          *bool   `json:"enabled,optional"`
+	Description      *string `json:"description,optional"`
+}
+
 type UpdateTrainingJobReq struct {
 	Id                 int64  `json:"id"`
 	DisplayName        string `json:"displayName,optional"`
@@ -1287,3 +1838,23 @@ type UpdateTrainingQueueReq struct {
 	Tolerations         string `json:"tolerations,optional"`
 	Status              string `json:"status,optional"`
 }
+
+type UpdateTrainingTemplateReq struct {
+	Id          int64                       `path:"id"`
+	DisplayName string                      `json:"displayName,optional"`
+	Description string                      `json:"description,optional"`
+	Visibility  string                      `json:"visibility,optional"`
+	Spec        string                      `json:"spec,optional"`
+	Parameters  []TrainingTemplateParameter `json:"parameters,optional"`
+}
+
+type UpdateTrainingTriggerReq struct {
+	Id                      int64   `path:"id"`
+	Description             *string `json:"description,optional"`
+	Parameters              *string `json:"parameters,optional"`
+	Schedule                *string `json:"schedule,optional"`
+	Timezone                *string `json:"timezone,optional"`
+	ConcurrencyPolicy       *string `json:"concurrencyPolicy,optional"`
+	StartingDeadlineSeconds *int64  `json:"startingDeadlineSeconds,optional"`
+	Enabled                 *bool   `json:"enabled,optional"`
+}
@@ -0,0 +1,140 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// 检查点保留策略作用范围
+const (
+	RetentionScopeGlobal    = "global"
+	RetentionScopeWorkspace = "workspace"
+	RetentionScopeQueue     = "queue"
+	RetentionScopeJob       = "job"
+)
+
+// VtCheckpointRetentionPolicies 检查点保留策略模型
+type VtCheckpointRetentionPolicies struct {
+	Id               int64     `db:"id" json:"id"`
+	Name             string    `db:"name" json:"name"`
+	ScopeType        string    `db:"scope_type" json:"scopeType"`
+	ScopeId          int64     `db:"scope_id" json:"scopeId"`
+	KeepLast         int       `db:"keep_last" json:"keepLast"`
+	KeepBest         int       `db:"keep_best" json:"keepBest"`
+	BestMetric       string    `db:"best_metric" json:"bestMetric"`
+	BestGoal         string    `db:"best_goal" json:"bestGoal"`
+	KeepEveryNEpochs int       `db:"keep_every_n_epochs" json:"keepEveryNEpochs"`
+	Enabled          bool      `db:"enabled" json:"enabled"`
+	Description      string    `db:"description" json:"description"`
+	CreatedBy        int64     `db:"created_by" json:"createdBy"`
+	CreatedAt        time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt        time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// VtCheckpointRetentionPoliciesModel 检查点保留策略模型操作接口
+type VtCheckpointRetentionPoliciesModel interface {
+	Insert(data *VtCheckpointRetentionPolicies) (sql.Result, error)
+	FindOne(id int64) (*VtCheckpointRetentionPolicies, error)
+	FindOneByScope(scopeType string, scopeId int64) (*VtCheckpointRetentionPolicies, error)
+	// FindEnabled 查询全部已启用的策略，供垃圾回收按作用范围匹配
+	FindEnabled() ([]*VtCheckpointRetentionPolicies, error)
+	List(scopeType string, page, pageSize int) ([]*VtCheckpointRetentionPolicies, int64, error)
+	// Update 更新保留规则，作用范围不可修改
+	Update(data *VtCheckpointRetentionPolicies) error
+	Delete(id int64) error
+}
+
+type vtCheckpointRetentionPoliciesModel struct {
+	conn *sql.DB
+}
+
+func NewVtCheckpointRetentionPoliciesModel(conn *sql.DB) VtCheckpointRetentionPoliciesModel {
+	return &vtCheckpointRetentionPoliciesModel{conn: conn}
+}
+
+const vtCheckpointRetentionPoliciesFields = `id, name, scope_type, scope_id, keep_last, keep_best, best_metric, best_goal, keep_every_n_epochs, enabled, IFNULL(description, ''), IFNULL(created_by, 0), created_at, updated_at`
+
+func scanVtCheckpointRetentionPolicies(scanner rowScanner) (*VtCheckpointRetentionPolicies, error) {
+	var p VtCheckpointRetentionPolicies
+	err := scanner.Scan(&p.Id, &p.Name, &p.ScopeType, &p.ScopeId, &p.KeepLast, &p.KeepBest, &p.BestMetric, &p.BestGoal,
+		&p.KeepEveryNEpochs, &p.Enabled, &p.Description, &p.CreatedBy, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (m *vtCheckpointRetentionPoliciesModel) Insert(data *VtCheckpointRetentionPolicies) (sql.Result, error) {
+	query := `INSERT INTO vt_checkpoint_retention_policies (name, scope_type, scope_id, keep_last, keep_best, best_metric, best_goal, keep_every_n_epochs, enabled, description, created_by) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	return m.conn.Exec(query, data.Name, data.ScopeType, data.ScopeId, data.KeepLast, data.KeepBest, data.BestMetric, data.BestGoal,
+		data.KeepEveryNEpochs, data.Enabled, data.Description, data.CreatedBy)
+}
+
+func (m *vtCheckpointRetentionPoliciesModel) FindOne(id int64) (*VtCheckpointRetentionPolicies, error) {
+	query := `SELECT ` + vtCheckpointRetentionPoliciesFields + ` FROM vt_checkpoint_retention_policies WHERE id = ?`
+	return scanVtCheckpointRetentionPolicies(m.conn.QueryRow(query, id))
+}
+
+func (m *vtCheckpointRetentionPoliciesModel) FindOneByScope(scopeType string, scopeId int64) (*VtCheckpointRetentionPolicies, error) {
+	query := `SELECT ` + vtCheckpointRetentionPoliciesFields + ` FROM vt_checkpoint_retention_policies WHERE scope_type = ? AND scope_id = ?`
+	return scanVtCheckpointRetentionPolicies(m.conn.QueryRow(query, scopeType, scopeId))
+}
+
+func (m *vtCheckpointRetentionPoliciesModel) FindEnabled() ([]*VtCheckpointRetentionPolicies, error) {
+	query := `SELECT ` + vtCheckpointRetentionPoliciesFields + ` FROM vt_checkpoint_retention_policies WHERE enabled = 1`
+	return m.query(query)
+}
+
+func (m *vtCheckpointRetentionPoliciesModel) List(scopeType string, page, pageSize int) ([]*VtCheckpointRetentionPolicies, int64, error) {
+	whereClause := ""
+	var args []interface{}
+	if scopeType != "" {
+		whereClause = " WHERE scope_type = ?"
+		args = append(args, scopeType)
+	}
+
+	var total int64
+	if err := m.conn.QueryRow(`SELECT COUNT(*) FROM vt_checkpoint_retention_policies`+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	query := `SELECT ` + vtCheckpointRetentionPoliciesFields + ` FROM vt_checkpoint_retention_policies` + whereClause + ` ORDER BY scope_type ASC, scope_id ASC LIMIT ? OFFSET ?`
+	policies, err := m.query(query, append(args, pageSize, (page-1)*pageSize)...)
+	return policies, total, err
+}
+
+func (m *vtCheckpointRetentionPoliciesModel) Update(data *VtCheckpointRetentionPolicies) error {
+	query := `UPDATE vt_checkpoint_retention_policies SET name = ?, keep_last = ?, keep_best = ?, best_metric = ?, best_goal = ?, keep_every_n_epochs = ?, enabled = ?, description = ? WHERE id = ?`
+	_, err := m.conn.Exec(query, data.Name, data.KeepLast, data.KeepBest, data.BestMetric, data.BestGoal, data.KeepEveryNEpochs,
+		data.Enabled, data.Description, data.Id)
+	return err
+}
+
+func (m *vtCheckpointRetentionPoliciesModel) Delete(id int64) error {
+	_, err := m.conn.Exec(`DELETE FROM vt_checkpoint_retention_policies WHERE id = ?`, id)
+	return err
+}
+
+func (m *vtCheckpointRetentionPoliciesModel) query(query string, args ...interface{}) ([]*VtCheckpointRetentionPolicies, error) {
+	rows, err := m.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []*VtCheckpointRetentionPolicies
+	for rows.Next() {
+		p, err := scanVtCheckpointRetentionPolicies(rows)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, p)
+	}
+	return policies, rows.Err()
+}
@@ -5,6 +5,12 @@ import (
 	"time"
 )
 
+// GPU集群类型和状态
+const (
+	GpuClusterTypeK8s      = "k8s"
+	GpuClusterStatusActive = "active"
+)
+
 // VtGpuClusters GPU集群表模型
 type VtGpuClusters struct {
 	Id             int64     `db:"id" json:"id"`
@@ -35,6 +41,7 @@ type VtGpuClustersModel interface {
 	Update(data *VtGpuClusters) error
 	Delete(id int64) error
 	FindAll(page, pageSize int, status, clusterType, region, search string) ([]*VtGpuClusters, int64, error)
+	FindAllByType(clusterType, status string) ([]*VtGpuClusters, error)
 }
 
 // vtGpuClustersModelImpl GPU集群模型实现
@@ -175,3 +182,41 @@ func (m *vtGpuClustersModelImpl) FindAll(page, pageSize int, status, clusterType
 
 	return clusters, total, nil
 }
+
+// FindAllByType 按类型和状态查询全部集群，status为空时不限状态
+func (m *vtGpuClustersModelImpl) FindAllByType(clusterType, status string) ([]*VtGpuClusters, error) {
+	query := `SELECT id, name, display_name, description, cluster_type, status,
+		kube_config, api_endpoint, region, zone,
+		total_nodes, active_nodes, total_gpus, available_gpus, allocated_gpus,
+		resource_labels, metrics_config, created_at, updated_at
+		FROM vt_gpu_clusters WHERE cluster_type = ?`
+	args := []interface{}{clusterType}
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY id"
+
+	rows, err := m.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var clusters []*VtGpuClusters
+	for rows.Next() {
+		var cluster VtGpuClusters
+		err := rows.Scan(
+			&cluster.Id, &cluster.Name, &cluster.DisplayName, &cluster.Description, &cluster.ClusterType, &cluster.Status,
+			&cluster.KubeConfig, &cluster.ApiEndpoint, &cluster.Region, &cluster.Zone,
+			&cluster.TotalNodes, &cluster.ActiveNodes, &cluster.TotalGpus, &cluster.AvailableGpus, &cluster.AllocatedGpus,
+			&cluster.ResourceLabels, &cluster.MetricsConfig, &cluster.CreatedAt, &cluster.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		clusters = append(clusters, &cluster)
+	}
+
+	return clusters, rows.Err()
+}
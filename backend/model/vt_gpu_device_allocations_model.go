@@ -0,0 +1,370 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GPU设备分配状态
+const (
+	GpuAllocationStatusActive   = "active"
+	GpuAllocationStatusReleased = "released"
+	GpuAllocationStatusExpired  = "expired" // 超过预期使用时长后由租约检查自动释放
+)
+
+// GPU设备分配的实体类型和分配类型
+const (
+	GpuAllocationEntityTrainingJob = "training_job"
+	GpuAllocationTypeExclusive     = "exclusive"
+	GpuAllocationTypeShared        = "shared" // MIG实例、时间片副本或vGPU，同一设备可有多个有效分配
+)
+
+// ErrGpuAllocationNotActive 分配已释放或已过期
+var ErrGpuAllocationNotActive = errors.New("gpu allocation is not active")
+
+// GpuDeviceUnavailableError 分配时设备不存在或不可分配，Status为空表示设备不存在，
+// Reason说明共享方式不匹配或共享容量不足
+type GpuDeviceUnavailableError struct {
+	DeviceId     int64
+	Status       string
+	HealthStatus string
+	Reason       string
+}
+
+func (e *GpuDeviceUnavailableError) Error() string {
+	if e.Status == "" {
+		return fmt.Sprintf("gpu device %d not found", e.DeviceId)
+	}
+	if e.Reason != "" {
+		return fmt.Sprintf("gpu device %d is %s/%s: %s", e.DeviceId, e.Status, e.HealthStatus, e.Reason)
+	}
+	return fmt.Sprintf("gpu device %d is %s/%s", e.DeviceId, e.Status, e.HealthStatus)
+}
+
+// VtGpuDeviceAllocations GPU设备分配表模型
+type VtGpuDeviceAllocations struct {
+	Id                      int64      `db:"id" json:"id"`
+	DeviceId                int64      `db:"device_id" json:"deviceId"`
+	EntityType              string     `db:"entity_type" json:"entityType"`
+	EntityId                int64      `db:"entity_id" json:"entityId"`
+	AllocationType          string     `db:"allocation_type" json:"allocationType"`
+	SharingMode             string     `db:"sharing_mode" json:"sharingMode"`
+	MigProfile              string     `db:"mig_profile" json:"migProfile"`
+	MemoryMb                int        `db:"memory_mb" json:"memoryMb"`
+	AllocatedAt             time.Time  `db:"allocated_at" json:"allocatedAt"`
+	ReleasedAt              *time.Time `db:"released_at" json:"releasedAt"`
+	ExpectedDurationSeconds int        `db:"expected_duration_seconds" json:"expectedDurationSeconds"` // 0表示不限时长
+	Priority                int        `db:"priority" json:"priority"`
+	Status                  string     `db:"status" json:"status"`
+	Metadata                string     `db:"metadata" json:"metadata"`
+	CreatedAt               time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt               time.Time  `db:"updated_at" json:"updatedAt"`
+}
+
+// ExpiresAt 租约到期时间，不限时长时返回nil
+func (a *VtGpuDeviceAllocations) ExpiresAt() *time.Time {
+	if a.ExpectedDurationSeconds <= 0 {
+		return nil
+	}
+	expiresAt := a.AllocatedAt.Add(time.Duration(a.ExpectedDurationSeconds) * time.Second)
+	return &expiresAt
+}
+
+// GpuDeviceAllocationRequest 为训练作业分配一组GPU设备，SharingMode为空或exclusive时独占整卡，
+// 否则在每个设备上占用一个共享单元：mig按MigProfile占用一个实例，vgpu另需MemoryMb显存
+type GpuDeviceAllocationRequest struct {
+	DeviceIds               []int64
+	JobId                   int64
+	UserId                  int64
+	Priority                int
+	ExpectedDurationSeconds int
+	Metadata                string
+	AllocatedAt             time.Time
+	SharingMode             string
+	MigProfile              string
+	MemoryMb                int
+}
+
+// Shared 是否为共享分配
+func (r *GpuDeviceAllocationRequest) Shared() bool {
+	return r.SharingMode != "" && r.SharingMode != GpuSharingModeExclusive
+}
+
+// VtGpuDeviceAllocationsModel GPU设备分配模型操作接口
+type VtGpuDeviceAllocationsModel interface {
+	FindOne(id int64) (*VtGpuDeviceAllocations, error)
+	// Allocate 在同一事务中按设备ID顺序以SELECT ... FOR UPDATE锁定设备，全部可分配时为每个设备写入分配记录并标记为occupied，
+	// 共享分配只在设备的共享单元全部占用后标记为occupied；任一设备不可分配时不做任何修改并返回*GpuDeviceUnavailableError
+	Allocate(req *GpuDeviceAllocationRequest) ([]*VtGpuDeviceAllocations, error)
+	// Release 在同一事务中结束分配并释放设备，分配已结束时返回ErrGpuAllocationNotActive
+	Release(id int64, status string, releasedAt time.Time) (*VtGpuDeviceAllocations, error)
+	// FindExpired 查询租约已到期但仍有效的分配
+	FindExpired(now time.Time, limit int) ([]*VtGpuDeviceAllocations, error)
+}
+
+type vtGpuDeviceAllocationsModel struct {
+	conn *sql.DB
+}
+
+func NewVtGpuDeviceAllocationsModel(conn *sql.DB) VtGpuDeviceAllocationsModel {
+	return &vtGpuDeviceAllocationsModel{conn: conn}
+}
+
+const vtGpuDeviceAllocationsFields = `id, device_id, entity_type, entity_id, IFNULL(allocation_type, ''), IFNULL(sharing_mode, ''),
+	IFNULL(mig_profile, ''), IFNULL(memory_mb, 0), allocated_at, released_at,
+	IFNULL(expected_duration_seconds, 0), IFNULL(priority, 0), status, IFNULL(metadata, ''), created_at, updated_at`
+
+func scanVtGpuDeviceAllocations(scanner rowScanner) (*VtGpuDeviceAllocations, error) {
+	var a VtGpuDeviceAllocations
+	err := scanner.Scan(&a.Id, &a.DeviceId, &a.EntityType, &a.EntityId, &a.AllocationType, &a.SharingMode,
+		&a.MigProfile, &a.MemoryMb, &a.AllocatedAt, &a.ReleasedAt,
+		&a.ExpectedDurationSeconds, &a.Priority, &a.Status, &a.Metadata, &a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (m *vtGpuDeviceAllocationsModel) FindOne(id int64) (*VtGpuDeviceAllocations, error) {
+	query := `SELECT ` + vtGpuDeviceAllocationsFields + ` FROM vt_gpu_device_allocations WHERE id = ?`
+	return scanVtGpuDeviceAllocations(m.conn.QueryRow(query, id))
+}
+
+func (m *vtGpuDeviceAllocationsModel) Allocate(req *GpuDeviceAllocationRequest) ([]*VtGpuDeviceAllocations, error) {
+	// 固定加锁顺序，避免两个请求交叉锁定同一组设备时死锁
+	deviceIds := append([]int64(nil), req.DeviceIds...)
+	sort.Slice(deviceIds, func(i, j int) bool { return deviceIds[i] < deviceIds[j] })
+
+	tx, err := m.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(deviceIds)), ", ")
+	args := make([]interface{}, len(deviceIds))
+	for i, id := range deviceIds {
+		args[i] = id
+	}
+	rows, err := tx.Query(`SELECT id, node_id, status, health_status, sharing_mode, share_capacity, IFNULL(mig_devices, ''),
+		IFNULL(memory_total_mb, 0) FROM vt_gpu_devices WHERE id IN (`+placeholders+`) ORDER BY id FOR UPDATE`, args...)
+	if err != nil {
+		return nil, err
+	}
+	locked := make(map[int64]*VtGpuDevices, len(deviceIds))
+	for rows.Next() {
+		var device VtGpuDevices
+		if err := rows.Scan(&device.Id, &device.NodeId, &device.Status, &device.HealthStatus, &device.SharingMode, &device.ShareCapacity,
+			&device.MigDevices, &device.MemoryTotalMb); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		locked[device.Id] = &device
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sharingMode := GpuSharingModeExclusive
+	allocationType := GpuAllocationTypeExclusive
+	if req.Shared() {
+		sharingMode = req.SharingMode
+		allocationType = GpuAllocationTypeShared
+	}
+	nodeIds := make(map[int64]struct{})
+	// full 分配后共享单元全部占用的设备
+	full := make(map[int64]bool, len(deviceIds))
+	for _, id := range deviceIds {
+		device, ok := locked[id]
+		if !ok {
+			return nil, &GpuDeviceUnavailableError{DeviceId: id}
+		}
+		if device.Status != GpuDeviceStatusAvailable || device.HealthStatus != GpuHealthHealthy {
+			return nil, &GpuDeviceUnavailableError{DeviceId: id, Status: device.Status, HealthStatus: device.HealthStatus}
+		}
+		if device.SharingModeOrDefault() != sharingMode {
+			return nil, &GpuDeviceUnavailableError{DeviceId: id, Status: device.Status, HealthStatus: device.HealthStatus,
+				Reason: fmt.Sprintf("设备共享方式为%s，不支持%s分配", device.SharingModeOrDefault(), sharingMode)}
+		}
+		if req.Shared() {
+			used, reason, err := checkShareCapacity(tx, device, req)
+			if err != nil {
+				return nil, err
+			}
+			if reason != "" {
+				return nil, &GpuDeviceUnavailableError{DeviceId: id, Status: device.Status, HealthStatus: device.HealthStatus, Reason: reason}
+			}
+			full[id] = used+1 >= device.ShareCapacityOrDefault()
+		}
+		nodeIds[device.NodeId] = struct{}{}
+	}
+
+	allocations := make([]*VtGpuDeviceAllocations, 0, len(deviceIds))
+	for _, id := range req.DeviceIds {
+		allocation := &VtGpuDeviceAllocations{
+			DeviceId:                id,
+			EntityType:              GpuAllocationEntityTrainingJob,
+			EntityId:                req.JobId,
+			AllocationType:          allocationType,
+			AllocatedAt:             req.AllocatedAt,
+			ExpectedDurationSeconds: req.ExpectedDurationSeconds,
+			Priority:                req.Priority,
+			Status:                  GpuAllocationStatusActive,
+			Metadata:                req.Metadata,
+		}
+		if req.Shared() {
+			allocation.SharingMode = req.SharingMode
+			allocation.MigProfile = req.MigProfile
+			allocation.MemoryMb = req.MemoryMb
+		}
+		result, err := tx.Exec(`INSERT INTO vt_gpu_device_allocations (device_id, entity_type, entity_id, allocation_type, sharing_mode,
+			mig_profile, memory_mb, allocated_at, expected_duration_seconds, priority, status, metadata)
+			VALUES (?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, 0), ?, ?, ?, ?, NULLIF(?, ''))`,
+			allocation.DeviceId, allocation.EntityType, allocation.EntityId, allocation.AllocationType, allocation.SharingMode,
+			allocation.MigProfile, allocation.MemoryMb, allocation.AllocatedAt,
+			allocation.ExpectedDurationSeconds, allocation.Priority, allocation.Status, allocation.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		if allocation.Id, err = result.LastInsertId(); err != nil {
+			return nil, err
+		}
+		if req.Shared() {
+			// 共享设备不记录单个分配，只在共享单元用完时标记为occupied
+			if full[id] {
+				_, err = tx.Exec(`UPDATE vt_gpu_devices SET status = ?, updated_at = NOW() WHERE id = ?`, GpuDeviceStatusOccupied, id)
+			}
+		} else {
+			_, err = tx.Exec(`UPDATE vt_gpu_devices SET status = ?, allocation_id = ?, allocated_job_id = ?, allocated_user_id = ?, allocated_at = ?,
+				updated_at = NOW() WHERE id = ?`, GpuDeviceStatusOccupied, allocation.Id, req.JobId, req.UserId, req.AllocatedAt, id)
+		}
+		if err != nil {
+			return nil, err
+		}
+		allocation.CreatedAt = req.AllocatedAt
+		allocation.UpdatedAt = req.AllocatedAt
+		allocations = append(allocations, allocation)
+	}
+
+	if err := refreshAvailableGpus(tx, nodeIds); err != nil {
+		return nil, err
+	}
+	return allocations, tx.Commit()
+}
+
+// checkShareCapacity 统计设备上有效的共享分配，返回已占用的共享单元数，容量不足以再分配一个单元时返回原因
+func checkShareCapacity(tx *sql.Tx, device *VtGpuDevices, req *GpuDeviceAllocationRequest) (int, string, error) {
+	var used, profileUsed, memoryUsed int
+	err := tx.QueryRow(`SELECT COUNT(*), IFNULL(SUM(mig_profile = ?), 0), IFNULL(SUM(memory_mb), 0) FROM vt_gpu_device_allocations
+		WHERE device_id = ? AND status = ?`, req.MigProfile, device.Id, GpuAllocationStatusActive).Scan(&used, &profileUsed, &memoryUsed)
+	if err != nil {
+		return 0, "", err
+	}
+	if capacity := device.ShareCapacityOrDefault(); used >= capacity {
+		return used, fmt.Sprintf("共享单元已用完(%d/%d)", used, capacity), nil
+	}
+	switch req.SharingMode {
+	case GpuSharingModeMig:
+		instances := device.MigProfiles()[req.MigProfile]
+		if instances == 0 {
+			return used, fmt.Sprintf("设备未划分%s规格的MIG实例", req.MigProfile), nil
+		}
+		if profileUsed >= instances {
+			return used, fmt.Sprintf("%s规格的MIG实例已用完(%d/%d)", req.MigProfile, profileUsed, instances), nil
+		}
+	case GpuSharingModeVgpu:
+		if device.MemoryTotalMb > 0 && memoryUsed+req.MemoryMb > device.MemoryTotalMb {
+			return used, fmt.Sprintf("显存不足，剩余%dMB，需要%dMB", device.MemoryTotalMb-memoryUsed, req.MemoryMb), nil
+		}
+	}
+	return used, "", nil
+}
+
+func (m *vtGpuDeviceAllocationsModel) Release(id int64, status string, releasedAt time.Time) (*VtGpuDeviceAllocations, error) {
+	tx, err := m.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	allocation, err := scanVtGpuDeviceAllocations(tx.QueryRow(`SELECT `+vtGpuDeviceAllocationsFields+` FROM vt_gpu_device_allocations WHERE id = ? FOR UPDATE`, id))
+	if err != nil {
+		return nil, err
+	}
+	if allocation.Status != GpuAllocationStatusActive {
+		return allocation, ErrGpuAllocationNotActive
+	}
+
+	if _, err := tx.Exec(`UPDATE vt_gpu_device_allocations SET status = ?, released_at = ? WHERE id = ?`, status, releasedAt, id); err != nil {
+		return nil, err
+	}
+	// 设备在分配期间可能已被标记为离线或维护，只把occupied恢复为available；共享设备释放一个单元后即可再分配
+	if allocation.AllocationType == GpuAllocationTypeShared {
+		_, err = tx.Exec(`UPDATE vt_gpu_devices SET status = CASE WHEN status = ? THEN ? ELSE status END, updated_at = NOW()
+			WHERE id = ? AND sharing_mode != ?`, GpuDeviceStatusOccupied, GpuDeviceStatusAvailable, allocation.DeviceId, GpuSharingModeExclusive)
+	} else {
+		_, err = tx.Exec(`UPDATE vt_gpu_devices SET status = CASE WHEN status = ? THEN ? ELSE status END,
+			allocation_id = 0, allocated_job_id = 0, allocated_user_id = 0, allocated_at = NULL, updated_at = NOW()
+			WHERE id = ? AND allocation_id = ?`,
+			GpuDeviceStatusOccupied, GpuDeviceStatusAvailable, allocation.DeviceId, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var nodeId int64
+	if err := tx.QueryRow(`SELECT node_id FROM vt_gpu_devices WHERE id = ?`, allocation.DeviceId).Scan(&nodeId); err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if err := refreshAvailableGpus(tx, map[int64]struct{}{nodeId: {}}); err != nil {
+		return nil, err
+	}
+
+	allocation.Status = status
+	allocation.ReleasedAt = &releasedAt
+	allocation.UpdatedAt = releasedAt
+	return allocation, tx.Commit()
+}
+
+// refreshAvailableGpus 按设备状态重新统计节点及所在集群的可用GPU数
+func refreshAvailableGpus(tx *sql.Tx, nodeIds map[int64]struct{}) error {
+	for nodeId := range nodeIds {
+		_, err := tx.Exec(`UPDATE vt_gpu_nodes SET available_gpus = (
+			SELECT COUNT(*) FROM vt_gpu_devices WHERE node_id = ? AND status = ?) WHERE id = ?`, nodeId, GpuDeviceStatusAvailable, nodeId)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`UPDATE vt_gpu_clusters SET available_gpus = (
+			SELECT IFNULL(SUM(available_gpus), 0) FROM vt_gpu_nodes WHERE cluster_id = vt_gpu_clusters.id AND status = ?)
+			WHERE id = (SELECT cluster_id FROM vt_gpu_nodes WHERE id = ?)`, GpuNodeStatusOnline, nodeId)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *vtGpuDeviceAllocationsModel) FindExpired(now time.Time, limit int) ([]*VtGpuDeviceAllocations, error) {
+	query := `SELECT ` + vtGpuDeviceAllocationsFields + ` FROM vt_gpu_device_allocations
+		WHERE status = ? AND expected_duration_seconds > 0 AND DATE_ADD(allocated_at, INTERVAL expected_duration_seconds SECOND) <= ?
+		ORDER BY allocated_at ASC LIMIT ?`
+	rows, err := m.conn.Query(query, GpuAllocationStatusActive, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var allocations []*VtGpuDeviceAllocations
+	for rows.Next() {
+		allocation, err := scanVtGpuDeviceAllocations(rows)
+		if err != nil {
+			return nil, err
+		}
+		allocations = append(allocations, allocation)
+	}
+	return allocations, rows.Err()
+}
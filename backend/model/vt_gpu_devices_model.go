@@ -2,40 +2,94 @@ package model
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 )
 
+// GPU设备状态
+const (
+	GpuDeviceStatusAvailable = "available"
+	GpuDeviceStatusOccupied  = "occupied" // 已被作业独占或共享分配
+	GpuDeviceStatusOffline   = "offline"  // 所在节点离线或节点不再上报该设备
+)
+
+// GPU设备健康状态
+const (
+	GpuHealthHealthy   = "healthy"
+	GpuHealthUnknown   = "unknown"
+	GpuHealthUnhealthy = "unhealthy" // DCGM检测到XID或ECC错误，管理员维修后恢复
+)
+
+// GPU设备共享方式，与volcano包中的取值一致
+const (
+	GpuSharingModeExclusive   = "exclusive"
+	GpuSharingModeMig         = "mig"
+	GpuSharingModeTimeSlicing = "time_slicing"
+	GpuSharingModeVgpu        = "vgpu"
+)
+
 // VtGpuDevices GPU设备表模型
 type VtGpuDevices struct {
-	Id              int64     `db:"id" json:"id"`
-	ClusterId       int64     `db:"cluster_id" json:"clusterId"`
-	NodeId          int64     `db:"node_id" json:"nodeId"`
-	DeviceIndex     int       `db:"device_index" json:"deviceIndex"`
-	DeviceUuid      string    `db:"device_uuid" json:"deviceUuid"`
-	DeviceName      string    `db:"device_name" json:"deviceName"`
-	Brand           string    `db:"brand" json:"brand"`
-	Model           string    `db:"model" json:"model"`
-	Architecture    string    `db:"architecture" json:"architecture"`
-	MemoryTotalMb   int       `db:"memory_total_mb" json:"memoryTotalMb"`
-	MemoryFreeMb    int       `db:"memory_free_mb" json:"memoryFreeMb"`
-	MemoryUsedMb    int       `db:"memory_used_mb" json:"memoryUsedMb"`
-	PowerDrawW      int       `db:"power_draw_w" json:"powerDrawW"`
-	PowerLimitW     int       `db:"power_limit_w" json:"powerLimitW"`
-	TemperatureC    int       `db:"temperature_c" json:"temperatureC"`
-	UtilizationGpu  int       `db:"utilization_gpu" json:"utilizationGpu"`
-	UtilizationMem  int       `db:"utilization_mem" json:"utilizationMem"`
-	Status          string    `db:"status" json:"status"`
-	HealthStatus    string    `db:"health_status" json:"healthStatus"`
-	PcieBusId       string    `db:"pcie_bus_id" json:"pcieBusId"`
-	CudaVersion     string    `db:"cuda_version" json:"cudaVersion"`
-	DriverVersion   string    `db:"driver_version" json:"driverVersion"`
-	AllocationId    int64     `db:"allocation_id" json:"allocationId"`
-	AllocatedJobId  int64     `db:"allocated_job_id" json:"allocatedJobId"`
-	AllocatedUserId int64     `db:"allocated_user_id" json:"allocatedUserId"`
-	AllocatedAt     time.Time `db:"allocated_at" json:"allocatedAt"`
-	LastHeartbeat   time.Time `db:"last_heartbeat" json:"lastHeartbeat"`
-	CreatedAt       time.Time `db:"created_at" json:"createdAt"`
-	UpdatedAt       time.Time `db:"updated_at" json:"updatedAt"`
+	Id              int64      `db:"id" json:"id"`
+	ClusterId       int64      `db:"cluster_id" json:"clusterId"`
+	NodeId          int64      `db:"node_id" json:"nodeId"`
+	DeviceIndex     int        `db:"device_index" json:"deviceIndex"`
+	DeviceUuid      string     `db:"device_uuid" json:"deviceUuid"`
+	DeviceName      string     `db:"device_name" json:"deviceName"`
+	Brand           string     `db:"brand" json:"brand"`
+	Model           string     `db:"model" json:"model"`
+	Architecture    string     `db:"architecture" json:"architecture"`
+	MemoryTotalMb   int        `db:"memory_total_mb" json:"memoryTotalMb"`
+	MemoryFreeMb    int        `db:"memory_free_mb" json:"memoryFreeMb"`
+	MemoryUsedMb    int        `db:"memory_used_mb" json:"memoryUsedMb"`
+	PowerDrawW      int        `db:"power_draw_w" json:"powerDrawW"`
+	PowerLimitW     int        `db:"power_limit_w" json:"powerLimitW"`
+	TemperatureC    int        `db:"temperature_c" json:"temperatureC"`
+	UtilizationGpu  int        `db:"utilization_gpu" json:"utilizationGpu"`
+	UtilizationMem  int        `db:"utilization_mem" json:"utilizationMem"`
+	Status          string     `db:"status" json:"status"`
+	HealthStatus    string     `db:"health_status" json:"healthStatus"`
+	PcieBusId       string     `db:"pcie_bus_id" json:"pcieBusId"`
+	CudaVersion     string     `db:"cuda_version" json:"cudaVersion"`
+	DriverVersion   string     `db:"driver_version" json:"driverVersion"`
+	AllocationId    int64      `db:"allocation_id" json:"allocationId"`
+	AllocatedJobId  int64      `db:"allocated_job_id" json:"allocatedJobId"`
+	AllocatedUserId int64      `db:"allocated_user_id" json:"allocatedUserId"`
+	AllocatedAt     *time.Time `db:"allocated_at" json:"allocatedAt"`
+	LastHeartbeat   *time.Time `db:"last_heartbeat" json:"lastHeartbeat"`
+	SharingMode     string     `db:"sharing_mode" json:"sharingMode"`
+	ShareCapacity   int        `db:"share_capacity" json:"shareCapacity"` // 可同时分配的共享单元数，独占为1
+	MigDevices      string     `db:"mig_devices" json:"migDevices"`       // 各MIG规格的实例数，如{"1g.10gb":7}
+	CreatedAt       time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt       time.Time  `db:"updated_at" json:"updatedAt"`
+}
+
+// SharingModeOrDefault 返回共享方式，未设置时为独占
+func (d *VtGpuDevices) SharingModeOrDefault() string {
+	if d.SharingMode == "" {
+		return GpuSharingModeExclusive
+	}
+	return d.SharingMode
+}
+
+// ShareCapacityOrDefault 返回共享单元数，至少为1
+func (d *VtGpuDevices) ShareCapacityOrDefault() int {
+	if d.ShareCapacity < 1 {
+		return 1
+	}
+	return d.ShareCapacity
+}
+
+// MigProfiles 解析各MIG规格的实例数，未切分或格式错误时返回nil
+func (d *VtGpuDevices) MigProfiles() map[string]int {
+	if d.MigDevices == "" {
+		return nil
+	}
+	var profiles map[string]int
+	if err := json.Unmarshal([]byte(d.MigDevices), &profiles); err != nil {
+		return nil
+	}
+	return profiles
 }
 
 // VtGpuDevicesModel GPU设备模型操作接口
@@ -48,6 +102,13 @@ type VtGpuDevicesModel interface {
 	FindByNodeId(nodeId int64, page, pageSize int, status string) ([]*VtGpuDevices, int64, error)
 	FindAvailableDevices(clusterId int64, gpuCount int) ([]*VtGpuDevices, error)
 	UpdateStatus(id int64, status string) error
+	FindAllByClusterId(clusterId int64) ([]*VtGpuDevices, error)
+	// FindAllOnline 查询全部未离线的设备
+	FindAllOnline() ([]*VtGpuDevices, error)
+	// UpdateTelemetry 只更新设备监控数据、心跳及UUID和PCIe总线ID，不影响分配和状态字段
+	UpdateTelemetry(data *VtGpuDevices) error
+	// UpdateHealth 只更新设备健康状态
+	UpdateHealth(id int64, healthStatus string) error
 }
 
 // vtGpuDevicesModelImpl GPU设备模型实现
@@ -68,25 +129,25 @@ func (m *vtGpuDevicesModelImpl) Insert(data *VtGpuDevices) (sql.Result, error) {
 		memory_total_mb, memory_free_mb, memory_used_mb, power_draw_w, power_limit_w,
 		temperature_c, utilization_gpu, utilization_mem, status, health_status,
 		pcie_bus_id, cuda_version, driver_version, allocation_id, allocated_job_id,
-		allocated_user_id, allocated_at, last_heartbeat
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		allocated_user_id, allocated_at, last_heartbeat, sharing_mode, share_capacity, mig_devices
+	) VALUES (?, ?, ?, NULLIF(?, ''), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''))`
 
 	return m.conn.Exec(query,
 		data.ClusterId, data.NodeId, data.DeviceIndex, data.DeviceUuid, data.DeviceName, data.Brand, data.Model, data.Architecture,
 		data.MemoryTotalMb, data.MemoryFreeMb, data.MemoryUsedMb, data.PowerDrawW, data.PowerLimitW,
 		data.TemperatureC, data.UtilizationGpu, data.UtilizationMem, data.Status, data.HealthStatus,
 		data.PcieBusId, data.CudaVersion, data.DriverVersion, data.AllocationId, data.AllocatedJobId,
-		data.AllocatedUserId, data.AllocatedAt, data.LastHeartbeat,
+		data.AllocatedUserId, data.AllocatedAt, data.LastHeartbeat, data.SharingModeOrDefault(), data.ShareCapacityOrDefault(), data.MigDevices,
 	)
 }
 
 func (m *vtGpuDevicesModelImpl) FindOne(id int64) (*VtGpuDevices, error) {
 	var device VtGpuDevices
-	query := `SELECT id, cluster_id, node_id, device_index, device_uuid, device_name, brand, model, architecture,
+	query := `SELECT id, cluster_id, node_id, device_index, IFNULL(device_uuid, ''), device_name, brand, model, architecture,
 		memory_total_mb, memory_free_mb, memory_used_mb, power_draw_w, power_limit_w,
 		temperature_c, utilization_gpu, utilization_mem, status, health_status,
 		pcie_bus_id, cuda_version, driver_version, allocation_id, allocated_job_id,
-		allocated_user_id, allocated_at, last_heartbeat, created_at, updated_at
+		allocated_user_id, allocated_at, last_heartbeat, sharing_mode, share_capacity, IFNULL(mig_devices, ''), created_at, updated_at
 		FROM vt_gpu_devices WHERE id = ?`
 
 	err := m.conn.QueryRow(query, id).Scan(
@@ -94,7 +155,8 @@ func (m *vtGpuDevicesModelImpl) FindOne(id int64) (*VtGpuDevices, error) {
 		&device.MemoryTotalMb, &device.MemoryFreeMb, &device.MemoryUsedMb, &device.PowerDrawW, &device.PowerLimitW,
 		&device.TemperatureC, &device.UtilizationGpu, &device.UtilizationMem, &device.Status, &device.HealthStatus,
 		&device.PcieBusId, &device.CudaVersion, &device.DriverVersion, &device.AllocationId, &device.AllocatedJobId,
-		&device.AllocatedUserId, &device.AllocatedAt, &device.LastHeartbeat, &device.CreatedAt, &device.UpdatedAt,
+		&device.AllocatedUserId, &device.AllocatedAt, &device.LastHeartbeat, &device.SharingMode, &device.ShareCapacity, &device.MigDevices,
+		&device.CreatedAt, &device.UpdatedAt,
 	)
 
 	if err != nil {
@@ -106,11 +168,12 @@ func (m *vtGpuDevicesModelImpl) FindOne(id int64) (*VtGpuDevices, error) {
 
 func (m *vtGpuDevicesModelImpl) Update(data *VtGpuDevices) error {
 	query := `UPDATE vt_gpu_devices SET 
-		cluster_id = ?, node_id = ?, device_index = ?, device_uuid = ?, device_name = ?, brand = ?, model = ?, architecture = ?,
+		cluster_id = ?, node_id = ?, device_index = ?, device_uuid = NULLIF(?, ''), device_name = ?, brand = ?, model = ?, architecture = ?,
 		memory_total_mb = ?, memory_free_mb = ?, memory_used_mb = ?, power_draw_w = ?, power_limit_w = ?,
 		temperature_c = ?, utilization_gpu = ?, utilization_mem = ?, status = ?, health_status = ?,
 		pcie_bus_id = ?, cuda_version = ?, driver_version = ?, allocation_id = ?, allocated_job_id = ?,
-		allocated_user_id = ?, allocated_at = ?, last_heartbeat = ?, updated_at = NOW()
+		allocated_user_id = ?, allocated_at = ?, last_heartbeat = ?, sharing_mode = ?, share_capacity = ?, mig_devices = NULLIF(?, ''),
+		updated_at = NOW()
 		WHERE id = ?`
 
 	_, err := m.conn.Exec(query,
@@ -118,7 +181,8 @@ func (m *vtGpuDevicesModelImpl) Update(data *VtGpuDevices) error {
 		data.MemoryTotalMb, data.MemoryFreeMb, data.MemoryUsedMb, data.PowerDrawW, data.PowerLimitW,
 		data.TemperatureC, data.UtilizationGpu, data.UtilizationMem, data.Status, data.HealthStatus,
 		data.PcieBusId, data.CudaVersion, data.DriverVersion, data.AllocationId, data.AllocatedJobId,
-		data.AllocatedUserId, data.AllocatedAt, data.LastHeartbeat, data.Id,
+		data.AllocatedUserId, data.AllocatedAt, data.LastHeartbeat, data.SharingModeOrDefault(), data.ShareCapacityOrDefault(), data.MigDevices,
+		data.Id,
 	)
 
 	return err
@@ -176,11 +240,11 @@ func (m *vtGpuDevicesModelImpl) FindAll(page, pageSize int, clusterId, nodeId in
 	}
 
 	// 查询数据
-	query := `SELECT id, cluster_id, node_id, device_index, device_uuid, device_name, brand, model, architecture,
+	query := `SELECT id, cluster_id, node_id, device_index, IFNULL(device_uuid, ''), device_name, brand, model, architecture,
 		memory_total_mb, memory_free_mb, memory_used_mb, power_draw_w, power_limit_w,
 		temperature_c, utilization_gpu, utilization_mem, status, health_status,
 		pcie_bus_id, cuda_version, driver_version, allocation_id, allocated_job_id,
-		allocated_user_id, allocated_at, last_heartbeat, created_at, updated_at
+		allocated_user_id, allocated_at, last_heartbeat, sharing_mode, share_capacity, IFNULL(mig_devices, ''), created_at, updated_at
 		FROM vt_gpu_devices ` + whereClause + ` ORDER BY created_at DESC LIMIT ? OFFSET ?`
 
 	args = append(args, pageSize, offset)
@@ -198,7 +262,8 @@ func (m *vtGpuDevicesModelImpl) FindAll(page, pageSize int, clusterId, nodeId in
 			&device.MemoryTotalMb, &device.MemoryFreeMb, &device.MemoryUsedMb, &device.PowerDrawW, &device.PowerLimitW,
 			&device.TemperatureC, &device.UtilizationGpu, &device.UtilizationMem, &device.Status, &device.HealthStatus,
 			&device.PcieBusId, &device.CudaVersion, &device.DriverVersion, &device.AllocationId, &device.AllocatedJobId,
-			&device.AllocatedUserId, &device.AllocatedAt, &device.LastHeartbeat, &device.CreatedAt, &device.UpdatedAt,
+			&device.AllocatedUserId, &device.AllocatedAt, &device.LastHeartbeat, &device.SharingMode, &device.ShareCapacity, &device.MigDevices,
+			&device.CreatedAt, &device.UpdatedAt,
 		)
 		if err != nil {
 			return nil, 0, err
@@ -222,11 +287,11 @@ func (m *vtGpuDevicesModelImpl) FindAvailableDevices(clusterId int64, gpuCount i
 		args = append(args, clusterId)
 	}
 
-	query := `SELECT id, cluster_id, node_id, device_index, device_uuid, device_name, brand, model, architecture,
+	query := `SELECT id, cluster_id, node_id, device_index, IFNULL(device_uuid, ''), device_name, brand, model, architecture,
 		memory_total_mb, memory_free_mb, memory_used_mb, power_draw_w, power_limit_w,
 		temperature_c, utilization_gpu, utilization_mem, status, health_status,
 		pcie_bus_id, cuda_version, driver_version, allocation_id, allocated_job_id,
-		allocated_user_id, allocated_at, last_heartbeat, created_at, updated_at
+		allocated_user_id, allocated_at, last_heartbeat, sharing_mode, share_capacity, IFNULL(mig_devices, ''), created_at, updated_at
 		FROM vt_gpu_devices ` + whereClause + ` ORDER BY utilization_gpu ASC LIMIT ?`
 
 	args = append(args, gpuCount)
@@ -244,7 +309,8 @@ func (m *vtGpuDevicesModelImpl) FindAvailableDevices(clusterId int64, gpuCount i
 			&device.MemoryTotalMb, &device.MemoryFreeMb, &device.MemoryUsedMb, &device.PowerDrawW, &device.PowerLimitW,
 			&device.TemperatureC, &device.UtilizationGpu, &device.UtilizationMem, &device.Status, &device.HealthStatus,
 			&device.PcieBusId, &device.CudaVersion, &device.DriverVersion, &device.AllocationId, &device.AllocatedJobId,
-			&device.AllocatedUserId, &device.AllocatedAt, &device.LastHeartbeat, &device.CreatedAt, &device.UpdatedAt,
+			&device.AllocatedUserId, &device.AllocatedAt, &device.LastHeartbeat, &device.SharingMode, &device.ShareCapacity, &device.MigDevices,
+			&device.CreatedAt, &device.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -260,3 +326,91 @@ func (m *vtGpuDevicesModelImpl) UpdateStatus(id int64, status string) error {
 	_, err := m.conn.Exec(query, status, id)
 	return err
 }
+
+// UpdateHealth 更新设备健康状态
+func (m *vtGpuDevicesModelImpl) UpdateHealth(id int64, healthStatus string) error {
+	query := `UPDATE vt_gpu_devices SET health_status = ?, updated_at = NOW() WHERE id = ?`
+	_, err := m.conn.Exec(query, healthStatus, id)
+	return err
+}
+
+// FindAllByClusterId 查询集群的全部设备
+func (m *vtGpuDevicesModelImpl) FindAllByClusterId(clusterId int64) ([]*VtGpuDevices, error) {
+	query := `SELECT id, cluster_id, node_id, device_index, IFNULL(device_uuid, ''), device_name, brand, model, architecture,
+		memory_total_mb, memory_free_mb, memory_used_mb, power_draw_w, power_limit_w,
+		temperature_c, utilization_gpu, utilization_mem, status, health_status,
+		pcie_bus_id, cuda_version, driver_version, allocation_id, allocated_job_id,
+		allocated_user_id, allocated_at, last_heartbeat, sharing_mode, share_capacity, IFNULL(mig_devices, ''), created_at, updated_at
+		FROM vt_gpu_devices WHERE cluster_id = ? ORDER BY node_id, device_index`
+
+	rows, err := m.conn.Query(query, clusterId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var devices []*VtGpuDevices
+	for rows.Next() {
+		var device VtGpuDevices
+		err := rows.Scan(
+			&device.Id, &device.ClusterId, &device.NodeId, &device.DeviceIndex, &device.DeviceUuid, &device.DeviceName, &device.Brand, &device.Model, &device.Architecture,
+			&device.MemoryTotalMb, &device.MemoryFreeMb, &device.MemoryUsedMb, &device.PowerDrawW, &device.PowerLimitW,
+			&device.TemperatureC, &device.UtilizationGpu, &device.UtilizationMem, &device.Status, &device.HealthStatus,
+			&device.PcieBusId, &device.CudaVersion, &device.DriverVersion, &device.AllocationId, &device.AllocatedJobId,
+			&device.AllocatedUserId, &device.AllocatedAt, &device.LastHeartbeat, &device.SharingMode, &device.ShareCapacity, &device.MigDevices,
+			&device.CreatedAt, &device.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, &device)
+	}
+
+	return devices, rows.Err()
+}
+
+// FindAllOnline 查询全部未离线的设备
+func (m *vtGpuDevicesModelImpl) FindAllOnline() ([]*VtGpuDevices, error) {
+	query := `SELECT id, cluster_id, node_id, device_index, IFNULL(device_uuid, ''), device_name, brand, model, architecture,
+		memory_total_mb, memory_free_mb, memory_used_mb, power_draw_w, power_limit_w,
+		temperature_c, utilization_gpu, utilization_mem, status, health_status,
+		pcie_bus_id, cuda_version, driver_version, allocation_id, allocated_job_id,
+		allocated_user_id, allocated_at, last_heartbeat, sharing_mode, share_capacity, IFNULL(mig_devices, ''), created_at, updated_at
+		FROM vt_gpu_devices WHERE status != ? ORDER BY node_id, device_index`
+
+	rows, err := m.conn.Query(query, GpuDeviceStatusOffline)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var devices []*VtGpuDevices
+	for rows.Next() {
+		var device VtGpuDevices
+		err := rows.Scan(
+			&device.Id, &device.ClusterId, &device.NodeId, &device.DeviceIndex, &device.DeviceUuid, &device.DeviceName, &device.Brand, &device.Model, &device.Architecture,
+			&device.MemoryTotalMb, &device.MemoryFreeMb, &device.MemoryUsedMb, &device.PowerDrawW, &device.PowerLimitW,
+			&device.TemperatureC, &device.UtilizationGpu, &device.UtilizationMem, &device.Status, &device.HealthStatus,
+			&device.PcieBusId, &device.CudaVersion, &device.DriverVersion, &device.AllocationId, &device.AllocatedJobId,
+			&device.AllocatedUserId, &device.AllocatedAt, &device.LastHeartbeat, &device.SharingMode, &device.ShareCapacity, &device.MigDevices,
+			&device.CreatedAt, &device.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, &device)
+	}
+
+	return devices, rows.Err()
+}
+
+// UpdateTelemetry 更新设备监控数据，与分配事务并发执行时不会覆盖设备状态
+func (m *vtGpuDevicesModelImpl) UpdateTelemetry(data *VtGpuDevices) error {
+	query := `UPDATE vt_gpu_devices SET device_uuid = NULLIF(?, ''), pcie_bus_id = ?, memory_total_mb = ?, memory_free_mb = ?, memory_used_mb = ?,
+		power_draw_w = ?, temperature_c = ?, utilization_gpu = ?, utilization_mem = ?, last_heartbeat = ?, updated_at = NOW()
+		WHERE id = ?`
+
+	_, err := m.conn.Exec(query, data.DeviceUuid, data.PcieBusId, data.MemoryTotalMb, data.MemoryFreeMb, data.MemoryUsedMb,
+		data.PowerDrawW, data.TemperatureC, data.UtilizationGpu, data.UtilizationMem, data.LastHeartbeat, data.Id)
+	return err
+}
@@ -5,29 +5,37 @@ import (
 	"time"
 )
 
+// GPU节点状态
+const (
+	GpuNodeStatusOnline      = "online"      // K8s节点就绪
+	GpuNodeStatusNotReady    = "not_ready"   // K8s节点未就绪
+	GpuNodeStatusOffline     = "offline"     // 节点已从集群中移除或不再上报GPU
+	GpuNodeStatusMaintenance = "maintenance" // 人工维护，同步时保持不变
+)
+
 // VtGpuNodes GPU节点表模型
 type VtGpuNodes struct {
-	Id            int64     `db:"id" json:"id"`
-	ClusterId     int64     `db:"cluster_id" json:"clusterId"`
-	Name          string    `db:"name" json:"name"`
-	Hostname      string    `db:"hostname" json:"hostname"`
-	InternalIp    string    `db:"internal_ip" json:"internalIp"`
-	ExternalIp    string    `db:"external_ip" json:"externalIp"`
-	Status        string    `db:"status" json:"status"`
-	NodeType      string    `db:"node_type" json:"nodeType"`
-	CpuCores      int       `db:"cpu_cores" json:"cpuCores"`
-	MemoryGb      int       `db:"memory_gb" json:"memoryGb"`
-	StorageGb     int       `db:"storage_gb" json:"storageGb"`
-	GpuCount      int       `db:"gpu_count" json:"gpuCount"`
-	AvailableGpus int       `db:"available_gpus" json:"availableGpus"`
-	AllocatedGpus int       `db:"allocated_gpus" json:"allocatedGpus"`
-	OsImage       string    `db:"os_image" json:"osImage"`
-	KernelVersion string    `db:"kernel_version" json:"kernelVersion"`
-	NodeLabels    string    `db:"node_labels" json:"nodeLabels"`
-	NodeTaints    string    `db:"node_taints" json:"nodeTaints"`
-	LastHeartbeat time.Time `db:"last_heartbeat" json:"lastHeartbeat"`
-	CreatedAt     time.Time `db:"created_at" json:"createdAt"`
-	UpdatedAt     time.Time `db:"updated_at" json:"updatedAt"`
+	Id            int64      `db:"id" json:"id"`
+	ClusterId     int64      `db:"cluster_id" json:"clusterId"`
+	Name          string     `db:"name" json:"name"`
+	Hostname      string     `db:"hostname" json:"hostname"`
+	InternalIp    string     `db:"internal_ip" json:"internalIp"`
+	ExternalIp    string     `db:"external_ip" json:"externalIp"`
+	Status        string     `db:"status" json:"status"`
+	NodeType      string     `db:"node_type" json:"nodeType"`
+	CpuCores      int        `db:"cpu_cores" json:"cpuCores"`
+	MemoryGb      int        `db:"memory_gb" json:"memoryGb"`
+	StorageGb     int        `db:"storage_gb" json:"storageGb"`
+	GpuCount      int        `db:"gpu_count" json:"gpuCount"`
+	AvailableGpus int        `db:"available_gpus" json:"availableGpus"`
+	AllocatedGpus int        `db:"allocated_gpus" json:"allocatedGpus"`
+	OsImage       string     `db:"os_image" json:"osImage"`
+	KernelVersion string     `db:"kernel_version" json:"kernelVersion"`
+	NodeLabels    string     `db:"node_labels" json:"nodeLabels"`
+	NodeTaints    string     `db:"node_taints" json:"nodeTaints"`
+	LastHeartbeat *time.Time `db:"last_heartbeat" json:"lastHeartbeat"`
+	CreatedAt     time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt     time.Time  `db:"updated_at" json:"updatedAt"`
 }
 
 // VtGpuNodesModel GPU节点模型操作接口
@@ -38,6 +46,7 @@ type VtGpuNodesModel interface {
 	Delete(id int64) error
 	FindAll(page, pageSize int, clusterId int64, status, nodeType, search string) ([]*VtGpuNodes, int64, error)
 	FindByClusterId(clusterId int64, page, pageSize int, status, nodeType string) ([]*VtGpuNodes, int64, error)
+	FindAllByClusterId(clusterId int64) ([]*VtGpuNodes, error)
 }
 
 // vtGpuNodesModelImpl GPU节点模型实现
@@ -57,7 +66,7 @@ func (m *vtGpuNodesModelImpl) Insert(data *VtGpuNodes) (sql.Result, error) {
 		cluster_id, name, hostname, internal_ip, external_ip, status, node_type,
 		cpu_cores, memory_gb, storage_gb, gpu_count, available_gpus, allocated_gpus,
 		os_image, kernel_version, node_labels, node_taints, last_heartbeat
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), ?)`
 
 	return m.conn.Exec(query,
 		data.ClusterId, data.Name, data.Hostname, data.InternalIp, data.ExternalIp, data.Status, data.NodeType,
@@ -70,7 +79,7 @@ func (m *vtGpuNodesModelImpl) FindOne(id int64) (*VtGpuNodes, error) {
 	var node VtGpuNodes
 	query := `SELECT id, cluster_id, name, hostname, internal_ip, external_ip, status, node_type,
 		cpu_cores, memory_gb, storage_gb, gpu_count, available_gpus, allocated_gpus,
-		os_image, kernel_version, node_labels, node_taints, last_heartbeat, created_at, updated_at
+		IFNULL(os_image, ''), IFNULL(kernel_version, ''), IFNULL(node_labels, ''), IFNULL(node_taints, ''), last_heartbeat, created_at, updated_at
 		FROM vt_gpu_nodes WHERE id = ?`
 
 	err := m.conn.QueryRow(query, id).Scan(
@@ -90,7 +99,7 @@ func (m *vtGpuNodesModelImpl) Update(data *VtGpuNodes) error {
 	query := `UPDATE vt_gpu_nodes SET 
 		cluster_id = ?, name = ?, hostname = ?, internal_ip = ?, external_ip = ?, status = ?, node_type = ?,
 		cpu_cores = ?, memory_gb = ?, storage_gb = ?, gpu_count = ?, available_gpus = ?, allocated_gpus = ?,
-		os_image = ?, kernel_version = ?, node_labels = ?, node_taints = ?, last_heartbeat = ?, updated_at = NOW()
+		os_image = ?, kernel_version = ?, node_labels = NULLIF(?, ''), node_taints = NULLIF(?, ''), last_heartbeat = ?, updated_at = NOW()
 		WHERE id = ?`
 
 	_, err := m.conn.Exec(query,
@@ -144,7 +153,7 @@ func (m *vtGpuNodesModelImpl) FindAll(page, pageSize int, clusterId int64, statu
 	// 查询数据
 	query := `SELECT id, cluster_id, name, hostname, internal_ip, external_ip, status, node_type,
 		cpu_cores, memory_gb, storage_gb, gpu_count, available_gpus, allocated_gpus,
-		os_image, kernel_version, node_labels, node_taints, last_heartbeat, created_at, updated_at
+		IFNULL(os_image, ''), IFNULL(kernel_version, ''), IFNULL(node_labels, ''), IFNULL(node_taints, ''), last_heartbeat, created_at, updated_at
 		FROM vt_gpu_nodes ` + whereClause + ` ORDER BY created_at DESC LIMIT ? OFFSET ?`
 
 	args = append(args, pageSize, offset)
@@ -174,3 +183,33 @@ func (m *vtGpuNodesModelImpl) FindAll(page, pageSize int, clusterId int64, statu
 func (m *vtGpuNodesModelImpl) FindByClusterId(clusterId int64, page, pageSize int, status, nodeType string) ([]*VtGpuNodes, int64, error) {
 	return m.FindAll(page, pageSize, clusterId, status, nodeType, "")
 }
+
+// FindAllByClusterId 查询集群的全部节点
+func (m *vtGpuNodesModelImpl) FindAllByClusterId(clusterId int64) ([]*VtGpuNodes, error) {
+	query := `SELECT id, cluster_id, name, hostname, internal_ip, external_ip, status, node_type,
+		cpu_cores, memory_gb, storage_gb, gpu_count, available_gpus, allocated_gpus,
+		IFNULL(os_image, ''), IFNULL(kernel_version, ''), IFNULL(node_labels, ''), IFNULL(node_taints, ''), last_heartbeat, created_at, updated_at
+		FROM vt_gpu_nodes WHERE cluster_id = ? ORDER BY id`
+
+	rows, err := m.conn.Query(query, clusterId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var nodes []*VtGpuNodes
+	for rows.Next() {
+		var node VtGpuNodes
+		err := rows.Scan(
+			&node.Id, &node.ClusterId, &node.Name, &node.Hostname, &node.InternalIp, &node.ExternalIp, &node.Status, &node.NodeType,
+			&node.CpuCores, &node.MemoryGb, &node.StorageGb, &node.GpuCount, &node.AvailableGpus, &node.AllocatedGpus,
+			&node.OsImage, &node.KernelVersion, &node.NodeLabels, &node.NodeTaints, &node.LastHeartbeat, &node.CreatedAt, &node.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, &node)
+	}
+
+	return nodes, rows.Err()
+}
@@ -0,0 +1,249 @@
+package model
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// VtTrainingCheckpoints 训练检查点表模型
+type VtTrainingCheckpoints struct {
+	Id               int64      `db:"id" json:"id"`
+	JobId            int64      `db:"job_id" json:"jobId"`
+	CheckpointName   string     `db:"checkpoint_name" json:"checkpointName"`
+	CheckpointType   string     `db:"checkpoint_type" json:"checkpointType"`
+	CheckpointFormat string     `db:"checkpoint_format" json:"checkpointFormat"`
+	Step             int64      `db:"step" json:"step"`
+	Epoch            int        `db:"epoch" json:"epoch"`
+	GlobalStep       int64      `db:"global_step" json:"globalStep"`
+	StoragePath      string     `db:"storage_path" json:"storagePath"`
+	FileSize         int64      `db:"file_size" json:"fileSize"`
+	Checksum         string     `db:"checksum" json:"checksum"`
+	CompressionType  string     `db:"compression_type" json:"compressionType"`
+	Metrics          string     `db:"metrics" json:"metrics"`
+	LossValue        string     `db:"loss_value" json:"lossValue"`
+	Accuracy         string     `db:"accuracy" json:"accuracy"`
+	ValidationScore  string     `db:"validation_score" json:"validationScore"`
+	ModelConfig      string     `db:"model_config" json:"modelConfig"`
+	OptimizerState   string     `db:"optimizer_state" json:"optimizerState"`
+	SchedulerState   string     `db:"scheduler_state" json:"schedulerState"`
+	Status           string     `db:"status" json:"status"`
+	IsBest           bool       `db:"is_best" json:"isBest"`
+	IsLatest         bool       `db:"is_latest" json:"isLatest"`
+	Tags             string     `db:"tags" json:"tags"`
+	Metadata         string     `db:"metadata" json:"metadata"`
+	Description      string     `db:"description" json:"description"`
+	CreatedAt        time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt        time.Time  `db:"updated_at" json:"updatedAt"`
+	SavedAt          *time.Time `db:"saved_at" json:"savedAt"`
+}
+
+// TrainingCheckpointFilter 检查点查询条件，零值字段不过滤，不返回已删除的检查点
+type TrainingCheckpointFilter struct {
+	JobId          int64
+	CheckpointType string
+	Status         string
+	IsBest         bool
+	IsLatest       bool
+}
+
+// VtTrainingCheckpointsModel 训练检查点模型操作接口
+type VtTrainingCheckpointsModel interface {
+	// Insert 新增检查点，已删除的同名检查点会被覆盖
+	Insert(data *VtTrainingCheckpoints) (sql.Result, error)
+	FindOne(id int64) (*VtTrainingCheckpoints, error)
+	// FindOneByName 按作业和名称查询检查点，包括已删除的检查点
+	FindOneByName(jobId int64, name string) (*VtTrainingCheckpoints, error)
+	List(filter TrainingCheckpointFilter, page, pageSize int) ([]*VtTrainingCheckpoints, int64, error)
+	FindLatest(jobId int64) (*VtTrainingCheckpoints, error)
+	FindBest(jobId int64) (*VtTrainingCheckpoints, error)
+	// FindByJobId 查询作业全部未删除的检查点，按步数从大到小排序
+	FindByJobId(jobId int64) ([]*VtTrainingCheckpoints, error)
+	// FindJobIds 查询存在未删除检查点的作业ID
+	FindJobIds() ([]int64, error)
+	// Update 更新检查点类型、标签、元数据和描述
+	Update(data *VtTrainingCheckpoints) error
+	UpdateStatus(id int64, status string) error
+	// MarkLatest 将检查点标记为作业的最新检查点，同时清除作业其他检查点的标记
+	MarkLatest(jobId, id int64) error
+	// MarkBest 将检查点标记为作业的最佳检查点，同时清除作业其他检查点的标记
+	MarkBest(jobId, id int64) error
+	// Delete 软删除检查点并清除最新和最佳标记
+	Delete(id int64) error
+}
+
+type vtTrainingCheckpointsModel struct {
+	conn *sql.DB
+}
+
+func NewVtTrainingCheckpointsModel(conn *sql.DB) VtTrainingCheckpointsModel {
+	return &vtTrainingCheckpointsModel{conn: conn}
+}
+
+const vtTrainingCheckpointsFields = `id, job_id, checkpoint_name, IFNULL(checkpoint_type, 'auto'), IFNULL(checkpoint_format, ''), IFNULL(step, 0), IFNULL(epoch, 0), IFNULL(global_step, 0), storage_path, IFNULL(file_size, 0), IFNULL(checksum, ''), IFNULL(compression_type, 'none'), IFNULL(metrics, ''), IFNULL(loss_value, ''), IFNULL(accuracy, ''), IFNULL(validation_score, ''), IFNULL(model_config, ''), IFNULL(optimizer_state, ''), IFNULL(scheduler_state, ''), IFNULL(status, ''), IFNULL(is_best, 0), IFNULL(is_latest, 0), IFNULL(tags, ''), IFNULL(metadata, ''), IFNULL(description, ''), created_at, updated_at, saved_at`
+
+func scanVtTrainingCheckpoints(scanner rowScanner) (*VtTrainingCheckpoints, error) {
+	var c VtTrainingCheckpoints
+	err := scanner.Scan(&c.Id, &c.JobId, &c.CheckpointName, &c.CheckpointType, &c.CheckpointFormat, &c.Step, &c.Epoch, &c.GlobalStep, &c.StoragePath, &c.FileSize, &c.Checksum, &c.CompressionType, &c.Metrics, &c.LossValue, &c.Accuracy, &c.ValidationScore, &c.ModelConfig, &c.OptimizerState, &c.SchedulerState, &c.Status, &c.IsBest, &c.IsLatest, &c.Tags, &c.Metadata, &c.Description, &c.CreatedAt, &c.UpdatedAt, &c.SavedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (m *vtTrainingCheckpointsModel) Insert(data *VtTrainingCheckpoints) (sql.Result, error) {
+	query := `INSERT INTO vt_training_checkpoints (job_id, checkpoint_name, checkpoint_type, checkpoint_format, step, epoch, global_step, storage_path, file_size, checksum, compression_type, metrics, loss_value, accuracy, validation_score, model_config, optimizer_state, scheduler_state, status, is_best, is_latest, tags, metadata, description, saved_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id), checkpoint_type = VALUES(checkpoint_type), checkpoint_format = VALUES(checkpoint_format), step = VALUES(step), epoch = VALUES(epoch), global_step = VALUES(global_step),
+		storage_path = VALUES(storage_path), file_size = VALUES(file_size), checksum = VALUES(checksum), compression_type = VALUES(compression_type), metrics = VALUES(metrics), loss_value = VALUES(loss_value), accuracy = VALUES(accuracy),
+		validation_score = VALUES(validation_score), model_config = VALUES(model_config), optimizer_state = VALUES(optimizer_state), scheduler_state = VALUES(scheduler_state), status = VALUES(status), is_best = VALUES(is_best),
+		is_latest = VALUES(is_latest), tags = VALUES(tags), metadata = VALUES(metadata), description = VALUES(description), saved_at = VALUES(saved_at), created_at = CURRENT_TIMESTAMP`
+	return m.conn.Exec(query, data.JobId, data.CheckpointName, data.CheckpointType, nullableString(data.CheckpointFormat), data.Step, data.Epoch, data.GlobalStep,
+		data.StoragePath, data.FileSize, data.Checksum, data.CompressionType, nullableJSON(data.Metrics), nullableString(data.LossValue), nullableString(data.Accuracy),
+		nullableString(data.ValidationScore), nullableJSON(data.ModelConfig), nullableJSON(data.OptimizerState), nullableJSON(data.SchedulerState), data.Status, data.IsBest,
+		data.IsLatest, nullableJSON(data.Tags), nullableJSON(data.Metadata), data.Description, data.SavedAt)
+}
+
+func (m *vtTrainingCheckpointsModel) FindOne(id int64) (*VtTrainingCheckpoints, error) {
+	query := `SELECT ` + vtTrainingCheckpointsFields + ` FROM vt_training_checkpoints WHERE id = ? AND status != 'deleted'`
+	return scanVtTrainingCheckpoints(m.conn.QueryRow(query, id))
+}
+
+func (m *vtTrainingCheckpointsModel) FindOneByName(jobId int64, name string) (*VtTrainingCheckpoints, error) {
+	query := `SELECT ` + vtTrainingCheckpointsFields + ` FROM vt_training_checkpoints WHERE job_id = ? AND checkpoint_name = ?`
+	return scanVtTrainingCheckpoints(m.conn.QueryRow(query, jobId, name))
+}
+
+func (m *vtTrainingCheckpointsModel) List(filter TrainingCheckpointFilter, page, pageSize int) ([]*VtTrainingCheckpoints, int64, error) {
+	conditions := []string{"job_id = ?", "status != 'deleted'"}
+	args := []interface{}{filter.JobId}
+	if filter.CheckpointType != "" {
+		conditions = append(conditions, "checkpoint_type = ?")
+		args = append(args, filter.CheckpointType)
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.IsBest {
+		conditions = append(conditions, "is_best = 1")
+	}
+	if filter.IsLatest {
+		conditions = append(conditions, "is_latest = 1")
+	}
+	whereClause := " WHERE " + strings.Join(conditions, " AND ")
+
+	var total int64
+	if err := m.conn.QueryRow(`SELECT COUNT(*) FROM vt_training_checkpoints`+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + vtTrainingCheckpointsFields + ` FROM vt_training_checkpoints` + whereClause + ` ORDER BY global_step DESC, step DESC, id DESC LIMIT ? OFFSET ?`
+	rows, err := m.conn.Query(query, append(args, pageSize, (page-1)*pageSize)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var checkpoints []*VtTrainingCheckpoints
+	for rows.Next() {
+		c, err := scanVtTrainingCheckpoints(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		checkpoints = append(checkpoints, c)
+	}
+	return checkpoints, total, rows.Err()
+}
+
+// FindLatest 查询作业最新的已保存检查点，优先使用标记为最新的检查点
+func (m *vtTrainingCheckpointsModel) FindLatest(jobId int64) (*VtTrainingCheckpoints, error) {
+	query := `SELECT ` + vtTrainingCheckpointsFields + ` FROM vt_training_checkpoints WHERE job_id = ? AND status = 'saved' ORDER BY is_latest DESC, global_step DESC, step DESC, id DESC LIMIT 1`
+	return scanVtTrainingCheckpoints(m.conn.QueryRow(query, jobId))
+}
+
+// FindBest 查询作业最佳的已保存检查点，没有标记最佳检查点时返回最新检查点
+func (m *vtTrainingCheckpointsModel) FindBest(jobId int64) (*VtTrainingCheckpoints, error) {
+	query := `SELECT ` + vtTrainingCheckpointsFields + ` FROM vt_training_checkpoints WHERE job_id = ? AND status = 'saved' ORDER BY is_best DESC, checkpoint_type = 'best' DESC, is_latest DESC, global_step DESC, step DESC, id DESC LIMIT 1`
+	return scanVtTrainingCheckpoints(m.conn.QueryRow(query, jobId))
+}
+
+func (m *vtTrainingCheckpointsModel) FindByJobId(jobId int64) ([]*VtTrainingCheckpoints, error) {
+	query := `SELECT ` + vtTrainingCheckpointsFields + ` FROM vt_training_checkpoints WHERE job_id = ? AND status != 'deleted' ORDER BY global_step DESC, step DESC, id DESC`
+	rows, err := m.conn.Query(query, jobId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var checkpoints []*VtTrainingCheckpoints
+	for rows.Next() {
+		c, err := scanVtTrainingCheckpoints(rows)
+		if err != nil {
+			return nil, err
+		}
+		checkpoints = append(checkpoints, c)
+	}
+	return checkpoints, rows.Err()
+}
+
+func (m *vtTrainingCheckpointsModel) FindJobIds() ([]int64, error) {
+	rows, err := m.conn.Query(`SELECT DISTINCT job_id FROM vt_training_checkpoints WHERE status != 'deleted' ORDER BY job_id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobIds []int64
+	for rows.Next() {
+		var jobId int64
+		if err := rows.Scan(&jobId); err != nil {
+			return nil, err
+		}
+		jobIds = append(jobIds, jobId)
+	}
+	return jobIds, rows.Err()
+}
+
+func (m *vtTrainingCheckpointsModel) Update(data *VtTrainingCheckpoints) error {
+	query := `UPDATE vt_training_checkpoints SET checkpoint_type = ?, tags = ?, metadata = ?, description = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND status != 'deleted'`
+	_, err := m.conn.Exec(query, data.CheckpointType, nullableJSON(data.Tags), nullableJSON(data.Metadata), data.Description, data.Id)
+	return err
+}
+
+func (m *vtTrainingCheckpointsModel) UpdateStatus(id int64, status string) error {
+	query := `UPDATE vt_training_checkpoints SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+	_, err := m.conn.Exec(query, status, id)
+	return err
+}
+
+func (m *vtTrainingCheckpointsModel) MarkLatest(jobId, id int64) error {
+	return m.markExclusive("is_latest", jobId, id)
+}
+
+func (m *vtTrainingCheckpointsModel) MarkBest(jobId, id int64) error {
+	return m.markExclusive("is_best", jobId, id)
+}
+
+// markExclusive 在同一事务中清除作业其他检查点的标记并设置目标检查点的标记
+func (m *vtTrainingCheckpointsModel) markExclusive(column string, jobId, id int64) error {
+	tx, err := m.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE vt_training_checkpoints SET `+column+` = 0 WHERE job_id = ? AND id != ? AND `+column+` = 1`, jobId, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE vt_training_checkpoints SET `+column+` = 1, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND job_id = ?`, id, jobId); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (m *vtTrainingCheckpointsModel) Delete(id int64) error {
+	query := `UPDATE vt_training_checkpoints SET status = 'deleted', is_best = 0, is_latest = 0, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+	_, err := m.conn.Exec(query, id)
+	return err
+}
@@ -0,0 +1,151 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// VtTrainingJobInstances 训练任务实例表模型，每个实例对应Volcano作业的一个Pod
+type VtTrainingJobInstances struct {
+	Id                 int64      `db:"id" json:"id"`
+	JobId              int64      `db:"job_id" json:"jobId"`
+	InstanceName       string     `db:"instance_name" json:"instanceName"`
+	InstanceType       string     `db:"instance_type" json:"instanceType"`
+	InstanceIndex      int        `db:"instance_index" json:"instanceIndex"`
+	ReplicaIndex       int        `db:"replica_index" json:"replicaIndex"`
+	PodName            string     `db:"pod_name" json:"podName"`
+	Namespace          string     `db:"namespace" json:"namespace"`
+	NodeName           string     `db:"node_name" json:"nodeName"`
+	NodeIp             string     `db:"node_ip" json:"nodeIp"`
+	PodIp              string     `db:"pod_ip" json:"podIp"`
+	ContainerId        string     `db:"container_id" json:"containerId"`
+	Status             string     `db:"status" json:"status"`
+	Phase              string     `db:"phase" json:"phase"`
+	Reason             string     `db:"reason" json:"reason"`
+	Message            string     `db:"message" json:"message"`
+	Ready              bool       `db:"ready" json:"ready"`
+	ScheduledAt        *time.Time `db:"scheduled_at" json:"scheduledAt"`
+	StartTime          *time.Time `db:"start_time" json:"startTime"`
+	EndTime            *time.Time `db:"end_time" json:"endTime"`
+	LastTransitionTime *time.Time `db:"last_transition_time" json:"lastTransitionTime"`
+	RestartCount       int        `db:"restart_count" json:"restartCount"`
+	ExitCode           *int       `db:"exit_code" json:"exitCode"`
+	TerminationReason  string     `db:"termination_reason" json:"terminationReason"`
+	CreatedAt          time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt          time.Time  `db:"updated_at" json:"updatedAt"`
+}
+
+// VtTrainingJobInstancesModel 训练任务实例模型操作接口
+type VtTrainingJobInstancesModel interface {
+	Upsert(data *VtTrainingJobInstances) error
+	FindOne(id int64) (*VtTrainingJobInstances, error)
+	FindByJobId(jobId int64) ([]*VtTrainingJobInstances, error)
+	UpdateStatus(id int64, status, reason string) error
+	UpdateGpuUsageByPod(namespace, podName string, gpuPercent float64, sampledAt time.Time) error
+	GetGpuUsage(jobId int64, since time.Time) (float64, bool, error)
+}
+
+type vtTrainingJobInstancesModel struct {
+	conn *sql.DB
+}
+
+func NewVtTrainingJobInstancesModel(conn *sql.DB) VtTrainingJobInstancesModel {
+	return &vtTrainingJobInstancesModel{conn: conn}
+}
+
+// Upsert 按(job_id, instance_name)写入或更新实例
+func (m *vtTrainingJobInstancesModel) Upsert(data *VtTrainingJobInstances) error {
+	query := `INSERT INTO vt_training_job_instances (
+		job_id, instance_name, instance_type, instance_index, replica_index, pod_name, namespace,
+		node_name, node_ip, pod_ip, container_id, status, phase, reason, message, ready,
+		scheduled_at, start_time, end_time, last_transition_time, restart_count, exit_code, termination_reason
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON DUPLICATE KEY UPDATE
+		node_name = VALUES(node_name), node_ip = VALUES(node_ip), pod_ip = VALUES(pod_ip),
+		container_id = VALUES(container_id), status = VALUES(status), phase = VALUES(phase),
+		reason = VALUES(reason), message = VALUES(message), ready = VALUES(ready),
+		scheduled_at = VALUES(scheduled_at), start_time = VALUES(start_time), end_time = VALUES(end_time),
+		last_transition_time = VALUES(last_transition_time), restart_count = VALUES(restart_count),
+		exit_code = VALUES(exit_code), termination_reason = VALUES(termination_reason)`
+
+	_, err := m.conn.Exec(query,
+		data.JobId, data.InstanceName, data.InstanceType, data.InstanceIndex, data.ReplicaIndex, data.PodName, data.Namespace,
+		data.NodeName, data.NodeIp, data.PodIp, data.ContainerId, data.Status, data.Phase, data.Reason, data.Message, data.Ready,
+		data.ScheduledAt, data.StartTime, data.EndTime, data.LastTransitionTime, data.RestartCount, data.ExitCode, data.TerminationReason,
+	)
+	return err
+}
+
+const vtTrainingJobInstancesFields = `id, job_id, instance_name, IFNULL(instance_type, 'worker'), instance_index, IFNULL(replica_index, 0),
+	IFNULL(pod_name, ''), IFNULL(namespace, ''), IFNULL(node_name, ''), IFNULL(node_ip, ''), IFNULL(pod_ip, ''),
+	IFNULL(container_id, ''), IFNULL(status, 'unknown'), IFNULL(phase, ''), IFNULL(reason, ''), IFNULL(message, ''),
+	IFNULL(ready, 0), scheduled_at, start_time, end_time, last_transition_time, IFNULL(restart_count, 0), exit_code,
+	IFNULL(termination_reason, ''), created_at, updated_at`
+
+func scanVtTrainingJobInstances(scanner rowScanner) (*VtTrainingJobInstances, error) {
+	var i VtTrainingJobInstances
+	var exitCode sql.NullInt64
+	err := scanner.Scan(&i.Id, &i.JobId, &i.InstanceName, &i.InstanceType, &i.InstanceIndex, &i.ReplicaIndex,
+		&i.PodName, &i.Namespace, &i.NodeName, &i.NodeIp, &i.PodIp,
+		&i.ContainerId, &i.Status, &i.Phase, &i.Reason, &i.Message,
+		&i.Ready, &i.ScheduledAt, &i.StartTime, &i.EndTime, &i.LastTransitionTime, &i.RestartCount, &exitCode,
+		&i.TerminationReason, &i.CreatedAt, &i.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if exitCode.Valid {
+		code := int(exitCode.Int64)
+		i.ExitCode = &code
+	}
+	return &i, nil
+}
+
+func (m *vtTrainingJobInstancesModel) FindOne(id int64) (*VtTrainingJobInstances, error) {
+	query := `SELECT ` + vtTrainingJobInstancesFields + ` FROM vt_training_job_instances WHERE id = ?`
+	return scanVtTrainingJobInstances(m.conn.QueryRow(query, id))
+}
+
+func (m *vtTrainingJobInstancesModel) FindByJobId(jobId int64) ([]*VtTrainingJobInstances, error) {
+	query := `SELECT ` + vtTrainingJobInstancesFields + ` FROM vt_training_job_instances WHERE job_id = ? ORDER BY id ASC`
+	rows, err := m.conn.Query(query, jobId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var instances []*VtTrainingJobInstances
+	for rows.Next() {
+		i, err := scanVtTrainingJobInstances(rows)
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, i)
+	}
+
+	return instances, rows.Err()
+}
+
+// UpdateStatus 将实例标记为终止状态，未记录结束时间时同时写入结束时间
+func (m *vtTrainingJobInstancesModel) UpdateStatus(id int64, status, reason string) error {
+	query := `UPDATE vt_training_job_instances SET status = ?, reason = ?, end_time = IFNULL(end_time, CURRENT_TIMESTAMP), last_transition_time = CURRENT_TIMESTAMP WHERE id = ?`
+	_, err := m.conn.Exec(query, status, reason, id)
+	return err
+}
+
+// UpdateGpuUsageByPod 按Pod写入运行中实例的GPU使用率并记录采集时间，同名Pod的历史实例不受影响
+func (m *vtTrainingJobInstancesModel) UpdateGpuUsageByPod(namespace, podName string, gpuPercent float64, sampledAt time.Time) error {
+	query := `UPDATE vt_training_job_instances SET gpu_usage_percent = ?, usage_updated_at = ? WHERE namespace = ? AND pod_name = ? AND status = 'running'`
+	_, err := m.conn.Exec(query, gpuPercent, sampledAt, namespace, podName)
+	return err
+}
+
+// GetGpuUsage 查询作业运行中实例在since之后采集到的最大GPU使用率，没有采集数据时返回false
+func (m *vtTrainingJobInstancesModel) GetGpuUsage(jobId int64, since time.Time) (float64, bool, error) {
+	query := `SELECT COUNT(*), IFNULL(MAX(gpu_usage_percent), 0) FROM vt_training_job_instances WHERE job_id = ? AND status = 'running' AND usage_updated_at >= ?`
+	var count int64
+	var usage float64
+	if err := m.conn.QueryRow(query, jobId, since).Scan(&count, &usage); err != nil {
+		return 0, false, err
+	}
+	return usage, count > 0, nil
+}
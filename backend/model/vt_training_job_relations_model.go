@@ -0,0 +1,115 @@
+package model
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// VtTrainingJobRelations 训练作业关联关系模型
+type VtTrainingJobRelations struct {
+	Id           int64     `db:"id" json:"id"`
+	JobId        int64     `db:"job_id" json:"jobId"`
+	EntityType   string    `db:"entity_type" json:"entityType"`
+	EntityId     int64     `db:"entity_id" json:"entityId"`
+	RelationType string    `db:"relation_type" json:"relationType"`
+	IsPrimary    bool      `db:"is_primary" json:"isPrimary"`
+	SortOrder    int       `db:"sort_order" json:"sortOrder"`
+	Status       string    `db:"status" json:"status"`
+	Metadata     string    `db:"metadata" json:"metadata"`
+	CreatedAt    time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// VtTrainingJobRelationsModel 训练作业关联关系模型操作接口
+type VtTrainingJobRelationsModel interface {
+	// Insert 新增关联，已软删除的相同关联会被恢复并覆盖属性
+	Insert(data *VtTrainingJobRelations) (sql.Result, error)
+	FindOne(id int64) (*VtTrainingJobRelations, error)
+	// FindByJobId 查询作业的有效关联，entityType和relationType为空时不过滤
+	FindByJobId(jobId int64, entityType, relationType string) ([]*VtTrainingJobRelations, error)
+	// FindByEntity 查询关联到指定实体的有效关联，按sort_order排序
+	FindByEntity(entityType string, entityId int64, relationType string) ([]*VtTrainingJobRelations, error)
+	Delete(id int64) error
+}
+
+type vtTrainingJobRelationsModel struct {
+	conn *sql.DB
+}
+
+func NewVtTrainingJobRelationsModel(conn *sql.DB) VtTrainingJobRelationsModel {
+	return &vtTrainingJobRelationsModel{conn: conn}
+}
+
+const vtTrainingJobRelationsFields = `id, job_id, entity_type, entity_id, relation_type, IFNULL(is_primary, 0), IFNULL(sort_order, 0), IFNULL(status, 'active'), IFNULL(metadata, ''), created_at, updated_at`
+
+func scanVtTrainingJobRelations(scanner rowScanner) (*VtTrainingJobRelations, error) {
+	var r VtTrainingJobRelations
+	err := scanner.Scan(&r.Id, &r.JobId, &r.EntityType, &r.EntityId, &r.RelationType, &r.IsPrimary, &r.SortOrder, &r.Status, &r.Metadata, &r.CreatedAt, &r.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (m *vtTrainingJobRelationsModel) Insert(data *VtTrainingJobRelations) (sql.Result, error) {
+	query := `INSERT INTO vt_training_job_relations (job_id, entity_type, entity_id, relation_type, is_primary, sort_order, status, metadata) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id), is_primary = VALUES(is_primary), sort_order = VALUES(sort_order), status = VALUES(status), metadata = VALUES(metadata)`
+	status := data.Status
+	if status == "" {
+		status = "active"
+	}
+	var metadata interface{}
+	if data.Metadata != "" {
+		metadata = data.Metadata
+	}
+	return m.conn.Exec(query, data.JobId, data.EntityType, data.EntityId, data.RelationType, data.IsPrimary, data.SortOrder, status, metadata)
+}
+
+func (m *vtTrainingJobRelationsModel) FindOne(id int64) (*VtTrainingJobRelations, error) {
+	query := `SELECT ` + vtTrainingJobRelationsFields + ` FROM vt_training_job_relations WHERE id = ? AND status != 'deleted'`
+	return scanVtTrainingJobRelations(m.conn.QueryRow(query, id))
+}
+
+func (m *vtTrainingJobRelationsModel) FindByJobId(jobId int64, entityType, relationType string) ([]*VtTrainingJobRelations, error) {
+	conditions := []string{"job_id = ?", "status != 'deleted'"}
+	args := []interface{}{jobId}
+	if entityType != "" {
+		conditions = append(conditions, "entity_type = ?")
+		args = append(args, entityType)
+	}
+	if relationType != "" {
+		conditions = append(conditions, "relation_type = ?")
+		args = append(args, relationType)
+	}
+	query := `SELECT ` + vtTrainingJobRelationsFields + ` FROM vt_training_job_relations WHERE ` + strings.Join(conditions, " AND ") + ` ORDER BY sort_order ASC, id ASC`
+	return m.query(query, args...)
+}
+
+func (m *vtTrainingJobRelationsModel) FindByEntity(entityType string, entityId int64, relationType string) ([]*VtTrainingJobRelations, error) {
+	query := `SELECT ` + vtTrainingJobRelationsFields + ` FROM vt_training_job_relations WHERE entity_type = ? AND entity_id = ? AND relation_type = ? AND status != 'deleted' ORDER BY sort_order ASC, id ASC`
+	return m.query(query, entityType, entityId, relationType)
+}
+
+func (m *vtTrainingJobRelationsModel) Delete(id int64) error {
+	_, err := m.conn.Exec(`UPDATE vt_training_job_relations SET status = 'deleted' WHERE id = ?`, id)
+	return err
+}
+
+func (m *vtTrainingJobRelationsModel) query(query string, args ...interface{}) ([]*VtTrainingJobRelations, error) {
+	rows, err := m.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var relations []*VtTrainingJobRelations
+	for rows.Next() {
+		r, err := scanVtTrainingJobRelations(rows)
+		if err != nil {
+			return nil, err
+		}
+		relations = append(relations, r)
+	}
+	return relations, rows.Err()
+}
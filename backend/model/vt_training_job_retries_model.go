@@ -0,0 +1,80 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// VtTrainingJobRetries 训练作业自动重试记录模型
+type VtTrainingJobRetries struct {
+	Id                   int64      `db:"id" json:"id"`
+	JobId                int64      `db:"job_id" json:"jobId"`
+	Attempt              int        `db:"attempt" json:"attempt"`
+	FailureReason        string     `db:"failure_reason" json:"failureReason"`
+	ExitCode             int        `db:"exit_code" json:"exitCode"`
+	ErrorMessage         string     `db:"error_message" json:"errorMessage"`
+	FailedVolcanoJobName string     `db:"failed_volcano_job_name" json:"failedVolcanoJobName"`
+	RetryVolcanoJobName  string     `db:"retry_volcano_job_name" json:"retryVolcanoJobName"`
+	CheckpointId         int64      `db:"checkpoint_id" json:"checkpointId"`
+	CheckpointPath       string     `db:"checkpoint_path" json:"checkpointPath"`
+	BackoffSeconds       int        `db:"backoff_seconds" json:"backoffSeconds"`
+	FailedAt             *time.Time `db:"failed_at" json:"failedAt"`
+	CreatedAt            time.Time  `db:"created_at" json:"createdAt"`
+}
+
+// VtTrainingJobRetriesModel 训练作业自动重试记录模型操作接口
+type VtTrainingJobRetriesModel interface {
+	Insert(data *VtTrainingJobRetries) (sql.Result, error)
+	FindByJobId(jobId int64) ([]*VtTrainingJobRetries, error)
+	CountByJobId(jobId int64) (int64, error)
+}
+
+type vtTrainingJobRetriesModel struct {
+	conn *sql.DB
+}
+
+func NewVtTrainingJobRetriesModel(conn *sql.DB) VtTrainingJobRetriesModel {
+	return &vtTrainingJobRetriesModel{conn: conn}
+}
+
+// insertVtTrainingJobRetry 写入重试记录，conn可以是事务，以便与作业状态变更原子提交
+func insertVtTrainingJobRetry(conn execer, data *VtTrainingJobRetries) (sql.Result, error) {
+	query := `INSERT INTO vt_training_job_retries (job_id, attempt, failure_reason, exit_code, error_message, failed_volcano_job_name, retry_volcano_job_name, checkpoint_id, checkpoint_path, backoff_seconds, failed_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	var checkpointId interface{}
+	if data.CheckpointId > 0 {
+		checkpointId = data.CheckpointId
+	}
+	return conn.Exec(query, data.JobId, data.Attempt, data.FailureReason, data.ExitCode, data.ErrorMessage,
+		data.FailedVolcanoJobName, data.RetryVolcanoJobName, checkpointId, data.CheckpointPath, data.BackoffSeconds, data.FailedAt)
+}
+
+func (m *vtTrainingJobRetriesModel) Insert(data *VtTrainingJobRetries) (sql.Result, error) {
+	return insertVtTrainingJobRetry(m.conn, data)
+}
+
+func (m *vtTrainingJobRetriesModel) FindByJobId(jobId int64) ([]*VtTrainingJobRetries, error) {
+	query := `SELECT id, job_id, attempt, failure_reason, IFNULL(exit_code, 0), IFNULL(error_message, ''), IFNULL(failed_volcano_job_name, ''), IFNULL(retry_volcano_job_name, ''), IFNULL(checkpoint_id, 0), IFNULL(checkpoint_path, ''), IFNULL(backoff_seconds, 0), failed_at, created_at FROM vt_training_job_retries WHERE job_id = ? ORDER BY attempt ASC`
+	rows, err := m.conn.Query(query, jobId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var retries []*VtTrainingJobRetries
+	for rows.Next() {
+		var r VtTrainingJobRetries
+		err := rows.Scan(&r.Id, &r.JobId, &r.Attempt, &r.FailureReason, &r.ExitCode, &r.ErrorMessage, &r.FailedVolcanoJobName, &r.RetryVolcanoJobName, &r.CheckpointId, &r.CheckpointPath, &r.BackoffSeconds, &r.FailedAt, &r.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		retries = append(retries, &r)
+	}
+
+	return retries, rows.Err()
+}
+
+func (m *vtTrainingJobRetriesModel) CountByJobId(jobId int64) (int64, error) {
+	var count int64
+	err := m.conn.QueryRow(`SELECT COUNT(*) FROM vt_training_job_retries WHERE job_id = ?`, jobId).Scan(&count)
+	return count, err
+}
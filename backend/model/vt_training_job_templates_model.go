@@ -0,0 +1,144 @@
+package model
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 模板可见性
+const (
+	TemplateVisibilityPrivate   = "private"
+	TemplateVisibilityWorkspace = "workspace"
+)
+
+// VtTrainingJobTemplates 训练作业模板模型
+type VtTrainingJobTemplates struct {
+	Id          int64      `db:"id" json:"id"`
+	Name        string     `db:"name" json:"name"`
+	DisplayName string     `db:"display_name" json:"displayName"`
+	Description string     `db:"description" json:"description"`
+	OwnerId     int64      `db:"owner_id" json:"ownerId"`
+	OwnerName   string     `db:"owner_name" json:"ownerName"`
+	WorkspaceId int64      `db:"workspace_id" json:"workspaceId"`
+	Visibility  string     `db:"visibility" json:"visibility"`
+	Spec        string     `db:"spec" json:"spec"`
+	Parameters  string     `db:"parameters" json:"parameters"`
+	UseCount    int        `db:"use_count" json:"useCount"`
+	LastUsedAt  *time.Time `db:"last_used_at" json:"lastUsedAt"`
+	CreatedAt   time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt   time.Time  `db:"updated_at" json:"updatedAt"`
+	DeletedAt   *time.Time `db:"deleted_at" json:"deletedAt"`
+}
+
+// VtTrainingJobTemplatesModel 训练作业模板模型操作接口
+type VtTrainingJobTemplatesModel interface {
+	Insert(data *VtTrainingJobTemplates) (sql.Result, error)
+	FindOne(id int64) (*VtTrainingJobTemplates, error)
+	FindOneByScope(ownerId, workspaceId int64, name string) (*VtTrainingJobTemplates, error)
+	Update(data *VtTrainingJobTemplates) error
+	Delete(id int64) error
+	// List 返回用户可见的模板：本人创建的模板，以及用户所在工作空间中共享的模板
+	List(userId, workspaceId int64, keyword string, page, pageSize int) ([]*VtTrainingJobTemplates, int64, error)
+	IncrUseCount(id int64) error
+}
+
+type vtTrainingJobTemplatesModel struct {
+	conn *sql.DB
+}
+
+func NewVtTrainingJobTemplatesModel(conn *sql.DB) VtTrainingJobTemplatesModel {
+	return &vtTrainingJobTemplatesModel{conn: conn}
+}
+
+const vtTrainingJobTemplatesFields = `id, name, IFNULL(display_name, ''), IFNULL(description, ''), owner_id, IFNULL(owner_name, ''), workspace_id, IFNULL(visibility, 'private'), spec, IFNULL(parameters, '[]'), IFNULL(use_count, 0), last_used_at, created_at, updated_at, deleted_at`
+
+func scanVtTrainingJobTemplates(row rowScanner) (*VtTrainingJobTemplates, error) {
+	var t VtTrainingJobTemplates
+	err := row.Scan(&t.Id, &t.Name, &t.DisplayName, &t.Description, &t.OwnerId, &t.OwnerName, &t.WorkspaceId,
+		&t.Visibility, &t.Spec, &t.Parameters, &t.UseCount, &t.LastUsedAt, &t.CreatedAt, &t.UpdatedAt, &t.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (m *vtTrainingJobTemplatesModel) Insert(data *VtTrainingJobTemplates) (sql.Result, error) {
+	query := `INSERT INTO vt_training_job_templates (name, display_name, description, owner_id, owner_name, workspace_id, visibility, spec, parameters) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	return m.conn.Exec(query, data.Name, data.DisplayName, data.Description, data.OwnerId, data.OwnerName,
+		data.WorkspaceId, data.Visibility, data.Spec, data.Parameters)
+}
+
+func (m *vtTrainingJobTemplatesModel) FindOne(id int64) (*VtTrainingJobTemplates, error) {
+	query := `SELECT ` + vtTrainingJobTemplatesFields + ` FROM vt_training_job_templates WHERE id = ? AND deleted_at IS NULL`
+	return scanVtTrainingJobTemplates(m.conn.QueryRow(query, id))
+}
+
+func (m *vtTrainingJobTemplatesModel) FindOneByScope(ownerId, workspaceId int64, name string) (*VtTrainingJobTemplates, error) {
+	query := `SELECT ` + vtTrainingJobTemplatesFields + ` FROM vt_training_job_templates WHERE owner_id = ? AND workspace_id = ? AND name = ? AND deleted_at IS NULL`
+	return scanVtTrainingJobTemplates(m.conn.QueryRow(query, ownerId, workspaceId, name))
+}
+
+func (m *vtTrainingJobTemplatesModel) Update(data *VtTrainingJobTemplates) error {
+	query := `UPDATE vt_training_job_templates SET display_name = ?, description = ?, visibility = ?, spec = ?, parameters = ? WHERE id = ? AND deleted_at IS NULL`
+	_, err := m.conn.Exec(query, data.DisplayName, data.Description, data.Visibility, data.Spec, data.Parameters, data.Id)
+	return err
+}
+
+// Delete 软删除模板，名称追加删除标记以释放唯一索引
+func (m *vtTrainingJobTemplatesModel) Delete(id int64) error {
+	query := `UPDATE vt_training_job_templates SET deleted_at = NOW(), name = CONCAT(name, '#deleted-', id) WHERE id = ? AND deleted_at IS NULL`
+	_, err := m.conn.Exec(query, id)
+	return err
+}
+
+func (m *vtTrainingJobTemplatesModel) List(userId, workspaceId int64, keyword string, page, pageSize int) ([]*VtTrainingJobTemplates, int64, error) {
+	conditions := []string{"deleted_at IS NULL",
+		`(owner_id = ? OR (visibility = 'workspace' AND workspace_id IN (SELECT workspace_id FROM vt_workspace_members WHERE user_id = ? AND status = 'active')))`}
+	args := []interface{}{userId, userId}
+	if workspaceId > 0 {
+		conditions = append(conditions, "workspace_id = ?")
+		args = append(args, workspaceId)
+	}
+	if keyword != "" {
+		conditions = append(conditions, "(name LIKE ? OR display_name LIKE ?)")
+		args = append(args, "%"+keyword+"%", "%"+keyword+"%")
+	}
+	whereClause := "WHERE " + strings.Join(conditions, " AND ")
+
+	var total int64
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM vt_training_job_templates %s", whereClause)
+	if err := m.conn.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	query := fmt.Sprintf("SELECT %s FROM vt_training_job_templates %s ORDER BY updated_at DESC LIMIT ? OFFSET ?",
+		vtTrainingJobTemplatesFields, whereClause)
+	rows, err := m.conn.Query(query, append(args, pageSize, (page-1)*pageSize)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var templates []*VtTrainingJobTemplates
+	for rows.Next() {
+		t, err := scanVtTrainingJobTemplates(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, total, rows.Err()
+}
+
+func (m *vtTrainingJobTemplatesModel) IncrUseCount(id int64) error {
+	_, err := m.conn.Exec(`UPDATE vt_training_job_templates SET use_count = use_count + 1, last_used_at = NOW() WHERE id = ?`, id)
+	return err
+}
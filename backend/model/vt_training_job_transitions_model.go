@@ -0,0 +1,96 @@
+package model
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// VtTrainingJobTransitions 训练作业状态变更记录模型
+type VtTrainingJobTransitions struct {
+	Id                 int64     `db:"id" json:"id"`
+	JobId              int64     `db:"job_id" json:"jobId"`
+	Action             string    `db:"action" json:"action"`
+	FromStatus         string    `db:"from_status" json:"fromStatus"`
+	ToStatus           string    `db:"to_status" json:"toStatus"`
+	OperatorId         int64     `db:"operator_id" json:"operatorId"`
+	OperatorName       string    `db:"operator_name" json:"operatorName"`
+	Reason             string    `db:"reason" json:"reason"`
+	VolcanoJobName     string    `db:"volcano_job_name" json:"volcanoJobName"`
+	ResumeCheckpointId int64     `db:"resume_checkpoint_id" json:"resumeCheckpointId"` // 新一次运行恢复训练使用的检查点，0表示从头开始
+	CreatedAt          time.Time `db:"created_at" json:"createdAt"`
+}
+
+// VtTrainingJobTransitionsModel 训练作业状态变更记录模型操作接口
+type VtTrainingJobTransitionsModel interface {
+	Insert(data *VtTrainingJobTransitions) (sql.Result, error)
+	FindByJobId(jobId int64) ([]*VtTrainingJobTransitions, error)
+	CountByActions(jobId int64, actions ...string) (int64, error)
+}
+
+type vtTrainingJobTransitionsModel struct {
+	conn *sql.DB
+}
+
+func NewVtTrainingJobTransitionsModel(conn *sql.DB) VtTrainingJobTransitionsModel {
+	return &vtTrainingJobTransitionsModel{conn: conn}
+}
+
+// execer 兼容sql.DB与sql.Tx的执行接口
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func insertVtTrainingJobTransition(conn execer, data *VtTrainingJobTransitions) (sql.Result, error) {
+	query := `INSERT INTO vt_training_job_transitions (job_id, action, from_status, to_status, operator_id, operator_name, reason, volcano_job_name, resume_checkpoint_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	var operatorId, resumeCheckpointId interface{}
+	if data.OperatorId > 0 {
+		operatorId = data.OperatorId
+	}
+	if data.ResumeCheckpointId > 0 {
+		resumeCheckpointId = data.ResumeCheckpointId
+	}
+	return conn.Exec(query, data.JobId, data.Action, data.FromStatus, data.ToStatus, operatorId, data.OperatorName, data.Reason, data.VolcanoJobName, resumeCheckpointId)
+}
+
+func (m *vtTrainingJobTransitionsModel) Insert(data *VtTrainingJobTransitions) (sql.Result, error) {
+	return insertVtTrainingJobTransition(m.conn, data)
+}
+
+func (m *vtTrainingJobTransitionsModel) FindByJobId(jobId int64) ([]*VtTrainingJobTransitions, error) {
+	query := `SELECT id, job_id, action, from_status, to_status, IFNULL(operator_id, 0), IFNULL(operator_name, ''), IFNULL(reason, ''), IFNULL(volcano_job_name, ''), IFNULL(resume_checkpoint_id, 0), created_at FROM vt_training_job_transitions WHERE job_id = ? ORDER BY id ASC`
+	rows, err := m.conn.Query(query, jobId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transitions []*VtTrainingJobTransitions
+	for rows.Next() {
+		var t VtTrainingJobTransitions
+		err := rows.Scan(&t.Id, &t.JobId, &t.Action, &t.FromStatus, &t.ToStatus, &t.OperatorId, &t.OperatorName, &t.Reason, &t.VolcanoJobName, &t.ResumeCheckpointId, &t.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		transitions = append(transitions, &t)
+	}
+
+	return transitions, rows.Err()
+}
+
+func (m *vtTrainingJobTransitionsModel) CountByActions(jobId int64, actions ...string) (int64, error) {
+	if len(actions) == 0 {
+		return 0, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(actions)), ", ")
+	query := `SELECT COUNT(*) FROM vt_training_job_transitions WHERE job_id = ? AND action IN (` + placeholders + `)`
+	args := []interface{}{jobId}
+	for _, action := range actions {
+		args = append(args, action)
+	}
+
+	var count int64
+	err := m.conn.QueryRow(query, args...).Scan(&count)
+	return count, err
+}
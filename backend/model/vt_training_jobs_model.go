@@ -2,6 +2,8 @@ package model
 
 import (
 	"database/sql"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -32,6 +34,8 @@ type VtTrainingJobs struct {
 	GpuCount                  int        `db:"gpu_count" json:"gpuCount"`
 	GpuType                   string     `db:"gpu_type" json:"gpuType"`
 	GpuMemoryGb               string     `db:"gpu_memory_gb" json:"gpuMemoryGb"`
+	GpuSharingMode            string     `db:"gpu_sharing_mode" json:"gpuSharingMode"` // 空为独占整卡，mig、time_slicing、vgpu为共享GPU
+	GpuMigProfile             string     `db:"gpu_mig_profile" json:"gpuMigProfile"`
 	StorageGb                 string     `db:"storage_gb" json:"storageGb"`
 	SharedMemoryGb            string     `db:"shared_memory_gb" json:"sharedMemoryGb"`
 	WorkerCount               int        `db:"worker_count" json:"workerCount"`
@@ -77,6 +81,8 @@ type VtTrainingJobs struct {
 	LogsPath                  string     `db:"logs_path" json:"logsPath"`
 	OutputPath                string     `db:"output_path" json:"outputPath"`
 	CheckpointPath            string     `db:"checkpoint_path" json:"checkpointPath"`
+	ResumeCheckpointId        int64      `db:"resume_checkpoint_id" json:"resumeCheckpointId"`
+	ResumeCheckpointPath      string     `db:"resume_checkpoint_path" json:"resumeCheckpointPath"`
 	TensorboardPath           string     `db:"tensorboard_path" json:"tensorboardPath"`
 	Hyperparameters           string     `db:"hyperparameters" json:"hyperparameters"`
 	TrainingConfig            string     `db:"training_config" json:"trainingConfig"`
@@ -104,6 +110,32 @@ type VtTrainingJobsModel interface {
 	Delete(id int64) error
 	List(page, pageSize int, filters map[string]interface{}) ([]*VtTrainingJobs, int64, error)
 	GetByStatus(status string) ([]*VtTrainingJobs, error)
+	FindOneDetail(id int64) (*VtTrainingJobs, error)
+	FindDispatchable(limit int) ([]*VtTrainingJobs, error)
+	FindSubmitted() ([]*VtTrainingJobs, error)
+	FindRunning() ([]*VtTrainingJobs, error)
+	FindFinishedSince(since time.Time) ([]*VtTrainingJobs, error)
+	FindRetryCandidates(failureReasons []string, backoffBase, backoffMax time.Duration, now time.Time, limit int) ([]*VtTrainingJobs, error)
+	MarkScheduled(id int64) (bool, error)
+	TransitionStatus(id int64, fromStatus, toStatus string, fields map[string]interface{}, record *VtTrainingJobTransitions, retry *VtTrainingJobRetries) (bool, error)
+}
+
+// vtTrainingJobsDetailFields 完整字段列表，可空列统一转换为零值便于扫描
+const vtTrainingJobsDetailFields = `id, name, IFNULL(display_name, ''), IFNULL(description, ''), IFNULL(job_type, 'single'), framework, IFNULL(framework_version, ''), IFNULL(python_version, ''), IFNULL(code_source_type, ''), IFNULL(code_source_config, ''), entry_point, IFNULL(working_dir, ''), image, IFNULL(image_pull_policy, ''), IFNULL(image_pull_secrets, ''), IFNULL(dataset_mount_configs, ''), IFNULL(data_source_config, ''), IFNULL(model_config, ''), IFNULL(output_model_name, ''), IFNULL(model_save_strategy, ''), IFNULL(cpu_cores, ''), IFNULL(memory_gb, ''), IFNULL(gpu_count, 0), IFNULL(gpu_type, ''), IFNULL(gpu_memory_gb, ''), IFNULL(gpu_sharing_mode, ''), IFNULL(gpu_mig_profile, ''), IFNULL(storage_gb, ''), IFNULL(shared_memory_gb, ''), IFNULL(worker_count, 0), IFNULL(ps_count, 0), IFNULL(master_count, 0), IFNULL(env_vars, ''), IFNULL(command_args, ''), IFNULL(secrets, ''), IFNULL(config_maps, ''), IFNULL(volume_mounts, ''), IFNULL(queue_name, ''), IFNULL(priority, 0), IFNULL(node_selector, ''), IFNULL(tolerations, ''), IFNULL(affinity, ''), IFNULL(max_runtime_seconds, 0), IFNULL(max_idle_seconds, 0), IFNULL(auto_restart, 0), IFNULL(max_retry_count, 0), IFNULL(volcano_job_name, ''), IFNULL(volcano_queue, ''), IFNULL(min_available, 0), IFNULL(status, ''), IFNULL(phase, ''), IFNULL(namespace, ''), IFNULL(cluster_name, ''), IFNULL(error_message, ''), IFNULL(error_code, ''), IFNULL(exit_code, 0), IFNULL(failure_reason, ''), submitted_at, queued_at, scheduled_at, start_time, end_time, IFNULL(duration_seconds, 0), IFNULL(actual_cpu_usage, ''), IFNULL(actual_memory_usage_gb, ''), IFNULL(actual_gpu_usage, ''), IFNULL(peak_memory_usage_gb, ''), IFNULL(total_gpu_hours, ''), IFNULL(workspace_path, ''), IFNULL(logs_path, ''), IFNULL(output_path, ''), IFNULL(checkpoint_path, ''), IFNULL(resume_checkpoint_id, 0), IFNULL(resume_checkpoint_path, ''), IFNULL(tensorboard_path, ''), IFNULL(hyperparameters, ''), IFNULL(training_config, ''), IFNULL(optimizer_config, ''), IFNULL(scheduler_config, ''), IFNULL(enable_tensorboard, 0), IFNULL(enable_profiling, 0), IFNULL(metrics_collection_interval, 0), IFNULL(notification_config, ''), IFNULL(tags, ''), IFNULL(annotations, ''), IFNULL(metadata, ''), created_at, updated_at, deleted_at`
+
+// rowScanner 兼容sql.Row与sql.Rows的扫描接口
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanVtTrainingJobsDetail 按完整字段列表扫描一行
+func scanVtTrainingJobsDetail(scanner rowScanner) (*VtTrainingJobs, error) {
+	var job VtTrainingJobs
+	err := scanner.Scan(&job.Id, &job.Name, &job.DisplayName, &job.Description, &job.JobType, &job.Framework, &job.FrameworkVersion, &job.PythonVersion, &job.CodeSourceType, &job.CodeSourceConfig, &job.EntryPoint, &job.WorkingDir, &job.Image, &job.ImagePullPolicy, &job.ImagePullSecrets, &job.DatasetMountConfigs, &job.DataSourceConfig, &job.ModelConfig, &job.OutputModelName, &job.ModelSaveStrategy, &job.CpuCores, &job.MemoryGb, &job.GpuCount, &job.GpuType, &job.GpuMemoryGb, &job.GpuSharingMode, &job.GpuMigProfile, &job.StorageGb, &job.SharedMemoryGb, &job.WorkerCount, &job.PsCount, &job.MasterCount, &job.EnvVars, &job.CommandArgs, &job.Secrets, &job.ConfigMaps, &job.VolumeMounts, &job.QueueName, &job.Priority, &job.NodeSelector, &job.Tolerations, &job.Affinity, &job.MaxRuntimeSeconds, &job.MaxIdleSeconds, &job.AutoRestart, &job.MaxRetryCount, &job.VolcanoJobName, &job.VolcanoQueue, &job.MinAvailable, &job.Status, &job.Phase, &job.Namespace, &job.ClusterName, &job.ErrorMessage, &job.ErrorCode, &job.ExitCode, &job.FailureReason, &job.SubmittedAt, &job.QueuedAt, &job.ScheduledAt, &job.StartTime, &job.EndTime, &job.DurationSeconds, &job.ActualCpuUsage, &job.ActualMemoryUsageGb, &job.ActualGpuUsage, &job.PeakMemoryUsageGb, &job.TotalGpuHours, &job.WorkspacePath, &job.LogsPath, &job.OutputPath, &job.CheckpointPath, &job.ResumeCheckpointId, &job.ResumeCheckpointPath, &job.TensorboardPath, &job.Hyperparameters, &job.TrainingConfig, &job.OptimizerConfig, &job.SchedulerConfig, &job.EnableTensorboard, &job.EnableProfiling, &job.MetricsCollectionInterval, &job.NotificationConfig, &job.Tags, &job.Annotations, &job.Metadata, &job.CreatedAt, &job.UpdatedAt, &job.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
 }
 
 type vtTrainingJobsModel struct {
@@ -235,3 +267,141 @@ func (m *vtTrainingJobsModel) GetByStatus(status string) ([]*VtTrainingJobs, err
 
 	return jobs, nil
 }
+
+// FindOneDetail 查询作业的完整信息
+func (m *vtTrainingJobsModel) FindOneDetail(id int64) (*VtTrainingJobs, error) {
+	query := `SELECT ` + vtTrainingJobsDetailFields + ` FROM vt_training_jobs WHERE id = ? AND deleted_at IS NULL`
+	return scanVtTrainingJobsDetail(m.conn.QueryRow(query, id))
+}
+
+// FindDispatchable 查询待派发的作业：上游依赖均已成功的pending作业，或已入队但尚未成功提交到Volcano的作业
+func (m *vtTrainingJobsModel) FindDispatchable(limit int) ([]*VtTrainingJobs, error) {
+	query := `SELECT ` + vtTrainingJobsDetailFields + ` FROM vt_training_jobs j WHERE deleted_at IS NULL AND ((status = 'pending' AND NOT EXISTS (
+		SELECT 1 FROM vt_training_job_relations r JOIN vt_training_jobs p ON p.id = r.entity_id
+		WHERE r.job_id = j.id AND r.entity_type = 'training_job' AND r.relation_type = 'depends_on' AND r.status = 'active' AND p.deleted_at IS NULL AND p.status != 'succeeded'
+	)) OR (status = 'queued' AND scheduled_at IS NULL)) ORDER BY priority DESC, submitted_at ASC LIMIT ?`
+	return m.queryDetails(query, limit)
+}
+
+// FindSubmitted 查询已提交到Volcano且尚未结束的作业
+func (m *vtTrainingJobsModel) FindSubmitted() ([]*VtTrainingJobs, error) {
+	query := `SELECT ` + vtTrainingJobsDetailFields + ` FROM vt_training_jobs WHERE deleted_at IS NULL AND scheduled_at IS NOT NULL AND status IN ('queued', 'scheduling', 'running', 'suspended') ORDER BY id ASC`
+	return m.queryDetails(query)
+}
+
+// FindRunning 查询运行中的作业，用于运行时长和空闲检查
+func (m *vtTrainingJobsModel) FindRunning() ([]*VtTrainingJobs, error) {
+	query := `SELECT ` + vtTrainingJobsDetailFields + ` FROM vt_training_jobs WHERE deleted_at IS NULL AND status = 'running' ORDER BY id ASC`
+	return m.queryDetails(query)
+}
+
+// FindFinishedSince 查询since之后结束且曾提交到集群的作业，用于归档作业结束前最后的日志
+func (m *vtTrainingJobsModel) FindFinishedSince(since time.Time) ([]*VtTrainingJobs, error) {
+	query := `SELECT ` + vtTrainingJobsDetailFields + ` FROM vt_training_jobs WHERE deleted_at IS NULL AND end_time >= ? AND IFNULL(volcano_job_name, '') != ''
+		AND status IN ('succeeded', 'failed', 'cancelled', 'timeout', 'oom_killed') ORDER BY id ASC`
+	return m.queryDetails(query, since)
+}
+
+// FindRetryCandidates 查询开启自动重启、因指定原因失败、重试次数未用尽且退避时间已到的作业
+// 第n次重试前的退避时长为backoffBase*2^(n-1)，不超过backoffMax，在SQL中过滤以免退避中的作业占满limit
+func (m *vtTrainingJobsModel) FindRetryCandidates(failureReasons []string, backoffBase, backoffMax time.Duration, now time.Time, limit int) ([]*VtTrainingJobs, error) {
+	if len(failureReasons) == 0 {
+		return nil, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(failureReasons)), ", ")
+	query := `SELECT ` + vtTrainingJobsDetailFields + ` FROM (
+		SELECT j.*, (SELECT COUNT(*) FROM vt_training_job_retries r WHERE r.job_id = j.id) AS retry_attempts FROM vt_training_jobs j
+		WHERE deleted_at IS NULL AND auto_restart = 1 AND status IN ('failed', 'oom_killed') AND failure_reason IN (` + placeholders + `)
+	) c WHERE retry_attempts < max_retry_count
+		AND end_time <= DATE_SUB(?, INTERVAL LEAST(? * POW(2, LEAST(retry_attempts, 30)), ?) MICROSECOND) ORDER BY end_time ASC LIMIT ?`
+	args := make([]interface{}, 0, len(failureReasons)+4)
+	for _, reason := range failureReasons {
+		args = append(args, reason)
+	}
+	args = append(args, now, backoffBase.Microseconds(), backoffMax.Microseconds(), limit)
+	return m.queryDetails(query, args...)
+}
+
+func (m *vtTrainingJobsModel) queryDetails(query string, args ...interface{}) ([]*VtTrainingJobs, error) {
+	rows, err := m.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []*VtTrainingJobs
+	for rows.Next() {
+		job, err := scanVtTrainingJobsDetail(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// MarkScheduled 在作业仍为queued时记录已成功提交到Volcano，返回是否记录成功
+func (m *vtTrainingJobsModel) MarkScheduled(id int64) (bool, error) {
+	query := `UPDATE vt_training_jobs SET scheduled_at = CURRENT_TIMESTAMP, error_code = NULL, error_message = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND status = 'queued' AND scheduled_at IS NULL AND deleted_at IS NULL`
+	result, err := m.conn.Exec(query, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// TransitionStatus 在状态仍为fromStatus时变更作业状态并写入变更记录，返回是否变更成功
+// fields为同时更新的附加字段，值为nil时写入NULL；retry不为nil时在同一事务中写入自动重试记录
+func (m *vtTrainingJobsModel) TransitionStatus(id int64, fromStatus, toStatus string, fields map[string]interface{}, record *VtTrainingJobTransitions, retry *VtTrainingJobRetries) (bool, error) {
+	columns := make([]string, 0, len(fields))
+	for column := range fields {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	setClause := "status = ?"
+	args := []interface{}{toStatus}
+	for _, column := range columns {
+		setClause += ", " + column + " = ?"
+		args = append(args, fields[column])
+	}
+	args = append(args, id, fromStatus)
+
+	tx, err := m.conn.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE vt_training_jobs SET ` + setClause + `, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND status = ? AND deleted_at IS NULL`
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected != 1 {
+		return false, nil
+	}
+
+	if record != nil {
+		if _, err := insertVtTrainingJobTransition(tx, record); err != nil {
+			return false, err
+		}
+	}
+	if retry != nil {
+		if _, err := insertVtTrainingJobRetry(tx, retry); err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit()
+}
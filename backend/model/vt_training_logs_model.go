@@ -0,0 +1,203 @@
+package model
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// VtTrainingLogs 训练日志表模型
+type VtTrainingLogs struct {
+	Id            int64     `db:"id" json:"id"`
+	JobId         int64     `db:"job_id" json:"jobId"`
+	InstanceId    int64     `db:"instance_id" json:"instanceId"`
+	LogLevel      string    `db:"log_level" json:"logLevel"`
+	LogSource     string    `db:"log_source" json:"logSource"`
+	LogContent    string    `db:"log_content" json:"logContent"`
+	LogFormat     string    `db:"log_format" json:"logFormat"`
+	LogTime       time.Time `db:"log_time" json:"logTime"`
+	CreatedAt     time.Time `db:"created_at" json:"createdAt"`
+	FileName      string    `db:"file_name" json:"fileName"`
+	LineNumber    int       `db:"line_number" json:"lineNumber"`
+	FunctionName  string    `db:"function_name" json:"functionName"`
+	ThreadId      string    `db:"thread_id" json:"threadId"`
+	ProcessId     string    `db:"process_id" json:"processId"`
+	Context       string    `db:"context" json:"context"`
+	CorrelationId string    `db:"correlation_id" json:"correlationId"`
+	Category      string    `db:"category" json:"category"`
+	Tags          string    `db:"tags" json:"tags"`
+}
+
+// TrainingLogFilter 训练日志查询条件，零值字段不过滤
+type TrainingLogFilter struct {
+	JobId      int64
+	InstanceId int64
+	Levels     []string
+	Source     string
+	Category   string
+	StartTime  *time.Time
+	EndTime    *time.Time
+	Keyword    string
+	Regex      string // MySQL正则表达式，调用方负责校验语法
+}
+
+// VtTrainingLogsModel 训练日志模型操作接口
+type VtTrainingLogsModel interface {
+	Insert(data *VtTrainingLogs) (sql.Result, error)
+	// BatchInsert 在一个事务内批量写入日志，用于日志归档建立索引
+	BatchInsert(logs []*VtTrainingLogs) error
+	// List 按日志时间正序分页查询
+	List(filter TrainingLogFilter, page, pageSize int) ([]*VtTrainingLogs, int64, error)
+}
+
+type vtTrainingLogsModel struct {
+	conn *sql.DB
+}
+
+func NewVtTrainingLogsModel(conn *sql.DB) VtTrainingLogsModel {
+	return &vtTrainingLogsModel{conn: conn}
+}
+
+const vtTrainingLogsFields = `id, job_id, IFNULL(instance_id, 0), IFNULL(log_level, 'INFO'), IFNULL(log_source, ''), log_content,
+	IFNULL(log_format, 'text'), log_time, created_at, IFNULL(file_name, ''), IFNULL(line_number, 0), IFNULL(function_name, ''),
+	IFNULL(thread_id, ''), IFNULL(process_id, ''), IFNULL(context, ''), IFNULL(correlation_id, ''), IFNULL(category, ''), IFNULL(tags, '')`
+
+func scanVtTrainingLogs(scanner rowScanner) (*VtTrainingLogs, error) {
+	var l VtTrainingLogs
+	err := scanner.Scan(&l.Id, &l.JobId, &l.InstanceId, &l.LogLevel, &l.LogSource, &l.LogContent,
+		&l.LogFormat, &l.LogTime, &l.CreatedAt, &l.FileName, &l.LineNumber, &l.FunctionName,
+		&l.ThreadId, &l.ProcessId, &l.Context, &l.CorrelationId, &l.Category, &l.Tags)
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+const (
+	vtTrainingLogsInsertColumns = `INSERT INTO vt_training_logs (job_id, instance_id, log_level, log_source, log_content, log_format, log_time,
+		file_name, line_number, function_name, thread_id, process_id, context, correlation_id, category, tags) VALUES `
+	vtTrainingLogsInsertValues = `(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	// logBatchSize 批量写入时每条INSERT语句的行数
+	logBatchSize = 500
+)
+
+// insertArgs 按插入列顺序返回参数
+func (data *VtTrainingLogs) insertArgs() []interface{} {
+	var instanceId interface{}
+	if data.InstanceId > 0 {
+		instanceId = data.InstanceId
+	}
+	logTime := data.LogTime
+	if logTime.IsZero() {
+		logTime = time.Now()
+	}
+	return []interface{}{data.JobId, instanceId, data.LogLevel, data.LogSource, data.LogContent, data.LogFormat, logTime,
+		data.FileName, data.LineNumber, data.FunctionName, data.ThreadId, data.ProcessId, nullableJSON(data.Context),
+		data.CorrelationId, data.Category, nullableJSON(data.Tags)}
+}
+
+func (m *vtTrainingLogsModel) Insert(data *VtTrainingLogs) (sql.Result, error) {
+	return m.conn.Exec(vtTrainingLogsInsertColumns+vtTrainingLogsInsertValues, data.insertArgs()...)
+}
+
+func (m *vtTrainingLogsModel) BatchInsert(logs []*VtTrainingLogs) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	tx, err := m.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for start := 0; start < len(logs); start += logBatchSize {
+		batch := logs[start:min(start+logBatchSize, len(logs))]
+		query := vtTrainingLogsInsertColumns + vtTrainingLogsInsertValues + strings.Repeat(`, `+vtTrainingLogsInsertValues, len(batch)-1)
+		args := make([]interface{}, 0, len(batch)*16)
+		for _, l := range batch {
+			args = append(args, l.insertArgs()...)
+		}
+		if _, err := tx.Exec(query, args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (m *vtTrainingLogsModel) List(filter TrainingLogFilter, page, pageSize int) ([]*VtTrainingLogs, int64, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.JobId > 0 {
+		conditions = append(conditions, `job_id = ?`)
+		args = append(args, filter.JobId)
+	}
+	if filter.InstanceId > 0 {
+		conditions = append(conditions, `instance_id = ?`)
+		args = append(args, filter.InstanceId)
+	}
+	if len(filter.Levels) > 0 {
+		conditions = append(conditions, `log_level IN (?`+strings.Repeat(`, ?`, len(filter.Levels)-1)+`)`)
+		for _, level := range filter.Levels {
+			args = append(args, level)
+		}
+	}
+	if filter.Source != "" {
+		conditions = append(conditions, `log_source = ?`)
+		args = append(args, filter.Source)
+	}
+	if filter.Category != "" {
+		conditions = append(conditions, `category = ?`)
+		args = append(args, filter.Category)
+	}
+	if filter.StartTime != nil {
+		conditions = append(conditions, `log_time >= ?`)
+		args = append(args, *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		conditions = append(conditions, `log_time <= ?`)
+		args = append(args, *filter.EndTime)
+	}
+	if filter.Keyword != "" {
+		conditions = append(conditions, `log_content LIKE ?`)
+		args = append(args, "%"+filter.Keyword+"%")
+	}
+	if filter.Regex != "" {
+		conditions = append(conditions, `log_content REGEXP ?`)
+		args = append(args, filter.Regex)
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = `WHERE ` + strings.Join(conditions, ` AND `)
+	}
+
+	var total int64
+	if err := m.conn.QueryRow(`SELECT COUNT(*) FROM vt_training_logs `+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 100
+	}
+	query := `SELECT ` + vtTrainingLogsFields + ` FROM vt_training_logs ` + whereClause + ` ORDER BY log_time ASC, id ASC LIMIT ? OFFSET ?`
+	rows, err := m.conn.Query(query, append(args, pageSize, (page-1)*pageSize)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var logs []*VtTrainingLogs
+	for rows.Next() {
+		l, err := scanVtTrainingLogs(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		logs = append(logs, l)
+	}
+	return logs, total, rows.Err()
+}
@@ -0,0 +1,367 @@
+package model
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// 指标类型，与vt_training_metrics.metric_type一致
+const (
+	MetricTypeScalar    = "scalar"
+	MetricTypeHistogram = "histogram"
+	MetricTypeText      = "text"
+)
+
+// VtTrainingMetrics 训练指标表模型，可为空的DECIMAL列以字符串表示，空字符串对应NULL
+type VtTrainingMetrics struct {
+	Id                  int64     `db:"id" json:"id"`
+	JobId               int64     `db:"job_id" json:"jobId"`
+	InstanceId          int64     `db:"instance_id" json:"instanceId"`
+	MetricName          string    `db:"metric_name" json:"metricName"`
+	MetricType          string    `db:"metric_type" json:"metricType"`
+	MetricValue         string    `db:"metric_value" json:"metricValue"`
+	MetricData          string    `db:"metric_data" json:"metricData"`
+	Step                int64     `db:"step" json:"step"`
+	Epoch               int       `db:"epoch" json:"epoch"`
+	GlobalStep          int64     `db:"global_step" json:"globalStep"`
+	BatchIdx            int       `db:"batch_idx" json:"batchIdx"`
+	Tag                 string    `db:"tag" json:"tag"`
+	Category            string    `db:"category" json:"category"`
+	Phase               string    `db:"phase" json:"phase"`
+	MetricTime          time.Time `db:"metric_time" json:"metricTime"`
+	WallTime            string    `db:"wall_time" json:"wallTime"`
+	RelativeTimeSeconds string    `db:"relative_time_seconds" json:"relativeTimeSeconds"`
+	MinValue            string    `db:"min_value" json:"minValue"`
+	MaxValue            string    `db:"max_value" json:"maxValue"`
+	AvgValue            string    `db:"avg_value" json:"avgValue"`
+	StdValue            string    `db:"std_value" json:"stdValue"`
+	CreatedAt           time.Time `db:"created_at" json:"createdAt"`
+}
+
+// MetricPoint 标量指标的一个数据点
+type MetricPoint struct {
+	Step  int64   `json:"step"`
+	Value float64 `json:"value"`
+}
+
+// TrainingMetricFilter 训练指标查询条件，零值字段不过滤
+type TrainingMetricFilter struct {
+	JobId      int64
+	MetricName string
+	MetricType string
+	Phase      string
+	Category   string
+	StartStep  int64
+	EndStep    int64
+	StartTime  *time.Time
+	EndTime    *time.Time
+}
+
+// MetricSeriesPoint 降采样后的一个数据点，Step为桶内最后一步，Value为桶内均值
+type MetricSeriesPoint struct {
+	Step     int64
+	Value    float64
+	Min      float64
+	Max      float64
+	WallTime float64
+}
+
+// MetricSeries 一条标量指标曲线，按指标名和阶段区分
+type MetricSeries struct {
+	MetricName string
+	Phase      string
+	Total      int64 // 降采样前的数据点数
+	Points     []MetricSeriesPoint
+}
+
+// VtTrainingMetricsModel 训练指标模型操作接口
+type VtTrainingMetricsModel interface {
+	Insert(data *VtTrainingMetrics) (sql.Result, error)
+	// BatchInsert 在一个事务内批量写入指标
+	BatchInsert(metrics []*VtTrainingMetrics) error
+	// List 按步数和写入顺序分页查询原始指标
+	List(filter TrainingMetricFilter, page, pageSize int) ([]*VtTrainingMetrics, int64, error)
+	// FindSeries 查询标量指标曲线，每条曲线超过maxPoints个点时按步数分桶聚合
+	FindSeries(filter TrainingMetricFilter, maxPoints int) ([]*MetricSeries, error)
+	// FindScalarSeries 查询作业某个标量指标按步数排序的数据点，步数优先使用global_step
+	FindScalarSeries(jobId int64, metricName string) ([]MetricPoint, error)
+	// FindLatestScalars 查询作业每个标量指标最近写入的一条记录，按指标名索引
+	FindLatestScalars(jobId int64) (map[string]*VtTrainingMetrics, error)
+}
+
+type vtTrainingMetricsModel struct {
+	conn *sql.DB
+}
+
+func NewVtTrainingMetricsModel(conn *sql.DB) VtTrainingMetricsModel {
+	return &vtTrainingMetricsModel{conn: conn}
+}
+
+const (
+	vtTrainingMetricsFields = `id, job_id, IFNULL(instance_id, 0), metric_name, IFNULL(metric_type, 'scalar'), IFNULL(metric_value, ''), IFNULL(metric_data, ''),
+		IFNULL(step, 0), IFNULL(epoch, 0), IFNULL(global_step, 0), IFNULL(batch_idx, 0), IFNULL(tag, ''), IFNULL(category, ''), IFNULL(phase, ''),
+		metric_time, IFNULL(wall_time, ''), IFNULL(relative_time_seconds, ''), IFNULL(min_value, ''), IFNULL(max_value, ''), IFNULL(avg_value, ''),
+		IFNULL(std_value, ''), created_at`
+
+	vtTrainingMetricsInsertColumns = `INSERT INTO vt_training_metrics (job_id, instance_id, metric_name, metric_type, metric_value, metric_data,
+		step, epoch, global_step, batch_idx, tag, category, phase, metric_time, wall_time, relative_time_seconds,
+		min_value, max_value, avg_value, std_value) VALUES `
+	vtTrainingMetricsInsertValues = `(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	// metricBatchSize 批量写入时每条INSERT语句的行数
+	metricBatchSize = 500
+
+	// metricStepExpr 曲线横轴使用的步数，与FindScalarSeries一致
+	metricStepExpr = `COALESCE(global_step, step, 0)`
+)
+
+func scanVtTrainingMetrics(scanner rowScanner) (*VtTrainingMetrics, error) {
+	var m VtTrainingMetrics
+	err := scanner.Scan(&m.Id, &m.JobId, &m.InstanceId, &m.MetricName, &m.MetricType, &m.MetricValue, &m.MetricData,
+		&m.Step, &m.Epoch, &m.GlobalStep, &m.BatchIdx, &m.Tag, &m.Category, &m.Phase,
+		&m.MetricTime, &m.WallTime, &m.RelativeTimeSeconds, &m.MinValue, &m.MaxValue, &m.AvgValue,
+		&m.StdValue, &m.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// insertArgs 按插入列顺序返回参数，global_step为0时写入NULL，曲线横轴回退到step
+func (data *VtTrainingMetrics) insertArgs() []interface{} {
+	var instanceId, globalStep interface{}
+	if data.InstanceId > 0 {
+		instanceId = data.InstanceId
+	}
+	if data.GlobalStep > 0 {
+		globalStep = data.GlobalStep
+	}
+	metricTime := data.MetricTime
+	if metricTime.IsZero() {
+		metricTime = time.Now()
+	}
+	return []interface{}{data.JobId, instanceId, data.MetricName, data.MetricType, nullableString(data.MetricValue), nullableJSON(data.MetricData),
+		data.Step, data.Epoch, globalStep, data.BatchIdx, nullableString(data.Tag), nullableString(data.Category), nullableString(data.Phase),
+		metricTime, nullableString(data.WallTime), nullableString(data.RelativeTimeSeconds),
+		nullableString(data.MinValue), nullableString(data.MaxValue), nullableString(data.AvgValue), nullableString(data.StdValue)}
+}
+
+func (m *vtTrainingMetricsModel) Insert(data *VtTrainingMetrics) (sql.Result, error) {
+	return m.conn.Exec(vtTrainingMetricsInsertColumns+vtTrainingMetricsInsertValues, data.insertArgs()...)
+}
+
+func (m *vtTrainingMetricsModel) BatchInsert(metrics []*VtTrainingMetrics) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	tx, err := m.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for start := 0; start < len(metrics); start += metricBatchSize {
+		batch := metrics[start:min(start+metricBatchSize, len(metrics))]
+		query := vtTrainingMetricsInsertColumns + vtTrainingMetricsInsertValues + strings.Repeat(`, `+vtTrainingMetricsInsertValues, len(batch)-1)
+		args := make([]interface{}, 0, len(batch)*20)
+		for _, metric := range batch {
+			args = append(args, metric.insertArgs()...)
+		}
+		if _, err := tx.Exec(query, args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// whereClause 构造查询条件
+func (filter TrainingMetricFilter) whereClause() (string, []interface{}) {
+	conditions := []string{`job_id = ?`}
+	args := []interface{}{filter.JobId}
+	if filter.MetricName != "" {
+		conditions = append(conditions, `metric_name = ?`)
+		args = append(args, filter.MetricName)
+	}
+	if filter.MetricType != "" {
+		conditions = append(conditions, `metric_type = ?`)
+		args = append(args, filter.MetricType)
+	}
+	if filter.Phase != "" {
+		conditions = append(conditions, `phase = ?`)
+		args = append(args, filter.Phase)
+	}
+	if filter.Category != "" {
+		conditions = append(conditions, `category = ?`)
+		args = append(args, filter.Category)
+	}
+	if filter.StartStep > 0 {
+		conditions = append(conditions, metricStepExpr+` >= ?`)
+		args = append(args, filter.StartStep)
+	}
+	if filter.EndStep > 0 {
+		conditions = append(conditions, metricStepExpr+` <= ?`)
+		args = append(args, filter.EndStep)
+	}
+	if filter.StartTime != nil {
+		conditions = append(conditions, `metric_time >= ?`)
+		args = append(args, *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		conditions = append(conditions, `metric_time <= ?`)
+		args = append(args, *filter.EndTime)
+	}
+	return `WHERE ` + strings.Join(conditions, ` AND `), args
+}
+
+func (m *vtTrainingMetricsModel) List(filter TrainingMetricFilter, page, pageSize int) ([]*VtTrainingMetrics, int64, error) {
+	whereClause, args := filter.whereClause()
+
+	var total int64
+	if err := m.conn.QueryRow(`SELECT COUNT(*) FROM vt_training_metrics `+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 100
+	}
+	query := `SELECT ` + vtTrainingMetricsFields + ` FROM vt_training_metrics ` + whereClause + ` ORDER BY ` + metricStepExpr + ` ASC, id ASC LIMIT ? OFFSET ?`
+	rows, err := m.conn.Query(query, append(args, pageSize, (page-1)*pageSize)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var metrics []*VtTrainingMetrics
+	for rows.Next() {
+		metric, err := scanVtTrainingMetrics(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		metrics = append(metrics, metric)
+	}
+	return metrics, total, rows.Err()
+}
+
+func (m *vtTrainingMetricsModel) FindSeries(filter TrainingMetricFilter, maxPoints int) ([]*MetricSeries, error) {
+	filter.MetricType = MetricTypeScalar
+	whereClause, args := filter.whereClause()
+	whereClause += ` AND metric_value IS NOT NULL`
+
+	// 先统计每条曲线的点数和步数范围，再决定是否分桶
+	statsQuery := `SELECT metric_name, IFNULL(phase, ''), COUNT(*), MIN(` + metricStepExpr + `), MAX(` + metricStepExpr + `)
+		FROM vt_training_metrics ` + whereClause + ` GROUP BY metric_name, phase ORDER BY metric_name, phase`
+	rows, err := m.conn.Query(statsQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	type seriesRange struct {
+		series           *MetricSeries
+		minStep, maxStep int64
+	}
+	var ranges []seriesRange
+	for rows.Next() {
+		r := seriesRange{series: &MetricSeries{}}
+		if err := rows.Scan(&r.series.MetricName, &r.series.Phase, &r.series.Total, &r.minStep, &r.maxStep); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ranges = append(ranges, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]*MetricSeries, 0, len(ranges))
+	for _, r := range ranges {
+		seriesWhere := whereClause + ` AND metric_name = ? AND IFNULL(phase, '') = ?`
+		seriesArgs := append(append([]interface{}{}, args...), r.series.MetricName, r.series.Phase)
+
+		var query string
+		if maxPoints <= 0 || r.series.Total <= int64(maxPoints) {
+			query = `SELECT ` + metricStepExpr + ` AS s, metric_value, metric_value, metric_value, IFNULL(wall_time, 0)
+				FROM vt_training_metrics ` + seriesWhere + ` ORDER BY s ASC, id ASC`
+		} else {
+			// 按步数等宽分桶，返回桶内均值和上下界，保留曲线的波动范围
+			width := (r.maxStep-r.minStep)/int64(maxPoints) + 1
+			query = `SELECT MAX(` + metricStepExpr + `), AVG(metric_value), MIN(metric_value), MAX(metric_value), IFNULL(MAX(wall_time), 0)
+				FROM vt_training_metrics ` + seriesWhere + ` GROUP BY FLOOR((` + metricStepExpr + ` - ?) / ?) ORDER BY 1 ASC`
+			seriesArgs = append(seriesArgs, r.minStep, width)
+		}
+		if r.series.Points, err = m.querySeriesPoints(query, seriesArgs...); err != nil {
+			return nil, err
+		}
+		result = append(result, r.series)
+	}
+	return result, nil
+}
+
+func (m *vtTrainingMetricsModel) querySeriesPoints(query string, args ...interface{}) ([]MetricSeriesPoint, error) {
+	rows, err := m.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []MetricSeriesPoint
+	for rows.Next() {
+		var p MetricSeriesPoint
+		if err := rows.Scan(&p.Step, &p.Value, &p.Min, &p.Max, &p.WallTime); err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+func (m *vtTrainingMetricsModel) FindScalarSeries(jobId int64, metricName string) ([]MetricPoint, error) {
+	query := `SELECT ` + metricStepExpr + ` AS s, metric_value FROM vt_training_metrics WHERE job_id = ? AND metric_name = ? AND metric_type = 'scalar' AND metric_value IS NOT NULL ORDER BY s ASC, id ASC`
+	rows, err := m.conn.Query(query, jobId, metricName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []MetricPoint
+	for rows.Next() {
+		var p MetricPoint
+		if err := rows.Scan(&p.Step, &p.Value); err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+func (m *vtTrainingMetricsModel) FindLatestScalars(jobId int64) (map[string]*VtTrainingMetrics, error) {
+	query := `SELECT ` + vtTrainingMetricsFields + ` FROM vt_training_metrics WHERE id IN (
+		SELECT MAX(id) FROM vt_training_metrics WHERE job_id = ? AND metric_type = 'scalar' AND metric_value IS NOT NULL GROUP BY metric_name)`
+	rows, err := m.conn.Query(query, jobId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	latest := make(map[string]*VtTrainingMetrics)
+	for rows.Next() {
+		metric, err := scanVtTrainingMetrics(rows)
+		if err != nil {
+			return nil, err
+		}
+		latest[metric.MetricName] = metric
+	}
+	return latest, rows.Err()
+}
+
+// nullableString 空字符串写入NULL，用于可为空的DECIMAL和ENUM列
+func nullableString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,179 @@
+package model
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 超参数搜索状态
+const (
+	SweepStatusRunning   = "running"
+	SweepStatusCompleted = "completed"
+	SweepStatusFailed    = "failed"
+	SweepStatusCancelled = "cancelled"
+)
+
+// VtTrainingSweeps 超参数搜索模型
+type VtTrainingSweeps struct {
+	Id              int64      `db:"id" json:"id"`
+	Name            string     `db:"name" json:"name"`
+	DisplayName     string     `db:"display_name" json:"displayName"`
+	Description     string     `db:"description" json:"description"`
+	OwnerId         int64      `db:"owner_id" json:"ownerId"`
+	OwnerName       string     `db:"owner_name" json:"ownerName"`
+	Algorithm       string     `db:"algorithm" json:"algorithm"`
+	ObjectiveMetric string     `db:"objective_metric" json:"objectiveMetric"`
+	ObjectiveGoal   string     `db:"objective_goal" json:"objectiveGoal"`
+	SearchSpace     string     `db:"search_space" json:"searchSpace"`
+	BaseSpec        string     `db:"base_spec" json:"baseSpec"`
+	MaxTrials       int        `db:"max_trials" json:"maxTrials"`
+	Parallelism     int        `db:"parallelism" json:"parallelism"`
+	EarlyStopping   string     `db:"early_stopping" json:"earlyStopping"`
+	Status          string     `db:"status" json:"status"`
+	TrialCount      int        `db:"trial_count" json:"trialCount"`
+	CompletedCount  int        `db:"completed_count" json:"completedCount"`
+	PrunedCount     int        `db:"pruned_count" json:"prunedCount"`
+	BestJobId       int64      `db:"best_job_id" json:"bestJobId"`
+	BestValue       *float64   `db:"best_value" json:"bestValue"`
+	ErrorMessage    string     `db:"error_message" json:"errorMessage"`
+	FinishedAt      *time.Time `db:"finished_at" json:"finishedAt"`
+	CreatedAt       time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt       time.Time  `db:"updated_at" json:"updatedAt"`
+}
+
+// SweepProgress 一轮调度后更新的搜索进度
+type SweepProgress struct {
+	TrialCount     int
+	CompletedCount int
+	PrunedCount    int
+	BestJobId      int64
+	BestValue      *float64
+}
+
+// VtTrainingSweepsModel 超参数搜索模型操作接口
+type VtTrainingSweepsModel interface {
+	Insert(data *VtTrainingSweeps) (sql.Result, error)
+	FindOne(id int64) (*VtTrainingSweeps, error)
+	FindOneByName(ownerId int64, name string) (*VtTrainingSweeps, error)
+	FindRunning() ([]*VtTrainingSweeps, error)
+	List(ownerId int64, status, keyword string, page, pageSize int) ([]*VtTrainingSweeps, int64, error)
+	UpdateProgress(id int64, progress *SweepProgress) error
+	// Finish 将运行中的搜索标记为结束，搜索已不在运行状态时返回false
+	Finish(id int64, status, errorMessage string) (bool, error)
+}
+
+type vtTrainingSweepsModel struct {
+	conn *sql.DB
+}
+
+func NewVtTrainingSweepsModel(conn *sql.DB) VtTrainingSweepsModel {
+	return &vtTrainingSweepsModel{conn: conn}
+}
+
+const vtTrainingSweepsFields = `id, name, IFNULL(display_name, ''), IFNULL(description, ''), owner_id, IFNULL(owner_name, ''), algorithm, objective_metric, objective_goal, search_space, base_spec, max_trials, parallelism, IFNULL(early_stopping, ''), status, IFNULL(trial_count, 0), IFNULL(completed_count, 0), IFNULL(pruned_count, 0), IFNULL(best_job_id, 0), best_value, IFNULL(error_message, ''), finished_at, created_at, updated_at`
+
+func scanVtTrainingSweeps(scanner rowScanner) (*VtTrainingSweeps, error) {
+	var s VtTrainingSweeps
+	err := scanner.Scan(&s.Id, &s.Name, &s.DisplayName, &s.Description, &s.OwnerId, &s.OwnerName, &s.Algorithm, &s.ObjectiveMetric,
+		&s.ObjectiveGoal, &s.SearchSpace, &s.BaseSpec, &s.MaxTrials, &s.Parallelism, &s.EarlyStopping, &s.Status, &s.TrialCount,
+		&s.CompletedCount, &s.PrunedCount, &s.BestJobId, &s.BestValue, &s.ErrorMessage, &s.FinishedAt, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (m *vtTrainingSweepsModel) Insert(data *VtTrainingSweeps) (sql.Result, error) {
+	query := `INSERT INTO vt_training_sweeps (name, display_name, description, owner_id, owner_name, algorithm, objective_metric, objective_goal, search_space, base_spec, max_trials, parallelism, early_stopping, status) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	var earlyStopping interface{}
+	if data.EarlyStopping != "" {
+		earlyStopping = data.EarlyStopping
+	}
+	return m.conn.Exec(query, data.Name, data.DisplayName, data.Description, data.OwnerId, data.OwnerName, data.Algorithm,
+		data.ObjectiveMetric, data.ObjectiveGoal, data.SearchSpace, data.BaseSpec, data.MaxTrials, data.Parallelism, earlyStopping, SweepStatusRunning)
+}
+
+func (m *vtTrainingSweepsModel) FindOne(id int64) (*VtTrainingSweeps, error) {
+	query := `SELECT ` + vtTrainingSweepsFields + ` FROM vt_training_sweeps WHERE id = ? AND deleted_at IS NULL`
+	return scanVtTrainingSweeps(m.conn.QueryRow(query, id))
+}
+
+func (m *vtTrainingSweepsModel) FindOneByName(ownerId int64, name string) (*VtTrainingSweeps, error) {
+	query := `SELECT ` + vtTrainingSweepsFields + ` FROM vt_training_sweeps WHERE owner_id = ? AND name = ? AND deleted_at IS NULL`
+	return scanVtTrainingSweeps(m.conn.QueryRow(query, ownerId, name))
+}
+
+func (m *vtTrainingSweepsModel) FindRunning() ([]*VtTrainingSweeps, error) {
+	query := `SELECT ` + vtTrainingSweepsFields + ` FROM vt_training_sweeps WHERE status = ? AND deleted_at IS NULL ORDER BY id ASC`
+	return m.query(query, SweepStatusRunning)
+}
+
+func (m *vtTrainingSweepsModel) List(ownerId int64, status, keyword string, page, pageSize int) ([]*VtTrainingSweeps, int64, error) {
+	conditions := []string{"deleted_at IS NULL", "owner_id = ?"}
+	args := []interface{}{ownerId}
+	if status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, status)
+	}
+	if keyword != "" {
+		conditions = append(conditions, "(name LIKE ? OR display_name LIKE ?)")
+		args = append(args, "%"+keyword+"%", "%"+keyword+"%")
+	}
+	whereClause := "WHERE " + strings.Join(conditions, " AND ")
+
+	var total int64
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM vt_training_sweeps %s", whereClause)
+	if err := m.conn.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	query := fmt.Sprintf("SELECT %s FROM vt_training_sweeps %s ORDER BY created_at DESC LIMIT ? OFFSET ?", vtTrainingSweepsFields, whereClause)
+	sweeps, err := m.query(query, append(args, pageSize, (page-1)*pageSize)...)
+	return sweeps, total, err
+}
+
+func (m *vtTrainingSweepsModel) UpdateProgress(id int64, progress *SweepProgress) error {
+	var bestJobId interface{}
+	if progress.BestJobId > 0 {
+		bestJobId = progress.BestJobId
+	}
+	query := `UPDATE vt_training_sweeps SET trial_count = ?, completed_count = ?, pruned_count = ?, best_job_id = ?, best_value = ? WHERE id = ?`
+	_, err := m.conn.Exec(query, progress.TrialCount, progress.CompletedCount, progress.PrunedCount, bestJobId, progress.BestValue, id)
+	return err
+}
+
+func (m *vtTrainingSweepsModel) Finish(id int64, status, errorMessage string) (bool, error) {
+	query := `UPDATE vt_training_sweeps SET status = ?, error_message = ?, finished_at = NOW() WHERE id = ? AND status = ?`
+	result, err := m.conn.Exec(query, status, errorMessage, id, SweepStatusRunning)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (m *vtTrainingSweepsModel) query(query string, args ...interface{}) ([]*VtTrainingSweeps, error) {
+	rows, err := m.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sweeps []*VtTrainingSweeps
+	for rows.Next() {
+		s, err := scanVtTrainingSweeps(rows)
+		if err != nil {
+			return nil, err
+		}
+		sweeps = append(sweeps, s)
+	}
+	return sweeps, rows.Err()
+}
@@ -0,0 +1,110 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// 定时触发结果
+const (
+	TriggerRunCreated = "created" // 已创建训练作业
+	TriggerRunSkipped = "skipped" // 因Forbid策略跳过
+	TriggerRunMissed  = "missed"  // 服务停机等原因错过触发时间
+	TriggerRunFailed  = "failed"  // 创建训练作业失败
+)
+
+// VtTrainingTriggerRuns 定时触发记录模型
+type VtTrainingTriggerRuns struct {
+	Id             int64     `db:"id" json:"id"`
+	TriggerId      int64     `db:"trigger_id" json:"triggerId"`
+	ScheduledAt    time.Time `db:"scheduled_at" json:"scheduledAt"`
+	Status         string    `db:"status" json:"status"`
+	JobId          int64     `db:"job_id" json:"jobId"`
+	ReplacedJobIds string    `db:"replaced_job_ids" json:"replacedJobIds"`
+	Message        string    `db:"message" json:"message"`
+	CreatedAt      time.Time `db:"created_at" json:"createdAt"`
+}
+
+// VtTrainingTriggerRunsModel 定时触发记录模型操作接口
+type VtTrainingTriggerRunsModel interface {
+	Insert(data *VtTrainingTriggerRuns) (sql.Result, error)
+	// FindByTriggerId 按计划触发时间倒序分页查询触发记录，status为空时不过滤
+	FindByTriggerId(triggerId int64, status string, page, pageSize int) ([]*VtTrainingTriggerRuns, int64, error)
+	// FindRecentJobs 查询最近创建了作业的触发记录，用于判断是否有未结束的运行
+	FindRecentJobs(triggerId int64, limit int) ([]*VtTrainingTriggerRuns, error)
+}
+
+type vtTrainingTriggerRunsModel struct {
+	conn *sql.DB
+}
+
+func NewVtTrainingTriggerRunsModel(conn *sql.DB) VtTrainingTriggerRunsModel {
+	return &vtTrainingTriggerRunsModel{conn: conn}
+}
+
+const vtTrainingTriggerRunsFields = `id, trigger_id, scheduled_at, status, IFNULL(job_id, 0), IFNULL(replaced_job_ids, ''), IFNULL(message, ''), created_at`
+
+func scanVtTrainingTriggerRuns(scanner rowScanner) (*VtTrainingTriggerRuns, error) {
+	var r VtTrainingTriggerRuns
+	err := scanner.Scan(&r.Id, &r.TriggerId, &r.ScheduledAt, &r.Status, &r.JobId, &r.ReplacedJobIds, &r.Message, &r.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (m *vtTrainingTriggerRunsModel) Insert(data *VtTrainingTriggerRuns) (sql.Result, error) {
+	query := `INSERT INTO vt_training_trigger_runs (trigger_id, scheduled_at, status, job_id, replaced_job_ids, message) VALUES (?, ?, ?, ?, ?, ?)`
+	var jobId interface{}
+	if data.JobId > 0 {
+		jobId = data.JobId
+	}
+	return m.conn.Exec(query, data.TriggerId, data.ScheduledAt, data.Status, jobId, nullableJSON(data.ReplacedJobIds), data.Message)
+}
+
+func (m *vtTrainingTriggerRunsModel) FindByTriggerId(triggerId int64, status string, page, pageSize int) ([]*VtTrainingTriggerRuns, int64, error) {
+	whereClause := `WHERE trigger_id = ?`
+	args := []interface{}{triggerId}
+	if status != "" {
+		whereClause += ` AND status = ?`
+		args = append(args, status)
+	}
+
+	var total int64
+	if err := m.conn.QueryRow(`SELECT COUNT(*) FROM vt_training_trigger_runs `+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	query := `SELECT ` + vtTrainingTriggerRunsFields + ` FROM vt_training_trigger_runs ` + whereClause + ` ORDER BY scheduled_at DESC, id DESC LIMIT ? OFFSET ?`
+	runs, err := m.query(query, append(args, pageSize, (page-1)*pageSize)...)
+	return runs, total, err
+}
+
+func (m *vtTrainingTriggerRunsModel) FindRecentJobs(triggerId int64, limit int) ([]*VtTrainingTriggerRuns, error) {
+	query := `SELECT ` + vtTrainingTriggerRunsFields + ` FROM vt_training_trigger_runs WHERE trigger_id = ? AND status = ? ORDER BY scheduled_at DESC, id DESC LIMIT ?`
+	return m.query(query, triggerId, TriggerRunCreated, limit)
+}
+
+func (m *vtTrainingTriggerRunsModel) query(query string, args ...interface{}) ([]*VtTrainingTriggerRuns, error) {
+	rows, err := m.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*VtTrainingTriggerRuns
+	for rows.Next() {
+		r, err := scanVtTrainingTriggerRuns(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
@@ -0,0 +1,189 @@
+package model
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 定时触发器目标类型
+const (
+	TriggerTargetJob      = "job"      // 复制已有训练作业
+	TriggerTargetTemplate = "template" // 实例化训练作业模板
+)
+
+// 定时触发器并发策略，语义同Kubernetes CronJob
+const (
+	TriggerConcurrencyAllow   = "Allow"   // 允许与未结束的运行并行
+	TriggerConcurrencyForbid  = "Forbid"  // 上一次运行未结束时跳过本次
+	TriggerConcurrencyReplace = "Replace" // 取消未结束的运行后创建新运行
+)
+
+// VtTrainingTriggers 训练作业定时触发器模型
+type VtTrainingTriggers struct {
+	Id                      int64      `db:"id" json:"id"`
+	Name                    string     `db:"name" json:"name"`
+	Description             string     `db:"description" json:"description"`
+	OwnerId                 int64      `db:"owner_id" json:"ownerId"`
+	OwnerName               string     `db:"owner_name" json:"ownerName"`
+	TargetType              string     `db:"target_type" json:"targetType"`
+	TargetId                int64      `db:"target_id" json:"targetId"`
+	Parameters              string     `db:"parameters" json:"parameters"`
+	Schedule                string     `db:"schedule" json:"schedule"`
+	Timezone                string     `db:"timezone" json:"timezone"`
+	ConcurrencyPolicy       string     `db:"concurrency_policy" json:"concurrencyPolicy"`
+	StartingDeadlineSeconds int        `db:"starting_deadline_seconds" json:"startingDeadlineSeconds"`
+	Enabled                 bool       `db:"enabled" json:"enabled"`
+	NextRunAt               *time.Time `db:"next_run_at" json:"nextRunAt"`
+	LastRunAt               *time.Time `db:"last_run_at" json:"lastRunAt"`
+	LastJobId               int64      `db:"last_job_id" json:"lastJobId"`
+	RunCount                int        `db:"run_count" json:"runCount"`
+	CreatedAt               time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt               time.Time  `db:"updated_at" json:"updatedAt"`
+}
+
+// VtTrainingTriggersModel 训练作业定时触发器模型操作接口
+type VtTrainingTriggersModel interface {
+	Insert(data *VtTrainingTriggers) (sql.Result, error)
+	FindOne(id int64) (*VtTrainingTriggers, error)
+	FindOneByName(ownerId int64, name string) (*VtTrainingTriggers, error)
+	// FindDue 查询已启用且到达触发时间的触发器
+	FindDue(now time.Time, limit int) ([]*VtTrainingTriggers, error)
+	List(ownerId int64, targetType, keyword string, page, pageSize int) ([]*VtTrainingTriggers, int64, error)
+	// Update 更新调度配置和下一次触发时间
+	Update(data *VtTrainingTriggers) error
+	// Advance 将下一次触发时间从from推进到to，已被其他实例推进时返回false
+	Advance(id int64, from time.Time, to *time.Time) (bool, error)
+	// RecordJob 记录触发创建的作业
+	RecordJob(id, jobId int64, runAt time.Time) error
+	Delete(id int64) error
+}
+
+type vtTrainingTriggersModel struct {
+	conn *sql.DB
+}
+
+func NewVtTrainingTriggersModel(conn *sql.DB) VtTrainingTriggersModel {
+	return &vtTrainingTriggersModel{conn: conn}
+}
+
+const vtTrainingTriggersFields = `id, name, IFNULL(description, ''), owner_id, IFNULL(owner_name, ''), target_type, target_id, IFNULL(parameters, ''), schedule, timezone, concurrency_policy, starting_deadline_seconds, enabled, next_run_at, last_run_at, IFNULL(last_job_id, 0), IFNULL(run_count, 0), created_at, updated_at`
+
+func scanVtTrainingTriggers(scanner rowScanner) (*VtTrainingTriggers, error) {
+	var t VtTrainingTriggers
+	err := scanner.Scan(&t.Id, &t.Name, &t.Description, &t.OwnerId, &t.OwnerName, &t.TargetType, &t.TargetId, &t.Parameters,
+		&t.Schedule, &t.Timezone, &t.ConcurrencyPolicy, &t.StartingDeadlineSeconds, &t.Enabled, &t.NextRunAt, &t.LastRunAt,
+		&t.LastJobId, &t.RunCount, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (m *vtTrainingTriggersModel) Insert(data *VtTrainingTriggers) (sql.Result, error) {
+	query := `INSERT INTO vt_training_triggers (name, description, owner_id, owner_name, target_type, target_id, parameters, schedule, timezone, concurrency_policy, starting_deadline_seconds, enabled, next_run_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	return m.conn.Exec(query, data.Name, data.Description, data.OwnerId, data.OwnerName, data.TargetType, data.TargetId,
+		nullableJSON(data.Parameters), data.Schedule, data.Timezone, data.ConcurrencyPolicy, data.StartingDeadlineSeconds, data.Enabled, data.NextRunAt)
+}
+
+func (m *vtTrainingTriggersModel) FindOne(id int64) (*VtTrainingTriggers, error) {
+	query := `SELECT ` + vtTrainingTriggersFields + ` FROM vt_training_triggers WHERE id = ? AND deleted_at IS NULL`
+	return scanVtTrainingTriggers(m.conn.QueryRow(query, id))
+}
+
+func (m *vtTrainingTriggersModel) FindOneByName(ownerId int64, name string) (*VtTrainingTriggers, error) {
+	query := `SELECT ` + vtTrainingTriggersFields + ` FROM vt_training_triggers WHERE owner_id = ? AND name = ? AND deleted_at IS NULL`
+	return scanVtTrainingTriggers(m.conn.QueryRow(query, ownerId, name))
+}
+
+func (m *vtTrainingTriggersModel) FindDue(now time.Time, limit int) ([]*VtTrainingTriggers, error) {
+	query := `SELECT ` + vtTrainingTriggersFields + ` FROM vt_training_triggers WHERE enabled = 1 AND deleted_at IS NULL AND next_run_at IS NOT NULL AND next_run_at <= ? ORDER BY next_run_at ASC LIMIT ?`
+	return m.query(query, now, limit)
+}
+
+func (m *vtTrainingTriggersModel) List(ownerId int64, targetType, keyword string, page, pageSize int) ([]*VtTrainingTriggers, int64, error) {
+	conditions := []string{"deleted_at IS NULL", "owner_id = ?"}
+	args := []interface{}{ownerId}
+	if targetType != "" {
+		conditions = append(conditions, "target_type = ?")
+		args = append(args, targetType)
+	}
+	if keyword != "" {
+		conditions = append(conditions, "(name LIKE ? OR description LIKE ?)")
+		args = append(args, "%"+keyword+"%", "%"+keyword+"%")
+	}
+	whereClause := "WHERE " + strings.Join(conditions, " AND ")
+
+	var total int64
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM vt_training_triggers %s", whereClause)
+	if err := m.conn.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	query := fmt.Sprintf("SELECT %s FROM vt_training_triggers %s ORDER BY created_at DESC LIMIT ? OFFSET ?", vtTrainingTriggersFields, whereClause)
+	triggers, err := m.query(query, append(args, pageSize, (page-1)*pageSize)...)
+	return triggers, total, err
+}
+
+func (m *vtTrainingTriggersModel) Update(data *VtTrainingTriggers) error {
+	query := `UPDATE vt_training_triggers SET description = ?, parameters = ?, schedule = ?, timezone = ?, concurrency_policy = ?, starting_deadline_seconds = ?, enabled = ?, next_run_at = ? WHERE id = ? AND deleted_at IS NULL`
+	_, err := m.conn.Exec(query, data.Description, nullableJSON(data.Parameters), data.Schedule, data.Timezone, data.ConcurrencyPolicy,
+		data.StartingDeadlineSeconds, data.Enabled, data.NextRunAt, data.Id)
+	return err
+}
+
+func (m *vtTrainingTriggersModel) Advance(id int64, from time.Time, to *time.Time) (bool, error) {
+	query := `UPDATE vt_training_triggers SET next_run_at = ? WHERE id = ? AND next_run_at = ? AND enabled = 1 AND deleted_at IS NULL`
+	result, err := m.conn.Exec(query, to, id, from)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (m *vtTrainingTriggersModel) RecordJob(id, jobId int64, runAt time.Time) error {
+	query := `UPDATE vt_training_triggers SET last_run_at = ?, last_job_id = ?, run_count = IFNULL(run_count, 0) + 1 WHERE id = ?`
+	_, err := m.conn.Exec(query, runAt, jobId, id)
+	return err
+}
+
+// Delete 软删除触发器，名称追加删除标记以释放唯一索引
+func (m *vtTrainingTriggersModel) Delete(id int64) error {
+	query := `UPDATE vt_training_triggers SET deleted_at = NOW(), enabled = 0, name = CONCAT(name, '#deleted-', id) WHERE id = ? AND deleted_at IS NULL`
+	_, err := m.conn.Exec(query, id)
+	return err
+}
+
+func (m *vtTrainingTriggersModel) query(query string, args ...interface{}) ([]*VtTrainingTriggers, error) {
+	rows, err := m.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var triggers []*VtTrainingTriggers
+	for rows.Next() {
+		t, err := scanVtTrainingTriggers(rows)
+		if err != nil {
+			return nil, err
+		}
+		triggers = append(triggers, t)
+	}
+	return triggers, rows.Err()
+}
+
+// nullableJSON 空字符串写入NULL，避免写入JSON列失败
+func nullableJSON(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"database/sql"
+)
+
+// VtWorkspaceMembersModel 工作空间成员模型操作接口
+type VtWorkspaceMembersModel interface {
+	// IsActiveMember 判断用户是否为工作空间的有效成员
+	IsActiveMember(workspaceId, userId int64) (bool, error)
+}
+
+type vtWorkspaceMembersModel struct {
+	conn *sql.DB
+}
+
+func NewVtWorkspaceMembersModel(conn *sql.DB) VtWorkspaceMembersModel {
+	return &vtWorkspaceMembersModel{conn: conn}
+}
+
+func (m *vtWorkspaceMembersModel) IsActiveMember(workspaceId, userId int64) (bool, error) {
+	var count int64
+	err := m.conn.QueryRow(`SELECT COUNT(*) FROM vt_workspace_members WHERE workspace_id = ? AND user_id = ? AND status = 'active'`,
+		workspaceId, userId).Scan(&count)
+	return count > 0, err
+}
@@ -0,0 +1,34 @@
+package checkpoint
+
+import "fmt"
+
+// Improved 判断value是否比best改善超过minDelta
+func Improved(value, best, minDelta float64, goal string) bool {
+	if goal == RetentionGoalMaximize {
+		return value > best+minDelta
+	}
+	return value < best-minDelta
+}
+
+// EarlyStop 按上报顺序的监控指标评估值判断是否提前停止训练，返回停止原因
+// 最优值之后连续Patience次评估没有改善超过MinDelta时停止，未配置Patience时不停止
+func (c CheckpointConfig) EarlyStop(values []float64) (bool, string) {
+	if c.Patience <= 0 || len(values) == 0 {
+		return false, ""
+	}
+
+	best := values[0]
+	wait := 0
+	for _, value := range values[1:] {
+		if Improved(value, best, c.MinDelta, c.MonitorGoal) {
+			best = value
+			wait = 0
+			continue
+		}
+		wait++
+	}
+	if wait < c.Patience {
+		return false, ""
+	}
+	return true, fmt.Sprintf("指标 %s 连续 %d 次评估没有改善超过 %g，最优值 %g", c.MonitorMetric, wait, c.MinDelta, best)
+}
@@ -0,0 +1,132 @@
+package checkpoint
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// 检查点压缩类型，与vt_training_checkpoints.compression_type一致
+const (
+	CompressionNone  = "none"
+	CompressionGzip  = "gzip"
+	CompressionBzip2 = "bzip2"
+	CompressionLz4   = "lz4"
+)
+
+var (
+	// ErrCorrupted 检查点文件缺失、校验和不匹配或无法解压
+	ErrCorrupted = errors.New("检查点文件已损坏")
+	// ErrUnsupportedCompression 平台无法处理该压缩类型
+	ErrUnsupportedCompression = errors.New("不支持的压缩类型")
+)
+
+// ValidCompression 判断是否为表中定义的压缩类型
+func ValidCompression(compression string) bool {
+	switch compression {
+	case CompressionNone, CompressionGzip, CompressionBzip2, CompressionLz4:
+		return true
+	}
+	return false
+}
+
+// NormalizeChecksum 规范化校验和为"算法:十六进制摘要"
+// 支持sha256和md5，未带算法前缀时按摘要长度推断
+func NormalizeChecksum(checksum string) (string, error) {
+	algorithm, digest, found := strings.Cut(strings.ToLower(strings.TrimSpace(checksum)), ":")
+	if !found {
+		digest = algorithm
+		switch len(digest) {
+		case sha256.Size * 2:
+			algorithm = "sha256"
+		case md5.Size * 2:
+			algorithm = "md5"
+		default:
+			return "", fmt.Errorf("无法识别的校验和: %s", checksum)
+		}
+	}
+	h, err := newChecksumHash(algorithm)
+	if err != nil {
+		return "", err
+	}
+	if decoded, err := hex.DecodeString(digest); err != nil || len(decoded) != h.Size() {
+		return "", fmt.Errorf("无效的%s校验和: %s", algorithm, checksum)
+	}
+	return algorithm + ":" + digest, nil
+}
+
+func newChecksumHash(algorithm string) (hash.Hash, error) {
+	switch algorithm {
+	case "sha256":
+		return sha256.New(), nil
+	case "md5":
+		return md5.New(), nil
+	}
+	return nil, fmt.Errorf("不支持的校验和算法: %s", algorithm)
+}
+
+// checksumWriter 计算写入数据的校验和
+type checksumWriter struct {
+	algorithm string
+	hash      hash.Hash
+	size      int64
+}
+
+// newChecksumWriter checksum为空时使用sha256，否则使用checksum中的算法
+func newChecksumWriter(checksum string) (*checksumWriter, error) {
+	algorithm := "sha256"
+	if checksum != "" {
+		algorithm, _, _ = strings.Cut(checksum, ":")
+	}
+	h, err := newChecksumHash(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	return &checksumWriter{algorithm: algorithm, hash: h}, nil
+}
+
+func (w *checksumWriter) Write(p []byte) (int, error) {
+	w.size += int64(len(p))
+	return w.hash.Write(p)
+}
+
+func (w *checksumWriter) Sum() string {
+	return w.algorithm + ":" + hex.EncodeToString(w.hash.Sum(nil))
+}
+
+// decompressReader 按压缩类型返回解压后的数据流
+func decompressReader(compression string, r io.Reader) (io.Reader, error) {
+	switch compression {
+	case "", CompressionNone:
+		return r, nil
+	case CompressionGzip:
+		return gzip.NewReader(r)
+	case CompressionBzip2:
+		return bzip2.NewReader(r), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedCompression, compression)
+}
+
+// compressWriter 按压缩类型返回压缩写入流，平台写入检查点时只支持gzip
+func compressWriter(compression string, w io.Writer) (io.WriteCloser, string, error) {
+	switch compression {
+	case "", CompressionNone:
+		return nopWriteCloser{w}, "", nil
+	case CompressionGzip:
+		return gzip.NewWriter(w), ".gz", nil
+	}
+	return nil, "", fmt.Errorf("%w: %s", ErrUnsupportedCompression, compression)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
@@ -2,448 +2,304 @@ package checkpoint
 
 import (
 	"context"
-	"encoding/json"
+	"database/sql"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
-	"sort"
-	"strings"
 	"time"
 
+	"api/model"
+
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
-// CheckpointManager 检查点管理器
-type CheckpointManager struct {
-	logger  logx.Logger
-	baseDir string
-}
-
-// CheckpointInfo 检查点信息
-type CheckpointInfo struct {
-	ID          string            `json:"id"`
-	JobID       int64             `json:"job_id"`
-	JobName     string            `json:"job_name"`
-	Epoch       int               `json:"epoch"`
-	Step        int               `json:"step"`
-	Loss        float64           `json:"loss"`
-	Accuracy    float64           `json:"accuracy"`
-	ModelPath   string            `json:"model_path"`
-	Size        int64             `json:"size"`
-	Metadata    map[string]string `json:"metadata"`
-	CreatedAt   time.Time         `json:"created_at"`
-	Description string            `json:"description"`
-	IsAutoSaved bool              `json:"is_auto_saved"`
-}
-
-// CheckpointConfig 检查点配置
-type CheckpointConfig struct {
-	SaveInterval    time.Duration `json:"save_interval"`     // 自动保存间隔
-	MaxCheckpoints  int           `json:"max_checkpoints"`   // 最大保留数量
-	SaveOnEpochEnd  bool          `json:"save_on_epoch_end"` // 是否在epoch结束时保存
-	SaveBestOnly    bool          `json:"save_best_only"`    // 是否只保存最优模型
-	MonitorMetric   string        `json:"monitor_metric"`    // 监控的指标
-	MinDelta        float64       `json:"min_delta"`         // 最小改进阈值
-	Patience        int           `json:"patience"`          // 早停耐心值
-	RestoreFromBest bool          `json:"restore_from_best"` // 是否从最优检查点恢复
-}
+var (
+	// ErrInvalidCheckpoint 检查点路径、压缩类型或校验和无效
+	ErrInvalidCheckpoint = errors.New("检查点参数无效")
+	// ErrCheckpointUnavailable 检查点不是已保存状态，不能用于恢复
+	ErrCheckpointUnavailable = errors.New("检查点不可用")
+)
 
-// CheckpointStorage 检查点存储接口
-type CheckpointStorage interface {
-	Save(info *CheckpointInfo, modelData []byte) error
-	Load(checkpointID string) (*CheckpointInfo, []byte, error)
-	List(jobID int64) ([]*CheckpointInfo, error)
-	Delete(checkpointID string) error
-	GetBest(jobID int64, metric string) (*CheckpointInfo, error)
-	Cleanup(jobID int64, keepCount int) error
+// CheckpointManager 检查点登记簿
+// 元数据记录在vt_training_checkpoints表，文件保存在CheckpointStorage中，恢复时按记录的校验和和压缩类型校验
+type CheckpointManager struct {
+	checkpointModel model.VtTrainingCheckpointsModel
+	storage         CheckpointStorage
+	logger          logx.Logger
 }
 
-// NewCheckpointManager 创建检查点管理器
-func NewCheckpointManager(baseDir string) *CheckpointManager {
+// NewCheckpointManager 创建检查点登记簿
+func NewCheckpointManager(checkpointModel model.VtTrainingCheckpointsModel, storage CheckpointStorage) *CheckpointManager {
 	return &CheckpointManager{
-		logger:  logx.WithContext(context.Background()),
-		baseDir: baseDir,
+		checkpointModel: checkpointModel,
+		storage:         storage,
+		logger:          logx.WithContext(context.Background()),
 	}
 }
 
-// SaveCheckpoint 保存检查点
-func (cm *CheckpointManager) SaveCheckpoint(info *CheckpointInfo, config *CheckpointConfig, storage CheckpointStorage) error {
-	// 生成检查点ID
-	info.ID = cm.generateCheckpointID(info.JobID, info.Epoch, info.Step)
-	info.CreatedAt = time.Now()
-
-	// 构建模型路径
-	info.ModelPath = cm.buildModelPath(info.JobID, info.ID)
+// Storage 返回检查点文件存储
+func (cm *CheckpointManager) Storage() CheckpointStorage {
+	return cm.storage
+}
 
-	// 模拟模型数据（实际应该是训练框架保存的模型文件）
-	modelData := cm.generateMockModelData(info)
+// Register 登记训练容器已写入存储的检查点
+// 检查文件存在并补全文件大小，未提供校验和时读取文件计算sha256，storage_path规范化为存储地址
+func (cm *CheckpointManager) Register(ctx context.Context, c *model.VtTrainingCheckpoints) error {
+	key, err := cm.validate(c)
+	if err != nil {
+		return err
+	}
+	size, err := cm.storage.Stat(ctx, key)
+	if err != nil {
+		return err
+	}
+	c.StoragePath = cm.storage.URI(key)
+	c.FileSize = size
 
-	// 检查是否需要保存
-	if config.SaveBestOnly {
-		shouldSave, err := cm.shouldSaveCheckpoint(info, config, storage)
+	if c.Checksum == "" {
+		body, err := cm.storage.Open(ctx, key)
 		if err != nil {
-			return fmt.Errorf("检查是否应该保存检查点失败: %v", err)
+			return err
 		}
-		if !shouldSave {
-			cm.logger.Infof("跳过保存检查点 %s，指标未改进", info.ID)
-			return nil
+		defer body.Close()
+		sum, err := newChecksumWriter("")
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(sum, body); err != nil {
+			return fmt.Errorf("计算检查点校验和失败: %w", err)
 		}
+		c.Checksum = sum.Sum()
 	}
+	return cm.record(c)
+}
 
-	// 保存检查点
-	if err := storage.Save(info, modelData); err != nil {
-		return fmt.Errorf("保存检查点失败: %v", err)
+// Save 按compression_type压缩数据后写入存储并登记，存储路径为jobs/<作业ID>/<检查点名>
+func (cm *CheckpointManager) Save(ctx context.Context, c *model.VtTrainingCheckpoints, data io.Reader) error {
+	c.StoragePath = fmt.Sprintf("jobs/%d/%s", c.JobId, c.CheckpointName)
+	c.Checksum = ""
+	if _, err := cm.validate(c); err != nil {
+		return err
 	}
 
-	cm.logger.Infof("成功保存检查点: %s (epoch=%d, step=%d, loss=%.4f)",
-		info.ID, info.Epoch, info.Step, info.Loss)
-
-	// 清理旧检查点
-	if config.MaxCheckpoints > 0 {
-		if err := storage.Cleanup(info.JobID, config.MaxCheckpoints); err != nil {
-			cm.logger.Errorf("清理旧检查点失败: %v", err)
-		}
+	spool, err := os.CreateTemp("", "checkpoint-*")
+	if err != nil {
+		return fmt.Errorf("创建检查点临时文件失败: %w", err)
 	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
 
-	return nil
-}
-
-// RestoreCheckpoint 恢复检查点
-func (cm *CheckpointManager) RestoreCheckpoint(checkpointID string, storage CheckpointStorage) (*CheckpointInfo, error) {
-	info, modelData, err := storage.Load(checkpointID)
+	sum, err := newChecksumWriter("")
 	if err != nil {
-		return nil, fmt.Errorf("加载检查点失败: %v", err)
+		return err
 	}
-
-	// 这里应该将模型数据加载到训练框架中
-	cm.logger.Infof("模拟恢复检查点 %s，模型数据大小: %d bytes", checkpointID, len(modelData))
-
-	cm.logger.Infof("成功恢复检查点: %s (epoch=%d, step=%d)",
-		info.ID, info.Epoch, info.Step)
-
-	return info, nil
-}
-
-// GetCheckpointHistory 获取检查点历史
-func (cm *CheckpointManager) GetCheckpointHistory(jobID int64, storage CheckpointStorage) ([]*CheckpointInfo, error) {
-	checkpoints, err := storage.List(jobID)
+	w, ext, err := compressWriter(c.CompressionType, io.MultiWriter(spool, sum))
 	if err != nil {
-		return nil, fmt.Errorf("获取检查点列表失败: %v", err)
+		return fmt.Errorf("%w: %v", ErrInvalidCheckpoint, err)
 	}
-
-	// 按创建时间排序
-	sort.Slice(checkpoints, func(i, j int) bool {
-		return checkpoints[i].CreatedAt.After(checkpoints[j].CreatedAt)
-	})
-
-	return checkpoints, nil
-}
-
-// GetBestCheckpoint 获取最优检查点
-func (cm *CheckpointManager) GetBestCheckpoint(jobID int64, metric string, storage CheckpointStorage) (*CheckpointInfo, error) {
-	return storage.GetBest(jobID, metric)
-}
-
-// DeleteCheckpoint 删除检查点
-func (cm *CheckpointManager) DeleteCheckpoint(checkpointID string, storage CheckpointStorage) error {
-	if err := storage.Delete(checkpointID); err != nil {
-		return fmt.Errorf("删除检查点失败: %v", err)
+	if _, err := io.Copy(w, data); err != nil {
+		return fmt.Errorf("写入检查点数据失败: %w", err)
 	}
-
-	cm.logger.Infof("成功删除检查点: %s", checkpointID)
-	return nil
-}
-
-// shouldSaveCheckpoint 检查是否应该保存检查点
-func (cm *CheckpointManager) shouldSaveCheckpoint(info *CheckpointInfo, config *CheckpointConfig, storage CheckpointStorage) (bool, error) {
-	if config.MonitorMetric == "" {
-		return true, nil
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("压缩检查点数据失败: %w", err)
 	}
-
-	// 获取当前最优检查点
-	bestCheckpoint, err := storage.GetBest(info.JobID, config.MonitorMetric)
-	if err != nil || bestCheckpoint == nil {
-		// 没有历史检查点，保存当前的
-		return true, nil
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return err
 	}
 
-	// 比较指标
-	currentValue := cm.getMetricValue(info, config.MonitorMetric)
-	bestValue := cm.getMetricValue(bestCheckpoint, config.MonitorMetric)
-
-	// 检查是否有改进
-	var improved bool
-	switch config.MonitorMetric {
-	case "loss":
-		improved = currentValue < bestValue-config.MinDelta
-	case "accuracy":
-		improved = currentValue > bestValue+config.MinDelta
-	default:
-		improved = currentValue > bestValue+config.MinDelta
+	key, err := cleanKey(c.StoragePath + ext)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCheckpoint, err)
 	}
-
-	return improved, nil
+	if err := cm.storage.Put(ctx, key, spool, sum.size); err != nil {
+		return err
+	}
+	c.StoragePath = cm.storage.URI(key)
+	c.FileSize = sum.size
+	c.Checksum = sum.Sum()
+	return cm.record(c)
 }
 
-// getMetricValue 获取指标值
-func (cm *CheckpointManager) getMetricValue(info *CheckpointInfo, metric string) float64 {
-	switch metric {
-	case "loss":
-		return info.Loss
-	case "accuracy":
-		return info.Accuracy
-	default:
-		return 0.0
+// Restore 将检查点解压后的数据写入dst，同时校验文件大小和校验和
+// 文件缺失、校验失败或无法解压时将检查点标记为corrupted并返回ErrCorrupted，dst中已写入的数据不可使用
+func (cm *CheckpointManager) Restore(ctx context.Context, id int64, dst io.Writer) (*model.VtTrainingCheckpoints, error) {
+	c, err := cm.checkpointModel.FindOne(id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Status != "saved" {
+		return c, fmt.Errorf("%w: 检查点 %s 状态为 %s", ErrCheckpointUnavailable, c.CheckpointName, c.Status)
+	}
+	key, err := cm.storage.Key(c.StoragePath)
+	if err != nil {
+		return c, fmt.Errorf("%w: %v", ErrInvalidCheckpoint, err)
 	}
-}
 
-// generateCheckpointID 生成检查点ID
-func (cm *CheckpointManager) generateCheckpointID(jobID int64, epoch, step int) string {
-	timestamp := time.Now().Format("20060102-150405")
-	return fmt.Sprintf("checkpoint-%d-epoch%d-step%d-%s", jobID, epoch, step, timestamp)
+	if err := cm.restore(ctx, c, key, dst); err != nil {
+		if errors.Is(err, ErrCorrupted) {
+			cm.markCorrupted(c, err)
+		}
+		return c, err
+	}
+	return c, nil
 }
 
-// buildModelPath 构建模型路径
-func (cm *CheckpointManager) buildModelPath(jobID int64, checkpointID string) string {
-	return filepath.Join(cm.baseDir, fmt.Sprintf("job-%d", jobID), checkpointID, "model.pkl")
+// Verify 完整读取检查点并校验，不保留解压后的数据
+func (cm *CheckpointManager) Verify(ctx context.Context, id int64) (*model.VtTrainingCheckpoints, error) {
+	return cm.Restore(ctx, id, io.Discard)
 }
 
-// generateMockModelData 生成模拟模型数据
-func (cm *CheckpointManager) generateMockModelData(info *CheckpointInfo) []byte {
-	// 模拟模型数据结构
-	mockModel := map[string]interface{}{
-		"architecture": "ResNet50",
-		"parameters": map[string]interface{}{
-			"layers":  50,
-			"filters": []int{64, 128, 256, 512},
-		},
-		"training_info": map[string]interface{}{
-			"epoch":    info.Epoch,
-			"step":     info.Step,
-			"loss":     info.Loss,
-			"accuracy": info.Accuracy,
-		},
-		"metadata":   info.Metadata,
-		"created_at": info.CreatedAt.Format(time.RFC3339),
+// SelectResume 选择作业重新运行时恢复训练使用的检查点，没有可用的检查点时返回nil
+// 按ResumeCandidates的顺序检查存储中的文件，文件缺失或大小与记录不一致的检查点标记为corrupted后跳过，
+// storage_path不属于当前存储的检查点无法检查，由训练容器自行读取
+func (cm *CheckpointManager) SelectResume(ctx context.Context, jobId int64, config CheckpointConfig) (*model.VtTrainingCheckpoints, error) {
+	checkpoints, err := cm.checkpointModel.FindByJobId(jobId)
+	if err != nil {
+		return nil, err
 	}
 
-	data, _ := json.Marshal(mockModel)
-	return data
+	for _, c := range ResumeCandidates(checkpoints, config) {
+		key, err := cm.storage.Key(c.StoragePath)
+		if err != nil {
+			return c, nil
+		}
+		size, err := cm.storage.Stat(ctx, key)
+		switch {
+		case errors.Is(err, ErrObjectNotFound):
+			cm.markCorrupted(c, err)
+		case err != nil:
+			return nil, err
+		case c.FileSize > 0 && size != c.FileSize:
+			cm.markCorrupted(c, fmt.Errorf("文件大小不匹配，期望%d字节，实际%d字节", c.FileSize, size))
+		default:
+			return c, nil
+		}
+	}
+	return nil, nil
 }
 
-// FileSystemCheckpointStorage 文件系统检查点存储实现
-type FileSystemCheckpointStorage struct {
-	baseDir string
-	logger  logx.Logger
+// Delete 删除检查点文件并软删除记录，storage_path不属于当前存储时只删除记录
+func (cm *CheckpointManager) Delete(ctx context.Context, c *model.VtTrainingCheckpoints) error {
+	if key, err := cm.storage.Key(c.StoragePath); err != nil {
+		cm.logger.Infof("检查点文件不在当前存储中，只删除记录: ID=%d, %v", c.Id, err)
+	} else if err := cm.storage.Delete(ctx, key); err != nil {
+		return err
+	}
+	return cm.checkpointModel.Delete(c.Id)
 }
 
-// NewFileSystemCheckpointStorage 创建文件系统检查点存储
-func NewFileSystemCheckpointStorage(baseDir string) *FileSystemCheckpointStorage {
-	return &FileSystemCheckpointStorage{
-		baseDir: baseDir,
-		logger:  logx.WithContext(context.Background()),
+// markCorrupted 将校验失败的检查点标记为corrupted，之后不再用于恢复
+func (cm *CheckpointManager) markCorrupted(c *model.VtTrainingCheckpoints, cause error) {
+	if err := cm.checkpointModel.UpdateStatus(c.Id, "corrupted"); err != nil {
+		cm.logger.Errorf("标记检查点损坏失败: ID=%d, %v", c.Id, err)
 	}
+	c.Status = "corrupted"
+	cm.logger.Errorf("检查点校验失败: ID=%d, %s, %v", c.Id, c.StoragePath, cause)
 }
 
-// Save 保存检查点
-func (fs *FileSystemCheckpointStorage) Save(info *CheckpointInfo, modelData []byte) error {
-	// 创建目录
-	checkpointDir := filepath.Join(fs.baseDir, fmt.Sprintf("job-%d", info.JobID), info.ID)
-	if err := os.MkdirAll(checkpointDir, 0755); err != nil {
-		return fmt.Errorf("创建检查点目录失败: %v", err)
+func (cm *CheckpointManager) restore(ctx context.Context, c *model.VtTrainingCheckpoints, key string, dst io.Writer) error {
+	if c.CompressionType == CompressionLz4 {
+		return fmt.Errorf("%w: %s", ErrUnsupportedCompression, c.CompressionType)
 	}
-
-	// 保存模型数据
-	modelPath := filepath.Join(checkpointDir, "model.pkl")
-	if err := os.WriteFile(modelPath, modelData, 0644); err != nil {
-		return fmt.Errorf("保存模型文件失败: %v", err)
+	body, err := cm.storage.Open(ctx, key)
+	if errors.Is(err, ErrObjectNotFound) {
+		return fmt.Errorf("%w: %w", ErrCorrupted, err)
 	}
-
-	// 保存检查点信息
-	infoPath := filepath.Join(checkpointDir, "checkpoint.json")
-	infoData, err := json.MarshalIndent(info, "", "  ")
 	if err != nil {
-		return fmt.Errorf("序列化检查点信息失败: %v", err)
-	}
-
-	if err := os.WriteFile(infoPath, infoData, 0644); err != nil {
-		return fmt.Errorf("保存检查点信息失败: %v", err)
-	}
-
-	// 更新文件大小
-	if stat, err := os.Stat(modelPath); err == nil {
-		info.Size = stat.Size()
+		return err
 	}
+	defer body.Close()
 
-	fs.logger.Infof("检查点已保存到: %s", checkpointDir)
-	return nil
-}
-
-// Load 加载检查点
-func (fs *FileSystemCheckpointStorage) Load(checkpointID string) (*CheckpointInfo, []byte, error) {
-	// 查找检查点目录
-	checkpointDir, err := fs.findCheckpointDir(checkpointID)
+	sum, err := newChecksumWriter(c.Checksum)
 	if err != nil {
-		return nil, nil, err
+		return fmt.Errorf("%w: %v", ErrInvalidCheckpoint, err)
 	}
-
-	// 加载检查点信息
-	infoPath := filepath.Join(checkpointDir, "checkpoint.json")
-	infoData, err := os.ReadFile(infoPath)
+	stored := io.TeeReader(body, sum)
+	data, err := decompressReader(c.CompressionType, stored)
 	if err != nil {
-		return nil, nil, fmt.Errorf("读取检查点信息失败: %v", err)
+		return fmt.Errorf("%w: 解压失败: %v", ErrCorrupted, err)
 	}
-
-	var info CheckpointInfo
-	if err := json.Unmarshal(infoData, &info); err != nil {
-		return nil, nil, fmt.Errorf("解析检查点信息失败: %v", err)
+	if _, err := io.Copy(dst, data); err != nil {
+		return fmt.Errorf("%w: 解压失败: %v", ErrCorrupted, err)
 	}
-
-	// 加载模型数据
-	modelPath := filepath.Join(checkpointDir, "model.pkl")
-	modelData, err := os.ReadFile(modelPath)
-	if err != nil {
-		return nil, nil, fmt.Errorf("读取模型文件失败: %v", err)
+	// 压缩流结束后可能还有未读取的数据，全部读完后再比较校验和
+	if _, err := io.Copy(io.Discard, stored); err != nil {
+		return fmt.Errorf("读取检查点文件失败: %w", err)
 	}
 
-	return &info, modelData, nil
-}
-
-// List 列出检查点
-func (fs *FileSystemCheckpointStorage) List(jobID int64) ([]*CheckpointInfo, error) {
-	jobDir := filepath.Join(fs.baseDir, fmt.Sprintf("job-%d", jobID))
-
-	entries, err := os.ReadDir(jobDir)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return []*CheckpointInfo{}, nil
-		}
-		return nil, fmt.Errorf("读取作业目录失败: %v", err)
-	}
-
-	var checkpoints []*CheckpointInfo
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
-
-		infoPath := filepath.Join(jobDir, entry.Name(), "checkpoint.json")
-		infoData, err := os.ReadFile(infoPath)
-		if err != nil {
-			fs.logger.Errorf("读取检查点信息失败: %s, %v", infoPath, err)
-			continue
-		}
-
-		var info CheckpointInfo
-		if err := json.Unmarshal(infoData, &info); err != nil {
-			fs.logger.Errorf("解析检查点信息失败: %s, %v", infoPath, err)
-			continue
-		}
-
-		checkpoints = append(checkpoints, &info)
+	if c.FileSize > 0 && sum.size != c.FileSize {
+		return fmt.Errorf("%w: 文件大小不匹配，期望%d字节，实际%d字节", ErrCorrupted, c.FileSize, sum.size)
 	}
-
-	return checkpoints, nil
-}
-
-// Delete 删除检查点
-func (fs *FileSystemCheckpointStorage) Delete(checkpointID string) error {
-	checkpointDir, err := fs.findCheckpointDir(checkpointID)
-	if err != nil {
-		return err
+	if c.Checksum == "" {
+		cm.logger.Infof("检查点没有记录校验和，跳过校验: ID=%d", c.Id)
+		return nil
 	}
-
-	if err := os.RemoveAll(checkpointDir); err != nil {
-		return fmt.Errorf("删除检查点目录失败: %v", err)
+	if actual := sum.Sum(); actual != c.Checksum {
+		return fmt.Errorf("%w: 校验和不匹配，期望%s，实际%s", ErrCorrupted, c.Checksum, actual)
 	}
-
 	return nil
 }
 
-// GetBest 获取最优检查点
-func (fs *FileSystemCheckpointStorage) GetBest(jobID int64, metric string) (*CheckpointInfo, error) {
-	checkpoints, err := fs.List(jobID)
-	if err != nil {
-		return nil, err
+// validate 校验检查点参数并返回存储内的key，规范化压缩类型和校验和
+func (cm *CheckpointManager) validate(c *model.VtTrainingCheckpoints) (string, error) {
+	if c.CheckpointName == "" {
+		return "", fmt.Errorf("%w: 检查点名称不能为空", ErrInvalidCheckpoint)
 	}
-
-	if len(checkpoints) == 0 {
-		return nil, nil
+	if c.CompressionType == "" {
+		c.CompressionType = CompressionNone
 	}
-
-	var best *CheckpointInfo
-	for _, checkpoint := range checkpoints {
-		if best == nil {
-			best = checkpoint
-			continue
-		}
-
-		switch metric {
-		case "loss":
-			if checkpoint.Loss < best.Loss {
-				best = checkpoint
-			}
-		case "accuracy":
-			if checkpoint.Accuracy > best.Accuracy {
-				best = checkpoint
-			}
+	if !ValidCompression(c.CompressionType) {
+		return "", fmt.Errorf("%w: %v: %s", ErrInvalidCheckpoint, ErrUnsupportedCompression, c.CompressionType)
+	}
+	if c.Checksum != "" {
+		checksum, err := NormalizeChecksum(c.Checksum)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInvalidCheckpoint, err)
 		}
+		c.Checksum = checksum
 	}
-
-	return best, nil
-}
-
-// Cleanup 清理旧检查点
-func (fs *FileSystemCheckpointStorage) Cleanup(jobID int64, keepCount int) error {
-	checkpoints, err := fs.List(jobID)
+	key, err := cm.storage.Key(c.StoragePath)
 	if err != nil {
-		return err
-	}
-
-	if len(checkpoints) <= keepCount {
-		return nil
+		return "", fmt.Errorf("%w: %v", ErrInvalidCheckpoint, err)
 	}
+	return key, nil
+}
 
-	// 按创建时间排序，保留最新的
-	sort.Slice(checkpoints, func(i, j int) bool {
-		return checkpoints[i].CreatedAt.After(checkpoints[j].CreatedAt)
-	})
-
-	// 删除多余的检查点
-	for i := keepCount; i < len(checkpoints); i++ {
-		if err := fs.Delete(checkpoints[i].ID); err != nil {
-			fs.logger.Errorf("清理检查点失败: %s, %v", checkpoints[i].ID, err)
-		} else {
-			fs.logger.Infof("已清理检查点: %s", checkpoints[i].ID)
-		}
+// record 写入检查点记录
+// 显式指定或步数不小于当前最新检查点时标记为最新，保证重试和恢复使用步数最大的检查点
+func (cm *CheckpointManager) record(c *model.VtTrainingCheckpoints) error {
+	latest, err := cm.checkpointModel.FindLatest(c.JobId)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		c.IsLatest = true
+	case err != nil:
+		return err
+	case c.GlobalStep > latest.GlobalStep || c.GlobalStep == latest.GlobalStep && c.Step >= latest.Step:
+		c.IsLatest = true
 	}
 
-	return nil
-}
-
-// findCheckpointDir 查找检查点目录
-func (fs *FileSystemCheckpointStorage) findCheckpointDir(checkpointID string) (string, error) {
-	// 遍历所有作业目录查找检查点
-	entries, err := os.ReadDir(fs.baseDir)
+	now := time.Now()
+	c.Status = "saved"
+	c.SavedAt = &now
+	result, err := cm.checkpointModel.Insert(c)
 	if err != nil {
-		return "", fmt.Errorf("读取基础目录失败: %v", err)
+		return err
+	}
+	if c.Id, err = result.LastInsertId(); err != nil {
+		return err
 	}
 
-	for _, entry := range entries {
-		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), "job-") {
-			continue
+	if c.IsLatest {
+		if err := cm.checkpointModel.MarkLatest(c.JobId, c.Id); err != nil {
+			return err
 		}
-
-		checkpointDir := filepath.Join(fs.baseDir, entry.Name(), checkpointID)
-		if _, err := os.Stat(checkpointDir); err == nil {
-			return checkpointDir, nil
+	}
+	if c.IsBest {
+		if err := cm.checkpointModel.MarkBest(c.JobId, c.Id); err != nil {
+			return err
 		}
 	}
-
-	return "", fmt.Errorf("检查点不存在: %s", checkpointID)
-}
-
-// strings.HasPrefix 的简单实现（避免导入strings包）
-func hasPrefix(s, prefix string) bool {
-	return len(s) >= len(prefix) && s[0:len(prefix)] == prefix
+	cm.logger.Infof("已登记检查点: 作业ID=%d, %s, step=%d, %s", c.JobId, c.CheckpointName, c.GlobalStep, c.StoragePath)
+	return nil
 }
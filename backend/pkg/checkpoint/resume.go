@@ -0,0 +1,83 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"api/model"
+)
+
+// CheckpointConfig 作业training_config中checkpoint字段的检查点配置
+type CheckpointConfig struct {
+	RestoreFromBest bool    `json:"restore_from_best"` // 恢复训练时优先使用监控指标最好的检查点，否则使用最新的检查点
+	MonitorMetric   string  `json:"monitor_metric"`    // 评价检查点的指标，默认loss
+	MonitorGoal     string  `json:"monitor_goal"`      // minimize或maximize，为空时名称包含loss的指标取最小化，其他指标取最大化
+	Patience        int     `json:"patience"`          // 监控指标连续多少次评估没有改善时提前停止训练，0表示不提前停止
+	MinDelta        float64 `json:"min_delta"`         // 视为改善的最小变化量
+}
+
+// ParseCheckpointConfig 从作业的training_config中解析检查点配置，未配置的项使用默认值
+// 配置无效时返回默认配置和错误
+func ParseCheckpointConfig(trainingConfig string) (CheckpointConfig, error) {
+	var parsed struct {
+		Checkpoint *CheckpointConfig `json:"checkpoint"`
+	}
+	var config CheckpointConfig
+	var err error
+	if trainingConfig != "" && trainingConfig != "null" {
+		if err = json.Unmarshal([]byte(trainingConfig), &parsed); err != nil {
+			err = fmt.Errorf("解析检查点配置失败: %v", err)
+		} else if parsed.Checkpoint != nil {
+			config = *parsed.Checkpoint
+		}
+	}
+
+	switch config.MonitorGoal {
+	case "", RetentionGoalMinimize, RetentionGoalMaximize:
+	default:
+		err = fmt.Errorf("不支持的指标优化方向: %s", config.MonitorGoal)
+		config = CheckpointConfig{}
+	}
+	if config.Patience < 0 || config.MinDelta < 0 {
+		err = fmt.Errorf("patience和min_delta不能为负数")
+		config.Patience = 0
+		config.MinDelta = 0
+	}
+	if config.MonitorMetric == "" {
+		config.MonitorMetric = "loss"
+	}
+	if config.MonitorGoal == "" {
+		config.MonitorGoal = RetentionGoalMaximize
+		if strings.Contains(strings.ToLower(config.MonitorMetric), "loss") {
+			config.MonitorGoal = RetentionGoalMinimize
+		}
+	}
+	return config, err
+}
+
+// ResumeCandidates 按恢复优先级返回已保存的检查点，checkpoints需按步数从大到小排序
+// RestoreFromBest时有监控指标的检查点按指标从好到坏排在前面，其余检查点按步数从新到旧
+func ResumeCandidates(checkpoints []*model.VtTrainingCheckpoints, config CheckpointConfig) []*model.VtTrainingCheckpoints {
+	var saved []*model.VtTrainingCheckpoints
+	for _, c := range checkpoints {
+		if c.Status == "saved" {
+			saved = append(saved, c)
+		}
+	}
+	if !config.RestoreFromBest {
+		return saved
+	}
+
+	candidates := rankByMetric(saved, config.MonitorMetric, config.MonitorGoal)
+	ranked := make(map[int64]bool, len(candidates))
+	for _, c := range candidates {
+		ranked[c.Id] = true
+	}
+	for _, c := range saved {
+		if !ranked[c.Id] {
+			candidates = append(candidates, c)
+		}
+	}
+	return candidates
+}
@@ -0,0 +1,163 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"api/model"
+)
+
+// 保留策略的指标优化方向，与vt_checkpoint_retention_policies.best_goal一致
+const (
+	RetentionGoalMinimize = "minimize"
+	RetentionGoalMaximize = "maximize"
+)
+
+// RetentionPolicy 检查点保留规则，各规则保留的检查点取并集
+type RetentionPolicy struct {
+	KeepLast         int    // 保留步数最大的N个检查点
+	KeepBest         int    // 按BestMetric保留最好的K个检查点
+	BestMetric       string // loss、accuracy、validation_score或metrics中的指标名
+	BestGoal         string // minimize或maximize
+	KeepEveryNEpochs int    // 保留epoch为N的整数倍的检查点，同一epoch只保留步数最大的一个
+}
+
+// NewRetentionPolicy 将表中的策略转换为保留规则
+func NewRetentionPolicy(p *model.VtCheckpointRetentionPolicies) RetentionPolicy {
+	return RetentionPolicy{
+		KeepLast:         p.KeepLast,
+		KeepBest:         p.KeepBest,
+		BestMetric:       p.BestMetric,
+		BestGoal:         p.BestGoal,
+		KeepEveryNEpochs: p.KeepEveryNEpochs,
+	}
+}
+
+// Validate 校验保留规则，至少需要一条规则，否则会删除除final外的全部检查点
+func (p RetentionPolicy) Validate() error {
+	if p.KeepLast < 0 || p.KeepBest < 0 || p.KeepEveryNEpochs < 0 {
+		return fmt.Errorf("保留数量不能为负数")
+	}
+	if p.KeepLast == 0 && p.KeepBest == 0 && p.KeepEveryNEpochs == 0 {
+		return fmt.Errorf("keepLast、keepBest和keepEveryNEpochs至少需要设置一项")
+	}
+	if p.KeepBest > 0 && p.BestMetric == "" {
+		return fmt.Errorf("按指标保留时需要指定评价指标")
+	}
+	switch p.BestGoal {
+	case "", RetentionGoalMinimize, RetentionGoalMaximize:
+	default:
+		return fmt.Errorf("不支持的指标优化方向: %s", p.BestGoal)
+	}
+	return nil
+}
+
+// Select 按保留规则划分作业的检查点，pinned中的检查点和final类型的检查点始终保留
+// 保存中的检查点不参与划分，损坏的检查点不计入保留数量并始终删除，checkpoints需按步数从大到小排序
+func (p RetentionPolicy) Select(checkpoints []*model.VtTrainingCheckpoints, pinned map[int64]bool) (keep, remove []*model.VtTrainingCheckpoints) {
+	var candidates []*model.VtTrainingCheckpoints
+	kept := make(map[int64]bool)
+	for _, c := range checkpoints {
+		switch {
+		case c.Status == "saving":
+		case c.CheckpointType == "final" || pinned[c.Id]:
+			kept[c.Id] = true
+		case c.Status == "saved":
+			candidates = append(candidates, c)
+		}
+	}
+
+	for i := 0; i < p.KeepLast && i < len(candidates); i++ {
+		kept[candidates[i].Id] = true
+	}
+	if p.KeepEveryNEpochs > 0 {
+		epochs := make(map[int]bool)
+		for _, c := range candidates {
+			if c.Epoch > 0 && c.Epoch%p.KeepEveryNEpochs == 0 && !epochs[c.Epoch] {
+				epochs[c.Epoch] = true
+				kept[c.Id] = true
+			}
+		}
+	}
+	for _, c := range p.best(candidates) {
+		kept[c.Id] = true
+	}
+
+	for _, c := range checkpoints {
+		switch {
+		case kept[c.Id]:
+			keep = append(keep, c)
+		case c.Status == "saved" || c.Status == "corrupted":
+			remove = append(remove, c)
+		}
+	}
+	return keep, remove
+}
+
+// best 返回指标最好的KeepBest个检查点，没有该指标的检查点不参与排序
+func (p RetentionPolicy) best(candidates []*model.VtTrainingCheckpoints) []*model.VtTrainingCheckpoints {
+	if p.KeepBest <= 0 {
+		return nil
+	}
+	ranked := rankByMetric(candidates, p.BestMetric, p.BestGoal)
+	if len(ranked) > p.KeepBest {
+		ranked = ranked[:p.KeepBest]
+	}
+	return ranked
+}
+
+// rankByMetric 按指标从好到坏排序，没有该指标的检查点不参与排序
+// 稳定排序，指标相同时保持candidates中的顺序，即步数更大的检查点在前
+func rankByMetric(candidates []*model.VtTrainingCheckpoints, metric, goal string) []*model.VtTrainingCheckpoints {
+	type scored struct {
+		checkpoint *model.VtTrainingCheckpoints
+		value      float64
+	}
+	var ranked []scored
+	for _, c := range candidates {
+		if value, ok := CheckpointMetric(c, metric); ok {
+			ranked = append(ranked, scored{checkpoint: c, value: value})
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if goal == RetentionGoalMaximize {
+			return ranked[i].value > ranked[j].value
+		}
+		return ranked[i].value < ranked[j].value
+	})
+
+	checkpoints := make([]*model.VtTrainingCheckpoints, 0, len(ranked))
+	for _, r := range ranked {
+		checkpoints = append(checkpoints, r.checkpoint)
+	}
+	return checkpoints
+}
+
+// CheckpointMetric 读取检查点的指标值，loss、accuracy和validation_score读取对应列，其他指标从metrics中读取
+func CheckpointMetric(c *model.VtTrainingCheckpoints, metric string) (float64, bool) {
+	var raw string
+	switch metric {
+	case "loss":
+		raw = c.LossValue
+	case "accuracy":
+		raw = c.Accuracy
+	case "validation_score":
+		raw = c.ValidationScore
+	}
+	if raw != "" {
+		value, err := strconv.ParseFloat(raw, 64)
+		return value, err == nil
+	}
+
+	if c.Metrics == "" {
+		return 0, false
+	}
+	var metrics map[string]interface{}
+	if err := json.Unmarshal([]byte(c.Metrics), &metrics); err != nil {
+		return 0, false
+	}
+	value, ok := metrics[metric].(float64)
+	return value, ok
+}
@@ -0,0 +1,263 @@
+package checkpoint
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3Algorithm        = "AWS4-HMAC-SHA256"
+	s3UnsignedPayload  = "UNSIGNED-PAYLOAD"
+	s3EmptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	s3SignedHeaders    = "host;x-amz-content-sha256;x-amz-date"
+	s3ErrorBodyLimit   = 1024
+
+	s3MaxPresignExpires = 7 * 24 * time.Hour
+)
+
+// S3Config S3兼容对象存储配置
+type S3Config struct {
+	Endpoint  string // 服务地址，如 http://minio.volctrain:9000
+	Region    string
+	Bucket    string
+	Prefix    string // 检查点在桶内的路径前缀
+	AccessKey string
+	SecretKey string
+	Timeout   time.Duration
+}
+
+// S3CheckpointStorage S3兼容对象存储的检查点存储，使用路径风格访问和SigV4签名，兼容MinIO
+type S3CheckpointStorage struct {
+	config   S3Config
+	endpoint *url.URL
+	client   *http.Client
+}
+
+// NewS3CheckpointStorage 创建S3兼容对象存储的检查点存储
+func NewS3CheckpointStorage(config S3Config) (*S3CheckpointStorage, error) {
+	if config.Endpoint == "" || config.Bucket == "" {
+		return nil, fmt.Errorf("对象存储地址和桶名不能为空")
+	}
+	endpoint, err := url.Parse(strings.TrimRight(config.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("无效的对象存储地址: %s", config.Endpoint)
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Minute
+	}
+	config.Prefix = strings.Trim(config.Prefix, "/")
+	return &S3CheckpointStorage{
+		config:   config,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+func (s *S3CheckpointStorage) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	if size < 0 {
+		return fmt.Errorf("上传到对象存储需要指定数据长度")
+	}
+	resp, err := s.do(ctx, http.MethodPut, key, r, size)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3CheckpointStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *S3CheckpointStorage) Stat(ctx context.Context, key string) (int64, error) {
+	resp, err := s.do(ctx, http.MethodHead, key, nil, 0)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.ContentLength, nil
+}
+
+func (s *S3CheckpointStorage) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, 0)
+	if err == ErrObjectNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3CheckpointStorage) URI(key string) string {
+	return "s3://" + s.config.Bucket + "/" + s.objectName(key)
+}
+
+// Key 支持本桶的s3://地址和相对于前缀的路径
+func (s *S3CheckpointStorage) Key(uri string) (string, error) {
+	if strings.Contains(uri, "://") {
+		bucketPrefix := "s3://" + s.config.Bucket + "/"
+		if !strings.HasPrefix(uri, bucketPrefix) {
+			return "", fmt.Errorf("检查点路径不在存储桶 %s 中: %s", s.config.Bucket, uri)
+		}
+		uri = strings.TrimPrefix(uri, bucketPrefix)
+		if s.config.Prefix != "" {
+			if !strings.HasPrefix(uri, s.config.Prefix+"/") {
+				return "", fmt.Errorf("检查点路径不在前缀 %s 下: %s", s.config.Prefix, uri)
+			}
+			uri = strings.TrimPrefix(uri, s.config.Prefix+"/")
+		}
+	}
+	return cleanKey(uri)
+}
+
+func (s *S3CheckpointStorage) objectName(key string) string {
+	if s.config.Prefix == "" {
+		return key
+	}
+	return path.Join(s.config.Prefix, key)
+}
+
+// do 发送签名后的对象请求，404返回ErrObjectNotFound，其他非2xx响应返回错误
+func (s *S3CheckpointStorage) do(ctx context.Context, method, key string, body io.Reader, size int64) (*http.Response, error) {
+	u := s.objectURL(key)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	payloadHash := s3EmptyPayloadHash
+	if method == http.MethodPut {
+		req.ContentLength = size
+		payloadHash = s3UnsignedPayload
+	}
+	s.sign(req, payloadHash, time.Now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("访问对象存储失败: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrObjectNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, s3ErrorBodyLimit))
+		resp.Body.Close()
+		return nil, fmt.Errorf("对象存储返回错误: %s %s: %d %s", method, key, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return resp, nil
+}
+
+// sign 按AWS Signature Version 4为请求添加签名头
+func (s *S3CheckpointStorage) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		"host:" + req.URL.Host + "\n" + "x-amz-content-sha256:" + payloadHash + "\n" + "x-amz-date:" + amzDate + "\n",
+		s3SignedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := s.scope(now)
+	signature := s.signature(now, canonicalRequest)
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.config.AccessKey, scope, s3SignedHeaders, signature))
+}
+
+// PresignGet 生成有效期为expires的对象下载地址，训练容器无需访问凭证即可下载检查点
+// SigV4查询参数签名的有效期最长为7天
+func (s *S3CheckpointStorage) PresignGet(key string, expires time.Duration) string {
+	if expires <= 0 || expires > s3MaxPresignExpires {
+		expires = s3MaxPresignExpires
+	}
+	now := time.Now().UTC()
+	u := s.objectURL(key)
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", s.config.AccessKey+"/"+s.scope(now))
+	query.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+	canonicalQuery := strings.ReplaceAll(query.Encode(), "+", "%20")
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		canonicalQuery,
+		"host:" + u.Host + "\n",
+		"host",
+		s3UnsignedPayload,
+	}, "\n")
+	u.RawQuery = canonicalQuery + "&X-Amz-Signature=" + s.signature(now, canonicalRequest)
+	return u.String()
+}
+
+// objectURL 返回路径风格的对象地址
+func (s *S3CheckpointStorage) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	u.Path = strings.TrimRight(u.Path, "/") + "/" + s.config.Bucket + "/" + s.objectName(key)
+	u.RawPath = s3EscapePath(u.Path)
+	return &u
+}
+
+// scope 返回签名的凭证范围
+func (s *S3CheckpointStorage) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.config.Region + "/s3/aws4_request"
+}
+
+// signature 计算规范请求的SigV4签名
+func (s *S3CheckpointStorage) signature(now time.Time, canonicalRequest string) string {
+	hashed := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := s3Algorithm + "\n" + now.Format("20060102T150405Z") + "\n" + s.scope(now) + "\n" + hex.EncodeToString(hashed[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// s3EscapePath 按SigV4规则编码路径，除/和非保留字符外的字节全部百分号编码
+func s3EscapePath(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if c == '/' || c == '-' || c == '_' || c == '.' || c == '~' ||
+			'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
@@ -0,0 +1,139 @@
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrObjectNotFound 存储中不存在指定的检查点文件
+var ErrObjectNotFound = errors.New("检查点文件不存在")
+
+// CheckpointStorage 检查点文件存储接口
+// key为存储内的相对路径，storage_path记录URI(key)返回的训练容器可访问的地址
+type CheckpointStorage interface {
+	// Put 写入检查点文件，size为数据长度
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+	// Open 读取检查点文件，不存在时返回ErrObjectNotFound
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Stat 返回检查点文件大小，不存在时返回ErrObjectNotFound
+	Stat(ctx context.Context, key string) (int64, error)
+	// Delete 删除检查点文件，不存在时忽略
+	Delete(ctx context.Context, key string) error
+	// URI 返回记录到storage_path的地址
+	URI(key string) string
+	// Key 将storage_path或相对路径解析为存储内的key，不属于该存储的地址返回错误
+	Key(uri string) (string, error)
+}
+
+// PresignedStorage 可以生成临时下载地址的检查点存储，训练容器通过该地址下载恢复用的检查点
+type PresignedStorage interface {
+	// PresignGet 返回有效期为expires、无需访问凭证的下载地址
+	PresignGet(key string, expires time.Duration) string
+}
+
+// cleanKey 规范化相对路径，拒绝跳出存储根目录的路径
+func cleanKey(key string) (string, error) {
+	trimmed := strings.Trim(strings.ReplaceAll(key, "\\", "/"), "/")
+	for _, part := range strings.Split(trimmed, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("无效的检查点路径: %s", key)
+		}
+	}
+	cleaned := path.Clean(trimmed)
+	if cleaned == "." {
+		return "", fmt.Errorf("无效的检查点路径: %s", key)
+	}
+	return cleaned, nil
+}
+
+// FileSystemCheckpointStorage 文件系统检查点存储，训练容器通过共享存储卷访问同一目录
+type FileSystemCheckpointStorage struct {
+	baseDir string
+}
+
+// NewFileSystemCheckpointStorage 创建文件系统检查点存储
+func NewFileSystemCheckpointStorage(baseDir string) *FileSystemCheckpointStorage {
+	return &FileSystemCheckpointStorage{baseDir: filepath.Clean(baseDir)}
+}
+
+// Put 先写入临时文件再重命名，避免读到写了一半的检查点
+func (fs *FileSystemCheckpointStorage) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	target := fs.URI(key)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("创建检查点目录失败: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("创建检查点文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("写入检查点文件失败: %w", err)
+	}
+	if size >= 0 && written != size {
+		return fmt.Errorf("写入检查点文件失败: 期望%d字节，实际%d字节", size, written)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("写入检查点文件失败: %w", err)
+	}
+	return nil
+}
+
+func (fs *FileSystemCheckpointStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	f, err := os.Open(fs.URI(key))
+	if os.IsNotExist(err) {
+		return nil, ErrObjectNotFound
+	}
+	return f, err
+}
+
+func (fs *FileSystemCheckpointStorage) Stat(ctx context.Context, key string) (int64, error) {
+	info, err := os.Stat(fs.URI(key))
+	if os.IsNotExist(err) {
+		return 0, ErrObjectNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	if info.IsDir() {
+		return 0, fmt.Errorf("检查点路径是目录，请登记目录下的检查点文件: %s", key)
+	}
+	return info.Size(), nil
+}
+
+func (fs *FileSystemCheckpointStorage) Delete(ctx context.Context, key string) error {
+	err := os.Remove(fs.URI(key))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除检查点文件失败: %w", err)
+	}
+	return nil
+}
+
+func (fs *FileSystemCheckpointStorage) URI(key string) string {
+	return filepath.Join(fs.baseDir, filepath.FromSlash(key))
+}
+
+// Key 绝对路径必须位于存储根目录下，相对路径视为相对于存储根目录
+func (fs *FileSystemCheckpointStorage) Key(uri string) (string, error) {
+	uri = strings.TrimPrefix(uri, "file://")
+	if filepath.IsAbs(uri) {
+		rel, err := filepath.Rel(fs.baseDir, filepath.Clean(uri))
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			return "", fmt.Errorf("检查点路径不在存储目录 %s 下: %s", fs.baseDir, uri)
+		}
+		uri = filepath.ToSlash(rel)
+	}
+	return cleanKey(uri)
+}
@@ -67,6 +67,14 @@ func NewMySQLManager(c config.MySQLConfig) (*MySQLManager, error) {
 	}, nil
 }
 
+// NewMySQLManagerWithDB 使用已有的数据库连接创建MySQL管理器
+func NewMySQLManagerWithDB(db *sql.DB, c config.MySQLConfig) *MySQLManager {
+	return &MySQLManager{
+		db:     db,
+		config: c,
+	}
+}
+
 // GetDB 获取数据库连接
 func (m *MySQLManager) GetDB() *sql.DB {
 	return m.db
@@ -23,18 +23,20 @@ func (e *BizError) Error() string {
 // GetHTTPStatus 获取对应的HTTP状态码
 func (e *BizError) GetHTTPStatus() int {
 	switch e.Code {
-	case ErrCodeBadRequest, ErrCodeValidation, ErrCodeInvalidParam:
+	case ErrCodeBadRequest, ErrCodeValidation, ErrCodeInvalidParam, ErrCodeTemplateInvalid, ErrCodeSweepInvalid, ErrCodePipelineInvalid, ErrCodeTriggerInvalid, ErrCodeCheckpointInvalid, ErrCodeRetentionInvalid:
 		return http.StatusBadRequest
 	case ErrCodeUnauthorized, ErrCodeTokenInvalid, ErrCodeTokenExpired:
 		return http.StatusUnauthorized
 	case ErrCodeForbidden, ErrCodePermissionDenied:
 		return http.StatusForbidden
-	case ErrCodeNotFound, ErrCodeUserNotFound:
+	case ErrCodeNotFound, ErrCodeUserNotFound, ErrCodeJobNotFound, ErrCodeTemplateNotFound, ErrCodeSweepNotFound, ErrCodeRelationNotFound, ErrCodeTriggerNotFound, ErrCodeInstanceNotFound, ErrCodeLogArchiveNotFound, ErrCodeTensorboardNotFound, ErrCodeCheckpointNotFound, ErrCodeRetentionNotFound, ErrCodeGpuClusterNotFound, ErrCodeGpuAllocationNotFound, ErrCodeGpuNodeNotFound:
 		return http.StatusNotFound
-	case ErrCodeConflict, ErrCodeDuplicateData:
+	case ErrCodeConflict, ErrCodeDuplicateData, ErrCodeJobInvalidTransition, ErrCodeJobStatusChanged, ErrCodeCheckpointCorrupted, ErrCodeGpuDeviceUnavailable, ErrCodeGpuAllocationNotActive:
 		return http.StatusConflict
 	case ErrCodeTooManyRequests:
 		return http.StatusTooManyRequests
+	case ErrCodeServiceUnavailable:
+		return http.StatusServiceUnavailable
 	default:
 		return http.StatusInternalServerError
 	}
@@ -85,6 +87,35 @@ const (
 	ErrCodeResourceBusy    = 5003
 	ErrCodeQuotaExceeded   = 5004
 
+	// 训练作业错误码 (5100-5199)
+	ErrCodeJobNotFound          = 5101
+	ErrCodeJobInvalidTransition = 5102
+	ErrCodeJobStatusChanged     = 5103
+	ErrCodeJobControlFailed     = 5104
+	ErrCodeTemplateNotFound     = 5105
+	ErrCodeTemplateInvalid      = 5106
+	ErrCodeSweepNotFound        = 5107
+	ErrCodeSweepInvalid         = 5108
+	ErrCodeRelationNotFound     = 5109
+	ErrCodePipelineInvalid      = 5110
+	ErrCodeTriggerNotFound      = 5111
+	ErrCodeTriggerInvalid       = 5112
+	ErrCodeInstanceNotFound     = 5113
+	ErrCodeLogArchiveNotFound   = 5114
+	ErrCodeTensorboardNotFound  = 5115
+	ErrCodeCheckpointNotFound   = 5116
+	ErrCodeCheckpointInvalid    = 5117
+	ErrCodeCheckpointCorrupted  = 5118
+	ErrCodeRetentionNotFound    = 5119
+	ErrCodeRetentionInvalid     = 5120
+
+	// GPU资源错误码 (5200-5299)
+	ErrCodeGpuClusterNotFound     = 5201
+	ErrCodeGpuDeviceUnavailable   = 5202
+	ErrCodeGpuAllocationNotFound  = 5203
+	ErrCodeGpuAllocationNotActive = 5204
+	ErrCodeGpuNodeNotFound        = 5205
+
 	// 外部服务错误码 (6000-6099)
 	ErrCodeExternalService = 6001
 	ErrCodeNetworkError    = 6002
@@ -153,6 +184,29 @@ var (
 	ErrResourceBusy    = NewBizError(ErrCodeResourceBusy, "资源忙碌", ErrorTypeBusiness)
 	ErrQuotaExceeded   = NewBizError(ErrCodeQuotaExceeded, "配额已超限", ErrorTypeBusiness)
 
+	// 训练作业错误
+	ErrJobNotFound         = NewBizError(ErrCodeJobNotFound, "训练作业不存在", ErrorTypeBusiness)
+	ErrJobStatusChanged    = NewBizError(ErrCodeJobStatusChanged, "训练作业状态已被并发修改，请刷新后重试", ErrorTypeBusiness)
+	ErrTemplateNotFound    = NewBizError(ErrCodeTemplateNotFound, "训练作业模板不存在", ErrorTypeBusiness)
+	ErrSweepNotFound       = NewBizError(ErrCodeSweepNotFound, "超参数搜索不存在", ErrorTypeBusiness)
+	ErrRelationNotFound    = NewBizError(ErrCodeRelationNotFound, "作业关联关系不存在", ErrorTypeBusiness)
+	ErrTriggerNotFound     = NewBizError(ErrCodeTriggerNotFound, "定时触发器不存在", ErrorTypeBusiness)
+	ErrInstanceNotFound    = NewBizError(ErrCodeInstanceNotFound, "训练作业实例不存在", ErrorTypeBusiness)
+	ErrLogArchiveNotFound  = NewBizError(ErrCodeLogArchiveNotFound, "训练作业没有已归档的日志", ErrorTypeBusiness)
+	ErrTensorboardNotFound = NewBizError(ErrCodeTensorboardNotFound, "训练作业未开启TensorBoard或TensorBoard已回收", ErrorTypeBusiness)
+	ErrTensorboardStarting = NewBizError(ErrCodeServiceUnavailable, "TensorBoard正在启动，请稍后重试", ErrorTypeBusiness)
+	ErrCheckpointNotFound  = NewBizError(ErrCodeCheckpointNotFound, "检查点不存在", ErrorTypeBusiness)
+	ErrCheckpointExists    = NewBizError(ErrCodeDuplicateData, "作业下已存在同名检查点", ErrorTypeBusiness)
+	ErrCheckpointCorrupted = NewBizError(ErrCodeCheckpointCorrupted, "检查点文件校验失败，已标记为损坏", ErrorTypeBusiness)
+	ErrRetentionNotFound   = NewBizError(ErrCodeRetentionNotFound, "检查点保留策略不存在", ErrorTypeBusiness)
+	ErrRetentionExists     = NewBizError(ErrCodeDuplicateData, "该作用范围已存在检查点保留策略", ErrorTypeBusiness)
+
+	// GPU资源错误
+	ErrGpuClusterNotFound     = NewBizError(ErrCodeGpuClusterNotFound, "GPU集群不存在", ErrorTypeBusiness)
+	ErrGpuAllocationNotFound  = NewBizError(ErrCodeGpuAllocationNotFound, "GPU设备分配记录不存在", ErrorTypeBusiness)
+	ErrGpuAllocationNotActive = NewBizError(ErrCodeGpuAllocationNotActive, "GPU设备分配已释放或已过期", ErrorTypeBusiness)
+	ErrGpuNodeNotFound        = NewBizError(ErrCodeGpuNodeNotFound, "GPU节点不存在", ErrorTypeBusiness)
+
 	// 外部服务错误
 	ErrExternalService = NewBizError(ErrCodeExternalService, "外部服务错误", ErrorTypeExternal)
 	ErrNetworkError    = NewBizError(ErrCodeNetworkError, "网络错误", ErrorTypeExternal)
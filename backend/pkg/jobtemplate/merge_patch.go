@@ -0,0 +1,88 @@
+package jobtemplate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// MergePatch 按RFC 7386将JSON合并补丁应用到target上，补丁中为null的字段被删除，对象逐层合并，其他值直接替换
+// 作业规格中以JSON字符串保存的字段(如hyperparameters)可以直接用对象修改：
+// 目标值为空字符串或JSON对象字符串、补丁值为对象时，补丁合并到解析后的对象中并重新编码为字符串
+func MergePatch(target, patch []byte) ([]byte, error) {
+	var doc interface{}
+	if len(bytes.TrimSpace(target)) > 0 {
+		if err := decodeJSON(target, &doc); err != nil {
+			return nil, fmt.Errorf("合并目标不是有效的JSON: %v", err)
+		}
+	}
+	var p interface{}
+	if err := decodeJSON(patch, &p); err != nil {
+		return nil, fmt.Errorf("合并补丁不是有效的JSON: %v", err)
+	}
+
+	merged, err := mergePatch(doc, p)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(merged)
+}
+
+// mergePatch 递归合并补丁
+func mergePatch(target, patch interface{}) (interface{}, error) {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch, nil
+	}
+
+	if s, ok := target.(string); ok {
+		if embedded, ok := embeddedObject(s); ok {
+			merged, err := mergePatch(embedded, patchObject)
+			if err != nil {
+				return nil, err
+			}
+			data, err := json.Marshal(merged)
+			if err != nil {
+				return nil, err
+			}
+			return string(data), nil
+		}
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{}, len(patchObject))
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		merged, err := mergePatch(targetObject[key], value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		targetObject[key] = merged
+	}
+	return targetObject, nil
+}
+
+// embeddedObject 解析以字符串保存的JSON对象，空字符串视为空对象
+func embeddedObject(s string) (map[string]interface{}, bool) {
+	if strings.TrimSpace(s) == "" {
+		return map[string]interface{}{}, true
+	}
+	var object map[string]interface{}
+	if err := decodeJSON([]byte(s), &object); err != nil || object == nil {
+		return nil, false
+	}
+	return object, true
+}
+
+// decodeJSON 解码JSON并保留数字原样，避免大整数丢失精度
+func decodeJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
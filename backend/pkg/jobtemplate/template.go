@@ -0,0 +1,235 @@
+package jobtemplate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// 模板参数类型
+const (
+	ParamTypeString = "string"
+	ParamTypeInt    = "int"
+	ParamTypeFloat  = "float"
+	ParamTypeBool   = "bool"
+)
+
+var (
+	// placeholderPattern 匹配模板中的${param}占位符
+	placeholderPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+	// paramNamePattern 合法的参数名
+	paramNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// Parameter 模板参数定义
+type Parameter struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Default     string `json:"default,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Placeholders 返回模板中引用的参数名，按名称排序去重
+func Placeholders(spec string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, match := range placeholderPattern.FindAllStringSubmatch(spec, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			names = append(names, match[1])
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate 校验模板内容和参数定义
+// 模板必须是JSON对象，引用的参数都需要声明，参数默认值必须符合声明的类型
+func Validate(spec string, params []Parameter) error {
+	var object map[string]interface{}
+	if err := json.Unmarshal([]byte(spec), &object); err != nil {
+		return fmt.Errorf("模板内容必须是JSON对象: %v", err)
+	}
+
+	declared := make(map[string]bool, len(params))
+	for _, param := range params {
+		if !paramNamePattern.MatchString(param.Name) {
+			return fmt.Errorf("参数名 '%s' 不合法，只能包含字母、数字和下划线且不能以数字开头", param.Name)
+		}
+		if declared[param.Name] {
+			return fmt.Errorf("参数 '%s' 重复声明", param.Name)
+		}
+		declared[param.Name] = true
+
+		switch param.Type {
+		case "", ParamTypeString, ParamTypeInt, ParamTypeFloat, ParamTypeBool:
+		default:
+			return fmt.Errorf("参数 '%s' 的类型 '%s' 不支持", param.Name, param.Type)
+		}
+		if _, err := convert(param, param.Default); err != nil {
+			return fmt.Errorf("参数 '%s' 的默认值无效: %v", param.Name, err)
+		}
+	}
+
+	for _, name := range Placeholders(spec) {
+		if !declared[name] {
+			return fmt.Errorf("模板引用了未声明的参数 '%s'", name)
+		}
+	}
+	return nil
+}
+
+// Render 使用参数值渲染模板，返回渲染后的JSON
+// 字符串值恰好是一个占位符时替换为参数的类型化值，否则按文本替换；
+// 未传入的参数使用默认值，必填参数缺失或传入未声明的参数时返回错误
+func Render(spec string, params []Parameter, values map[string]interface{}) ([]byte, error) {
+	resolved, err := resolve(params, values)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(spec)))
+	decoder.UseNumber()
+	var object map[string]interface{}
+	if err := decoder.Decode(&object); err != nil {
+		return nil, fmt.Errorf("模板内容必须是JSON对象: %v", err)
+	}
+
+	return json.Marshal(substitute(object, resolved))
+}
+
+// resolve 计算每个参数的最终值
+func resolve(params []Parameter, values map[string]interface{}) (map[string]interface{}, error) {
+	declared := make(map[string]Parameter, len(params))
+	for _, param := range params {
+		declared[param.Name] = param
+	}
+	for name := range values {
+		if _, ok := declared[name]; !ok {
+			return nil, fmt.Errorf("模板未声明参数 '%s'", name)
+		}
+	}
+
+	resolved := make(map[string]interface{}, len(params))
+	for _, param := range params {
+		raw, ok := values[param.Name]
+		switch {
+		case ok:
+		case param.Default != "":
+			raw = param.Default
+		case param.Required:
+			return nil, fmt.Errorf("缺少必填参数 '%s'", param.Name)
+		default:
+			raw = ""
+		}
+
+		value, err := convert(param, raw)
+		if err != nil {
+			return nil, fmt.Errorf("参数 '%s' 的值无效: %v", param.Name, err)
+		}
+		resolved[param.Name] = value
+	}
+	return resolved, nil
+}
+
+// convert 将参数值转换为声明的类型，空字符串转换为该类型的零值
+func convert(param Parameter, raw interface{}) (interface{}, error) {
+	if raw == nil {
+		raw = ""
+	}
+
+	switch param.Type {
+	case "", ParamTypeString:
+		switch v := raw.(type) {
+		case string:
+			return v, nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case json.Number:
+			return v.String(), nil
+		}
+	case ParamTypeInt:
+		switch v := raw.(type) {
+		case string:
+			if v == "" {
+				return int64(0), nil
+			}
+			return strconv.ParseInt(v, 10, 64)
+		case float64:
+			if v != math.Trunc(v) {
+				return nil, fmt.Errorf("%v 不是整数", v)
+			}
+			return int64(v), nil
+		case json.Number:
+			return v.Int64()
+		}
+	case ParamTypeFloat:
+		switch v := raw.(type) {
+		case string:
+			if v == "" {
+				return float64(0), nil
+			}
+			return strconv.ParseFloat(v, 64)
+		case float64:
+			return v, nil
+		case json.Number:
+			return v.Float64()
+		}
+	case ParamTypeBool:
+		switch v := raw.(type) {
+		case string:
+			if v == "" {
+				return false, nil
+			}
+			return strconv.ParseBool(v)
+		case bool:
+			return v, nil
+		}
+	default:
+		return nil, fmt.Errorf("不支持的参数类型 '%s'", param.Type)
+	}
+	return nil, fmt.Errorf("%v 不是 %s 类型", raw, param.Type)
+}
+
+// substitute 递归替换JSON值中的占位符
+func substitute(value interface{}, resolved map[string]interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = substitute(item, resolved)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = substitute(item, resolved)
+		}
+	case string:
+		// 整个字符串就是一个占位符时保留参数类型，如 "gpuCount": "${gpus}"
+		if match := placeholderPattern.FindStringSubmatch(v); match != nil && match[0] == v {
+			return resolved[match[1]]
+		}
+		return placeholderPattern.ReplaceAllStringFunc(v, func(placeholder string) string {
+			name := placeholderPattern.FindStringSubmatch(placeholder)[1]
+			return formatValue(resolved[name])
+		})
+	}
+	return value
+}
+
+// formatValue 将参数值格式化为文本
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,254 @@
+package logstream
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// chunkSuffix 归档分片文件后缀
+const chunkSuffix = ".log.gz"
+
+// correlationPattern 匹配JSON字段或key=value形式的关联ID
+var correlationPattern = regexp.MustCompile(`(?i)"?\b(correlation_?id|trace_?id|request_?id)"?\s*[:=]\s*"?([\w.:/-]+)`)
+
+// CorrelationID 提取日志行中的关联ID（correlation_id、trace_id或request_id），没有时返回空字符串
+func CorrelationID(content string) string {
+	if match := correlationPattern.FindStringSubmatch(content); match != nil {
+		return match[2]
+	}
+	return ""
+}
+
+// LogFormat 判断日志行格式，JSON对象返回json，其余返回text
+func LogFormat(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if strings.HasPrefix(trimmed, "{") && json.Valid([]byte(trimmed)) {
+		return "json"
+	}
+	return "text"
+}
+
+// Chunk 一个已归档的日志分片，First和Last为分片内第一行和最后一行的日志时间
+type Chunk struct {
+	Pod   string
+	Path  string // 相对归档根目录的路径
+	First time.Time
+	Last  time.Time
+}
+
+// Archive 按作业和实例保存gzip压缩的日志分片
+// 目录结构为 <root>/<jobId>/<pod>/<first>-<last>.log.gz，文件名中的时间为纳秒时间戳，
+// 分片内容为带RFC3339Nano时间戳的原始日志行，与kubelet返回的格式一致
+type Archive struct {
+	root string
+}
+
+// NewArchive 创建日志归档
+func NewArchive(root string) *Archive {
+	return &Archive{root: root}
+}
+
+func (a *Archive) jobDir(jobId int64) string {
+	return filepath.Join(a.root, strconv.FormatInt(jobId, 10))
+}
+
+// Chunks 列出作业的全部分片，按实例名分组，每个实例内按时间排序
+func (a *Archive) Chunks(jobId int64) (map[string][]Chunk, error) {
+	podDirs, err := os.ReadDir(a.jobDir(jobId))
+	if os.IsNotExist(err) {
+		return map[string][]Chunk{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make(map[string][]Chunk, len(podDirs))
+	for _, podDir := range podDirs {
+		if !podDir.IsDir() {
+			continue
+		}
+		pod := podDir.Name()
+		files, err := os.ReadDir(filepath.Join(a.jobDir(jobId), pod))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			first, last, ok := parseChunkName(file.Name())
+			if !ok {
+				continue
+			}
+			chunks[pod] = append(chunks[pod], Chunk{
+				Pod:   pod,
+				Path:  filepath.Join(strconv.FormatInt(jobId, 10), pod, file.Name()),
+				First: first,
+				Last:  last,
+			})
+		}
+		sort.Slice(chunks[pod], func(i, j int) bool { return chunks[pod][i].First.Before(chunks[pod][j].First) })
+	}
+	return chunks, nil
+}
+
+// Cursor 根据已归档的分片计算各实例的续传游标
+func (a *Archive) Cursor(jobId int64) (Cursor, error) {
+	chunks, err := a.Chunks(jobId)
+	if err != nil {
+		return nil, err
+	}
+	cursor := Cursor{}
+	for pod, podChunks := range chunks {
+		cursor[pod] = podChunks[len(podChunks)-1].Last
+	}
+	return cursor, nil
+}
+
+// WriteChunk 将一个实例的日志行写入新的分片，先写临时文件再重命名，读取方不会看到写了一半的分片
+func (a *Archive) WriteChunk(jobId int64, pod string, lines []*Line) (Chunk, error) {
+	if len(lines) == 0 {
+		return Chunk{}, fmt.Errorf("日志分片不能为空")
+	}
+	if pod == "" || pod == "." || pod == ".." || strings.ContainsAny(pod, `/\`) {
+		return Chunk{}, fmt.Errorf("实例名 %q 无效", pod)
+	}
+
+	dir := filepath.Join(a.jobDir(jobId), pod)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return Chunk{}, err
+	}
+	tmp, err := os.CreateTemp(dir, ".chunk-*")
+	if err != nil {
+		return Chunk{}, err
+	}
+	defer os.Remove(tmp.Name())
+
+	gz := gzip.NewWriter(tmp)
+	writer := bufio.NewWriter(gz)
+	for _, line := range lines {
+		writer.WriteString(line.Time.UTC().Format(time.RFC3339Nano))
+		writer.WriteByte(' ')
+		writer.WriteString(line.Content)
+		writer.WriteByte('\n')
+	}
+	err = writer.Flush()
+	if err == nil {
+		err = gz.Close()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return Chunk{}, err
+	}
+
+	chunk := Chunk{Pod: pod, First: lines[0].Time, Last: lines[len(lines)-1].Time}
+	name := fmt.Sprintf("%d-%d%s", chunk.First.UnixNano(), chunk.Last.UnixNano(), chunkSuffix)
+	chunk.Path = filepath.Join(strconv.FormatInt(jobId, 10), pod, name)
+	if err := os.Rename(tmp.Name(), filepath.Join(a.root, chunk.Path)); err != nil {
+		return Chunk{}, err
+	}
+	return chunk, nil
+}
+
+// RemoveChunk 删除分片，用于建立索引失败时回滚，下次归档会重新读取这部分日志
+func (a *Archive) RemoveChunk(chunk Chunk) error {
+	return os.Remove(filepath.Join(a.root, chunk.Path))
+}
+
+// WriteTarball 将作业的归档日志打包为tar.gz写入w，每个实例一个文件：<prefix>/<pod>.log
+func (a *Archive) WriteTarball(w io.Writer, jobId int64, prefix string) error {
+	chunks, err := a.Chunks(jobId)
+	if err != nil {
+		return err
+	}
+	pods := make([]string, 0, len(chunks))
+	for pod := range chunks {
+		pods = append(pods, pod)
+	}
+	sort.Strings(pods)
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, pod := range pods {
+		if err := a.writeTarEntry(tw, path.Join(prefix, pod+".log"), chunks[pod]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// writeTarEntry 写入一个实例的日志，tar头需要文件长度，先解压一遍统计长度再写入内容
+func (a *Archive) writeTarEntry(tw *tar.Writer, name string, chunks []Chunk) error {
+	var size int64
+	var modTime time.Time
+	for _, chunk := range chunks {
+		n, err := a.copyChunk(io.Discard, chunk)
+		if err != nil {
+			return err
+		}
+		size += n
+		if chunk.Last.After(modTime) {
+			modTime = chunk.Last
+		}
+	}
+
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: size, ModTime: modTime, Typeflag: tar.TypeReg}); err != nil {
+		return err
+	}
+	for _, chunk := range chunks {
+		if _, err := a.copyChunk(tw, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyChunk 解压分片写入w
+func (a *Archive) copyChunk(w io.Writer, chunk Chunk) (int64, error) {
+	file, err := os.Open(filepath.Join(a.root, chunk.Path))
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return 0, fmt.Errorf("读取日志分片 %s 失败: %w", chunk.Path, err)
+	}
+	defer gz.Close()
+	return io.Copy(w, gz)
+}
+
+// parseChunkName 解析分片文件名中的首尾日志时间
+func parseChunkName(name string) (time.Time, time.Time, bool) {
+	if !strings.HasSuffix(name, chunkSuffix) {
+		return time.Time{}, time.Time{}, false
+	}
+	firstValue, lastValue, ok := strings.Cut(strings.TrimSuffix(name, chunkSuffix), "-")
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	first, err := strconv.ParseInt(firstValue, 10, 64)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	last, err := strconv.ParseInt(lastValue, 10, 64)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	return time.Unix(0, first), time.Unix(0, last), true
+}
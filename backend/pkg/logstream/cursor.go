@@ -0,0 +1,57 @@
+package logstream
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cursor 日志流的续传游标，记录每个Pod已推送的最后一行日志时间
+// 编码后作为SSE事件ID，断线重连时通过Last-Event-ID或offset参数带回
+type Cursor map[string]time.Time
+
+// ParseCursor 解析编码后的游标，空字符串返回空游标
+func ParseCursor(value string) (Cursor, error) {
+	cursor := make(Cursor)
+	if value == "" {
+		return cursor, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("日志游标格式错误: %v", err)
+	}
+	for _, item := range strings.Split(string(data), ",") {
+		pod, nanos, ok := strings.Cut(item, "@")
+		if !ok || pod == "" {
+			return nil, fmt.Errorf("日志游标格式错误: %q", item)
+		}
+		n, err := strconv.ParseInt(nanos, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("日志游标格式错误: %q", item)
+		}
+		cursor[pod] = time.Unix(0, n).UTC()
+	}
+	return cursor, nil
+}
+
+// String 编码游标，Pod按名称排序保证结果稳定
+func (c Cursor) String() string {
+	if len(c) == 0 {
+		return ""
+	}
+	pods := make([]string, 0, len(c))
+	for pod := range c {
+		pods = append(pods, pod)
+	}
+	sort.Strings(pods)
+
+	items := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		items = append(items, pod+"@"+strconv.FormatInt(c[pod].UnixNano(), 10))
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(strings.Join(items, ",")))
+}
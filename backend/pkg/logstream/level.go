@@ -0,0 +1,117 @@
+package logstream
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// 日志级别，与vt_training_logs.log_level一致，按严重程度递增
+const (
+	LevelTrace = "TRACE"
+	LevelDebug = "DEBUG"
+	LevelInfo  = "INFO"
+	LevelWarn  = "WARN"
+	LevelError = "ERROR"
+	LevelFatal = "FATAL"
+)
+
+var levelOrder = []string{LevelTrace, LevelDebug, LevelInfo, LevelWarn, LevelError, LevelFatal}
+
+var (
+	// glogPattern glog/absl格式的行首，如 "E0302 02:30:00.123456"
+	glogPattern = regexp.MustCompile(`^([IWEF])\d{4} \d{2}:\d{2}:\d{2}`)
+	// keywordPattern 行内的级别关键字，取最靠前的一个
+	keywordPattern = regexp.MustCompile(`\b(?i:(trace|debug|info|warn|warning|error|fatal|critical|panic))\b|\b\w+(Error|Exception):`)
+)
+
+// NormalizeLevel 将各种写法的级别名转换为标准级别，无法识别时返回空
+func NormalizeLevel(level string) string {
+	switch strings.ToUpper(strings.TrimSpace(level)) {
+	case "TRACE":
+		return LevelTrace
+	case "DEBUG":
+		return LevelDebug
+	case "INFO", "INFORMATION", "NOTICE":
+		return LevelInfo
+	case "WARN", "WARNING":
+		return LevelWarn
+	case "ERROR", "ERR":
+		return LevelError
+	case "FATAL", "CRITICAL", "PANIC":
+		return LevelFatal
+	}
+	return ""
+}
+
+// LevelRank 返回级别的严重程度，未知级别按INFO处理
+func LevelRank(level string) int {
+	for i, l := range levelOrder {
+		if l == level {
+			return i
+		}
+	}
+	return 2
+}
+
+// LevelsAtLeast 返回不低于min的全部级别，min为空时返回nil
+func LevelsAtLeast(min string) []string {
+	min = NormalizeLevel(min)
+	if min == "" {
+		return nil
+	}
+	return levelOrder[LevelRank(min):]
+}
+
+// LevelDetector 识别日志行的级别
+// 依次识别JSON结构化日志的level字段、glog行首和行内级别关键字，
+// 错误之后的缩进行（如Python traceback）沿用上一行的级别
+type LevelDetector struct {
+	last string
+}
+
+// Detect 返回日志行的级别
+func (d *LevelDetector) Detect(content string) string {
+	level := detectLevel(content)
+	if level == "" {
+		level = LevelInfo
+		indented := strings.HasPrefix(content, " ") || strings.HasPrefix(content, "\t")
+		if indented && LevelRank(d.last) >= LevelRank(LevelError) {
+			level = d.last
+		}
+	}
+	if strings.HasPrefix(content, "Traceback (most recent call last)") {
+		level = LevelError
+	}
+	d.last = level
+	return level
+}
+
+// detectLevel 识别单行日志中显式给出的级别
+func detectLevel(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if strings.HasPrefix(trimmed, "{") {
+		var fields map[string]interface{}
+		if json.Unmarshal([]byte(trimmed), &fields) == nil {
+			for _, key := range []string{"level", "severity", "levelname", "lvl"} {
+				if value, ok := fields[key].(string); ok {
+					if level := NormalizeLevel(value); level != "" {
+						return level
+					}
+				}
+			}
+		}
+	}
+
+	if m := glogPattern.FindStringSubmatch(content); m != nil {
+		return map[string]string{"I": LevelInfo, "W": LevelWarn, "E": LevelError, "F": LevelFatal}[m[1]]
+	}
+
+	if m := keywordPattern.FindStringSubmatch(content); m != nil {
+		if m[1] == "" {
+			return LevelError
+		}
+		return NormalizeLevel(m[1])
+	}
+	return ""
+}
@@ -0,0 +1,389 @@
+package logstream
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	vcjob "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+)
+
+// maxLineBytes 单行日志的最大长度，超出部分丢弃
+const maxLineBytes = 64 * 1024
+
+// Line 一行容器日志
+type Line struct {
+	Pod     string    `json:"instance"`
+	Task    string    `json:"instanceType"`
+	Index   int       `json:"instanceIndex"`
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Content string    `json:"content"`
+	Offset  string    `json:"offset"` // 推送该行后的续传游标
+}
+
+// PodSource 提供作业Pod和容器日志，默认实现基于Kubernetes API
+type PodSource interface {
+	ListPods(ctx context.Context, namespace, selector string) ([]corev1.Pod, error)
+	GetPod(ctx context.Context, namespace, name string) (*corev1.Pod, error)
+	OpenLogs(ctx context.Context, namespace, name string, options *corev1.PodLogOptions) (io.ReadCloser, error)
+}
+
+type kubePodSource struct {
+	client kubernetes.Interface
+}
+
+// NewKubePodSource 创建基于Kubernetes API的Pod日志来源
+func NewKubePodSource(client kubernetes.Interface) PodSource {
+	return &kubePodSource{client: client}
+}
+
+func (s *kubePodSource) ListPods(ctx context.Context, namespace, selector string) ([]corev1.Pod, error) {
+	pods, err := s.client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	return pods.Items, nil
+}
+
+func (s *kubePodSource) GetPod(ctx context.Context, namespace, name string) (*corev1.Pod, error) {
+	return s.client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+func (s *kubePodSource) OpenLogs(ctx context.Context, namespace, name string, options *corev1.PodLogOptions) (io.ReadCloser, error) {
+	return s.client.CoreV1().Pods(namespace).GetLogs(name, options).Stream(ctx)
+}
+
+// Writer 日志推送目标，由同一个goroutine调用
+type Writer interface {
+	WriteLine(line *Line) error
+	Ping() error
+	WriteEnd(reason string) error
+}
+
+// Options 日志流参数
+type Options struct {
+	Namespace    string
+	Selector     string // 作业Pod的标签选择器
+	Pod          string // 只读取指定实例，为空时读取全部副本
+	Task         string // 只读取指定类型的实例（master/worker/ps）
+	Container    string
+	Follow       bool
+	SinceSeconds int64
+	TailLines    int64
+	MinLevel     string
+	Keyword      string
+	Cursor       Cursor // 断线重连时的续传游标，游标中的Pod从记录的时间之后继续读取
+	// Finished 跟随模式下判断作业是否已结束，作业结束且全部实例日志读取完毕后日志流结束
+	Finished func() bool
+}
+
+// accept 判断日志行是否满足级别和关键字过滤条件
+func (o *Options) accept(level, content string) bool {
+	if o.MinLevel != "" && LevelRank(level) < LevelRank(NormalizeLevel(o.MinLevel)) {
+		return false
+	}
+	return o.Keyword == "" || strings.Contains(content, o.Keyword)
+}
+
+// matches 判断Pod是否满足实例过滤条件
+func (o *Options) matches(pod *corev1.Pod) bool {
+	if o.Pod != "" && pod.Name != o.Pod {
+		return false
+	}
+	return o.Task == "" || pod.Labels[vcjob.TaskSpecKey] == o.Task
+}
+
+// Config 日志流配置
+type Config struct {
+	PollInterval      time.Duration // 跟随模式下发现新实例和重连日志流的间隔
+	HeartbeatInterval time.Duration // 没有新日志时的心跳间隔，避免代理断开空闲连接
+}
+
+// Streamer 合并作业全部副本的容器日志
+// 每个Pod一个goroutine读取日志，由调用方goroutine统一推送并维护续传游标；
+// 跟随模式下定期发现新创建的Pod（重试、弹性扩容），容器重启后从上次的位置重新连接
+type Streamer struct {
+	source PodSource
+	config Config
+	logger logx.Logger
+}
+
+// NewStreamer 创建日志流
+func NewStreamer(source PodSource, config Config) *Streamer {
+	if config.PollInterval <= 0 {
+		config.PollInterval = 5 * time.Second
+	}
+	if config.HeartbeatInterval <= 0 {
+		config.HeartbeatInterval = 15 * time.Second
+	}
+	return &Streamer{
+		source: source,
+		config: config,
+		logger: logx.WithContext(context.Background()),
+	}
+}
+
+// Stream 读取日志并推送给w，非跟随模式在全部实例读取完毕后返回，
+// 跟随模式在作业结束或ctx取消后返回
+func (s *Streamer) Stream(ctx context.Context, opts Options, w Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cursor := make(Cursor, len(opts.Cursor))
+	for pod, t := range opts.Cursor {
+		cursor[pod] = t
+	}
+
+	lines := make(chan *Line, 256)
+	done := make(chan string, 16)
+	started := make(map[string]bool)
+	active := 0
+
+	discover := func() error {
+		pods, err := s.source.ListPods(ctx, opts.Namespace, opts.Selector)
+		if err != nil {
+			return err
+		}
+		sortPods(pods)
+		for i := range pods {
+			pod := &pods[i]
+			if started[pod.Name] || !opts.matches(pod) || !containerStarted(pod) {
+				continue
+			}
+			started[pod.Name] = true
+			active++
+			go s.tail(ctx, pod, opts, cursor[pod.Name], lines, done)
+		}
+		return nil
+	}
+	if err := discover(); err != nil {
+		return err
+	}
+
+	emit := func(line *Line) error {
+		cursor[line.Pod] = line.Time
+		line.Offset = cursor.String()
+		return w.WriteLine(line)
+	}
+
+	poll := time.NewTicker(s.config.PollInterval)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(s.config.HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		if active == 0 && (!opts.Follow || (opts.Finished != nil && opts.Finished())) {
+			// 读取goroutine先发送完日志再通知结束，剩余日志都已在缓冲区中
+			for {
+				select {
+				case line := <-lines:
+					if err := emit(line); err != nil {
+						return err
+					}
+				default:
+					return nil
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case line := <-lines:
+			if err := emit(line); err != nil {
+				return err
+			}
+			heartbeat.Reset(s.config.HeartbeatInterval)
+		case <-done:
+			active--
+		case <-poll.C:
+			if opts.Follow {
+				if err := discover(); err != nil {
+					s.logger.Errorf("查询作业Pod失败: %s/%s, %v", opts.Namespace, opts.Selector, err)
+				}
+			}
+		case <-heartbeat.C:
+			if err := w.Ping(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// tail 读取一个Pod的日志，跟随模式下容器重启后从上次读取的位置重新连接，Pod结束后退出
+func (s *Streamer) tail(ctx context.Context, pod *corev1.Pod, opts Options, since time.Time, out chan<- *Line, done chan<- string) {
+	defer func() {
+		select {
+		case done <- pod.Name:
+		case <-ctx.Done():
+		}
+	}()
+
+	task, index := pod.Labels[vcjob.TaskSpecKey], podIndex(pod)
+	last := since
+	for first := true; ; first = false {
+		logOptions := &corev1.PodLogOptions{Container: opts.Container, Follow: opts.Follow, Timestamps: true}
+		if !last.IsZero() {
+			// sinceTime只精确到秒，重复的日志按游标时间过滤
+			sinceTime := metav1.NewTime(last.Truncate(time.Second))
+			logOptions.SinceTime = &sinceTime
+		} else if first {
+			if opts.SinceSeconds > 0 {
+				logOptions.SinceSeconds = &opts.SinceSeconds
+			}
+			if opts.TailLines > 0 {
+				logOptions.TailLines = &opts.TailLines
+			}
+		}
+
+		err := s.read(ctx, pod, logOptions, &last, task, index, opts, out)
+		if !opts.Follow || ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			s.logger.Infof("Pod日志流中断，稍后重新连接: %s/%s, %v", pod.Namespace, pod.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.config.PollInterval):
+		}
+		current, err := s.source.GetPod(ctx, pod.Namespace, pod.Name)
+		if err != nil || current.UID != pod.UID || isPodFinished(current) {
+			// Pod结束前日志流已读到容器退出，无需再读取
+			return
+		}
+	}
+}
+
+// read 打开一次日志流并逐行读取，跳过时间不晚于last的日志
+func (s *Streamer) read(ctx context.Context, pod *corev1.Pod, logOptions *corev1.PodLogOptions, last *time.Time,
+	task string, index int, opts Options, out chan<- *Line) error {
+	stream, err := s.source.OpenLogs(ctx, pod.Namespace, pod.Name, logOptions)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	skipUntil := *last
+	detector := &LevelDetector{}
+	reader := bufio.NewReaderSize(stream, 4096)
+	for {
+		raw, err := readLine(reader)
+		if raw != "" || err == nil {
+			t, content := splitTimestamp(raw)
+			if !t.IsZero() && !skipUntil.IsZero() && !t.After(skipUntil) {
+				continue
+			}
+			if !t.IsZero() {
+				*last = t
+			}
+
+			level := detector.Detect(content)
+			if opts.accept(level, content) {
+				line := &Line{Pod: pod.Name, Task: task, Index: index, Time: t, Level: level, Content: content}
+				select {
+				case out <- line:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readLine 读取一行日志，超长部分丢弃
+func readLine(reader *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if len(line) < maxLineBytes {
+			remain := maxLineBytes - len(line)
+			if len(chunk) > remain {
+				chunk = chunk[:remain]
+			}
+			line = append(line, chunk...)
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		return strings.TrimRight(string(line), "\r\n"), err
+	}
+}
+
+// splitTimestamp 拆分kubelet在日志行首添加的RFC3339时间戳
+func splitTimestamp(raw string) (time.Time, string) {
+	prefix, content, ok := strings.Cut(raw, " ")
+	if !ok {
+		prefix, content = raw, ""
+	}
+	t, err := time.Parse(time.RFC3339Nano, prefix)
+	if err != nil {
+		return time.Time{}, raw
+	}
+	return t, content
+}
+
+// containerStarted 判断Pod是否已有容器启动，未启动的容器无法读取日志
+func containerStarted(pod *corev1.Pod) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Running != nil || status.State.Terminated != nil || status.LastTerminationState.Terminated != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// isPodFinished 判断Pod是否已结束
+func isPodFinished(pod *corev1.Pod) bool {
+	return pod.DeletionTimestamp != nil || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
+
+// podIndex 获取Pod在任务中的序号
+func podIndex(pod *corev1.Pod) int {
+	value := pod.Annotations[vcjob.TaskIndex]
+	if value == "" {
+		if pos := strings.LastIndex(pod.Name, "-"); pos >= 0 {
+			value = pod.Name[pos+1:]
+		}
+	}
+	index, _ := strconv.Atoi(value)
+	return index
+}
+
+// sortPods 按任务类型和序号排序，master在前
+func sortPods(pods []corev1.Pod) {
+	rank := func(pod *corev1.Pod) int {
+		switch pod.Labels[vcjob.TaskSpecKey] {
+		case "master", "chief":
+			return 0
+		case "worker":
+			return 1
+		}
+		return 2
+	}
+	sort.SliceStable(pods, func(i, j int) bool {
+		ri, rj := rank(&pods[i]), rank(&pods[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return podIndex(&pods[i]) < podIndex(&pods[j])
+	})
+}
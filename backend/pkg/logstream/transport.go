@@ -0,0 +1,126 @@
+package logstream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// IsWebSocketRequest 判断是否为WebSocket升级请求
+func IsWebSocketRequest(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
+// IsStreamRequest 判断客户端是否以SSE或WebSocket方式读取日志
+// 与go-zero超时中间件的判断保持一致，只有这两类请求不受服务端请求超时限制
+func IsStreamRequest(r *http.Request) bool {
+	return IsWebSocketRequest(r) || r.Header.Get("Accept") == "text/event-stream"
+}
+
+// Serve 按请求类型建立SSE或WebSocket连接，并在连接上执行run，客户端断开时ctx被取消
+func Serve(w http.ResponseWriter, r *http.Request, run func(ctx context.Context, w Writer)) {
+	if IsWebSocketRequest(r) {
+		serveWebSocket(w, r, run)
+		return
+	}
+	run(r.Context(), newSSEWriter(w))
+}
+
+// sseWriter 以Server-Sent Events推送日志，事件ID为续传游标，浏览器重连时通过Last-Event-ID带回
+type sseWriter struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func newSSEWriter(w http.ResponseWriter) *sseWriter {
+	rc := http.NewResponseController(w)
+	// 尽量取消服务端写超时，无法取消时连接到期断开，客户端按游标续传
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	s := &sseWriter{w: w, rc: rc}
+	_ = s.write("retry: 3000\n\n")
+	return s
+}
+
+func (s *sseWriter) write(data string) error {
+	if _, err := fmt.Fprint(s.w, data); err != nil {
+		return err
+	}
+	if err := s.rc.Flush(); err != nil && err != http.ErrNotSupported {
+		return err
+	}
+	return nil
+}
+
+func (s *sseWriter) WriteLine(line *Line) error {
+	data, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	return s.write(fmt.Sprintf("id: %s\nevent: log\ndata: %s\n\n", line.Offset, data))
+}
+
+func (s *sseWriter) Ping() error {
+	return s.write(": ping\n\n")
+}
+
+func (s *sseWriter) WriteEnd(reason string) error {
+	data, _ := json.Marshal(map[string]string{"reason": reason})
+	return s.write(fmt.Sprintf("event: end\ndata: %s\n\n", data))
+}
+
+// wsMessage WebSocket消息，type为log、ping或end
+type wsMessage struct {
+	Type   string `json:"type"`
+	Line   *Line  `json:"line,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// wsWriter 以WebSocket推送日志，每条消息为一个JSON对象
+type wsWriter struct {
+	conn *websocket.Conn
+}
+
+func (s *wsWriter) WriteLine(line *Line) error {
+	return websocket.JSON.Send(s.conn, wsMessage{Type: "log", Line: line})
+}
+
+func (s *wsWriter) Ping() error {
+	return websocket.JSON.Send(s.conn, wsMessage{Type: "ping"})
+}
+
+func (s *wsWriter) WriteEnd(reason string) error {
+	return websocket.JSON.Send(s.conn, wsMessage{Type: "end", Reason: reason})
+}
+
+// serveWebSocket 完成WebSocket握手，认证已由JWT中间件完成，不校验Origin
+func serveWebSocket(w http.ResponseWriter, r *http.Request, run func(ctx context.Context, w Writer)) {
+	server := websocket.Server{Handler: func(conn *websocket.Conn) {
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Time{})
+
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		// 客户端只会发送关闭帧，读取失败即视为断开
+		go func() {
+			defer cancel()
+			var discard []byte
+			for websocket.Message.Receive(conn, &discard) == nil {
+			}
+		}()
+
+		run(ctx, &wsWriter{conn: conn})
+	}}
+	server.ServeHTTP(w, r)
+}
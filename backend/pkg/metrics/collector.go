@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"api/model"
@@ -12,10 +13,11 @@ import (
 
 // MetricsCollector 训练指标收集器
 type MetricsCollector struct {
-	logger   logx.Logger
-	interval time.Duration
-	ctx      context.Context
-	cancel   context.CancelFunc
+	logger       logx.Logger
+	interval     time.Duration
+	metricsModel model.VtTrainingMetricsModel
+	ctx          context.Context
+	cancel       context.CancelFunc
 }
 
 // JobMetrics 作业指标数据
@@ -58,15 +60,16 @@ type MetricsStorage interface {
 	Delete(jobID int64) error
 }
 
-// NewMetricsCollector 创建指标收集器
-func NewMetricsCollector(interval time.Duration) *MetricsCollector {
+// NewMetricsCollector 创建指标收集器，训练指标来自metricsModel中训练脚本上报的数据
+func NewMetricsCollector(interval time.Duration, metricsModel model.VtTrainingMetricsModel) *MetricsCollector {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &MetricsCollector{
-		logger:   logx.WithContext(ctx),
-		interval: interval,
-		ctx:      ctx,
-		cancel:   cancel,
+		logger:       logx.WithContext(ctx),
+		interval:     interval,
+		metricsModel: metricsModel,
+		ctx:          ctx,
+		cancel:       cancel,
 	}
 }
 
@@ -167,34 +170,55 @@ func (mc *MetricsCollector) collectResourceMetrics(job *model.VtTrainingJobs, me
 	return nil
 }
 
-// collectTrainingMetrics 收集训练指标
-func (mc *MetricsCollector) collectTrainingMetrics(job *model.VtTrainingJobs, metrics *JobMetrics) error {
-	// TODO: 实际环境中需要解析训练日志或从训练框架获取指标
-	// 这里提供模拟数据
-
-	// 模拟训练进度
-	elapsed := time.Since(job.SubmittedAt).Minutes()
-
-	// 模拟epoch和step
-	metrics.Epoch = int(elapsed / 10) // 假设每10分钟一个epoch
-	metrics.Step = int(elapsed * 100) // 假设每分钟100步
-
-	// 模拟训练指标
-	if metrics.Epoch > 0 {
-		// 模拟loss下降趋势
-		metrics.Loss = 2.0 / (1.0 + float64(metrics.Epoch)*0.1)
-
-		// 模拟accuracy上升趋势
-		metrics.Accuracy = 1.0 - metrics.Loss/2.0
+// trainingMetricAliases 训练脚本上报的常见指标名，按优先级排列
+var trainingMetricAliases = map[string][]string{
+	"loss":          {"loss", "train/loss", "train_loss"},
+	"accuracy":      {"accuracy", "acc", "train/accuracy", "train_accuracy"},
+	"learning_rate": {"learning_rate", "lr", "train/learning_rate"},
+	"throughput":    {"throughput", "samples_per_second", "train/samples_per_second"},
+	"batch_size":    {"batch_size"},
+}
 
-		// 模拟学习率衰减
-		metrics.LearningRate = 0.01 * (0.95 / float64(1+metrics.Epoch))
+// collectTrainingMetrics 读取训练脚本通过指标上报接口写入的最新训练指标
+func (mc *MetricsCollector) collectTrainingMetrics(job *model.VtTrainingJobs, metrics *JobMetrics) error {
+	if mc.metricsModel == nil {
+		return nil
+	}
+	latest, err := mc.metricsModel.FindLatestScalars(job.Id)
+	if err != nil {
+		return err
+	}
 
-		// 模拟其他指标
-		metrics.BatchSize = 32
-		metrics.Throughput = 50.0 + float64(time.Now().Unix()%20)
+	lookup := func(name string) (float64, bool) {
+		for _, alias := range trainingMetricAliases[name] {
+			if metric, ok := latest[alias]; ok {
+				value, err := strconv.ParseFloat(metric.MetricValue, 64)
+				return value, err == nil
+			}
+		}
+		return 0, false
+	}
+	metrics.Loss, _ = lookup("loss")
+	metrics.Accuracy, _ = lookup("accuracy")
+	metrics.LearningRate, _ = lookup("learning_rate")
+	metrics.Throughput, _ = lookup("throughput")
+	if batchSize, ok := lookup("batch_size"); ok {
+		metrics.BatchSize = int(batchSize)
 	}
 
+	// 训练进度取全部指标中最大的轮次和步数
+	for _, metric := range latest {
+		if metric.Epoch > metrics.Epoch {
+			metrics.Epoch = metric.Epoch
+		}
+		step := metric.GlobalStep
+		if step == 0 {
+			step = metric.Step
+		}
+		if int(step) > metrics.Step {
+			metrics.Step = int(step)
+		}
+	}
 	return nil
 }
 
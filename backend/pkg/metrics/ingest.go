@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"api/model"
+)
+
+// jobTokenPrefix 作业指标上报令牌前缀，用于和用户JWT区分
+const jobTokenPrefix = "vtm_"
+
+// maxMetricValue vt_training_metrics中DECIMAL(20, 8)列能保存的最大绝对值
+const maxMetricValue = 1e12
+
+// JobToken 生成作业的指标上报令牌
+// 令牌由服务端密钥对作业ID做HMAC得到，无需入库，作业重试和重启后保持不变
+func JobToken(secret string, jobId int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("metrics:" + strconv.FormatInt(jobId, 10)))
+	return jobTokenPrefix + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyJobToken 校验作业的指标上报令牌，token可以带Bearer前缀
+func VerifyJobToken(secret string, jobId int64, token string) bool {
+	if secret == "" {
+		return false
+	}
+	token = strings.TrimSpace(strings.TrimPrefix(token, "Bearer "))
+	return hmac.Equal([]byte(token), []byte(JobToken(secret, jobId)))
+}
+
+// Histogram 直方图指标，与TensorBoard的HistogramProto字段一致
+// BucketLimits[i]为第i个桶的右边界，BucketCounts[i]为落在该桶的样本数
+type Histogram struct {
+	Min          float64   `json:"min"`
+	Max          float64   `json:"max"`
+	Num          float64   `json:"num"`
+	Sum          float64   `json:"sum"`
+	SumSquares   float64   `json:"sumSquares"`
+	BucketLimits []float64 `json:"bucketLimits"`
+	BucketCounts []float64 `json:"bucketCounts"`
+}
+
+// Validate 检查直方图的桶定义
+func (h *Histogram) Validate() error {
+	if len(h.BucketLimits) != len(h.BucketCounts) {
+		return fmt.Errorf("bucketLimits和bucketCounts长度不一致")
+	}
+	if h.Num < 0 {
+		return fmt.Errorf("样本数不能为负数")
+	}
+	for i := 1; i < len(h.BucketLimits); i++ {
+		if h.BucketLimits[i] < h.BucketLimits[i-1] {
+			return fmt.Errorf("bucketLimits必须递增")
+		}
+	}
+	for _, value := range []float64{h.Min, h.Max, h.Sum, h.SumSquares} {
+		if err := ValidateValue(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Mean 返回样本均值，没有样本时返回0
+func (h *Histogram) Mean() float64 {
+	if h.Num == 0 {
+		return 0
+	}
+	return h.Sum / h.Num
+}
+
+// Std 返回样本标准差，没有样本时返回0
+func (h *Histogram) Std() float64 {
+	if h.Num == 0 {
+		return 0
+	}
+	mean := h.Mean()
+	// 浮点误差可能使方差略小于0
+	return math.Sqrt(math.Max(h.SumSquares/h.Num-mean*mean, 0))
+}
+
+// ValidateValue 检查指标值能否写入DECIMAL列，NaN和Inf无法保存
+func ValidateValue(value float64) error {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return fmt.Errorf("指标值 %v 不是有限数值", value)
+	}
+	if math.Abs(value) >= maxMetricValue {
+		return fmt.Errorf("指标值 %v 超出范围", value)
+	}
+	return nil
+}
+
+// FormatValue 将指标值格式化为DECIMAL列的字符串
+func FormatValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// SetHistogram 将直方图写入指标的metric_data，并填充最小、最大、均值和标准差列
+func SetHistogram(m *model.VtTrainingMetrics, h *Histogram) error {
+	if err := h.Validate(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	m.MetricType = model.MetricTypeHistogram
+	m.MetricData = string(data)
+	m.MinValue = FormatValue(h.Min)
+	m.MaxValue = FormatValue(h.Max)
+	m.AvgValue = FormatValue(h.Mean())
+	m.StdValue = FormatValue(h.Std())
+	return nil
+}
+
+// SetWallTime 按Unix时间戳(秒)设置指标时间，相对时间以作业开始时间为起点
+func SetWallTime(m *model.VtTrainingMetrics, wallTime float64, start *time.Time) {
+	if wallTime <= 0 {
+		return
+	}
+	seconds, fraction := math.Modf(wallTime)
+	m.MetricTime = time.Unix(int64(seconds), int64(fraction*1e9))
+	m.WallTime = strconv.FormatFloat(wallTime, 'f', 3, 64)
+	if start != nil && !m.MetricTime.Before(*start) {
+		m.RelativeTimeSeconds = strconv.FormatFloat(m.MetricTime.Sub(*start).Seconds(), 'f', 3, 64)
+	}
+}
@@ -0,0 +1,307 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/api"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// DCGM exporter导出的设备指标
+const (
+	DCGMGPUUtil     = "DCGM_FI_DEV_GPU_UTIL"      // GPU使用率(%)
+	DCGMMemCopyUtil = "DCGM_FI_DEV_MEM_COPY_UTIL" // 显存带宽使用率(%)
+	DCGMFBUsed      = "DCGM_FI_DEV_FB_USED"       // 已用显存(MiB)
+	DCGMFBFree      = "DCGM_FI_DEV_FB_FREE"       // 可用显存(MiB)
+	DCGMPowerUsage  = "DCGM_FI_DEV_POWER_USAGE"   // 功耗(W)
+	DCGMGPUTemp     = "DCGM_FI_DEV_GPU_TEMP"      // 温度(℃)
+
+	DCGMXIDErrors       = "DCGM_FI_DEV_XID_ERRORS"        // 最近一次XID错误码
+	DCGMECCDBEVolTotal  = "DCGM_FI_DEV_ECC_DBE_VOL_TOTAL" // 驱动加载以来的双比特ECC错误数
+	DCGMRowRemapFailure = "DCGM_FI_DEV_ROW_REMAP_FAILURE" // 显存行重映射失败
+)
+
+// DCGMFields 采集的DCGM指标
+var DCGMFields = []string{DCGMGPUUtil, DCGMMemCopyUtil, DCGMFBUsed, DCGMFBFree, DCGMPowerUsage, DCGMGPUTemp,
+	DCGMXIDErrors, DCGMECCDBEVolTotal, DCGMRowRemapFailure}
+
+// CriticalXIDs 表示GPU硬件或驱动故障、需要下线维修的XID错误，
+// 13、31、43等由应用程序引起的XID不影响设备健康
+var CriticalXIDs = map[int]string{
+	48:  "双比特ECC错误",
+	62:  "GPU内部微控制器停止",
+	64:  "显存页退役或行重映射记录失败",
+	74:  "NVLink错误",
+	79:  "GPU已从总线上掉线",
+	92:  "单比特ECC错误率过高",
+	95:  "不可隔离的ECC错误",
+	119: "GSP RPC超时",
+	120: "GSP错误",
+	140: "无法恢复的ECC错误",
+}
+
+// DCGM exporter的设备标签；节点名优先使用Prometheus重标记得到的kubernetes_node或node标签，
+// 未开启hostNetwork时Hostname是exporter所在Pod的名称
+var dcgmHostLabels = []string{"kubernetes_node", "node", "Hostname"}
+
+// DCGMSample 单个GPU的一组DCGM指标
+type DCGMSample struct {
+	UUID      string
+	PCIBusID  string // 已按NormalizePCIBusID规范化
+	Hostname  string
+	GPUIndex  int
+	ModelName string
+	Pod       string // 正在使用该GPU的Pod，需开启exporter的Kubernetes映射
+	Namespace string
+	Values    map[string]float64 // 按DCGM指标名
+}
+
+// Value 读取指标值，exporter未导出该指标时返回false
+func (s *DCGMSample) Value(field string) (float64, bool) {
+	value, ok := s.Values[field]
+	return value, ok
+}
+
+// FaultReason 根据XID和ECC错误计数判断设备是否故障，返回故障原因，设备正常时返回空字符串
+func (s *DCGMSample) FaultReason() string {
+	if value, ok := s.Value(DCGMXIDErrors); ok && value > 0 {
+		xid := int(value)
+		if description, critical := CriticalXIDs[xid]; critical {
+			return fmt.Sprintf("XID %d: %s", xid, description)
+		}
+	}
+	if value, ok := s.Value(DCGMECCDBEVolTotal); ok && value > 0 {
+		return fmt.Sprintf("双比特ECC错误%.0f次", value)
+	}
+	if value, ok := s.Value(DCGMRowRemapFailure); ok && value > 0 {
+		return "显存行重映射失败"
+	}
+	return ""
+}
+
+// DCGMSource GPU指标数据源
+type DCGMSource interface {
+	Samples(ctx context.Context) ([]DCGMSample, error)
+}
+
+// NormalizePCIBusID 规范化PCIe总线ID，DCGM使用8位域号(00000000:07:00.0)，nvidia-smi和sysfs常用4位域号(0000:07:00.0)
+func NormalizePCIBusID(id string) string {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if domain, rest, ok := strings.Cut(id, ":"); ok && len(domain) > 4 {
+		id = domain[len(domain)-4:] + ":" + rest
+	}
+	return id
+}
+
+// dcgmSampleSet 按GPU合并各指标的序列
+type dcgmSampleSet struct {
+	samples map[string]*DCGMSample
+}
+
+func newDCGMSampleSet() *dcgmSampleSet {
+	return &dcgmSampleSet{samples: make(map[string]*DCGMSample)}
+}
+
+func (s *dcgmSampleSet) add(field string, labels map[string]string, value float64) {
+	var host string
+	for _, key := range dcgmHostLabels {
+		if labels[key] != "" {
+			host = labels[key]
+			break
+		}
+	}
+	key := labels["UUID"]
+	if key == "" {
+		key = host + "/" + labels["gpu"]
+	}
+
+	sample, ok := s.samples[key]
+	if !ok {
+		index, _ := strconv.Atoi(labels["gpu"])
+		sample = &DCGMSample{
+			UUID:      labels["UUID"],
+			PCIBusID:  NormalizePCIBusID(labels["pci_bus_id"]),
+			Hostname:  host,
+			GPUIndex:  index,
+			ModelName: labels["modelName"],
+			Values:    make(map[string]float64),
+		}
+		s.samples[key] = sample
+	}
+	if sample.Pod == "" && labels["pod"] != "" {
+		sample.Pod = labels["pod"]
+		sample.Namespace = labels["namespace"]
+	}
+	sample.Values[field] = value
+}
+
+// list 按节点和设备索引排序返回
+func (s *dcgmSampleSet) list() []DCGMSample {
+	samples := make([]DCGMSample, 0, len(s.samples))
+	for _, sample := range s.samples {
+		samples = append(samples, *sample)
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		if samples[i].Hostname != samples[j].Hostname {
+			return samples[i].Hostname < samples[j].Hostname
+		}
+		return samples[i].GPUIndex < samples[j].GPUIndex
+	})
+	return samples
+}
+
+// addFamilies 合并Prometheus文本格式解析出的指标
+func (s *dcgmSampleSet) addFamilies(families map[string]*dto.MetricFamily) {
+	for _, field := range DCGMFields {
+		family, ok := families[field]
+		if !ok {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := make(map[string]string, len(metric.GetLabel()))
+			for _, pair := range metric.GetLabel() {
+				labels[pair.GetName()] = pair.GetValue()
+			}
+			var value float64
+			switch {
+			case metric.Gauge != nil:
+				value = metric.GetGauge().GetValue()
+			case metric.Counter != nil:
+				value = metric.GetCounter().GetValue()
+			case metric.Untyped != nil:
+				value = metric.GetUntyped().GetValue()
+			default:
+				continue
+			}
+			s.add(field, labels, value)
+		}
+	}
+}
+
+// ParseDCGMMetrics 解析DCGM exporter /metrics返回的Prometheus文本格式
+func ParseDCGMMetrics(r io.Reader) ([]DCGMSample, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return nil, fmt.Errorf("解析DCGM指标失败: %v", err)
+	}
+	set := newDCGMSampleSet()
+	set.addFamilies(families)
+	return set.list(), nil
+}
+
+// DCGMExporterSource 直接抓取各节点DCGM exporter的/metrics
+type DCGMExporterSource struct {
+	urls   []string
+	client *http.Client
+	logger logx.Logger
+}
+
+// NewDCGMExporterSource 创建DCGM exporter数据源，urls为各exporter的完整指标地址
+func NewDCGMExporterSource(urls []string, timeout time.Duration) *DCGMExporterSource {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &DCGMExporterSource{
+		urls:   urls,
+		client: &http.Client{Timeout: timeout},
+		logger: logx.WithContext(context.Background()),
+	}
+}
+
+// Samples 抓取全部exporter，部分exporter不可用时返回其余exporter的数据
+func (s *DCGMExporterSource) Samples(ctx context.Context) ([]DCGMSample, error) {
+	set := newDCGMSampleSet()
+	var lastErr error
+	scraped := 0
+	for _, url := range s.urls {
+		families, err := s.scrape(ctx, url)
+		if err != nil {
+			s.logger.Errorf("抓取DCGM exporter失败: %s, %v", url, err)
+			lastErr = err
+			continue
+		}
+		set.addFamilies(families)
+		scraped++
+	}
+	if scraped == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return set.list(), nil
+}
+
+func (s *DCGMExporterSource) scrape(ctx context.Context, url string) (map[string]*dto.MetricFamily, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/plain")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("解析DCGM指标失败: %v", err)
+	}
+	return families, nil
+}
+
+// PrometheusDCGMSource 从已抓取DCGM exporter的Prometheus查询最新值
+type PrometheusDCGMSource struct {
+	prometheus PromClientInterface
+}
+
+// NewPrometheusDCGMSource 创建Prometheus数据源
+func NewPrometheusDCGMSource(prometheus PromClientInterface) *PrometheusDCGMSource {
+	return &PrometheusDCGMSource{prometheus: prometheus}
+}
+
+// Samples 一次查询全部DCGM指标的即时向量
+func (s *PrometheusDCGMSource) Samples(ctx context.Context) ([]DCGMSample, error) {
+	query := fmt.Sprintf(`{__name__=~"%s"}`, strings.Join(DCGMFields, "|"))
+	result, err := s.prometheus.Query(ctx, query, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("查询DCGM指标失败: %v", err)
+	}
+	vector, ok := result.(model.Vector)
+	if !ok {
+		return nil, fmt.Errorf("DCGM指标查询结果类型错误: %T", result)
+	}
+
+	set := newDCGMSampleSet()
+	for _, sample := range vector {
+		labels := make(map[string]string, len(sample.Metric))
+		for name, value := range sample.Metric {
+			labels[string(name)] = string(value)
+		}
+		set.add(labels[model.MetricNameLabel], labels, float64(sample.Value))
+	}
+	return set.list(), nil
+}
+
+// NewPrometheusClient 创建Prometheus查询客户端
+func NewPrometheusClient(address string) (PromClientInterface, error) {
+	client, err := api.NewClient(api.Config{Address: address})
+	if err != nil {
+		return nil, err
+	}
+	return &PrometheusClient{client: v1.NewAPI(client)}, nil
+}
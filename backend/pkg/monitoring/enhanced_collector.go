@@ -628,7 +628,7 @@ func (c *EnhancedMetricsCollector) getGPUStats() []GPUStat {
 	// 查询数据库获取GPU信息
 	query := `SELECT id, model, utilization_gpu, device_uuid 
 		FROM vt_gpu_devices 
-		WHERE status = 'available' OR status = 'allocated'`
+		WHERE status = 'available' OR status = 'occupied'`
 
 	rows, err := c.db.Query(query)
 	if err != nil {
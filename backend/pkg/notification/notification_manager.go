@@ -488,6 +488,13 @@ func (m *NotificationManager) getRecipients(channelConfig map[string]interface{}
 		}
 	}
 
+	// 告警上下文中指定的收件人，如训练作业负责人
+	if alert.Alert.Context != nil {
+		if contextRecipients, ok := alert.Alert.Context["recipients"].([]string); ok {
+			recipients = append(recipients, contextRecipients...)
+		}
+	}
+
 	return recipients
 }
 
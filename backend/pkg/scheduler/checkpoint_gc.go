@@ -0,0 +1,295 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"api/model"
+	"api/pkg/checkpoint"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// WorkspaceEntityType 作业所属工作空间在vt_training_job_relations中的实体类型
+const WorkspaceEntityType = "workspace"
+
+// CheckpointGCConfig 检查点垃圾回收配置
+type CheckpointGCConfig struct {
+	Interval time.Duration // 回收间隔
+	DryRun   bool          // 定期回收只统计不删除
+}
+
+// CheckpointGCOptions 单次回收选项
+type CheckpointGCOptions struct {
+	DryRun bool
+	JobId  int64 // 只回收指定作业的检查点，0表示全部作业
+}
+
+// CheckpointGCItem 回收的检查点
+type CheckpointGCItem struct {
+	JobId        int64
+	CheckpointId int64
+	Name         string
+	Status       string
+	FileSize     int64
+	PolicyId     int64
+}
+
+// CheckpointGCReport 回收结果，DryRun时Removed为将被删除的检查点
+type CheckpointGCReport struct {
+	DryRun         bool
+	Jobs           int // 匹配到保留策略的作业数
+	Kept           int
+	Removed        []CheckpointGCItem
+	ReclaimedBytes int64
+	Failed         int // 删除失败的检查点数
+}
+
+// CheckpointGC 检查点垃圾回收
+// 每个作业只使用最具体的一条保留策略：作业 > 队列 > 工作空间 > 全局，没有匹配策略的作业不回收；
+// final类型的检查点、作业恢复使用的检查点和未结束作业的最新检查点始终保留
+type CheckpointGC struct {
+	policyModel     model.VtCheckpointRetentionPoliciesModel
+	checkpointModel model.VtTrainingCheckpointsModel
+	jobModel        model.VtTrainingJobsModel
+	queueModel      model.VtTrainingQueuesModel
+	relationModel   model.VtTrainingJobRelationsModel
+	manager         *checkpoint.CheckpointManager
+	config          CheckpointGCConfig
+	logger          logx.Logger
+
+	mu     sync.Mutex // 定期回收和手动回收不并发执行
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewCheckpointGC 创建检查点垃圾回收
+func NewCheckpointGC(policyModel model.VtCheckpointRetentionPoliciesModel, checkpointModel model.VtTrainingCheckpointsModel, jobModel model.VtTrainingJobsModel,
+	queueModel model.VtTrainingQueuesModel, relationModel model.VtTrainingJobRelationsModel, manager *checkpoint.CheckpointManager, config CheckpointGCConfig) *CheckpointGC {
+	if config.Interval <= 0 {
+		config.Interval = time.Hour
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &CheckpointGC{
+		policyModel:     policyModel,
+		checkpointModel: checkpointModel,
+		jobModel:        jobModel,
+		queueModel:      queueModel,
+		relationModel:   relationModel,
+		manager:         manager,
+		config:          config,
+		logger:          logx.WithContext(context.Background()),
+		ctx:             ctx,
+		cancel:          cancel,
+	}
+}
+
+// Start 启动回收循环
+func (g *CheckpointGC) Start() {
+	g.logger.Infof("启动检查点垃圾回收，回收间隔: %v, dry-run: %v", g.config.Interval, g.config.DryRun)
+
+	g.wg.Add(1)
+	go g.loop()
+}
+
+// Stop 停止回收循环
+func (g *CheckpointGC) Stop() {
+	g.cancel()
+	g.wg.Wait()
+	g.logger.Info("检查点垃圾回收已停止")
+}
+
+// loop 回收循环
+func (g *CheckpointGC) loop() {
+	defer g.wg.Done()
+
+	ticker := time.NewTicker(g.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := g.ReconcileOnce(); err != nil {
+			g.logger.Errorf("检查点垃圾回收失败: %v", err)
+		}
+
+		select {
+		case <-g.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReconcileOnce 按配置执行一次全量回收
+func (g *CheckpointGC) ReconcileOnce() error {
+	report, err := g.Run(g.ctx, CheckpointGCOptions{DryRun: g.config.DryRun})
+	if err != nil {
+		return err
+	}
+	if len(report.Removed) > 0 || report.Failed > 0 {
+		g.logger.Infof("检查点垃圾回收完成: dry-run=%v, 作业%d个, 保留%d个, 删除%d个, 释放%d字节, 失败%d个",
+			report.DryRun, report.Jobs, report.Kept, len(report.Removed), report.ReclaimedBytes, report.Failed)
+	}
+	return nil
+}
+
+// Run 按保留策略回收检查点，DryRun时只统计将被删除的检查点和可释放的空间
+func (g *CheckpointGC) Run(ctx context.Context, options CheckpointGCOptions) (*CheckpointGCReport, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	policies, err := g.policyModel.FindEnabled()
+	if err != nil {
+		return nil, err
+	}
+	report := &CheckpointGCReport{DryRun: options.DryRun}
+	if len(policies) == 0 {
+		return report, nil
+	}
+	resolver := &retentionResolver{gc: g, policies: make(map[string]map[int64]*model.VtCheckpointRetentionPolicies), queueIds: make(map[string]int64)}
+	for _, p := range policies {
+		if resolver.policies[p.ScopeType] == nil {
+			resolver.policies[p.ScopeType] = make(map[int64]*model.VtCheckpointRetentionPolicies)
+		}
+		resolver.policies[p.ScopeType][p.ScopeId] = p
+	}
+
+	jobIds := []int64{options.JobId}
+	if options.JobId == 0 {
+		if jobIds, err = g.checkpointModel.FindJobIds(); err != nil {
+			return nil, err
+		}
+	}
+	for _, jobId := range jobIds {
+		if ctx.Err() != nil {
+			return report, ctx.Err()
+		}
+		if err := g.collectJob(ctx, jobId, resolver, options.DryRun, report); err != nil {
+			g.logger.Errorf("回收作业检查点失败: 作业ID=%d, %v", jobId, err)
+		}
+	}
+	return report, nil
+}
+
+// collectJob 按作业匹配的保留策略回收检查点
+func (g *CheckpointGC) collectJob(ctx context.Context, jobId int64, resolver *retentionResolver, dryRun bool, report *CheckpointGCReport) error {
+	// 作业已删除时只匹配全局策略
+	job, err := g.jobModel.FindOneDetail(jobId)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		job = &model.VtTrainingJobs{Id: jobId, Status: JobStatusSucceeded}
+	}
+	policy, err := resolver.resolve(job)
+	if err != nil || policy == nil {
+		return err
+	}
+
+	checkpoints, err := g.checkpointModel.FindByJobId(jobId)
+	if err != nil {
+		return err
+	}
+	pinned := map[int64]bool{job.ResumeCheckpointId: true}
+	if !IsTerminalJobStatus(job.Status) {
+		for _, c := range checkpoints {
+			if c.IsLatest {
+				pinned[c.Id] = true
+			}
+		}
+	}
+	// 从该作业的检查点开始训练的克隆作业仍需要读取初始检查点
+	clones, err := g.relationModel.FindByEntity(JobEntityType, jobId, ClonedFromRelation)
+	if err != nil {
+		return err
+	}
+	for _, relation := range clones {
+		clone, err := g.jobModel.FindOneDetail(relation.JobId)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		pinned[clone.ResumeCheckpointId] = true
+	}
+	keep, remove := checkpoint.NewRetentionPolicy(policy).Select(checkpoints, pinned)
+	report.Jobs++
+	report.Kept += len(keep)
+
+	for _, c := range remove {
+		if !dryRun {
+			if err := g.manager.Delete(ctx, c); err != nil {
+				report.Failed++
+				g.logger.Errorf("删除检查点失败: 作业ID=%d, ID=%d, %s, %v", jobId, c.Id, c.StoragePath, err)
+				continue
+			}
+			g.logger.Infof("按保留策略删除检查点: 作业ID=%d, %s, 策略=%s, %d字节", jobId, c.CheckpointName, policy.Name, c.FileSize)
+		}
+		report.Removed = append(report.Removed, CheckpointGCItem{
+			JobId:        jobId,
+			CheckpointId: c.Id,
+			Name:         c.CheckpointName,
+			Status:       c.Status,
+			FileSize:     c.FileSize,
+			PolicyId:     policy.Id,
+		})
+		report.ReclaimedBytes += c.FileSize
+	}
+	return nil
+}
+
+// retentionResolver 单次回收内按作用范围匹配保留策略，缓存队列名到队列ID的映射
+type retentionResolver struct {
+	gc       *CheckpointGC
+	policies map[string]map[int64]*model.VtCheckpointRetentionPolicies
+	queueIds map[string]int64
+}
+
+// resolve 返回作业最具体的保留策略，没有匹配策略时返回nil
+func (r *retentionResolver) resolve(job *model.VtTrainingJobs) (*model.VtCheckpointRetentionPolicies, error) {
+	if p := r.policies[model.RetentionScopeJob][job.Id]; p != nil {
+		return p, nil
+	}
+	if len(r.policies[model.RetentionScopeQueue]) > 0 && job.QueueName != "" {
+		queueId, err := r.queueId(job.QueueName)
+		if err != nil {
+			return nil, err
+		}
+		if p := r.policies[model.RetentionScopeQueue][queueId]; p != nil {
+			return p, nil
+		}
+	}
+	if len(r.policies[model.RetentionScopeWorkspace]) > 0 {
+		relations, err := r.gc.relationModel.FindByJobId(job.Id, WorkspaceEntityType, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, relation := range relations {
+			if p := r.policies[model.RetentionScopeWorkspace][relation.EntityId]; p != nil {
+				return p, nil
+			}
+		}
+	}
+	return r.policies[model.RetentionScopeGlobal][0], nil
+}
+
+func (r *retentionResolver) queueId(name string) (int64, error) {
+	if id, ok := r.queueIds[name]; ok {
+		return id, nil
+	}
+	queue, err := r.gc.queueModel.FindOneByName(name)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+	var id int64
+	if queue != nil {
+		id = queue.Id
+	}
+	r.queueIds[name] = id
+	return id, nil
+}
@@ -0,0 +1,201 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"api/model"
+	"api/pkg/checkpoint"
+	"api/pkg/volcano"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// 从检查点恢复时与ResumeCheckpointEnv一起注入的检查点ID、步数和epoch
+	ResumeCheckpointIDEnv = "RESUME_CHECKPOINT_ID"
+	ResumeStepEnv         = "RESUME_STEP"
+	ResumeGlobalStepEnv   = "RESUME_GLOBAL_STEP"
+	ResumeEpochEnv        = "RESUME_EPOCH"
+
+	// ResumeDownloadDir 对象存储中的检查点下载到训练Pod内的目录
+	ResumeDownloadDir = "/resume-checkpoint"
+
+	resumeVolumeName     = "resume-checkpoint"
+	checkpointVolumeName = "checkpoint-storage"
+)
+
+// ResumeConfig 检查点恢复配置
+type ResumeConfig struct {
+	DownloaderImage string        // 下载检查点的初始化容器镜像，需要包含wget、sha256sum和gunzip
+	URLExpires      time.Duration // 检查点下载地址的有效期，需覆盖作业排队等待调度的时长
+	PVCName         string        // 文件系统存储所在的PVC，不为空时挂载到训练容器
+	MountPath       string        // 文件系统存储在训练容器中的挂载路径，需与storage_path的根目录一致
+}
+
+// CheckpointResumer 检查点恢复
+// 作业重新运行、自动重试或恢复时选择最新或最佳的可用检查点，
+// 派发时将检查点挂载或下载到训练Pod，并注入RESUME_FROM_CHECKPOINT等环境变量
+type CheckpointResumer struct {
+	checkpointModel model.VtTrainingCheckpointsModel
+	manager         *checkpoint.CheckpointManager
+	config          ResumeConfig
+	logger          logx.Logger
+}
+
+// NewCheckpointResumer 创建检查点恢复，manager为nil时不检查检查点文件，也不挂载或下载检查点
+func NewCheckpointResumer(checkpointModel model.VtTrainingCheckpointsModel, manager *checkpoint.CheckpointManager, config ResumeConfig) *CheckpointResumer {
+	if config.DownloaderImage == "" {
+		config.DownloaderImage = "busybox:1.36"
+	}
+	if config.URLExpires <= 0 {
+		config.URLExpires = 24 * time.Hour
+	}
+	return &CheckpointResumer{
+		checkpointModel: checkpointModel,
+		manager:         manager,
+		config:          config,
+		logger:          logx.WithContext(context.Background()),
+	}
+}
+
+// Select 按作业training_config中的检查点配置选择恢复用的检查点，没有可用的检查点时返回nil
+func (r *CheckpointResumer) Select(job *model.VtTrainingJobs) (*model.VtTrainingCheckpoints, error) {
+	config, err := checkpoint.ParseCheckpointConfig(job.TrainingConfig)
+	if err != nil {
+		r.logger.Errorf("训练作业检查点配置无效，按默认配置选择检查点: ID=%d, %v", job.Id, err)
+	}
+
+	if r.manager != nil {
+		return r.manager.SelectResume(context.Background(), job.Id, config)
+	}
+	checkpoints, err := r.checkpointModel.FindByJobId(job.Id)
+	if err != nil {
+		return nil, err
+	}
+	if candidates := checkpoint.ResumeCandidates(checkpoints, config); len(candidates) > 0 {
+		return candidates[0], nil
+	}
+	return nil, nil
+}
+
+// Load 查询指定的检查点并校验其属于该作业且已保存
+func (r *CheckpointResumer) Load(job *model.VtTrainingJobs, checkpointId int64) (*model.VtTrainingCheckpoints, error) {
+	c, err := r.checkpointModel.FindOne(checkpointId)
+	if err != nil {
+		return nil, err
+	}
+	if c.JobId != job.Id {
+		return nil, sql.ErrNoRows
+	}
+	if c.Status != "saved" {
+		return nil, fmt.Errorf("%w: 检查点 %s 状态为 %s", checkpoint.ErrCheckpointUnavailable, c.CheckpointName, c.Status)
+	}
+	return c, nil
+}
+
+// Inject 为从检查点恢复的作业注入检查点信息
+// 对象存储中的检查点由初始化容器下载到ResumeDownloadDir，RESUME_FROM_CHECKPOINT指向下载后的文件；
+// 文件系统存储在配置了PVC时挂载到训练容器；检查点记录已删除时只保留作业记录的恢复路径
+func (r *CheckpointResumer) Inject(spec *volcano.TrainingJobSpec, job *model.VtTrainingJobs) error {
+	if job.ResumeCheckpointId == 0 {
+		return nil
+	}
+	c, err := r.checkpointModel.FindOne(job.ResumeCheckpointId)
+	if err == sql.ErrNoRows {
+		r.logger.Infof("恢复用的检查点已删除，使用作业记录的恢复路径: 作业ID=%d, 检查点ID=%d", job.Id, job.ResumeCheckpointId)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if spec.EnvVars == nil {
+		spec.EnvVars = make(map[string]string)
+	}
+	spec.EnvVars[ResumeCheckpointEnv] = c.StoragePath
+	spec.EnvVars[ResumeCheckpointIDEnv] = strconv.FormatInt(c.Id, 10)
+	spec.EnvVars[ResumeStepEnv] = strconv.FormatInt(c.Step, 10)
+	spec.EnvVars[ResumeGlobalStepEnv] = strconv.FormatInt(c.GlobalStep, 10)
+	spec.EnvVars[ResumeEpochEnv] = strconv.Itoa(c.Epoch)
+
+	if r.manager == nil {
+		return nil
+	}
+	storage := r.manager.Storage()
+	key, err := storage.Key(c.StoragePath)
+	if err != nil {
+		// 不在平台存储中的检查点由训练脚本按storage_path自行读取
+		return nil
+	}
+	if presigner, ok := storage.(checkpoint.PresignedStorage); ok {
+		r.injectDownload(spec, c, key, presigner.PresignGet(key, r.config.URLExpires))
+		return nil
+	}
+	r.injectMount(spec)
+	return nil
+}
+
+// injectDownload 添加下载检查点的初始化容器，按记录的校验和校验后解压到共享的emptyDir
+func (r *CheckpointResumer) injectDownload(spec *volcano.TrainingJobSpec, c *model.VtTrainingCheckpoints, key, url string) {
+	name := path.Base(key)
+	download := path.Join(ResumeDownloadDir, name+".download")
+	script := []string{"set -e", `wget -q -O "$DOWNLOAD" "$CHECKPOINT_URL"`}
+	if algorithm, digest, ok := strings.Cut(c.Checksum, ":"); ok {
+		script = append(script, fmt.Sprintf(`echo "%s  $DOWNLOAD" | %ssum -c -`, digest, algorithm))
+	}
+	switch c.CompressionType {
+	case checkpoint.CompressionGzip:
+		name = strings.TrimSuffix(name, ".gz")
+		script = append(script, `gunzip -c "$DOWNLOAD" > "$CHECKPOINT_FILE"`, `rm -f "$DOWNLOAD"`)
+	case checkpoint.CompressionBzip2:
+		name = strings.TrimSuffix(name, ".bz2")
+		script = append(script, `bunzip2 -c "$DOWNLOAD" > "$CHECKPOINT_FILE"`, `rm -f "$DOWNLOAD"`)
+	default:
+		// lz4等平台无法解压的格式保持原样，由训练脚本处理
+		script = append(script, `mv "$DOWNLOAD" "$CHECKPOINT_FILE"`)
+	}
+	file := path.Join(ResumeDownloadDir, name)
+
+	spec.VolumeMounts = append(spec.VolumeMounts, volcano.VolumeMountSpec{
+		Name:         resumeVolumeName,
+		MountPath:    ResumeDownloadDir,
+		VolumeSource: volcano.VolumeSource{Type: "emptyDir"},
+	})
+	spec.InitContainers = append(spec.InitContainers, volcano.Container{
+		Name:            "resume-checkpoint",
+		Image:           r.config.DownloaderImage,
+		Command:         []string{"sh", "-c", strings.Join(script, "\n")},
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Env: []corev1.EnvVar{
+			{Name: "CHECKPOINT_URL", Value: url},
+			{Name: "DOWNLOAD", Value: download},
+			{Name: "CHECKPOINT_FILE", Value: file},
+		},
+		VolumeMounts: []corev1.VolumeMount{{Name: resumeVolumeName, MountPath: ResumeDownloadDir}},
+	})
+	spec.EnvVars[ResumeCheckpointEnv] = file
+}
+
+// injectMount 挂载文件系统存储所在的PVC，作业已在同一路径挂载存储卷时不重复挂载
+func (r *CheckpointResumer) injectMount(spec *volcano.TrainingJobSpec) {
+	if r.config.PVCName == "" || r.config.MountPath == "" {
+		return
+	}
+	for _, vm := range spec.VolumeMounts {
+		if path.Clean(vm.MountPath) == path.Clean(r.config.MountPath) {
+			return
+		}
+	}
+	spec.VolumeMounts = append(spec.VolumeMounts, volcano.VolumeMountSpec{
+		Name:         checkpointVolumeName,
+		MountPath:    r.config.MountPath,
+		VolumeSource: volcano.VolumeSource{Type: "pvc", PVCName: r.config.PVCName},
+	})
+}
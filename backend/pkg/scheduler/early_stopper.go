@@ -0,0 +1,280 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"api/model"
+	"api/pkg/checkpoint"
+	bizerrors "api/pkg/errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// FailureReasonEarlyStopped 作业因监控指标不再改善被提前停止时写入的失败原因，作业状态为succeeded
+const FailureReasonEarlyStopped = "early_stopped"
+
+// EarlyStopConfig 提前停止配置
+type EarlyStopConfig struct {
+	Interval    time.Duration // 检查间隔
+	GracePeriod time.Duration // 请求停止后等待训练脚本保存最终检查点的时长
+}
+
+// earlyStopRequest 已请求提前停止的运行
+type earlyStopRequest struct {
+	run            string
+	reason         string
+	requestedAt    time.Time
+	lastCheckpoint int64 // 请求停止时已保存的最新检查点ID
+}
+
+// EarlyStopper 训练作业提前停止
+// 按作业training_config.checkpoint中的monitor_metric、patience和min_delta评估上报的验证指标，
+// 连续patience次评估没有改善时请求停止：训练脚本通过指标上报响应的stopRequested得知后保存最终检查点并退出，
+// 保存了新的检查点或超过等待时长后删除Volcano作业，作业记为succeeded且failure_reason为early_stopped
+type EarlyStopper struct {
+	jobModel        model.VtTrainingJobsModel
+	metricsModel    model.VtTrainingMetricsModel
+	checkpointModel model.VtTrainingCheckpointsModel
+	machine         *JobStateMachine
+	config          EarlyStopConfig
+	logger          logx.Logger
+
+	mu       sync.Mutex
+	requests map[int64]*earlyStopRequest
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewEarlyStopper 创建提前停止检查
+func NewEarlyStopper(jobModel model.VtTrainingJobsModel, metricsModel model.VtTrainingMetricsModel,
+	checkpointModel model.VtTrainingCheckpointsModel, machine *JobStateMachine, config EarlyStopConfig) *EarlyStopper {
+	if config.Interval <= 0 {
+		config.Interval = time.Minute
+	}
+	if config.GracePeriod < 0 {
+		config.GracePeriod = 0
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &EarlyStopper{
+		jobModel:        jobModel,
+		metricsModel:    metricsModel,
+		checkpointModel: checkpointModel,
+		machine:         machine,
+		config:          config,
+		logger:          logx.WithContext(context.Background()),
+		requests:        make(map[int64]*earlyStopRequest),
+		ctx:             ctx,
+		cancel:          cancel,
+	}
+}
+
+// Start 启动检查循环
+func (s *EarlyStopper) Start() {
+	s.logger.Infof("启动训练作业提前停止检查，检查间隔: %v", s.config.Interval)
+
+	s.wg.Add(1)
+	go s.stopLoop()
+}
+
+// Stop 停止检查循环
+func (s *EarlyStopper) Stop() {
+	s.cancel()
+	s.wg.Wait()
+	s.logger.Info("训练作业提前停止检查已停止")
+}
+
+// stopLoop 检查循环
+func (s *EarlyStopper) stopLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.CheckOnce(time.Now()); err != nil {
+			s.logger.Errorf("训练作业提前停止检查失败: %v", err)
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// StopRequested 返回作业当前运行是否已被请求提前停止及原因
+func (s *EarlyStopper) StopRequested(job *model.VtTrainingJobs) (string, bool) {
+	request, ok := s.pending(job)
+	return request.reason, ok
+}
+
+// pending 返回作业当前运行的停止请求
+func (s *EarlyStopper) pending(job *model.VtTrainingJobs) (earlyStopRequest, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	request := s.requests[job.Id]
+	if request == nil || request.run != job.VolcanoJobName {
+		return earlyStopRequest{}, false
+	}
+	return *request, true
+}
+
+// CheckOnce 检查所有运行中的作业，返回本轮停止的作业数
+func (s *EarlyStopper) CheckOnce(now time.Time) (int, error) {
+	jobs, err := s.jobModel.FindRunning()
+	if err != nil {
+		return 0, err
+	}
+
+	running := make(map[int64]bool, len(jobs))
+	stopped := 0
+	for _, job := range jobs {
+		running[job.Id] = true
+		ok, err := s.checkJob(job, now)
+		if err != nil {
+			s.logger.Errorf("检查训练作业是否提前停止失败: ID=%d, %v", job.Id, err)
+			continue
+		}
+		if ok {
+			stopped++
+		}
+	}
+
+	// 已结束的作业不再跟踪
+	s.mu.Lock()
+	for id := range s.requests {
+		if !running[id] {
+			delete(s.requests, id)
+		}
+	}
+	s.mu.Unlock()
+	return stopped, nil
+}
+
+// checkJob 检查单个作业，返回是否已停止
+func (s *EarlyStopper) checkJob(job *model.VtTrainingJobs, now time.Time) (bool, error) {
+	request, ok := s.pending(job)
+	if !ok {
+		config, err := checkpoint.ParseCheckpointConfig(job.TrainingConfig)
+		if err != nil || config.Patience <= 0 {
+			return false, nil
+		}
+		values, err := s.values(job, config.MonitorMetric)
+		if err != nil {
+			return false, fmt.Errorf("查询监控指标失败: %w", err)
+		}
+		stop, reason := config.EarlyStop(values)
+		if !stop {
+			return false, nil
+		}
+		if request, err = s.request(job, reason, now); err != nil {
+			return false, err
+		}
+	}
+
+	latest, err := s.latestCheckpoint(job.Id)
+	if err != nil {
+		return false, fmt.Errorf("查询检查点失败: %w", err)
+	}
+	saved := latest > request.lastCheckpoint
+	if !saved && now.Sub(request.requestedAt) < s.config.GracePeriod {
+		return false, nil
+	}
+	return s.stop(job, request.reason, saved)
+}
+
+// request 记录停止请求，训练脚本下次上报指标时收到stopRequested
+func (s *EarlyStopper) request(job *model.VtTrainingJobs, reason string, now time.Time) (earlyStopRequest, error) {
+	latest, err := s.latestCheckpoint(job.Id)
+	if err != nil {
+		return earlyStopRequest{}, fmt.Errorf("查询检查点失败: %w", err)
+	}
+	request := earlyStopRequest{run: job.VolcanoJobName, reason: reason, requestedAt: now, lastCheckpoint: latest}
+
+	s.mu.Lock()
+	s.requests[job.Id] = &request
+	s.mu.Unlock()
+
+	s.logger.Infof("请求提前停止训练作业: ID=%d, %s", job.Id, reason)
+	return request, nil
+}
+
+// stop 删除Volcano作业并将作业标记为提前停止
+func (s *EarlyStopper) stop(job *model.VtTrainingJobs, reason string, saved bool) (bool, error) {
+	message := fmt.Sprintf("提前停止: %s", reason)
+	if !saved {
+		message += fmt.Sprintf("，%s 内未保存最终检查点", formatDuration(s.config.GracePeriod))
+	}
+
+	_, err := s.machine.terminate(job, JobTransition{
+		Action:   JobActionSucceed,
+		Operator: SystemOperator,
+		Reason:   message,
+		Fields: finishFields(job, map[string]interface{}{
+			"failure_reason": FailureReasonEarlyStopped,
+		}),
+	})
+	if err == bizerrors.ErrJobStatusChanged {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	delete(s.requests, job.Id)
+	s.mu.Unlock()
+
+	s.logger.Infof("训练作业已提前停止: ID=%d, %s", job.Id, message)
+	return true, nil
+}
+
+// values 返回作业当前运行上报的监控指标，优先使用验证阶段的指标
+func (s *EarlyStopper) values(job *model.VtTrainingJobs, metric string) ([]float64, error) {
+	series, err := s.metricsModel.FindSeries(model.TrainingMetricFilter{JobId: job.Id, MetricName: metric, StartTime: job.StartTime}, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected *model.MetricSeries
+	for _, ms := range series {
+		if ms.Phase == "val" {
+			selected = ms
+			break
+		}
+		if ms.Phase == "" {
+			selected = ms
+		}
+	}
+	if selected == nil {
+		return nil, nil
+	}
+	values := make([]float64, 0, len(selected.Points))
+	for _, point := range selected.Points {
+		values = append(values, point.Value)
+	}
+	return values, nil
+}
+
+// latestCheckpoint 返回作业已保存的最新检查点ID，没有检查点时返回0
+func (s *EarlyStopper) latestCheckpoint(jobId int64) (int64, error) {
+	checkpoints, err := s.checkpointModel.FindByJobId(jobId)
+	if err != nil {
+		return 0, err
+	}
+	var latest int64
+	for _, c := range checkpoints {
+		if c.Status == "saved" && c.Id > latest {
+			latest = c.Id
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,200 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"api/model"
+	bizerrors "api/pkg/errors"
+	"api/pkg/volcano"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// GPUNodeRemediation 单个节点的故障处理结果
+type GPUNodeRemediation struct {
+	NodeId     int64
+	NodeName   string
+	Pods       int     // 删除的Volcano Pod数
+	FailedJobs []int64 // 标记为gpu_fault失败的训练作业，按重试策略由自动重试器在其他节点重新运行
+}
+
+// GPUNodeRemediator GPU故障节点处理
+// 节点上有设备出现严重XID或ECC错误时隔离Kubernetes节点(cordon)，删除节点上的Volcano Pod，
+// 并将受影响的训练作业以gpu_fault失败，节点标记为maintenance直至管理员维修后解除隔离
+type GPUNodeRemediator struct {
+	clusterModel model.VtGpuClustersModel
+	nodeModel    model.VtGpuNodesModel
+	deviceModel  model.VtGpuDevicesModel
+	jobModel     model.VtTrainingJobsModel
+	machine      *JobStateMachine
+	clients      GPUClusterClient
+	logger       logx.Logger
+
+	mu sync.Mutex // 自动隔离和手动解除隔离不并发执行
+}
+
+// NewGPUNodeRemediator 创建GPU故障节点处理
+func NewGPUNodeRemediator(clusterModel model.VtGpuClustersModel, nodeModel model.VtGpuNodesModel, deviceModel model.VtGpuDevicesModel,
+	jobModel model.VtTrainingJobsModel, machine *JobStateMachine, clients GPUClusterClient) *GPUNodeRemediator {
+	return &GPUNodeRemediator{
+		clusterModel: clusterModel,
+		nodeModel:    nodeModel,
+		deviceModel:  deviceModel,
+		jobModel:     jobModel,
+		machine:      machine,
+		clients:      clients,
+		logger:       logx.WithContext(context.Background()),
+	}
+}
+
+// HandleGPUFaults 按节点处理故障，实现GPUFaultHandler；已处于维护状态的节点不重复处理
+func (r *GPUNodeRemediator) HandleGPUFaults(faults []GPUFault) error {
+	var nodeIds []int64
+	reasons := make(map[int64][]string)
+	for _, fault := range faults {
+		if _, ok := reasons[fault.NodeId]; !ok {
+			nodeIds = append(nodeIds, fault.NodeId)
+		}
+		reasons[fault.NodeId] = append(reasons[fault.NodeId], fmt.Sprintf("GPU %d: %s", fault.Index, fault.Reason))
+	}
+
+	var lastErr error
+	for _, nodeId := range nodeIds {
+		if _, err := r.RemediateNode(context.Background(), nodeId, strings.Join(reasons[nodeId], "; ")); err != nil {
+			r.logger.Errorf("处理GPU故障节点失败: ID=%d, %v", nodeId, err)
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// RemediateNode 隔离节点并驱逐其上的训练作业，节点已处于维护状态时返回nil
+// 删除Pod或作业失败时节点保持原状态，下一轮采集仍上报故障时重新处理
+func (r *GPUNodeRemediator) RemediateNode(ctx context.Context, nodeId int64, reason string) (*GPUNodeRemediation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	node, err := r.nodeModel.FindOne(nodeId)
+	if err != nil {
+		return nil, fmt.Errorf("查询GPU节点失败: %w", err)
+	}
+	if node.Status == model.GpuNodeStatusMaintenance || node.Status == model.GpuNodeStatusOffline {
+		return nil, nil
+	}
+	cluster, err := r.clusterModel.FindOne(node.ClusterId)
+	if err != nil {
+		return nil, fmt.Errorf("查询GPU集群失败: %w", err)
+	}
+	// 只有Kubernetes集群可以隔离和驱逐，其他集群只将节点置为维护状态
+	var pods []volcano.DrainedPod
+	if cluster.ClusterType == model.GpuClusterTypeK8s {
+		manager, err := r.clients(cluster)
+		if err != nil {
+			return nil, fmt.Errorf("连接集群失败: %w", err)
+		}
+		if err := manager.CordonNode(ctx, node.Name, reason); err != nil {
+			return nil, err
+		}
+		if pods, err = manager.DrainNode(ctx, node.Name); err != nil {
+			return nil, err
+		}
+	}
+	result := &GPUNodeRemediation{NodeId: node.Id, NodeName: node.Name, Pods: len(pods)}
+
+	if len(pods) > 0 {
+		affected := make(map[string]bool, len(pods))
+		for _, pod := range pods {
+			if pod.JobName != "" {
+				affected[pod.Namespace+"/"+pod.JobName] = true
+				affected[pod.JobName] = true
+			}
+		}
+		jobs, err := r.jobModel.FindSubmitted()
+		if err != nil {
+			return nil, fmt.Errorf("查询运行中的训练作业失败: %w", err)
+		}
+		message := fmt.Sprintf("节点 %s GPU故障: %s", node.Name, reason)
+		for _, job := range jobs {
+			key := job.VolcanoJobName
+			if job.Namespace != "" {
+				key = job.Namespace + "/" + key
+			}
+			if job.VolcanoJobName == "" || !affected[key] {
+				continue
+			}
+			_, err := r.machine.terminate(job, JobTransition{
+				Action:   JobActionFail,
+				Operator: SystemOperator,
+				Reason:   message,
+				Fields: map[string]interface{}{
+					"failure_reason": FailureReasonGPUFault,
+					"error_message":  message,
+				},
+			})
+			if err == bizerrors.ErrJobStatusChanged {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("终止训练作业 %d 失败: %w", job.Id, err)
+			}
+			result.FailedJobs = append(result.FailedJobs, job.Id)
+		}
+	}
+
+	node.Status = model.GpuNodeStatusMaintenance
+	if err := r.nodeModel.Update(node); err != nil {
+		return nil, fmt.Errorf("更新GPU节点状态失败: %w", err)
+	}
+
+	r.logger.Infof("GPU故障节点已隔离: 节点=%s, 删除Pod%d个, 失败作业%v, 原因=%s", node.Name, result.Pods, result.FailedJobs, reason)
+	return result, nil
+}
+
+// UncordonNode 管理员维修后解除节点隔离：恢复调度，故障设备恢复为healthy，节点恢复为online，返回恢复的设备数
+// 设备仍上报故障时下一轮采集会重新隔离节点
+func (r *GPUNodeRemediator) UncordonNode(ctx context.Context, node *model.VtGpuNodes) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cluster, err := r.clusterModel.FindOne(node.ClusterId)
+	if err != nil {
+		return 0, fmt.Errorf("查询GPU集群失败: %w", err)
+	}
+	if cluster.ClusterType == model.GpuClusterTypeK8s {
+		manager, err := r.clients(cluster)
+		if err != nil {
+			return 0, fmt.Errorf("连接集群失败: %w", err)
+		}
+		if err := manager.UncordonNode(ctx, node.Name); err != nil {
+			return 0, err
+		}
+	}
+
+	devices, err := r.deviceModel.FindAllByClusterId(node.ClusterId)
+	if err != nil {
+		return 0, fmt.Errorf("查询GPU设备失败: %w", err)
+	}
+	recovered := 0
+	for _, device := range devices {
+		if device.NodeId != node.Id || device.HealthStatus != model.GpuHealthUnhealthy {
+			continue
+		}
+		if err := r.deviceModel.UpdateHealth(device.Id, model.GpuHealthHealthy); err != nil {
+			return recovered, fmt.Errorf("更新GPU设备健康状态失败: %w", err)
+		}
+		recovered++
+	}
+
+	// 节点就绪状态由下一次清单同步更新
+	if node.Status == model.GpuNodeStatusMaintenance {
+		node.Status = model.GpuNodeStatusOnline
+		if err := r.nodeModel.Update(node); err != nil {
+			return recovered, fmt.Errorf("更新GPU节点状态失败: %w", err)
+		}
+	}
+
+	return recovered, nil
+}
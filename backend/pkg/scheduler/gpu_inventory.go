@@ -0,0 +1,381 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"api/model"
+	"api/pkg/volcano"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// GPUClusterClient 返回访问登记集群的GPU管理器
+type GPUClusterClient func(cluster *model.VtGpuClusters) (*volcano.GPUManager, error)
+
+// GPUInventoryConfig GPU清单同步配置
+type GPUInventoryConfig struct {
+	Interval time.Duration // 同步间隔
+}
+
+// GPUInventoryReport 单个集群的同步结果
+type GPUInventoryReport struct {
+	ClusterId      int64
+	Nodes          int // 本次发现的GPU节点数
+	NodesCreated   int
+	NodesOffline   int // 本次标记为离线的节点数
+	Devices        int // 本次发现的GPU设备数
+	DevicesCreated int
+	DevicesOffline int // 本次标记为离线的设备数
+}
+
+// GPUInventorySyncer GPU清单同步
+// 定期读取各K8s集群中带nvidia.com/gpu资源的节点，按节点名更新vt_gpu_nodes，按节点和设备索引更新vt_gpu_devices；
+// 集群中已不存在的节点及其设备标记为offline而不删除，保留分配和使用记录的关联
+type GPUInventorySyncer struct {
+	clusterModel model.VtGpuClustersModel
+	nodeModel    model.VtGpuNodesModel
+	deviceModel  model.VtGpuDevicesModel
+	clients      GPUClusterClient
+	config       GPUInventoryConfig
+	logger       logx.Logger
+
+	mu     sync.Mutex // 定期同步和手动同步不并发执行
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewGPUInventorySyncer 创建GPU清单同步
+func NewGPUInventorySyncer(clusterModel model.VtGpuClustersModel, nodeModel model.VtGpuNodesModel, deviceModel model.VtGpuDevicesModel,
+	clients GPUClusterClient, config GPUInventoryConfig) *GPUInventorySyncer {
+	if config.Interval <= 0 {
+		config.Interval = 5 * time.Minute
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &GPUInventorySyncer{
+		clusterModel: clusterModel,
+		nodeModel:    nodeModel,
+		deviceModel:  deviceModel,
+		clients:      clients,
+		config:       config,
+		logger:       logx.WithContext(context.Background()),
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// Start 启动同步循环
+func (s *GPUInventorySyncer) Start() {
+	s.logger.Infof("启动GPU清单同步，同步间隔: %v", s.config.Interval)
+
+	s.wg.Add(1)
+	go s.loop()
+}
+
+// Stop 停止同步循环
+func (s *GPUInventorySyncer) Stop() {
+	s.cancel()
+	s.wg.Wait()
+	s.logger.Info("GPU清单同步已停止")
+}
+
+// loop 同步循环
+func (s *GPUInventorySyncer) loop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.SyncOnce(time.Now()); err != nil {
+			s.logger.Errorf("GPU清单同步失败: %v", err)
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncOnce 同步所有启用的K8s集群，返回同步成功的集群数
+func (s *GPUInventorySyncer) SyncOnce(now time.Time) (int, error) {
+	clusters, err := s.clusterModel.FindAllByType(model.GpuClusterTypeK8s, model.GpuClusterStatusActive)
+	if err != nil {
+		return 0, err
+	}
+
+	synced := 0
+	for _, cluster := range clusters {
+		report, err := s.SyncCluster(s.ctx, cluster, now)
+		if err != nil {
+			s.logger.Errorf("同步GPU集群清单失败: ID=%d, %s, %v", cluster.Id, cluster.Name, err)
+			continue
+		}
+		synced++
+		if report.NodesCreated > 0 || report.NodesOffline > 0 || report.DevicesCreated > 0 || report.DevicesOffline > 0 {
+			s.logger.Infof("GPU集群清单已同步: ID=%d, 节点%d个(新增%d, 离线%d), 设备%d个(新增%d, 离线%d)", cluster.Id,
+				report.Nodes, report.NodesCreated, report.NodesOffline, report.Devices, report.DevicesCreated, report.DevicesOffline)
+		}
+	}
+	return synced, nil
+}
+
+// SyncCluster 同步单个集群的节点和设备，并更新集群的节点数和GPU数
+func (s *GPUInventorySyncer) SyncCluster(ctx context.Context, cluster *model.VtGpuClusters, now time.Time) (*GPUInventoryReport, error) {
+	manager, err := s.clients(cluster)
+	if err != nil {
+		return nil, fmt.Errorf("连接集群失败: %w", err)
+	}
+	inventories, err := manager.DiscoverGPUNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nodes, err := s.nodeModel.FindAllByClusterId(cluster.Id)
+	if err != nil {
+		return nil, fmt.Errorf("查询集群节点失败: %w", err)
+	}
+	devices, err := s.deviceModel.FindAllByClusterId(cluster.Id)
+	if err != nil {
+		return nil, fmt.Errorf("查询集群设备失败: %w", err)
+	}
+	existingNodes := make(map[string]*model.VtGpuNodes, len(nodes))
+	for _, node := range nodes {
+		existingNodes[node.Name] = node
+	}
+	nodeDevices := make(map[int64][]*model.VtGpuDevices)
+	for _, device := range devices {
+		nodeDevices[device.NodeId] = append(nodeDevices[device.NodeId], device)
+	}
+
+	report := &GPUInventoryReport{ClusterId: cluster.Id}
+	seen := make(map[string]bool, len(inventories))
+	for i := range inventories {
+		inventory := &inventories[i]
+		seen[inventory.NodeName] = true
+		existing := existingNodes[inventory.NodeName]
+		var devices []*model.VtGpuDevices
+		if existing != nil {
+			devices = nodeDevices[existing.Id]
+		}
+		node, err := s.syncNode(cluster, existing, inventory, devices, now, report)
+		if err != nil {
+			return nil, fmt.Errorf("同步节点 %s 失败: %w", inventory.NodeName, err)
+		}
+		if existing == nil {
+			nodes = append(nodes, node)
+		}
+	}
+
+	for _, node := range nodes {
+		if seen[node.Name] || node.Status == model.GpuNodeStatusOffline {
+			continue
+		}
+		if err := s.offlineNode(node, nodeDevices[node.Id], report); err != nil {
+			return nil, fmt.Errorf("标记节点 %s 离线失败: %w", node.Name, err)
+		}
+	}
+
+	if err := s.updateCluster(cluster, nodes); err != nil {
+		return nil, fmt.Errorf("更新集群统计失败: %w", err)
+	}
+	return report, nil
+}
+
+// syncNode 新增或更新节点及其设备，返回节点记录
+func (s *GPUInventorySyncer) syncNode(cluster *model.VtGpuClusters, node *model.VtGpuNodes, inventory *volcano.GPUNodeInventory,
+	devices []*model.VtGpuDevices, now time.Time, report *GPUInventoryReport) (*model.VtGpuNodes, error) {
+	created := node == nil
+	if created {
+		// K8s节点信息无法区分物理机与虚拟机，新发现的节点按默认的physical记录
+		node = &model.VtGpuNodes{ClusterId: cluster.Id, Name: inventory.NodeName, NodeType: "physical"}
+	}
+
+	labels, err := json.Marshal(inventory.Labels)
+	if err != nil {
+		return nil, err
+	}
+	taints, err := json.Marshal(inventory.Taints)
+	if err != nil {
+		return nil, err
+	}
+	node.Hostname = inventory.Hostname
+	node.InternalIp = inventory.InternalIP
+	node.ExternalIp = inventory.ExternalIP
+	node.CpuCores = inventory.CPUCores
+	node.MemoryGb = inventory.MemoryGB
+	node.StorageGb = inventory.StorageGB
+	node.OsImage = inventory.OSImage
+	node.KernelVersion = inventory.KernelVersion
+	node.NodeLabels = string(labels)
+	node.NodeTaints = string(taints)
+	node.LastHeartbeat = &now
+	if node.Status != model.GpuNodeStatusMaintenance {
+		node.Status = model.GpuNodeStatusNotReady
+		if inventory.Ready() {
+			node.Status = model.GpuNodeStatusOnline
+		}
+	}
+
+	planned := planDevices(cluster, node, inventory, devices, now, report)
+	node.GpuCount = len(inventory.Devices)
+	node.AvailableGpus = 0
+	for _, device := range planned {
+		if device.Status == model.GpuDeviceStatusAvailable {
+			node.AvailableGpus++
+		}
+	}
+
+	if created {
+		result, err := s.nodeModel.Insert(node)
+		if err != nil {
+			return nil, err
+		}
+		if node.Id, err = result.LastInsertId(); err != nil {
+			return nil, err
+		}
+		report.NodesCreated++
+	} else if err := s.nodeModel.Update(node); err != nil {
+		return nil, err
+	}
+	report.Nodes++
+
+	for _, device := range planned {
+		device.NodeId = node.Id
+		if device.Id > 0 {
+			if err := s.deviceModel.Update(device); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		result, err := s.deviceModel.Insert(device)
+		if err != nil {
+			return nil, err
+		}
+		if device.Id, err = result.LastInsertId(); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+// planDevices 按节点清单计算设备记录：已有设备更新型号和版本信息，新设备以available状态加入，
+// 节点不再上报的设备标记为offline；已分配、维护中等状态由其他流程管理，同步时保持不变
+func planDevices(cluster *model.VtGpuClusters, node *model.VtGpuNodes, inventory *volcano.GPUNodeInventory,
+	devices []*model.VtGpuDevices, now time.Time, report *GPUInventoryReport) []*model.VtGpuDevices {
+	byIndex := make(map[int]*model.VtGpuDevices, len(devices))
+	for _, device := range devices {
+		byIndex[device.DeviceIndex] = device
+	}
+
+	planned := make([]*model.VtGpuDevices, 0, len(inventory.Devices))
+	for _, found := range inventory.Devices {
+		device := byIndex[found.Index]
+		delete(byIndex, found.Index)
+		if device == nil {
+			device = &model.VtGpuDevices{
+				ClusterId:    cluster.Id,
+				DeviceIndex:  found.Index,
+				DeviceName:   fmt.Sprintf("%s-gpu%d", node.Name, found.Index),
+				Brand:        "nvidia",
+				Status:       model.GpuDeviceStatusAvailable,
+				HealthStatus: model.GpuHealthUnknown,
+			}
+			if inventory.Ready() {
+				device.HealthStatus = model.GpuHealthHealthy
+			}
+			report.DevicesCreated++
+		} else if device.Status == model.GpuDeviceStatusOffline {
+			// 离线期间未释放的分配仍然有效，设备恢复为已分配
+			device.Status = model.GpuDeviceStatusAvailable
+			if device.AllocationId > 0 {
+				device.Status = model.GpuDeviceStatusOccupied
+			}
+		}
+
+		if found.UUID != "" {
+			device.DeviceUuid = found.UUID
+		}
+		device.Model = found.Model
+		device.Architecture = found.Architecture
+		device.MemoryTotalMb = found.MemoryTotalMB
+		device.DriverVersion = found.DriverVersion
+		device.CudaVersion = found.CUDAVersion
+		device.SharingMode = found.SharingMode
+		device.ShareCapacity = found.ShareCapacity
+		device.MigDevices = migDevicesJSON(found.MIGDevices)
+		device.LastHeartbeat = &now
+		planned = append(planned, device)
+		report.Devices++
+	}
+
+	for _, device := range devices {
+		if byIndex[device.DeviceIndex] == device && device.Status != model.GpuDeviceStatusOffline {
+			device.Status = model.GpuDeviceStatusOffline
+			planned = append(planned, device)
+			report.DevicesOffline++
+		}
+	}
+	return planned
+}
+
+// migDevicesJSON 序列化各MIG规格的实例数，未切分时为空
+func migDevicesJSON(profiles map[string]int32) string {
+	if len(profiles) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(profiles)
+	return string(data)
+}
+
+// offlineNode 将集群中已不存在的节点及其设备标记为离线
+func (s *GPUInventorySyncer) offlineNode(node *model.VtGpuNodes, devices []*model.VtGpuDevices, report *GPUInventoryReport) error {
+	for _, device := range devices {
+		if device.Status == model.GpuDeviceStatusOffline {
+			continue
+		}
+		if err := s.deviceModel.UpdateStatus(device.Id, model.GpuDeviceStatusOffline); err != nil {
+			return err
+		}
+		device.Status = model.GpuDeviceStatusOffline
+		report.DevicesOffline++
+	}
+
+	node.Status = model.GpuNodeStatusOffline
+	node.AvailableGpus = 0
+	if err := s.nodeModel.Update(node); err != nil {
+		return err
+	}
+	report.NodesOffline++
+	s.logger.Infof("GPU节点已离线: 集群ID=%d, 节点=%s", node.ClusterId, node.Name)
+	return nil
+}
+
+// updateCluster 按节点记录更新集群的节点数和GPU数，离线节点的GPU不计入
+func (s *GPUInventorySyncer) updateCluster(cluster *model.VtGpuClusters, nodes []*model.VtGpuNodes) error {
+	cluster.TotalNodes = len(nodes)
+	cluster.ActiveNodes = 0
+	cluster.TotalGpus = 0
+	cluster.AvailableGpus = 0
+	for _, node := range nodes {
+		if node.Status == model.GpuNodeStatusOffline {
+			continue
+		}
+		cluster.TotalGpus += node.GpuCount
+		if node.Status == model.GpuNodeStatusOnline {
+			cluster.ActiveNodes++
+			cluster.AvailableGpus += node.AvailableGpus
+		}
+	}
+	return s.clusterModel.Update(cluster)
+}
@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"api/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// GPULeaseConfig GPU分配租约检查配置
+type GPULeaseConfig struct {
+	Interval  time.Duration // 检查间隔
+	BatchSize int           // 每轮最多释放的分配数
+}
+
+// GPULeaseExpirer GPU分配租约检查
+// 分配设置了expected_duration_seconds时，从allocated_at起超过该时长仍未释放的分配标记为expired并释放设备
+type GPULeaseExpirer struct {
+	allocationModel model.VtGpuDeviceAllocationsModel
+	config          GPULeaseConfig
+	logger          logx.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewGPULeaseExpirer 创建GPU分配租约检查
+func NewGPULeaseExpirer(allocationModel model.VtGpuDeviceAllocationsModel, config GPULeaseConfig) *GPULeaseExpirer {
+	if config.Interval <= 0 {
+		config.Interval = time.Minute
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &GPULeaseExpirer{
+		allocationModel: allocationModel,
+		config:          config,
+		logger:          logx.WithContext(context.Background()),
+		ctx:             ctx,
+		cancel:          cancel,
+	}
+}
+
+// Start 启动检查循环
+func (e *GPULeaseExpirer) Start() {
+	e.logger.Infof("启动GPU分配租约检查，检查间隔: %v", e.config.Interval)
+
+	e.wg.Add(1)
+	go e.loop()
+}
+
+// Stop 停止检查循环
+func (e *GPULeaseExpirer) Stop() {
+	e.cancel()
+	e.wg.Wait()
+	e.logger.Info("GPU分配租约检查已停止")
+}
+
+// loop 检查循环
+func (e *GPULeaseExpirer) loop() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := e.CheckOnce(time.Now()); err != nil {
+			e.logger.Errorf("GPU分配租约检查失败: %v", err)
+		}
+
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckOnce 释放租约已到期的分配，返回本轮释放的分配数
+func (e *GPULeaseExpirer) CheckOnce(now time.Time) (int, error) {
+	allocations, err := e.allocationModel.FindExpired(now, e.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, allocation := range allocations {
+		_, err := e.allocationModel.Release(allocation.Id, model.GpuAllocationStatusExpired, now)
+		if err == model.ErrGpuAllocationNotActive {
+			// 查询后已被手动释放
+			continue
+		}
+		if err != nil {
+			e.logger.Errorf("释放到期的GPU分配失败: ID=%d, 设备ID=%d, %v", allocation.Id, allocation.DeviceId, err)
+			continue
+		}
+		e.logger.Infof("GPU分配租约已到期并释放: ID=%d, 设备ID=%d, 作业ID=%d, 分配于%s, 时长%ds",
+			allocation.Id, allocation.DeviceId, allocation.EntityId, allocation.AllocatedAt.Format(time.RFC3339), allocation.ExpectedDurationSeconds)
+		expired++
+	}
+	return expired, nil
+}
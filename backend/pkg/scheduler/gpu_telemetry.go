@@ -0,0 +1,482 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"api/model"
+	"api/pkg/monitoring"
+	"api/pkg/volcano"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 写入vt_monitor_data的GPU指标，指标定义需预先登记在vt_monitor_metrics中
+const (
+	GPUUsageMetric       = "gpu_usage_percent"
+	GPUMemoryUsageMetric = "gpu_memory_usage_percent"
+	GPUMemoryUsedMetric  = "gpu_memory_used_mb"
+	GPUPowerMetric       = "gpu_power_watts"
+	GPUTemperatureMetric = "gpu_temperature_celsius"
+)
+
+// GPUTelemetryConfig GPU监控数据采集配置
+type GPUTelemetryConfig struct {
+	Interval   time.Duration // 采集间隔
+	StaleAfter time.Duration // 节点汇总数据超过该时长未更新视为无数据
+}
+
+// GPUTelemetryReport 单轮采集结果
+type GPUTelemetryReport struct {
+	Samples   int // DCGM返回的GPU数
+	Matched   int // 匹配到设备记录的GPU数
+	Unmatched int
+	Points    int // 写入vt_monitor_data的数据点数
+	Faults    int // 检测到XID或ECC错误的GPU数
+	Pods      int // 写入实例GPU使用率的Pod数
+}
+
+// GPUFault DCGM检测到的设备故障
+type GPUFault struct {
+	DeviceId  int64
+	ClusterId int64
+	NodeId    int64
+	NodeName  string
+	Index     int
+	Reason    string
+}
+
+// GPUFaultHandler 处理一轮采集中检测到的设备故障，同一故障在恢复前每轮都会上报
+type GPUFaultHandler interface {
+	HandleGPUFaults(faults []GPUFault) error
+}
+
+// InstanceGPUUsageRecorder 按Pod记录训练作业实例的GPU使用率，供看门狗判断作业是否空闲
+type InstanceGPUUsageRecorder interface {
+	UpdateGpuUsageByPod(namespace, podName string, gpuPercent float64, sampledAt time.Time) error
+}
+
+// podUsage Pod在一轮采集中各GPU的最大使用率
+type podUsage struct {
+	namespace string
+	pod       string
+	percent   float64
+}
+
+// nodeTelemetry 节点最近一次的GPU汇总数据
+type nodeTelemetry struct {
+	stats       volcano.NodeGPUStats
+	collectedAt time.Time
+}
+
+// GPUTelemetryCollector GPU监控数据采集
+// 定期从DCGM exporter读取各GPU的使用率、显存、功耗和温度，依次按UUID、节点和PCIe总线ID、节点和设备索引匹配vt_gpu_devices，
+// 更新设备的监控字段和last_heartbeat并写入vt_monitor_data；同时按节点汇总供GPUManager查询。
+// 上报严重XID或ECC错误的设备标记为unhealthy并交给GPUFaultHandler处理
+type GPUTelemetryCollector struct {
+	source       monitoring.DCGMSource
+	deviceModel  model.VtGpuDevicesModel
+	nodeModel    model.VtGpuNodesModel
+	metricsModel model.VtMonitorMetricsModel
+	dataModel    model.VtMonitorDataModel
+	config       GPUTelemetryConfig
+	faults       GPUFaultHandler
+	instances    InstanceGPUUsageRecorder
+	logger       logx.Logger
+
+	mu        sync.Mutex
+	metricIds map[string]int64 // 指标名到指标ID，0表示指标未登记
+	nodes     map[string]nodeTelemetry
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewGPUTelemetryCollector 创建GPU监控数据采集
+func NewGPUTelemetryCollector(source monitoring.DCGMSource, deviceModel model.VtGpuDevicesModel, nodeModel model.VtGpuNodesModel,
+	metricsModel model.VtMonitorMetricsModel, dataModel model.VtMonitorDataModel, config GPUTelemetryConfig) *GPUTelemetryCollector {
+	if config.Interval <= 0 {
+		config.Interval = 30 * time.Second
+	}
+	if config.StaleAfter <= 0 {
+		config.StaleAfter = 3 * config.Interval
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &GPUTelemetryCollector{
+		source:       source,
+		deviceModel:  deviceModel,
+		nodeModel:    nodeModel,
+		metricsModel: metricsModel,
+		dataModel:    dataModel,
+		config:       config,
+		logger:       logx.WithContext(context.Background()),
+		metricIds:    make(map[string]int64),
+		nodes:        make(map[string]nodeTelemetry),
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// SetFaultHandler 设置设备故障的处理，未设置时只将故障设备标记为unhealthy
+func (c *GPUTelemetryCollector) SetFaultHandler(handler GPUFaultHandler) {
+	c.faults = handler
+}
+
+// SetInstanceUsageRecorder 设置实例GPU使用率的记录，exporter开启Kubernetes映射后按Pod写入各GPU的最大使用率
+func (c *GPUTelemetryCollector) SetInstanceUsageRecorder(recorder InstanceGPUUsageRecorder) {
+	c.instances = recorder
+}
+
+// Start 启动采集循环
+func (c *GPUTelemetryCollector) Start() {
+	c.logger.Infof("启动GPU监控数据采集，采集间隔: %v", c.config.Interval)
+
+	c.wg.Add(1)
+	go c.loop()
+}
+
+// Stop 停止采集循环
+func (c *GPUTelemetryCollector) Stop() {
+	c.cancel()
+	c.wg.Wait()
+	c.logger.Info("GPU监控数据采集已停止")
+}
+
+// loop 采集循环
+func (c *GPUTelemetryCollector) loop() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := c.CollectOnce(time.Now()); err != nil {
+			c.logger.Errorf("GPU监控数据采集失败: %v", err)
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// NodeGPUStats 返回节点最近一次采集的GPU汇总数据，实现volcano.GPUTelemetry
+func (c *GPUTelemetryCollector) NodeGPUStats(nodeName string) (volcano.NodeGPUStats, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	node, ok := c.nodes[nodeName]
+	if !ok || time.Since(node.collectedAt) > c.config.StaleAfter {
+		return volcano.NodeGPUStats{}, false
+	}
+	return node.stats, true
+}
+
+// CollectOnce 采集一轮GPU监控数据
+func (c *GPUTelemetryCollector) CollectOnce(now time.Time) (*GPUTelemetryReport, error) {
+	samples, err := c.source.Samples(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+	devices, err := c.deviceModel.FindAllOnline()
+	if err != nil {
+		return nil, fmt.Errorf("查询GPU设备失败: %v", err)
+	}
+	matcher, err := c.newDeviceMatcher(devices)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &GPUTelemetryReport{Samples: len(samples)}
+	var points []*model.VtMonitorData
+	var faults []GPUFault
+	nodeSamples := make(map[string][]*monitoring.DCGMSample)
+	pods := make(map[string]*podUsage)
+	for i := range samples {
+		sample := &samples[i]
+		if value, ok := sample.Value(monitoring.DCGMGPUUtil); ok && sample.Pod != "" {
+			key := sample.Namespace + "/" + sample.Pod
+			if usage, ok := pods[key]; !ok {
+				pods[key] = &podUsage{namespace: sample.Namespace, pod: sample.Pod, percent: value}
+			} else if value > usage.percent {
+				usage.percent = value
+			}
+		}
+
+		device, nodeName := matcher.match(sample)
+		if device == nil {
+			report.Unmatched++
+			c.logger.Debugf("DCGM指标未匹配到GPU设备: 节点=%s, 索引=%d, UUID=%s, PCIe=%s", sample.Hostname, sample.GPUIndex, sample.UUID, sample.PCIBusID)
+			nodeSamples[sample.Hostname] = append(nodeSamples[sample.Hostname], sample)
+			continue
+		}
+		report.Matched++
+		nodeSamples[nodeName] = append(nodeSamples[nodeName], sample)
+
+		applyDCGMSample(device, sample, now)
+		if err := c.deviceModel.UpdateTelemetry(device); err != nil {
+			c.logger.Errorf("更新GPU设备监控数据失败: ID=%d, %v", device.Id, err)
+			continue
+		}
+		points = append(points, c.monitorPoints(device, nodeName, sample, now)...)
+
+		if reason := sample.FaultReason(); reason != "" {
+			if device.HealthStatus != model.GpuHealthUnhealthy {
+				if err := c.deviceModel.UpdateHealth(device.Id, model.GpuHealthUnhealthy); err != nil {
+					c.logger.Errorf("标记GPU设备故障失败: ID=%d, %v", device.Id, err)
+					continue
+				}
+				c.logger.Errorf("GPU设备故障: 节点=%s, 设备=%s, %s", nodeName, device.DeviceName, reason)
+			}
+			faults = append(faults, GPUFault{DeviceId: device.Id, ClusterId: device.ClusterId, NodeId: device.NodeId,
+				NodeName: nodeName, Index: device.DeviceIndex, Reason: reason})
+		}
+	}
+	report.Faults = len(faults)
+
+	if len(points) > 0 {
+		if err := c.dataModel.BatchInsert(points); err != nil {
+			return report, fmt.Errorf("写入GPU监控数据失败: %v", err)
+		}
+		report.Points = len(points)
+	}
+
+	if c.instances != nil {
+		for _, usage := range pods {
+			if err := c.instances.UpdateGpuUsageByPod(usage.namespace, usage.pod, usage.percent, now); err != nil {
+				c.logger.Errorf("更新实例GPU使用率失败: Pod=%s/%s, %v", usage.namespace, usage.pod, err)
+				continue
+			}
+			report.Pods++
+		}
+	}
+
+	c.mu.Lock()
+	for nodeName, samples := range nodeSamples {
+		c.nodes[nodeName] = nodeTelemetry{stats: summarizeNode(samples), collectedAt: now}
+	}
+	c.mu.Unlock()
+
+	if len(faults) > 0 && c.faults != nil {
+		if err := c.faults.HandleGPUFaults(faults); err != nil {
+			return report, fmt.Errorf("处理GPU设备故障失败: %v", err)
+		}
+	}
+	return report, nil
+}
+
+// deviceMatcher 按UUID、节点和PCIe总线ID、节点和设备索引查找设备
+type deviceMatcher struct {
+	byUUID    map[string]*model.VtGpuDevices
+	byPCI     map[string]*model.VtGpuDevices
+	byIndex   map[string]*model.VtGpuDevices
+	nodeNames map[int64]string
+}
+
+func (c *GPUTelemetryCollector) newDeviceMatcher(devices []*model.VtGpuDevices) (*deviceMatcher, error) {
+	m := &deviceMatcher{
+		byUUID:    make(map[string]*model.VtGpuDevices),
+		byPCI:     make(map[string]*model.VtGpuDevices),
+		byIndex:   make(map[string]*model.VtGpuDevices),
+		nodeNames: make(map[int64]string),
+	}
+	for _, device := range devices {
+		nodeName, ok := m.nodeNames[device.NodeId]
+		if !ok {
+			node, err := c.nodeModel.FindOne(device.NodeId)
+			if err != nil && err != sql.ErrNoRows {
+				return nil, fmt.Errorf("查询GPU节点失败: %v", err)
+			}
+			if node != nil {
+				nodeName = node.Name
+			}
+			m.nodeNames[device.NodeId] = nodeName
+		}
+
+		if device.DeviceUuid != "" {
+			m.byUUID[device.DeviceUuid] = device
+		}
+		if device.PcieBusId != "" {
+			m.byPCI[nodeName+"/"+monitoring.NormalizePCIBusID(device.PcieBusId)] = device
+		}
+		m.byIndex[fmt.Sprintf("%s/%d", nodeName, device.DeviceIndex)] = device
+	}
+	return m, nil
+}
+
+// match 返回匹配的设备及其所在节点名
+func (m *deviceMatcher) match(sample *monitoring.DCGMSample) (*model.VtGpuDevices, string) {
+	device := m.byUUID[sample.UUID]
+	if device == nil && sample.PCIBusID != "" {
+		device = m.byPCI[sample.Hostname+"/"+sample.PCIBusID]
+	}
+	if device == nil {
+		device = m.byIndex[fmt.Sprintf("%s/%d", sample.Hostname, sample.GPUIndex)]
+	}
+	if device == nil {
+		return nil, ""
+	}
+	return device, m.nodeNames[device.NodeId]
+}
+
+// applyDCGMSample 将DCGM指标写入设备记录，exporter未导出的指标保持原值
+func applyDCGMSample(device *model.VtGpuDevices, sample *monitoring.DCGMSample, now time.Time) {
+	if device.DeviceUuid == "" {
+		device.DeviceUuid = sample.UUID
+	}
+	if device.PcieBusId == "" {
+		device.PcieBusId = sample.PCIBusID
+	}
+	if value, ok := sample.Value(monitoring.DCGMGPUUtil); ok {
+		device.UtilizationGpu = roundInt(value)
+	}
+	if value, ok := sample.Value(monitoring.DCGMMemCopyUtil); ok {
+		device.UtilizationMem = roundInt(value)
+	}
+	used, hasUsed := sample.Value(monitoring.DCGMFBUsed)
+	free, hasFree := sample.Value(monitoring.DCGMFBFree)
+	if hasUsed {
+		device.MemoryUsedMb = roundInt(used)
+	}
+	if hasFree {
+		device.MemoryFreeMb = roundInt(free)
+	}
+	if hasUsed && hasFree && device.MemoryTotalMb == 0 {
+		device.MemoryTotalMb = roundInt(used + free)
+	}
+	if value, ok := sample.Value(monitoring.DCGMPowerUsage); ok {
+		device.PowerDrawW = roundInt(value)
+	}
+	if value, ok := sample.Value(monitoring.DCGMGPUTemp); ok {
+		device.TemperatureC = roundInt(value)
+	}
+	device.LastHeartbeat = &now
+}
+
+// monitorPoints 生成设备的监控数据点，指标未登记时跳过
+func (c *GPUTelemetryCollector) monitorPoints(device *model.VtGpuDevices, nodeName string, sample *monitoring.DCGMSample, now time.Time) []*model.VtMonitorData {
+	values := make(map[string]float64)
+	if value, ok := sample.Value(monitoring.DCGMGPUUtil); ok {
+		values[GPUUsageMetric] = value
+	}
+	used, hasUsed := sample.Value(monitoring.DCGMFBUsed)
+	free, hasFree := sample.Value(monitoring.DCGMFBFree)
+	if hasUsed {
+		values[GPUMemoryUsedMetric] = used
+	}
+	if hasUsed && hasFree && used+free > 0 {
+		values[GPUMemoryUsageMetric] = used / (used + free) * 100
+	}
+	if value, ok := sample.Value(monitoring.DCGMPowerUsage); ok {
+		values[GPUPowerMetric] = value
+	}
+	if value, ok := sample.Value(monitoring.DCGMGPUTemp); ok {
+		values[GPUTemperatureMetric] = value
+	}
+
+	labels := map[string]interface{}{
+		"cluster_id": device.ClusterId,
+		"node":       nodeName,
+		"gpu":        device.DeviceIndex,
+		"uuid":       device.DeviceUuid,
+		"model":      sample.ModelName,
+	}
+	if sample.Pod != "" {
+		labels["pod"] = sample.Pod
+		labels["namespace"] = sample.Namespace
+	}
+
+	var points []*model.VtMonitorData
+	for _, name := range []string{GPUUsageMetric, GPUMemoryUsageMetric, GPUMemoryUsedMetric, GPUPowerMetric, GPUTemperatureMetric} {
+		value, ok := values[name]
+		if !ok {
+			continue
+		}
+		metricId := c.metricId(name)
+		if metricId == 0 {
+			continue
+		}
+		resourceId := device.Id
+		point := &model.VtMonitorData{
+			MetricId:       metricId,
+			ResourceType:   "gpu",
+			ResourceId:     &resourceId,
+			ResourceName:   device.DeviceName,
+			InstanceId:     device.DeviceUuid,
+			Value:          value,
+			Timestamp:      now,
+			CollectionTime: now,
+			QualityScore:   1.0,
+		}
+		if err := point.SetLabels(labels); err != nil {
+			c.logger.Errorf("设置GPU监控数据标签失败: %v", err)
+		}
+		points = append(points, point)
+	}
+	return points
+}
+
+// metricId 查询并缓存指标ID，指标未登记时只记录一次日志
+func (c *GPUTelemetryCollector) metricId(name string) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if id, ok := c.metricIds[name]; ok {
+		return id
+	}
+	metric, err := c.metricsModel.FindOneByName(name)
+	if err == sql.ErrNoRows {
+		c.logger.Errorf("监控指标%s未登记，不写入该指标的GPU监控数据", name)
+		c.metricIds[name] = 0
+		return 0
+	}
+	if err != nil {
+		c.logger.Errorf("查询监控指标失败 [%s]: %v", name, err)
+		return 0
+	}
+	c.metricIds[name] = metric.Id
+	return metric.Id
+}
+
+// summarizeNode 汇总节点各GPU的监控数据
+func summarizeNode(samples []*monitoring.DCGMSample) volcano.NodeGPUStats {
+	var stats volcano.NodeGPUStats
+	utilized := 0
+	for _, sample := range samples {
+		if value, ok := sample.Value(monitoring.DCGMGPUUtil); ok {
+			stats.Utilization += value
+			utilized++
+		}
+		if value, ok := sample.Value(monitoring.DCGMFBUsed); ok {
+			stats.MemoryUsedMB += value
+		}
+		if value, ok := sample.Value(monitoring.DCGMFBFree); ok {
+			stats.MemoryFreeMB += value
+		}
+		if value, ok := sample.Value(monitoring.DCGMPowerUsage); ok {
+			stats.PowerDrawW += value
+		}
+		if value, ok := sample.Value(monitoring.DCGMGPUTemp); ok && value > stats.MaxTemperatureC {
+			stats.MaxTemperatureC = value
+		}
+		if reason := sample.FaultReason(); reason != "" {
+			stats.Faults = append(stats.Faults, fmt.Sprintf("GPU %d: %s", sample.GPUIndex, reason))
+		}
+	}
+	if utilized > 0 {
+		stats.Utilization /= float64(utilized)
+	}
+	return stats
+}
+
+func roundInt(value float64) int {
+	return int(math.Round(value))
+}
@@ -0,0 +1,335 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"api/model"
+	bizerrors "api/pkg/errors"
+	"api/pkg/volcano"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// DispatcherConfig 作业派发器配置
+type DispatcherConfig struct {
+	Namespace         string        // 默认提交的命名空间
+	Interval          time.Duration // 轮询间隔
+	BatchSize         int           // 每轮最多派发的作业数
+	MaxSubmitAttempts int           // 瞬时错误的最大重试次数，0表示不限制
+	BackoffBase       time.Duration // 重试退避基础时长
+	BackoffMax        time.Duration // 重试退避最大时长
+
+	MetricsEndpoint    string // 训练容器访问指标上报接口的服务地址
+	MetricsTokenSecret string // 生成作业指标上报令牌的密钥，为空时不注入令牌
+}
+
+// submitAttempt 单个作业的提交重试状态
+type submitAttempt struct {
+	count       int
+	nextAttempt time.Time
+}
+
+// JobDispatcher 训练作业派发器
+// 以数据库中的作业状态作为持久化队列，将pending作业提交到Volcano，
+// 进程重启后会继续处理已入队但尚未提交成功的作业
+type JobDispatcher struct {
+	jobModel   model.VtTrainingJobsModel
+	machine    *JobStateMachine
+	jobManager *volcano.JobManager
+	pipeline   *JobPipeline
+	config     DispatcherConfig
+	logger     logx.Logger
+
+	mu       sync.Mutex
+	attempts map[int64]*submitAttempt
+
+	wakeCh chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewJobDispatcher 创建作业派发器，pipeline为nil时不检查作业依赖
+func NewJobDispatcher(jobModel model.VtTrainingJobsModel, machine *JobStateMachine, jobManager *volcano.JobManager, pipeline *JobPipeline, config DispatcherConfig) *JobDispatcher {
+	if config.Interval <= 0 {
+		config.Interval = 5 * time.Second
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 20
+	}
+	if config.BackoffBase <= 0 {
+		config.BackoffBase = 2 * time.Second
+	}
+	if config.BackoffMax <= 0 {
+		config.BackoffMax = 5 * time.Minute
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &JobDispatcher{
+		jobModel:   jobModel,
+		machine:    machine,
+		jobManager: jobManager,
+		pipeline:   pipeline,
+		config:     config,
+		logger:     logx.WithContext(context.Background()),
+		attempts:   make(map[int64]*submitAttempt),
+		wakeCh:     make(chan struct{}, 1),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Start 启动派发循环
+func (d *JobDispatcher) Start() {
+	d.logger.Infof("启动训练作业派发器，轮询间隔: %v", d.config.Interval)
+
+	d.wg.Add(1)
+	go d.dispatchLoop()
+}
+
+// Stop 停止派发循环
+func (d *JobDispatcher) Stop() {
+	d.cancel()
+	d.wg.Wait()
+	d.logger.Info("训练作业派发器已停止")
+}
+
+// Notify 通知派发器立即处理待派发作业
+func (d *JobDispatcher) Notify() {
+	select {
+	case d.wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+// dispatchLoop 派发循环
+func (d *JobDispatcher) dispatchLoop() {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.DispatchOnce(); err != nil {
+			d.logger.Errorf("派发训练作业失败: %v", err)
+		}
+
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wakeCh:
+		}
+	}
+}
+
+// DispatchOnce 执行一轮派发，返回成功提交到Volcano的作业数
+func (d *JobDispatcher) DispatchOnce() (int, error) {
+	jobs, err := d.jobModel.FindDispatchable(d.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	submitted := 0
+	for _, job := range jobs {
+		if d.ctx.Err() != nil {
+			break
+		}
+		if !d.readyForAttempt(job.Id) {
+			continue
+		}
+		if d.dispatchJob(job) {
+			submitted++
+		}
+	}
+
+	return submitted, nil
+}
+
+// dispatchJob 派发单个作业，返回是否提交成功
+func (d *JobDispatcher) dispatchJob(job *model.VtTrainingJobs) bool {
+	// 查询与认领之间上游作业可能被重新运行，派发前再次确认依赖并取得上游输出
+	var upstreamEnv map[string]string
+	if d.pipeline != nil {
+		env, ready, err := d.pipeline.Resolve(job)
+		if err != nil {
+			d.logger.Errorf("解析训练作业依赖失败: ID=%d, %v", job.Id, err)
+			return false
+		}
+		if !ready && job.Status == JobStatusPending {
+			return false
+		}
+		upstreamEnv = env
+	}
+
+	spec, err := BuildTrainingJobSpec(job, d.config.Namespace)
+	if err != nil {
+		d.failJob(job, "INVALID_SPEC", err)
+		return false
+	}
+	InjectMetricsEnv(spec, job.Id, d.config.MetricsEndpoint, d.config.MetricsTokenSecret)
+	if d.machine.resumer != nil {
+		if err := d.machine.resumer.Inject(spec, job); err != nil {
+			d.logger.Errorf("注入训练作业恢复用的检查点失败: ID=%d, %v", job.Id, err)
+			return false
+		}
+	}
+
+	// 作业自身配置的同名环境变量优先
+	for name, value := range upstreamEnv {
+		if spec.EnvVars == nil {
+			spec.EnvVars = make(map[string]string)
+		}
+		if _, ok := spec.EnvVars[name]; !ok {
+			spec.EnvVars[name] = value
+		}
+	}
+
+	// pending作业需要先认领，避免多个实例重复提交
+	if job.Status == JobStatusPending {
+		status, err := d.machine.Apply(job, JobTransition{
+			Action:   JobActionSubmit,
+			Operator: SystemOperator,
+			Reason:   "派发器提交作业到Volcano",
+			Fields: map[string]interface{}{
+				"volcano_job_name": spec.Name,
+				"namespace":        spec.Namespace,
+				"queued_at":        time.Now(),
+			},
+		})
+		if err != nil {
+			if err != bizerrors.ErrJobStatusChanged {
+				d.logger.Errorf("认领训练作业失败: ID=%d, %v", job.Id, err)
+			}
+			return false
+		}
+		job.Status = status
+	}
+
+	if _, err = d.jobManager.CreateTrainingJob(spec); err != nil && !apierrors.IsAlreadyExists(err) {
+		if isTransientError(err) {
+			d.scheduleRetry(job, err)
+		} else {
+			d.failJob(job, "SUBMIT_REJECTED", err)
+		}
+		return false
+	}
+
+	// 作业已存在说明之前提交成功但回写失败，视为提交成功
+	marked, err := d.jobModel.MarkScheduled(job.Id)
+	if err != nil {
+		d.logger.Errorf("回写训练作业调度时间失败: ID=%d, %v", job.Id, err)
+	} else if !marked && !d.stillSubmitted(job.Id) {
+		d.abortSubmission(job, spec)
+		return false
+	}
+	d.clearAttempt(job.Id)
+
+	d.logger.Infof("训练作业已提交到Volcano: ID=%d, VolcanoJob=%s/%s", job.Id, spec.Namespace, spec.Name)
+	return true
+}
+
+// stillSubmitted 判断未能回写调度时间的作业是否已被其他派发器实例回写
+func (d *JobDispatcher) stillSubmitted(jobID int64) bool {
+	current, err := d.jobModel.FindOneDetail(jobID)
+	if err != nil {
+		// 无法确认状态时保留Volcano作业，由下一轮派发重新判断
+		d.logger.Errorf("查询训练作业状态失败: ID=%d, %v", jobID, err)
+		return true
+	}
+	return current.Status == JobStatusQueued && current.ScheduledAt != nil
+}
+
+// abortSubmission 作业在提交期间被暂停、取消或删除时，删除刚创建的Volcano作业
+func (d *JobDispatcher) abortSubmission(job *model.VtTrainingJobs, spec *volcano.TrainingJobSpec) {
+	d.clearAttempt(job.Id)
+	d.logger.Infof("训练作业在提交期间状态已变更，删除已创建的Volcano作业: ID=%d, VolcanoJob=%s/%s", job.Id, spec.Namespace, spec.Name)
+
+	if err := d.machine.control(func(c VolcanoJobController) error {
+		return c.DeleteJob(spec.Namespace, spec.Name)
+	}); err != nil {
+		d.logger.Errorf("删除训练作业对应的Volcano作业失败: ID=%d, %v", job.Id, err)
+	}
+}
+
+// readyForAttempt 判断作业是否已过重试退避时间
+func (d *JobDispatcher) readyForAttempt(jobID int64) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	attempt, ok := d.attempts[jobID]
+	return !ok || !time.Now().Before(attempt.nextAttempt)
+}
+
+// scheduleRetry 记录瞬时错误并按指数退避安排重试
+func (d *JobDispatcher) scheduleRetry(job *model.VtTrainingJobs, err error) {
+	d.mu.Lock()
+	attempt, ok := d.attempts[job.Id]
+	if !ok {
+		attempt = &submitAttempt{}
+		d.attempts[job.Id] = attempt
+	}
+	attempt.count++
+	count := attempt.count
+
+	backoff := d.config.BackoffBase << uint(count-1)
+	if backoff <= 0 || backoff > d.config.BackoffMax {
+		backoff = d.config.BackoffMax
+	}
+	attempt.nextAttempt = time.Now().Add(backoff)
+	d.mu.Unlock()
+
+	if d.config.MaxSubmitAttempts > 0 && count >= d.config.MaxSubmitAttempts {
+		d.failJob(job, "SUBMIT_RETRY_EXHAUSTED", err)
+		return
+	}
+
+	d.logger.Infof("提交训练作业遇到瞬时错误，%v 后重试(第%d次): ID=%d, %v", backoff, count, job.Id, err)
+}
+
+// failJob 将作业标记为提交失败
+func (d *JobDispatcher) failJob(job *model.VtTrainingJobs, errorCode string, err error) {
+	d.clearAttempt(job.Id)
+	d.logger.Errorf("训练作业提交失败: ID=%d, Code=%s, %v", job.Id, errorCode, err)
+
+	_, applyErr := d.machine.Apply(job, JobTransition{
+		Action:   JobActionFail,
+		Operator: SystemOperator,
+		Reason:   "提交Volcano作业失败",
+		Fields: map[string]interface{}{
+			"error_code":     errorCode,
+			"error_message":  err.Error(),
+			"failure_reason": "submit_failed",
+		},
+	})
+	if applyErr != nil {
+		d.logger.Errorf("更新训练作业失败状态失败: ID=%d, %v", job.Id, applyErr)
+	}
+}
+
+// clearAttempt 清除作业的重试状态
+func (d *JobDispatcher) clearAttempt(jobID int64) {
+	d.mu.Lock()
+	delete(d.attempts, jobID)
+	d.mu.Unlock()
+}
+
+// isTransientError 判断是否为可重试的Kubernetes瞬时错误
+func isTransientError(err error) bool {
+	if apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) || apierrors.IsInternalError(err) ||
+		apierrors.IsServiceUnavailable(err) || apierrors.IsUnexpectedServerError(err) {
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,451 @@
+package scheduler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"api/model"
+	bizerrors "api/pkg/errors"
+)
+
+const (
+	// JobEntityType 作业依赖其他训练作业时使用的实体类型
+	JobEntityType = "training_job"
+	// DependsOnRelation 子作业依赖上游作业的关联类型，上游作业成功后子作业才会被派发
+	DependsOnRelation = "depends_on"
+	// ClonedFromRelation 克隆作业关联到源作业的关联类型
+	ClonedFromRelation = "cloned_from"
+
+	// UpstreamEnvPrefix 注入上游作业输出的环境变量前缀
+	UpstreamEnvPrefix = "UPSTREAM_"
+
+	// maxPipelineNodes 单个流水线最多遍历的作业数，避免异常数据导致无限展开
+	maxPipelineNodes = 500
+)
+
+// 上游作业可传递给子作业的输出
+const (
+	OutputJobId          = "job_id"
+	OutputJobName        = "job_name"
+	OutputPath           = "output_path"
+	OutputCheckpointPath = "checkpoint_path" // 最佳检查点，没有检查点记录时使用作业的检查点目录
+)
+
+// 流水线节点状态，pending作业细分为以下三种，其余节点使用作业状态
+const (
+	PipelineNodeReady   = "ready"   // 上游均已成功，等待派发
+	PipelineNodeWaiting = "waiting" // 等待上游作业完成
+	PipelineNodeBlocked = "blocked" // 上游作业未成功结束，重新运行上游后继续
+)
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+var invalidEnvChars = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// DependencyMetadata 依赖关联中记录的元数据
+// Alias 用于生成环境变量名，默认使用上游作业名；Outputs 为额外的 环境变量名 -> 输出 映射
+type DependencyMetadata struct {
+	Alias   string            `json:"alias,omitempty"`
+	Outputs map[string]string `json:"outputs,omitempty"`
+}
+
+// ParseDependencyMetadata 解析并校验依赖元数据，为空时返回零值
+func ParseDependencyMetadata(data string) (*DependencyMetadata, error) {
+	metadata := &DependencyMetadata{}
+	if data == "" || data == "null" {
+		return metadata, nil
+	}
+	if err := json.Unmarshal([]byte(data), metadata); err != nil {
+		return nil, fmt.Errorf("依赖元数据格式错误: %v", err)
+	}
+
+	if metadata.Alias != "" && envKey(metadata.Alias) == "" {
+		return nil, fmt.Errorf("依赖别名 %q 无法转换为环境变量名", metadata.Alias)
+	}
+	for name, output := range metadata.Outputs {
+		if !envNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("环境变量名 %q 不合法", name)
+		}
+		switch output {
+		case OutputJobId, OutputJobName, OutputPath, OutputCheckpointPath:
+		default:
+			return nil, fmt.Errorf("不支持的上游输出 %q", output)
+		}
+	}
+	return metadata, nil
+}
+
+// envKey 将作业名或别名转换为环境变量名片段
+func envKey(name string) string {
+	key := invalidEnvChars.ReplaceAllString(strings.ToUpper(name), "_")
+	return strings.Trim(key, "_")
+}
+
+// PipelineNode 流水线中的一个作业
+type PipelineNode struct {
+	JobId         int64
+	JobName       string
+	Status        string
+	State         string
+	FailureReason string
+	DependsOn     []int64
+}
+
+// Pipeline 作业所在的依赖图，节点按拓扑序排列
+type Pipeline struct {
+	Status string
+	Nodes  []*PipelineNode
+}
+
+// JobPipeline 基于作业依赖关联的流水线：判断子作业能否派发、传递上游输出、查询和重新运行节点
+type JobPipeline struct {
+	jobModel        model.VtTrainingJobsModel
+	relationModel   model.VtTrainingJobRelationsModel
+	checkpointModel model.VtTrainingCheckpointsModel
+	machine         *JobStateMachine
+}
+
+// NewJobPipeline 创建作业流水线
+func NewJobPipeline(jobModel model.VtTrainingJobsModel, relationModel model.VtTrainingJobRelationsModel,
+	checkpointModel model.VtTrainingCheckpointsModel, machine *JobStateMachine) *JobPipeline {
+	return &JobPipeline{
+		jobModel:        jobModel,
+		relationModel:   relationModel,
+		checkpointModel: checkpointModel,
+		machine:         machine,
+	}
+}
+
+// dependency 一条已加载上游作业的依赖
+type dependency struct {
+	relation *model.VtTrainingJobRelations
+	parent   *model.VtTrainingJobs
+}
+
+// dependencies 加载作业的上游作业，已删除的上游作业视为不存在
+func (p *JobPipeline) dependencies(jobId int64) ([]dependency, error) {
+	relations, err := p.relationModel.FindByJobId(jobId, JobEntityType, DependsOnRelation)
+	if err != nil {
+		return nil, fmt.Errorf("查询作业依赖失败: ID=%d, %w", jobId, err)
+	}
+
+	deps := make([]dependency, 0, len(relations))
+	for _, relation := range relations {
+		if relation.Status != "active" {
+			continue
+		}
+		parent, err := p.jobModel.FindOneDetail(relation.EntityId)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("查询上游作业失败: ID=%d, %w", relation.EntityId, err)
+		}
+		deps = append(deps, dependency{relation: relation, parent: parent})
+	}
+	return deps, nil
+}
+
+// Resolve 判断作业的上游是否均已成功，成功时返回需要注入的上游输出环境变量
+func (p *JobPipeline) Resolve(job *model.VtTrainingJobs) (map[string]string, bool, error) {
+	deps, err := p.dependencies(job.Id)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, dep := range deps {
+		if dep.parent.Status != JobStatusSucceeded {
+			return nil, false, nil
+		}
+	}
+	if len(deps) == 0 {
+		return nil, true, nil
+	}
+
+	env := make(map[string]string)
+	for _, dep := range deps {
+		metadata, err := ParseDependencyMetadata(dep.relation.Metadata)
+		if err != nil {
+			return nil, false, fmt.Errorf("依赖关联 %d: %w", dep.relation.Id, err)
+		}
+		outputs, err := p.outputs(dep.parent)
+		if err != nil {
+			return nil, false, err
+		}
+
+		key := envKey(metadata.Alias)
+		if key == "" {
+			key = envKey(dep.parent.Name)
+		}
+		prefix := UpstreamEnvPrefix + key + "_"
+		setEnv(env, prefix+"JOB_ID", outputs[OutputJobId])
+		setEnv(env, prefix+"OUTPUT_PATH", outputs[OutputPath])
+		setEnv(env, prefix+"CHECKPOINT_PATH", outputs[OutputCheckpointPath])
+		if len(deps) == 1 {
+			setEnv(env, UpstreamEnvPrefix+"OUTPUT_PATH", outputs[OutputPath])
+			setEnv(env, UpstreamEnvPrefix+"CHECKPOINT_PATH", outputs[OutputCheckpointPath])
+		}
+		for name, output := range metadata.Outputs {
+			setEnv(env, name, outputs[output])
+		}
+	}
+	return env, true, nil
+}
+
+// outputs 读取上游作业可传递的输出
+func (p *JobPipeline) outputs(job *model.VtTrainingJobs) (map[string]string, error) {
+	checkpointPath := job.CheckpointPath
+	checkpoint, err := p.checkpointModel.FindBest(job.Id)
+	switch {
+	case err == nil:
+		checkpointPath = checkpoint.StoragePath
+	case err != sql.ErrNoRows:
+		return nil, fmt.Errorf("查询上游作业检查点失败: ID=%d, %w", job.Id, err)
+	}
+
+	return map[string]string{
+		OutputJobId:          strconv.FormatInt(job.Id, 10),
+		OutputJobName:        job.Name,
+		OutputPath:           job.OutputPath,
+		OutputCheckpointPath: checkpointPath,
+	}, nil
+}
+
+// setEnv 仅写入非空值
+func setEnv(env map[string]string, name, value string) {
+	if value != "" {
+		env[name] = value
+	}
+}
+
+// CheckDependency 校验新增依赖 childId -> parentId 不会形成环
+func (p *JobPipeline) CheckDependency(childId, parentId int64) error {
+	if childId == parentId {
+		return bizerrors.NewBizError(bizerrors.ErrCodePipelineInvalid, "作业不能依赖自身", bizerrors.ErrorTypeValidation)
+	}
+
+	visited := map[int64]bool{parentId: true}
+	queue := []int64{parentId}
+	for len(queue) > 0 && len(visited) <= maxPipelineNodes {
+		current := queue[0]
+		queue = queue[1:]
+
+		relations, err := p.relationModel.FindByJobId(current, JobEntityType, DependsOnRelation)
+		if err != nil {
+			return bizerrors.WrapError(err, bizerrors.ErrCodeDatabaseError, "查询作业依赖失败")
+		}
+		for _, relation := range relations {
+			if relation.EntityId == childId {
+				return bizerrors.NewBizError(bizerrors.ErrCodePipelineInvalid,
+					fmt.Sprintf("作业 %d 已直接或间接依赖作业 %d，新增依赖会形成环", parentId, childId), bizerrors.ErrorTypeValidation)
+			}
+			if !visited[relation.EntityId] {
+				visited[relation.EntityId] = true
+				queue = append(queue, relation.EntityId)
+			}
+		}
+	}
+	return nil
+}
+
+// Graph 查询作业所在的整个依赖图，包括全部上游和下游作业
+func (p *JobPipeline) Graph(jobId int64) (*Pipeline, error) {
+	nodes := make(map[int64]*PipelineNode)
+	queue := []int64{jobId}
+	for len(queue) > 0 && len(nodes) < maxPipelineNodes {
+		current := queue[0]
+		queue = queue[1:]
+		if _, ok := nodes[current]; ok {
+			continue
+		}
+
+		job, err := p.jobModel.FindOneDetail(current)
+		if err == sql.ErrNoRows {
+			if current == jobId {
+				return nil, bizerrors.ErrJobNotFound
+			}
+			continue
+		}
+		if err != nil {
+			return nil, bizerrors.WrapError(err, bizerrors.ErrCodeDatabaseError, "查询训练作业失败")
+		}
+
+		node := &PipelineNode{JobId: job.Id, JobName: job.Name, Status: job.Status, FailureReason: job.FailureReason}
+		nodes[current] = node
+
+		parents, err := p.relationModel.FindByJobId(current, JobEntityType, DependsOnRelation)
+		if err != nil {
+			return nil, bizerrors.WrapError(err, bizerrors.ErrCodeDatabaseError, "查询作业依赖失败")
+		}
+		for _, relation := range parents {
+			if relation.Status != "active" {
+				continue
+			}
+			node.DependsOn = append(node.DependsOn, relation.EntityId)
+			queue = append(queue, relation.EntityId)
+		}
+
+		children, err := p.relationModel.FindByEntity(JobEntityType, current, DependsOnRelation)
+		if err != nil {
+			return nil, bizerrors.WrapError(err, bizerrors.ErrCodeDatabaseError, "查询下游作业失败")
+		}
+		for _, relation := range children {
+			if relation.Status != "active" {
+				continue
+			}
+			queue = append(queue, relation.JobId)
+		}
+	}
+
+	// 依赖已删除作业的边不参与计算
+	for _, node := range nodes {
+		parents := node.DependsOn[:0]
+		for _, parentId := range node.DependsOn {
+			if _, ok := nodes[parentId]; ok {
+				parents = append(parents, parentId)
+			}
+		}
+		node.DependsOn = parents
+	}
+
+	ordered := topologicalOrder(nodes)
+	for _, node := range ordered {
+		node.State = nodeState(node, nodes)
+	}
+	return &Pipeline{Status: pipelineStatus(ordered), Nodes: ordered}, nil
+}
+
+// topologicalOrder 按依赖顺序排列节点，同一层按作业ID排序
+func topologicalOrder(nodes map[int64]*PipelineNode) []*PipelineNode {
+	indegree := make(map[int64]int, len(nodes))
+	children := make(map[int64][]int64, len(nodes))
+	for id := range nodes {
+		indegree[id] = 0
+	}
+	for id, node := range nodes {
+		for _, parentId := range node.DependsOn {
+			indegree[id]++
+			children[parentId] = append(children[parentId], id)
+		}
+	}
+
+	var ready []int64
+	for id, degree := range indegree {
+		if degree == 0 {
+			ready = append(ready, id)
+		}
+	}
+
+	ordered := make([]*PipelineNode, 0, len(nodes))
+	for len(ready) > 0 {
+		sort.Slice(ready, func(i, j int) bool { return ready[i] < ready[j] })
+		id := ready[0]
+		ready = ready[1:]
+		ordered = append(ordered, nodes[id])
+		for _, childId := range children[id] {
+			indegree[childId]--
+			if indegree[childId] == 0 {
+				ready = append(ready, childId)
+			}
+		}
+	}
+
+	// 存在环时剩余节点按ID追加，保证每个节点都出现一次
+	if len(ordered) < len(nodes) {
+		var rest []int64
+		for id, degree := range indegree {
+			if degree > 0 {
+				rest = append(rest, id)
+			}
+		}
+		sort.Slice(rest, func(i, j int) bool { return rest[i] < rest[j] })
+		for _, id := range rest {
+			ordered = append(ordered, nodes[id])
+		}
+	}
+	return ordered
+}
+
+// nodeState 计算节点状态，调用前上游节点的状态需已计算完成
+func nodeState(node *PipelineNode, nodes map[int64]*PipelineNode) string {
+	if node.Status != JobStatusPending {
+		return node.Status
+	}
+
+	state := PipelineNodeReady
+	for _, parentId := range node.DependsOn {
+		parent := nodes[parentId]
+		switch {
+		case parent.State == PipelineNodeBlocked || (IsTerminalJobStatus(parent.State) && parent.State != JobStatusSucceeded):
+			return PipelineNodeBlocked
+		case parent.State != JobStatusSucceeded:
+			state = PipelineNodeWaiting
+		}
+	}
+	return state
+}
+
+// pipelineStatus 汇总流水线状态：有节点在推进时为running，否则取最需要关注的结束状态
+func pipelineStatus(nodes []*PipelineNode) string {
+	counts := make(map[string]int)
+	for _, node := range nodes {
+		counts[node.State]++
+	}
+
+	switch {
+	case counts[PipelineNodeReady]+counts[PipelineNodeWaiting]+counts[JobStatusQueued]+counts[JobStatusScheduling]+counts[JobStatusRunning] > 0:
+		return JobStatusRunning
+	case counts[JobStatusFailed]+counts[JobStatusTimeout]+counts[JobStatusOOMKilled] > 0:
+		return JobStatusFailed
+	case counts[JobStatusCancelled] > 0:
+		return JobStatusCancelled
+	case counts[JobStatusSuspended] > 0:
+		return JobStatusSuspended
+	case counts[JobStatusSucceeded] == len(nodes):
+		return JobStatusSucceeded
+	}
+	return JobStatusPending
+}
+
+// Rerun 重新运行流水线中的一个作业，downstream为true时同时重新运行已开始或已结束的下游作业
+// 重新运行的下游作业回到pending，等待上游再次成功后派发；返回重新运行的作业ID
+func (p *JobPipeline) Rerun(jobId int64, downstream bool, operator JobOperator, reason string) ([]int64, error) {
+	if _, err := p.machine.Restart(jobId, operator, reason, RestartOptions{}); err != nil {
+		return nil, err
+	}
+	restarted := []int64{jobId}
+	if !downstream {
+		return restarted, nil
+	}
+
+	pipeline, err := p.Graph(jobId)
+	if err != nil {
+		return restarted, err
+	}
+	affected := map[int64]bool{jobId: true}
+	for _, node := range pipeline.Nodes {
+		if affected[node.JobId] {
+			continue
+		}
+		for _, parentId := range node.DependsOn {
+			if affected[parentId] {
+				affected[node.JobId] = true
+				break
+			}
+		}
+		if !affected[node.JobId] || node.Status == JobStatusPending {
+			continue
+		}
+
+		// 上游输出已变化，下游作业从头开始训练
+		if _, err := p.machine.Restart(node.JobId, operator, fmt.Sprintf("上游作业 %d 重新运行: %s", jobId, reason), RestartOptions{FromScratch: true}); err != nil {
+			return restarted, err
+		}
+		restarted = append(restarted, node.JobId)
+	}
+	return restarted, nil
+}
@@ -0,0 +1,661 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"api/model"
+	bizerrors "api/pkg/errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	vcjob "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+	vcclient "volcano.sh/apis/pkg/client/clientset/versioned"
+	vcinformers "volcano.sh/apis/pkg/client/informers/externalversions"
+	vclisters "volcano.sh/apis/pkg/client/listers/batch/v1alpha1"
+)
+
+// ReconcilerConfig 作业状态同步器配置
+type ReconcilerConfig struct {
+	Namespace      string        // 监听的命名空间
+	ResyncInterval time.Duration // 全量同步间隔，用于发现进程停止期间被删除的作业
+}
+
+// JobReconciler Volcano作业状态同步器
+// 通过Informer监听Volcano作业及其Pod，将阶段、起止时间、退出码和实例信息回写数据库，
+// 并处理在平台之外被删除的作业
+type JobReconciler struct {
+	jobModel      model.VtTrainingJobsModel
+	instanceModel model.VtTrainingJobInstancesModel
+	machine       *JobStateMachine
+	vcClient      vcclient.Interface
+	config        ReconcilerConfig
+	logger        logx.Logger
+
+	// 所用的volcano.sh/apis版本的InformerFactory不支持Shutdown，作业Informer由同步器自行运行和等待退出
+	vcInformer  cache.SharedIndexInformer
+	kubeFactory kubeinformers.SharedInformerFactory
+	jobLister   vclisters.JobLister
+	podLister   corelisters.PodLister
+
+	mu      sync.Mutex
+	pending map[int64]struct{}
+
+	wakeCh chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewJobReconciler 创建作业状态同步器
+func NewJobReconciler(jobModel model.VtTrainingJobsModel, instanceModel model.VtTrainingJobInstancesModel, machine *JobStateMachine,
+	vcClient vcclient.Interface, kubeClient kubernetes.Interface, config ReconcilerConfig) *JobReconciler {
+	if config.ResyncInterval <= 0 {
+		config.ResyncInterval = time.Minute
+	}
+
+	// 只关注平台创建的作业及其Pod
+	selector := labels.NewSelector()
+	requirement, _ := labels.NewRequirement(JobIDLabelKey, selection.Exists, nil)
+	selector = selector.Add(*requirement)
+	tweak := func(options *metav1.ListOptions) {
+		options.LabelSelector = selector.String()
+	}
+
+	vcFactory := vcinformers.NewSharedInformerFactoryWithOptions(vcClient, config.ResyncInterval,
+		vcinformers.WithNamespace(config.Namespace), vcinformers.WithTweakListOptions(tweak))
+	kubeFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, config.ResyncInterval,
+		kubeinformers.WithNamespace(config.Namespace), kubeinformers.WithTweakListOptions(tweak))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &JobReconciler{
+		jobModel:      jobModel,
+		instanceModel: instanceModel,
+		machine:       machine,
+		vcClient:      vcClient,
+		config:        config,
+		logger:        logx.WithContext(context.Background()),
+		kubeFactory:   kubeFactory,
+		pending:       make(map[int64]struct{}),
+		wakeCh:        make(chan struct{}, 1),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+
+	jobInformer := vcFactory.Batch().V1alpha1().Jobs()
+	podInformer := kubeFactory.Core().V1().Pods()
+	r.vcInformer = jobInformer.Informer()
+	r.jobLister = jobInformer.Lister()
+	r.podLister = podInformer.Lister()
+
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    r.enqueueObject,
+		UpdateFunc: func(_, obj interface{}) { r.enqueueObject(obj) },
+		DeleteFunc: r.enqueueObject,
+	}
+	jobInformer.Informer().AddEventHandler(handler)
+	podInformer.Informer().AddEventHandler(handler)
+
+	return r
+}
+
+// Start 启动Informer，缓存同步完成后开始处理，不阻塞调用方
+func (r *JobReconciler) Start() {
+	r.logger.Infof("启动训练作业状态同步器，全量同步间隔: %v", r.config.ResyncInterval)
+
+	r.kubeFactory.Start(r.ctx.Done())
+
+	r.wg.Add(2)
+	go func() {
+		defer r.wg.Done()
+		r.vcInformer.Run(r.ctx.Done())
+	}()
+	go func() {
+		defer r.wg.Done()
+		if !r.waitForCacheSync() {
+			return
+		}
+
+		r.wg.Add(2)
+		go r.workLoop()
+		go r.resyncLoop()
+	}()
+}
+
+// waitForCacheSync 等待Volcano作业和Pod缓存同步
+func (r *JobReconciler) waitForCacheSync() bool {
+	synced := true
+	if !cache.WaitForCacheSync(r.ctx.Done(), r.vcInformer.HasSynced) {
+		r.logger.Error("Volcano作业缓存同步失败")
+		synced = false
+	}
+	for informerType, ok := range r.kubeFactory.WaitForCacheSync(r.ctx.Done()) {
+		if !ok {
+			r.logger.Errorf("Pod缓存同步失败: %v", informerType)
+			synced = false
+		}
+	}
+	return synced
+}
+
+// Stop 停止同步器
+func (r *JobReconciler) Stop() {
+	r.cancel()
+	r.wg.Wait()
+	r.kubeFactory.Shutdown()
+	r.logger.Info("训练作业状态同步器已停止")
+}
+
+// Enqueue 将作业加入同步队列
+func (r *JobReconciler) Enqueue(jobID int64) {
+	r.mu.Lock()
+	r.pending[jobID] = struct{}{}
+	r.mu.Unlock()
+
+	select {
+	case r.wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+// enqueueObject 从Volcano作业或Pod的标签中解析平台作业ID并入队
+func (r *JobReconciler) enqueueObject(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	meta, ok := obj.(metav1.Object)
+	if !ok {
+		return
+	}
+	jobID, err := strconv.ParseInt(meta.GetLabels()[JobIDLabelKey], 10, 64)
+	if err != nil {
+		return
+	}
+	r.Enqueue(jobID)
+}
+
+// workLoop 处理同步队列
+func (r *JobReconciler) workLoop() {
+	defer r.wg.Done()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-r.wakeCh:
+		}
+
+		r.mu.Lock()
+		jobIDs := make([]int64, 0, len(r.pending))
+		for jobID := range r.pending {
+			jobIDs = append(jobIDs, jobID)
+		}
+		r.pending = make(map[int64]struct{})
+		r.mu.Unlock()
+
+		for _, jobID := range jobIDs {
+			if r.ctx.Err() != nil {
+				return
+			}
+			if err := r.ReconcileJob(jobID); err != nil {
+				r.logger.Errorf("同步训练作业状态失败: ID=%d, %v", jobID, err)
+			}
+		}
+	}
+}
+
+// resyncLoop 定期将数据库中已提交的作业加入队列，Informer无法感知进程停止期间发生的删除
+func (r *JobReconciler) resyncLoop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.config.ResyncInterval)
+	defer ticker.Stop()
+
+	for {
+		jobs, err := r.jobModel.FindSubmitted()
+		if err != nil {
+			r.logger.Errorf("查询已提交的训练作业失败: %v", err)
+		}
+		for _, job := range jobs {
+			r.Enqueue(job.Id)
+		}
+
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReconcileJob 同步单个作业的状态
+func (r *JobReconciler) ReconcileJob(jobID int64) error {
+	job, err := r.jobModel.FindOneDetail(jobID)
+	if err == sql.ErrNoRows {
+		// 数据库中已删除的作业不应继续占用集群资源
+		return r.deleteVolcanoJobs(jobID, "")
+	}
+	if err != nil {
+		return err
+	}
+
+	// 清理重新运行前的旧作业，已取消或被看门狗终止的作业连同当前作业一起删除
+	keep := job.VolcanoJobName
+	if job.Status == JobStatusCancelled || job.Status == JobStatusTimeout {
+		keep = ""
+	}
+	if err := r.deleteVolcanoJobs(job.Id, keep); err != nil {
+		return err
+	}
+	if job.VolcanoJobName == "" {
+		return nil
+	}
+
+	// 实例只记录当前运行的Pod，上一次运行遗留的实例会被标记为killed
+	pods, err := r.listPods(job)
+	if err != nil {
+		return err
+	}
+	if err := r.syncInstances(job, pods); err != nil {
+		return err
+	}
+	if job.ScheduledAt == nil {
+		return nil
+	}
+
+	vcJob, err := r.getVolcanoJob(job.Namespace, job.VolcanoJobName)
+	if err != nil {
+		return err
+	}
+	if vcJob == nil {
+		return r.handleMissing(job)
+	}
+	return r.syncStatus(job, vcJob, pods)
+}
+
+// getVolcanoJob 获取Volcano作业，缓存未命中时回源确认，避免刚提交的作业被误判为已删除
+func (r *JobReconciler) getVolcanoJob(namespace, name string) (*vcjob.Job, error) {
+	vcJob, err := r.jobLister.Jobs(namespace).Get(name)
+	if err == nil {
+		return vcJob, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	vcJob, err = r.vcClient.BatchV1alpha1().Jobs(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return vcJob, err
+}
+
+// deleteVolcanoJobs 删除属于该作业但名称不是keep的Volcano作业
+func (r *JobReconciler) deleteVolcanoJobs(jobID int64, keep string) error {
+	selector := labels.SelectorFromSet(labels.Set{JobIDLabelKey: strconv.FormatInt(jobID, 10)})
+	vcJobs, err := r.jobLister.List(selector)
+	if err != nil {
+		return err
+	}
+
+	for _, vcJob := range vcJobs {
+		if vcJob.Name == keep || vcJob.DeletionTimestamp != nil {
+			continue
+		}
+		err := r.vcClient.BatchV1alpha1().Jobs(vcJob.Namespace).Delete(context.TODO(), vcJob.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("删除残留的Volcano作业失败: %s/%s, %w", vcJob.Namespace, vcJob.Name, err)
+		}
+		r.logger.Infof("已删除残留的Volcano作业: ID=%d, VolcanoJob=%s/%s", jobID, vcJob.Namespace, vcJob.Name)
+	}
+	return nil
+}
+
+// listPods 列出当前运行的Pod
+func (r *JobReconciler) listPods(job *model.VtTrainingJobs) ([]*corev1.Pod, error) {
+	return r.podLister.Pods(job.Namespace).List(JobPodSelector(job))
+}
+
+// JobPodSelector 作业当前Volcano作业的Pod标签选择器
+func JobPodSelector(job *model.VtTrainingJobs) labels.Selector {
+	return labels.SelectorFromSet(labels.Set{
+		JobIDLabelKey:    strconv.FormatInt(job.Id, 10),
+		vcjob.JobNameKey: job.VolcanoJobName,
+	})
+}
+
+// syncInstances 将Pod信息写入实例表，Pod已不存在的实例标记为killed
+func (r *JobReconciler) syncInstances(job *model.VtTrainingJobs, pods []*corev1.Pod) error {
+	seen := make(map[string]bool, len(pods))
+	for _, pod := range pods {
+		seen[pod.Name] = true
+		if err := r.instanceModel.Upsert(BuildJobInstance(job.Id, pod)); err != nil {
+			return fmt.Errorf("更新训练作业实例失败: %s, %w", pod.Name, err)
+		}
+	}
+
+	instances, err := r.instanceModel.FindByJobId(job.Id)
+	if err != nil {
+		return err
+	}
+	for _, instance := range instances {
+		if seen[instance.PodName] || isTerminalInstanceStatus(instance.Status) {
+			continue
+		}
+		if err := r.instanceModel.UpdateStatus(instance.Id, "killed", "PodDeleted"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleMissing 处理已提交但在集群中不存在的作业
+func (r *JobReconciler) handleMissing(job *model.VtTrainingJobs) error {
+	if _, ok := NextJobStatus(job.Status, JobActionFail); !ok {
+		return nil
+	}
+
+	r.logger.Errorf("Volcano作业已在平台外被删除: ID=%d, VolcanoJob=%s/%s", job.Id, job.Namespace, job.VolcanoJobName)
+	return r.apply(job, JobTransition{
+		Action:   JobActionFail,
+		Operator: SystemOperator,
+		Reason:   "Volcano作业已被删除",
+		Fields: map[string]interface{}{
+			"error_code":     "VOLCANO_JOB_DELETED",
+			"error_message":  fmt.Sprintf("Volcano作业 %s/%s 已在平台外被删除", job.Namespace, job.VolcanoJobName),
+			"failure_reason": "deleted",
+		},
+	})
+}
+
+// syncStatus 根据Volcano作业阶段推进平台作业状态
+func (r *JobReconciler) syncStatus(job *model.VtTrainingJobs, vcJob *vcjob.Job, pods []*corev1.Pod) error {
+	state := vcJob.Status.State
+
+	switch state.Phase {
+	case vcjob.Pending:
+		return r.applyIfAllowed(job, JobTransition{Action: JobActionSchedule, Reason: "Volcano作业等待调度"})
+
+	case vcjob.Running:
+		fields := map[string]interface{}{}
+		if job.StartTime == nil {
+			fields["start_time"] = jobStartTime(vcJob, pods)
+		}
+		return r.applyIfAllowed(job, JobTransition{Action: JobActionStart, Reason: "Volcano作业开始运行", Fields: fields})
+
+	case vcjob.Completing, vcjob.Completed:
+		return r.applyIfAllowed(job, JobTransition{
+			Action: JobActionSucceed,
+			Reason: "Volcano作业运行完成",
+			Fields: finishFields(job, map[string]interface{}{"exit_code": 0}),
+		})
+
+	case vcjob.Failed, vcjob.Terminated:
+		return r.applyFailure(job, vcJob, pods)
+
+	case vcjob.Aborted:
+		// 仅处理运行中被外部中止的作业，平台恢复作业时Volcano尚未处理命令前也处于Aborted
+		if job.Status != JobStatusRunning {
+			return nil
+		}
+		return r.applyIfAllowed(job, JobTransition{Action: JobActionSuspend, Reason: "Volcano作业已被中止"})
+	}
+
+	return nil
+}
+
+// applyFailure 根据Pod终止信息区分内存溢出和普通失败
+func (r *JobReconciler) applyFailure(job *model.VtTrainingJobs, vcJob *vcjob.Job, pods []*corev1.Pod) error {
+	exitCode, reason, message := podFailure(pods)
+	if reason == "" {
+		reason = vcJob.Status.State.Reason
+	}
+	if message == "" {
+		message = vcJob.Status.State.Message
+	}
+
+	// 可识别的原因统一归类，自动重试器据此判断是否重试
+	failureReason := reason
+	if classified := ClassifyFailure(reason, message); classified != "" {
+		failureReason = classified
+	}
+
+	fields := map[string]interface{}{
+		"error_code":     "VOLCANO_JOB_FAILED",
+		"error_message":  message,
+		"failure_reason": failureReason,
+	}
+	if exitCode != nil {
+		fields["exit_code"] = *exitCode
+	}
+
+	action := JobActionFail
+	if failureReason == FailureReasonOOMKilled {
+		action = JobActionOOM
+		fields["error_code"] = "OOM_KILLED"
+	}
+	if message == "" {
+		fields["error_message"] = fmt.Sprintf("Volcano作业进入%s阶段", vcJob.Status.State.Phase)
+	}
+
+	return r.applyIfAllowed(job, JobTransition{
+		Action: action,
+		Reason: fmt.Sprintf("Volcano作业%s", vcJob.Status.State.Phase),
+		Fields: finishFields(job, fields),
+	})
+}
+
+// applyIfAllowed 仅在当前状态允许时执行变更，其余情况视为已同步
+func (r *JobReconciler) applyIfAllowed(job *model.VtTrainingJobs, t JobTransition) error {
+	if _, ok := NextJobStatus(job.Status, t.Action); !ok {
+		return nil
+	}
+	t.Operator = SystemOperator
+	return r.apply(job, t)
+}
+
+// apply 执行状态变更，状态已被并发修改时重新入队
+func (r *JobReconciler) apply(job *model.VtTrainingJobs, t JobTransition) error {
+	_, err := r.machine.Apply(job, t)
+	if err == bizerrors.ErrJobStatusChanged {
+		r.Enqueue(job.Id)
+		return nil
+	}
+	return err
+}
+
+// BuildJobInstance 将Pod转换为训练任务实例
+func BuildJobInstance(jobID int64, pod *corev1.Pod) *model.VtTrainingJobInstances {
+	index := podTaskIndex(pod)
+	instance := &model.VtTrainingJobInstances{
+		JobId:         jobID,
+		InstanceName:  pod.Name,
+		InstanceType:  instanceType(pod.Labels[vcjob.TaskSpecKey]),
+		InstanceIndex: index,
+		ReplicaIndex:  index,
+		PodName:       pod.Name,
+		Namespace:     pod.Namespace,
+		NodeName:      pod.Spec.NodeName,
+		NodeIp:        pod.Status.HostIP,
+		PodIp:         pod.Status.PodIP,
+		Status:        instanceStatus(pod),
+		Phase:         string(pod.Status.Phase),
+		Reason:        pod.Status.Reason,
+		Message:       pod.Status.Message,
+	}
+	if pod.Status.StartTime != nil {
+		instance.StartTime = &pod.Status.StartTime.Time
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		transition := condition.LastTransitionTime.Time
+		if !transition.IsZero() && (instance.LastTransitionTime == nil || transition.After(*instance.LastTransitionTime)) {
+			instance.LastTransitionTime = &transition
+		}
+		switch condition.Type {
+		case corev1.PodScheduled:
+			if condition.Status == corev1.ConditionTrue && !transition.IsZero() {
+				instance.ScheduledAt = &transition
+			}
+		case corev1.PodReady:
+			instance.Ready = condition.Status == corev1.ConditionTrue
+		}
+	}
+
+	for _, status := range pod.Status.ContainerStatuses {
+		instance.RestartCount += int(status.RestartCount)
+		if instance.ContainerId == "" {
+			instance.ContainerId = status.ContainerID
+		}
+		if terminated := status.State.Terminated; terminated != nil {
+			finished := terminated.FinishedAt.Time
+			if !finished.IsZero() && (instance.EndTime == nil || finished.After(*instance.EndTime)) {
+				instance.EndTime = &finished
+			}
+			// 优先记录失败容器的退出信息
+			if instance.ExitCode == nil || *instance.ExitCode == 0 {
+				exitCode := int(terminated.ExitCode)
+				instance.ExitCode = &exitCode
+				instance.TerminationReason = terminated.Reason
+			}
+		}
+	}
+
+	return instance
+}
+
+// podTaskIndex 获取Pod在任务中的序号，优先使用Volcano注解，其次解析Pod名后缀
+func podTaskIndex(pod *corev1.Pod) int {
+	value := pod.Annotations[vcjob.TaskIndex]
+	if value == "" {
+		value = pod.Labels[vcjob.TaskIndex]
+	}
+	if value == "" {
+		if pos := strings.LastIndex(pod.Name, "-"); pos >= 0 {
+			value = pod.Name[pos+1:]
+		}
+	}
+	index, _ := strconv.Atoi(value)
+	return index
+}
+
+// instanceType 将Volcano任务名映射为实例类型
+func instanceType(taskName string) string {
+	switch taskName {
+	case "master", "worker", "ps", "evaluator", "chief":
+		return taskName
+	}
+	return "worker"
+}
+
+// instanceStatus 将Pod状态映射为实例状态
+func instanceStatus(pod *corev1.Pod) string {
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded:
+		return "succeeded"
+	case corev1.PodFailed:
+		return "failed"
+	}
+	if pod.DeletionTimestamp != nil {
+		return "killed"
+	}
+
+	switch pod.Status.Phase {
+	case corev1.PodPending:
+		if pod.Spec.NodeName != "" {
+			return "creating"
+		}
+		return "pending"
+	case corev1.PodRunning:
+		return "running"
+	}
+	return "unknown"
+}
+
+// isTerminalInstanceStatus 判断实例是否已结束
+func isTerminalInstanceStatus(status string) bool {
+	return status == "succeeded" || status == "failed" || status == "killed"
+}
+
+// podFailure 从Pod中提取第一个失败容器的退出码、原因和消息
+func podFailure(pods []*corev1.Pod) (*int, string, string) {
+	var exitCode *int
+	var reason, message string
+
+	for _, pod := range pods {
+		for _, status := range pod.Status.ContainerStatuses {
+			terminated := status.State.Terminated
+			if terminated == nil {
+				terminated = status.LastTerminationState.Terminated
+			}
+			if terminated == nil || terminated.ExitCode == 0 {
+				continue
+			}
+			// 内存溢出优先于其他失败原因
+			if exitCode == nil || (terminated.Reason == "OOMKilled" && reason != "OOMKilled") {
+				code := int(terminated.ExitCode)
+				exitCode = &code
+				reason = terminated.Reason
+				message = terminated.Message
+				if message == "" {
+					message = fmt.Sprintf("Pod %s 容器 %s 退出码 %d", pod.Name, status.Name, code)
+				}
+			}
+		}
+		// 节点丢失、抢占等中断原因比容器退出原因更准确
+		if reason != "OOMKilled" {
+			if ClassifyFailure(pod.Status.Reason, "") != "" || (reason == "" && pod.Status.Reason != "") {
+				reason = pod.Status.Reason
+				message = pod.Status.Message
+			}
+			for _, condition := range pod.Status.Conditions {
+				if condition.Type == corev1.DisruptionTarget && condition.Status == corev1.ConditionTrue && ClassifyFailure(condition.Reason, "") != "" {
+					reason = condition.Reason
+					message = condition.Message
+				}
+			}
+		}
+	}
+
+	return exitCode, reason, message
+}
+
+// jobStartTime 获取作业开始运行的时间
+func jobStartTime(vcJob *vcjob.Job, pods []*corev1.Pod) time.Time {
+	var start time.Time
+	for _, pod := range pods {
+		if pod.Status.StartTime != nil && (start.IsZero() || pod.Status.StartTime.Time.Before(start)) {
+			start = pod.Status.StartTime.Time
+		}
+	}
+	if start.IsZero() {
+		start = vcJob.Status.State.LastTransitionTime.Time
+	}
+	if start.IsZero() {
+		start = time.Now()
+	}
+	return start
+}
+
+// finishFields 补充作业结束时需要回写的字段
+func finishFields(job *model.VtTrainingJobs, fields map[string]interface{}) map[string]interface{} {
+	end := time.Now()
+	fields["end_time"] = end
+	if job.StartTime != nil {
+		fields["duration_seconds"] = int64(end.Sub(*job.StartTime).Seconds())
+	}
+	return fields
+}
@@ -0,0 +1,237 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"api/model"
+	bizerrors "api/pkg/errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 可自动重试的失败原因，由状态同步器写入failure_reason
+const (
+	FailureReasonNodeLost    = "node_lost"
+	FailureReasonOOMKilled   = "oom_killed"
+	FailureReasonPreempted   = "preempted"
+	FailureReasonNCCLTimeout = "nccl_timeout"
+	FailureReasonGPUFault    = "gpu_fault" // 所在节点GPU故障被隔离
+)
+
+// RetriableFailureReasons 允许自动重试的失败原因
+var RetriableFailureReasons = []string{
+	FailureReasonNodeLost,
+	FailureReasonOOMKilled,
+	FailureReasonPreempted,
+	FailureReasonNCCLTimeout,
+	FailureReasonGPUFault,
+}
+
+// ClassifyFailure 将Pod或Volcano作业的失败原因归类为可重试原因，无法归类时返回空字符串
+func ClassifyFailure(reason, message string) string {
+	switch reason {
+	case "OOMKilled":
+		return FailureReasonOOMKilled
+	case "NodeLost", "NodeShutdown", "Shutdown", "DeletionByTaintManager", "DeletionByPodGC", "TerminationByKubelet":
+		return FailureReasonNodeLost
+	case "Preempted", "Preempting", "PreemptionByScheduler", "PodEvicted":
+		return FailureReasonPreempted
+	}
+
+	text := strings.ToLower(reason + " " + message)
+	switch {
+	case strings.Contains(text, "nccl") && (strings.Contains(text, "timeout") || strings.Contains(text, "timed out")):
+		return FailureReasonNCCLTimeout
+	case strings.Contains(text, "preempt"):
+		return FailureReasonPreempted
+	case strings.Contains(text, "node lost") || strings.Contains(text, "node not ready"):
+		return FailureReasonNodeLost
+	}
+	return ""
+}
+
+// RetrierConfig 自动重试配置
+type RetrierConfig struct {
+	Interval    time.Duration // 轮询间隔
+	BatchSize   int           // 每轮最多处理的作业数
+	BackoffBase time.Duration // 第一次重试前的等待时长
+	BackoffMax  time.Duration // 重试等待的最大时长
+}
+
+// JobRetrier 训练作业自动重试器
+// 对开启auto_restart且因可重试原因失败的作业按指数退避重新提交，
+// 状态机启用检查点恢复时从最新或最佳的可用检查点继续训练，重试次数达到max_retry_count后不再重试
+type JobRetrier struct {
+	jobModel   model.VtTrainingJobsModel
+	retryModel model.VtTrainingJobRetriesModel
+	machine    *JobStateMachine
+	dispatcher *JobDispatcher
+	config     RetrierConfig
+	logger     logx.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewJobRetrier 创建自动重试器，dispatcher不为nil时重试后立即通知派发
+func NewJobRetrier(jobModel model.VtTrainingJobsModel, retryModel model.VtTrainingJobRetriesModel, machine *JobStateMachine,
+	dispatcher *JobDispatcher, config RetrierConfig) *JobRetrier {
+	if config.Interval <= 0 {
+		config.Interval = 10 * time.Second
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 20
+	}
+	if config.BackoffBase <= 0 {
+		config.BackoffBase = 30 * time.Second
+	}
+	if config.BackoffMax <= 0 {
+		config.BackoffMax = 30 * time.Minute
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &JobRetrier{
+		jobModel:   jobModel,
+		retryModel: retryModel,
+		machine:    machine,
+		dispatcher: dispatcher,
+		config:     config,
+		logger:     logx.WithContext(context.Background()),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Start 启动重试循环
+func (r *JobRetrier) Start() {
+	r.logger.Infof("启动训练作业自动重试器，轮询间隔: %v", r.config.Interval)
+
+	r.wg.Add(1)
+	go r.retryLoop()
+}
+
+// Stop 停止重试循环
+func (r *JobRetrier) Stop() {
+	r.cancel()
+	r.wg.Wait()
+	r.logger.Info("训练作业自动重试器已停止")
+}
+
+// retryLoop 重试循环
+func (r *JobRetrier) retryLoop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.RetryOnce(); err != nil {
+			r.logger.Errorf("自动重试训练作业失败: %v", err)
+		}
+
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RetryOnce 执行一轮重试，返回重新提交的作业数
+func (r *JobRetrier) RetryOnce() (int, error) {
+	jobs, err := r.jobModel.FindRetryCandidates(RetriableFailureReasons, r.config.BackoffBase, r.config.BackoffMax, time.Now(), r.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	retried := 0
+	for _, job := range jobs {
+		if r.ctx.Err() != nil {
+			break
+		}
+		ok, err := r.retryJob(job)
+		if err != nil {
+			r.logger.Errorf("重试训练作业失败: ID=%d, %v", job.Id, err)
+			continue
+		}
+		if ok {
+			retried++
+		}
+	}
+
+	if retried > 0 && r.dispatcher != nil {
+		r.dispatcher.Notify()
+	}
+	return retried, nil
+}
+
+// retryJob 重试退避时间已到的作业，返回是否已重试
+func (r *JobRetrier) retryJob(job *model.VtTrainingJobs) (bool, error) {
+	attempts, err := r.retryModel.CountByJobId(job.Id)
+	if err != nil {
+		return false, err
+	}
+	if attempts >= int64(job.MaxRetryCount) {
+		return false, nil
+	}
+
+	// 退避时间已在查询候选作业时过滤，这里只记录本次重试前的退避时长
+	backoff := RetryBackoff(r.config.BackoffBase, r.config.BackoffMax, int(attempts))
+
+	checkpoint, err := r.machine.resumeCheckpoint(job, 0)
+	if err != nil {
+		return false, err
+	}
+
+	// 重试记录与状态变更在同一事务中写入，避免次数统计遗漏导致超过最大重试次数
+	record := &model.VtTrainingJobRetries{
+		JobId:                job.Id,
+		Attempt:              int(attempts) + 1,
+		FailureReason:        job.FailureReason,
+		ExitCode:             job.ExitCode,
+		ErrorMessage:         job.ErrorMessage,
+		FailedVolcanoJobName: job.VolcanoJobName,
+		BackoffSeconds:       int(backoff.Seconds()),
+		FailedAt:             job.EndTime,
+	}
+	t := JobTransition{
+		Action:   JobActionRetry,
+		Operator: SystemOperator,
+		Reason:   fmt.Sprintf("第%d次自动重试，失败原因: %s", attempts+1, job.FailureReason),
+		Retry:    record,
+	}
+	if checkpoint != nil {
+		record.CheckpointId = checkpoint.Id
+		record.CheckpointPath = checkpoint.StoragePath
+		t.Reason = resumeReason(t.Reason, checkpoint)
+		t.Fields = resumeFields(checkpoint)
+	}
+
+	_, err = r.machine.restart(job, t)
+	if err == bizerrors.ErrJobStatusChanged {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	r.logger.Infof("训练作业已自动重试: ID=%d, 第%d/%d次, 原因=%s", job.Id, record.Attempt, job.MaxRetryCount, job.FailureReason)
+	return true, nil
+}
+
+// RetryBackoff 计算第attempts+1次重试前的等待时长
+func RetryBackoff(base, max time.Duration, attempts int) time.Duration {
+	if attempts > 30 {
+		return max
+	}
+	backoff := base << uint(attempts)
+	if backoff <= 0 || backoff > max {
+		return max
+	}
+	return backoff
+}
@@ -304,55 +304,3 @@ func (s *JobScheduler) GetJobStatus(k8sJobName string) (*k8s.JobStatus, error) {
 func (s *JobScheduler) StopJob(k8sJobName string) error {
 	return s.k8sClient.DeleteJob(k8sJobName)
 }
-
-// UpdateJobStatus 更新作业状态
-func (s *JobScheduler) UpdateJobStatus(jobModel model.VtTrainingJobsModel, jobID int64, k8sJobName string) error {
-	status, err := s.GetJobStatus(k8sJobName)
-	if err != nil {
-		s.logger.Errorf("获取K8s作业状态失败: %v", err)
-		return err
-	}
-
-	// 映射K8s状态到业务状态
-	var businessStatus, businessPhase string
-	switch status.Phase {
-	case "Pending":
-		businessStatus = "pending"
-		businessPhase = "waiting"
-	case "Running":
-		businessStatus = "running"
-		businessPhase = "training"
-	case "Succeeded":
-		businessStatus = "completed"
-		businessPhase = "succeeded"
-	case "Failed":
-		businessStatus = "failed"
-		businessPhase = "failed"
-	default:
-		businessStatus = "unknown"
-		businessPhase = "unknown"
-	}
-
-	// 更新数据库中的作业状态
-	return jobModel.UpdateStatus(jobID, businessStatus, businessPhase)
-}
-
-// SyncJobStatus 同步所有作业状态
-func (s *JobScheduler) SyncJobStatus(jobModel model.VtTrainingJobsModel) error {
-	// 获取所有运行中的作业
-	runningJobs, err := jobModel.GetByStatus("running")
-	if err != nil {
-		return fmt.Errorf("获取运行中作业失败: %v", err)
-	}
-
-	// 逐个同步状态
-	for _, job := range runningJobs {
-		if job.VolcanoJobName != "" {
-			if err := s.UpdateJobStatus(jobModel, job.Id, job.VolcanoJobName); err != nil {
-				s.logger.Errorf("同步作业状态失败: job=%s, error=%v", job.Name, err)
-			}
-		}
-	}
-
-	return nil
-}
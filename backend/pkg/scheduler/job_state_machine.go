@@ -0,0 +1,471 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"api/model"
+	"api/pkg/checkpoint"
+	bizerrors "api/pkg/errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 训练作业状态
+const (
+	JobStatusPending    = "pending"
+	JobStatusQueued     = "queued"
+	JobStatusScheduling = "scheduling"
+	JobStatusRunning    = "running"
+	JobStatusSucceeded  = "succeeded"
+	JobStatusFailed     = "failed"
+	JobStatusCancelled  = "cancelled"
+	JobStatusSuspended  = "suspended"
+	JobStatusTimeout    = "timeout"
+	JobStatusOOMKilled  = "oom_killed"
+)
+
+// 训练作业状态变更动作
+const (
+	JobActionSubmit   = "submit"   // 派发器认领并提交到Volcano
+	JobActionSchedule = "schedule" // Volcano开始调度作业
+	JobActionStart    = "start"    // Volcano作业开始运行
+	JobActionSuspend  = "suspend"  // 暂停
+	JobActionResume   = "resume"   // 恢复
+	JobActionRestart  = "restart"  // 重新运行
+	JobActionRetry    = "retry"    // 失败后自动重试
+	JobActionCancel   = "cancel"   // 取消
+	JobActionSucceed  = "succeed"  // 运行成功
+	JobActionFail     = "fail"     // 运行或提交失败
+	JobActionTimeout  = "timeout"  // 超时
+	JobActionOOM      = "oom"      // 内存溢出
+)
+
+// jobTransitions 状态机定义：动作 -> 允许的源状态 -> 目标状态
+var jobTransitions = map[string]struct {
+	from []string
+	to   string
+}{
+	JobActionSubmit:   {from: []string{JobStatusPending}, to: JobStatusQueued},
+	JobActionSchedule: {from: []string{JobStatusQueued}, to: JobStatusScheduling},
+	JobActionStart:    {from: []string{JobStatusQueued, JobStatusScheduling}, to: JobStatusRunning},
+	JobActionSuspend:  {from: []string{JobStatusQueued, JobStatusScheduling, JobStatusRunning}, to: JobStatusSuspended},
+	JobActionResume:   {from: []string{JobStatusSuspended}, to: JobStatusQueued},
+	JobActionRestart:  {from: []string{JobStatusRunning, JobStatusSuspended, JobStatusSucceeded, JobStatusFailed, JobStatusCancelled, JobStatusTimeout, JobStatusOOMKilled}, to: JobStatusPending},
+	JobActionRetry:    {from: []string{JobStatusFailed, JobStatusOOMKilled}, to: JobStatusPending},
+	JobActionCancel:   {from: []string{JobStatusPending, JobStatusQueued, JobStatusScheduling, JobStatusRunning, JobStatusSuspended}, to: JobStatusCancelled},
+	JobActionSucceed:  {from: []string{JobStatusQueued, JobStatusScheduling, JobStatusRunning}, to: JobStatusSucceeded},
+	JobActionFail:     {from: []string{JobStatusPending, JobStatusQueued, JobStatusScheduling, JobStatusRunning, JobStatusSuspended}, to: JobStatusFailed},
+	JobActionTimeout:  {from: []string{JobStatusQueued, JobStatusScheduling, JobStatusRunning, JobStatusSuspended}, to: JobStatusTimeout},
+	JobActionOOM:      {from: []string{JobStatusQueued, JobStatusScheduling, JobStatusRunning}, to: JobStatusOOMKilled},
+}
+
+// jobStatusPhases 进入状态时同步更新的执行阶段
+var jobStatusPhases = map[string]string{
+	JobStatusPending:    "creating",
+	JobStatusQueued:     "scheduling",
+	JobStatusScheduling: "scheduling",
+	JobStatusRunning:    "training",
+	JobStatusSucceeded:  "completed",
+}
+
+// NextJobStatus 计算动作对应的目标状态，不允许的变更返回false
+func NextJobStatus(from, action string) (string, bool) {
+	transition, ok := jobTransitions[action]
+	if !ok {
+		return "", false
+	}
+	for _, status := range transition.from {
+		if status == from {
+			return transition.to, true
+		}
+	}
+	return "", false
+}
+
+// IsTerminalJobStatus 判断是否为终止状态
+func IsTerminalJobStatus(status string) bool {
+	switch status {
+	case JobStatusSucceeded, JobStatusFailed, JobStatusCancelled, JobStatusTimeout, JobStatusOOMKilled:
+		return true
+	}
+	return false
+}
+
+// JobOperator 状态变更的操作人
+type JobOperator struct {
+	UserID   int64
+	Username string
+}
+
+// SystemOperator 后台任务触发变更时使用的操作人
+var SystemOperator = JobOperator{Username: "system"}
+
+// JobTransition 一次状态变更请求
+type JobTransition struct {
+	Action   string
+	Operator JobOperator
+	Reason   string
+	Fields   map[string]interface{}      // 同时更新的附加字段
+	Retry    *model.VtTrainingJobRetries // 与状态变更在同一事务中写入的自动重试记录
+}
+
+// RestartOptions 重新运行时的检查点恢复选项
+type RestartOptions struct {
+	CheckpointId int64 // 从指定的检查点恢复，0表示自动选择最新或最佳的可用检查点
+	FromScratch  bool  // 不从检查点恢复，从头开始训练
+}
+
+// VolcanoJobController 状态机依赖的Volcano作业操作
+type VolcanoJobController interface {
+	SuspendJob(namespace, jobName string) error
+	ResumeJob(namespace, jobName string) error
+	DeleteJob(namespace, jobName string) error
+}
+
+// JobStateMachine 训练作业状态机
+// 所有状态变更都通过条件更新完成并写入变更记录，非法变更返回带错误码的BizError
+type JobStateMachine struct {
+	jobModel        model.VtTrainingJobsModel
+	transitionModel model.VtTrainingJobTransitionsModel
+	controller      VolcanoJobController
+	resumer         *CheckpointResumer
+	stopper         *EarlyStopper
+	logger          logx.Logger
+}
+
+// NewJobStateMachine 创建作业状态机，controller为nil时仅支持无需操作集群的变更
+func NewJobStateMachine(jobModel model.VtTrainingJobsModel, transitionModel model.VtTrainingJobTransitionsModel, controller VolcanoJobController) *JobStateMachine {
+	return &JobStateMachine{
+		jobModel:        jobModel,
+		transitionModel: transitionModel,
+		controller:      controller,
+		logger:          logx.WithContext(context.Background()),
+	}
+}
+
+// SetCheckpointResumer 启用检查点恢复，重新运行、自动重试和恢复暂停的作业时从检查点继续训练
+func (m *JobStateMachine) SetCheckpointResumer(resumer *CheckpointResumer) {
+	m.resumer = resumer
+}
+
+// SetEarlyStopper 启用提前停止，被请求提前停止的作业自行退出时同样记录failure_reason为early_stopped
+func (m *JobStateMachine) SetEarlyStopper(stopper *EarlyStopper) {
+	m.stopper = stopper
+}
+
+// Apply 对已加载的作业执行状态变更，仅更新数据库，返回变更后的状态
+func (m *JobStateMachine) Apply(job *model.VtTrainingJobs, t JobTransition) (string, error) {
+	to, ok := NextJobStatus(job.Status, t.Action)
+	if !ok {
+		return "", bizerrors.NewBusinessError(bizerrors.ErrCodeJobInvalidTransition,
+			fmt.Sprintf("训练作业当前状态为 %s，不允许执行 %s 操作", job.Status, t.Action))
+	}
+
+	fields := make(map[string]interface{}, len(t.Fields)+2)
+	if phase, ok := jobStatusPhases[to]; ok {
+		fields["phase"] = phase
+	}
+	if IsTerminalJobStatus(to) {
+		fields["end_time"] = time.Now()
+	}
+	for column, value := range t.Fields {
+		fields[column] = value
+	}
+	if _, ok := fields["failure_reason"]; !ok && t.Action == JobActionSucceed && m.stopper != nil {
+		if _, requested := m.stopper.StopRequested(job); requested {
+			fields["failure_reason"] = FailureReasonEarlyStopped
+		}
+	}
+
+	volcanoJobName := job.VolcanoJobName
+	if name, ok := fields["volcano_job_name"].(string); ok {
+		volcanoJobName = name
+	}
+
+	record := &model.VtTrainingJobTransitions{
+		JobId:          job.Id,
+		Action:         t.Action,
+		FromStatus:     job.Status,
+		ToStatus:       to,
+		OperatorId:     t.Operator.UserID,
+		OperatorName:   t.Operator.Username,
+		Reason:         t.Reason,
+		VolcanoJobName: volcanoJobName,
+	}
+	if checkpointId, ok := fields["resume_checkpoint_id"].(int64); ok {
+		record.ResumeCheckpointId = checkpointId
+	}
+
+	if t.Retry != nil {
+		t.Retry.RetryVolcanoJobName = volcanoJobName
+	}
+
+	changed, err := m.jobModel.TransitionStatus(job.Id, job.Status, to, fields, record, t.Retry)
+	if err != nil {
+		return "", bizerrors.WrapError(err, bizerrors.ErrCodeDatabaseError, "更新训练作业状态失败")
+	}
+	if !changed {
+		return "", bizerrors.ErrJobStatusChanged
+	}
+
+	m.logger.Infof("训练作业状态变更: ID=%d, %s -> %s, 动作=%s, 操作人=%s, 原因=%s",
+		job.Id, job.Status, to, t.Action, t.Operator.Username, t.Reason)
+	return to, nil
+}
+
+// Suspend 暂停作业
+func (m *JobStateMachine) Suspend(jobID int64, operator JobOperator, reason string) (string, error) {
+	job, err := m.loadForAction(jobID, JobActionSuspend)
+	if err != nil {
+		return "", err
+	}
+
+	// 尚未提交到Volcano的作业只需更新状态，派发器不会处理已暂停的作业
+	if isSubmitted(job) {
+		if err := m.control(func(c VolcanoJobController) error {
+			return c.SuspendJob(job.Namespace, job.VolcanoJobName)
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	return m.Apply(job, JobTransition{Action: JobActionSuspend, Operator: operator, Reason: reason})
+}
+
+// Resume 恢复已暂停的作业
+// 已提交的作业存在比当前恢复点更新的检查点时，删除原Volcano作业并以新的运行从该检查点继续训练
+func (m *JobStateMachine) Resume(jobID int64, operator JobOperator, reason string) (string, error) {
+	job, err := m.loadForAction(jobID, JobActionResume)
+	if err != nil {
+		return "", err
+	}
+
+	// 未提交过的作业恢复为queued后由派发器继续提交
+	if isSubmitted(job) {
+		c, err := m.resumeCheckpoint(job, 0)
+		if err != nil {
+			return "", err
+		}
+		if c != nil && c.Id != job.ResumeCheckpointId {
+			return m.resubmit(job, JobTransition{Action: JobActionResume, Operator: operator, Reason: resumeReason(reason, c), Fields: resumeFields(c)})
+		}
+
+		if err := m.control(func(c VolcanoJobController) error {
+			return c.ResumeJob(job.Namespace, job.VolcanoJobName)
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	return m.Apply(job, JobTransition{Action: JobActionResume, Operator: operator, Reason: reason})
+}
+
+// Cancel 取消作业并删除对应的Volcano作业
+func (m *JobStateMachine) Cancel(jobID int64, operator JobOperator, reason string) (string, error) {
+	job, err := m.loadForAction(jobID, JobActionCancel)
+	if err != nil {
+		return "", err
+	}
+
+	if job.VolcanoJobName != "" {
+		if err := m.control(func(c VolcanoJobController) error {
+			return c.DeleteJob(job.Namespace, job.VolcanoJobName)
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	return m.Apply(job, JobTransition{Action: JobActionCancel, Operator: operator, Reason: reason})
+}
+
+// terminate 删除当前运行的Volcano作业后执行终止类状态变更
+func (m *JobStateMachine) terminate(job *model.VtTrainingJobs, t JobTransition) (string, error) {
+	if _, ok := NextJobStatus(job.Status, t.Action); !ok {
+		return "", bizerrors.NewBusinessError(bizerrors.ErrCodeJobInvalidTransition,
+			fmt.Sprintf("训练作业当前状态为 %s，不允许执行 %s 操作", job.Status, t.Action))
+	}
+
+	if job.VolcanoJobName != "" {
+		if err := m.control(func(c VolcanoJobController) error {
+			return c.DeleteJob(job.Namespace, job.VolcanoJobName)
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	return m.Apply(job, t)
+}
+
+// Restart 删除当前运行并将作业重置为pending，由派发器以新的Volcano作业名重新提交
+// 启用检查点恢复时，未成功的作业默认从最新或最佳的可用检查点继续训练，已成功的作业只在指定检查点时恢复
+func (m *JobStateMachine) Restart(jobID int64, operator JobOperator, reason string, options RestartOptions) (string, error) {
+	job, err := m.loadForAction(jobID, JobActionRestart)
+	if err != nil {
+		return "", err
+	}
+
+	t := JobTransition{Action: JobActionRestart, Operator: operator, Reason: reason}
+	if !options.FromScratch && (options.CheckpointId > 0 || job.Status != JobStatusSucceeded) {
+		c, err := m.resumeCheckpoint(job, options.CheckpointId)
+		if err != nil {
+			return "", err
+		}
+		if c != nil {
+			t.Reason = resumeReason(reason, c)
+			t.Fields = resumeFields(c)
+		}
+	}
+	return m.restart(job, t)
+}
+
+// restart 执行重新运行，t.Fields中的字段会覆盖默认的重置字段
+func (m *JobStateMachine) restart(job *model.VtTrainingJobs, t JobTransition) (string, error) {
+	if job.VolcanoJobName != "" {
+		if err := m.control(func(c VolcanoJobController) error {
+			return c.DeleteJob(job.Namespace, job.VolcanoJobName)
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	runName, err := m.nextRunName(job)
+	if err != nil {
+		return "", err
+	}
+
+	fields := map[string]interface{}{
+		"volcano_job_name":       runName,
+		"queued_at":              nil,
+		"scheduled_at":           nil,
+		"start_time":             nil,
+		"end_time":               nil,
+		"exit_code":              nil,
+		"error_code":             nil,
+		"error_message":          nil,
+		"failure_reason":         nil,
+		"resume_checkpoint_id":   nil,
+		"resume_checkpoint_path": nil,
+	}
+	for column, value := range t.Fields {
+		fields[column] = value
+	}
+	t.Fields = fields
+
+	return m.Apply(job, t)
+}
+
+// resubmit 删除已暂停的Volcano作业，以新的运行名恢复为queued，由派发器重新提交
+func (m *JobStateMachine) resubmit(job *model.VtTrainingJobs, t JobTransition) (string, error) {
+	if err := m.control(func(c VolcanoJobController) error {
+		return c.DeleteJob(job.Namespace, job.VolcanoJobName)
+	}); err != nil {
+		return "", err
+	}
+
+	runName, err := m.nextRunName(job)
+	if err != nil {
+		return "", err
+	}
+	fields := map[string]interface{}{
+		"volcano_job_name": runName,
+		"scheduled_at":     nil,
+	}
+	for column, value := range t.Fields {
+		fields[column] = value
+	}
+	t.Fields = fields
+
+	return m.Apply(job, t)
+}
+
+// nextRunName 生成下一次运行的Volcano作业名
+// Volcano作业删除是异步的，新一次运行使用带序号的作业名避免冲突
+func (m *JobStateMachine) nextRunName(job *model.VtTrainingJobs) (string, error) {
+	runs, err := m.transitionModel.CountByActions(job.Id, JobActionRestart, JobActionRetry, JobActionResume)
+	if err != nil {
+		return "", bizerrors.WrapError(err, bizerrors.ErrCodeDatabaseError, "查询训练作业运行次数失败")
+	}
+	return BuildVolcanoRunName(job, int(runs)+1), nil
+}
+
+// resumeCheckpoint 选择恢复用的检查点，checkpointId为0时自动选择，未启用检查点恢复或没有可用检查点时返回nil
+func (m *JobStateMachine) resumeCheckpoint(job *model.VtTrainingJobs, checkpointId int64) (*model.VtTrainingCheckpoints, error) {
+	if m.resumer == nil {
+		if checkpointId > 0 {
+			return nil, bizerrors.NewBizError(bizerrors.ErrCodeServiceUnavailable, "检查点恢复未启用", bizerrors.ErrorTypeExternal)
+		}
+		return nil, nil
+	}
+
+	if checkpointId > 0 {
+		c, err := m.resumer.Load(job, checkpointId)
+		switch {
+		case err == sql.ErrNoRows:
+			return nil, bizerrors.ErrCheckpointNotFound
+		case errors.Is(err, checkpoint.ErrCheckpointUnavailable):
+			return nil, bizerrors.NewBizError(bizerrors.ErrCodeCheckpointInvalid, err.Error(), bizerrors.ErrorTypeValidation)
+		case err != nil:
+			return nil, bizerrors.WrapError(err, bizerrors.ErrCodeDatabaseError, "查询检查点失败")
+		}
+		return c, nil
+	}
+
+	c, err := m.resumer.Select(job)
+	if err != nil {
+		return nil, bizerrors.WrapError(err, bizerrors.ErrCodeExternalService, "选择恢复用的检查点失败")
+	}
+	return c, nil
+}
+
+// resumeFields 从检查点恢复时需要更新的作业字段
+func resumeFields(c *model.VtTrainingCheckpoints) map[string]interface{} {
+	return map[string]interface{}{
+		"resume_checkpoint_id":   c.Id,
+		"resume_checkpoint_path": c.StoragePath,
+	}
+}
+
+// resumeReason 在变更原因中记录恢复用的检查点
+func resumeReason(reason string, c *model.VtTrainingCheckpoints) string {
+	if reason == "" {
+		return fmt.Sprintf("从检查点 %s 恢复", c.CheckpointName)
+	}
+	return fmt.Sprintf("%s，从检查点 %s 恢复", reason, c.CheckpointName)
+}
+
+// loadForAction 加载作业并预先校验动作是否合法
+func (m *JobStateMachine) loadForAction(jobID int64, action string) (*model.VtTrainingJobs, error) {
+	job, err := m.jobModel.FindOneDetail(jobID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, bizerrors.ErrJobNotFound
+		}
+		return nil, bizerrors.WrapError(err, bizerrors.ErrCodeDatabaseError, "查询训练作业失败")
+	}
+
+	if _, ok := NextJobStatus(job.Status, action); !ok {
+		return nil, bizerrors.NewBusinessError(bizerrors.ErrCodeJobInvalidTransition,
+			fmt.Sprintf("训练作业当前状态为 %s，不允许执行 %s 操作", job.Status, action))
+	}
+	return job, nil
+}
+
+// control 调用Volcano执行作业操作
+func (m *JobStateMachine) control(fn func(c VolcanoJobController) error) error {
+	if m.controller == nil {
+		return bizerrors.NewBizError(bizerrors.ErrCodeServiceUnavailable, "Volcano客户端不可用，无法操作集群中的作业", bizerrors.ErrorTypeExternal)
+	}
+	if err := fn(m.controller); err != nil {
+		return bizerrors.NewBizError(bizerrors.ErrCodeJobControlFailed, fmt.Sprintf("操作Volcano作业失败: %v", err), bizerrors.ErrorTypeExternal)
+	}
+	return nil
+}
+
+// isSubmitted 判断作业是否已成功提交到Volcano
+func isSubmitted(job *model.VtTrainingJobs) bool {
+	return job.VolcanoJobName != "" && job.ScheduledAt != nil
+}
@@ -0,0 +1,336 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"api/model"
+	"api/pkg/alerting"
+	bizerrors "api/pkg/errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 看门狗终止作业时写入的失败原因
+const (
+	FailureReasonTimeout = "timeout"
+	FailureReasonIdle    = "idle"
+)
+
+// JobNotifier 看门狗发送预警通知的接口，由NotificationManager实现
+type JobNotifier interface {
+	SendNotification(alert *alerting.AlertNotification) error
+}
+
+// GPUUsageSource 作业GPU使用率数据源
+type GPUUsageSource interface {
+	// GetGpuUsage 返回作业运行实例在since之后采集到的最大GPU使用率，没有采集数据时返回false
+	GetGpuUsage(jobId int64, since time.Time) (float64, bool, error)
+}
+
+// WatchdogConfig 看门狗配置
+type WatchdogConfig struct {
+	Interval          time.Duration // 检查间隔
+	WarnBefore        time.Duration // 终止前多久发送预警
+	IdleGPUThreshold  float64       // GPU使用率低于该值(百分比)视为空闲
+	NotifyChannels    []string      // 作业未配置通知渠道时使用的默认渠道
+	UsageSampleMaxAge time.Duration // GPU使用率采集数据的有效期
+}
+
+// jobNotificationConfig 作业notification_config字段中看门狗使用的部分
+type jobNotificationConfig struct {
+	Channels   []string `json:"channels"`
+	Recipients []string `json:"recipients"`
+}
+
+// watchState 单个运行中作业的看门狗状态，作业重新运行后重置
+type watchState struct {
+	run        string
+	idleSince  *time.Time
+	warnedRun  bool
+	warnedIdle bool
+}
+
+// JobWatchdog 训练作业看门狗
+// 运行时长超过max_runtime_seconds，或GPU使用率持续低于阈值超过max_idle_seconds的作业会被终止，
+// 终止前通过通知管理器向作业负责人预警，终止后记录failure_reason为timeout或idle
+type JobWatchdog struct {
+	jobModel    model.VtTrainingJobsModel
+	usageSource GPUUsageSource
+	machine     *JobStateMachine
+	notifier    JobNotifier
+	config      WatchdogConfig
+	logger      logx.Logger
+
+	mu     sync.Mutex
+	states map[int64]*watchState
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewJobWatchdog 创建看门狗，notifier为nil时只记录日志不发送预警
+func NewJobWatchdog(jobModel model.VtTrainingJobsModel, usageSource GPUUsageSource, machine *JobStateMachine,
+	notifier JobNotifier, config WatchdogConfig) *JobWatchdog {
+	if config.Interval <= 0 {
+		config.Interval = 30 * time.Second
+	}
+	if config.WarnBefore < 0 {
+		config.WarnBefore = 0
+	}
+	if config.IdleGPUThreshold <= 0 {
+		config.IdleGPUThreshold = 5
+	}
+	if config.UsageSampleMaxAge <= 0 {
+		config.UsageSampleMaxAge = 3 * config.Interval
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &JobWatchdog{
+		jobModel:    jobModel,
+		usageSource: usageSource,
+		machine:     machine,
+		notifier:    notifier,
+		config:      config,
+		logger:      logx.WithContext(context.Background()),
+		states:      make(map[int64]*watchState),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Start 启动检查循环
+func (w *JobWatchdog) Start() {
+	w.logger.Infof("启动训练作业看门狗，检查间隔: %v", w.config.Interval)
+
+	w.wg.Add(1)
+	go w.watchLoop()
+}
+
+// Stop 停止检查循环
+func (w *JobWatchdog) Stop() {
+	w.cancel()
+	w.wg.Wait()
+	w.logger.Info("训练作业看门狗已停止")
+}
+
+// watchLoop 检查循环
+func (w *JobWatchdog) watchLoop() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.CheckOnce(time.Now()); err != nil {
+			w.logger.Errorf("训练作业看门狗检查失败: %v", err)
+		}
+
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckOnce 检查所有运行中的作业，返回本轮终止的作业数
+func (w *JobWatchdog) CheckOnce(now time.Time) (int, error) {
+	jobs, err := w.jobModel.FindRunning()
+	if err != nil {
+		return 0, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	running := make(map[int64]bool, len(jobs))
+	terminated := 0
+	for _, job := range jobs {
+		running[job.Id] = true
+		ok, err := w.checkJob(job, now)
+		if err != nil {
+			w.logger.Errorf("检查训练作业失败: ID=%d, %v", job.Id, err)
+			continue
+		}
+		if ok {
+			terminated++
+			delete(w.states, job.Id)
+		}
+	}
+
+	// 已结束的作业不再跟踪
+	for id := range w.states {
+		if !running[id] {
+			delete(w.states, id)
+		}
+	}
+	return terminated, nil
+}
+
+// checkJob 检查单个作业，返回是否已终止
+func (w *JobWatchdog) checkJob(job *model.VtTrainingJobs, now time.Time) (bool, error) {
+	state := w.states[job.Id]
+	if state == nil || state.run != job.VolcanoJobName {
+		state = &watchState{run: job.VolcanoJobName}
+		w.states[job.Id] = state
+	}
+
+	if job.MaxRuntimeSeconds > 0 && job.StartTime != nil {
+		limit := time.Duration(job.MaxRuntimeSeconds) * time.Second
+		elapsed := now.Sub(*job.StartTime)
+		if elapsed >= limit {
+			message := fmt.Sprintf("运行时长 %s 超过上限 %s", formatDuration(elapsed), formatDuration(limit))
+			return w.terminate(job, FailureReasonTimeout, message)
+		}
+		if !state.warnedRun && elapsed >= limit-w.config.WarnBefore {
+			state.warnedRun = true
+			w.warn(job, FailureReasonTimeout, fmt.Sprintf("训练作业 %s 已运行 %s，将在 %s 后因超过最大运行时长被终止",
+				job.Name, formatDuration(elapsed), formatDuration(limit-elapsed)), elapsed.Seconds(), limit.Seconds())
+		}
+	}
+
+	if job.MaxIdleSeconds > 0 && job.GpuCount > 0 && w.usageSource != nil {
+		usage, sampled, err := w.usageSource.GetGpuUsage(job.Id, now.Add(-w.config.UsageSampleMaxAge))
+		if err != nil {
+			return false, fmt.Errorf("查询GPU使用率失败: %w", err)
+		}
+
+		// 没有采集数据时无法判断是否空闲，重新开始计时
+		if !sampled || usage >= w.config.IdleGPUThreshold {
+			state.idleSince = nil
+			state.warnedIdle = false
+			return false, nil
+		}
+		if state.idleSince == nil {
+			idleSince := now
+			state.idleSince = &idleSince
+		}
+
+		limit := time.Duration(job.MaxIdleSeconds) * time.Second
+		idle := now.Sub(*state.idleSince)
+		if idle >= limit {
+			message := fmt.Sprintf("GPU使用率 %.1f%% 低于 %.1f%% 已持续 %s，超过空闲上限 %s",
+				usage, w.config.IdleGPUThreshold, formatDuration(idle), formatDuration(limit))
+			return w.terminate(job, FailureReasonIdle, message)
+		}
+		if !state.warnedIdle && idle >= limit-w.config.WarnBefore {
+			state.warnedIdle = true
+			w.warn(job, FailureReasonIdle, fmt.Sprintf("训练作业 %s 的GPU使用率 %.1f%% 已持续 %s 低于 %.1f%%，将在 %s 后因空闲被终止",
+				job.Name, usage, formatDuration(idle), w.config.IdleGPUThreshold, formatDuration(limit-idle)), idle.Seconds(), limit.Seconds())
+		}
+	}
+
+	return false, nil
+}
+
+// terminate 删除Volcano作业并将作业标记为超时
+func (w *JobWatchdog) terminate(job *model.VtTrainingJobs, reason, message string) (bool, error) {
+	_, err := w.machine.terminate(job, JobTransition{
+		Action:   JobActionTimeout,
+		Operator: SystemOperator,
+		Reason:   message,
+		Fields: map[string]interface{}{
+			"failure_reason": reason,
+			"error_code":     watchdogErrorCode(reason),
+			"error_message":  message,
+		},
+	})
+	if err == bizerrors.ErrJobStatusChanged {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	w.logger.Infof("训练作业已被看门狗终止: ID=%d, 原因=%s, %s", job.Id, reason, message)
+	w.notify(job, reason, "critical", fmt.Sprintf("训练作业 %s 已被终止: %s", job.Name, message), 0, 0)
+	return true, nil
+}
+
+// warn 发送即将终止的预警
+func (w *JobWatchdog) warn(job *model.VtTrainingJobs, reason, message string, value, threshold float64) {
+	w.logger.Infof("训练作业即将被看门狗终止: ID=%d, 原因=%s, %s", job.Id, reason, message)
+	w.notify(job, reason, "warning", message, value, threshold)
+}
+
+// notify 通过通知管理器发送通知，作业未配置渠道时使用默认渠道
+func (w *JobWatchdog) notify(job *model.VtTrainingJobs, reason, level, message string, value, threshold float64) {
+	if w.notifier == nil {
+		return
+	}
+
+	var config jobNotificationConfig
+	if job.NotificationConfig != "" {
+		if err := json.Unmarshal([]byte(job.NotificationConfig), &config); err != nil {
+			w.logger.Errorf("解析作业通知配置失败: ID=%d, %v", job.Id, err)
+		}
+	}
+	channels := config.Channels
+	if len(channels) == 0 {
+		channels = w.config.NotifyChannels
+	}
+	if len(channels) == 0 {
+		return
+	}
+
+	now := time.Now()
+	ruleName := "training_job_" + reason
+	alert := &alerting.AlertNotification{
+		Alert: &alerting.ActiveAlert{
+			ID:             fmt.Sprintf("%s_%d_%s_%s", ruleName, job.Id, job.VolcanoJobName, level),
+			RuleName:       ruleName,
+			AlertLevel:     level,
+			Message:        message,
+			Summary:        message,
+			ResourceType:   "training_job",
+			ResourceID:     job.Id,
+			ResourceName:   job.Name,
+			InstanceID:     job.VolcanoJobName,
+			TriggerValue:   value,
+			ThresholdValue: threshold,
+			Labels: map[string]interface{}{
+				"job_id":         job.Id,
+				"job_name":       job.Name,
+				"failure_reason": reason,
+			},
+			Context: map[string]interface{}{
+				"recipients": config.Recipients,
+			},
+			Status:            "firing",
+			TriggeredAt:       now,
+			FirstOccurrenceAt: now,
+			LastOccurrenceAt:  now,
+			OccurrenceCount:   1,
+		},
+		RuleInfo: &alerting.AlertRule{
+			Name:                 ruleName,
+			DisplayName:          "训练作业看门狗",
+			RuleType:             "training_job",
+			AlertLevel:           level,
+			NotificationChannels: channels,
+		},
+		Action: "firing",
+	}
+
+	if err := w.notifier.SendNotification(alert); err != nil {
+		w.logger.Errorf("发送训练作业看门狗通知失败: ID=%d, %v", job.Id, err)
+	}
+}
+
+// watchdogErrorCode 终止原因对应的错误码
+func watchdogErrorCode(reason string) string {
+	if reason == FailureReasonIdle {
+		return "IDLE_TIMEOUT"
+	}
+	return "RUNTIME_EXCEEDED"
+}
+
+// formatDuration 将时长格式化到秒
+func formatDuration(d time.Duration) string {
+	return d.Truncate(time.Second).String()
+}
@@ -0,0 +1,246 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"api/model"
+	"api/pkg/logstream"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// vt_training_logs中log_source和correlation_id列的最大长度
+const (
+	maxLogSourceLength     = 64
+	maxCorrelationIdLength = 128
+)
+
+// LogArchiverConfig 日志归档配置
+type LogArchiverConfig struct {
+	Interval   time.Duration // 归档间隔
+	ChunkLines int           // 每个分片的最大行数
+	Grace      time.Duration // 作业结束后继续归档的时长，Pod被清理前读取最后的日志
+}
+
+// LogArchiver 训练日志归档器
+// 定期读取已提交作业全部实例的容器日志，按实例写入压缩分片并将每行日志索引到vt_training_logs；
+// 续传位置由已写入的分片文件名决定，服务重启后从上次归档的位置继续，不会重复建立索引
+type LogArchiver struct {
+	jobModel      model.VtTrainingJobsModel
+	instanceModel model.VtTrainingJobInstancesModel
+	logModel      model.VtTrainingLogsModel
+	streamer      *logstream.Streamer
+	archive       *logstream.Archive
+	config        LogArchiverConfig
+	logger        logx.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewLogArchiver 创建日志归档器
+func NewLogArchiver(jobModel model.VtTrainingJobsModel, instanceModel model.VtTrainingJobInstancesModel, logModel model.VtTrainingLogsModel,
+	streamer *logstream.Streamer, archive *logstream.Archive, config LogArchiverConfig) *LogArchiver {
+	if config.Interval <= 0 {
+		config.Interval = 60 * time.Second
+	}
+	if config.ChunkLines <= 0 {
+		config.ChunkLines = 5000
+	}
+	if config.Grace <= 0 {
+		config.Grace = 10 * time.Minute
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &LogArchiver{
+		jobModel:      jobModel,
+		instanceModel: instanceModel,
+		logModel:      logModel,
+		streamer:      streamer,
+		archive:       archive,
+		config:        config,
+		logger:        logx.WithContext(context.Background()),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+// Start 启动归档循环
+func (a *LogArchiver) Start() {
+	a.logger.Infof("启动训练日志归档，归档间隔: %v", a.config.Interval)
+
+	a.wg.Add(1)
+	go a.loop()
+}
+
+// Stop 停止归档循环
+func (a *LogArchiver) Stop() {
+	a.cancel()
+	a.wg.Wait()
+	a.logger.Info("训练日志归档已停止")
+}
+
+// loop 归档循环
+func (a *LogArchiver) loop() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(a.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := a.ReconcileOnce(); err != nil {
+			a.logger.Errorf("归档训练日志失败: %v", err)
+		}
+
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReconcileOnce 归档运行中和刚结束的作业的新日志
+func (a *LogArchiver) ReconcileOnce() error {
+	submitted, err := a.jobModel.FindSubmitted()
+	if err != nil {
+		return err
+	}
+	finished, err := a.jobModel.FindFinishedSince(time.Now().Add(-a.config.Grace))
+	if err != nil {
+		return err
+	}
+
+	for _, job := range append(submitted, finished...) {
+		if a.ctx.Err() != nil {
+			break
+		}
+		if err := a.ArchiveJob(a.ctx, job); err != nil {
+			a.logger.Errorf("归档训练作业日志失败: ID=%d, %v", job.Id, err)
+		}
+	}
+	return nil
+}
+
+// ArchiveJob 读取作业各实例自上次归档之后的日志，写入分片并建立索引
+// 只按作业ID选择Pod，重试前后的各次运行都会被归档
+func (a *LogArchiver) ArchiveJob(ctx context.Context, job *model.VtTrainingJobs) error {
+	cursor, err := a.archive.Cursor(job.Id)
+	if err != nil {
+		return fmt.Errorf("读取归档位置失败: %w", err)
+	}
+	instances, err := a.instanceModel.FindByJobId(job.Id)
+	if err != nil {
+		return fmt.Errorf("查询作业实例失败: %w", err)
+	}
+
+	w := &archiveWriter{
+		archiver:  a,
+		job:       job,
+		instances: make(map[string]int64, len(instances)),
+		buffers:   make(map[string][]*logstream.Line),
+	}
+	for _, instance := range instances {
+		w.instances[instance.InstanceName] = instance.Id
+	}
+
+	selector := labels.SelectorFromSet(labels.Set{JobIDLabelKey: strconv.FormatInt(job.Id, 10)})
+	err = a.streamer.Stream(ctx, logstream.Options{Namespace: job.Namespace, Selector: selector.String(), Cursor: cursor}, w)
+	// 已读取的日志先落盘，下次从已归档的位置继续
+	if flushErr := w.flushAll(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+// archiveWriter 按实例缓存日志行，达到分片行数时写入分片
+type archiveWriter struct {
+	archiver  *LogArchiver
+	job       *model.VtTrainingJobs
+	instances map[string]int64 // 实例名到实例ID
+	buffers   map[string][]*logstream.Line
+}
+
+func (w *archiveWriter) WriteLine(line *logstream.Line) error {
+	// 没有时间戳的行沿用上一行的时间，分片名和续传位置依赖日志时间
+	if buffer := w.buffers[line.Pod]; line.Time.IsZero() && len(buffer) > 0 {
+		line.Time = buffer[len(buffer)-1].Time
+	}
+	w.buffers[line.Pod] = append(w.buffers[line.Pod], line)
+	if len(w.buffers[line.Pod]) >= w.archiver.config.ChunkLines {
+		return w.flush(line.Pod)
+	}
+	return nil
+}
+
+func (w *archiveWriter) Ping() error {
+	return nil
+}
+
+func (w *archiveWriter) WriteEnd(string) error {
+	return nil
+}
+
+// flushAll 写入全部实例缓存的日志
+func (w *archiveWriter) flushAll() error {
+	var firstErr error
+	for pod := range w.buffers {
+		if err := w.flush(pod); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// flush 将实例缓存的日志写入分片并建立索引，索引写入失败时删除分片，下次归档重新读取
+func (w *archiveWriter) flush(pod string) error {
+	lines := w.buffers[pod]
+	delete(w.buffers, pod)
+	if len(lines) == 0 {
+		return nil
+	}
+
+	chunk, err := w.archiver.archive.WriteChunk(w.job.Id, pod, lines)
+	if err != nil {
+		return fmt.Errorf("写入日志分片失败: %s, %w", pod, err)
+	}
+
+	logs := make([]*model.VtTrainingLogs, 0, len(lines))
+	for i, line := range lines {
+		position, _ := json.Marshal(map[string]interface{}{"chunk": chunk.Path, "line": i + 1})
+		logs = append(logs, &model.VtTrainingLogs{
+			JobId:         w.job.Id,
+			InstanceId:    w.instances[pod],
+			LogLevel:      line.Level,
+			LogSource:     truncate(pod, maxLogSourceLength),
+			LogContent:    line.Content,
+			LogFormat:     logstream.LogFormat(line.Content),
+			LogTime:       line.Time,
+			Context:       string(position),
+			CorrelationId: truncate(logstream.CorrelationID(line.Content), maxCorrelationIdLength),
+			Category:      line.Task,
+		})
+	}
+	if err := w.archiver.logModel.BatchInsert(logs); err != nil {
+		if removeErr := w.archiver.archive.RemoveChunk(chunk); removeErr != nil {
+			w.archiver.logger.Errorf("删除未建立索引的日志分片失败: %s, %v", chunk.Path, removeErr)
+		}
+		return fmt.Errorf("写入日志索引失败: %s, %w", pod, err)
+	}
+	return nil
+}
+
+// truncate 按字符截断字符串
+func truncate(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n])
+	}
+	return s
+}
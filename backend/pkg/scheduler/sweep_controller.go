@@ -0,0 +1,463 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"api/model"
+	bizerrors "api/pkg/errors"
+	"api/pkg/sweep"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// SweepEntityType 试验作业关联到超参数搜索时使用的实体类型
+	SweepEntityType = "sweep"
+	// SweepTrialRelation 试验作业与超参数搜索的关联类型
+	SweepTrialRelation = "sweep_trial"
+	// FailureReasonPruned 试验被提前终止策略终止时写入的失败原因
+	FailureReasonPruned = "pruned"
+)
+
+// TrainingJobCreator 通过常规创建流程(参数校验、名称查重、入库)创建训练作业
+// spec为创建训练作业请求的JSON，返回新作业ID
+type TrainingJobCreator interface {
+	CreateTrainingJob(ctx context.Context, spec []byte) (int64, error)
+}
+
+// SweepTrialMetadata 试验关联关系中记录的元数据
+type SweepTrialMetadata struct {
+	Trial  int                    `json:"trial"`
+	Params map[string]interface{} `json:"params"`
+}
+
+// SweepTrial 超参数搜索中的一次试验
+type SweepTrial struct {
+	Index         int
+	JobId         int64
+	JobName       string
+	Status        string
+	FailureReason string
+	Params        map[string]interface{}
+	Series        []sweep.Point
+	Objective     *float64 // 已上报目标指标中的最优值
+	LastStep      int64
+
+	job *model.VtTrainingJobs
+}
+
+// Pruned 试验是否被提前终止
+func (t *SweepTrial) Pruned() bool {
+	return t.FailureReason == FailureReasonPruned
+}
+
+// SweepTracker 读取超参数搜索的试验作业及其目标指标
+type SweepTracker struct {
+	jobModel      model.VtTrainingJobsModel
+	relationModel model.VtTrainingJobRelationsModel
+	metricsModel  model.VtTrainingMetricsModel
+}
+
+// NewSweepTracker 创建试验读取器
+func NewSweepTracker(jobModel model.VtTrainingJobsModel, relationModel model.VtTrainingJobRelationsModel,
+	metricsModel model.VtTrainingMetricsModel) *SweepTracker {
+	return &SweepTracker{
+		jobModel:      jobModel,
+		relationModel: relationModel,
+		metricsModel:  metricsModel,
+	}
+}
+
+// Trials 按试验序号返回搜索的全部试验
+func (t *SweepTracker) Trials(s *model.VtTrainingSweeps) ([]*SweepTrial, error) {
+	relations, err := t.relationModel.FindByEntity(SweepEntityType, s.Id, SweepTrialRelation)
+	if err != nil {
+		return nil, fmt.Errorf("查询试验关联失败: %w", err)
+	}
+
+	trials := make([]*SweepTrial, 0, len(relations))
+	for _, relation := range relations {
+		var metadata SweepTrialMetadata
+		if relation.Metadata != "" {
+			if err := json.Unmarshal([]byte(relation.Metadata), &metadata); err != nil {
+				return nil, fmt.Errorf("解析试验元数据失败: 关联ID=%d, %v", relation.Id, err)
+			}
+		}
+
+		job, err := t.jobModel.FindOneDetail(relation.JobId)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("查询试验作业失败: ID=%d, %w", relation.JobId, err)
+		}
+
+		points, err := t.metricsModel.FindScalarSeries(job.Id, s.ObjectiveMetric)
+		if err != nil {
+			return nil, fmt.Errorf("查询试验目标指标失败: ID=%d, %w", job.Id, err)
+		}
+
+		trial := &SweepTrial{
+			Index:         metadata.Trial,
+			JobId:         job.Id,
+			JobName:       job.Name,
+			Status:        job.Status,
+			FailureReason: job.FailureReason,
+			Params:        metadata.Params,
+			job:           job,
+		}
+		for _, point := range points {
+			trial.Series = append(trial.Series, sweep.Point{Step: point.Step, Value: point.Value})
+		}
+		if len(trial.Series) > 0 {
+			trial.LastStep = trial.Series[len(trial.Series)-1].Step
+			best, _ := sweep.BestUntil(trial.Series, trial.LastStep, s.ObjectiveGoal)
+			trial.Objective = &best
+		}
+		trials = append(trials, trial)
+	}
+
+	sort.SliceStable(trials, func(i, j int) bool { return trials[i].Index < trials[j].Index })
+	return trials, nil
+}
+
+// Leaderboard 按目标值从优到差排序试验，没有上报目标指标的试验排在最后
+func Leaderboard(trials []*SweepTrial, goal string) []*SweepTrial {
+	ranked := make([]*SweepTrial, len(trials))
+	copy(ranked, trials)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i].Objective, ranked[j].Objective
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return sweep.Better(*a, *b, goal)
+	})
+	return ranked
+}
+
+// SweepConfig 超参数搜索控制器配置
+type SweepConfig struct {
+	Interval time.Duration // 调度间隔
+}
+
+// SweepController 超参数搜索控制器
+// 按搜索算法生成超参数并通过常规创建流程创建试验作业，控制并行试验数，
+// 根据vt_training_metrics中的目标指标提前终止表现较差的试验，全部试验结束后汇总最优结果
+type SweepController struct {
+	sweepModel    model.VtTrainingSweepsModel
+	relationModel model.VtTrainingJobRelationsModel
+	tracker       *SweepTracker
+	machine       *JobStateMachine
+	creator       TrainingJobCreator
+	config        SweepConfig
+	logger        logx.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewSweepController 创建超参数搜索控制器
+func NewSweepController(sweepModel model.VtTrainingSweepsModel, relationModel model.VtTrainingJobRelationsModel, tracker *SweepTracker,
+	machine *JobStateMachine, creator TrainingJobCreator, config SweepConfig) *SweepController {
+	if config.Interval <= 0 {
+		config.Interval = 15 * time.Second
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &SweepController{
+		sweepModel:    sweepModel,
+		relationModel: relationModel,
+		tracker:       tracker,
+		machine:       machine,
+		creator:       creator,
+		config:        config,
+		logger:        logx.WithContext(context.Background()),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+// Start 启动调度循环
+func (c *SweepController) Start() {
+	c.logger.Infof("启动超参数搜索控制器，调度间隔: %v", c.config.Interval)
+
+	c.wg.Add(1)
+	go c.loop()
+}
+
+// Stop 停止调度循环
+func (c *SweepController) Stop() {
+	c.cancel()
+	c.wg.Wait()
+	c.logger.Info("超参数搜索控制器已停止")
+}
+
+// loop 调度循环
+func (c *SweepController) loop() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.ReconcileOnce(); err != nil {
+			c.logger.Errorf("超参数搜索调度失败: %v", err)
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReconcileOnce 调度所有运行中的搜索
+func (c *SweepController) ReconcileOnce() error {
+	sweeps, err := c.sweepModel.FindRunning()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range sweeps {
+		if err := c.reconcile(s); err != nil {
+			c.logger.Errorf("调度超参数搜索失败: ID=%d, %v", s.Id, err)
+		}
+	}
+	return nil
+}
+
+// reconcile 调度单个搜索：终止较差试验、补充新试验、更新进度并判断是否结束
+func (c *SweepController) reconcile(s *model.VtTrainingSweeps) error {
+	space, err := sweep.ParseSearchSpace(s.SearchSpace)
+	if err != nil {
+		return c.fail(s, err.Error())
+	}
+	policy, err := sweep.ParseEarlyStopping(s.EarlyStopping)
+	if err != nil {
+		return c.fail(s, err.Error())
+	}
+
+	trials, err := c.tracker.Trials(s)
+	if err != nil {
+		return err
+	}
+
+	if policy != nil {
+		c.prune(s, policy, trials)
+	}
+
+	active := 0
+	for _, trial := range trials {
+		if !IsTerminalJobStatus(trial.Status) {
+			active++
+		}
+	}
+
+	exhausted := false
+	for active < s.Parallelism && len(trials) < s.MaxTrials {
+		index := nextTrialIndex(trials)
+		rng := rand.New(rand.NewSource(s.Id*1000003 + int64(index)))
+		params, ok, err := sweep.Suggest(s.Algorithm, space, index, observations(trials), s.ObjectiveGoal, rng)
+		if err != nil {
+			return c.fail(s, err.Error())
+		}
+		if !ok {
+			exhausted = true
+			break
+		}
+
+		trial, err := c.launch(s, index, params)
+		if err != nil {
+			return c.fail(s, fmt.Sprintf("创建第%d个试验失败: %v", index+1, err))
+		}
+		trials = append(trials, trial)
+		active++
+	}
+	if s.Algorithm == sweep.AlgorithmGrid && !exhausted {
+		if size, err := space.GridSize(); err == nil && len(trials) >= size {
+			exhausted = true
+		}
+	}
+
+	progress := summarize(s, trials)
+	if err := c.sweepModel.UpdateProgress(s.Id, progress); err != nil {
+		return fmt.Errorf("更新搜索进度失败: %w", err)
+	}
+
+	if active > 0 || (len(trials) < s.MaxTrials && !exhausted) {
+		return nil
+	}
+	if progress.BestValue == nil {
+		return c.fail(s, fmt.Sprintf("所有试验均未上报目标指标 %s", s.ObjectiveMetric))
+	}
+	if _, err := c.sweepModel.Finish(s.Id, model.SweepStatusCompleted, ""); err != nil {
+		return err
+	}
+	c.logger.Infof("超参数搜索完成: ID=%d, 最优作业ID=%d, %s=%g", s.Id, progress.BestJobId, s.ObjectiveMetric, *progress.BestValue)
+	return nil
+}
+
+// prune 终止表现较差的运行中试验
+func (c *SweepController) prune(s *model.VtTrainingSweeps, policy *sweep.EarlyStopping, trials []*SweepTrial) {
+	for _, trial := range trials {
+		if trial.Status != JobStatusRunning || len(trial.Series) == 0 {
+			continue
+		}
+
+		var others [][]sweep.Point
+		for _, other := range trials {
+			if other != trial && len(other.Series) > 0 {
+				others = append(others, other.Series)
+			}
+		}
+		stop, reason := policy.ShouldStop(trial.Series, others, s.ObjectiveGoal)
+		if !stop {
+			continue
+		}
+
+		message := fmt.Sprintf("超参数搜索提前终止: %s", reason)
+		status, err := c.machine.terminate(trial.job, JobTransition{
+			Action:   JobActionCancel,
+			Operator: SystemOperator,
+			Reason:   message,
+			Fields: map[string]interface{}{
+				"failure_reason": FailureReasonPruned,
+				"error_message":  message,
+			},
+		})
+		if err == bizerrors.ErrJobStatusChanged {
+			continue
+		}
+		if err != nil {
+			c.logger.Errorf("提前终止试验失败: 搜索ID=%d, 作业ID=%d, %v", s.Id, trial.JobId, err)
+			continue
+		}
+
+		trial.Status = status
+		trial.FailureReason = FailureReasonPruned
+		c.logger.Infof("试验已被提前终止: 搜索ID=%d, 作业ID=%d, %s", s.Id, trial.JobId, reason)
+	}
+}
+
+// launch 创建试验作业并关联到搜索
+func (c *SweepController) launch(s *model.VtTrainingSweeps, index int, params map[string]interface{}) (*SweepTrial, error) {
+	spec, name, err := buildTrialSpec(s, index, params)
+	if err != nil {
+		return nil, err
+	}
+
+	jobId, err := c.creator.CreateTrainingJob(c.ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := json.Marshal(SweepTrialMetadata{Trial: index, Params: params})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.relationModel.Insert(&model.VtTrainingJobRelations{
+		JobId:        jobId,
+		EntityType:   SweepEntityType,
+		EntityId:     s.Id,
+		RelationType: SweepTrialRelation,
+		SortOrder:    index,
+		Metadata:     string(metadata),
+	}); err != nil {
+		return nil, fmt.Errorf("关联试验作业失败: %w", err)
+	}
+
+	c.logger.Infof("创建超参数搜索试验: 搜索ID=%d, 序号=%d, 作业ID=%d", s.Id, index, jobId)
+	return &SweepTrial{Index: index, JobId: jobId, JobName: name, Status: JobStatusPending, Params: params}, nil
+}
+
+// fail 将搜索标记为失败
+func (c *SweepController) fail(s *model.VtTrainingSweeps, message string) error {
+	if _, err := c.sweepModel.Finish(s.Id, model.SweepStatusFailed, message); err != nil {
+		return err
+	}
+	c.logger.Errorf("超参数搜索失败: ID=%d, %s", s.Id, message)
+	return nil
+}
+
+// buildTrialSpec 在基础规格上设置试验名称和超参数，基础规格中已有的超参数会被搜索值覆盖
+func buildTrialSpec(s *model.VtTrainingSweeps, index int, params map[string]interface{}) ([]byte, string, error) {
+	var spec map[string]interface{}
+	if err := json.Unmarshal([]byte(s.BaseSpec), &spec); err != nil {
+		return nil, "", fmt.Errorf("解析试验基础规格失败: %v", err)
+	}
+
+	hyperparameters := make(map[string]interface{})
+	if base, ok := spec["hyperparameters"].(string); ok && base != "" {
+		if err := json.Unmarshal([]byte(base), &hyperparameters); err != nil {
+			return nil, "", fmt.Errorf("解析基础超参数失败: %v", err)
+		}
+	}
+	for name, value := range params {
+		hyperparameters[name] = value
+	}
+	data, err := json.Marshal(hyperparameters)
+	if err != nil {
+		return nil, "", err
+	}
+
+	name := fmt.Sprintf("%s-%d-trial-%d", s.Name, s.Id, index+1)
+	spec["name"] = name
+	spec["hyperparameters"] = string(data)
+
+	result, err := json.Marshal(spec)
+	return result, name, err
+}
+
+// nextTrialIndex 下一个试验序号
+func nextTrialIndex(trials []*SweepTrial) int {
+	next := 0
+	for _, trial := range trials {
+		if trial.Index >= next {
+			next = trial.Index + 1
+		}
+	}
+	return next
+}
+
+// observations 已结束且上报了目标指标的试验，作为贝叶斯优化的历史数据
+func observations(trials []*SweepTrial) []sweep.Observation {
+	var history []sweep.Observation
+	for _, trial := range trials {
+		if trial.Objective != nil && IsTerminalJobStatus(trial.Status) {
+			history = append(history, sweep.Observation{Params: trial.Params, Value: *trial.Objective})
+		}
+	}
+	return history
+}
+
+// summarize 统计搜索进度，提前终止的试验不参与最优结果评选
+func summarize(s *model.VtTrainingSweeps, trials []*SweepTrial) *model.SweepProgress {
+	progress := &model.SweepProgress{TrialCount: len(trials)}
+	for _, trial := range trials {
+		if IsTerminalJobStatus(trial.Status) {
+			progress.CompletedCount++
+		}
+		if trial.Pruned() {
+			progress.PrunedCount++
+			continue
+		}
+		if trial.Objective == nil {
+			continue
+		}
+		if progress.BestValue == nil || sweep.Better(*trial.Objective, *progress.BestValue, s.ObjectiveGoal) {
+			value := *trial.Objective
+			progress.BestValue = &value
+			progress.BestJobId = trial.JobId
+		}
+	}
+	return progress
+}
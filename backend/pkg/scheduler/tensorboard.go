@@ -0,0 +1,186 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"api/model"
+	"api/pkg/volcano"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// TensorboardConfig 作业TensorBoard管理配置
+type TensorboardConfig struct {
+	Interval time.Duration // 检查间隔
+	TTL      time.Duration // 作业结束后TensorBoard保留的时长
+	Image    string        // TensorBoard镜像，需要包含tensorboard命令
+}
+
+// TensorboardController 作业TensorBoard控制器
+// 为开启TensorBoard的运行中作业创建指向tensorboard_path的Deployment和Service，
+// 作业结束超过TTL、被删除或关闭TensorBoard后删除对应资源
+type TensorboardController struct {
+	jobModel   model.VtTrainingJobsModel
+	jobManager *volcano.JobManager
+	config     TensorboardConfig
+	logger     logx.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewTensorboardController 创建TensorBoard控制器
+func NewTensorboardController(jobModel model.VtTrainingJobsModel, jobManager *volcano.JobManager, config TensorboardConfig) *TensorboardController {
+	if config.Interval <= 0 {
+		config.Interval = 30 * time.Second
+	}
+	if config.TTL <= 0 {
+		config.TTL = time.Hour
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &TensorboardController{
+		jobModel:   jobModel,
+		jobManager: jobManager,
+		config:     config,
+		logger:     logx.WithContext(context.Background()),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// TensorboardName 返回作业TensorBoard的Deployment和Service名，重试前后保持不变
+func TensorboardName(job *model.VtTrainingJobs) string {
+	return BuildVolcanoJobName(job) + "-tensorboard"
+}
+
+// TensorboardPathPrefix 返回作业TensorBoard的反向代理路径前缀
+func TensorboardPathPrefix(jobId int64) string {
+	return fmt.Sprintf("/api/v1/training/jobs/%d/tensorboard", jobId)
+}
+
+// Start 启动检查循环
+func (c *TensorboardController) Start() {
+	c.logger.Infof("启动TensorBoard管理，检查间隔: %v，作业结束后保留: %v", c.config.Interval, c.config.TTL)
+
+	c.wg.Add(1)
+	go c.loop()
+}
+
+// Stop 停止检查循环，已创建的TensorBoard保留到下次启动后按TTL回收
+func (c *TensorboardController) Stop() {
+	c.cancel()
+	c.wg.Wait()
+	c.logger.Info("TensorBoard管理已停止")
+}
+
+// loop 检查循环
+func (c *TensorboardController) loop() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.ReconcileOnce(); err != nil {
+			c.logger.Errorf("同步TensorBoard失败: %v", err)
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReconcileOnce 为运行中和结束未超过TTL的作业创建TensorBoard，回收其余作业的TensorBoard
+func (c *TensorboardController) ReconcileOnce() error {
+	running, err := c.jobModel.FindRunning()
+	if err != nil {
+		return err
+	}
+	finished, err := c.jobModel.FindFinishedSince(time.Now().Add(-c.config.TTL))
+	if err != nil {
+		return err
+	}
+
+	wanted := make(map[string]bool)
+	for _, job := range append(running, finished...) {
+		if !job.EnableTensorboard || job.TensorboardPath == "" {
+			continue
+		}
+		spec, err := c.buildSpec(job)
+		if err != nil {
+			c.logger.Errorf("构建TensorBoard规格失败: ID=%d, %v", job.Id, err)
+			continue
+		}
+		wanted[spec.Namespace+"/"+spec.Name] = true
+		if err := c.jobManager.EnsureTensorboard(spec); err != nil {
+			c.logger.Errorf("创建TensorBoard失败: ID=%d, %v", job.Id, err)
+		}
+	}
+
+	deployments, err := c.jobManager.ListTensorboards("")
+	if err != nil {
+		return err
+	}
+	for _, deployment := range deployments {
+		if wanted[deployment.Namespace+"/"+deployment.Name] {
+			continue
+		}
+		if err := c.jobManager.DeleteTensorboard(deployment.Namespace, deployment.Name); err != nil {
+			c.logger.Errorf("回收TensorBoard失败: %s/%s, %v", deployment.Namespace, deployment.Name, err)
+			continue
+		}
+		c.logger.Infof("已回收TensorBoard: %s/%s, 作业ID=%s", deployment.Namespace, deployment.Name, deployment.Labels[JobIDLabelKey])
+	}
+	return nil
+}
+
+// buildSpec 按作业的存储卷配置构建TensorBoard规格，事件目录为训练容器中的tensorboard_path
+func (c *TensorboardController) buildSpec(job *model.VtTrainingJobs) (*volcano.TensorboardSpec, error) {
+	jobSpec, err := BuildTrainingJobSpec(job, "")
+	if err != nil {
+		return nil, err
+	}
+	return &volcano.TensorboardSpec{
+		Name:         TensorboardName(job),
+		Namespace:    c.jobManager.Namespace(job.Namespace),
+		Image:        c.config.Image,
+		LogDir:       job.TensorboardPath,
+		PathPrefix:   TensorboardPathPrefix(job.Id),
+		VolumeMounts: jobSpec.VolumeMounts,
+		Labels:       map[string]string{JobIDLabelKey: strconv.FormatInt(job.Id, 10)},
+	}, nil
+}
+
+// NewTensorboardProxy 创建转发到TensorBoard的反向代理
+// 请求路径保持不变，由TensorBoard按--path_prefix处理；用户的认证信息不会转发给TensorBoard
+func NewTensorboardProxy(target *url.URL, logger logx.Logger) http.Handler {
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	director := proxy.Director
+	proxy.Director = func(r *http.Request) {
+		director(r)
+		r.Header.Del("Authorization")
+		r.Header.Del("Cookie")
+		query := r.URL.Query()
+		if query.Has("token") {
+			query.Del("token")
+			r.URL.RawQuery = query.Encode()
+		}
+	}
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		logger.Errorf("转发TensorBoard请求失败: %s, %v", r.URL.Path, err)
+		http.Error(w, "TensorBoard暂时不可用", http.StatusBadGateway)
+	}
+	return proxy
+}
@@ -0,0 +1,366 @@
+package scheduler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"api/model"
+	"api/pkg/metrics"
+	"api/pkg/tfevents"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// TfeventsCategory 从TensorBoard事件文件导入的指标的category
+const TfeventsCategory = "tensorboard"
+
+// maxEventsPerFile 每轮从单个事件文件读取的最大记录数，剩余的记录下一轮继续导入
+const maxEventsPerFile = 10000
+
+// TfeventsImporterConfig 事件文件导入配置
+type TfeventsImporterConfig struct {
+	Interval time.Duration // 导入间隔
+	Grace    time.Duration // 作业结束后继续导入的时长，读取训练结束前最后写入的事件
+	Root     string        // 作业目录为相对路径时的根目录
+	StateDir string        // 保存各事件文件导入位置的目录
+}
+
+// TfeventsImporter TensorBoard事件文件导入器
+// 定期扫描开启TensorBoard的作业的事件目录（tensorboard_path，未设置时为output_path），
+// 从上次导入的位置继续读取events.out.tfevents.*文件，将标量、直方图和文本摘要写入vt_training_metrics。
+// 事件目录下的子目录视为TensorBoard的run，目录名为train、validation等时同时作为指标的阶段
+type TfeventsImporter struct {
+	jobModel    model.VtTrainingJobsModel
+	metricModel model.VtTrainingMetricsModel
+	config      TfeventsImporterConfig
+	logger      logx.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// tfeventsState 作业各事件文件的导入位置，键为文件相对事件目录的路径
+type tfeventsState struct {
+	Files map[string]*tfeventsFileState `json:"files"`
+}
+
+type tfeventsFileState struct {
+	Offset  int64 `json:"offset"`
+	Corrupt bool  `json:"corrupt,omitempty"` // 记录头校验失败，文件不再读取
+}
+
+// NewTfeventsImporter 创建事件文件导入器
+func NewTfeventsImporter(jobModel model.VtTrainingJobsModel, metricModel model.VtTrainingMetricsModel, config TfeventsImporterConfig) *TfeventsImporter {
+	if config.Interval <= 0 {
+		config.Interval = 30 * time.Second
+	}
+	if config.Grace <= 0 {
+		config.Grace = 10 * time.Minute
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &TfeventsImporter{
+		jobModel:    jobModel,
+		metricModel: metricModel,
+		config:      config,
+		logger:      logx.WithContext(context.Background()),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Start 启动导入循环
+func (i *TfeventsImporter) Start() {
+	i.logger.Infof("启动TensorBoard事件导入，导入间隔: %v", i.config.Interval)
+
+	i.wg.Add(1)
+	go i.loop()
+}
+
+// Stop 停止导入循环
+func (i *TfeventsImporter) Stop() {
+	i.cancel()
+	i.wg.Wait()
+	i.logger.Info("TensorBoard事件导入已停止")
+}
+
+// loop 导入循环
+func (i *TfeventsImporter) loop() {
+	defer i.wg.Done()
+
+	ticker := time.NewTicker(i.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := i.ReconcileOnce(); err != nil {
+			i.logger.Errorf("导入TensorBoard事件失败: %v", err)
+		}
+
+		select {
+		case <-i.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReconcileOnce 导入运行中和刚结束的作业新写入的事件
+func (i *TfeventsImporter) ReconcileOnce() error {
+	submitted, err := i.jobModel.FindSubmitted()
+	if err != nil {
+		return err
+	}
+	finished, err := i.jobModel.FindFinishedSince(time.Now().Add(-i.config.Grace))
+	if err != nil {
+		return err
+	}
+
+	for _, job := range append(submitted, finished...) {
+		if i.ctx.Err() != nil {
+			break
+		}
+		if !job.EnableTensorboard && job.TensorboardPath == "" {
+			continue
+		}
+		if err := i.ImportJob(job); err != nil {
+			i.logger.Errorf("导入作业TensorBoard事件失败: ID=%d, %v", job.Id, err)
+		}
+	}
+	return nil
+}
+
+// ImportJob 导入作业事件目录下全部事件文件自上次导入之后的记录
+func (i *TfeventsImporter) ImportJob(job *model.VtTrainingJobs) error {
+	dir := i.eventDir(job)
+	if dir == "" {
+		return nil
+	}
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	state, err := i.loadState(job.Id)
+	if err != nil {
+		return fmt.Errorf("读取导入位置失败: %w", err)
+	}
+
+	var files []string
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.Contains(d.Name(), "tfevents") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("扫描事件目录失败: %w", err)
+	}
+
+	for _, path := range files {
+		rel, _ := filepath.Rel(dir, path)
+		rel = filepath.ToSlash(rel)
+		fileState := state.Files[rel]
+		if fileState == nil {
+			fileState = &tfeventsFileState{}
+			state.Files[rel] = fileState
+		}
+		if fileState.Corrupt {
+			continue
+		}
+
+		offset := fileState.Offset
+		if err := i.importFile(job, path, rel, fileState); err != nil {
+			i.logger.Errorf("导入事件文件失败: %s, %v", path, err)
+		}
+		if fileState.Offset != offset || fileState.Corrupt {
+			if err := i.saveState(job.Id, state); err != nil {
+				return fmt.Errorf("保存导入位置失败: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// importFile 从上次导入的位置读取事件文件并写入指标，写入成功后才推进导入位置
+func (i *TfeventsImporter) importFile(job *model.VtTrainingJobs, path, rel string, state *tfeventsFileState) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() < state.Offset {
+		// 文件被重新写入，从头导入
+		state.Offset = 0
+	}
+	if info.Size() == state.Offset {
+		return nil
+	}
+	if _, err := f.Seek(state.Offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	run := filepath.ToSlash(filepath.Dir(rel))
+	if run == "." {
+		run = ""
+	}
+	phase := runPhase(filepath.Base(run))
+
+	reader := tfevents.NewReader(bufio.NewReader(f), state.Offset)
+	var rows []*model.VtTrainingMetrics
+	for n := 0; n < maxEventsPerFile; n++ {
+		data, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, tfevents.ErrDataCorrupt) {
+			i.logger.Errorf("跳过校验失败的事件记录: %s, offset=%d", path, reader.Offset())
+			continue
+		}
+		if errors.Is(err, tfevents.ErrCorrupt) {
+			i.logger.Errorf("事件文件已损坏，停止导入: %s, offset=%d", path, reader.Offset())
+			state.Corrupt = true
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		event, err := tfevents.ParseEvent(data)
+		if err != nil {
+			i.logger.Errorf("跳过无法解码的事件记录: %s, %v", path, err)
+			continue
+		}
+		for _, value := range event.Values {
+			if row := eventMetric(job, event, value, run, phase); row != nil {
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	if len(rows) > 0 {
+		if err := i.metricModel.BatchInsert(rows); err != nil {
+			// 导入位置不变，下一轮重新读取这些记录，损坏位置之前的记录写入后再标记损坏
+			state.Corrupt = false
+			return err
+		}
+	}
+	state.Offset = reader.Offset()
+	return nil
+}
+
+// eventDir 返回作业的事件目录，相对路径以配置的根目录为起点
+func (i *TfeventsImporter) eventDir(job *model.VtTrainingJobs) string {
+	dir := job.TensorboardPath
+	if dir == "" {
+		dir = job.OutputPath
+	}
+	if dir == "" {
+		return ""
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(i.config.Root, dir)
+	}
+	return dir
+}
+
+func (i *TfeventsImporter) statePath(jobId int64) string {
+	return filepath.Join(i.config.StateDir, strconv.FormatInt(jobId, 10)+".json")
+}
+
+func (i *TfeventsImporter) loadState(jobId int64) (*tfeventsState, error) {
+	state := &tfeventsState{}
+	data, err := os.ReadFile(i.statePath(jobId))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, state); err != nil {
+			return nil, err
+		}
+	}
+	if state.Files == nil {
+		state.Files = make(map[string]*tfeventsFileState)
+	}
+	return state, nil
+}
+
+// saveState 先写临时文件再重命名，避免服务中断时留下不完整的状态文件
+func (i *TfeventsImporter) saveState(jobId int64, state *tfeventsState) error {
+	if err := os.MkdirAll(i.config.StateDir, 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	path := i.statePath(jobId)
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// eventMetric 将事件中的一个摘要值转换为训练指标，无法保存的值返回nil
+func eventMetric(job *model.VtTrainingJobs, event *tfevents.Event, value tfevents.Value, run, phase string) *model.VtTrainingMetrics {
+	if value.Tag == "" {
+		return nil
+	}
+	m := &model.VtTrainingMetrics{
+		JobId:      job.Id,
+		MetricName: truncate(value.Tag, 128),
+		Step:       event.Step,
+		Tag:        truncate(run, 128),
+		Category:   TfeventsCategory,
+		Phase:      phase,
+	}
+	switch value.Kind {
+	case tfevents.KindScalar:
+		if metrics.ValidateValue(value.Scalar) != nil {
+			return nil
+		}
+		m.MetricType = model.MetricTypeScalar
+		m.MetricValue = metrics.FormatValue(value.Scalar)
+	case tfevents.KindHistogram:
+		if metrics.SetHistogram(m, value.Histogram) != nil {
+			return nil
+		}
+	case tfevents.KindText:
+		data, _ := json.Marshal(map[string]string{"text": value.Text})
+		m.MetricType = model.MetricTypeText
+		m.MetricData = string(data)
+	}
+	metrics.SetWallTime(m, event.WallTime, job.StartTime)
+	return m
+}
+
+// runPhase 根据TensorBoard run目录名推断指标阶段，如Keras回调写入的train和validation
+func runPhase(run string) string {
+	switch strings.ToLower(run) {
+	case "train", "training":
+		return "train"
+	case "val", "valid", "validation", "eval", "evaluation":
+		return "val"
+	case "test", "testing":
+		return "test"
+	}
+	return ""
+}
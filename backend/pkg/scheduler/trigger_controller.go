@@ -0,0 +1,293 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"api/model"
+	"api/pkg/cron"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// maxMissedTriggerRuns 停机期间错过的触发次数超过该值时不再补触发，与Kubernetes CronJob一致
+	maxMissedTriggerRuns = 100
+	// activeRunLookback 判断并发策略时检查的最近运行数
+	activeRunLookback = 20
+)
+
+// TriggerJobCreator 按触发器目标创建训练作业：复制训练作业或实例化模板，name为新作业名
+type TriggerJobCreator interface {
+	CreateTriggeredJob(ctx context.Context, trigger *model.VtTrainingTriggers, name string) (int64, error)
+}
+
+// JobCreator 后台任务使用的全部作业创建入口，由logic层实现
+type JobCreator interface {
+	TrainingJobCreator
+	TriggerJobCreator
+}
+
+// ParseTriggerSchedule 解析触发器的cron表达式和时区
+func ParseTriggerSchedule(schedule, timezone string) (*cron.Schedule, *time.Location, error) {
+	parsed, err := cron.Parse(schedule)
+	if err != nil {
+		return nil, nil, err
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("时区 %q 无效: %v", timezone, err)
+	}
+	return parsed, loc, nil
+}
+
+// NextTriggerTime 计算after之后的下一次触发时间，表达式永远不会触发时返回nil
+func NextTriggerTime(schedule, timezone string, after time.Time) (*time.Time, error) {
+	parsed, loc, err := ParseTriggerSchedule(schedule, timezone)
+	if err != nil {
+		return nil, err
+	}
+	next := parsed.Next(after.In(loc))
+	if next.IsZero() {
+		return nil, nil
+	}
+	return &next, nil
+}
+
+// TriggerConfig 定时触发控制器配置
+type TriggerConfig struct {
+	Interval time.Duration // 检查到期触发器的间隔
+}
+
+// TriggerController 训练作业定时触发控制器
+// 按cron表达式在触发器时区内计算触发时间，通过条件更新next_run_at保证多实例下每个触发时间只处理一次，
+// 停机期间错过的触发只补触发最近一次，其余记录为missed
+type TriggerController struct {
+	triggerModel model.VtTrainingTriggersModel
+	runModel     model.VtTrainingTriggerRunsModel
+	jobModel     model.VtTrainingJobsModel
+	machine      *JobStateMachine
+	creator      TriggerJobCreator
+	config       TriggerConfig
+	logger       logx.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewTriggerController 创建定时触发控制器
+func NewTriggerController(triggerModel model.VtTrainingTriggersModel, runModel model.VtTrainingTriggerRunsModel, jobModel model.VtTrainingJobsModel,
+	machine *JobStateMachine, creator TriggerJobCreator, config TriggerConfig) *TriggerController {
+	if config.Interval <= 0 {
+		config.Interval = 30 * time.Second
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &TriggerController{
+		triggerModel: triggerModel,
+		runModel:     runModel,
+		jobModel:     jobModel,
+		machine:      machine,
+		creator:      creator,
+		config:       config,
+		logger:       logx.WithContext(context.Background()),
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// Start 启动触发循环
+func (c *TriggerController) Start() {
+	c.logger.Infof("启动定时触发控制器，检查间隔: %v", c.config.Interval)
+
+	c.wg.Add(1)
+	go c.loop()
+}
+
+// Stop 停止触发循环
+func (c *TriggerController) Stop() {
+	c.cancel()
+	c.wg.Wait()
+	c.logger.Info("定时触发控制器已停止")
+}
+
+// loop 触发循环
+func (c *TriggerController) loop() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.ReconcileOnce(); err != nil {
+			c.logger.Errorf("处理定时触发器失败: %v", err)
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReconcileOnce 处理当前到期的触发器
+func (c *TriggerController) ReconcileOnce() error {
+	return c.ReconcileAt(time.Now())
+}
+
+// ReconcileAt 以now为当前时间处理到期的触发器
+func (c *TriggerController) ReconcileAt(now time.Time) error {
+	triggers, err := c.triggerModel.FindDue(now, 100)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range triggers {
+		if c.ctx.Err() != nil {
+			break
+		}
+		if err := c.fire(t, now); err != nil {
+			c.logger.Errorf("处理定时触发器失败: ID=%d, %v", t.Id, err)
+		}
+	}
+	return nil
+}
+
+// fire 认领到期的触发时间并执行触发
+func (c *TriggerController) fire(t *model.VtTrainingTriggers, now time.Time) error {
+	schedule, loc, err := ParseTriggerSchedule(t.Schedule, t.Timezone)
+	if err != nil {
+		return err
+	}
+
+	due := *t.NextRunAt
+	var times []time.Time
+	next := due
+	for !next.IsZero() && !next.After(now) && len(times) <= maxMissedTriggerRuns {
+		times = append(times, next)
+		next = schedule.Next(next.In(loc))
+	}
+	if !next.IsZero() && !next.After(now) {
+		next = schedule.Next(now.In(loc))
+	}
+
+	var nextRunAt *time.Time
+	if !next.IsZero() {
+		nextRunAt = &next
+	}
+	claimed, err := c.triggerModel.Advance(t.Id, due, nextRunAt)
+	if err != nil {
+		return fmt.Errorf("推进下一次触发时间失败: %w", err)
+	}
+	if !claimed {
+		return nil
+	}
+
+	if len(times) > maxMissedTriggerRuns {
+		c.record(t, times[0], model.TriggerRunMissed, 0, nil,
+			fmt.Sprintf("错过的触发超过%d次，不再补触发", maxMissedTriggerRuns))
+		return nil
+	}
+
+	scheduledAt := times[len(times)-1]
+	for _, missed := range times[:len(times)-1] {
+		c.record(t, missed, model.TriggerRunMissed, 0, nil, "服务停止期间错过触发，仅补触发最近一次")
+	}
+
+	if t.StartingDeadlineSeconds > 0 && now.Sub(scheduledAt) > time.Duration(t.StartingDeadlineSeconds)*time.Second {
+		c.record(t, scheduledAt, model.TriggerRunMissed, 0, nil,
+			fmt.Sprintf("已超过补触发期限%d秒", t.StartingDeadlineSeconds))
+		return nil
+	}
+
+	c.run(t, scheduledAt, loc)
+	return nil
+}
+
+// run 按并发策略创建一次运行
+func (c *TriggerController) run(t *model.VtTrainingTriggers, scheduledAt time.Time, loc *time.Location) {
+	var replaced []int64
+	if t.ConcurrencyPolicy == model.TriggerConcurrencyForbid || t.ConcurrencyPolicy == model.TriggerConcurrencyReplace {
+		active, err := c.activeJobs(t)
+		if err != nil {
+			c.record(t, scheduledAt, model.TriggerRunFailed, 0, nil, err.Error())
+			return
+		}
+
+		if len(active) > 0 && t.ConcurrencyPolicy == model.TriggerConcurrencyForbid {
+			c.record(t, scheduledAt, model.TriggerRunSkipped, 0, nil, fmt.Sprintf("上一次运行的作业 %v 尚未结束", active))
+			return
+		}
+		for _, jobId := range active {
+			if _, err := c.machine.Cancel(jobId, SystemOperator, fmt.Sprintf("定时触发器 %s 以新运行替换", t.Name)); err != nil {
+				c.logger.Errorf("取消被替换的作业失败: 触发器ID=%d, 作业ID=%d, %v", t.Id, jobId, err)
+				continue
+			}
+			replaced = append(replaced, jobId)
+		}
+	}
+
+	name := fmt.Sprintf("%s-%s", t.Name, scheduledAt.In(loc).Format("200601021504"))
+	jobId, err := c.creator.CreateTriggeredJob(c.ctx, t, name)
+	if err != nil {
+		c.record(t, scheduledAt, model.TriggerRunFailed, 0, replaced, err.Error())
+		return
+	}
+
+	c.record(t, scheduledAt, model.TriggerRunCreated, jobId, replaced, "")
+	if err := c.triggerModel.RecordJob(t.Id, jobId, scheduledAt); err != nil {
+		c.logger.Errorf("更新触发器最近运行失败: ID=%d, %v", t.Id, err)
+	}
+	c.logger.Infof("定时触发器已创建作业: 触发器=%s(ID=%d), 作业ID=%d, 计划时间=%s", t.Name, t.Id, jobId, scheduledAt.In(loc).Format(time.RFC3339))
+}
+
+// activeJobs 查询触发器最近创建且尚未结束的作业
+func (c *TriggerController) activeJobs(t *model.VtTrainingTriggers) ([]int64, error) {
+	runs, err := c.runModel.FindRecentJobs(t.Id, activeRunLookback)
+	if err != nil {
+		return nil, fmt.Errorf("查询触发记录失败: %w", err)
+	}
+
+	var active []int64
+	for _, r := range runs {
+		job, err := c.jobModel.FindOneDetail(r.JobId)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("查询触发创建的作业失败: ID=%d, %w", r.JobId, err)
+		}
+		if !IsTerminalJobStatus(job.Status) {
+			active = append(active, job.Id)
+		}
+	}
+	return active, nil
+}
+
+// record 写入触发记录
+func (c *TriggerController) record(t *model.VtTrainingTriggers, scheduledAt time.Time, status string, jobId int64, replaced []int64, message string) {
+	run := &model.VtTrainingTriggerRuns{
+		TriggerId:   t.Id,
+		ScheduledAt: scheduledAt,
+		Status:      status,
+		JobId:       jobId,
+		Message:     message,
+	}
+	if len(replaced) > 0 {
+		data, _ := json.Marshal(replaced)
+		run.ReplacedJobIds = string(data)
+	}
+	if _, err := c.runModel.Insert(run); err != nil && !strings.Contains(err.Error(), "Duplicate entry") {
+		c.logger.Errorf("写入触发记录失败: 触发器ID=%d, 计划时间=%v, %v", t.Id, scheduledAt, err)
+	}
+	if status != model.TriggerRunCreated {
+		c.logger.Infof("定时触发器未创建作业: 触发器=%s(ID=%d), 结果=%s, %s", t.Name, t.Id, status, message)
+	}
+}
@@ -0,0 +1,268 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"api/model"
+	"api/pkg/metrics"
+	"api/pkg/volcano"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// JobIDLabelKey Volcano作业及其Pod上记录平台作业ID的标签
+	JobIDLabelKey = "volctrain.io/job-id"
+
+	// ResumeCheckpointEnv 恢复训练时注入的检查点路径环境变量
+	ResumeCheckpointEnv = "RESUME_FROM_CHECKPOINT"
+
+	// HyperparametersEnv 以JSON形式注入作业超参数的环境变量
+	HyperparametersEnv = "HYPERPARAMETERS"
+
+	// 训练脚本上报指标使用的环境变量：作业ID、上报地址和作业级令牌
+	JobIDEnv           = "VOLCTRAIN_JOB_ID"
+	MetricsEndpointEnv = "VOLCTRAIN_METRICS_ENDPOINT"
+	MetricsTokenEnv    = "VOLCTRAIN_METRICS_TOKEN"
+
+	// maxVolcanoJobNameLength 作业名会作为Pod主机名前缀，需要为任务名和序号预留长度
+	maxVolcanoJobNameLength = 48
+)
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// BuildVolcanoJobName 生成符合DNS-1035规范且可重复计算的Volcano作业名
+func BuildVolcanoJobName(job *model.VtTrainingJobs) string {
+	return BuildVolcanoRunName(job, 0)
+}
+
+// BuildVolcanoRunName 生成第run次重新运行使用的Volcano作业名，run为0表示首次运行
+func BuildVolcanoRunName(job *model.VtTrainingJobs, run int) string {
+	name := strings.ToLower(job.Name)
+	name = strings.ReplaceAll(name, "_", "-")
+	name = invalidNameChars.ReplaceAllString(name, "-")
+	name = strings.Trim(name, "-")
+	if name == "" || name[0] < 'a' || name[0] > 'z' {
+		name = "vt-" + name
+	}
+
+	suffix := fmt.Sprintf("-%d", job.Id)
+	if run > 0 {
+		suffix += fmt.Sprintf("-r%d", run)
+	}
+	if len(name)+len(suffix) > maxVolcanoJobNameLength {
+		name = strings.TrimRight(name[:maxVolcanoJobNameLength-len(suffix)], "-")
+	}
+	return name + suffix
+}
+
+// BuildTrainingJobSpec 将数据库中的训练作业转换为Volcano训练作业规格
+func BuildTrainingJobSpec(job *model.VtTrainingJobs, namespace string) (*volcano.TrainingJobSpec, error) {
+	if job.Namespace != "" {
+		namespace = job.Namespace
+	}
+
+	name := job.VolcanoJobName
+	if name == "" {
+		name = BuildVolcanoJobName(job)
+	}
+
+	spec := &volcano.TrainingJobSpec{
+		Name:              name,
+		Namespace:         namespace,
+		JobType:           volcanoJobType(job),
+		Framework:         job.Framework,
+		FrameworkVersion:  job.FrameworkVersion,
+		Image:             job.Image,
+		Command:           []string{"python", "-u"},
+		Args:              []string{job.EntryPoint},
+		WorkingDir:        job.WorkingDir,
+		CPURequest:        job.CpuCores,
+		GPUCount:          int64(job.GpuCount),
+		GPUType:           job.GpuType,
+		QueueName:         volcanoQueueName(job),
+		Priority:          int64(job.Priority),
+		EnableTensorboard: job.EnableTensorboard,
+		EnableProfiling:   job.EnableProfiling,
+		Labels: map[string]string{
+			JobIDLabelKey: strconv.FormatInt(job.Id, 10),
+		},
+	}
+
+	if job.MemoryGb != "" {
+		spec.MemoryRequest = job.MemoryGb + "Gi"
+	}
+	if job.GpuCount > 0 {
+		sharing, err := JobGPUSharing(job.GpuSharingMode, job.GpuMigProfile, job.GpuMemoryGb)
+		if err != nil {
+			return nil, err
+		}
+		spec.GPUSharing = sharing
+	}
+	if job.StorageGb != "" {
+		spec.StorageRequest = job.StorageGb + "Gi"
+	}
+
+	setReplicas(spec, job)
+
+	// 解析JSON配置字段
+	if job.CommandArgs != "" {
+		var commandArgs []string
+		if err := json.Unmarshal([]byte(job.CommandArgs), &commandArgs); err != nil {
+			return nil, fmt.Errorf("解析命令参数失败: %v", err)
+		}
+		spec.Args = append(spec.Args, commandArgs...)
+	}
+	if err := unmarshalJSONField(job.EnvVars, &spec.EnvVars, "环境变量"); err != nil {
+		return nil, err
+	}
+	if err := unmarshalJSONField(job.ConfigMaps, &spec.ConfigMaps, "配置映射"); err != nil {
+		return nil, err
+	}
+	if err := unmarshalJSONField(job.Secrets, &spec.Secrets, "密钥配置"); err != nil {
+		return nil, err
+	}
+	if err := unmarshalJSONField(job.VolumeMounts, &spec.VolumeMounts, "挂载卷配置"); err != nil {
+		return nil, err
+	}
+	if err := unmarshalJSONField(job.NodeSelector, &spec.NodeSelector, "节点选择器"); err != nil {
+		return nil, err
+	}
+	if err := unmarshalJSONField(job.Tolerations, &spec.Tolerations, "容忍度"); err != nil {
+		return nil, err
+	}
+	if job.Affinity != "" {
+		spec.Affinity = &corev1.Affinity{}
+		if err := unmarshalJSONField(job.Affinity, spec.Affinity, "亲和性配置"); err != nil {
+			return nil, err
+		}
+	}
+
+	// 超参数以JSON注入，训练脚本可直接解析，超参数搜索的试验依赖该变量
+	if job.Hyperparameters != "" {
+		if spec.EnvVars == nil {
+			spec.EnvVars = make(map[string]string)
+		}
+		spec.EnvVars[HyperparametersEnv] = job.Hyperparameters
+	}
+
+	// 从检查点恢复训练，训练脚本读取该环境变量加载检查点
+	if job.ResumeCheckpointPath != "" {
+		if spec.EnvVars == nil {
+			spec.EnvVars = make(map[string]string)
+		}
+		spec.EnvVars[ResumeCheckpointEnv] = job.ResumeCheckpointPath
+	}
+
+	// GPU作业需要容忍GPU节点污点
+	if job.GpuCount > 0 {
+		spec.Tolerations = append(spec.Tolerations, corev1.Toleration{
+			Key:      "nvidia.com/gpu",
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoSchedule,
+		})
+	}
+
+	return spec, nil
+}
+
+// JobGPUSharing 按作业的GPU共享配置构建共享规格，vgpu模式每个vGPU的显存取自gpu_memory_gb
+func JobGPUSharing(mode, migProfile, gpuMemoryGb string) (volcano.GPUSharing, error) {
+	sharing := volcano.GPUSharing{Mode: mode, MIGProfile: migProfile}
+	if sharing.ModeOrDefault() == volcano.GPUSharingVGPU {
+		memoryGb, err := strconv.ParseFloat(gpuMemoryGb, 64)
+		if err != nil || memoryGb <= 0 {
+			return sharing, fmt.Errorf("vgpu模式必须指定有效的GPU显存: %q", gpuMemoryGb)
+		}
+		sharing.MemoryMB = int64(memoryGb * 1024)
+	}
+	if err := sharing.Validate(); err != nil {
+		return sharing, err
+	}
+	return sharing, nil
+}
+
+// InjectMetricsEnv 注入指标上报所需的环境变量，secret为空时不启用指标上报
+// 上报地址为 <endpoint>/api/v1/ingest/jobs/<jobId>/metrics，令牌以Bearer方式放在Authorization头中
+func InjectMetricsEnv(spec *volcano.TrainingJobSpec, jobId int64, endpoint, secret string) {
+	if secret == "" {
+		return
+	}
+	if spec.EnvVars == nil {
+		spec.EnvVars = make(map[string]string)
+	}
+	spec.EnvVars[JobIDEnv] = strconv.FormatInt(jobId, 10)
+	spec.EnvVars[MetricsTokenEnv] = metrics.JobToken(secret, jobId)
+	if endpoint != "" {
+		spec.EnvVars[MetricsEndpointEnv] = strings.TrimRight(endpoint, "/")
+	}
+}
+
+// setReplicas 根据作业类型设置各角色副本数和最小可用数
+func setReplicas(spec *volcano.TrainingJobSpec, job *model.VtTrainingJobs) {
+	switch job.JobType {
+	case "", "single":
+		spec.MasterReplicas = 1
+	case "parameter_server":
+		spec.MasterReplicas = int32(job.MasterCount)
+		spec.WorkerReplicas = int32(job.WorkerCount)
+		spec.PSReplicas = int32(job.PsCount)
+	default:
+		spec.MasterReplicas = int32(job.MasterCount)
+		spec.WorkerReplicas = int32(job.WorkerCount)
+	}
+
+	total := spec.MasterReplicas + spec.WorkerReplicas
+	if spec.PSReplicas > 0 && hasPSFramework(spec.Framework) {
+		total += spec.PSReplicas
+	}
+
+	// 未指定或超出副本总数时按全部副本进行Gang调度
+	spec.MinAvailable = int32(job.MinAvailable)
+	if spec.MinAvailable <= 0 || spec.MinAvailable > total {
+		spec.MinAvailable = total
+	}
+}
+
+// hasPSFramework 框架是否支持参数服务器模式
+func hasPSFramework(framework string) bool {
+	switch strings.ToLower(framework) {
+	case "tensorflow", "paddle", "paddlepaddle":
+		return true
+	}
+	return false
+}
+
+// volcanoJobType 将平台作业类型映射为Volcano插件使用的作业类型
+func volcanoJobType(job *model.VtTrainingJobs) string {
+	if job.JobType == "horovod" {
+		return "mpi"
+	}
+	return strings.ToLower(job.Framework)
+}
+
+// volcanoQueueName 获取Volcano队列名，平台队列名中的下划线不符合K8s命名规范
+func volcanoQueueName(job *model.VtTrainingJobs) string {
+	if job.VolcanoQueue != "" {
+		return job.VolcanoQueue
+	}
+	if job.QueueName == "" {
+		return "default"
+	}
+	return strings.ReplaceAll(job.QueueName, "_", "-")
+}
+
+// unmarshalJSONField 解析可选的JSON字段
+func unmarshalJSONField(data string, v interface{}, fieldName string) error {
+	if data == "" || data == "null" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		return fmt.Errorf("解析%s失败: %v", fieldName, err)
+	}
+	return nil
+}
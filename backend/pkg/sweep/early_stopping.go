@@ -0,0 +1,179 @@
+package sweep
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// 提前终止策略
+const (
+	EarlyStoppingMedian    = "median"
+	EarlyStoppingHyperband = "hyperband"
+)
+
+// Point 试验上报的一次目标指标
+type Point struct {
+	Step  int64
+	Value float64
+}
+
+// EarlyStopping 提前终止表现较差试验的策略
+type EarlyStopping struct {
+	Type string `json:"type"`
+
+	// 中位数终止：试验在某一步的最优值差于其他试验到该步为止平均值的中位数时终止
+	GraceSteps int64 `json:"graceSteps,omitempty"` // 前若干步不做判断
+	MinTrials  int   `json:"minTrials,omitempty"`  // 参与比较的其他试验最少数量，默认3
+
+	// Hyperband：按异步连续减半在minResource*eta^k步设置检查点，只保留前1/eta的试验
+	MinResource int64 `json:"minResource,omitempty"` // 第一个检查点的步数
+	MaxResource int64 `json:"maxResource,omitempty"` // 不再做判断的步数，0表示不限
+	Eta         int   `json:"eta,omitempty"`         // 减半系数，默认3
+}
+
+// ParseEarlyStopping 解析提前终止策略，内容为空时返回nil表示不启用
+func ParseEarlyStopping(data string) (*EarlyStopping, error) {
+	if data == "" || data == "null" {
+		return nil, nil
+	}
+
+	var policy EarlyStopping
+	if err := json.Unmarshal([]byte(data), &policy); err != nil {
+		return nil, fmt.Errorf("解析提前终止策略失败: %v", err)
+	}
+	switch policy.Type {
+	case "":
+		return nil, nil
+	case EarlyStoppingMedian:
+		if policy.MinTrials <= 0 {
+			policy.MinTrials = 3
+		}
+	case EarlyStoppingHyperband:
+		if policy.MinResource <= 0 {
+			return nil, fmt.Errorf("Hyperband策略必须指定minResource")
+		}
+		if policy.Eta <= 1 {
+			policy.Eta = 3
+		}
+	default:
+		return nil, fmt.Errorf("不支持的提前终止策略 '%s'", policy.Type)
+	}
+	return &policy, nil
+}
+
+// ShouldStop 判断试验是否应当提前终止，others为其他试验上报的目标指标序列
+func (p *EarlyStopping) ShouldStop(trial []Point, others [][]Point, goal string) (bool, string) {
+	if p == nil || len(trial) == 0 {
+		return false, ""
+	}
+	switch p.Type {
+	case EarlyStoppingMedian:
+		return p.medianStop(trial, others, goal)
+	case EarlyStoppingHyperband:
+		return p.hyperbandStop(trial, others, goal)
+	}
+	return false, ""
+}
+
+// medianStop 中位数终止规则
+func (p *EarlyStopping) medianStop(trial []Point, others [][]Point, goal string) (bool, string) {
+	step := trial[len(trial)-1].Step
+	if step < p.GraceSteps {
+		return false, ""
+	}
+	best, _ := BestUntil(trial, step, goal)
+
+	var averages []float64
+	for _, series := range others {
+		if len(series) == 0 || series[len(series)-1].Step < step {
+			continue
+		}
+		sum, count := 0.0, 0
+		for _, point := range series {
+			if point.Step > step {
+				break
+			}
+			sum += point.Value
+			count++
+		}
+		if count > 0 {
+			averages = append(averages, sum/float64(count))
+		}
+	}
+	if len(averages) < p.MinTrials {
+		return false, ""
+	}
+
+	median := medianOf(averages)
+	if Better(median, best, goal) {
+		return true, fmt.Sprintf("第%d步最优值 %g 差于其他试验平均值的中位数 %g", step, best, median)
+	}
+	return false, ""
+}
+
+// hyperbandStop 异步连续减半(ASHA)规则，只在试验达到的最高检查点上判断
+func (p *EarlyStopping) hyperbandStop(trial []Point, others [][]Point, goal string) (bool, string) {
+	step := trial[len(trial)-1].Step
+	rung := int64(0)
+	for r := p.MinResource; r <= step; r *= int64(p.Eta) {
+		if p.MaxResource > 0 && r >= p.MaxResource {
+			break
+		}
+		rung = r
+	}
+	if rung == 0 {
+		return false, ""
+	}
+
+	value, _ := BestUntil(trial, rung, goal)
+	values := []float64{value}
+	for _, series := range others {
+		if len(series) == 0 || series[len(series)-1].Step < rung {
+			continue
+		}
+		v, _ := BestUntil(series, rung, goal)
+		values = append(values, v)
+	}
+	if len(values) < p.Eta {
+		return false, ""
+	}
+
+	sort.Slice(values, func(i, j int) bool { return Better(values[i], values[j], goal) })
+	keep := len(values) / p.Eta
+	if keep < 1 {
+		keep = 1
+	}
+	cutoff := values[keep-1]
+	if Better(cutoff, value, goal) {
+		return true, fmt.Sprintf("第%d步检查点的最优值 %g 未进入前1/%d (阈值 %g)", rung, value, p.Eta, cutoff)
+	}
+	return false, ""
+}
+
+// BestUntil 返回序列在step(含)之前的最优值
+func BestUntil(series []Point, step int64, goal string) (float64, bool) {
+	var best float64
+	found := false
+	for _, point := range series {
+		if point.Step > step {
+			break
+		}
+		if !found || Better(point.Value, best, goal) {
+			best = point.Value
+			found = true
+		}
+	}
+	return best, found
+}
+
+func medianOf(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
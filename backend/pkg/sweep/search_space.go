@@ -0,0 +1,211 @@
+package sweep
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// 搜索维度类型
+const (
+	DimensionChoice     = "choice"     // 离散取值
+	DimensionUniform    = "uniform"    // [min, max] 均匀分布
+	DimensionLogUniform = "loguniform" // [min, max] 对数均匀分布
+	DimensionInt        = "int"        // [min, max] 按step取整
+)
+
+// Dimension 单个超参数的搜索范围
+type Dimension struct {
+	Type   string        `json:"type"`
+	Values []interface{} `json:"values,omitempty"`
+	Min    float64       `json:"min,omitempty"`
+	Max    float64       `json:"max,omitempty"`
+	Step   float64       `json:"step,omitempty"`   // int类型的步长，默认为1
+	Points int           `json:"points,omitempty"` // 网格搜索时连续维度的取点数
+}
+
+// SearchSpace 超参数搜索空间，键为超参数名
+type SearchSpace map[string]Dimension
+
+// ParseSearchSpace 解析并校验搜索空间
+func ParseSearchSpace(data string) (SearchSpace, error) {
+	var space SearchSpace
+	if err := json.Unmarshal([]byte(data), &space); err != nil {
+		return nil, fmt.Errorf("解析搜索空间失败: %v", err)
+	}
+	if len(space) == 0 {
+		return nil, fmt.Errorf("搜索空间不能为空")
+	}
+
+	for name, dim := range space {
+		switch dim.Type {
+		case DimensionChoice:
+			if len(dim.Values) == 0 {
+				return nil, fmt.Errorf("超参数 '%s' 的候选值不能为空", name)
+			}
+		case DimensionUniform, DimensionInt:
+			if dim.Max < dim.Min {
+				return nil, fmt.Errorf("超参数 '%s' 的最大值小于最小值", name)
+			}
+		case DimensionLogUniform:
+			if dim.Min <= 0 || dim.Max < dim.Min {
+				return nil, fmt.Errorf("超参数 '%s' 的对数均匀分布范围必须为正数且max不小于min", name)
+			}
+		default:
+			return nil, fmt.Errorf("超参数 '%s' 的类型 '%s' 不支持", name, dim.Type)
+		}
+		if dim.Step < 0 || dim.Points < 0 {
+			return nil, fmt.Errorf("超参数 '%s' 的step和points不能为负数", name)
+		}
+	}
+	return space, nil
+}
+
+// names 按名称排序的超参数列表，保证网格顺序和随机采样可复现
+func (s SearchSpace) names() []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GridSize 返回网格搜索的组合总数
+func (s SearchSpace) GridSize() (int, error) {
+	size := 1
+	for _, name := range s.names() {
+		values, err := s[name].gridValues()
+		if err != nil {
+			return 0, fmt.Errorf("超参数 '%s' %v", name, err)
+		}
+		size *= len(values)
+	}
+	return size, nil
+}
+
+// GridPoint 返回第index个网格组合，最后一个超参数变化最快
+func (s SearchSpace) GridPoint(index int) (map[string]interface{}, error) {
+	names := s.names()
+	params := make(map[string]interface{}, len(names))
+	for i := len(names) - 1; i >= 0; i-- {
+		values, err := s[names[i]].gridValues()
+		if err != nil {
+			return nil, fmt.Errorf("超参数 '%s' %v", names[i], err)
+		}
+		params[names[i]] = values[index%len(values)]
+		index /= len(values)
+	}
+	return params, nil
+}
+
+// Sample 在搜索空间中随机采样一组超参数
+func (s SearchSpace) Sample(rng *rand.Rand) map[string]interface{} {
+	params := make(map[string]interface{}, len(s))
+	for _, name := range s.names() {
+		params[name] = s[name].fromUnit(rng.Float64())
+	}
+	return params
+}
+
+// gridValues 返回维度在网格搜索中的取值
+func (d Dimension) gridValues() ([]interface{}, error) {
+	switch d.Type {
+	case DimensionChoice:
+		return d.Values, nil
+	case DimensionInt:
+		step := d.step()
+		var values []interface{}
+		for v := d.Min; v <= d.Max+1e-9; v += step {
+			values = append(values, int64(math.Round(v)))
+		}
+		return values, nil
+	default:
+		if len(d.Values) > 0 {
+			return d.Values, nil
+		}
+		if d.Points <= 0 {
+			return nil, fmt.Errorf("是连续分布，网格搜索需要指定values或points")
+		}
+		values := make([]interface{}, d.Points)
+		for i := range values {
+			u := 0.0
+			if d.Points > 1 {
+				u = float64(i) / float64(d.Points-1)
+			}
+			values[i] = d.fromUnit(u)
+		}
+		return values, nil
+	}
+}
+
+func (d Dimension) step() float64 {
+	if d.Step > 0 {
+		return d.Step
+	}
+	return 1
+}
+
+// toUnit 将取值映射到[0,1]区间，choice类型返回候选值下标
+func (d Dimension) toUnit(value interface{}) float64 {
+	x, ok := toFloat(value)
+	switch d.Type {
+	case DimensionChoice:
+		for i, candidate := range d.Values {
+			if fmt.Sprint(candidate) == fmt.Sprint(value) {
+				return float64(i)
+			}
+		}
+		return 0
+	case DimensionLogUniform:
+		if !ok || x <= 0 || d.Max == d.Min {
+			return 0
+		}
+		return (math.Log(x) - math.Log(d.Min)) / (math.Log(d.Max) - math.Log(d.Min))
+	default:
+		if !ok || d.Max == d.Min {
+			return 0
+		}
+		return (x - d.Min) / (d.Max - d.Min)
+	}
+}
+
+// fromUnit 将[0,1]区间的值映射回搜索范围，choice类型按区间均分选择候选值
+func (d Dimension) fromUnit(u float64) interface{} {
+	u = math.Max(0, math.Min(1, u))
+	switch d.Type {
+	case DimensionChoice:
+		index := int(u * float64(len(d.Values)))
+		if index >= len(d.Values) {
+			index = len(d.Values) - 1
+		}
+		return d.Values[index]
+	case DimensionLogUniform:
+		return math.Exp(math.Log(d.Min) + u*(math.Log(d.Max)-math.Log(d.Min)))
+	case DimensionInt:
+		step := d.step()
+		steps := math.Floor((d.Max - d.Min) / step)
+		return int64(math.Round(d.Min + math.Round(u*steps)*step))
+	default:
+		return d.Min + u*(d.Max-d.Min)
+	}
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,149 @@
+package sweep
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// 搜索算法
+const (
+	AlgorithmGrid     = "grid"
+	AlgorithmRandom   = "random"
+	AlgorithmBayesian = "bayesian"
+)
+
+// 优化方向
+const (
+	GoalMinimize = "minimize"
+	GoalMaximize = "maximize"
+)
+
+const (
+	// bayesianStartupTrials 贝叶斯优化开始建模前的随机试验数
+	bayesianStartupTrials = 5
+	// bayesianCandidates 每次建议时从较优分布中采样的候选数
+	bayesianCandidates = 24
+	// bayesianGamma 历史试验中视为较优的比例
+	bayesianGamma = 0.25
+)
+
+// Observation 已完成试验的超参数及目标值
+type Observation struct {
+	Params map[string]interface{}
+	Value  float64
+}
+
+// Better 判断a是否优于b
+func Better(a, b float64, goal string) bool {
+	if goal == GoalMaximize {
+		return a > b
+	}
+	return a < b
+}
+
+// Suggest 根据搜索算法给出第index个试验的超参数，网格已遍历完时返回false
+// 随机数种子由调用方按搜索和试验序号生成，保证重复计算时结果一致
+func Suggest(algorithm string, space SearchSpace, index int, history []Observation, goal string, rng *rand.Rand) (map[string]interface{}, bool, error) {
+	switch algorithm {
+	case AlgorithmGrid:
+		size, err := space.GridSize()
+		if err != nil {
+			return nil, false, err
+		}
+		if index >= size {
+			return nil, false, nil
+		}
+		params, err := space.GridPoint(index)
+		return params, err == nil, err
+	case AlgorithmRandom:
+		return space.Sample(rng), true, nil
+	case AlgorithmBayesian:
+		if len(history) < bayesianStartupTrials {
+			return space.Sample(rng), true, nil
+		}
+		return suggestTPE(space, history, goal, rng), true, nil
+	default:
+		return nil, false, fmt.Errorf("不支持的搜索算法 '%s'", algorithm)
+	}
+}
+
+// suggestTPE 使用树结构Parzen估计器(TPE)给出建议
+// 历史试验按目标值分为较优组和较差组，分别在归一化空间中做核密度估计，
+// 从较优组附近采样候选点，选择较优密度与较差密度之比最大的候选
+func suggestTPE(space SearchSpace, history []Observation, goal string, rng *rand.Rand) map[string]interface{} {
+	sorted := make([]Observation, len(history))
+	copy(sorted, history)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return Better(sorted[i].Value, sorted[j].Value, goal)
+	})
+
+	nGood := int(math.Ceil(bayesianGamma * float64(len(sorted))))
+	if nGood < 1 {
+		nGood = 1
+	}
+	good, bad := sorted[:nGood], sorted[nGood:]
+	bandwidth := math.Max(0.05, math.Pow(float64(len(history)), -0.2)/2)
+
+	names := space.names()
+	var best map[string]float64
+	bestScore := math.Inf(-1)
+	for i := 0; i < bayesianCandidates; i++ {
+		anchor := good[rng.Intn(len(good))]
+		candidate := make(map[string]float64, len(names))
+		for _, name := range names {
+			dim := space[name]
+			u := dim.toUnit(anchor.Params[name])
+			if dim.Type == DimensionChoice {
+				if rng.Float64() < 0.2 {
+					u = float64(rng.Intn(len(dim.Values)))
+				}
+			} else {
+				u = math.Max(0, math.Min(1, u+rng.NormFloat64()*bandwidth))
+			}
+			candidate[name] = u
+		}
+
+		score := 0.0
+		for _, name := range names {
+			score += math.Log(density(space[name], name, candidate[name], good, bandwidth)) -
+				math.Log(density(space[name], name, candidate[name], bad, bandwidth))
+		}
+		if score > bestScore {
+			bestScore = score
+			best = candidate
+		}
+	}
+
+	params := make(map[string]interface{}, len(names))
+	for _, name := range names {
+		dim := space[name]
+		if dim.Type == DimensionChoice {
+			params[name] = dim.Values[int(best[name])]
+		} else {
+			params[name] = dim.fromUnit(best[name])
+		}
+	}
+	return params
+}
+
+// density 估计取值在一组观测中的概率密度，混入均匀先验避免密度为0
+func density(dim Dimension, name string, u float64, observations []Observation, bandwidth float64) float64 {
+	if dim.Type == DimensionChoice {
+		count := 1.0
+		for _, o := range observations {
+			if dim.toUnit(o.Params[name]) == u {
+				count++
+			}
+		}
+		return count / (float64(len(observations)) + float64(len(dim.Values)))
+	}
+
+	sum := 1.0 // 均匀先验
+	for _, o := range observations {
+		d := (u - dim.toUnit(o.Params[name])) / bandwidth
+		sum += math.Exp(-d*d/2) / (bandwidth * math.Sqrt(2*math.Pi))
+	}
+	return sum / float64(len(observations)+1)
+}
@@ -0,0 +1,397 @@
+package tfevents
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+
+	"api/pkg/metrics"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// ValueKind 摘要值的类型
+type ValueKind int
+
+const (
+	KindScalar ValueKind = iota
+	KindHistogram
+	KindText
+)
+
+// 摘要插件名，TF2和PyTorch写入张量摘要时用于区分数据类型
+const (
+	pluginScalars    = "scalars"
+	pluginHistograms = "histograms"
+	pluginText       = "text"
+)
+
+// TensorProto.dtype取值
+const (
+	dtFloat  = 1
+	dtDouble = 2
+	dtInt32  = 3
+	dtString = 7
+	dtInt64  = 9
+	dtHalf   = 19
+)
+
+// Event 一条事件记录，只保留摘要中可以导入的标量、直方图和文本
+type Event struct {
+	WallTime    float64 // Unix时间戳(秒)
+	Step        int64
+	FileVersion string
+	Values      []Value
+}
+
+// Value 一个摘要值
+type Value struct {
+	Tag       string
+	Kind      ValueKind
+	Scalar    float64
+	Histogram *metrics.Histogram
+	Text      string
+}
+
+// summaryValue 解码中的Summary.Value
+type summaryValue struct {
+	tag         string
+	plugin      string
+	simpleValue *float64
+	histo       *metrics.Histogram
+	tensor      *tensor
+}
+
+// tensor 解码中的TensorProto
+type tensor struct {
+	dtype   uint64
+	shape   []int64
+	content []byte
+	floats  []float64
+	strings []string
+}
+
+// ParseEvent 解码tensorflow.Event，图、图片、音频等无法导入的摘要被忽略
+// 同时支持TF1/PyTorch的simple_value、histo字段和TF2按插件名写入的张量摘要
+func ParseEvent(data []byte) (*Event, error) {
+	event := &Event{}
+	err := walk(data, func(num protowire.Number, typ protowire.Type, raw []byte, v uint64) error {
+		switch {
+		case num == 1 && typ == protowire.Fixed64Type:
+			event.WallTime = math.Float64frombits(v)
+		case num == 2 && typ == protowire.VarintType:
+			event.Step = int64(v)
+		case num == 3 && typ == protowire.BytesType:
+			event.FileVersion = string(raw)
+		case num == 5 && typ == protowire.BytesType:
+			// Summary.value
+			return walk(raw, func(num protowire.Number, typ protowire.Type, raw []byte, _ uint64) error {
+				if num != 1 || typ != protowire.BytesType {
+					return nil
+				}
+				sv, err := parseSummaryValue(raw)
+				if err != nil {
+					return err
+				}
+				if value, ok := sv.toValue(); ok {
+					event.Values = append(event.Values, value)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func parseSummaryValue(data []byte) (*summaryValue, error) {
+	sv := &summaryValue{}
+	err := walk(data, func(num protowire.Number, typ protowire.Type, raw []byte, v uint64) error {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			sv.tag = string(raw)
+		case num == 2 && typ == protowire.Fixed32Type:
+			value := float64(math.Float32frombits(uint32(v)))
+			sv.simpleValue = &value
+		case num == 5 && typ == protowire.BytesType:
+			h, err := parseHistogram(raw)
+			if err != nil {
+				return err
+			}
+			sv.histo = h
+		case num == 8 && typ == protowire.BytesType:
+			t, err := parseTensor(raw)
+			if err != nil {
+				return err
+			}
+			sv.tensor = t
+		case num == 9 && typ == protowire.BytesType:
+			// SummaryMetadata.plugin_data.plugin_name
+			return walk(raw, func(num protowire.Number, typ protowire.Type, raw []byte, _ uint64) error {
+				if num != 1 || typ != protowire.BytesType {
+					return nil
+				}
+				return walk(raw, func(num protowire.Number, typ protowire.Type, raw []byte, _ uint64) error {
+					if num == 1 && typ == protowire.BytesType {
+						sv.plugin = string(raw)
+					}
+					return nil
+				})
+			})
+		}
+		return nil
+	})
+	return sv, err
+}
+
+// toValue 将摘要值转换为可导入的值，第二个返回值为false表示不支持的摘要类型
+func (sv *summaryValue) toValue() (Value, bool) {
+	value := Value{Tag: sv.tag}
+	switch {
+	case sv.simpleValue != nil:
+		value.Kind, value.Scalar = KindScalar, *sv.simpleValue
+	case sv.histo != nil:
+		value.Kind, value.Histogram = KindHistogram, sv.histo
+	case sv.tensor == nil:
+		return value, false
+	case sv.plugin == pluginScalars:
+		values := sv.tensor.numbers()
+		if len(values) != 1 {
+			return value, false
+		}
+		value.Kind, value.Scalar = KindScalar, values[0]
+	case sv.plugin == pluginHistograms:
+		h := sv.tensor.histogram()
+		if h == nil {
+			return value, false
+		}
+		value.Kind, value.Histogram = KindHistogram, h
+	case sv.plugin == pluginText:
+		if sv.tensor.dtype != dtString || len(sv.tensor.strings) == 0 {
+			return value, false
+		}
+		value.Kind, value.Text = KindText, strings.Join(sv.tensor.strings, "\n")
+	default:
+		return value, false
+	}
+	return value, true
+}
+
+// parseHistogram 解码HistogramProto
+func parseHistogram(data []byte) (*metrics.Histogram, error) {
+	h := &metrics.Histogram{}
+	err := walk(data, func(num protowire.Number, typ protowire.Type, raw []byte, v uint64) error {
+		value := math.Float64frombits(v)
+		switch num {
+		case 1:
+			h.Min = value
+		case 2:
+			h.Max = value
+		case 3:
+			h.Num = value
+		case 4:
+			h.Sum = value
+		case 5:
+			h.SumSquares = value
+		case 6:
+			h.BucketLimits = appendDoubles(h.BucketLimits, typ, raw, v)
+		case 7:
+			h.BucketCounts = appendDoubles(h.BucketCounts, typ, raw, v)
+		}
+		return nil
+	})
+	return h, err
+}
+
+// parseTensor 解码TensorProto中导入需要的字段
+func parseTensor(data []byte) (*tensor, error) {
+	t := &tensor{}
+	err := walk(data, func(num protowire.Number, typ protowire.Type, raw []byte, v uint64) error {
+		switch num {
+		case 1:
+			t.dtype = v
+		case 2:
+			// TensorShapeProto.dim.size
+			return walk(raw, func(num protowire.Number, typ protowire.Type, raw []byte, _ uint64) error {
+				if num != 2 || typ != protowire.BytesType {
+					return nil
+				}
+				return walk(raw, func(num protowire.Number, typ protowire.Type, _ []byte, v uint64) error {
+					if num == 1 && typ == protowire.VarintType {
+						t.shape = append(t.shape, int64(v))
+					}
+					return nil
+				})
+			})
+		case 4:
+			t.content = raw
+		case 5:
+			t.floats = appendFloats(t.floats, typ, raw, v)
+		case 6:
+			t.floats = appendDoubles(t.floats, typ, raw, v)
+		case 7, 10:
+			t.floats = appendVarints(t.floats, typ, raw, v, func(v uint64) float64 { return float64(int64(v)) })
+		case 13:
+			t.floats = appendVarints(t.floats, typ, raw, v, func(v uint64) float64 { return halfToFloat(uint16(v)) })
+		case 8:
+			t.strings = append(t.strings, string(raw))
+		}
+		return nil
+	})
+	return t, err
+}
+
+// numbers 返回数值张量的全部元素，优先使用tensor_content中的紧凑编码
+func (t *tensor) numbers() []float64 {
+	if len(t.content) == 0 {
+		return t.floats
+	}
+	var size int
+	switch t.dtype {
+	case dtFloat, dtInt32:
+		size = 4
+	case dtDouble, dtInt64:
+		size = 8
+	case dtHalf:
+		size = 2
+	default:
+		return nil
+	}
+	values := make([]float64, 0, len(t.content)/size)
+	for i := 0; i+size <= len(t.content); i += size {
+		b := t.content[i : i+size]
+		switch t.dtype {
+		case dtFloat:
+			values = append(values, float64(math.Float32frombits(binary.LittleEndian.Uint32(b))))
+		case dtInt32:
+			values = append(values, float64(int32(binary.LittleEndian.Uint32(b))))
+		case dtDouble:
+			values = append(values, math.Float64frombits(binary.LittleEndian.Uint64(b)))
+		case dtInt64:
+			values = append(values, float64(int64(binary.LittleEndian.Uint64(b))))
+		case dtHalf:
+			values = append(values, halfToFloat(binary.LittleEndian.Uint16(b)))
+		}
+	}
+	return values
+}
+
+// histogram 将TF2直方图张量转换为直方图
+// 张量形状为[k, 3]，每行依次为桶的左边界、右边界和样本数，均值和平方和按桶中点估算
+func (t *tensor) histogram() *metrics.Histogram {
+	values := t.numbers()
+	if len(t.shape) != 2 || t.shape[1] != 3 || int64(len(values)) != t.shape[0]*3 || len(values) == 0 {
+		return nil
+	}
+	h := &metrics.Histogram{Min: values[0], Max: values[len(values)-2]}
+	for i := 0; i < len(values); i += 3 {
+		left, right, count := values[i], values[i+1], values[i+2]
+		mid := (left + right) / 2
+		h.Num += count
+		h.Sum += count * mid
+		h.SumSquares += count * mid * mid
+		h.BucketLimits = append(h.BucketLimits, right)
+		h.BucketCounts = append(h.BucketCounts, count)
+	}
+	return h
+}
+
+// walk 遍历消息的每个字段，varint和定长字段的值通过v传入，长度前缀字段的内容通过raw传入
+func walk(data []byte, fn func(num protowire.Number, typ protowire.Type, raw []byte, v uint64) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return fmt.Errorf("解码protobuf失败: %w", protowire.ParseError(n))
+		}
+		data = data[n:]
+
+		var raw []byte
+		var v uint64
+		switch typ {
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(data)
+		case protowire.Fixed32Type:
+			var v32 uint32
+			v32, n = protowire.ConsumeFixed32(data)
+			v = uint64(v32)
+		case protowire.Fixed64Type:
+			v, n = protowire.ConsumeFixed64(data)
+		case protowire.BytesType:
+			raw, n = protowire.ConsumeBytes(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return fmt.Errorf("解码protobuf字段%d失败: %w", num, protowire.ParseError(n))
+		}
+		data = data[n:]
+		if err := fn(num, typ, raw, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendDoubles 追加repeated double字段，兼容打包和未打包两种编码
+func appendDoubles(values []float64, typ protowire.Type, raw []byte, v uint64) []float64 {
+	if typ == protowire.Fixed64Type {
+		return append(values, math.Float64frombits(v))
+	}
+	for len(raw) >= 8 {
+		values = append(values, math.Float64frombits(binary.LittleEndian.Uint64(raw)))
+		raw = raw[8:]
+	}
+	return values
+}
+
+// appendFloats 追加repeated float字段，兼容打包和未打包两种编码
+func appendFloats(values []float64, typ protowire.Type, raw []byte, v uint64) []float64 {
+	if typ == protowire.Fixed32Type {
+		return append(values, float64(math.Float32frombits(uint32(v))))
+	}
+	for len(raw) >= 4 {
+		values = append(values, float64(math.Float32frombits(binary.LittleEndian.Uint32(raw))))
+		raw = raw[4:]
+	}
+	return values
+}
+
+// appendVarints 追加repeated整数字段，兼容打包和未打包两种编码
+func appendVarints(values []float64, typ protowire.Type, raw []byte, v uint64, convert func(uint64) float64) []float64 {
+	if typ == protowire.VarintType {
+		return append(values, convert(v))
+	}
+	for len(raw) > 0 {
+		v, n := protowire.ConsumeVarint(raw)
+		if n < 0 {
+			break
+		}
+		values = append(values, convert(v))
+		raw = raw[n:]
+	}
+	return values
+}
+
+// halfToFloat 将IEEE 754半精度浮点数转换为float64
+func halfToFloat(h uint16) float64 {
+	sign := 1.0
+	if h&0x8000 != 0 {
+		sign = -1
+	}
+	exponent := int(h>>10) & 0x1f
+	fraction := float64(h & 0x3ff)
+	switch exponent {
+	case 0:
+		return sign * math.Ldexp(fraction, -24)
+	case 0x1f:
+		if fraction == 0 {
+			return math.Inf(int(sign))
+		}
+		return math.NaN()
+	}
+	return sign * math.Ldexp(1024+fraction, exponent-25)
+}
@@ -0,0 +1,90 @@
+package tfevents
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+)
+
+// recordHeaderSize 记录头长度：8字节数据长度和4字节长度校验和
+const recordHeaderSize = 12
+
+// maxRecordSize 单条记录的最大长度，超过时视为文件损坏
+const maxRecordSize = 64 << 20
+
+var (
+	// ErrCorrupt 记录头校验失败，无法确定后续记录的位置，文件不能继续读取
+	ErrCorrupt = errors.New("tfevents记录头校验失败")
+	// ErrDataCorrupt 记录数据校验失败，该条记录已被跳过，可以继续读取
+	ErrDataCorrupt = errors.New("tfevents记录数据校验失败")
+)
+
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+// maskedCRC 计算TFRecord使用的掩码CRC32C
+func maskedCRC(data []byte) uint32 {
+	crc := crc32.Checksum(data, crc32c)
+	return ((crc >> 15) | (crc << 17)) + 0xa282ead8
+}
+
+// Reader TFRecord格式读取器
+// 每条记录的格式为：uint64数据长度、uint32长度校验和、数据、uint32数据校验和，均为小端序
+type Reader struct {
+	r      io.Reader
+	offset int64
+}
+
+// NewReader 创建读取器，offset为r当前位置在文件中的偏移量
+func NewReader(r io.Reader, offset int64) *Reader {
+	return &Reader{r: r, offset: offset}
+}
+
+// Offset 返回最后一条完整记录结束位置的偏移量，下次从该位置继续读取
+func (r *Reader) Offset() int64 {
+	return r.offset
+}
+
+// Next 读取下一条记录
+// 文件末尾的记录还没写完时返回io.EOF且不移动偏移量，写入方追加数据后可以从Offset重新读取
+func (r *Reader) Next() ([]byte, error) {
+	var header [recordHeaderSize]byte
+	if _, err := io.ReadFull(r.r, header[:]); err != nil {
+		return nil, eof(err)
+	}
+	if binary.LittleEndian.Uint32(header[8:]) != maskedCRC(header[:8]) {
+		return nil, ErrCorrupt
+	}
+	length := binary.LittleEndian.Uint64(header[:8])
+	if length > maxRecordSize {
+		return nil, ErrCorrupt
+	}
+
+	data := make([]byte, length+4)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		return nil, eof(err)
+	}
+	r.offset += recordHeaderSize + int64(length) + 4
+	if binary.LittleEndian.Uint32(data[length:]) != maskedCRC(data[:length]) {
+		return nil, ErrDataCorrupt
+	}
+	return data[:length], nil
+}
+
+// eof 将不完整记录导致的ErrUnexpectedEOF转换为io.EOF
+func eof(err error) error {
+	if err == io.ErrUnexpectedEOF {
+		return io.EOF
+	}
+	return err
+}
+
+// AppendRecord 将数据按TFRecord格式追加到buf
+func AppendRecord(buf, data []byte) []byte {
+	var header [recordHeaderSize]byte
+	binary.LittleEndian.PutUint64(header[:8], uint64(len(data)))
+	binary.LittleEndian.PutUint32(header[8:], maskedCRC(header[:8]))
+	buf = append(buf, header[:]...)
+	buf = append(buf, data...)
+	return binary.LittleEndian.AppendUint32(buf, maskedCRC(data))
+}
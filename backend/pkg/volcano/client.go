@@ -3,6 +3,7 @@ package volcano
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -13,6 +14,7 @@ import (
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	vcjob "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+	busv1alpha1 "volcano.sh/apis/pkg/apis/bus/v1alpha1"
 	vcclient "volcano.sh/apis/pkg/client/clientset/versioned"
 )
 
@@ -61,6 +63,54 @@ func NewClient(kubeconfig, namespace string) (*Client, error) {
 	}, nil
 }
 
+// NewClientFromKubeConfig 使用kubeconfig内容创建Volcano客户端，用于访问登记的其他集群
+func NewClientFromKubeConfig(kubeconfig []byte, namespace string) (*Client, error) {
+	config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("解析kubeconfig失败: %v", err)
+	}
+
+	volcanoClient, err := vcclient.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("创建Volcano客户端失败: %v", err)
+	}
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("创建Kubernetes客户端失败: %v", err)
+	}
+
+	return &Client{
+		volcanoClient: volcanoClient,
+		kubeClient:    kubeClient,
+		config:        config,
+		namespace:     namespace,
+	}, nil
+}
+
+// NewClientWithClientsets 使用已有的客户端创建Volcano客户端（用于测试或复用连接）
+func NewClientWithClientsets(volcanoClient vcclient.Interface, kubeClient kubernetes.Interface, namespace string) *Client {
+	return &Client{
+		volcanoClient: volcanoClient,
+		kubeClient:    kubeClient,
+		namespace:     namespace,
+	}
+}
+
+// Namespace 获取默认命名空间
+func (c *Client) Namespace() string {
+	return c.namespace
+}
+
+// VolcanoClientset 获取Volcano CRD客户端
+func (c *Client) VolcanoClientset() vcclient.Interface {
+	return c.volcanoClient
+}
+
+// KubeClientset 获取Kubernetes标准客户端
+func (c *Client) KubeClientset() kubernetes.Interface {
+	return c.kubeClient
+}
+
 // JobSpec Volcano作业规格定义
 type JobSpec struct {
 	Name                    string
@@ -132,6 +182,8 @@ type Container struct {
 	ReadinessProbe  *corev1.Probe
 	ImagePullPolicy corev1.PullPolicy
 	SecurityContext *corev1.SecurityContext
+
+	TerminationMessagePolicy corev1.TerminationMessagePolicy
 }
 
 // RunPolicy 运行策略
@@ -233,7 +285,7 @@ func (c *Client) CreateJob(spec *JobSpec) (*vcjob.Job, error) {
 		metav1.CreateOptions{},
 	)
 	if err != nil {
-		return nil, fmt.Errorf("创建Volcano作业失败: %v", err)
+		return nil, fmt.Errorf("创建Volcano作业失败: %w", err)
 	}
 
 	return createdJob, nil
@@ -323,6 +375,8 @@ func (c *Client) buildContainer(container Container) corev1.Container {
 		ReadinessProbe:  container.ReadinessProbe,
 		ImagePullPolicy: container.ImagePullPolicy,
 		SecurityContext: container.SecurityContext,
+
+		TerminationMessagePolicy: container.TerminationMessagePolicy,
 	}
 }
 
@@ -513,66 +567,61 @@ func (c *Client) DeleteJob(namespace, jobName string) error {
 		metav1.DeleteOptions{},
 	)
 	if err != nil && !errors.IsNotFound(err) {
-		return fmt.Errorf("删除Volcano作业失败: %v", err)
+		return fmt.Errorf("删除Volcano作业失败: %w", err)
 	}
 
 	return nil
 }
 
 // SuspendJob 暂停作业
+// Volcano控制器会覆盖直接写入的状态，需要通过bus命令触发AbortJob
 func (c *Client) SuspendJob(namespace, jobName string) error {
-	// 获取现有作业
-	job, err := c.volcanoClient.BatchV1alpha1().Jobs(c.getNamespace(namespace)).Get(
-		context.TODO(),
-		jobName,
-		metav1.GetOptions{},
-	)
-	if err != nil {
-		return fmt.Errorf("获取作业失败: %v", err)
-	}
-
-	// 添加暂停动作
-	job.Status.State.Phase = vcjob.Aborted
-
-	// 更新作业状态
-	_, err = c.volcanoClient.BatchV1alpha1().Jobs(c.getNamespace(namespace)).UpdateStatus(
-		context.TODO(),
-		job,
-		metav1.UpdateOptions{},
-	)
-	if err != nil {
-		return fmt.Errorf("暂停作业失败: %v", err)
+	if err := c.sendJobCommand(namespace, jobName, busv1alpha1.AbortJobAction, "暂停作业"); err != nil {
+		return fmt.Errorf("暂停作业失败: %w", err)
 	}
-
 	return nil
 }
 
 // ResumeJob 恢复作业
 func (c *Client) ResumeJob(namespace, jobName string) error {
+	if err := c.sendJobCommand(namespace, jobName, busv1alpha1.ResumeJobAction, "恢复作业"); err != nil {
+		return fmt.Errorf("恢复作业失败: %w", err)
+	}
+	return nil
+}
+
+// sendJobCommand 向作业发送Volcano bus命令
+func (c *Client) sendJobCommand(namespace, jobName string, action busv1alpha1.Action, message string) error {
+	namespace = c.getNamespace(namespace)
+
 	// 获取现有作业
-	job, err := c.volcanoClient.BatchV1alpha1().Jobs(c.getNamespace(namespace)).Get(
+	job, err := c.volcanoClient.BatchV1alpha1().Jobs(namespace).Get(
 		context.TODO(),
 		jobName,
 		metav1.GetOptions{},
 	)
 	if err != nil {
-		return fmt.Errorf("获取作业失败: %v", err)
+		return fmt.Errorf("获取作业失败: %w", err)
 	}
 
-	// 恢复作业
-	job.Status.State.Phase = vcjob.Pending
+	ctrlRef := metav1.NewControllerRef(job, vcjob.SchemeGroupVersion.WithKind("Job"))
+	command := &busv1alpha1.Command{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            fmt.Sprintf("%s-%s-%d", job.Name, strings.ToLower(string(action)), time.Now().UnixNano()),
+			Namespace:       namespace,
+			OwnerReferences: []metav1.OwnerReference{*ctrlRef},
+		},
+		TargetObject: ctrlRef,
+		Action:       string(action),
+		Message:      message,
+	}
 
-	// 更新作业状态
-	_, err = c.volcanoClient.BatchV1alpha1().Jobs(c.getNamespace(namespace)).UpdateStatus(
+	_, err = c.volcanoClient.BusV1alpha1().Commands(namespace).Create(
 		context.TODO(),
-		job,
-		metav1.UpdateOptions{},
+		command,
+		metav1.CreateOptions{},
 	)
-	if err != nil {
-		return fmt.Errorf("恢复作业失败: %v", err)
-	}
-
-	return nil
+	return err
 }
 
 // ListJobs 列出作业
@@ -611,8 +660,8 @@ func (c *Client) getSchedulerName(schedulerName string) string {
 	return "volcano"
 }
 
-// BuildResourceRequirements 构建资源需求
-func BuildResourceRequirements(cpu, memory string, gpuCount int64) corev1.ResourceRequirements {
+// BuildResourceRequirements 构建资源需求，GPU按共享方式申请整卡、MIG实例或vGPU
+func BuildResourceRequirements(cpu, memory string, gpuCount int64, sharing GPUSharing) corev1.ResourceRequirements {
 	requirements := corev1.ResourceRequirements{
 		Requests: corev1.ResourceList{},
 		Limits:   corev1.ResourceList{},
@@ -629,9 +678,10 @@ func BuildResourceRequirements(cpu, memory string, gpuCount int64) corev1.Resour
 	}
 
 	if gpuCount > 0 {
-		gpuQuantity := resource.MustParse(fmt.Sprintf("%d", gpuCount))
-		requirements.Requests["nvidia.com/gpu"] = gpuQuantity
-		requirements.Limits["nvidia.com/gpu"] = gpuQuantity
+		for name, quantity := range sharing.ResourceList(gpuCount) {
+			requirements.Requests[name] = quantity
+			requirements.Limits[name] = quantity
+		}
 	}
 
 	return requirements
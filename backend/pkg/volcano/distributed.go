@@ -0,0 +1,292 @@
+package volcano
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// 分布式训练启动模式
+const (
+	launchPyTorch    = "pytorch"
+	launchTensorFlow = "tensorflow"
+	launchMPI        = "mpi"
+	launchPaddle     = "paddle"
+)
+
+// 各框架的通信端口
+const (
+	pytorchPort     = 23456
+	pytorchRdzvPort = 29400
+	tensorflowPort  = 2222
+	paddlePort      = 36543
+)
+
+const (
+	// taskIndexEnv Volcano env插件为每个Pod注入的任务内序号
+	taskIndexEnv = "VC_TASK_INDEX"
+	// mpiHostfilePath MPI启动节点上生成的hostfile路径
+	mpiHostfilePath = "/tmp/volcano/hostfile"
+	// launchScriptName sh -c 执行引导脚本时的$0，用户命令从$1开始
+	launchScriptName = "volcano-launch"
+)
+
+// launchMode 确定作业的分布式启动模式，Horovod作业以MPI方式启动
+func launchMode(spec *TrainingJobSpec) string {
+	if strings.ToLower(spec.JobType) == launchMPI {
+		return launchMPI
+	}
+	switch strings.ToLower(spec.Framework) {
+	case "pytorch":
+		return launchPyTorch
+	case "tensorflow":
+		return launchTensorFlow
+	case "mpi", "horovod":
+		return launchMPI
+	case "paddle", "paddlepaddle":
+		return launchPaddle
+	}
+	return ""
+}
+
+// hasPSTask 作业是否包含参数服务器任务，只有TensorFlow和PaddlePaddle支持PS模式
+func hasPSTask(spec *TrainingJobSpec) bool {
+	if spec.PSReplicas <= 0 {
+		return false
+	}
+	mode := launchMode(spec)
+	return mode == launchTensorFlow || mode == launchPaddle
+}
+
+// taskHost 返回svc插件为任务副本生成的主机名：<作业名>-<任务名>-<序号>.<作业名>
+func taskHost(spec *TrainingJobSpec, taskType string, index int32) string {
+	return fmt.Sprintf("%s-%s-%d.%s", spec.Name, taskType, index, spec.Name)
+}
+
+// taskHosts 返回任务全部副本的地址，port为0时不带端口
+func taskHosts(spec *TrainingJobSpec, taskType string, replicas int32, port int) []string {
+	hosts := make([]string, 0, replicas)
+	for i := int32(0); i < replicas; i++ {
+		host := taskHost(spec, taskType, i)
+		if port > 0 {
+			host = fmt.Sprintf("%s:%d", host, port)
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+// trainerHosts 返回参与计算的节点地址，master在前worker在后，与节点序号一致
+func trainerHosts(spec *TrainingJobSpec, port int) []string {
+	hosts := taskHosts(spec, "master", spec.MasterReplicas, port)
+	return append(hosts, taskHosts(spec, "worker", spec.WorkerReplicas, port)...)
+}
+
+// rankOffset 任务第一个副本的全局节点序号
+func rankOffset(spec *TrainingJobSpec, taskType string) int32 {
+	if taskType == "worker" {
+		return spec.MasterReplicas
+	}
+	return 0
+}
+
+// procsPerNode 每个节点启动的训练进程数，按GPU数量计算
+func procsPerNode(spec *TrainingJobSpec) int64 {
+	if spec.GPUCount > 0 {
+		return spec.GPUCount
+	}
+	return 1
+}
+
+// nodeRankExpr 计算全局节点序号的shell表达式
+func nodeRankExpr(spec *TrainingJobSpec, taskType string) string {
+	return fmt.Sprintf("$((%d + ${%s:-0}))", rankOffset(spec, taskType), taskIndexEnv)
+}
+
+// buildDistributedTrainingEnvVars 构建各副本相同的分布式训练环境变量
+// 与副本序号相关的变量在引导脚本中根据VC_TASK_INDEX计算
+func (jm *JobManager) buildDistributedTrainingEnvVars(spec *TrainingJobSpec, taskType string) []corev1.EnvVar {
+	var envVars []corev1.EnvVar
+	add := func(name, value string) {
+		envVars = append(envVars, corev1.EnvVar{Name: name, Value: value})
+	}
+
+	switch launchMode(spec) {
+	case launchPyTorch:
+		hosts := trainerHosts(spec, 0)
+		if len(hosts) == 0 {
+			break
+		}
+		nodes := len(hosts)
+		masterAddr := hosts[0]
+		add("MASTER_ADDR", masterAddr)
+		add("MASTER_PORT", fmt.Sprintf("%d", pytorchPort))
+		add("WORLD_SIZE", fmt.Sprintf("%d", nodes))
+		add("NCCL_DEBUG", "INFO")
+
+		// torchrun从PET_前缀的环境变量读取启动参数，直接执行 torchrun train.py 即可
+		add("PET_NPROC_PER_NODE", fmt.Sprintf("%d", procsPerNode(spec)))
+		add("PET_MASTER_ADDR", masterAddr)
+		add("PET_MASTER_PORT", fmt.Sprintf("%d", pytorchPort))
+		minNodes := int(spec.MinAvailable)
+		if minNodes > 0 && minNodes < nodes {
+			// 最小可用数小于节点数时以弹性方式启动，节点序号由c10d rendezvous分配
+			add("PET_NNODES", fmt.Sprintf("%d:%d", minNodes, nodes))
+			add("PET_RDZV_BACKEND", "c10d")
+			add("PET_RDZV_ENDPOINT", fmt.Sprintf("%s:%d", masterAddr, pytorchRdzvPort))
+			add("PET_RDZV_ID", spec.Name)
+		} else {
+			add("PET_NNODES", fmt.Sprintf("%d", nodes))
+		}
+	case launchTensorFlow:
+		add("TF_CLUSTER_SPEC", jm.buildTFClusterSpec(spec))
+	case launchMPI:
+		slots := procsPerNode(spec)
+		workers := taskHosts(spec, "worker", spec.WorkerReplicas, 0)
+		horovodHosts := make([]string, 0, len(workers))
+		for _, host := range workers {
+			horovodHosts = append(horovodHosts, fmt.Sprintf("%s:%d", host, slots))
+		}
+		add("OMPI_ALLOW_RUN_AS_ROOT", "1")
+		add("OMPI_ALLOW_RUN_AS_ROOT_CONFIRM", "1")
+		add("OMPI_MCA_orte_default_hostfile", mpiHostfilePath)
+		add("MPI_HOSTFILE", mpiHostfilePath)
+		add("MPI_NUM_PROCESSES", fmt.Sprintf("%d", int64(len(workers))*slots))
+		add("HOROVOD_HOSTS", strings.Join(horovodHosts, ","))
+	case launchPaddle:
+		trainers := trainerHosts(spec, paddlePort)
+		if len(trainers) == 0 {
+			break
+		}
+		add("PADDLE_PORT", fmt.Sprintf("%d", paddlePort))
+		add("PADDLE_TRAINERS_NUM", fmt.Sprintf("%d", len(trainers)))
+		add("PADDLE_TRAINER_ENDPOINTS", strings.Join(trainers, ","))
+		add("PADDLE_TRAINERS", strings.Join(trainerHosts(spec, 0), ","))
+		add("PADDLE_MASTER", trainers[0])
+		add("PADDLE_NNODES", fmt.Sprintf("%d", len(trainers)))
+		if hasPSTask(spec) {
+			add("PADDLE_PSERVERS_IP_PORT_LIST", strings.Join(taskHosts(spec, "ps", spec.PSReplicas, paddlePort), ","))
+			if taskType == "ps" {
+				add("TRAINING_ROLE", "PSERVER")
+			} else {
+				add("TRAINING_ROLE", "TRAINER")
+			}
+		}
+	}
+
+	return envVars
+}
+
+// buildTFClusterSpec 构建TF_CONFIG中的cluster部分，master任务对应TensorFlow的chief
+func (jm *JobManager) buildTFClusterSpec(spec *TrainingJobSpec) string {
+	cluster := make(map[string][]string)
+	if spec.MasterReplicas > 0 {
+		cluster["chief"] = taskHosts(spec, "master", spec.MasterReplicas, tensorflowPort)
+	}
+	if spec.WorkerReplicas > 0 {
+		cluster["worker"] = taskHosts(spec, "worker", spec.WorkerReplicas, tensorflowPort)
+	}
+	if hasPSTask(spec) {
+		cluster["ps"] = taskHosts(spec, "ps", spec.PSReplicas, tensorflowPort)
+	}
+	data, _ := json.Marshal(cluster)
+	return string(data)
+}
+
+// tfTaskType 将Volcano任务名映射为TF_CONFIG中的任务类型
+func tfTaskType(taskType string) string {
+	if taskType == "master" {
+		return "chief"
+	}
+	return taskType
+}
+
+// buildBootstrapScript 构建在用户命令之前执行的引导脚本，根据VC_TASK_INDEX导出与副本序号相关的环境变量
+func (jm *JobManager) buildBootstrapScript(spec *TrainingJobSpec, taskType string) []string {
+	switch launchMode(spec) {
+	case launchPyTorch:
+		return []string{
+			fmt.Sprintf("export NODE_RANK=%s", nodeRankExpr(spec, taskType)),
+			"export RANK=$NODE_RANK PET_NODE_RANK=$NODE_RANK",
+		}
+	case launchTensorFlow:
+		return []string{
+			fmt.Sprintf(`export TF_CONFIG="{\"cluster\":${TF_CLUSTER_SPEC},\"task\":{\"type\":\"%s\",\"index\":${%s:-0}}}"`,
+				tfTaskType(taskType), taskIndexEnv),
+		}
+	case launchPaddle:
+		self := fmt.Sprintf("%s-%s-${%s:-0}.%s:%d", spec.Name, taskType, taskIndexEnv, spec.Name, paddlePort)
+		if taskType == "ps" {
+			return []string{
+				fmt.Sprintf("export PADDLE_PSERVER_ID=${%s:-0}", taskIndexEnv),
+				fmt.Sprintf("export PADDLE_CURRENT_ENDPOINT=%s", self),
+			}
+		}
+		return []string{
+			fmt.Sprintf("export PADDLE_TRAINER_ID=%s", nodeRankExpr(spec, taskType)),
+			fmt.Sprintf("export PADDLE_CURRENT_ENDPOINT=%s", self),
+		}
+	}
+	return nil
+}
+
+// buildLaunchCommand 构建容器的启动命令和参数
+// 需要引导的框架以 sh -c 执行引导脚本后exec用户命令；MPI作业由master执行mpirun，worker只运行sshd
+func (jm *JobManager) buildLaunchCommand(spec *TrainingJobSpec, taskType string) ([]string, []string) {
+	args := jm.buildContainerArgs(spec, taskType)
+
+	if launchMode(spec) == launchMPI && spec.WorkerReplicas > 0 {
+		if taskType == "worker" {
+			return []string{"/bin/sh", "-c", "mkdir -p /var/run/sshd && exec /usr/sbin/sshd -D -e"}, nil
+		}
+		if len(spec.Command) == 0 {
+			return spec.Command, args
+		}
+		script := append(jm.buildMPIHostfileScript(spec), jm.buildMPIRunCommand(spec)+` "$@"`)
+		return []string{"/bin/sh", "-c", strings.Join(script, "\n"), launchScriptName}, append(append([]string{}, spec.Command...), args...)
+	}
+
+	// 未指定启动命令时使用镜像入口，无法执行引导脚本
+	script := jm.buildBootstrapScript(spec, taskType)
+	if len(script) == 0 || len(spec.Command) == 0 {
+		return spec.Command, args
+	}
+	script = append(script, `exec "$@"`)
+	return []string{"/bin/sh", "-c", strings.Join(script, "\n"), launchScriptName}, append(append([]string{}, spec.Command...), args...)
+}
+
+// buildMPIHostfileScript 生成MPI hostfile，每个worker的slots为单节点进程数
+func (jm *JobManager) buildMPIHostfileScript(spec *TrainingJobSpec) []string {
+	slots := procsPerNode(spec)
+	lines := []string{fmt.Sprintf("mkdir -p %s", mpiHostfilePath[:strings.LastIndex(mpiHostfilePath, "/")])}
+	entries := make([]string, 0, spec.WorkerReplicas)
+	for _, host := range taskHosts(spec, "worker", spec.WorkerReplicas, 0) {
+		entries = append(entries, fmt.Sprintf("'%s slots=%d'", host, slots))
+	}
+	return append(lines, fmt.Sprintf("printf '%%s\\n' %s > %s", strings.Join(entries, " "), mpiHostfilePath))
+}
+
+// buildMPIRunCommand 构建mpirun命令，平台和用户环境变量通过-x传递给各进程
+func (jm *JobManager) buildMPIRunCommand(spec *TrainingJobSpec) string {
+	parts := []string{
+		"exec mpirun",
+		"--hostfile", mpiHostfilePath,
+		"-np", "${MPI_NUM_PROCESSES}",
+		"-bind-to", "none", "-map-by", "slot",
+		"-mca", "pml", "ob1", "-mca", "btl", "^openib",
+		"-x", "NCCL_DEBUG=INFO", "-x", "LD_LIBRARY_PATH", "-x", "PATH",
+		"-x", "JOB_NAME", "-x", "FRAMEWORK",
+	}
+	keys := make([]string, 0, len(spec.EnvVars))
+	for key := range spec.EnvVars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		parts = append(parts, "-x", key)
+	}
+	return strings.Join(parts, " ")
+}
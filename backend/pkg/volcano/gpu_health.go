@@ -0,0 +1,85 @@
+package volcano
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/util/retry"
+	vcjob "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+)
+
+// GPUCordonAnnotation 因GPU故障被自动隔离的节点上记录的故障原因，解除隔离时删除
+const GPUCordonAnnotation = "volctrain.io/gpu-cordon-reason"
+
+// DrainedPod 从节点上驱逐的Volcano Pod
+type DrainedPod struct {
+	Namespace string
+	Name      string
+	JobName   string // 所属Volcano作业，不属于Volcano作业时为空
+}
+
+// CordonNode 将节点标记为不可调度并记录原因，节点已隔离时只更新原因
+func (gm *GPUManager) CordonNode(ctx context.Context, nodeName, reason string) error {
+	return gm.updateNode(ctx, nodeName, func(node *corev1.Node) {
+		node.Spec.Unschedulable = true
+		if node.Annotations == nil {
+			node.Annotations = make(map[string]string)
+		}
+		node.Annotations[GPUCordonAnnotation] = reason
+	})
+}
+
+// UncordonNode 恢复节点调度并删除隔离原因
+func (gm *GPUManager) UncordonNode(ctx context.Context, nodeName string) error {
+	return gm.updateNode(ctx, nodeName, func(node *corev1.Node) {
+		node.Spec.Unschedulable = false
+		delete(node.Annotations, GPUCordonAnnotation)
+	})
+}
+
+func (gm *GPUManager) updateNode(ctx context.Context, nodeName string, mutate func(node *corev1.Node)) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node, err := gm.client.kubeClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		mutate(node)
+		_, err = gm.client.kubeClient.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("更新节点 %s 失败: %v", nodeName, err)
+	}
+	return nil
+}
+
+// DrainNode 删除节点上由Volcano调度且未结束的Pod，返回被删除的Pod
+// GPU故障时Pod已无法正常运行，直接删除而不经过驱逐API，避免被PodDisruptionBudget阻塞
+func (gm *GPUManager) DrainNode(ctx context.Context, nodeName string) ([]DrainedPod, error) {
+	pods, err := gm.client.kubeClient.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取节点 %s 的Pod列表失败: %v", nodeName, err)
+	}
+
+	var drained []DrainedPod
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != nodeName || pod.Spec.SchedulerName != gm.client.getSchedulerName("") {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		err := gm.client.kubeClient.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return drained, fmt.Errorf("删除Pod %s/%s 失败: %v", pod.Namespace, pod.Name, err)
+		}
+		drained = append(drained, DrainedPod{Namespace: pod.Namespace, Name: pod.Name, JobName: pod.Labels[vcjob.JobNameKey]})
+	}
+	return drained, nil
+}
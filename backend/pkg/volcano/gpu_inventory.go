@@ -0,0 +1,202 @@
+package volcano
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NVIDIA GPU Feature Discovery(GFD)和Node Feature Discovery(NFD)写入的节点标签
+const (
+	GPUProductLabel       = "nvidia.com/gpu.product"
+	GPUCountLabel         = "nvidia.com/gpu.count" // 物理GPU数，开启时间片共享时nvidia.com/gpu容量为count*replicas
+	GPUMemoryLabel        = "nvidia.com/gpu.memory"
+	GPUFamilyLabel        = "nvidia.com/gpu.family"
+	CUDADriverMajorLabel  = "nvidia.com/cuda.driver.major"
+	CUDADriverMinorLabel  = "nvidia.com/cuda.driver.minor"
+	CUDADriverRevLabel    = "nvidia.com/cuda.driver.rev"
+	CUDARuntimeMajorLabel = "nvidia.com/cuda.runtime.major"
+	CUDARuntimeMinorLabel = "nvidia.com/cuda.runtime.minor"
+
+	// 新版GFD使用的完整版本标签，存在时优先使用
+	CUDADriverVersionLabel  = "nvidia.com/cuda.driver-version.full"
+	CUDARuntimeVersionLabel = "nvidia.com/cuda.runtime-version.full"
+
+	// GPUUUIDsAnnotation 节点上各GPU的UUID，逗号分隔并按设备索引排列
+	// GFD不提供设备UUID，由节点初始化脚本或DCGM同步写入，缺失时设备UUID为空
+	GPUUUIDsAnnotation = "volctrain.io/gpu-uuids"
+)
+
+// gpuNodeSelector 列出节点时排除虚拟节点
+const gpuNodeSelector = "node.kubernetes.io/instance-type!=virtual-node"
+
+// GPUNodeInventory GPU节点清单
+type GPUNodeInventory struct {
+	GPUResourceInfo
+	Hostname      string
+	InternalIP    string
+	ExternalIP    string
+	CPUCores      int
+	MemoryGB      int
+	StorageGB     int
+	OSImage       string
+	KernelVersion string
+	Devices       []GPUDeviceInventory
+}
+
+// Ready 节点是否就绪
+func (n *GPUNodeInventory) Ready() bool {
+	return n.Status == "Ready"
+}
+
+// GPUDeviceInventory GPU设备清单
+type GPUDeviceInventory struct {
+	Index         int
+	UUID          string
+	Model         string
+	Architecture  string
+	MemoryTotalMB int
+	DriverVersion string
+	CUDAVersion   string
+	SharingMode   string
+	ShareCapacity int              // 可同时分配的共享单元数，独占为1
+	MIGDevices    map[string]int32 // 每张卡各MIG规格的实例数
+}
+
+// DiscoverGPUNodes 读取集群中带nvidia.com/gpu资源的节点及其GPU设备
+func (gm *GPUManager) DiscoverGPUNodes(ctx context.Context) ([]GPUNodeInventory, error) {
+	nodes, err := gm.client.kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: gpuNodeSelector})
+	if err != nil {
+		return nil, fmt.Errorf("获取节点列表失败: %v", err)
+	}
+
+	var inventories []GPUNodeInventory
+	for i := range nodes.Items {
+		if inventory := gm.nodeInventory(&nodes.Items[i]); inventory != nil {
+			inventories = append(inventories, *inventory)
+		}
+	}
+	sort.Slice(inventories, func(i, j int) bool {
+		return inventories[i].NodeName < inventories[j].NodeName
+	})
+	return inventories, nil
+}
+
+// nodeInventory 提取节点清单，节点没有GPU时返回nil
+func (gm *GPUManager) nodeInventory(node *corev1.Node) *GPUNodeInventory {
+	gpuInfo := gm.extractGPUInfo(node)
+	if gpuInfo == nil {
+		return nil
+	}
+
+	inventory := &GPUNodeInventory{
+		GPUResourceInfo: *gpuInfo,
+		Hostname:        node.Name,
+		OSImage:         node.Status.NodeInfo.OSImage,
+		KernelVersion:   node.Status.NodeInfo.KernelVersion,
+	}
+	for _, address := range node.Status.Addresses {
+		switch address.Type {
+		case corev1.NodeHostName:
+			inventory.Hostname = address.Address
+		case corev1.NodeInternalIP:
+			inventory.InternalIP = address.Address
+		case corev1.NodeExternalIP:
+			inventory.ExternalIP = address.Address
+		}
+	}
+	if cpu, ok := node.Status.Capacity[corev1.ResourceCPU]; ok {
+		inventory.CPUCores = int(cpu.Value())
+	}
+	if memory, ok := node.Status.Capacity[corev1.ResourceMemory]; ok {
+		inventory.MemoryGB = int(memory.Value() >> 30)
+	}
+	if storage, ok := node.Status.Capacity[corev1.ResourceEphemeralStorage]; ok {
+		inventory.StorageGB = int(storage.Value() >> 30)
+	}
+
+	count := int(gpuInfo.TotalGPUs)
+	if n, err := strconv.Atoi(node.Labels[GPUCountLabel]); err == nil && n > 0 {
+		count = n
+	}
+	memoryMB, _ := strconv.Atoi(node.Labels[GPUMemoryLabel])
+	var uuids []string
+	if value := node.Annotations[GPUUUIDsAnnotation]; value != "" {
+		uuids = strings.Split(value, ",")
+	}
+	shareCapacity, migDevices := deviceShares(node, gpuInfo, count)
+	for i := 0; i < count; i++ {
+		device := GPUDeviceInventory{
+			Index:         i,
+			Model:         gpuInfo.GPUType,
+			Architecture:  node.Labels[GPUFamilyLabel],
+			MemoryTotalMB: memoryMB,
+			DriverVersion: labelVersion(node.Labels, CUDADriverVersionLabel, CUDADriverMajorLabel, CUDADriverMinorLabel, CUDADriverRevLabel),
+			CUDAVersion:   labelVersion(node.Labels, CUDARuntimeVersionLabel, CUDARuntimeMajorLabel, CUDARuntimeMinorLabel),
+			SharingMode:   gpuInfo.SharingMode,
+			ShareCapacity: shareCapacity,
+			MIGDevices:    migDevices,
+		}
+		if i < len(uuids) {
+			device.UUID = strings.TrimSpace(uuids[i])
+		}
+		inventory.Devices = append(inventory.Devices, device)
+	}
+	return inventory
+}
+
+// deviceShares 按节点上报的共享资源计算每张卡的共享单元数，节点资源为所有卡之和，按卡数均分
+func deviceShares(node *corev1.Node, gpuInfo *GPUResourceInfo, count int) (int, map[string]int32) {
+	if count <= 0 {
+		return 1, nil
+	}
+	switch gpuInfo.SharingMode {
+	case GPUSharingTimeSlicing:
+		if gpuInfo.GPUReplicas > 1 {
+			return int(gpuInfo.GPUReplicas), nil
+		}
+		// 未打副本数标签时按nvidia.com/gpu容量推算
+		if shares := int(gpuInfo.TotalGPUs) / count; shares > 1 {
+			return shares, nil
+		}
+	case GPUSharingMIG:
+		profiles := make(map[string]int32)
+		shares := 0
+		for profile, instances := range migCapacity(node.Status.Capacity) {
+			if perDevice := instances / int32(count); perDevice > 0 {
+				profiles[profile] = perDevice
+				shares += int(perDevice)
+			}
+		}
+		if shares > 0 {
+			return shares, profiles
+		}
+	case GPUSharingVGPU:
+		vgpuNumber := node.Status.Capacity[VGPUNumberResourceName]
+		if shares := int(vgpuNumber.Value()) / count; shares > 0 {
+			return shares, nil
+		}
+	}
+	return 1, nil
+}
+
+// labelVersion 读取版本号，完整版本标签不存在时按各段标签拼接，如 535.104.05
+func labelVersion(labels map[string]string, fullKey string, partKeys ...string) string {
+	if version := labels[fullKey]; version != "" {
+		return version
+	}
+	var parts []string
+	for _, key := range partKeys {
+		part := labels[key]
+		if part == "" {
+			break
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ".")
+}
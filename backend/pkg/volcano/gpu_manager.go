@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strconv"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -13,7 +14,24 @@ import (
 
 // GPUManager GPU资源管理器
 type GPUManager struct {
-	client *Client
+	client    *Client
+	telemetry GPUTelemetry
+}
+
+// GPUTelemetry 节点GPU实时监控数据，由DCGM采集提供
+type GPUTelemetry interface {
+	// NodeGPUStats 返回节点最近一次采集的GPU汇总数据，没有数据时返回false
+	NodeGPUStats(nodeName string) (NodeGPUStats, bool)
+}
+
+// NodeGPUStats 节点GPU汇总监控数据
+type NodeGPUStats struct {
+	Utilization     float64 // 平均使用率(%)
+	MemoryUsedMB    float64
+	MemoryFreeMB    float64
+	PowerDrawW      float64  // 总功耗
+	MaxTemperatureC float64  // 最高温度
+	Faults          []string // 检测到XID或ECC错误的GPU，如"GPU 3: XID 79: GPU已从总线上掉线"
 }
 
 // NewGPUManager 创建GPU管理器
@@ -23,6 +41,11 @@ func NewGPUManager(client *Client) *GPUManager {
 	}
 }
 
+// SetTelemetry 设置GPU监控数据来源，未设置时节点使用率为0，功耗和温度为N/A
+func (gm *GPUManager) SetTelemetry(telemetry GPUTelemetry) {
+	gm.telemetry = telemetry
+}
+
 // GPUResourceInfo GPU资源信息
 type GPUResourceInfo struct {
 	NodeName       string            `json:"nodeName"`
@@ -39,6 +62,13 @@ type GPUResourceInfo struct {
 	Labels         map[string]string `json:"labels"`
 	Taints         []string          `json:"taints"`
 	Status         string            `json:"status"` // Ready, NotReady, Unknown
+	Unschedulable  bool              `json:"unschedulable"`
+	CordonReason   string            `json:"cordonReason,omitempty"` // 因GPU故障被自动隔离的原因
+	SharingMode    string            `json:"sharingMode"`            // exclusive, mig, time_slicing, vgpu
+	GPUReplicas    int32             `json:"gpuReplicas,omitempty"`  // 时间片共享时每张卡的副本数
+	MIGDevices     map[string]int32  `json:"migDevices,omitempty"`   // 各MIG规格可分配的实例数
+	VGPUAvailable  int32             `json:"vgpuAvailable,omitempty"`
+	VGPUMemoryMB   int64             `json:"vgpuMemoryMB,omitempty"` // 可分配的vGPU显存
 }
 
 // GPUAllocationStrategy GPU分配策略
@@ -65,6 +95,7 @@ type GPUAllocationRequest struct {
 	Tolerations  []corev1.Toleration   `json:"tolerations,omitempty"`
 	Priority     int32                 `json:"priority"`
 	Queue        string                `json:"queue"`
+	Sharing      GPUSharing            `json:"sharing"` // GPUCount按共享单元计，如MIG实例数或vGPU数
 }
 
 // GPUAllocationResult GPU分配结果
@@ -84,6 +115,7 @@ type AllocatedGPU struct {
 	GPUType      string `json:"gpuType"`
 	MemoryTotal  string `json:"memoryTotal"`
 	AllocationID string `json:"allocationId"`
+	Resource     string `json:"resource"` // 申请的扩展资源，如nvidia.com/mig-1g.10gb
 }
 
 // GetClusterGPUResources 获取集群GPU资源信息
@@ -92,7 +124,7 @@ func (gm *GPUManager) GetClusterGPUResources() ([]GPUResourceInfo, error) {
 	nodes, err := gm.client.kubeClient.CoreV1().Nodes().List(
 		context.TODO(),
 		metav1.ListOptions{
-			LabelSelector: "node.kubernetes.io/instance-type!=virtual-node",
+			LabelSelector: gpuNodeSelector,
 		},
 	)
 	if err != nil {
@@ -116,16 +148,23 @@ func (gm *GPUManager) GetClusterGPUResources() ([]GPUResourceInfo, error) {
 
 // extractGPUInfo 提取节点的GPU信息
 func (gm *GPUManager) extractGPUInfo(node *corev1.Node) *GPUResourceInfo {
-	// 检查节点是否有GPU资源
-	gpuCapacity, hasGPU := node.Status.Capacity["nvidia.com/gpu"]
-	if !hasGPU || gpuCapacity.IsZero() {
+	// 检查节点是否有GPU资源，MIG和vGPU节点的整卡可能已全部切分而不再上报nvidia.com/gpu
+	sharingMode := nodeGPUSharing(node)
+	gpuCapacity := node.Status.Capacity[GPUResourceName]
+	if gpuCapacity.IsZero() && sharingMode != GPUSharingMIG && sharingMode != GPUSharingVGPU {
 		return nil
 	}
 
-	gpuAllocatable := node.Status.Allocatable["nvidia.com/gpu"]
+	gpuAllocatable := node.Status.Allocatable[GPUResourceName]
 	totalGPUs := int32(gpuCapacity.Value())
 	availableGPUs := int32(gpuAllocatable.Value())
 	allocatedGPUs := totalGPUs - availableGPUs
+	if sharingMode == GPUSharingMIG || sharingMode == GPUSharingVGPU {
+		// 切分后的节点按物理卡数统计，可分配单元见MIGDevices和VGPUAvailable
+		if count, err := strconv.Atoi(node.Labels[GPUCountLabel]); err == nil && int32(count) > totalGPUs {
+			totalGPUs = int32(count)
+		}
+	}
 
 	gpuInfo := &GPUResourceInfo{
 		NodeName:      node.Name,
@@ -134,10 +173,23 @@ func (gm *GPUManager) extractGPUInfo(node *corev1.Node) *GPUResourceInfo {
 		AllocatedGPUs: allocatedGPUs,
 		Labels:        node.Labels,
 		Status:        gm.getNodeGPUStatus(node),
+		Unschedulable: node.Spec.Unschedulable,
+		CordonReason:  node.Annotations[GPUCordonAnnotation],
+		SharingMode:   sharingMode,
+		MIGDevices:    migCapacity(node.Status.Allocatable),
+	}
+	if replicas, err := strconv.Atoi(node.Labels[GPUReplicasLabel]); err == nil && replicas > 1 {
+		gpuInfo.GPUReplicas = int32(replicas)
+	}
+	if sharingMode == GPUSharingVGPU {
+		vgpuNumber := node.Status.Allocatable[VGPUNumberResourceName]
+		vgpuMemory := node.Status.Allocatable[VGPUMemoryResourceName]
+		gpuInfo.VGPUAvailable = int32(vgpuNumber.Value())
+		gpuInfo.VGPUMemoryMB = vgpuMemory.Value()
 	}
 
 	// 提取GPU类型
-	if gpuType, exists := node.Labels["nvidia.com/gpu.product"]; exists {
+	if gpuType, exists := node.Labels[GPUProductLabel]; exists {
 		gpuInfo.GPUType = gpuType
 	} else if gpuType, exists := node.Labels["gpu.nvidia.com/class"]; exists {
 		gpuInfo.GPUType = gpuType
@@ -146,7 +198,7 @@ func (gm *GPUManager) extractGPUInfo(node *corev1.Node) *GPUResourceInfo {
 	}
 
 	// 提取GPU内存信息
-	if gpuMemory, exists := node.Labels["nvidia.com/gpu.memory"]; exists {
+	if gpuMemory, exists := node.Labels[GPUMemoryLabel]; exists {
 		gpuInfo.MemoryTotal = gpuMemory
 	}
 
@@ -156,10 +208,17 @@ func (gm *GPUManager) extractGPUInfo(node *corev1.Node) *GPUResourceInfo {
 		gpuInfo.Taints = append(gpuInfo.Taints, taintStr)
 	}
 
-	// TODO: 从监控系统获取实时GPU使用率、功耗、温度等信息
 	gpuInfo.GPUUtilization = gm.getNodeGPUUtilization(node.Name)
 	gpuInfo.PowerDraw = "N/A"
 	gpuInfo.Temperature = "N/A"
+	if gm.telemetry != nil {
+		if stats, ok := gm.telemetry.NodeGPUStats(node.Name); ok {
+			gpuInfo.MemoryUsed = fmt.Sprintf("%.0f", stats.MemoryUsedMB)
+			gpuInfo.MemoryFree = fmt.Sprintf("%.0f", stats.MemoryFreeMB)
+			gpuInfo.PowerDraw = fmt.Sprintf("%.1fW", stats.PowerDrawW)
+			gpuInfo.Temperature = fmt.Sprintf("%.0f℃", stats.MaxTemperatureC)
+		}
+	}
 
 	return gpuInfo
 }
@@ -178,15 +237,28 @@ func (gm *GPUManager) getNodeGPUStatus(node *corev1.Node) string {
 	return "Unknown"
 }
 
-// getNodeGPUUtilization 获取节点GPU使用率
+// getNodeGPUUtilization 获取节点GPU平均使用率，没有监控数据时返回0
 func (gm *GPUManager) getNodeGPUUtilization(nodeName string) float64 {
-	// TODO: 集成Prometheus或其他监控系统获取实时GPU使用率
-	// 这里返回模拟数据
-	return 0.0
+	if gm.telemetry == nil {
+		return 0.0
+	}
+	stats, ok := gm.telemetry.NodeGPUStats(nodeName)
+	if !ok {
+		return 0.0
+	}
+	return stats.Utilization
 }
 
 // AllocateGPUs 分配GPU资源
 func (gm *GPUManager) AllocateGPUs(req *GPUAllocationRequest) (*GPUAllocationResult, error) {
+	if err := req.Sharing.Validate(); err != nil {
+		return &GPUAllocationResult{
+			Success: false,
+			Message: err.Error(),
+			Reason:  "共享配置错误",
+		}, nil
+	}
+
 	// 获取可用的GPU资源
 	gpuResources, err := gm.GetClusterGPUResources()
 	if err != nil {
@@ -202,6 +274,7 @@ func (gm *GPUManager) AllocateGPUs(req *GPUAllocationRequest) (*GPUAllocationRes
 			Suggestions: []string{
 				"检查节点选择器和容忍度配置",
 				"确认集群中有足够的可用GPU资源",
+				"确认集群中有支持所选共享方式的节点",
 				"考虑调整资源请求要求",
 			},
 		}, nil
@@ -229,6 +302,11 @@ func (gm *GPUManager) AllocateGPUs(req *GPUAllocationRequest) (*GPUAllocationRes
 		}, nil
 	}
 
+	resourceName := string(req.Sharing.ResourceName())
+	for i := range allocation {
+		allocation[i].Resource = resourceName
+	}
+
 	return &GPUAllocationResult{
 		Success:       true,
 		AllocatedGPUs: allocation,
@@ -246,10 +324,12 @@ func (gm *GPUManager) filterAvailableNodes(gpuResources []GPUResourceInfo, req *
 			continue
 		}
 
-		// 检查可用GPU数量
-		if gpuInfo.AvailableGPUs <= 0 {
+		// 检查共享方式一致且有可用的GPU单元，后续按单元数执行分配策略
+		units := availableUnits(&gpuInfo, req.Sharing)
+		if units <= 0 {
 			continue
 		}
+		gpuInfo.AvailableGPUs = units
 
 		// 检查节点选择器
 		if !gm.matchesNodeSelector(gpuInfo.Labels, req.NodeSelector) {
@@ -677,29 +757,46 @@ func (gm *GPUManager) MonitorGPUHealth() (*GPUHealthReport, error) {
 			NodeName:    gpuInfo.NodeName,
 			Status:      gpuInfo.Status,
 			TotalGPUs:   gpuInfo.TotalGPUs,
-			HealthyGPUs: gpuInfo.TotalGPUs, // 简化：假设所有GPU都是健康的
+			HealthyGPUs: gpuInfo.TotalGPUs,
 			Issues:      make([]string, 0),
 		}
-
-		// 检查节点健康状况
-		if gpuInfo.Status == "Ready" {
-			healthyNodes++
-		} else {
-			issue := HealthIssue{
-				Severity:   "high",
+		addIssue := func(severity, component, message, suggestion string) {
+			healthReport.Issues = append(healthReport.Issues, HealthIssue{
+				Severity:   severity,
 				NodeName:   gpuInfo.NodeName,
-				Component:  "node",
-				Issue:      fmt.Sprintf("节点状态异常: %s", gpuInfo.Status),
+				Component:  component,
+				Issue:      message,
 				Timestamp:  time.Now().Format("2006-01-02 15:04:05"),
-				Suggestion: "检查节点状态和kubelet日志",
-			}
-			healthReport.Issues = append(healthReport.Issues, issue)
-			nodeHealth.Issues = append(nodeHealth.Issues, issue.Issue)
+				Suggestion: suggestion,
+			})
+			nodeHealth.Issues = append(nodeHealth.Issues, message)
 			totalIssues++
 		}
 
-		// TODO: 检查GPU温度、功耗等健康指标
+		// 检查节点健康状况
+		if gpuInfo.Status != "Ready" {
+			addIssue("high", "node", fmt.Sprintf("节点状态异常: %s", gpuInfo.Status), "检查节点状态和kubelet日志")
+		}
+		if gpuInfo.CordonReason != "" {
+			addIssue("high", "gpu", fmt.Sprintf("节点因GPU故障已隔离: %s", gpuInfo.CordonReason), "维修完成后解除节点隔离")
+		}
+
+		// 检查DCGM上报的GPU故障
+		if gm.telemetry != nil {
+			if stats, ok := gm.telemetry.NodeGPUStats(gpuInfo.NodeName); ok {
+				for _, fault := range stats.Faults {
+					addIssue("critical", "gpu", fault, "检查dmesg中的XID日志，必要时重启节点或更换GPU")
+				}
+				nodeHealth.HealthyGPUs -= int32(len(stats.Faults))
+				if nodeHealth.HealthyGPUs < 0 {
+					nodeHealth.HealthyGPUs = 0
+				}
+			}
+		}
 
+		if len(nodeHealth.Issues) == 0 {
+			healthyNodes++
+		}
 		healthReport.NodeHealth = append(healthReport.NodeHealth, nodeHealth)
 	}
 
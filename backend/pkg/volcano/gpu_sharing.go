@@ -0,0 +1,207 @@
+package volcano
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// GPU扩展资源名称
+const (
+	GPUResourceName corev1.ResourceName = "nvidia.com/gpu"
+	// MIGResourcePrefix MIG mixed策略下device plugin按规格上报资源，如nvidia.com/mig-1g.10gb
+	MIGResourcePrefix = "nvidia.com/mig-"
+	// Volcano vGPU device plugin上报的资源，vgpu-memory单位为MB，vgpu-cores为单卡算力百分比
+	VGPUNumberResourceName corev1.ResourceName = "volcano.sh/vgpu-number"
+	VGPUMemoryResourceName corev1.ResourceName = "volcano.sh/vgpu-memory"
+	VGPUCoresResourceName  corev1.ResourceName = "volcano.sh/vgpu-cores"
+)
+
+// GPU共享相关的GFD节点标签
+const (
+	GPUReplicasLabel        = "nvidia.com/gpu.replicas"         // 时间片共享时每张卡的副本数
+	GPUSharingStrategyLabel = "nvidia.com/gpu.sharing-strategy" // none, time-slicing, mps
+	MIGStrategyLabel        = "nvidia.com/mig.strategy"         // none, single, mixed
+
+	timeSlicingStrategy = "time-slicing"
+)
+
+// GPU共享方式
+const (
+	GPUSharingExclusive   = "exclusive"    // 独占整卡
+	GPUSharingMIG         = "mig"          // MIG切分的GPU实例
+	GPUSharingTimeSlicing = "time_slicing" // device plugin时间片共享的副本
+	GPUSharingVGPU        = "vgpu"         // Volcano vGPU按显存和算力切分
+)
+
+// migProfilePattern MIG规格，如1g.10gb、3g.40gb、1g.10gb+me
+var migProfilePattern = regexp.MustCompile(`^[1-7]g\.[0-9]+gb(\+me)?$`)
+
+// GPUSharing GPU共享配置，零值表示独占整卡
+type GPUSharing struct {
+	Mode       string `json:"mode,omitempty"`
+	MIGProfile string `json:"migProfile,omitempty"` // mig模式的实例规格
+	MemoryMB   int64  `json:"memoryMB,omitempty"`   // vgpu模式每个vGPU的显存
+	Cores      int64  `json:"cores,omitempty"`      // vgpu模式每个vGPU的算力百分比，0表示不限制
+}
+
+// ModeOrDefault 返回共享方式，未设置时为exclusive
+func (s GPUSharing) ModeOrDefault() string {
+	if s.Mode == "" {
+		return GPUSharingExclusive
+	}
+	return s.Mode
+}
+
+// Shared 是否与其他作业共享物理GPU
+func (s GPUSharing) Shared() bool {
+	return s.ModeOrDefault() != GPUSharingExclusive
+}
+
+// Validate 校验共享方式与参数是否匹配
+func (s GPUSharing) Validate() error {
+	switch s.ModeOrDefault() {
+	case GPUSharingExclusive, GPUSharingTimeSlicing:
+		if s.MIGProfile != "" || s.MemoryMB != 0 || s.Cores != 0 {
+			return fmt.Errorf("%s模式不支持指定MIG规格或vGPU显存", s.ModeOrDefault())
+		}
+	case GPUSharingMIG:
+		if !migProfilePattern.MatchString(s.MIGProfile) {
+			return fmt.Errorf("无效的MIG规格: %q，应为如1g.10gb的格式", s.MIGProfile)
+		}
+		if s.MemoryMB != 0 || s.Cores != 0 {
+			return fmt.Errorf("mig模式不支持指定vGPU显存")
+		}
+	case GPUSharingVGPU:
+		if s.MemoryMB <= 0 {
+			return fmt.Errorf("vgpu模式必须指定每个vGPU的显存")
+		}
+		if s.Cores < 0 || s.Cores > 100 {
+			return fmt.Errorf("vGPU算力百分比必须在0-100之间")
+		}
+		if s.MIGProfile != "" {
+			return fmt.Errorf("vgpu模式不支持指定MIG规格")
+		}
+	default:
+		return fmt.Errorf("不支持的GPU共享方式: %s", s.Mode)
+	}
+	return nil
+}
+
+// ResourceName 每个GPU单元对应的扩展资源
+func (s GPUSharing) ResourceName() corev1.ResourceName {
+	switch s.ModeOrDefault() {
+	case GPUSharingMIG:
+		return corev1.ResourceName(MIGResourcePrefix + s.MIGProfile)
+	case GPUSharingVGPU:
+		return VGPUNumberResourceName
+	default:
+		return GPUResourceName
+	}
+}
+
+// ResourceList 申请count个GPU单元所需的扩展资源，vGPU的显存和算力按单个vGPU计
+func (s GPUSharing) ResourceList(count int64) corev1.ResourceList {
+	resources := corev1.ResourceList{
+		s.ResourceName(): resource.MustParse(strconv.FormatInt(count, 10)),
+	}
+	if s.ModeOrDefault() == GPUSharingVGPU {
+		resources[VGPUMemoryResourceName] = resource.MustParse(strconv.FormatInt(s.MemoryMB, 10))
+		if s.Cores > 0 {
+			resources[VGPUCoresResourceName] = resource.MustParse(strconv.FormatInt(s.Cores, 10))
+		}
+	}
+	return resources
+}
+
+// NodeSelector 时间片副本与整卡使用同一资源名，需要按GFD标签选择开启时间片的节点
+func (s GPUSharing) NodeSelector() map[string]string {
+	if s.ModeOrDefault() != GPUSharingTimeSlicing {
+		return nil
+	}
+	return map[string]string{GPUSharingStrategyLabel: timeSlicingStrategy}
+}
+
+// mergeNodeSelector 合并作业节点选择器和共享方式要求的标签，不修改原map
+func mergeNodeSelector(nodeSelector map[string]string, sharing GPUSharing) map[string]string {
+	required := sharing.NodeSelector()
+	if len(required) == 0 {
+		return nodeSelector
+	}
+	merged := make(map[string]string, len(nodeSelector)+len(required))
+	for key, value := range nodeSelector {
+		merged[key] = value
+	}
+	for key, value := range required {
+		merged[key] = value
+	}
+	return merged
+}
+
+// nodeGPUSharing 根据节点上报的资源和GFD标签识别GPU共享方式
+func nodeGPUSharing(node *corev1.Node) string {
+	if quantity, ok := node.Status.Capacity[VGPUNumberResourceName]; ok && !quantity.IsZero() {
+		return GPUSharingVGPU
+	}
+	if len(migCapacity(node.Status.Capacity)) > 0 {
+		return GPUSharingMIG
+	}
+	if node.Labels[GPUSharingStrategyLabel] == timeSlicingStrategy {
+		return GPUSharingTimeSlicing
+	}
+	if replicas, err := strconv.Atoi(node.Labels[GPUReplicasLabel]); err == nil && replicas > 1 {
+		return GPUSharingTimeSlicing
+	}
+	// 旧版GFD不写共享标签，nvidia.com/gpu容量超过物理卡数说明开启了时间片
+	capacity := node.Status.Capacity[GPUResourceName]
+	if count, err := strconv.Atoi(node.Labels[GPUCountLabel]); err == nil && count > 0 && capacity.Value() > int64(count) {
+		return GPUSharingTimeSlicing
+	}
+	return GPUSharingExclusive
+}
+
+// migCapacity 按MIG规格统计资源数量，如{"1g.10gb": 7}
+func migCapacity(resources corev1.ResourceList) map[string]int32 {
+	var profiles map[string]int32
+	for name, quantity := range resources {
+		profile := strings.TrimPrefix(string(name), MIGResourcePrefix)
+		if profile == string(name) || quantity.IsZero() {
+			continue
+		}
+		if profiles == nil {
+			profiles = make(map[string]int32)
+		}
+		profiles[profile] = int32(quantity.Value())
+	}
+	return profiles
+}
+
+// availableUnits 节点可满足请求的GPU单元数，共享方式与节点不一致时为0
+func availableUnits(gpuInfo *GPUResourceInfo, sharing GPUSharing) int32 {
+	mode := sharing.ModeOrDefault()
+	if mode == GPUSharingExclusive && gpuInfo.SharingMode == GPUSharingMIG {
+		// MIG mixed策略节点上未切分的整卡仍以nvidia.com/gpu上报，可以独占
+		return gpuInfo.AvailableGPUs
+	}
+	if gpuInfo.SharingMode != mode {
+		return 0
+	}
+	switch mode {
+	case GPUSharingMIG:
+		return gpuInfo.MIGDevices[sharing.MIGProfile]
+	case GPUSharingVGPU:
+		units := gpuInfo.VGPUAvailable
+		if sharing.MemoryMB > 0 {
+			if byMemory := int32(gpuInfo.VGPUMemoryMB / sharing.MemoryMB); byMemory < units {
+				units = byMemory
+			}
+		}
+		return units
+	default:
+		return gpuInfo.AvailableGPUs
+	}
+}
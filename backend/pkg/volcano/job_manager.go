@@ -3,6 +3,7 @@ package volcano
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -38,7 +39,7 @@ func (jm *JobManager) CreateTrainingJob(spec *TrainingJobSpec) (*vcjob.Job, erro
 	// 创建作业
 	job, err := jm.client.CreateJob(volcanoJobSpec)
 	if err != nil {
-		return nil, fmt.Errorf("创建Volcano训练作业失败: %v", err)
+		return nil, fmt.Errorf("创建Volcano训练作业失败: %w", err)
 	}
 
 	return job, nil
@@ -61,6 +62,7 @@ type TrainingJobSpec struct {
 	MemoryRequest  string
 	GPUCount       int64
 	GPUType        string
+	GPUSharing     GPUSharing // 零值独占整卡，共享时GPUCount为MIG实例数、时间片副本数或vGPU数
 	StorageRequest string
 
 	// 分布式训练配置
@@ -89,6 +91,9 @@ type TrainingJobSpec struct {
 	Secrets      map[string]string
 	VolumeMounts []VolumeMountSpec
 
+	// 训练容器启动前运行的初始化容器，如下载恢复用的检查点
+	InitContainers []Container
+
 	// 监控配置
 	EnableTensorboard bool
 	EnableProfiling   bool
@@ -97,6 +102,9 @@ type TrainingJobSpec struct {
 	// 元数据
 	Labels      map[string]string
 	Annotations map[string]string
+
+	// 服务账号，为空时使用命名空间默认账号
+	ServiceAccount string
 }
 
 // VolumeMountSpec 存储卷挂载规格
@@ -132,9 +140,17 @@ func (jm *JobManager) validateJobSpec(spec *TrainingJobSpec) error {
 	if spec.MinAvailable <= 0 {
 		return fmt.Errorf("最小可用实例数必须大于0")
 	}
+	if err := spec.GPUSharing.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
+// BuildVolcanoJobSpec 构建提交给Volcano的作业规格，不访问集群
+func (jm *JobManager) BuildVolcanoJobSpec(spec *TrainingJobSpec) *JobSpec {
+	return jm.buildVolcanoJobSpec(spec)
+}
+
 // buildVolcanoJobSpec 构建Volcano作业规格
 func (jm *JobManager) buildVolcanoJobSpec(spec *TrainingJobSpec) *JobSpec {
 	volcanoSpec := &JobSpec{
@@ -183,8 +199,8 @@ func (jm *JobManager) buildTasks(spec *TrainingJobSpec) []TaskSpec {
 		tasks = append(tasks, workerTask)
 	}
 
-	// 构建PS任务（如果是TensorFlow分布式训练）
-	if spec.PSReplicas > 0 && strings.ToLower(spec.Framework) == "tensorflow" {
+	// 构建PS任务（TensorFlow和PaddlePaddle参数服务器模式）
+	if hasPSTask(spec) {
 		psTask := jm.buildPSTask(spec)
 		tasks = append(tasks, psTask)
 	}
@@ -204,12 +220,11 @@ func (jm *JobManager) buildMasterTask(spec *TrainingJobSpec) TaskSpec {
 			},
 			Spec:         jm.buildPodSpec(spec, "master"),
 			Affinity:     spec.Affinity,
-			NodeSelector: spec.NodeSelector,
+			NodeSelector: mergeNodeSelector(spec.NodeSelector, spec.GPUSharing),
 			Tolerations:  spec.Tolerations,
 		},
-		Policies:     jm.buildTaskPolicies("master", spec.JobType),
-		MinAvailable: &spec.MinAvailable,
-		MaxRetry:     &spec.MaxRetry,
+		Policies: jm.buildTaskPolicies("master", spec.JobType),
+		MaxRetry: &spec.MaxRetry,
 	}
 }
 
@@ -225,7 +240,7 @@ func (jm *JobManager) buildWorkerTask(spec *TrainingJobSpec) TaskSpec {
 			},
 			Spec:         jm.buildPodSpec(spec, "worker"),
 			Affinity:     spec.Affinity,
-			NodeSelector: spec.NodeSelector,
+			NodeSelector: mergeNodeSelector(spec.NodeSelector, spec.GPUSharing),
 			Tolerations:  spec.Tolerations,
 		},
 		Policies: jm.buildTaskPolicies("worker", spec.JobType),
@@ -244,7 +259,7 @@ func (jm *JobManager) buildPSTask(spec *TrainingJobSpec) TaskSpec {
 			},
 			Spec:         jm.buildPodSpec(spec, "ps"),
 			Affinity:     spec.Affinity,
-			NodeSelector: spec.NodeSelector,
+			NodeSelector: mergeNodeSelector(spec.NodeSelector, spec.GPUSharing),
 			Tolerations:  spec.Tolerations,
 		},
 		Policies: jm.buildTaskPolicies("ps", spec.JobType),
@@ -278,14 +293,8 @@ func (jm *JobManager) buildPodSpec(spec *TrainingJobSpec, taskType string) PodSp
 		Containers: []Container{
 			jm.buildMainContainer(spec, taskType),
 		},
-		ServiceAccount: fmt.Sprintf("%s-service-account", spec.Name),
-	}
-
-	// 添加初始化容器（如果需要）
-	if jm.needInitContainer(spec) {
-		podSpec.InitContainers = []Container{
-			jm.buildInitContainer(spec),
-		}
+		InitContainers: spec.InitContainers,
+		ServiceAccount: spec.ServiceAccount,
 	}
 
 	// 构建存储卷
@@ -301,16 +310,19 @@ func (jm *JobManager) buildPodSpec(spec *TrainingJobSpec, taskType string) PodSp
 
 // buildMainContainer 构建主要容器
 func (jm *JobManager) buildMainContainer(spec *TrainingJobSpec, taskType string) Container {
+	command, args := jm.buildLaunchCommand(spec, taskType)
 	container := Container{
 		Name:            "training-container",
 		Image:           spec.Image,
-		Command:         spec.Command,
-		Args:            jm.buildContainerArgs(spec, taskType),
+		Command:         command,
+		Args:            args,
 		WorkingDir:      spec.WorkingDir,
 		ImagePullPolicy: corev1.PullIfNotPresent,
 		Resources:       jm.buildResourceRequirements(spec),
 		Env:             jm.buildEnvironmentVariables(spec, taskType),
 		VolumeMounts:    jm.buildVolumeMounts(spec),
+		// 失败时以日志末尾作为终止消息，用于识别NCCL超时等失败原因
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 	}
 
 	// 添加健康检查（如果需要）
@@ -348,11 +360,12 @@ func (jm *JobManager) buildResourceRequirements(spec *TrainingJobSpec) corev1.Re
 		}
 	}
 
-	// GPU资源
+	// GPU资源，按共享方式申请整卡、MIG实例或vGPU
 	if spec.GPUCount > 0 {
-		gpuQuantity := resource.MustParse(fmt.Sprintf("%d", spec.GPUCount))
-		resources.Requests["nvidia.com/gpu"] = gpuQuantity
-		resources.Limits["nvidia.com/gpu"] = gpuQuantity
+		for name, quantity := range spec.GPUSharing.ResourceList(spec.GPUCount) {
+			resources.Requests[name] = quantity
+			resources.Limits[name] = quantity
+		}
 	}
 
 	return resources
@@ -397,76 +410,36 @@ func (jm *JobManager) buildEnvironmentVariables(spec *TrainingJobSpec, taskType
 	// 添加分布式训练相关环境变量
 	envVars = append(envVars, jm.buildDistributedTrainingEnvVars(spec, taskType)...)
 
-	// 添加用户自定义环境变量
-	for key, value := range spec.EnvVars {
+	// 添加用户自定义环境变量，按名称排序保证每次生成的规格一致
+	keys := make([]string, 0, len(spec.EnvVars))
+	for key := range spec.EnvVars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
 		envVars = append(envVars, corev1.EnvVar{
 			Name:  key,
-			Value: value,
+			Value: spec.EnvVars[key],
 		})
 	}
 
 	return envVars
 }
 
-// buildDistributedTrainingEnvVars 构建分布式训练环境变量
-func (jm *JobManager) buildDistributedTrainingEnvVars(spec *TrainingJobSpec, taskType string) []corev1.EnvVar {
-	var envVars []corev1.EnvVar
-
-	switch strings.ToLower(spec.Framework) {
-	case "pytorch":
-		envVars = append(envVars,
-			corev1.EnvVar{Name: "MASTER_ADDR", Value: fmt.Sprintf("%s-master-0.%s-master", spec.Name, spec.Name)},
-			corev1.EnvVar{Name: "MASTER_PORT", Value: "23456"},
-			corev1.EnvVar{Name: "WORLD_SIZE", Value: fmt.Sprintf("%d", spec.WorkerReplicas)},
-			corev1.EnvVar{Name: "NCCL_DEBUG", Value: "INFO"},
-		)
-	case "tensorflow":
-		envVars = append(envVars,
-			corev1.EnvVar{Name: "TF_CONFIG", Value: jm.buildTFConfig(spec, taskType)},
-		)
-	case "mpi":
-		envVars = append(envVars,
-			corev1.EnvVar{Name: "OMPI_ALLOW_RUN_AS_ROOT", Value: "1"},
-			corev1.EnvVar{Name: "OMPI_ALLOW_RUN_AS_ROOT_CONFIRM", Value: "1"},
-		)
-	}
-
-	return envVars
-}
-
-// buildTFConfig 构建TensorFlow配置
-func (jm *JobManager) buildTFConfig(spec *TrainingJobSpec, taskType string) string {
-	// 简化的TF_CONFIG构建逻辑
-	// 实际实现中需要根据具体需求构建完整的TF_CONFIG JSON
-	return fmt.Sprintf(`{"cluster": {"worker": ["%s-worker:2222"], "ps": ["%s-ps:2222"]}, "task": {"type": "%s", "index": 0}}`,
-		spec.Name, spec.Name, taskType)
-}
-
 // buildContainerArgs 构建容器参数
 func (jm *JobManager) buildContainerArgs(spec *TrainingJobSpec, taskType string) []string {
 	args := make([]string, len(spec.Args))
 	copy(args, spec.Args)
 
 	// 根据框架类型添加特定参数
-	switch strings.ToLower(spec.Framework) {
-	case "pytorch":
-		args = jm.addPyTorchArgs(args, spec, taskType)
-	case "tensorflow":
+	switch launchMode(spec) {
+	case launchTensorFlow:
 		args = jm.addTensorFlowArgs(args, spec, taskType)
 	}
 
 	return args
 }
 
-// addPyTorchArgs 添加PyTorch特定参数
-func (jm *JobManager) addPyTorchArgs(args []string, spec *TrainingJobSpec, taskType string) []string {
-	if taskType == "master" {
-		args = append(args, "--rank=0")
-		args = append(args, fmt.Sprintf("--world-size=%d", spec.WorkerReplicas))
-	}
-	return args
-}
-
 // addTensorFlowArgs 添加TensorFlow特定参数
 func (jm *JobManager) addTensorFlowArgs(args []string, spec *TrainingJobSpec, taskType string) []string {
 	args = append(args, fmt.Sprintf("--task-type=%s", taskType))
@@ -548,19 +521,25 @@ func (jm *JobManager) buildContainerPorts(spec *TrainingJobSpec, taskType string
 	var ports []corev1.ContainerPort
 
 	// 添加框架特定端口
-	switch strings.ToLower(spec.Framework) {
-	case "pytorch":
+	switch launchMode(spec) {
+	case launchPyTorch:
 		if taskType == "master" || taskType == "worker" {
 			ports = append(ports, corev1.ContainerPort{
 				Name:          "pytorch-port",
-				ContainerPort: 23456,
+				ContainerPort: pytorchPort,
 				Protocol:      corev1.ProtocolTCP,
 			})
 		}
-	case "tensorflow":
+	case launchTensorFlow:
 		ports = append(ports, corev1.ContainerPort{
 			Name:          "tf-port",
-			ContainerPort: 2222,
+			ContainerPort: tensorflowPort,
+			Protocol:      corev1.ProtocolTCP,
+		})
+	case launchPaddle:
+		ports = append(ports, corev1.ContainerPort{
+			Name:          "paddle-port",
+			ContainerPort: paddlePort,
 			Protocol:      corev1.ProtocolTCP,
 		})
 	}
@@ -579,14 +558,15 @@ func (jm *JobManager) buildContainerPorts(spec *TrainingJobSpec, taskType string
 
 // buildTaskPolicies 构建任务策略
 func (jm *JobManager) buildTaskPolicies(taskType, jobType string) []LifecyclePolicy {
+	// Pod失败或被驱逐时终止作业，由平台按auto_restart和max_retry_count决定是否重试
 	policies := []LifecyclePolicy{
 		{
 			Event:  "PodFailed",
-			Action: "RestartJob",
+			Action: "TerminateJob",
 		},
 		{
 			Event:  "PodEvicted",
-			Action: "RestartJob",
+			Action: "TerminateJob",
 		},
 	}
 
@@ -603,17 +583,11 @@ func (jm *JobManager) buildTaskPolicies(taskType, jobType string) []LifecyclePol
 
 // buildPluginsForJobType 为不同作业类型构建插件配置
 func (jm *JobManager) buildPluginsForJobType(jobType string) map[string][]string {
+	// 仅包含作业插件，gang/drf等属于调度器插件，写入作业会被准入控制拒绝
 	basePlugins := map[string][]string{
-		"env":         {},
-		"svc":         {},
-		"ssh":         {},
-		"gang":        {},
-		"proportion":  {},
-		"drf":         {},
-		"predicates":  {},
-		"nodeorder":   {},
-		"binpack":     {},
-		"conformance": {},
+		"env": {},
+		"svc": {},
+		"ssh": {},
 	}
 
 	switch strings.ToLower(jobType) {
@@ -628,7 +602,10 @@ func (jm *JobManager) buildPluginsForJobType(jobType string) map[string][]string
 
 // 健康检查相关方法
 func (jm *JobManager) needHealthCheck(spec *TrainingJobSpec, taskType string) bool {
-	// 根据框架和任务类型决定是否需要健康检查
+	// 根据框架和任务类型决定是否需要健康检查，未暴露监控端口时无法探测
+	if spec.MetricsPort <= 0 {
+		return false
+	}
 	return taskType == "master" || taskType == "worker"
 }
 
@@ -661,20 +638,6 @@ func (jm *JobManager) buildLivenessProbe(spec *TrainingJobSpec) *corev1.Probe {
 	}
 }
 
-// 初始化容器相关方法
-func (jm *JobManager) needInitContainer(spec *TrainingJobSpec) bool {
-	// 根据作业类型决定是否需要初始化容器
-	return strings.ToLower(spec.JobType) == "mpi"
-}
-
-func (jm *JobManager) buildInitContainer(spec *TrainingJobSpec) Container {
-	return Container{
-		Name:    "init-container",
-		Image:   fmt.Sprintf("%s-init:latest", spec.Framework),
-		Command: []string{"sh", "-c", "echo 'Initializing...' && sleep 10"},
-	}
-}
-
 // 监控容器相关方法
 func (jm *JobManager) buildProfilingContainer(spec *TrainingJobSpec) Container {
 	return Container{
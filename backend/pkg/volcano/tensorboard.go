@@ -0,0 +1,176 @@
+package volcano
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// TensorboardPort TensorBoard容器和Service的端口
+	TensorboardPort = 6006
+
+	// TensorboardComponentLabelKey 平台创建的TensorBoard资源上的组件标签
+	TensorboardComponentLabelKey   = "volctrain.io/component"
+	TensorboardComponentLabelValue = "tensorboard"
+)
+
+// TensorboardSpec 作业TensorBoard规格
+type TensorboardSpec struct {
+	Name       string // Deployment和Service名
+	Namespace  string
+	Image      string
+	LogDir     string // 事件目录，与训练容器中的路径一致
+	PathPrefix string // 反向代理的路径前缀，TensorBoard生成的链接以此为前缀
+
+	// 与训练作业相同的存储卷，以只读方式挂载
+	VolumeMounts []VolumeMountSpec
+
+	Labels map[string]string
+}
+
+// EnsureTensorboard 创建作业的TensorBoard Deployment和Service，已存在时保持不变
+func (jm *JobManager) EnsureTensorboard(spec *TensorboardSpec) error {
+	if spec.Name == "" || spec.Image == "" || spec.LogDir == "" {
+		return fmt.Errorf("TensorBoard名称、镜像和事件目录不能为空")
+	}
+	namespace := jm.client.getNamespace(spec.Namespace)
+	kube := jm.client.KubeClientset()
+
+	labels := map[string]string{TensorboardComponentLabelKey: TensorboardComponentLabelValue}
+	for k, v := range spec.Labels {
+		labels[k] = v
+	}
+	selector := map[string]string{TensorboardComponentLabelKey: TensorboardComponentLabelValue, "app": spec.Name}
+	podLabels := map[string]string{"app": spec.Name}
+	for k, v := range labels {
+		podLabels[k] = v
+	}
+
+	mounts := make([]VolumeMountSpec, len(spec.VolumeMounts))
+	for i, vm := range spec.VolumeMounts {
+		vm.ReadOnly = true
+		mounts[i] = vm
+	}
+	volumes := &TrainingJobSpec{VolumeMounts: mounts}
+
+	args := []string{"--logdir=" + spec.LogDir, "--bind_all", fmt.Sprintf("--port=%d", TensorboardPort)}
+	if spec.PathPrefix != "" {
+		args = append(args, "--path_prefix="+spec.PathPrefix)
+	}
+	replicas := int32(1)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: spec.Name, Namespace: namespace, Labels: labels},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: selector},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:    "tensorboard",
+						Image:   spec.Image,
+						Command: []string{"tensorboard"},
+						Args:    args,
+						Ports:   []corev1.ContainerPort{{Name: "http", ContainerPort: TensorboardPort}},
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("100m"),
+								corev1.ResourceMemory: resource.MustParse("256Mi"),
+							},
+							Limits: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("1"),
+								corev1.ResourceMemory: resource.MustParse("2Gi"),
+							},
+						},
+						ReadinessProbe: &corev1.Probe{
+							ProbeHandler: corev1.ProbeHandler{
+								TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(TensorboardPort)},
+							},
+							PeriodSeconds: 5,
+						},
+						VolumeMounts: jm.buildVolumeMounts(volumes),
+					}},
+					Volumes: jm.buildVolumes(volumes),
+				},
+			},
+		},
+	}
+	_, err := kube.AppsV1().Deployments(namespace).Create(context.TODO(), deployment, metav1.CreateOptions{})
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("创建TensorBoard Deployment失败: %w", err)
+	}
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: spec.Name, Namespace: namespace, Labels: labels},
+		Spec: corev1.ServiceSpec{
+			Selector: selector,
+			Ports: []corev1.ServicePort{{
+				Name:       "http",
+				Port:       TensorboardPort,
+				TargetPort: intstr.FromInt(TensorboardPort),
+			}},
+		},
+	}
+	_, err = kube.CoreV1().Services(namespace).Create(context.TODO(), service, metav1.CreateOptions{})
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("创建TensorBoard Service失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteTensorboard 删除作业的TensorBoard Deployment和Service，不存在时忽略
+func (jm *JobManager) DeleteTensorboard(namespace, name string) error {
+	namespace = jm.client.getNamespace(namespace)
+	kube := jm.client.KubeClientset()
+
+	err := kube.CoreV1().Services(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("删除TensorBoard Service失败: %w", err)
+	}
+	policy := metav1.DeletePropagationBackground
+	err = kube.AppsV1().Deployments(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{PropagationPolicy: &policy})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("删除TensorBoard Deployment失败: %w", err)
+	}
+	return nil
+}
+
+// ListTensorboards 列出命名空间下平台创建的TensorBoard Deployment，namespace为空时列出全部命名空间
+func (jm *JobManager) ListTensorboards(namespace string) ([]appsv1.Deployment, error) {
+	list, err := jm.client.KubeClientset().AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: TensorboardComponentLabelKey + "=" + TensorboardComponentLabelValue,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查询TensorBoard列表失败: %w", err)
+	}
+	return list.Items, nil
+}
+
+// GetTensorboardStatus 查询TensorBoard是否存在以及是否有就绪的副本
+func (jm *JobManager) GetTensorboardStatus(namespace, name string) (exists, ready bool, err error) {
+	deployment, err := jm.client.KubeClientset().AppsV1().Deployments(jm.client.getNamespace(namespace)).Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, fmt.Errorf("查询TensorBoard状态失败: %w", err)
+	}
+	return true, deployment.Status.ReadyReplicas > 0, nil
+}
+
+// TensorboardURL 返回集群内访问TensorBoard Service的地址
+func (jm *JobManager) TensorboardURL(namespace, name string) string {
+	return fmt.Sprintf("http://%s.%s.svc:%d", name, jm.client.getNamespace(namespace), TensorboardPort)
+}
+
+// Namespace 返回实际使用的命名空间，为空时使用客户端默认命名空间
+func (jm *JobManager) Namespace(namespace string) string {
+	return jm.client.getNamespace(namespace)
+}
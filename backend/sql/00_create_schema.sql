@@ -72,6 +72,9 @@ SOURCE 09_monitoring.sql;
 -- 10. K8s关联关系模块表
 SOURCE 10_k8s_relations.sql;
 
+-- 11. GPU资源发现、分配和监控列
+SOURCE 11_gpu_inventory.sql;
+
 -- =====================================
 -- 创建额外的索引优化
 -- =====================================
@@ -146,6 +149,9 @@ INSERT INTO vt_monitor_metrics (name, display_name, description, metric_type, ca
 ('memory_usage_percent', '内存使用率', '系统内存使用率百分比', 'gauge', 'system', 'system', '%'),
 ('gpu_usage_percent', 'GPU使用率', 'GPU使用率百分比', 'gauge', 'gpu', 'gpu', '%'),
 ('gpu_memory_usage_percent', 'GPU显存使用率', 'GPU显存使用率百分比', 'gauge', 'gpu', 'gpu', '%'),
+('gpu_memory_used_mb', 'GPU已用显存', 'DCGM采集的GPU已用显存', 'gauge', 'gpu', 'gpu', 'MB'),
+('gpu_power_watts', 'GPU功耗', 'DCGM采集的GPU功耗', 'gauge', 'gpu', 'gpu', 'W'),
+('gpu_temperature_celsius', 'GPU温度', 'DCGM采集的GPU温度', 'gauge', 'gpu', 'gpu', '℃'),
 ('disk_usage_percent', '磁盘使用率', '磁盘使用率百分比', 'gauge', 'storage', 'system', '%'),
 ('network_bytes_in', '网络入流量', '网络入站流量', 'counter', 'network', 'system', 'bytes'),
 ('network_bytes_out', '网络出流量', '网络出站流量', 'counter', 'network', 'system', 'bytes'),
@@ -53,6 +53,7 @@ CREATE TABLE vt_gpu_nodes (
     gpu_total_memory_gb DECIMAL(8, 2) DEFAULT 0 COMMENT 'GPU总显存(GB)',
     status ENUM(
         'online',
+        'not_ready',
         'offline',
         'maintenance',
         'error',
@@ -110,6 +111,8 @@ CREATE TABLE vt_gpu_devices (
     vbios_version VARCHAR(64) COMMENT 'VBIOS版本',
     mig_enabled TINYINT(1) DEFAULT 0 COMMENT '是否启用MIG',
     mig_devices JSON COMMENT 'MIG设备列表',
+    sharing_mode VARCHAR(32) NOT NULL DEFAULT 'exclusive' COMMENT '共享方式(exclusive, mig, time_slicing, vgpu)',
+    share_capacity INT NOT NULL DEFAULT 1 COMMENT '可同时分配的共享单元数',
     memory_total_mb INT COMMENT '显存总量(MB)',
     memory_free_mb INT COMMENT '可用显存(MB)',
     memory_used_mb INT COMMENT '已用显存(MB)',
@@ -129,7 +132,7 @@ CREATE TABLE vt_gpu_devices (
         'error',
         'offline'
     ) DEFAULT 'available' COMMENT '设备状态',
-    health_status ENUM('healthy', 'warning', 'critical', 'unknown') DEFAULT 'unknown' COMMENT '健康状态',
+    health_status ENUM('healthy', 'warning', 'critical', 'unhealthy', 'unknown') DEFAULT 'unknown' COMMENT '健康状态',
     utilization_percent DECIMAL(5, 2) DEFAULT 0 COMMENT 'GPU使用率',
     memory_utilization_percent DECIMAL(5, 2) DEFAULT 0 COMMENT '显存使用率',
     encoder_utilization_percent DECIMAL(5, 2) DEFAULT 0 COMMENT '编码器使用率',
@@ -226,6 +229,9 @@ CREATE TABLE vt_gpu_device_allocations (
     entity_type VARCHAR(64) NOT NULL COMMENT '实体类型(user, workspace, training_job等)',
     entity_id BIGINT NOT NULL COMMENT '实体ID',
     allocation_type VARCHAR(64) DEFAULT 'exclusive' COMMENT '分配类型(exclusive, shared)',
+    sharing_mode VARCHAR(32) COMMENT '共享分配的方式(mig, time_slicing, vgpu)',
+    mig_profile VARCHAR(32) COMMENT 'MIG规格',
+    memory_mb INT COMMENT 'vGPU显存(MB)',
     allocated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '分配时间',
     released_at TIMESTAMP NULL COMMENT '释放时间',
     expected_duration_seconds INT COMMENT '预期使用时长(秒)',
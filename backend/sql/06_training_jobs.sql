@@ -89,6 +89,8 @@ CREATE TABLE vt_training_jobs (
     gpu_count INT DEFAULT 0 COMMENT 'GPU数量',
     gpu_type VARCHAR(64) COMMENT 'GPU类型',
     gpu_memory_gb DECIMAL(8, 2) COMMENT 'GPU显存(GB)',
+    gpu_sharing_mode VARCHAR(32) COMMENT 'GPU共享方式(mig, time_slicing, vgpu)，为空时独占整卡',
+    gpu_mig_profile VARCHAR(32) COMMENT 'MIG规格',
     storage_gb DECIMAL(8, 2) COMMENT '存储(GB)',
     shared_memory_gb DECIMAL(8, 2) COMMENT '共享内存(GB)',
     worker_count INT DEFAULT 1 COMMENT 'Worker数量',
@@ -152,6 +154,8 @@ CREATE TABLE vt_training_jobs (
     logs_path VARCHAR(512) COMMENT '日志路径',
     output_path VARCHAR(512) COMMENT '输出路径',
     checkpoint_path VARCHAR(512) COMMENT '检查点路径',
+    resume_checkpoint_id BIGINT COMMENT '恢复训练使用的检查点ID',
+    resume_checkpoint_path VARCHAR(512) COMMENT '恢复训练使用的检查点路径',
     tensorboard_path VARCHAR(512) COMMENT 'TensorBoard路径',
     hyperparameters JSON COMMENT '超参数',
     training_config JSON COMMENT '训练配置',
@@ -224,6 +228,7 @@ CREATE TABLE vt_training_job_instances (
     cpu_usage_percent DECIMAL(5, 2) DEFAULT 0 COMMENT 'CPU使用率',
     memory_usage_percent DECIMAL(5, 2) DEFAULT 0 COMMENT '内存使用率',
     gpu_usage_percent DECIMAL(5, 2) DEFAULT 0 COMMENT 'GPU使用率',
+    usage_updated_at TIMESTAMP NULL COMMENT '资源使用率采集时间',
     logs_path VARCHAR(512) COMMENT '日志路径',
     labels JSON COMMENT '标签',
     annotations JSON COMMENT '注解',
@@ -373,9 +378,9 @@ CREATE TABLE vt_training_checkpoints (
 CREATE TABLE vt_training_job_relations (
     id BIGINT PRIMARY KEY AUTO_INCREMENT,
     job_id BIGINT NOT NULL COMMENT '训练作业ID',
-    entity_type VARCHAR(64) NOT NULL COMMENT '实体类型(workspace, project, user, dataset, model, code_file等)',
+    entity_type VARCHAR(64) NOT NULL COMMENT '实体类型(workspace, project, user, dataset, model, code_file, training_job等)',
     entity_id BIGINT NOT NULL COMMENT '实体ID',
-    relation_type VARCHAR(64) NOT NULL COMMENT '关联类型(workspace, project, creator, dataset, base_model, code_file等)',
+    relation_type VARCHAR(64) NOT NULL COMMENT '关联类型(workspace, project, creator, dataset, base_model, code_file, depends_on等)',
     is_primary TINYINT(1) DEFAULT 0 COMMENT '是否主要关联',
     sort_order INT DEFAULT 0 COMMENT '排序',
     status ENUM('active', 'inactive', 'pending', 'deleted') DEFAULT 'active' COMMENT '状态',
@@ -402,4 +407,155 @@ CREATE TABLE vt_training_checkpoint_files (
     INDEX idx_file_id (file_id),
     INDEX idx_file_type (file_type),
     INDEX idx_status (status)
-) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci COMMENT = '训练检查点文件关联表';
\ No newline at end of file
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci COMMENT = '训练检查点文件关联表';
+-- 训练作业状态变更记录表
+CREATE TABLE vt_training_job_transitions (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    job_id BIGINT NOT NULL COMMENT '训练作业ID',
+    action VARCHAR(32) NOT NULL COMMENT '触发动作(submit, start, suspend, resume, restart, cancel, succeed, fail等)',
+    from_status VARCHAR(32) NOT NULL COMMENT '变更前状态',
+    to_status VARCHAR(32) NOT NULL COMMENT '变更后状态',
+    operator_id BIGINT COMMENT '操作人ID，系统操作为空',
+    operator_name VARCHAR(64) COMMENT '操作人名称',
+    reason VARCHAR(512) COMMENT '变更原因',
+    volcano_job_name VARCHAR(128) COMMENT '变更时对应的Volcano作业名',
+    resume_checkpoint_id BIGINT COMMENT '新一次运行恢复训练使用的检查点ID，为空表示从头开始',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    INDEX idx_job_id (job_id),
+    INDEX idx_action (action),
+    INDEX idx_operator_id (operator_id),
+    INDEX idx_created_at (created_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci COMMENT = '训练作业状态变更记录表';
+-- 训练作业自动重试记录表
+CREATE TABLE vt_training_job_retries (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    job_id BIGINT NOT NULL COMMENT '训练作业ID',
+    attempt INT NOT NULL COMMENT '第几次重试',
+    failure_reason VARCHAR(64) NOT NULL COMMENT '触发重试的失败原因',
+    exit_code INT COMMENT '失败时的退出码',
+    error_message TEXT COMMENT '失败时的错误信息',
+    failed_volcano_job_name VARCHAR(128) COMMENT '失败的Volcano作业名',
+    retry_volcano_job_name VARCHAR(128) COMMENT '重试使用的Volcano作业名',
+    checkpoint_id BIGINT COMMENT '恢复使用的检查点ID',
+    checkpoint_path VARCHAR(512) COMMENT '恢复使用的检查点路径',
+    backoff_seconds INT DEFAULT 0 COMMENT '重试前等待时长(秒)',
+    failed_at TIMESTAMP NULL COMMENT '失败时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '重试时间',
+    UNIQUE KEY uk_job_attempt (job_id, attempt),
+    INDEX idx_job_id (job_id),
+    INDEX idx_failure_reason (failure_reason),
+    INDEX idx_created_at (created_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci COMMENT = '训练作业自动重试记录表';
+-- 训练作业模板表
+CREATE TABLE vt_training_job_templates (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    name VARCHAR(128) NOT NULL COMMENT '模板名称',
+    display_name VARCHAR(256) COMMENT '显示名称',
+    description TEXT COMMENT '模板描述',
+    owner_id BIGINT NOT NULL COMMENT '创建人ID',
+    owner_name VARCHAR(64) COMMENT '创建人名称',
+    workspace_id BIGINT NOT NULL DEFAULT 0 COMMENT '所属工作空间ID，0表示个人模板',
+    visibility ENUM('private', 'workspace') DEFAULT 'private' COMMENT '可见性',
+    spec JSON NOT NULL COMMENT '作业规格，字符串值中可使用${param}占位符',
+    parameters JSON COMMENT '参数定义列表',
+    use_count INT DEFAULT 0 COMMENT '实例化次数',
+    last_used_at TIMESTAMP NULL COMMENT '最后实例化时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    deleted_at TIMESTAMP NULL COMMENT '删除时间',
+    UNIQUE KEY uk_owner_workspace_name (owner_id, workspace_id, name),
+    INDEX idx_owner_id (owner_id),
+    INDEX idx_workspace_visibility (workspace_id, visibility),
+    INDEX idx_deleted_at (deleted_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci COMMENT = '训练作业模板表';
+-- 超参数搜索表
+CREATE TABLE vt_training_sweeps (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    name VARCHAR(128) NOT NULL COMMENT '搜索名称',
+    display_name VARCHAR(256) COMMENT '显示名称',
+    description TEXT COMMENT '描述',
+    owner_id BIGINT NOT NULL COMMENT '创建人ID',
+    owner_name VARCHAR(64) COMMENT '创建人名称',
+    algorithm ENUM('grid', 'random', 'bayesian') NOT NULL DEFAULT 'random' COMMENT '搜索算法',
+    objective_metric VARCHAR(128) NOT NULL COMMENT '目标指标名称，读取自vt_training_metrics',
+    objective_goal ENUM('minimize', 'maximize') NOT NULL DEFAULT 'minimize' COMMENT '优化方向',
+    search_space JSON NOT NULL COMMENT '搜索空间',
+    base_spec JSON NOT NULL COMMENT '试验作业的基础规格，格式同创建训练作业请求',
+    max_trials INT NOT NULL DEFAULT 10 COMMENT '最大试验数',
+    parallelism INT NOT NULL DEFAULT 2 COMMENT '最大并行试验数',
+    early_stopping JSON COMMENT '提前终止策略',
+    status ENUM('running', 'completed', 'failed', 'cancelled') DEFAULT 'running' COMMENT '状态',
+    trial_count INT DEFAULT 0 COMMENT '已创建试验数',
+    completed_count INT DEFAULT 0 COMMENT '已结束试验数',
+    pruned_count INT DEFAULT 0 COMMENT '提前终止试验数',
+    best_job_id BIGINT COMMENT '最优试验作业ID',
+    best_value DECIMAL(20, 8) COMMENT '最优目标值',
+    error_message TEXT COMMENT '错误信息',
+    finished_at TIMESTAMP NULL COMMENT '结束时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    deleted_at TIMESTAMP NULL COMMENT '删除时间',
+    UNIQUE KEY uk_owner_name (owner_id, name),
+    INDEX idx_status (status),
+    INDEX idx_deleted_at (deleted_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci COMMENT = '超参数搜索表';
+-- 训练作业定时触发器表
+CREATE TABLE vt_training_triggers (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    name VARCHAR(128) NOT NULL COMMENT '触发器名称，同时作为触发作业名前缀',
+    description TEXT COMMENT '描述',
+    owner_id BIGINT NOT NULL COMMENT '创建人ID',
+    owner_name VARCHAR(64) COMMENT '创建人名称',
+    target_type ENUM('job', 'template') NOT NULL COMMENT '触发目标类型：复制训练作业或实例化模板',
+    target_id BIGINT NOT NULL COMMENT '目标训练作业或模板ID',
+    parameters JSON COMMENT '实例化模板使用的参数值',
+    schedule VARCHAR(128) NOT NULL COMMENT 'cron表达式(分 时 日 月 周)',
+    timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Shanghai' COMMENT '计算触发时间使用的时区',
+    concurrency_policy ENUM('Allow', 'Forbid', 'Replace') NOT NULL DEFAULT 'Allow' COMMENT '上一次运行未结束时的处理策略',
+    starting_deadline_seconds INT NOT NULL DEFAULT 0 COMMENT '错过触发时间后仍允许补触发的秒数，0表示不限制',
+    enabled TINYINT(1) NOT NULL DEFAULT 1 COMMENT '是否启用',
+    next_run_at TIMESTAMP NULL COMMENT '下一次触发时间',
+    last_run_at TIMESTAMP NULL COMMENT '最近一次触发时间',
+    last_job_id BIGINT COMMENT '最近一次触发创建的作业ID',
+    run_count INT DEFAULT 0 COMMENT '累计创建作业次数',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    deleted_at TIMESTAMP NULL COMMENT '删除时间',
+    UNIQUE KEY uk_owner_name (owner_id, name),
+    INDEX idx_target (target_type, target_id),
+    INDEX idx_enabled_next_run (enabled, next_run_at),
+    INDEX idx_deleted_at (deleted_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci COMMENT = '训练作业定时触发器表';
+-- 定时触发记录表
+CREATE TABLE vt_training_trigger_runs (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    trigger_id BIGINT NOT NULL COMMENT '触发器ID',
+    scheduled_at TIMESTAMP NOT NULL COMMENT '计划触发时间',
+    status ENUM('created', 'skipped', 'missed', 'failed') NOT NULL COMMENT '触发结果：已创建作业、因并发策略跳过、错过触发时间、创建失败',
+    job_id BIGINT COMMENT '创建的训练作业ID',
+    replaced_job_ids JSON COMMENT 'Replace策略取消的作业ID',
+    message TEXT COMMENT '说明',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '处理时间',
+    UNIQUE KEY uk_trigger_scheduled (trigger_id, scheduled_at),
+    INDEX idx_trigger_status (trigger_id, status),
+    INDEX idx_job_id (job_id)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci COMMENT = '定时触发记录表';
+-- 检查点保留策略表
+CREATE TABLE vt_checkpoint_retention_policies (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    name VARCHAR(128) NOT NULL COMMENT '策略名称',
+    scope_type ENUM('global', 'workspace', 'queue', 'job') NOT NULL COMMENT '作用范围，作业 > 队列 > 工作空间 > 全局，只使用最具体的一条策略',
+    scope_id BIGINT NOT NULL DEFAULT 0 COMMENT '工作空间、队列或作业ID，全局策略为0',
+    keep_last INT NOT NULL DEFAULT 0 COMMENT '保留步数最大的N个检查点，0表示不按该规则保留',
+    keep_best INT NOT NULL DEFAULT 0 COMMENT '按best_metric保留最好的K个检查点，0表示不按该规则保留',
+    best_metric VARCHAR(128) NOT NULL DEFAULT 'loss' COMMENT '评价指标：loss、accuracy、validation_score或metrics中的指标名',
+    best_goal ENUM('minimize', 'maximize') NOT NULL DEFAULT 'minimize' COMMENT '指标优化方向',
+    keep_every_n_epochs INT NOT NULL DEFAULT 0 COMMENT '保留epoch为N的整数倍的检查点，0表示不按该规则保留',
+    enabled TINYINT(1) NOT NULL DEFAULT 1 COMMENT '是否启用',
+    description TEXT COMMENT '描述',
+    created_by BIGINT COMMENT '创建人ID',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    UNIQUE KEY uk_scope (scope_type, scope_id),
+    INDEX idx_enabled (enabled)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci COMMENT = '检查点保留策略表';
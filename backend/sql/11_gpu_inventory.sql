@@ -0,0 +1,49 @@
+-- GPU资源发现、分配和监控使用的列
+-- 集群、节点和设备模型直接按集群ID和节点ID关联，与05_gpu_clusters.sql中的列名对齐
+
+-- GPU集群表
+ALTER TABLE vt_gpu_clusters
+    CHANGE COLUMN cluster_endpoint api_endpoint VARCHAR(512) COMMENT 'K8s API地址',
+    CHANGE COLUMN available_nodes active_nodes INT DEFAULT 0 COMMENT '在线节点数',
+    ADD COLUMN kube_config TEXT COMMENT 'kubeconfig内容，为空时使用服务所在集群' AFTER cluster_type,
+    ADD COLUMN allocated_gpus INT DEFAULT 0 COMMENT '已分配GPU数' AFTER available_gpus,
+    ADD COLUMN resource_labels TEXT COMMENT '资源标签' AFTER allocated_gpus,
+    ADD COLUMN metrics_config TEXT COMMENT '监控配置' AFTER resource_labels;
+
+-- GPU节点表，节点按集群和名称同步，主机名和IP可能为空或在集群间重复
+ALTER TABLE vt_gpu_nodes
+    ADD COLUMN cluster_id BIGINT NOT NULL DEFAULT 0 COMMENT '集群ID' AFTER id,
+    CHANGE COLUMN ip_address internal_ip VARCHAR(64) NOT NULL DEFAULT '' COMMENT '内网IP',
+    ADD COLUMN external_ip VARCHAR(64) NOT NULL DEFAULT '' COMMENT '外网IP' AFTER internal_ip,
+    ADD COLUMN os_image VARCHAR(256) COMMENT '操作系统镜像' AFTER os_version,
+    ADD COLUMN available_gpus INT DEFAULT 0 COMMENT '可用GPU数量' AFTER gpu_count,
+    ADD COLUMN allocated_gpus INT DEFAULT 0 COMMENT '已分配GPU数量' AFTER available_gpus,
+    CHANGE COLUMN labels node_labels JSON COMMENT 'K8s标签',
+    CHANGE COLUMN taints node_taints JSON COMMENT 'K8s污点',
+    CHANGE COLUMN last_heartbeat_at last_heartbeat TIMESTAMP NULL COMMENT '最后心跳时间',
+    DROP INDEX uk_hostname,
+    DROP INDEX uk_ip_address,
+    ADD INDEX idx_cluster_name (cluster_id, name),
+    ADD INDEX idx_hostname (hostname),
+    ADD INDEX idx_internal_ip (internal_ip);
+
+-- GPU设备表，UUID未上报时为NULL
+ALTER TABLE vt_gpu_devices
+    ADD COLUMN cluster_id BIGINT NOT NULL DEFAULT 0 COMMENT '集群ID' AFTER id,
+    ADD COLUMN node_id BIGINT NOT NULL DEFAULT 0 COMMENT '节点ID' AFTER cluster_id,
+    CHANGE COLUMN pci_bus_id pcie_bus_id VARCHAR(32) COMMENT 'PCIe总线ID',
+    CHANGE COLUMN gpu_model model VARCHAR(128) COMMENT 'GPU型号',
+    CHANGE COLUMN gpu_brand brand ENUM('nvidia', 'amd', 'intel', 'other') DEFAULT 'nvidia' COMMENT 'GPU品牌',
+    CHANGE COLUMN utilization_percent utilization_gpu INT DEFAULT 0 COMMENT 'GPU使用率',
+    CHANGE COLUMN memory_utilization_percent utilization_mem INT DEFAULT 0 COMMENT '显存使用率',
+    CHANGE COLUMN temperature_celsius temperature_c INT DEFAULT 0 COMMENT '温度(摄氏度)',
+    CHANGE COLUMN power_usage_watts power_draw_w INT DEFAULT 0 COMMENT '功耗(瓦)',
+    CHANGE COLUMN power_limit_watts power_limit_w INT DEFAULT 0 COMMENT '功耗限制(瓦)',
+    ADD COLUMN allocation_id BIGINT NOT NULL DEFAULT 0 COMMENT '独占分配ID' AFTER health_status,
+    ADD COLUMN allocated_job_id BIGINT NOT NULL DEFAULT 0 COMMENT '独占分配的训练作业ID' AFTER allocation_id,
+    ADD COLUMN allocated_user_id BIGINT NOT NULL DEFAULT 0 COMMENT '独占分配的用户ID' AFTER allocated_job_id,
+    ADD COLUMN allocated_at TIMESTAMP NULL COMMENT '独占分配时间' AFTER allocated_user_id,
+    ADD COLUMN last_heartbeat TIMESTAMP NULL COMMENT '最后心跳时间' AFTER allocated_at,
+    ADD INDEX idx_node_device_index (node_id, device_index),
+    ADD INDEX idx_cluster_id (cluster_id),
+    ADD INDEX idx_allocation_id (allocation_id);
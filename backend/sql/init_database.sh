@@ -89,6 +89,7 @@ SQL_FILES=(
     "08_system_support.sql"
     "09_monitoring.sql"
     "10_k8s_relations.sql"
+    "11_gpu_inventory.sql"
 )
 
 for file in "${SQL_FILES[@]}"; do
@@ -0,0 +1,188 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"api/internal/logic/training"
+	"api/internal/svc"
+	"api/internal/types"
+	"api/model"
+	"api/pkg/checkpoint"
+	bizerrors "api/pkg/errors"
+	"api/pkg/scheduler"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestCheckpointGCSuite struct {
+	suite.Suite
+	baseDir     string
+	checkpoints *fakeCheckpointsModel
+	policies    *fakeRetentionPoliciesModel
+	relations   *fakeRelationsModel
+	svcCtx      *svc.ServiceContext
+}
+
+func (s *TestCheckpointGCSuite) SetupTest() {
+	s.baseDir = s.T().TempDir()
+	s.checkpoints = &fakeCheckpointsModel{}
+	s.policies = &fakeRetentionPoliciesModel{}
+	s.relations = &fakeRelationsModel{}
+	jobs := newFakeTrainingJobsModel(
+		&model.VtTrainingJobs{Id: 5, Name: "llama", Status: "running"},
+		&model.VtTrainingJobs{Id: 6, Name: "bert", Status: "succeeded"},
+	)
+	manager := checkpoint.NewCheckpointManager(s.checkpoints, checkpoint.NewFileSystemCheckpointStorage(s.baseDir))
+	s.svcCtx = &svc.ServiceContext{
+		VtTrainingJobsModel:                jobs,
+		VtTrainingCheckpointsModel:         s.checkpoints,
+		VtCheckpointRetentionPoliciesModel: s.policies,
+		CheckpointManager:                  manager,
+		CheckpointGC: scheduler.NewCheckpointGC(s.policies, s.checkpoints, jobs, nil, s.relations, manager,
+			scheduler.CheckpointGCConfig{}),
+	}
+}
+
+// addCheckpoint 写入检查点文件并登记，文件大小等于步数
+func (s *TestCheckpointGCSuite) addCheckpoint(jobId, step int64, epoch int, checkpointType, loss string) int64 {
+	name := fmt.Sprintf("step-%d", step)
+	if checkpointType == "final" {
+		name = "final"
+	}
+	path := filepath.Join(s.baseDir, fmt.Sprintf("jobs/%d/%s.pt", jobId, name))
+	s.Require().NoError(os.MkdirAll(filepath.Dir(path), 0755))
+	s.Require().NoError(os.WriteFile(path, []byte(strings.Repeat("w", int(step))), 0644))
+
+	result, err := s.checkpoints.Insert(&model.VtTrainingCheckpoints{JobId: jobId, CheckpointName: name, CheckpointType: checkpointType,
+		GlobalStep: step, Epoch: epoch, StoragePath: path, FileSize: step, LossValue: loss, Status: "saved"})
+	s.Require().NoError(err)
+	id, _ := result.LastInsertId()
+	return id
+}
+
+func (s *TestCheckpointGCSuite) createPolicy(req *types.CreateCheckpointRetentionPolicyReq) error {
+	_, err := training.NewCreateCheckpointRetentionPolicyLogic(context.Background(), s.svcCtx).CreateCheckpointRetentionPolicy(req)
+	return err
+}
+
+func (s *TestCheckpointGCSuite) runGC(dryRun bool) *types.RunCheckpointGCResp {
+	resp, err := training.NewRunCheckpointGCLogic(context.Background(), s.svcCtx).RunCheckpointGC(&types.RunCheckpointGCReq{DryRun: dryRun})
+	s.Require().NoError(err)
+	return resp
+}
+
+func (s *TestCheckpointGCSuite) names(jobId int64) []string {
+	checkpoints, err := s.checkpoints.FindByJobId(jobId)
+	s.Require().NoError(err)
+	var names []string
+	for _, c := range checkpoints {
+		names = append(names, c.CheckpointName)
+	}
+	return names
+}
+
+func (s *TestCheckpointGCSuite) httpStatus(err error) int {
+	bizErr := bizerrors.GetBizError(err)
+	s.Require().NotNil(bizErr, "%v", err)
+	return bizErr.GetHTTPStatus()
+}
+
+// TestRetention 保留最近N个、指标最好的K个、每N个epoch和final检查点，dry-run只统计不删除
+func (s *TestCheckpointGCSuite) TestRetention() {
+	losses := []string{"0.9", "0.5", "0.1", "0.4", "0.6", "0.7", "0.8", "0.3", "0.2"}
+	for i, loss := range losses {
+		s.addCheckpoint(6, int64(i+1)*100, i+1, "auto", loss)
+	}
+	s.addCheckpoint(6, 1000, 10, "final", "0.25")
+	s.Require().NoError(s.createPolicy(&types.CreateCheckpointRetentionPolicyReq{Name: "default", ScopeType: model.RetentionScopeGlobal,
+		KeepLast: 2, KeepBest: 1, BestMetric: "loss", BestGoal: "minimize", KeepEveryNEpochs: 4, Enabled: true}))
+
+	dryRun := s.runGC(true)
+	s.True(dryRun.DryRun)
+	s.Equal(int64(1), dryRun.Jobs)
+	s.Equal(int64(5), dryRun.Kept)
+	s.Len(dryRun.Removed, 5)
+	s.Equal(int64(100+200+500+600+700), dryRun.ReclaimedBytes)
+	s.Len(s.names(6), 10)
+	s.FileExists(filepath.Join(s.baseDir, "jobs/6/step-100.pt"))
+
+	report := s.runGC(false)
+	s.Equal(dryRun.ReclaimedBytes, report.ReclaimedBytes)
+	s.Equal(int64(0), report.Failed)
+	s.Equal([]string{"final", "step-900", "step-800", "step-400", "step-300"}, s.names(6))
+	s.NoFileExists(filepath.Join(s.baseDir, "jobs/6/step-100.pt"))
+	s.FileExists(filepath.Join(s.baseDir, "jobs/6/step-300.pt"))
+
+	// 再次回收没有可删除的检查点
+	s.Empty(s.runGC(false).Removed)
+}
+
+// TestPolicyPrecedence 作业策略优先于全局策略，运行中作业的最新检查点和恢复用检查点始终保留
+func (s *TestCheckpointGCSuite) TestPolicyPrecedence() {
+	resumeId := s.addCheckpoint(5, 10, 1, "auto", "")
+	s.addCheckpoint(5, 20, 2, "auto", "")
+	s.addCheckpoint(5, 30, 3, "auto", "")
+	latestId := s.addCheckpoint(5, 40, 4, "auto", "")
+	s.Require().NoError(s.checkpoints.MarkLatest(5, latestId))
+	jobs := s.svcCtx.VtTrainingJobsModel.(*fakeTrainingJobsModel)
+	jobs.jobs[5].ResumeCheckpointId = resumeId
+
+	s.Require().NoError(s.createPolicy(&types.CreateCheckpointRetentionPolicyReq{Name: "default", ScopeType: model.RetentionScopeGlobal,
+		KeepLast: 3, BestGoal: "minimize", Enabled: true}))
+	s.Require().NoError(s.createPolicy(&types.CreateCheckpointRetentionPolicyReq{Name: "llama", ScopeType: model.RetentionScopeJob, ScopeId: 5,
+		KeepBest: 1, BestMetric: "loss", BestGoal: "minimize", Enabled: true}))
+
+	// 没有loss指标时按指标保留不生效，只保留最新和恢复用检查点
+	report := s.runGC(false)
+	s.Len(report.Removed, 2)
+	s.Equal(int64(2), report.Removed[0].PolicyId)
+	s.Equal([]string{"step-40", "step-10"}, s.names(5))
+}
+
+// TestClonePinsSeed 克隆作业使用的初始检查点不会被源作业的保留策略删除
+func (s *TestCheckpointGCSuite) TestClonePinsSeed() {
+	seedId := s.addCheckpoint(6, 100, 1, "auto", "")
+	s.addCheckpoint(6, 200, 2, "auto", "")
+	s.addCheckpoint(6, 300, 3, "auto", "")
+	jobs := s.svcCtx.VtTrainingJobsModel.(*fakeTrainingJobsModel)
+	jobs.jobs[7] = &model.VtTrainingJobs{Id: 7, Name: "bert-clone-1", Status: "pending", ResumeCheckpointId: seedId}
+	_, err := s.relations.Insert(&model.VtTrainingJobRelations{JobId: 7, EntityType: scheduler.JobEntityType, EntityId: 6,
+		RelationType: scheduler.ClonedFromRelation})
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.createPolicy(&types.CreateCheckpointRetentionPolicyReq{Name: "default", ScopeType: model.RetentionScopeGlobal,
+		KeepLast: 1, BestGoal: "minimize", Enabled: true}))
+	s.Len(s.runGC(false).Removed, 1)
+	s.Equal([]string{"step-300", "step-100"}, s.names(6))
+}
+
+// TestValidatePolicy 至少需要一条保留规则，同一作用范围只能有一条策略
+func (s *TestCheckpointGCSuite) TestValidatePolicy() {
+	err := s.createPolicy(&types.CreateCheckpointRetentionPolicyReq{Name: "empty", ScopeType: model.RetentionScopeGlobal, BestGoal: "minimize"})
+	s.Equal(http.StatusBadRequest, s.httpStatus(err))
+	err = s.createPolicy(&types.CreateCheckpointRetentionPolicyReq{Name: "goal", ScopeType: model.RetentionScopeGlobal, KeepBest: 1, BestMetric: "loss", BestGoal: "lowest"})
+	s.Equal(http.StatusBadRequest, s.httpStatus(err))
+	err = s.createPolicy(&types.CreateCheckpointRetentionPolicyReq{Name: "scope", ScopeType: model.RetentionScopeGlobal, ScopeId: 3, KeepLast: 1})
+	s.Equal(http.StatusBadRequest, s.httpStatus(err))
+	err = s.createPolicy(&types.CreateCheckpointRetentionPolicyReq{Name: "missing", ScopeType: model.RetentionScopeJob, ScopeId: 99, KeepLast: 1})
+	s.Equal(http.StatusNotFound, s.httpStatus(err))
+
+	s.Require().NoError(s.createPolicy(&types.CreateCheckpointRetentionPolicyReq{Name: "llama", ScopeType: model.RetentionScopeJob, ScopeId: 5, KeepLast: 1}))
+	err = s.createPolicy(&types.CreateCheckpointRetentionPolicyReq{Name: "again", ScopeType: model.RetentionScopeJob, ScopeId: 5, KeepLast: 2})
+	s.Equal(http.StatusConflict, s.httpStatus(err))
+
+	keepLast := int64(0)
+	_, err = training.NewUpdateCheckpointRetentionPolicyLogic(context.Background(), s.svcCtx).UpdateCheckpointRetentionPolicy(
+		&types.UpdateCheckpointRetentionPolicyReq{Id: 1, KeepLast: &keepLast})
+	s.Equal(http.StatusBadRequest, s.httpStatus(err))
+}
+
+func TestRunCheckpointGCTests(t *testing.T) {
+	suite.Run(t, new(TestCheckpointGCSuite))
+}
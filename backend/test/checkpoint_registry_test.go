@@ -0,0 +1,223 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"api/internal/logic/training"
+	"api/internal/svc"
+	"api/internal/types"
+	"api/model"
+	"api/pkg/checkpoint"
+	bizerrors "api/pkg/errors"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeObjectStore 基于内存的S3兼容对象存储，按路径风格访问，只接受指定AccessKey签名的请求
+type fakeObjectStore struct {
+	accessKey string
+
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeObjectStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential="+f.accessKey+"/") || !strings.Contains(auth, "Signature=") ||
+		r.Header.Get("X-Amz-Date") == "" || r.Header.Get("X-Amz-Content-Sha256") == "" {
+		http.Error(w, "<Error><Code>AccessDenied</Code></Error>", http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := strings.TrimPrefix(r.URL.Path, "/")
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil || int64(len(data)) != r.ContentLength {
+			http.Error(w, "<Error><Code>IncompleteBody</Code></Error>", http.StatusBadRequest)
+			return
+		}
+		f.objects[name] = data
+	case http.MethodGet, http.MethodHead:
+		data, ok := f.objects[name]
+		if !ok {
+			http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	case http.MethodDelete:
+		delete(f.objects, name)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+type TestCheckpointRegistrySuite struct {
+	suite.Suite
+	baseDir     string
+	checkpoints *fakeCheckpointsModel
+	svcCtx      *svc.ServiceContext
+}
+
+func (s *TestCheckpointRegistrySuite) SetupTest() {
+	s.baseDir = s.T().TempDir()
+	s.checkpoints = &fakeCheckpointsModel{}
+	s.svcCtx = &svc.ServiceContext{
+		VtTrainingJobsModel:        newFakeTrainingJobsModel(&model.VtTrainingJobs{Id: 5, Name: "llama", Status: "running"}),
+		VtTrainingCheckpointsModel: s.checkpoints,
+		CheckpointManager:          checkpoint.NewCheckpointManager(s.checkpoints, checkpoint.NewFileSystemCheckpointStorage(s.baseDir)),
+	}
+}
+
+func (s *TestCheckpointRegistrySuite) writeFile(key string, data []byte) string {
+	path := filepath.Join(s.baseDir, key)
+	s.Require().NoError(os.MkdirAll(filepath.Dir(path), 0755))
+	s.Require().NoError(os.WriteFile(path, data, 0644))
+	return path
+}
+
+func (s *TestCheckpointRegistrySuite) create(req *types.CreateCheckpointReq) (*types.CreateCheckpointResp, error) {
+	return training.NewCreateCheckpointLogic(context.Background(), s.svcCtx).CreateCheckpoint(req)
+}
+
+func (s *TestCheckpointRegistrySuite) httpStatus(err error) int {
+	bizErr := bizerrors.GetBizError(err)
+	s.Require().NotNil(bizErr, "%v", err)
+	return bizErr.GetHTTPStatus()
+}
+
+// TestRegister 登记时补全文件大小和sha256校验和，步数更大的检查点成为最新检查点
+func (s *TestCheckpointRegistrySuite) TestRegister() {
+	data := []byte("step-100 weights")
+	path := s.writeFile("llama/step-100.pt", data)
+	s.writeFile("llama/step-200.pt", []byte("step-200 weights"))
+
+	resp, err := s.create(&types.CreateCheckpointReq{JobId: 5, CheckpointName: "step-100", CheckpointType: "auto", CheckpointFormat: "pytorch",
+		GlobalStep: 100, StoragePath: path, CompressionType: "none", LossValue: "0.5"})
+	s.Require().NoError(err)
+	sum := sha256.Sum256(data)
+	got, err := training.NewGetCheckpointLogic(context.Background(), s.svcCtx).GetCheckpoint(&types.GetCheckpointReq{Id: resp.Id})
+	s.Require().NoError(err)
+	s.Equal(path, got.Checkpoint.StoragePath)
+	s.Equal(int64(len(data)), got.Checkpoint.FileSize)
+	s.Equal("sha256:"+hex.EncodeToString(sum[:]), got.Checkpoint.Checksum)
+	s.Equal("saved", got.Checkpoint.Status)
+	s.True(got.Checkpoint.IsLatest)
+
+	// 相对路径按存储根目录解析
+	second, err := s.create(&types.CreateCheckpointReq{JobId: 5, CheckpointName: "step-200", GlobalStep: 200, StoragePath: "llama/step-200.pt"})
+	s.Require().NoError(err)
+	latest, err := s.checkpoints.FindLatest(5)
+	s.Require().NoError(err)
+	s.Equal(second.Id, latest.Id)
+	first, err := s.checkpoints.FindOne(resp.Id)
+	s.Require().NoError(err)
+	s.False(first.IsLatest)
+
+	_, err = s.create(&types.CreateCheckpointReq{JobId: 5, CheckpointName: "step-100", StoragePath: path})
+	s.Equal(http.StatusConflict, s.httpStatus(err))
+	_, err = s.create(&types.CreateCheckpointReq{JobId: 5, CheckpointName: "step-300", StoragePath: "llama/step-300.pt"})
+	s.Equal(http.StatusBadRequest, s.httpStatus(err))
+	_, err = s.create(&types.CreateCheckpointReq{JobId: 5, CheckpointName: "escape", StoragePath: "/etc/passwd"})
+	s.Equal(http.StatusBadRequest, s.httpStatus(err))
+	_, err = s.create(&types.CreateCheckpointReq{JobId: 5, CheckpointName: "lz", StoragePath: path, CompressionType: "zstd"})
+	s.Equal(http.StatusBadRequest, s.httpStatus(err))
+}
+
+// TestRestoreVerifiesChecksum 恢复时校验登记的校验和，不匹配时标记为损坏
+func (s *TestCheckpointRegistrySuite) TestRestoreVerifiesChecksum() {
+	data := []byte("epoch-3 weights")
+	path := s.writeFile("llama/epoch-3.pt", data)
+	sum := sha256.Sum256(data)
+	resp, err := s.create(&types.CreateCheckpointReq{JobId: 5, CheckpointName: "epoch-3", StoragePath: path,
+		Checksum: strings.ToUpper(hex.EncodeToString(sum[:]))})
+	s.Require().NoError(err)
+
+	var restored bytes.Buffer
+	_, err = s.svcCtx.CheckpointManager.Restore(context.Background(), resp.Id, &restored)
+	s.Require().NoError(err)
+	s.Equal(data, restored.Bytes())
+
+	s.writeFile("llama/epoch-3.pt", []byte("epoch-3 weightz"))
+	c, err := s.svcCtx.CheckpointManager.Verify(context.Background(), resp.Id)
+	s.ErrorIs(err, checkpoint.ErrCorrupted)
+	s.Equal("corrupted", c.Status)
+	stored, err := s.checkpoints.FindOne(resp.Id)
+	s.Require().NoError(err)
+	s.Equal("corrupted", stored.Status)
+}
+
+// TestObjectStorage 写入S3兼容存储时压缩并计算校验和，恢复时解压校验，删除时同时删除对象
+func (s *TestCheckpointRegistrySuite) TestObjectStorage() {
+	store := &fakeObjectStore{accessKey: "minio", objects: make(map[string][]byte)}
+	server := httptest.NewServer(store)
+	defer server.Close()
+
+	storage, err := checkpoint.NewS3CheckpointStorage(checkpoint.S3Config{
+		Endpoint: server.URL, Bucket: "volctrain", Prefix: "checkpoints", AccessKey: "minio", SecretKey: "minio123",
+	})
+	s.Require().NoError(err)
+	manager := checkpoint.NewCheckpointManager(s.checkpoints, storage)
+	s.svcCtx.CheckpointManager = manager
+
+	data := bytes.Repeat([]byte("final weights "), 1000)
+	c := &model.VtTrainingCheckpoints{JobId: 5, CheckpointName: "final", CheckpointType: "final", CompressionType: checkpoint.CompressionGzip, GlobalStep: 1000}
+	s.Require().NoError(manager.Save(context.Background(), c, bytes.NewReader(data)))
+	s.Equal("s3://volctrain/checkpoints/jobs/5/final.gz", c.StoragePath)
+	object := store.objects["volctrain/checkpoints/jobs/5/final.gz"]
+	s.Require().NotEmpty(object)
+	s.Less(len(object), len(data))
+	s.Equal(int64(len(object)), c.FileSize)
+	sum := sha256.Sum256(object)
+	s.Equal("sha256:"+hex.EncodeToString(sum[:]), c.Checksum)
+
+	var restored bytes.Buffer
+	_, err = manager.Restore(context.Background(), c.Id, &restored)
+	s.Require().NoError(err)
+	s.Equal(data, restored.Bytes())
+
+	// 已存在的对象可以按s3://地址登记
+	store.objects["volctrain/checkpoints/llama/step-500.pt"] = []byte("step-500")
+	resp, err := s.create(&types.CreateCheckpointReq{JobId: 5, CheckpointName: "step-500", GlobalStep: 500,
+		StoragePath: "s3://volctrain/checkpoints/llama/step-500.pt"})
+	s.Require().NoError(err)
+	_, err = s.create(&types.CreateCheckpointReq{JobId: 5, CheckpointName: "other", StoragePath: "s3://other-bucket/step-500.pt"})
+	s.Equal(http.StatusBadRequest, s.httpStatus(err))
+
+	_, err = training.NewDeleteCheckpointLogic(context.Background(), s.svcCtx).DeleteCheckpoint(&types.DeleteCheckpointReq{Id: resp.Id})
+	s.Require().NoError(err)
+	s.NotContains(store.objects, "volctrain/checkpoints/llama/step-500.pt")
+	_, err = training.NewGetCheckpointLogic(context.Background(), s.svcCtx).GetCheckpoint(&types.GetCheckpointReq{Id: resp.Id})
+	s.Equal(http.StatusNotFound, s.httpStatus(err))
+
+	// 压缩数据损坏时无法解压，标记为损坏
+	object[len(object)/2] ^= 0xff
+	_, err = manager.Verify(context.Background(), c.Id)
+	s.ErrorIs(err, checkpoint.ErrCorrupted)
+
+	wrongKey, err := checkpoint.NewS3CheckpointStorage(checkpoint.S3Config{Endpoint: server.URL, Bucket: "volctrain", AccessKey: "other"})
+	s.Require().NoError(err)
+	_, err = wrongKey.Stat(context.Background(), "checkpoints/jobs/5/final.gz")
+	s.Error(err)
+	s.NotErrorIs(err, checkpoint.ErrObjectNotFound)
+}
+
+func TestRunCheckpointRegistryTests(t *testing.T) {
+	suite.Run(t, new(TestCheckpointRegistrySuite))
+}
@@ -0,0 +1,232 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"api/model"
+	"api/pkg/checkpoint"
+	"api/pkg/scheduler"
+	"api/pkg/volcano"
+
+	"github.com/stretchr/testify/suite"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	vcjob "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+	vcfake "volcano.sh/apis/pkg/client/clientset/versioned/fake"
+)
+
+// TestCheckpointResumeSuite 检查点恢复测试套件
+type TestCheckpointResumeSuite struct {
+	suite.Suite
+	baseDir     string
+	vcClient    *vcfake.Clientset
+	jobModel    *fakeTrainingJobsModel
+	checkpoints *fakeCheckpointsModel
+	machine     *scheduler.JobStateMachine
+	dispatcher  *scheduler.JobDispatcher
+}
+
+// SetupTest 使用文件系统检查点存储，恢复时挂载存储所在的PVC
+func (s *TestCheckpointResumeSuite) SetupTest() {
+	s.baseDir = s.T().TempDir()
+	s.vcClient = vcfake.NewSimpleClientset()
+	s.jobModel = newFakeTrainingJobsModel()
+	s.checkpoints = &fakeCheckpointsModel{}
+
+	client := volcano.NewClientWithClientsets(s.vcClient, k8sfake.NewSimpleClientset(), testNamespace)
+	manager := checkpoint.NewCheckpointManager(s.checkpoints, checkpoint.NewFileSystemCheckpointStorage(s.baseDir))
+	s.machine = scheduler.NewJobStateMachine(s.jobModel, s.jobModel.transitions, client)
+	s.machine.SetCheckpointResumer(scheduler.NewCheckpointResumer(s.checkpoints, manager, scheduler.ResumeConfig{
+		PVCName:   "checkpoints",
+		MountPath: s.baseDir,
+	}))
+	s.dispatcher = scheduler.NewJobDispatcher(s.jobModel, s.machine, volcano.NewJobManager(client), nil, scheduler.DispatcherConfig{
+		Namespace: testNamespace,
+	})
+}
+
+// addCheckpoint 登记已保存的检查点，writeFile为false时模拟存储中文件丢失
+func (s *TestCheckpointResumeSuite) addCheckpoint(jobId, step int64, accuracy string, writeFile bool) *model.VtTrainingCheckpoints {
+	path := filepath.Join(s.baseDir, fmt.Sprintf("jobs/%d/step-%d.pt", jobId, step))
+	if writeFile {
+		s.Require().NoError(os.MkdirAll(filepath.Dir(path), 0755))
+		s.Require().NoError(os.WriteFile(path, []byte(strings.Repeat("w", int(step))), 0644))
+	}
+	c := &model.VtTrainingCheckpoints{JobId: jobId, CheckpointName: fmt.Sprintf("step-%d", step), Step: step, GlobalStep: step,
+		Epoch: int(step / 100), StoragePath: path, FileSize: step, Accuracy: accuracy, Status: "saved"}
+	result, err := s.checkpoints.Insert(c)
+	s.Require().NoError(err)
+	c.Id, _ = result.LastInsertId()
+	return c
+}
+
+func (s *TestCheckpointResumeSuite) newJob(id int64, status string) *model.VtTrainingJobs {
+	job := newPendingJob(id, "llama")
+	job.Status = status
+	job.Namespace = testNamespace
+	s.jobModel.jobs[id] = job
+	return job
+}
+
+// TestRestartFromLatestValidCheckpoint 重新运行跳过文件丢失和保存中的检查点，派发时注入检查点信息并挂载存储
+func (s *TestCheckpointResumeSuite) TestRestartFromLatestValidCheckpoint() {
+	s.newJob(1, "failed")
+	s.addCheckpoint(1, 100, "", true)
+	valid := s.addCheckpoint(1, 200, "", true)
+	missing := s.addCheckpoint(1, 300, "", false)
+	saving := s.addCheckpoint(1, 400, "", true)
+	s.Require().NoError(s.checkpoints.UpdateStatus(saving.Id, "saving"))
+
+	_, err := s.machine.Restart(1, scheduler.SystemOperator, "", scheduler.RestartOptions{})
+	s.Require().NoError(err)
+
+	job := s.jobModel.get(1)
+	s.Equal("pending", job.Status)
+	s.Equal(valid.Id, job.ResumeCheckpointId)
+	s.Equal(valid.StoragePath, job.ResumeCheckpointPath)
+	corrupted, _ := s.checkpoints.FindOne(missing.Id)
+	s.Equal("corrupted", corrupted.Status)
+
+	records, _ := s.jobModel.transitions.FindByJobId(1)
+	s.Require().NotEmpty(records)
+	s.Equal(valid.Id, records[len(records)-1].ResumeCheckpointId)
+	s.Contains(records[len(records)-1].Reason, "step-200")
+
+	submitted, err := s.dispatcher.DispatchOnce()
+	s.NoError(err)
+	s.Equal(1, submitted)
+
+	vcJob, err := s.vcClient.BatchV1alpha1().Jobs(testNamespace).Get(context.Background(), job.VolcanoJobName, metav1.GetOptions{})
+	s.Require().NoError(err)
+	pod := vcJob.Spec.Tasks[0].Template.Spec
+	env := map[string]string{}
+	for _, e := range pod.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	s.Equal(valid.StoragePath, env[scheduler.ResumeCheckpointEnv])
+	s.Equal(fmt.Sprint(valid.Id), env[scheduler.ResumeCheckpointIDEnv])
+	s.Equal("200", env[scheduler.ResumeGlobalStepEnv])
+	s.Equal("2", env[scheduler.ResumeEpochEnv])
+
+	var claims []string
+	for _, v := range pod.Volumes {
+		if v.PersistentVolumeClaim != nil {
+			claims = append(claims, v.PersistentVolumeClaim.ClaimName)
+		}
+	}
+	s.Equal([]string{"checkpoints"}, claims)
+
+	// 从头开始时清除恢复点，记录中不再有检查点
+	s.jobModel.jobs[1].Status = "failed"
+	_, err = s.machine.Restart(1, scheduler.SystemOperator, "", scheduler.RestartOptions{FromScratch: true})
+	s.Require().NoError(err)
+	s.Equal(int64(0), s.jobModel.get(1).ResumeCheckpointId)
+	records, _ = s.jobModel.transitions.FindByJobId(1)
+	s.Equal(int64(0), records[len(records)-1].ResumeCheckpointId)
+}
+
+// TestRestoreFromBest 配置restore_from_best时按监控指标选择，也可以指定检查点，已成功的作业默认从头开始
+func (s *TestCheckpointResumeSuite) TestRestoreFromBest() {
+	job := s.newJob(2, "failed")
+	job.TrainingConfig = `{"checkpoint":{"restore_from_best":true,"monitor_metric":"accuracy"}}`
+	best := s.addCheckpoint(2, 100, "0.92", true)
+	other := s.addCheckpoint(2, 200, "0.85", true)
+	s.addCheckpoint(2, 300, "", true)
+
+	_, err := s.machine.Restart(2, scheduler.SystemOperator, "", scheduler.RestartOptions{})
+	s.Require().NoError(err)
+	s.Equal(best.Id, s.jobModel.get(2).ResumeCheckpointId)
+
+	s.jobModel.jobs[2].Status = "succeeded"
+	_, err = s.machine.Restart(2, scheduler.SystemOperator, "", scheduler.RestartOptions{})
+	s.Require().NoError(err)
+	s.Equal(int64(0), s.jobModel.get(2).ResumeCheckpointId)
+
+	s.jobModel.jobs[2].Status = "succeeded"
+	_, err = s.machine.Restart(2, scheduler.SystemOperator, "", scheduler.RestartOptions{CheckpointId: other.Id})
+	s.Require().NoError(err)
+	s.Equal(other.Id, s.jobModel.get(2).ResumeCheckpointId)
+
+	// 其他作业的检查点不能用于恢复
+	s.newJob(9, "failed")
+	_, err = s.machine.Restart(9, scheduler.SystemOperator, "", scheduler.RestartOptions{CheckpointId: other.Id})
+	s.Error(err)
+}
+
+// TestResumeFromNewerCheckpoint 恢复暂停的作业时存在更新的检查点，以新的运行从该检查点重新提交
+func (s *TestCheckpointResumeSuite) TestResumeFromNewerCheckpoint() {
+	first := s.addCheckpoint(3, 100, "", true)
+	job := s.newJob(3, "suspended")
+	scheduledAt := time.Now().Add(-time.Hour)
+	job.VolcanoJobName = scheduler.BuildVolcanoJobName(job)
+	job.ScheduledAt = &scheduledAt
+	job.ResumeCheckpointId = first.Id
+	suspendedRun := job.VolcanoJobName
+	_, err := s.vcClient.BatchV1alpha1().Jobs(testNamespace).Create(context.Background(), &vcjob.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: job.VolcanoJobName, Namespace: testNamespace},
+	}, metav1.CreateOptions{})
+	s.Require().NoError(err)
+	newer := s.addCheckpoint(3, 200, "", true)
+
+	status, err := s.machine.Resume(3, scheduler.SystemOperator, "")
+	s.Require().NoError(err)
+	s.Equal("queued", status)
+
+	resumed := s.jobModel.get(3)
+	s.Equal(suspendedRun+"-r1", resumed.VolcanoJobName)
+	s.Nil(resumed.ScheduledAt)
+	s.Equal(newer.Id, resumed.ResumeCheckpointId)
+	_, err = s.vcClient.BatchV1alpha1().Jobs(testNamespace).Get(context.Background(), suspendedRun, metav1.GetOptions{})
+	s.True(apierrors.IsNotFound(err))
+
+	submitted, err := s.dispatcher.DispatchOnce()
+	s.NoError(err)
+	s.Equal(1, submitted)
+}
+
+// TestInjectDownloadFromS3 对象存储中的检查点由初始化容器通过预签名地址下载、校验并解压
+func (s *TestCheckpointResumeSuite) TestInjectDownloadFromS3() {
+	storage, err := checkpoint.NewS3CheckpointStorage(checkpoint.S3Config{
+		Endpoint: "http://minio:9000", Bucket: "ckpt", Prefix: "checkpoints", AccessKey: "ak", SecretKey: "sk",
+	})
+	s.Require().NoError(err)
+	resumer := scheduler.NewCheckpointResumer(s.checkpoints, checkpoint.NewCheckpointManager(s.checkpoints, storage), scheduler.ResumeConfig{})
+
+	digest := strings.Repeat("ab", 32)
+	c := &model.VtTrainingCheckpoints{JobId: 4, CheckpointName: "step-100", Step: 100, GlobalStep: 100, Epoch: 1,
+		StoragePath: "s3://ckpt/checkpoints/jobs/4/step-100.pt.gz", CompressionType: "gzip", Checksum: "sha256:" + digest, Status: "saved"}
+	result, err := s.checkpoints.Insert(c)
+	s.Require().NoError(err)
+	id, _ := result.LastInsertId()
+
+	spec := &volcano.TrainingJobSpec{}
+	s.Require().NoError(resumer.Inject(spec, &model.VtTrainingJobs{Id: 4, ResumeCheckpointId: id}))
+
+	s.Equal(scheduler.ResumeDownloadDir+"/step-100.pt", spec.EnvVars[scheduler.ResumeCheckpointEnv])
+	s.Equal("100", spec.EnvVars[scheduler.ResumeStepEnv])
+	s.Require().Len(spec.InitContainers, 1)
+	downloader := spec.InitContainers[0]
+	env := map[string]string{}
+	for _, e := range downloader.Env {
+		env[e.Name] = e.Value
+	}
+	s.True(strings.HasPrefix(env["CHECKPOINT_URL"], "http://minio:9000/ckpt/checkpoints/jobs/4/step-100.pt.gz?X-Amz-Algorithm=AWS4-HMAC-SHA256"))
+	s.Contains(env["CHECKPOINT_URL"], "X-Amz-Signature=")
+	s.Equal(spec.EnvVars[scheduler.ResumeCheckpointEnv], env["CHECKPOINT_FILE"])
+	script := downloader.Command[len(downloader.Command)-1]
+	s.Contains(script, digest+"  $DOWNLOAD\" | sha256sum -c -")
+	s.Contains(script, "gunzip")
+	s.Require().Len(spec.VolumeMounts, 1)
+	s.Equal("emptyDir", spec.VolumeMounts[0].Type)
+}
+
+func TestCheckpointResumeTests(t *testing.T) {
+	suite.Run(t, new(TestCheckpointResumeSuite))
+}
@@ -0,0 +1,213 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"api/internal/config"
+	"api/internal/logic/training"
+	"api/internal/svc"
+	"api/internal/types"
+	"api/model"
+	"api/pkg/database"
+	bizerrors "api/pkg/errors"
+	"api/pkg/scheduler"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+// recordedArg 匹配任意参数并记录实际传入的值
+type recordedArg struct {
+	value driver.Value
+}
+
+func (a *recordedArg) Match(value driver.Value) bool {
+	a.value = value
+	return true
+}
+
+// jobInsertColumns 创建作业时INSERT语句的列
+var jobInsertColumns = regexp.MustCompile(`INSERT INTO vt_training_jobs \(([^)]*)\)`)
+
+// TestCloneTrainingJobSuite 克隆训练作业测试套件，作业和关联通过sqlmock校验在同一事务中写入
+type TestCloneTrainingJobSuite struct {
+	suite.Suite
+	db          *sql.DB
+	mock        sqlmock.Sqlmock
+	jobModel    *fakeTrainingJobsModel
+	checkpoints *fakeCheckpointsModel
+	svcCtx      *svc.ServiceContext
+	queries     []string
+}
+
+func (s *TestCloneTrainingJobSuite) SetupTest() {
+	s.queries = nil
+	matcher := sqlmock.QueryMatcherFunc(func(expectedSQL, actualSQL string) error {
+		if err := sqlmock.QueryMatcherRegexp.Match(expectedSQL, actualSQL); err != nil {
+			return err
+		}
+		s.queries = append(s.queries, actualSQL)
+		return nil
+	})
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(matcher))
+	s.Require().NoError(err)
+	s.db = db
+	s.mock = mock
+
+	origin := newPendingJob(1, "resnet")
+	origin.Status = "succeeded"
+	origin.Hyperparameters = `{"lr":0.1,"epochs":10}`
+	s.jobModel = newFakeTrainingJobsModel(origin)
+	s.checkpoints = &fakeCheckpointsModel{}
+	s.svcCtx = &svc.ServiceContext{
+		DBManager:                   database.NewMySQLManagerWithDB(db, config.MySQLConfig{}),
+		VtTrainingJobsModel:         s.jobModel,
+		VtTrainingCheckpointsModel:  s.checkpoints,
+		VtTrainingJobRelationsModel: &fakeRelationsModel{},
+	}
+}
+
+func (s *TestCloneTrainingJobSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+	s.db.Close()
+}
+
+// expectCreate 期望在一个事务中写入作业及其cloned_from关联，返回作业INSERT的参数和关联元数据
+func (s *TestCloneTrainingJobSuite) expectCreate(jobId, originId int64) ([]*recordedArg, *recordedArg) {
+	jobArgs := make([]*recordedArg, 61)
+	args := make([]driver.Value, len(jobArgs))
+	for i := range jobArgs {
+		jobArgs[i] = &recordedArg{}
+		args[i] = jobArgs[i]
+	}
+	metadata := &recordedArg{}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(`INSERT INTO vt_training_jobs \(`).WithArgs(args...).WillReturnResult(sqlmock.NewResult(jobId, 1))
+	s.mock.ExpectExec(`INSERT INTO vt_training_job_relations`).
+		WithArgs(jobId, scheduler.JobEntityType, originId, scheduler.ClonedFromRelation, metadata).
+		WillReturnResult(sqlmock.NewResult(jobId*10, 1))
+	s.mock.ExpectCommit()
+	return jobArgs, metadata
+}
+
+// jobColumn 读取最近一次创建作业时写入指定列的值
+func (s *TestCloneTrainingJobSuite) jobColumn(args []*recordedArg, column string) driver.Value {
+	for i := len(s.queries) - 1; i >= 0; i-- {
+		match := jobInsertColumns.FindStringSubmatch(s.queries[i])
+		if match == nil {
+			continue
+		}
+		for j, name := range strings.Split(match[1], ",") {
+			if strings.TrimSpace(name) == column {
+				return args[j].value
+			}
+		}
+	}
+	s.FailNow("未找到作业列", column)
+	return nil
+}
+
+func (s *TestCloneTrainingJobSuite) clone(req *types.CloneTrainingJobReq) (*types.CloneTrainingJobResp, error) {
+	return training.NewCloneTrainingJobLogic(context.Background(), s.svcCtx).CloneTrainingJob(req)
+}
+
+// TestCloneNameSequence 未指定名称时按源作业生成序号名称，克隆已克隆的作业时去掉原有后缀后取下一个序号
+func (s *TestCloneTrainingJobSuite) TestCloneNameSequence() {
+	args, _ := s.expectCreate(2, 1)
+	resp, err := s.clone(&types.CloneTrainingJobReq{Id: 1})
+	s.Require().NoError(err)
+	s.Equal(int64(2), resp.Id)
+	s.Equal("resnet-clone-1", resp.Name)
+	s.Equal("resnet-clone-1", s.jobColumn(args, "name"))
+
+	cloned := newPendingJob(2, "resnet-clone-1")
+	s.jobModel.jobs[2] = cloned
+	args, _ = s.expectCreate(3, 2)
+	resp, err = s.clone(&types.CloneTrainingJobReq{Id: 2})
+	s.Require().NoError(err)
+	s.Equal("resnet-clone-2", resp.Name)
+	s.Equal("resnet-clone-2", s.jobColumn(args, "name"))
+
+	// 指定的名称直接使用
+	s.expectCreate(4, 1)
+	resp, err = s.clone(&types.CloneTrainingJobReq{Id: 1, Name: "resnet-lr-sweep"})
+	s.Require().NoError(err)
+	s.Equal("resnet-lr-sweep", resp.Name)
+}
+
+// TestCloneNameTruncatedOnRuneBoundary 名称超过128个字符时按字符截断源作业名称，不截断多字节字符
+func (s *TestCloneTrainingJobSuite) TestCloneNameTruncatedOnRuneBoundary() {
+	s.jobModel.jobs[1].Name = strings.Repeat("训", 125)
+
+	s.expectCreate(2, 1)
+	resp, err := s.clone(&types.CloneTrainingJobReq{Id: 1})
+	s.Require().NoError(err)
+	s.True(utf8.ValidString(resp.Name))
+	s.Equal(128, utf8.RuneCountInString(resp.Name))
+	s.Equal(strings.Repeat("训", 120)+"-clone-1", resp.Name)
+}
+
+// TestCloneOverridesEmbeddedHyperparameters 覆盖项中的对象逐项合并到以JSON字符串保存的hyperparameters，并记录在关联元数据中
+func (s *TestCloneTrainingJobSuite) TestCloneOverridesEmbeddedHyperparameters() {
+	args, metadata := s.expectCreate(2, 1)
+	_, err := s.clone(&types.CloneTrainingJobReq{Id: 1, Overrides: map[string]interface{}{
+		"hyperparameters": map[string]interface{}{"lr": 0.01, "warmup": 500},
+		"gpuCount":        2,
+	}})
+	s.Require().NoError(err)
+
+	s.JSONEq(`{"lr":0.01,"epochs":10,"warmup":500}`, s.jobColumn(args, "hyperparameters").(string))
+	s.Equal(int64(2), s.jobColumn(args, "gpu_count"))
+	s.Equal("pytorch", s.jobColumn(args, "framework"))
+	s.JSONEq(`{"overrides":{"hyperparameters":{"lr":0.01,"warmup":500},"gpuCount":2}}`, metadata.value.(string))
+}
+
+// TestCloneFromBestCheckpoint 从源作业的最佳检查点开始训练，检查点ID写入作业和关联元数据
+func (s *TestCloneTrainingJobSuite) TestCloneFromBestCheckpoint() {
+	_, err := s.checkpoints.Insert(&model.VtTrainingCheckpoints{JobId: 1, CheckpointName: "step-100", Step: 100, Status: "saved", StoragePath: "/ckpt/resnet/step-100.pt"})
+	s.Require().NoError(err)
+	best, err := s.checkpoints.Insert(&model.VtTrainingCheckpoints{JobId: 1, CheckpointName: "step-50", Step: 50, Status: "saved", IsBest: true, StoragePath: "/ckpt/resnet/step-50.pt"})
+	s.Require().NoError(err)
+	bestId, _ := best.LastInsertId()
+
+	args, metadata := s.expectCreate(2, 1)
+	resp, err := s.clone(&types.CloneTrainingJobReq{Id: 1, FromBestCheckpoint: true})
+	s.Require().NoError(err)
+	s.Equal(bestId, resp.ResumeCheckpointId)
+	s.Equal(bestId, s.jobColumn(args, "resume_checkpoint_id"))
+	s.Equal("/ckpt/resnet/step-50.pt", s.jobColumn(args, "resume_checkpoint_path"))
+	s.JSONEq(`{"checkpointId":2}`, metadata.value.(string))
+}
+
+// TestCloneFromBestCheckpointWithoutCheckpoint 源作业没有可用检查点时返回参数错误，不写入任何记录
+func (s *TestCloneTrainingJobSuite) TestCloneFromBestCheckpointWithoutCheckpoint() {
+	_, err := s.clone(&types.CloneTrainingJobReq{Id: 1, FromBestCheckpoint: true})
+	bizErr := bizerrors.GetBizError(err)
+	s.Require().NotNil(bizErr, "%v", err)
+	s.Equal(bizerrors.ErrCodeCheckpointInvalid, bizErr.Code)
+}
+
+// TestCloneRelationFailureRollsBack 关联写入失败时作业随事务回滚，不会留下没有cloned_from关联的克隆作业
+func (s *TestCloneTrainingJobSuite) TestCloneRelationFailureRollsBack() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(`INSERT INTO vt_training_jobs \(`).WillReturnResult(sqlmock.NewResult(2, 1))
+	s.mock.ExpectExec(`INSERT INTO vt_training_job_relations`).
+		WithArgs(int64(2), scheduler.JobEntityType, int64(1), scheduler.ClonedFromRelation, sqlmock.AnyArg()).
+		WillReturnError(errors.New("lock wait timeout"))
+	s.mock.ExpectRollback()
+
+	_, err := s.clone(&types.CloneTrainingJobReq{Id: 1})
+	s.Error(err)
+}
+
+func TestRunCloneTrainingJobTests(t *testing.T) {
+	suite.Run(t, new(TestCloneTrainingJobSuite))
+}
@@ -0,0 +1,184 @@
+package test
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"api/model"
+	"api/pkg/scheduler"
+	"api/pkg/volcano"
+
+	"github.com/stretchr/testify/suite"
+)
+
+var updateGolden = flag.Bool("update", false, "重新生成golden文件")
+
+// launchGolden golden文件中记录的任务启动配置
+type launchGolden struct {
+	Plugins map[string][]string `json:"plugins"`
+	Tasks   []launchGoldenTask  `json:"tasks"`
+}
+
+type launchGoldenTask struct {
+	Name     string            `json:"name"`
+	Replicas int32             `json:"replicas"`
+	Command  []string          `json:"command"`
+	Args     []string          `json:"args"`
+	Env      map[string]string `json:"env"`
+	Ports    []int32           `json:"ports,omitempty"`
+}
+
+type TestDistributedLaunchSuite struct {
+	suite.Suite
+}
+
+// newDistributedJob 构造分布式训练作业
+func newDistributedJob(name, framework, jobType string, masters, workers, ps, gpus int) *model.VtTrainingJobs {
+	return &model.VtTrainingJobs{
+		Id:          42,
+		Name:        name,
+		JobType:     jobType,
+		Framework:   framework,
+		EntryPoint:  "train.py",
+		Image:       "registry.local/train:latest",
+		CpuCores:    "8",
+		MemoryGb:    "32",
+		GpuCount:    gpus,
+		MasterCount: masters,
+		WorkerCount: workers,
+		PsCount:     ps,
+		QueueName:   "default",
+		EnvVars:     `{"EPOCHS":"10"}`,
+	}
+}
+
+// buildLaunch 生成作业的Volcano规格并提取启动配置
+func (s *TestDistributedLaunchSuite) buildLaunch(job *model.VtTrainingJobs) launchGolden {
+	spec, err := scheduler.BuildTrainingJobSpec(job, "training")
+	s.Require().NoError(err)
+	vcSpec := volcano.NewJobManager(nil).BuildVolcanoJobSpec(spec)
+
+	golden := launchGolden{Plugins: vcSpec.Plugins}
+	for _, task := range vcSpec.Tasks {
+		container := task.Template.Spec.Containers[0]
+		t := launchGoldenTask{
+			Name:     task.Name,
+			Replicas: task.Replicas,
+			Command:  container.Command,
+			Args:     container.Args,
+			Env:      make(map[string]string),
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				t.Env[env.Name] = env.Value
+			}
+		}
+		for _, port := range container.Ports {
+			t.Ports = append(t.Ports, port.ContainerPort)
+		}
+		golden.Tasks = append(golden.Tasks, t)
+	}
+	return golden
+}
+
+// assertGolden 与testdata中的golden文件比较，-update时重新生成
+func (s *TestDistributedLaunchSuite) assertGolden(name string, golden launchGolden) {
+	data, err := json.MarshalIndent(golden, "", "  ")
+	s.Require().NoError(err)
+	data = append(data, '\n')
+
+	path := filepath.Join("testdata", "distributed", name+".golden.json")
+	if *updateGolden {
+		s.Require().NoError(os.MkdirAll(filepath.Dir(path), 0755))
+		s.Require().NoError(os.WriteFile(path, data, 0644))
+	}
+	expected, err := os.ReadFile(path)
+	s.Require().NoError(err, "golden文件不存在，使用 go test ./test -run TestRunDistributedLaunchTests -update 生成")
+	s.Equal(string(expected), string(data))
+}
+
+// runBootstrap 以指定的VC_TASK_INDEX执行任务的引导脚本，返回执行用户命令时的环境变量
+func (s *TestDistributedLaunchSuite) runBootstrap(task launchGoldenTask, index string) map[string]string {
+	s.Require().Len(task.Command, 4, "任务需要引导脚本")
+	cmd := exec.Command(task.Command[0], task.Command[1], task.Command[2], task.Command[3], "env")
+	cmd.Env = []string{"PATH=" + os.Getenv("PATH"), "VC_TASK_INDEX=" + index}
+	for key, value := range task.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	output, err := cmd.Output()
+	s.Require().NoError(err)
+
+	env := make(map[string]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		if key, value, ok := strings.Cut(line, "="); ok {
+			env[key] = value
+		}
+	}
+	return env
+}
+
+// TestGoldenSpecs 各框架的分布式启动配置与golden文件一致
+func (s *TestDistributedLaunchSuite) TestGoldenSpecs() {
+	elastic := newDistributedJob("llama-elastic", "pytorch", "distributed", 1, 3, 0, 8)
+	elastic.MinAvailable = 2
+
+	cases := map[string]*model.VtTrainingJobs{
+		"pytorch_ddp":            newDistributedJob("resnet-ddp", "pytorch", "distributed", 1, 2, 0, 4),
+		"pytorch_elastic":        elastic,
+		"tensorflow_multiworker": newDistributedJob("bert-mwms", "tensorflow", "distributed", 1, 2, 0, 1),
+		"tensorflow_ps":          newDistributedJob("wide-deep", "tensorflow", "parameter_server", 1, 2, 2, 0),
+		"horovod":                newDistributedJob("hvd-resnet", "pytorch", "horovod", 1, 2, 0, 4),
+		"paddle_collective":      newDistributedJob("ernie", "paddle", "distributed", 1, 1, 0, 8),
+		"paddle_ps":              newDistributedJob("ctr-dnn", "paddle", "parameter_server", 1, 2, 1, 0),
+	}
+	for name, job := range cases {
+		s.Run(name, func() {
+			s.assertGolden(name, s.buildLaunch(job))
+		})
+	}
+}
+
+// TestPyTorchRanks worker的节点序号在master之后
+func (s *TestDistributedLaunchSuite) TestPyTorchRanks() {
+	launch := s.buildLaunch(newDistributedJob("resnet-ddp", "pytorch", "distributed", 1, 2, 0, 4))
+
+	master := s.runBootstrap(launch.Tasks[0], "0")
+	s.Equal("0", master["RANK"])
+	s.Equal("3", master["WORLD_SIZE"])
+	s.Equal("resnet-ddp-42-master-0.resnet-ddp-42", master["MASTER_ADDR"])
+
+	worker := s.runBootstrap(launch.Tasks[1], "1")
+	s.Equal("2", worker["RANK"])
+	s.Equal("2", worker["PET_NODE_RANK"])
+	s.Equal("10", worker["EPOCHS"], "用户环境变量保留")
+}
+
+// TestTFConfigPerReplica 每个副本的TF_CONFIG包含完整集群和自身序号
+func (s *TestDistributedLaunchSuite) TestTFConfigPerReplica() {
+	launch := s.buildLaunch(newDistributedJob("wide-deep", "tensorflow", "parameter_server", 1, 2, 2, 0))
+
+	var config struct {
+		Cluster map[string][]string `json:"cluster"`
+		Task    struct {
+			Type  string `json:"type"`
+			Index int    `json:"index"`
+		} `json:"task"`
+	}
+	s.Require().Equal("ps", launch.Tasks[2].Name)
+	env := s.runBootstrap(launch.Tasks[2], "1")
+	s.Require().NoError(json.Unmarshal([]byte(env["TF_CONFIG"]), &config))
+	s.Equal("ps", config.Task.Type)
+	s.Equal(1, config.Task.Index)
+	s.Equal([]string{"wide-deep-42-ps-0.wide-deep-42:2222", "wide-deep-42-ps-1.wide-deep-42:2222"}, config.Cluster["ps"])
+	s.Len(config.Cluster["chief"], 1)
+	s.Len(config.Cluster["worker"], 2)
+}
+
+func TestRunDistributedLaunchTests(t *testing.T) {
+	suite.Run(t, new(TestDistributedLaunchSuite))
+}
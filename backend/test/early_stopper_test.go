@@ -0,0 +1,153 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"api/model"
+	"api/pkg/scheduler"
+	"api/pkg/volcano"
+
+	"github.com/stretchr/testify/suite"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	vcjob "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+	vcfake "volcano.sh/apis/pkg/client/clientset/versioned/fake"
+)
+
+// TestEarlyStopperSuite 提前停止测试套件
+type TestEarlyStopperSuite struct {
+	suite.Suite
+	vcClient    *vcfake.Clientset
+	jobModel    *fakeTrainingJobsModel
+	metrics     *fakeMetricsModel
+	checkpoints *fakeCheckpointsModel
+	machine     *scheduler.JobStateMachine
+	stopper     *scheduler.EarlyStopper
+	now         time.Time
+}
+
+// SetupTest 请求停止后最多等待10分钟保存最终检查点
+func (s *TestEarlyStopperSuite) SetupTest() {
+	s.vcClient = vcfake.NewSimpleClientset()
+	s.jobModel = newFakeTrainingJobsModel()
+	s.metrics = &fakeMetricsModel{}
+	s.checkpoints = &fakeCheckpointsModel{}
+	s.now = time.Now()
+
+	client := volcano.NewClientWithClientsets(s.vcClient, k8sfake.NewSimpleClientset(), testNamespace)
+	s.machine = scheduler.NewJobStateMachine(s.jobModel, s.jobModel.transitions, client)
+	s.stopper = scheduler.NewEarlyStopper(s.jobModel, s.metrics, s.checkpoints, s.machine, scheduler.EarlyStopConfig{
+		GracePeriod: 10 * time.Minute,
+	})
+	s.machine.SetEarlyStopper(s.stopper)
+}
+
+// newRunningJob 创建监控val_loss、patience为2的运行中作业及其Volcano作业
+func (s *TestEarlyStopperSuite) newRunningJob(id int64, name string) *model.VtTrainingJobs {
+	job := newPendingJob(id, name)
+	startTime := s.now.Add(-time.Hour)
+	job.Status = "running"
+	job.Namespace = testNamespace
+	job.VolcanoJobName = scheduler.BuildVolcanoJobName(job)
+	job.StartTime = &startTime
+	job.TrainingConfig = `{"checkpoint":{"monitor_metric":"val_loss","patience":2,"min_delta":0.01}}`
+	s.jobModel.jobs[id] = job
+
+	_, err := s.vcClient.BatchV1alpha1().Jobs(testNamespace).Create(context.Background(), &vcjob.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: job.VolcanoJobName, Namespace: testNamespace},
+	}, metav1.CreateOptions{})
+	s.Require().NoError(err)
+	return job
+}
+
+// check 在now之后offset时刻执行一轮检查
+func (s *TestEarlyStopperSuite) check(offset time.Duration) int {
+	stopped, err := s.stopper.CheckOnce(s.now.Add(offset))
+	s.Require().NoError(err)
+	return stopped
+}
+
+func (s *TestEarlyStopperSuite) volcanoJobDeleted(name string) bool {
+	_, err := s.vcClient.BatchV1alpha1().Jobs(testNamespace).Get(context.Background(), name, metav1.GetOptions{})
+	return apierrors.IsNotFound(err)
+}
+
+// TestStopAfterFinalCheckpoint 连续patience次评估没有改善超过min_delta时请求停止，保存最终检查点后停止作业
+func (s *TestEarlyStopperSuite) TestStopAfterFinalCheckpoint() {
+	job := s.newRunningJob(1, "llama")
+	s.checkpoints.Insert(&model.VtTrainingCheckpoints{JobId: 1, CheckpointName: "step-200", Status: "saved"})
+	s.metrics.add(1, "val_loss", 1.0, 0.8, 0.795)
+
+	s.Equal(0, s.check(0))
+	_, requested := s.stopper.StopRequested(job)
+	s.False(requested)
+
+	s.metrics.add(1, "val_loss", 0.81)
+	s.Equal(0, s.check(time.Minute))
+	reason, requested := s.stopper.StopRequested(job)
+	s.True(requested)
+	s.Contains(reason, "val_loss")
+	s.Equal("running", s.jobModel.get(1).Status)
+	s.False(s.volcanoJobDeleted(job.VolcanoJobName))
+
+	s.checkpoints.Insert(&model.VtTrainingCheckpoints{JobId: 1, CheckpointName: "final", CheckpointType: "final", Status: "saved"})
+	s.Equal(1, s.check(2*time.Minute))
+	stopped := s.jobModel.get(1)
+	s.Equal("succeeded", stopped.Status)
+	s.Equal(scheduler.FailureReasonEarlyStopped, stopped.FailureReason)
+	s.NotNil(stopped.EndTime)
+	s.True(s.volcanoJobDeleted(job.VolcanoJobName))
+}
+
+// TestStopAfterGracePeriod 等待时长内没有保存检查点时直接停止作业
+func (s *TestEarlyStopperSuite) TestStopAfterGracePeriod() {
+	job := s.newRunningJob(2, "bert")
+	s.metrics.add(2, "val_loss", 0.5, 0.6, 0.7)
+
+	s.Equal(0, s.check(0))
+	s.Equal(0, s.check(5*time.Minute))
+	s.Equal(1, s.check(10*time.Minute))
+	s.Equal(scheduler.FailureReasonEarlyStopped, s.jobModel.get(2).FailureReason)
+	s.True(s.volcanoJobDeleted(job.VolcanoJobName))
+}
+
+// TestKeepImproving 指标持续改善或未配置patience的作业不会被停止
+func (s *TestEarlyStopperSuite) TestKeepImproving() {
+	s.newRunningJob(3, "gpt")
+	s.metrics.add(3, "val_loss", 1.0, 0.9, 0.95, 0.85, 0.84, 0.7)
+	unlimited := s.newRunningJob(4, "t5")
+	unlimited.TrainingConfig = `{"checkpoint":{"monitor_metric":"val_loss"}}`
+	s.metrics.add(4, "val_loss", 0.1, 0.2, 0.3, 0.4)
+
+	s.Equal(0, s.check(time.Hour))
+	s.Equal("running", s.jobModel.get(3).Status)
+	s.Equal("running", s.jobModel.get(4).Status)
+}
+
+// TestJobExitsAfterRequest 训练脚本收到停止请求后自行退出，作业完成时同样记为提前停止
+func (s *TestEarlyStopperSuite) TestJobExitsAfterRequest() {
+	job := s.newRunningJob(5, "vit")
+	job.TrainingConfig = `{"checkpoint":{"monitor_metric":"accuracy","patience":1}}`
+	s.metrics.add(5, "accuracy", 0.7, 0.9, 0.85)
+
+	s.Equal(0, s.check(0))
+	_, requested := s.stopper.StopRequested(job)
+	s.Require().True(requested)
+
+	status, err := s.machine.Apply(s.jobModel.get(5), scheduler.JobTransition{Action: scheduler.JobActionSucceed, Reason: "Volcano作业运行完成"})
+	s.Require().NoError(err)
+	s.Equal("succeeded", status)
+	s.Equal(scheduler.FailureReasonEarlyStopped, s.jobModel.get(5).FailureReason)
+
+	// 结束的作业不再跟踪
+	s.Equal(0, s.check(time.Minute))
+	_, requested = s.stopper.StopRequested(job)
+	s.False(requested)
+}
+
+func TestEarlyStopperTests(t *testing.T) {
+	suite.Run(t, new(TestEarlyStopperSuite))
+}
@@ -0,0 +1,163 @@
+package test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"api/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+// TestGpuAllocationModelSuite 用sqlmock校验分配模型在事务中的加锁、写入和更新顺序，语句引用的列按建表脚本校验
+type TestGpuAllocationModelSuite struct {
+	suite.Suite
+	db    *sql.DB
+	mock  sqlmock.Sqlmock
+	model model.VtGpuDeviceAllocationsModel
+	now   time.Time
+}
+
+func (s *TestGpuAllocationModelSuite) SetupTest() {
+	matcher, err := schemaMatcher()
+	s.Require().NoError(err)
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(matcher))
+	s.Require().NoError(err)
+	s.db = db
+	s.mock = mock
+	s.model = model.NewVtGpuDeviceAllocationsModel(db)
+	s.now = time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)
+}
+
+func (s *TestGpuAllocationModelSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+	s.db.Close()
+}
+
+// expectLock 期望按设备ID顺序以FOR UPDATE锁定设备，并返回给定状态
+func (s *TestGpuAllocationModelSuite) expectLock(statuses map[int64]string, ids ...int64) {
+	rows := sqlmock.NewRows([]string{"id", "node_id", "status", "health_status", "sharing_mode", "share_capacity", "mig_devices", "memory_total_mb"})
+	args := make([]driver.Value, 0, len(ids))
+	for _, id := range ids {
+		rows.AddRow(id, 1, statuses[id], model.GpuHealthHealthy, model.GpuSharingModeExclusive, 1, "", 81920)
+		args = append(args, id)
+	}
+	s.mock.ExpectQuery(`SELECT id, node_id, status, health_status, .* FROM vt_gpu_devices WHERE id IN \(.*\) ORDER BY id FOR UPDATE`).
+		WithArgs(args...).WillReturnRows(rows)
+}
+
+// expectRefresh 期望重新统计节点和集群的可用GPU数
+func (s *TestGpuAllocationModelSuite) expectRefresh() {
+	s.mock.ExpectExec(`UPDATE vt_gpu_nodes SET available_gpus`).
+		WithArgs(int64(1), model.GpuDeviceStatusAvailable, int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(`UPDATE vt_gpu_clusters SET available_gpus`).
+		WithArgs(model.GpuNodeStatusOnline, int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+// TestAllocateLocksThenMarksOccupied 先锁定全部设备，再逐个写入分配记录并将设备标记为occupied，最后提交
+func (s *TestGpuAllocationModelSuite) TestAllocateLocksThenMarksOccupied() {
+	s.mock.ExpectBegin()
+	s.expectLock(map[int64]string{1: model.GpuDeviceStatusAvailable, 2: model.GpuDeviceStatusAvailable}, 1, 2)
+	for i, id := range []int64{2, 1} {
+		allocationId := int64(100 + i)
+		s.mock.ExpectExec(`INSERT INTO vt_gpu_device_allocations`).
+			WithArgs(id, model.GpuAllocationEntityTrainingJob, int64(5), model.GpuAllocationTypeExclusive, "", "", 0, s.now,
+				3600, 0, model.GpuAllocationStatusActive, "").
+			WillReturnResult(sqlmock.NewResult(allocationId, 1))
+		s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE vt_gpu_devices SET status = ?, allocation_id = ?`)).
+			WithArgs("occupied", allocationId, int64(5), int64(9), s.now, id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	s.expectRefresh()
+	s.mock.ExpectCommit()
+
+	allocations, err := s.model.Allocate(&model.GpuDeviceAllocationRequest{
+		DeviceIds: []int64{2, 1}, JobId: 5, UserId: 9, ExpectedDurationSeconds: 3600, AllocatedAt: s.now,
+	})
+	s.Require().NoError(err)
+	s.Require().Len(allocations, 2)
+	s.Equal(int64(100), allocations[0].Id)
+	s.Equal(int64(2), allocations[0].DeviceId)
+}
+
+// TestAllocateAfterConcurrentCommit 并发分配同一设备时，后加锁的事务读到先提交的事务写入的occupied，不写入任何记录并回滚
+func (s *TestGpuAllocationModelSuite) TestAllocateAfterConcurrentCommit() {
+	s.mock.ExpectBegin()
+	s.expectLock(map[int64]string{1: model.GpuDeviceStatusAvailable, 2: model.GpuDeviceStatusOccupied}, 1, 2)
+	s.mock.ExpectRollback()
+
+	_, err := s.model.Allocate(&model.GpuDeviceAllocationRequest{DeviceIds: []int64{1, 2}, JobId: 6, AllocatedAt: s.now})
+	var unavailable *model.GpuDeviceUnavailableError
+	s.Require().True(errors.As(err, &unavailable))
+	s.Equal(int64(2), unavailable.DeviceId)
+	s.Equal(model.GpuDeviceStatusOccupied, unavailable.Status)
+}
+
+// TestReleaseRestoresOccupiedDevice 释放时锁定分配记录，只把仍为occupied的设备恢复为available
+func (s *TestGpuAllocationModelSuite) TestReleaseRestoresOccupiedDevice() {
+	columns := []string{"id", "device_id", "entity_type", "entity_id", "allocation_type", "sharing_mode", "mig_profile", "memory_mb",
+		"allocated_at", "released_at", "expected_duration_seconds", "priority", "status", "metadata", "created_at", "updated_at"}
+	releasedAt := s.now.Add(time.Hour)
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(`SELECT .* FROM vt_gpu_device_allocations WHERE id = \? FOR UPDATE`).WithArgs(int64(100)).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(int64(100), int64(2), model.GpuAllocationEntityTrainingJob, int64(5),
+			model.GpuAllocationTypeExclusive, "", "", 0, s.now, nil, 3600, 0, model.GpuAllocationStatusActive, "", s.now, s.now))
+	s.mock.ExpectExec(`UPDATE vt_gpu_device_allocations SET status = \?, released_at = \?`).
+		WithArgs(model.GpuAllocationStatusExpired, releasedAt, int64(100)).WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(`UPDATE vt_gpu_devices SET status = CASE WHEN status = \? THEN \? ELSE status END,\s+allocation_id = 0, .*allocated_at = NULL`).
+		WithArgs("occupied", model.GpuDeviceStatusAvailable, int64(2), int64(100)).WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectQuery(`SELECT node_id FROM vt_gpu_devices WHERE id = \?`).WithArgs(int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"node_id"}).AddRow(int64(1)))
+	s.expectRefresh()
+	s.mock.ExpectCommit()
+
+	allocation, err := s.model.Release(100, model.GpuAllocationStatusExpired, releasedAt)
+	s.Require().NoError(err)
+	s.Equal(model.GpuAllocationStatusExpired, allocation.Status)
+}
+
+// TestAllocateSharedCountsActiveUnits 共享分配统计设备上有效的共享单元，未用完时只写入分配记录，不标记设备
+func (s *TestGpuAllocationModelSuite) TestAllocateSharedCountsActiveUnits() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(`SELECT id, node_id, status, health_status, .* FROM vt_gpu_devices WHERE id IN \(.*\) ORDER BY id FOR UPDATE`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "node_id", "status", "health_status", "sharing_mode", "share_capacity", "mig_devices", "memory_total_mb"}).
+			AddRow(int64(1), int64(1), model.GpuDeviceStatusAvailable, model.GpuHealthHealthy, model.GpuSharingModeTimeSlicing, 4, "", 81920))
+	s.mock.ExpectQuery(`SELECT COUNT\(\*\), .* FROM vt_gpu_device_allocations`).
+		WithArgs("", int64(1), model.GpuAllocationStatusActive).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "profile", "memory"}).AddRow(1, 0, 0))
+	s.mock.ExpectExec(`INSERT INTO vt_gpu_device_allocations`).
+		WithArgs(int64(1), model.GpuAllocationEntityTrainingJob, int64(5), model.GpuAllocationTypeShared, model.GpuSharingModeTimeSlicing, "", 0, s.now,
+			0, 0, model.GpuAllocationStatusActive, "").
+		WillReturnResult(sqlmock.NewResult(100, 1))
+	s.expectRefresh()
+	s.mock.ExpectCommit()
+
+	allocations, err := s.model.Allocate(&model.GpuDeviceAllocationRequest{
+		DeviceIds: []int64{1}, JobId: 5, SharingMode: model.GpuSharingModeTimeSlicing, AllocatedAt: s.now,
+	})
+	s.Require().NoError(err)
+	s.Require().Len(allocations, 1)
+	s.Equal(model.GpuAllocationTypeShared, allocations[0].AllocationType)
+}
+
+// TestFindExpired 按分配时间和预期时长查询到期的有效分配
+func (s *TestGpuAllocationModelSuite) TestFindExpired() {
+	s.mock.ExpectQuery(`FROM vt_gpu_device_allocations\s+WHERE status = \? AND expected_duration_seconds > 0`).
+		WithArgs(model.GpuAllocationStatusActive, s.now, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	allocations, err := s.model.FindExpired(s.now, 10)
+	s.Require().NoError(err)
+	s.Empty(allocations)
+}
+
+func TestRunGpuAllocationModelTests(t *testing.T) {
+	suite.Run(t, new(TestGpuAllocationModelSuite))
+}
@@ -0,0 +1,267 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"api/internal/logic/gpu_device"
+	"api/internal/svc"
+	"api/internal/types"
+	"api/model"
+	bizerrors "api/pkg/errors"
+	"api/pkg/scheduler"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestGpuAllocationSuite struct {
+	suite.Suite
+	devices     *fakeGpuDevicesModel
+	allocations *fakeGpuAllocationsModel
+	svcCtx      *svc.ServiceContext
+}
+
+func (s *TestGpuAllocationSuite) SetupTest() {
+	clusters := &fakeGpuClustersModel{}
+	nodes := &fakeGpuNodesModel{}
+	s.devices = &fakeGpuDevicesModel{}
+	s.allocations = &fakeGpuAllocationsModel{devices: s.devices}
+
+	_, err := clusters.Insert(&model.VtGpuClusters{Name: "gpu-a", ClusterType: model.GpuClusterTypeK8s, Status: model.GpuClusterStatusActive})
+	s.Require().NoError(err)
+	_, err = nodes.Insert(&model.VtGpuNodes{ClusterId: 1, Name: "gpu-1", Status: model.GpuNodeStatusOnline})
+	s.Require().NoError(err)
+	for i := 0; i < 4; i++ {
+		_, err := s.devices.Insert(&model.VtGpuDevices{ClusterId: 1, NodeId: 1, DeviceIndex: i, DeviceName: fmt.Sprintf("gpu-1-gpu%d", i),
+			DeviceUuid: fmt.Sprintf("GPU-%d", i), Status: model.GpuDeviceStatusAvailable, HealthStatus: model.GpuHealthHealthy})
+		s.Require().NoError(err)
+	}
+
+	s.svcCtx = &svc.ServiceContext{
+		VtTrainingJobsModel: newFakeTrainingJobsModel(
+			&model.VtTrainingJobs{Id: 5, Name: "llama", Status: "queued"},
+			&model.VtTrainingJobs{Id: 6, Name: "bert", Status: "queued"},
+		),
+		VtGpuClustersModel:          clusters,
+		VtGpuNodesModel:             nodes,
+		VtGpuDevicesModel:           s.devices,
+		VtGpuDeviceAllocationsModel: s.allocations,
+	}
+}
+
+func (s *TestGpuAllocationSuite) allocate(req *types.AllocateGpuDeviceReq) (*types.AllocateGpuDeviceResp, error) {
+	if req.UserId == 0 {
+		req.UserId = 9
+	}
+	if req.QueueName == "" {
+		req.QueueName = "default"
+	}
+	return gpu_device.NewAllocateGpuDeviceLogic(context.Background(), s.svcCtx).AllocateGpuDevice(req)
+}
+
+func (s *TestGpuAllocationSuite) release(id int64) error {
+	_, err := gpu_device.NewReleaseGpuDeviceLogic(context.Background(), s.svcCtx).ReleaseGpuDevice(&types.ReleaseGpuDeviceReq{ID: id})
+	return err
+}
+
+func (s *TestGpuAllocationSuite) device(id int64) *model.VtGpuDevices {
+	device, err := s.devices.FindOne(id)
+	s.Require().NoError(err)
+	return device
+}
+
+func (s *TestGpuAllocationSuite) httpStatus(err error) int {
+	bizErr := bizerrors.GetBizError(err)
+	s.Require().NotNil(bizErr, "%v", err)
+	return bizErr.GetHTTPStatus()
+}
+
+// TestAllocateAndRelease 分配写入分配记录并将设备标记为已分配，释放后设备恢复可用，重复释放返回冲突
+func (s *TestGpuAllocationSuite) TestAllocateAndRelease() {
+	resp, err := s.allocate(&types.AllocateGpuDeviceReq{DeviceIds: []int64{2, 1}, JobId: 5, WorkspaceId: 3, Priority: 10})
+	s.Require().NoError(err)
+	s.Require().Len(resp.Allocations, 2)
+	info := resp.Allocations[0]
+	s.Equal(int64(2), info.DeviceId)
+	s.Equal("GPU-1", info.DeviceUUID)
+	s.Equal("gpu-1-gpu1", info.DeviceName)
+	s.Equal("gpu-1", info.NodeName)
+	s.Equal("gpu-a", info.ClusterName)
+	s.Equal("llama", info.JobName)
+	s.Equal(int64(9), info.UserId)
+	s.Equal(int64(3), info.WorkspaceId)
+	s.Equal("default", info.QueueName)
+	s.Equal(model.GpuAllocationStatusActive, info.Status)
+	s.Equal(10, info.Priority)
+	s.Nil(info.ExpiresAt)
+
+	device := s.device(2)
+	s.Equal(model.GpuDeviceStatusOccupied, device.Status)
+	s.Equal(info.ID, device.AllocationId)
+	s.Equal(int64(5), device.AllocatedJobId)
+	s.Equal(int64(9), device.AllocatedUserId)
+
+	// 任一设备不可用时整个请求失败，其余设备不受影响
+	_, err = s.allocate(&types.AllocateGpuDeviceReq{DeviceIds: []int64{3, 2}, JobId: 6})
+	s.Equal(http.StatusConflict, s.httpStatus(err))
+	s.Equal(model.GpuDeviceStatusAvailable, s.device(3).Status)
+
+	s.Require().NoError(s.release(info.ID))
+	device = s.device(2)
+	s.Equal(model.GpuDeviceStatusAvailable, device.Status)
+	s.Zero(device.AllocationId)
+	s.Equal(model.GpuDeviceStatusOccupied, s.device(1).Status)
+
+	s.Equal(http.StatusConflict, s.httpStatus(s.release(info.ID)))
+	s.Equal(http.StatusNotFound, s.httpStatus(s.release(100)))
+}
+
+// TestInvalidRequests 设备列表为空或重复、设备或作业不存在、设备不健康以及过期时间早于当前时间的请求被拒绝
+func (s *TestGpuAllocationSuite) TestInvalidRequests() {
+	past := time.Now().Add(-time.Minute).Format(time.RFC3339)
+	unhealthy := s.device(4)
+	unhealthy.HealthStatus = "critical"
+	s.Require().NoError(s.devices.Update(unhealthy))
+
+	cases := []struct {
+		req    *types.AllocateGpuDeviceReq
+		status int
+	}{
+		{&types.AllocateGpuDeviceReq{JobId: 5}, http.StatusBadRequest},
+		{&types.AllocateGpuDeviceReq{DeviceIds: []int64{1, 1}, JobId: 5}, http.StatusBadRequest},
+		{&types.AllocateGpuDeviceReq{DeviceIds: []int64{1}, JobId: 5, ExpiresAt: &past}, http.StatusBadRequest},
+		{&types.AllocateGpuDeviceReq{DeviceIds: []int64{1}, JobId: 5, ExpectedDurationSeconds: -1}, http.StatusBadRequest},
+		{&types.AllocateGpuDeviceReq{DeviceIds: []int64{1}, JobId: 404}, http.StatusNotFound},
+		{&types.AllocateGpuDeviceReq{DeviceIds: []int64{1, 99}, JobId: 5}, http.StatusNotFound},
+		{&types.AllocateGpuDeviceReq{DeviceIds: []int64{4}, JobId: 5}, http.StatusConflict},
+	}
+	for _, c := range cases {
+		_, err := s.allocate(c.req)
+		s.Equal(c.status, s.httpStatus(err), "%+v", c.req)
+	}
+	s.Empty(s.allocations.active())
+	s.Equal(model.GpuDeviceStatusAvailable, s.device(1).Status)
+}
+
+// TestLeaseExpiry 超过预期使用时长的分配由租约检查标记为expired并释放设备，之后手动释放返回冲突
+func (s *TestGpuAllocationSuite) TestLeaseExpiry() {
+	expiresAt := time.Now().Add(time.Hour).Format(time.RFC3339)
+	resp, err := s.allocate(&types.AllocateGpuDeviceReq{DeviceIds: []int64{1}, JobId: 5, ExpectedDurationSeconds: 60})
+	s.Require().NoError(err)
+	s.Require().NotNil(resp.Allocations[0].ExpiresAt)
+	leased := resp.Allocations[0].ID
+	resp, err = s.allocate(&types.AllocateGpuDeviceReq{DeviceIds: []int64{2}, JobId: 6, ExpiresAt: &expiresAt})
+	s.Require().NoError(err)
+	s.Require().NotNil(resp.Allocations[0].ExpiresAt)
+	_, err = s.allocate(&types.AllocateGpuDeviceReq{DeviceIds: []int64{3}, JobId: 6})
+	s.Require().NoError(err)
+
+	expirer := scheduler.NewGPULeaseExpirer(s.allocations, scheduler.GPULeaseConfig{})
+	expired, err := expirer.CheckOnce(time.Now())
+	s.Require().NoError(err)
+	s.Zero(expired)
+
+	expired, err = expirer.CheckOnce(time.Now().Add(2 * time.Minute))
+	s.Require().NoError(err)
+	s.Equal(1, expired)
+	allocation, err := s.allocations.FindOne(leased)
+	s.Require().NoError(err)
+	s.Equal(model.GpuAllocationStatusExpired, allocation.Status)
+	s.NotNil(allocation.ReleasedAt)
+	s.Equal(model.GpuDeviceStatusAvailable, s.device(1).Status)
+	s.Equal(model.GpuDeviceStatusOccupied, s.device(2).Status)
+	s.Equal(http.StatusConflict, s.httpStatus(s.release(leased)))
+
+	// 不限时长的分配不会过期
+	expired, err = expirer.CheckOnce(time.Now().Add(2 * time.Hour))
+	s.Require().NoError(err)
+	s.Equal(1, expired)
+	s.Equal(model.GpuDeviceStatusOccupied, s.device(3).Status)
+}
+
+// TestConcurrentAllocateRelease 并发分配和释放重叠的设备组，校验逻辑层的分配结果与设备状态保持一致
+// 分配模型为内存实现，不涉及数据库加锁，事务中的SELECT ... FOR UPDATE由TestGpuAllocationModelSuite校验
+func (s *TestGpuAllocationSuite) TestConcurrentAllocateRelease() {
+	const workers, rounds = 16, 50
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+		conflicts int
+		failures  []string
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(seed))
+			for i := 0; i < rounds; i++ {
+				first := int64(r.Intn(4) + 1)
+				deviceIds := []int64{first}
+				if second := int64(r.Intn(4) + 1); second != first {
+					deviceIds = append(deviceIds, second)
+				}
+
+				resp, err := s.allocate(&types.AllocateGpuDeviceReq{DeviceIds: deviceIds, JobId: 5})
+				if err != nil {
+					bizErr := bizerrors.GetBizError(err)
+					mu.Lock()
+					if bizErr != nil && bizErr.Code == bizerrors.ErrCodeGpuDeviceUnavailable {
+						conflicts++
+					} else {
+						failures = append(failures, err.Error())
+					}
+					mu.Unlock()
+					continue
+				}
+
+				for _, allocation := range resp.Allocations {
+					device, _ := s.devices.FindOne(allocation.DeviceId)
+					if device.AllocationId != allocation.ID || device.Status != model.GpuDeviceStatusOccupied {
+						mu.Lock()
+						failures = append(failures, fmt.Sprintf("设备%d被分配%d占用", allocation.DeviceId, device.AllocationId))
+						mu.Unlock()
+					}
+				}
+				for deviceId, count := range s.allocations.active() {
+					if count > 1 {
+						mu.Lock()
+						failures = append(failures, fmt.Sprintf("设备%d有%d个有效分配", deviceId, count))
+						mu.Unlock()
+					}
+				}
+				for _, allocation := range resp.Allocations {
+					if err := s.release(allocation.ID); err != nil {
+						mu.Lock()
+						failures = append(failures, err.Error())
+						mu.Unlock()
+					}
+				}
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}(int64(w))
+	}
+	wg.Wait()
+
+	s.Empty(failures)
+	s.Equal(workers*rounds, succeeded+conflicts)
+	s.Positive(succeeded)
+	s.Empty(s.allocations.active())
+	for id := int64(1); id <= 4; id++ {
+		device := s.device(id)
+		s.Equal(model.GpuDeviceStatusAvailable, device.Status)
+		s.Zero(device.AllocationId)
+	}
+}
+
+func TestRunGpuAllocationTests(t *testing.T) {
+	suite.Run(t, new(TestGpuAllocationSuite))
+}
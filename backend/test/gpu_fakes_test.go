@@ -0,0 +1,529 @@
+package test
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"api/model"
+)
+
+// fakeGpuClustersModel 基于内存的GPU集群模型，仅实现测试用到的方法
+type fakeGpuClustersModel struct {
+	model.VtGpuClustersModel
+
+	mu       sync.Mutex
+	clusters []*model.VtGpuClusters
+}
+
+func (m *fakeGpuClustersModel) Insert(data *model.VtGpuClusters) (sql.Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	copied := *data
+	copied.Id = int64(len(m.clusters) + 1)
+	m.clusters = append(m.clusters, &copied)
+	return fakeResult{id: copied.Id}, nil
+}
+
+func (m *fakeGpuClustersModel) FindOne(id int64) (*model.VtGpuClusters, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, c := range m.clusters {
+		if c.Id == id {
+			copied := *c
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *fakeGpuClustersModel) Update(data *model.VtGpuClusters) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, c := range m.clusters {
+		if c.Id == data.Id {
+			copied := *data
+			m.clusters[i] = &copied
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (m *fakeGpuClustersModel) FindAllByType(clusterType, status string) ([]*model.VtGpuClusters, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []*model.VtGpuClusters
+	for _, c := range m.clusters {
+		if c.ClusterType == clusterType && (status == "" || c.Status == status) {
+			copied := *c
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+// fakeGpuNodesModel 基于内存的GPU节点模型，仅实现测试用到的方法
+type fakeGpuNodesModel struct {
+	model.VtGpuNodesModel
+
+	mu    sync.Mutex
+	nodes []*model.VtGpuNodes
+}
+
+func (m *fakeGpuNodesModel) Insert(data *model.VtGpuNodes) (sql.Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	copied := *data
+	copied.Id = int64(len(m.nodes) + 1)
+	m.nodes = append(m.nodes, &copied)
+	return fakeResult{id: copied.Id}, nil
+}
+
+func (m *fakeGpuNodesModel) FindOne(id int64) (*model.VtGpuNodes, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, n := range m.nodes {
+		if n.Id == id {
+			copied := *n
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *fakeGpuNodesModel) Update(data *model.VtGpuNodes) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, n := range m.nodes {
+		if n.Id == data.Id {
+			copied := *data
+			m.nodes[i] = &copied
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (m *fakeGpuNodesModel) FindAllByClusterId(clusterId int64) ([]*model.VtGpuNodes, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []*model.VtGpuNodes
+	for _, n := range m.nodes {
+		if n.ClusterId == clusterId {
+			copied := *n
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+// byName 按节点名查询节点副本
+func (m *fakeGpuNodesModel) byName(name string) *model.VtGpuNodes {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, n := range m.nodes {
+		if n.Name == name {
+			copied := *n
+			return &copied
+		}
+	}
+	return nil
+}
+
+// fakeGpuDevicesModel 基于内存的GPU设备模型，仅实现测试用到的方法
+type fakeGpuDevicesModel struct {
+	model.VtGpuDevicesModel
+
+	mu      sync.Mutex
+	devices []*model.VtGpuDevices
+}
+
+func (m *fakeGpuDevicesModel) Insert(data *model.VtGpuDevices) (sql.Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	copied := *data
+	copied.Id = int64(len(m.devices) + 1)
+	m.devices = append(m.devices, &copied)
+	return fakeResult{id: copied.Id}, nil
+}
+
+func (m *fakeGpuDevicesModel) FindOne(id int64) (*model.VtGpuDevices, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, d := range m.devices {
+		if d.Id == id {
+			copied := *d
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *fakeGpuDevicesModel) Update(data *model.VtGpuDevices) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, d := range m.devices {
+		if d.Id == data.Id {
+			copied := *data
+			m.devices[i] = &copied
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (m *fakeGpuDevicesModel) UpdateStatus(id int64, status string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, d := range m.devices {
+		if d.Id == id {
+			d.Status = status
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (m *fakeGpuDevicesModel) FindAllByClusterId(clusterId int64) ([]*model.VtGpuDevices, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []*model.VtGpuDevices
+	for _, d := range m.devices {
+		if d.ClusterId == clusterId {
+			copied := *d
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+func (m *fakeGpuDevicesModel) FindAllOnline() ([]*model.VtGpuDevices, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []*model.VtGpuDevices
+	for _, d := range m.devices {
+		if d.Status != model.GpuDeviceStatusOffline {
+			copied := *d
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+func (m *fakeGpuDevicesModel) UpdateTelemetry(data *model.VtGpuDevices) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, d := range m.devices {
+		if d.Id == data.Id {
+			d.DeviceUuid = data.DeviceUuid
+			d.PcieBusId = data.PcieBusId
+			d.MemoryTotalMb = data.MemoryTotalMb
+			d.MemoryFreeMb = data.MemoryFreeMb
+			d.MemoryUsedMb = data.MemoryUsedMb
+			d.PowerDrawW = data.PowerDrawW
+			d.TemperatureC = data.TemperatureC
+			d.UtilizationGpu = data.UtilizationGpu
+			d.UtilizationMem = data.UtilizationMem
+			d.LastHeartbeat = data.LastHeartbeat
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (m *fakeGpuDevicesModel) UpdateHealth(id int64, healthStatus string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, d := range m.devices {
+		if d.Id == id {
+			d.HealthStatus = healthStatus
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+// byNode 按设备索引顺序返回节点的设备副本
+func (m *fakeGpuDevicesModel) byNode(nodeId int64) []*model.VtGpuDevices {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []*model.VtGpuDevices
+	for _, d := range m.devices {
+		if d.NodeId == nodeId {
+			copied := *d
+			result = append(result, &copied)
+		}
+	}
+	return result
+}
+
+// fakeGpuAllocationsModel 基于内存的GPU设备分配模型，供逻辑层测试使用
+// Allocate和Release持有设备表的互斥锁完成检查和修改；数据库事务中的加锁顺序由gpu_allocation_model_test.go覆盖
+type fakeGpuAllocationsModel struct {
+	model.VtGpuDeviceAllocationsModel
+
+	devices     *fakeGpuDevicesModel
+	allocations []*model.VtGpuDeviceAllocations
+}
+
+func (m *fakeGpuAllocationsModel) FindOne(id int64) (*model.VtGpuDeviceAllocations, error) {
+	m.devices.mu.Lock()
+	defer m.devices.mu.Unlock()
+
+	for _, a := range m.allocations {
+		if a.Id == id {
+			copied := *a
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *fakeGpuAllocationsModel) Allocate(req *model.GpuDeviceAllocationRequest) ([]*model.VtGpuDeviceAllocations, error) {
+	m.devices.mu.Lock()
+	defer m.devices.mu.Unlock()
+
+	sharingMode := model.GpuSharingModeExclusive
+	if req.Shared() {
+		sharingMode = req.SharingMode
+	}
+	locked := make([]*model.VtGpuDevices, 0, len(req.DeviceIds))
+	full := make(map[int64]bool)
+	for _, id := range req.DeviceIds {
+		var device *model.VtGpuDevices
+		for _, d := range m.devices.devices {
+			if d.Id == id {
+				device = d
+			}
+		}
+		if device == nil {
+			return nil, &model.GpuDeviceUnavailableError{DeviceId: id}
+		}
+		if device.Status != model.GpuDeviceStatusAvailable || device.HealthStatus != model.GpuHealthHealthy {
+			return nil, &model.GpuDeviceUnavailableError{DeviceId: id, Status: device.Status, HealthStatus: device.HealthStatus}
+		}
+		if device.SharingModeOrDefault() != sharingMode {
+			return nil, &model.GpuDeviceUnavailableError{DeviceId: id, Status: device.Status, HealthStatus: device.HealthStatus,
+				Reason: fmt.Sprintf("设备共享方式为%s，不支持%s分配", device.SharingModeOrDefault(), sharingMode)}
+		}
+		if req.Shared() {
+			used, reason := m.shareUsage(device, req)
+			if reason != "" {
+				return nil, &model.GpuDeviceUnavailableError{DeviceId: id, Status: device.Status, HealthStatus: device.HealthStatus, Reason: reason}
+			}
+			full[id] = used+1 >= device.ShareCapacityOrDefault()
+		}
+		locked = append(locked, device)
+	}
+
+	var result []*model.VtGpuDeviceAllocations
+	for _, device := range locked {
+		allocation := &model.VtGpuDeviceAllocations{
+			Id:                      int64(len(m.allocations) + 1),
+			DeviceId:                device.Id,
+			EntityType:              model.GpuAllocationEntityTrainingJob,
+			EntityId:                req.JobId,
+			AllocationType:          model.GpuAllocationTypeExclusive,
+			AllocatedAt:             req.AllocatedAt,
+			ExpectedDurationSeconds: req.ExpectedDurationSeconds,
+			Priority:                req.Priority,
+			Status:                  model.GpuAllocationStatusActive,
+			Metadata:                req.Metadata,
+			CreatedAt:               req.AllocatedAt,
+			UpdatedAt:               req.AllocatedAt,
+		}
+		m.allocations = append(m.allocations, allocation)
+		if req.Shared() {
+			allocation.AllocationType = model.GpuAllocationTypeShared
+			allocation.SharingMode = req.SharingMode
+			allocation.MigProfile = req.MigProfile
+			allocation.MemoryMb = req.MemoryMb
+			if full[device.Id] {
+				device.Status = model.GpuDeviceStatusOccupied
+			}
+		} else {
+			device.Status = model.GpuDeviceStatusOccupied
+			device.AllocationId = allocation.Id
+			device.AllocatedJobId = req.JobId
+			device.AllocatedUserId = req.UserId
+			allocatedAt := req.AllocatedAt
+			device.AllocatedAt = &allocatedAt
+		}
+		copied := *allocation
+		result = append(result, &copied)
+	}
+	return result, nil
+}
+
+// shareUsage 与数据库实现一致地统计设备上的有效共享分配
+func (m *fakeGpuAllocationsModel) shareUsage(device *model.VtGpuDevices, req *model.GpuDeviceAllocationRequest) (int, string) {
+	var used, profileUsed, memoryUsed int
+	for _, a := range m.allocations {
+		if a.DeviceId != device.Id || a.Status != model.GpuAllocationStatusActive {
+			continue
+		}
+		used++
+		memoryUsed += a.MemoryMb
+		if a.MigProfile == req.MigProfile {
+			profileUsed++
+		}
+	}
+	if used >= device.ShareCapacityOrDefault() {
+		return used, fmt.Sprintf("共享单元已用完(%d/%d)", used, device.ShareCapacityOrDefault())
+	}
+	switch req.SharingMode {
+	case model.GpuSharingModeMig:
+		instances := device.MigProfiles()[req.MigProfile]
+		if instances == 0 {
+			return used, fmt.Sprintf("设备未划分%s规格的MIG实例", req.MigProfile)
+		}
+		if profileUsed >= instances {
+			return used, fmt.Sprintf("%s规格的MIG实例已用完(%d/%d)", req.MigProfile, profileUsed, instances)
+		}
+	case model.GpuSharingModeVgpu:
+		if device.MemoryTotalMb > 0 && memoryUsed+req.MemoryMb > device.MemoryTotalMb {
+			return used, fmt.Sprintf("显存不足，剩余%dMB，需要%dMB", device.MemoryTotalMb-memoryUsed, req.MemoryMb)
+		}
+	}
+	return used, ""
+}
+
+func (m *fakeGpuAllocationsModel) Release(id int64, status string, releasedAt time.Time) (*model.VtGpuDeviceAllocations, error) {
+	m.devices.mu.Lock()
+	defer m.devices.mu.Unlock()
+
+	for _, a := range m.allocations {
+		if a.Id != id {
+			continue
+		}
+		if a.Status != model.GpuAllocationStatusActive {
+			copied := *a
+			return &copied, model.ErrGpuAllocationNotActive
+		}
+		a.Status = status
+		a.ReleasedAt = &releasedAt
+		for _, d := range m.devices.devices {
+			if d.Id == a.DeviceId && a.AllocationType == model.GpuAllocationTypeShared && d.SharingModeOrDefault() != model.GpuSharingModeExclusive {
+				if d.Status == model.GpuDeviceStatusOccupied {
+					d.Status = model.GpuDeviceStatusAvailable
+				}
+			} else if d.Id == a.DeviceId && d.AllocationId == id {
+				if d.Status == model.GpuDeviceStatusOccupied {
+					d.Status = model.GpuDeviceStatusAvailable
+				}
+				d.AllocationId, d.AllocatedJobId, d.AllocatedUserId, d.AllocatedAt = 0, 0, 0, nil
+			}
+		}
+		copied := *a
+		return &copied, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *fakeGpuAllocationsModel) FindExpired(now time.Time, limit int) ([]*model.VtGpuDeviceAllocations, error) {
+	m.devices.mu.Lock()
+	defer m.devices.mu.Unlock()
+
+	var result []*model.VtGpuDeviceAllocations
+	for _, a := range m.allocations {
+		if a.Status == model.GpuAllocationStatusActive && a.ExpiresAt() != nil && !a.ExpiresAt().After(now) && len(result) < limit {
+			copied := *a
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+// active 返回每个设备上仍有效的分配数
+func (m *fakeGpuAllocationsModel) active() map[int64]int {
+	m.devices.mu.Lock()
+	defer m.devices.mu.Unlock()
+
+	counts := make(map[int64]int)
+	for _, a := range m.allocations {
+		if a.Status == model.GpuAllocationStatusActive {
+			counts[a.DeviceId]++
+		}
+	}
+	return counts
+}
+
+// fakeMonitorMetricsModel 基于内存的监控指标定义模型，仅实现按名称查询
+type fakeMonitorMetricsModel struct {
+	model.VtMonitorMetricsModel
+
+	mu      sync.Mutex
+	metrics []*model.VtMonitorMetrics
+}
+
+func (m *fakeMonitorMetricsModel) register(names ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, name := range names {
+		m.metrics = append(m.metrics, &model.VtMonitorMetrics{Id: int64(len(m.metrics) + 1), Name: name})
+	}
+}
+
+func (m *fakeMonitorMetricsModel) FindOneByName(name string) (*model.VtMonitorMetrics, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, metric := range m.metrics {
+		if metric.Name == name {
+			copied := *metric
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+// fakeMonitorDataModel 基于内存的监控数据模型，记录批量写入的数据点
+type fakeMonitorDataModel struct {
+	model.VtMonitorDataModel
+
+	mu     sync.Mutex
+	points []*model.VtMonitorData
+}
+
+func (m *fakeMonitorDataModel) BatchInsert(data []*model.VtMonitorData) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.points = append(m.points, data...)
+	return nil
+}
+
+// byResource 返回设备各指标最近写入的值
+func (m *fakeMonitorDataModel) byResource(resourceId int64) map[int64]float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	values := make(map[int64]float64)
+	for _, point := range m.points {
+		if point.ResourceId != nil && *point.ResourceId == resourceId {
+			values[point.MetricId] = point.Value
+		}
+	}
+	return values
+}
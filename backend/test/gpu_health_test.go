@@ -0,0 +1,322 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"api/internal/logic/gpu_node"
+	"api/internal/svc"
+	"api/internal/types"
+	"api/model"
+	bizerrors "api/pkg/errors"
+	"api/pkg/monitoring"
+	"api/pkg/scheduler"
+	"api/pkg/volcano"
+
+	"github.com/stretchr/testify/suite"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	vcjob "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+	vcfake "volcano.sh/apis/pkg/client/clientset/versioned/fake"
+)
+
+// TestGpuHealthSuite GPU故障检测与节点隔离测试套件
+type TestGpuHealthSuite struct {
+	suite.Suite
+	kube       *k8sfake.Clientset
+	clusters   *fakeGpuClustersModel
+	nodes      *fakeGpuNodesModel
+	devices    *fakeGpuDevicesModel
+	jobModel   *fakeTrainingJobsModel
+	manager    *volcano.GPUManager
+	machine    *scheduler.JobStateMachine
+	remediator *scheduler.GPUNodeRemediator
+	collector  *scheduler.GPUTelemetryCollector
+	gpus       []dcgmGPU
+	server     *httptest.Server
+}
+
+func (s *TestGpuHealthSuite) SetupTest() {
+	s.kube = k8sfake.NewSimpleClientset()
+	s.clusters = &fakeGpuClustersModel{}
+	s.nodes = &fakeGpuNodesModel{}
+	s.devices = &fakeGpuDevicesModel{}
+	s.jobModel = newFakeTrainingJobsModel()
+
+	_, err := s.clusters.Insert(&model.VtGpuClusters{Name: "gpu-a", ClusterType: model.GpuClusterTypeK8s, Status: model.GpuClusterStatusActive})
+	s.Require().NoError(err)
+	for _, name := range []string{"gpu-1", "gpu-2"} {
+		s.addNode(name)
+		_, err := s.nodes.Insert(&model.VtGpuNodes{ClusterId: 1, Name: name, Status: model.GpuNodeStatusOnline})
+		s.Require().NoError(err)
+		for i := 0; i < 2; i++ {
+			_, err := s.devices.Insert(&model.VtGpuDevices{
+				ClusterId: 1, NodeId: s.nodes.byName(name).Id, DeviceIndex: i,
+				DeviceName: fmt.Sprintf("%s-gpu%d", name, i), DeviceUuid: fmt.Sprintf("GPU-%s-%d", name, i),
+				Status: model.GpuDeviceStatusOccupied, HealthStatus: model.GpuHealthHealthy,
+			})
+			s.Require().NoError(err)
+		}
+	}
+
+	llama := s.addRunningJob(1, "llama")
+	bert := s.addRunningJob(2, "bert")
+	s.addPod("llama-worker-0", "gpu-1", "volcano", llama.VolcanoJobName, corev1.PodRunning)
+	s.addPod("bert-worker-0", "gpu-2", "volcano", bert.VolcanoJobName, corev1.PodRunning)
+	// 非Volcano调度和已结束的Pod不删除
+	s.addPod("node-exporter-abcde", "gpu-1", corev1.DefaultSchedulerName, "", corev1.PodRunning)
+	s.addPod("llama-init-0", "gpu-1", "volcano", llama.VolcanoJobName, corev1.PodSucceeded)
+
+	client := volcano.NewClientWithClientsets(vcfake.NewSimpleClientset(), s.kube, testNamespace)
+	s.manager = volcano.NewGPUManager(client)
+	s.machine = scheduler.NewJobStateMachine(s.jobModel, s.jobModel.transitions, client)
+	s.remediator = scheduler.NewGPUNodeRemediator(s.clusters, s.nodes, s.devices, s.jobModel, s.machine,
+		func(cluster *model.VtGpuClusters) (*volcano.GPUManager, error) {
+			return s.manager, nil
+		})
+
+	s.gpus = []dcgmGPU{
+		{host: "gpu-1", uuid: "GPU-gpu-1-0", index: 0, pod: "llama-worker-0", util: 95, temp: 70},
+		{host: "gpu-1", uuid: "GPU-gpu-1-1", index: 1, pod: "llama-worker-0", util: 0, temp: 45, xid: 79},
+		{host: "gpu-2", uuid: "GPU-gpu-2-0", index: 0, pod: "bert-worker-0", util: 80, temp: 65, xid: 13},
+		{host: "gpu-2", uuid: "GPU-gpu-2-1", index: 1, pod: "bert-worker-0", util: 82, temp: 66},
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		fmt.Fprint(w, dcgmExporterText(s.gpus...))
+	}))
+	s.collector = scheduler.NewGPUTelemetryCollector(monitoring.NewDCGMExporterSource([]string{s.server.URL}, time.Second),
+		s.devices, s.nodes, &fakeMonitorMetricsModel{}, &fakeMonitorDataModel{}, scheduler.GPUTelemetryConfig{})
+	s.collector.SetFaultHandler(s.remediator)
+	s.manager.SetTelemetry(s.collector)
+}
+
+func (s *TestGpuHealthSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *TestGpuHealthSuite) addNode(name string) {
+	_, err := s.kube.CoreV1().Nodes().Create(context.Background(), &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Capacity:    corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("2")},
+			Allocatable: corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("2")},
+			Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}, metav1.CreateOptions{})
+	s.Require().NoError(err)
+}
+
+func (s *TestGpuHealthSuite) addPod(name, nodeName, schedulerName, jobName string, phase corev1.PodPhase) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Spec:       corev1.PodSpec{NodeName: nodeName, SchedulerName: schedulerName},
+		Status:     corev1.PodStatus{Phase: phase},
+	}
+	if jobName != "" {
+		pod.Labels = map[string]string{vcjob.JobNameKey: jobName}
+	}
+	_, err := s.kube.CoreV1().Pods(testNamespace).Create(context.Background(), pod, metav1.CreateOptions{})
+	s.Require().NoError(err)
+}
+
+// addRunningJob 创建已调度运行、允许自动重试的作业
+func (s *TestGpuHealthSuite) addRunningJob(id int64, name string) *model.VtTrainingJobs {
+	job := newPendingJob(id, name)
+	scheduledAt := time.Now().Add(-time.Hour)
+	job.Status = "running"
+	job.Namespace = testNamespace
+	job.VolcanoJobName = scheduler.BuildVolcanoJobName(job)
+	job.ScheduledAt = &scheduledAt
+	job.StartTime = &scheduledAt
+	job.AutoRestart = true
+	job.MaxRetryCount = 2
+	s.jobModel.jobs[id] = job
+	return job
+}
+
+func (s *TestGpuHealthSuite) podNames() []string {
+	pods, err := s.kube.CoreV1().Pods(testNamespace).List(context.Background(), metav1.ListOptions{})
+	s.Require().NoError(err)
+	var names []string
+	for _, pod := range pods.Items {
+		names = append(names, pod.Name)
+	}
+	return names
+}
+
+// TestFaultReason 只有硬件类XID、双比特ECC错误和行重映射失败视为设备故障
+func (s *TestGpuHealthSuite) TestFaultReason() {
+	sample := func(values map[string]float64) *monitoring.DCGMSample {
+		return &monitoring.DCGMSample{Values: values}
+	}
+	s.Equal("XID 79: GPU已从总线上掉线", sample(map[string]float64{monitoring.DCGMXIDErrors: 79}).FaultReason())
+	s.Empty(sample(map[string]float64{monitoring.DCGMXIDErrors: 13}).FaultReason())
+	s.Empty(sample(map[string]float64{monitoring.DCGMXIDErrors: 0, monitoring.DCGMECCDBEVolTotal: 0}).FaultReason())
+	s.Equal("双比特ECC错误2次", sample(map[string]float64{monitoring.DCGMECCDBEVolTotal: 2}).FaultReason())
+	s.Equal("显存行重映射失败", sample(map[string]float64{monitoring.DCGMRowRemapFailure: 1}).FaultReason())
+	s.Empty(sample(map[string]float64{monitoring.DCGMGPUUtil: 50}).FaultReason())
+}
+
+// TestCordonAndDrainOnXID 设备上报严重XID时标记为unhealthy，隔离节点，删除Volcano Pod并使作业以gpu_fault失败
+func (s *TestGpuHealthSuite) TestCordonAndDrainOnXID() {
+	report, err := s.collector.CollectOnce(time.Now())
+	s.Require().NoError(err)
+	s.Equal(1, report.Faults)
+
+	faulty, err := s.devices.FindOne(2)
+	s.Require().NoError(err)
+	s.Equal(model.GpuHealthUnhealthy, faulty.HealthStatus)
+	for _, id := range []int64{1, 3, 4} {
+		device, err := s.devices.FindOne(id)
+		s.Require().NoError(err)
+		s.Equal(model.GpuHealthHealthy, device.HealthStatus, "device %d", id)
+	}
+
+	node, err := s.kube.CoreV1().Nodes().Get(context.Background(), "gpu-1", metav1.GetOptions{})
+	s.Require().NoError(err)
+	s.True(node.Spec.Unschedulable)
+	s.Equal("GPU 1: XID 79: GPU已从总线上掉线", node.Annotations[volcano.GPUCordonAnnotation])
+	other, err := s.kube.CoreV1().Nodes().Get(context.Background(), "gpu-2", metav1.GetOptions{})
+	s.Require().NoError(err)
+	s.False(other.Spec.Unschedulable)
+
+	s.Equal(model.GpuNodeStatusMaintenance, s.nodes.byName("gpu-1").Status)
+	s.Equal(model.GpuNodeStatusOnline, s.nodes.byName("gpu-2").Status)
+	s.ElementsMatch([]string{"bert-worker-0", "node-exporter-abcde", "llama-init-0"}, s.podNames())
+
+	llama := s.jobModel.get(1)
+	s.Equal("failed", llama.Status)
+	s.Equal(scheduler.FailureReasonGPUFault, llama.FailureReason)
+	s.Contains(llama.ErrorMessage, "gpu-1")
+	s.NotNil(llama.EndTime)
+	s.Equal("running", s.jobModel.get(2).Status)
+
+	// 故障持续上报时不重复处理已隔离的节点
+	transitions := len(s.jobModel.transitions.records)
+	report, err = s.collector.CollectOnce(time.Now())
+	s.Require().NoError(err)
+	s.Equal(1, report.Faults)
+	s.Len(s.jobModel.transitions.records, transitions)
+}
+
+// TestRemediateNonK8sNode 非Kubernetes集群的故障节点只置为维护状态，不连接集群隔离和驱逐
+func (s *TestGpuHealthSuite) TestRemediateNonK8sNode() {
+	cluster, err := s.clusters.FindOne(1)
+	s.Require().NoError(err)
+	cluster.ClusterType = "slurm"
+	s.Require().NoError(s.clusters.Update(cluster))
+	remediator := scheduler.NewGPUNodeRemediator(s.clusters, s.nodes, s.devices, s.jobModel, s.machine,
+		func(cluster *model.VtGpuClusters) (*volcano.GPUManager, error) {
+			s.Fail("非Kubernetes集群不应连接集群")
+			return nil, errors.New("not a kubernetes cluster")
+		})
+
+	result, err := remediator.RemediateNode(context.Background(), s.nodes.byName("gpu-1").Id, "GPU 1: XID 79")
+	s.Require().NoError(err)
+	s.Zero(result.Pods)
+	s.Empty(result.FailedJobs)
+	s.Equal(model.GpuNodeStatusMaintenance, s.nodes.byName("gpu-1").Status)
+
+	node, err := s.kube.CoreV1().Nodes().Get(context.Background(), "gpu-1", metav1.GetOptions{})
+	s.Require().NoError(err)
+	s.False(node.Spec.Unschedulable)
+	s.Contains(s.podNames(), "llama-worker-0")
+	s.Equal("running", s.jobModel.get(1).Status)
+}
+
+// TestRetryAfterGPUFault gpu_fault属于可重试的失败原因，作业按重试策略重新排队
+func (s *TestGpuHealthSuite) TestRetryAfterGPUFault() {
+	_, err := s.collector.CollectOnce(time.Now())
+	s.Require().NoError(err)
+
+	client := volcano.NewClientWithClientsets(vcfake.NewSimpleClientset(), s.kube, testNamespace)
+	dispatcher := scheduler.NewJobDispatcher(s.jobModel, s.machine, volcano.NewJobManager(client), nil, scheduler.DispatcherConfig{
+		Namespace: testNamespace,
+	})
+	retrier := scheduler.NewJobRetrier(s.jobModel, s.jobModel.retries, s.machine, dispatcher, scheduler.RetrierConfig{
+		BackoffBase: time.Nanosecond,
+	})
+	retried, err := retrier.RetryOnce()
+	s.Require().NoError(err)
+	s.Equal(1, retried)
+
+	job := s.jobModel.get(1)
+	s.Equal("pending", job.Status)
+	records, _ := s.jobModel.retries.FindByJobId(1)
+	s.Require().Len(records, 1)
+	s.Equal(scheduler.FailureReasonGPUFault, records[0].FailureReason)
+}
+
+// TestMonitorGPUHealth 健康检查报告故障设备和已隔离的节点
+func (s *TestGpuHealthSuite) TestMonitorGPUHealth() {
+	_, err := s.collector.CollectOnce(time.Now())
+	s.Require().NoError(err)
+
+	report, err := s.manager.MonitorGPUHealth()
+	s.Require().NoError(err)
+	s.Equal(int32(2), report.Statistics.TotalNodes)
+	s.Equal(int32(1), report.Statistics.HealthyNodes)
+	s.Equal("Critical", report.OverallHealth)
+
+	health := make(map[string]volcano.NodeHealthStatus)
+	for _, node := range report.NodeHealth {
+		health[node.NodeName] = node
+	}
+	s.Equal(int32(1), health["gpu-1"].HealthyGPUs)
+	s.Equal(int32(2), health["gpu-2"].HealthyGPUs)
+
+	s.Require().Len(report.Issues, 2)
+	for _, issue := range report.Issues {
+		s.Equal("gpu-1", issue.NodeName)
+		s.Equal("gpu", issue.Component)
+	}
+	s.Equal("high", report.Issues[0].Severity)
+	s.Contains(report.Issues[0].Issue, "节点因GPU故障已隔离")
+	s.Equal("critical", report.Issues[1].Severity)
+	s.Equal("GPU 1: XID 79: GPU已从总线上掉线", report.Issues[1].Issue)
+}
+
+// TestUncordonGpuNode 管理员解除隔离后节点恢复调度，故障设备恢复为healthy
+func (s *TestGpuHealthSuite) TestUncordonGpuNode() {
+	_, err := s.collector.CollectOnce(time.Now())
+	s.Require().NoError(err)
+
+	svcCtx := &svc.ServiceContext{VtGpuNodesModel: s.nodes, GpuRemediator: s.remediator}
+	logic := gpu_node.NewUncordonGpuNodeLogic(context.Background(), svcCtx)
+	resp, err := logic.UncordonGpuNode(&types.UncordonGpuNodeReq{ID: s.nodes.byName("gpu-1").Id})
+	s.Require().NoError(err)
+	s.Equal(model.GpuNodeStatusOnline, resp.Status)
+	s.Equal(1, resp.DevicesRecovered)
+
+	node, err := s.kube.CoreV1().Nodes().Get(context.Background(), "gpu-1", metav1.GetOptions{})
+	s.Require().NoError(err)
+	s.False(node.Spec.Unschedulable)
+	s.NotContains(node.Annotations, volcano.GPUCordonAnnotation)
+	device, err := s.devices.FindOne(2)
+	s.Require().NoError(err)
+	s.Equal(model.GpuHealthHealthy, device.HealthStatus)
+	s.Equal(model.GpuNodeStatusOnline, s.nodes.byName("gpu-1").Status)
+
+	// 设备修复后不再上报故障，节点保持在线
+	s.gpus[1].xid = 0
+	report, err := s.collector.CollectOnce(time.Now())
+	s.Require().NoError(err)
+	s.Zero(report.Faults)
+	s.Equal(model.GpuNodeStatusOnline, s.nodes.byName("gpu-1").Status)
+
+	_, err = logic.UncordonGpuNode(&types.UncordonGpuNodeReq{ID: 99})
+	s.Require().Error(err)
+	s.Equal(http.StatusNotFound, bizerrors.GetBizError(err).GetHTTPStatus())
+}
+
+func TestRunGpuHealthTests(t *testing.T) {
+	suite.Run(t, new(TestGpuHealthSuite))
+}
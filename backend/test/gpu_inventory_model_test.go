@@ -0,0 +1,152 @@
+package test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"api/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+// TestGpuInventoryModelSuite 用sqlmock执行资源发现使用的集群、节点和设备模型语句，并按建表脚本校验引用的列
+type TestGpuInventoryModelSuite struct {
+	suite.Suite
+	db       *sql.DB
+	mock     sqlmock.Sqlmock
+	clusters model.VtGpuClustersModel
+	nodes    model.VtGpuNodesModel
+	devices  model.VtGpuDevicesModel
+	now      time.Time
+}
+
+func (s *TestGpuInventoryModelSuite) SetupTest() {
+	matcher, err := schemaMatcher()
+	s.Require().NoError(err)
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(matcher))
+	s.Require().NoError(err)
+	s.db = db
+	s.mock = mock
+	s.clusters = model.NewVtGpuClustersModel(db)
+	s.nodes = model.NewVtGpuNodesModel(db)
+	s.devices = model.NewVtGpuDevicesModel(db)
+	s.now = time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)
+}
+
+func (s *TestGpuInventoryModelSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+	s.db.Close()
+}
+
+// anyArgs 返回n个任意参数，overrides按位置指定需要校验的参数
+func anyArgs(n int, overrides map[int]driver.Value) []driver.Value {
+	args := make([]driver.Value, n)
+	for i := range args {
+		args[i] = sqlmock.AnyArg()
+		if value, ok := overrides[i]; ok {
+			args[i] = value
+		}
+	}
+	return args
+}
+
+// TestClusterQueries 集群的查询和同步后的统计更新只引用建表脚本中的列
+func (s *TestGpuInventoryModelSuite) TestClusterQueries() {
+	columns := []string{"id", "name", "display_name", "description", "cluster_type", "status", "kube_config", "api_endpoint",
+		"region", "zone", "total_nodes", "active_nodes", "total_gpus", "available_gpus", "allocated_gpus",
+		"resource_labels", "metrics_config", "created_at", "updated_at"}
+	s.mock.ExpectQuery(`FROM vt_gpu_clusters WHERE cluster_type = \?`).
+		WithArgs(model.GpuClusterTypeK8s, model.GpuClusterStatusActive).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(int64(1), "gpu-a", "", "", model.GpuClusterTypeK8s, model.GpuClusterStatusActive,
+			"", "", "", "", 2, 1, 16, 8, 0, "", "", s.now, s.now))
+	s.mock.ExpectExec(`UPDATE vt_gpu_clusters SET`).WithArgs(anyArgs(17, nil)...).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	clusters, err := s.clusters.FindAllByType(model.GpuClusterTypeK8s, model.GpuClusterStatusActive)
+	s.Require().NoError(err)
+	s.Require().Len(clusters, 1)
+	s.Equal(1, clusters[0].ActiveNodes)
+	s.Require().NoError(s.clusters.Update(clusters[0]))
+}
+
+// TestNodeQueries 新节点未上报心跳和标签时写入NULL，读取时NULL还原为空值
+func (s *TestGpuInventoryModelSuite) TestNodeQueries() {
+	s.mock.ExpectExec(`INSERT INTO vt_gpu_nodes`).
+		WithArgs(anyArgs(18, map[int]driver.Value{0: int64(1), 1: "gpu-node-1", 3: "10.0.0.1", 17: nil})...).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+	_, err := s.nodes.Insert(&model.VtGpuNodes{ClusterId: 1, Name: "gpu-node-1", InternalIp: "10.0.0.1",
+		Status: model.GpuNodeStatusOnline, NodeType: "physical"})
+	s.Require().NoError(err)
+
+	columns := []string{"id", "cluster_id", "name", "hostname", "internal_ip", "external_ip", "status", "node_type",
+		"cpu_cores", "memory_gb", "storage_gb", "gpu_count", "available_gpus", "allocated_gpus",
+		"os_image", "kernel_version", "node_labels", "node_taints", "last_heartbeat", "created_at", "updated_at"}
+	s.mock.ExpectQuery(`FROM vt_gpu_nodes WHERE cluster_id = \?`).WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(int64(7), int64(1), "gpu-node-1", "", "10.0.0.1", "", model.GpuNodeStatusOnline, "physical",
+				64, 512, 1024, 8, 8, 0, "", "", "", "", nil, s.now, s.now).
+			AddRow(int64(8), int64(1), "gpu-node-2", "gpu-node-2", "10.0.0.2", "", model.GpuNodeStatusOnline, "physical",
+				64, 512, 1024, 8, 8, 0, "Ubuntu 22.04", "5.15", `{"gpu":"a100"}`, "[]", s.now, s.now, s.now))
+	nodes, err := s.nodes.FindAllByClusterId(1)
+	s.Require().NoError(err)
+	s.Require().Len(nodes, 2)
+	s.Nil(nodes[0].LastHeartbeat)
+	s.Require().NotNil(nodes[1].LastHeartbeat)
+	s.Equal(s.now, *nodes[1].LastHeartbeat)
+
+	s.mock.ExpectExec(`UPDATE vt_gpu_nodes SET`).
+		WithArgs(anyArgs(19, map[int]driver.Value{15: `{"gpu":"a100"}`, 17: s.now, 18: int64(8)})...).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.Require().NoError(s.nodes.Update(nodes[1]))
+}
+
+// TestDeviceQueries 未上报UUID和未分配的设备写入NULL，避免UUID唯一键冲突和写入零值时间
+func (s *TestGpuInventoryModelSuite) TestDeviceQueries() {
+	s.mock.ExpectExec(`INSERT INTO vt_gpu_devices`).
+		WithArgs(anyArgs(29, map[int]driver.Value{0: int64(1), 1: int64(7), 2: 0, 3: "", 24: nil, 25: s.now})...).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+	_, err := s.devices.Insert(&model.VtGpuDevices{ClusterId: 1, NodeId: 7, DeviceName: "gpu-node-1-gpu0", Brand: "nvidia",
+		Status: model.GpuDeviceStatusAvailable, HealthStatus: model.GpuHealthHealthy, LastHeartbeat: &s.now})
+	s.Require().NoError(err)
+
+	columns := []string{"id", "cluster_id", "node_id", "device_index", "device_uuid", "device_name", "brand", "model", "architecture",
+		"memory_total_mb", "memory_free_mb", "memory_used_mb", "power_draw_w", "power_limit_w",
+		"temperature_c", "utilization_gpu", "utilization_mem", "status", "health_status",
+		"pcie_bus_id", "cuda_version", "driver_version", "allocation_id", "allocated_job_id",
+		"allocated_user_id", "allocated_at", "last_heartbeat", "sharing_mode", "share_capacity", "mig_devices", "created_at", "updated_at"}
+	s.mock.ExpectQuery(`FROM vt_gpu_devices WHERE cluster_id = \? ORDER BY node_id, device_index`).WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(int64(3), int64(1), int64(7), 0, "", "gpu-node-1-gpu0", "nvidia", "A100", "ampere",
+			81920, 0, 0, 0, 0, 0, 0, 0, model.GpuDeviceStatusAvailable, model.GpuHealthHealthy,
+			"", "12.2", "535.104", int64(0), int64(0), int64(0), nil, s.now, model.GpuSharingModeExclusive, 1, "", s.now, s.now))
+	devices, err := s.devices.FindAllByClusterId(1)
+	s.Require().NoError(err)
+	s.Require().Len(devices, 1)
+	s.Nil(devices[0].AllocatedAt)
+	s.Require().NotNil(devices[0].LastHeartbeat)
+
+	s.mock.ExpectExec(`UPDATE vt_gpu_devices SET`).
+		WithArgs(anyArgs(30, map[int]driver.Value{3: "", 24: nil, 29: int64(3)})...).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.Require().NoError(s.devices.Update(devices[0]))
+
+	s.mock.ExpectExec(`UPDATE vt_gpu_devices SET status = \?`).WithArgs(model.GpuDeviceStatusOffline, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.Require().NoError(s.devices.UpdateStatus(3, model.GpuDeviceStatusOffline))
+}
+
+// TestSchemaMatcherRejectsUnknownColumn 语句引用建表脚本中不存在或已改名的列时匹配失败
+func (s *TestGpuInventoryModelSuite) TestSchemaMatcherRejectsUnknownColumn() {
+	tables, err := loadSchema()
+	s.Require().NoError(err)
+	s.Error(checkColumns(tables, `UPDATE vt_gpu_devices SET pci_bus_id = ? WHERE id = ?`))
+	s.Error(checkColumns(tables, `SELECT id FROM vt_gpu_nodes WHERE ip_address = ?`))
+	s.Error(checkColumns(tables, `SELECT id FROM vt_gpu_hosts WHERE id = ?`))
+	s.NoError(checkColumns(tables, `UPDATE vt_gpu_clusters SET available_gpus = (
+		SELECT IFNULL(SUM(available_gpus), 0) FROM vt_gpu_nodes WHERE cluster_id = vt_gpu_clusters.id AND status = 'online')`))
+}
+
+func TestRunGpuInventoryModelTests(t *testing.T) {
+	suite.Run(t, new(TestGpuInventoryModelSuite))
+}
@@ -0,0 +1,287 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"api/model"
+	"api/pkg/scheduler"
+	"api/pkg/volcano"
+
+	"github.com/stretchr/testify/suite"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	vcfake "volcano.sh/apis/pkg/client/clientset/versioned/fake"
+)
+
+type TestGpuInventorySuite struct {
+	suite.Suite
+	kube     *k8sfake.Clientset
+	clusters *fakeGpuClustersModel
+	nodes    *fakeGpuNodesModel
+	devices  *fakeGpuDevicesModel
+	syncer   *scheduler.GPUInventorySyncer
+	now      time.Time
+}
+
+func (s *TestGpuInventorySuite) SetupTest() {
+	s.kube = k8sfake.NewSimpleClientset()
+	s.clusters = &fakeGpuClustersModel{}
+	s.nodes = &fakeGpuNodesModel{}
+	s.devices = &fakeGpuDevicesModel{}
+	s.now = time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+
+	_, err := s.clusters.Insert(&model.VtGpuClusters{Name: "gpu-a", ClusterType: model.GpuClusterTypeK8s, Status: model.GpuClusterStatusActive})
+	s.Require().NoError(err)
+	_, err = s.clusters.Insert(&model.VtGpuClusters{Name: "slurm", ClusterType: "slurm", Status: model.GpuClusterStatusActive})
+	s.Require().NoError(err)
+
+	manager := volcano.NewGPUManager(volcano.NewClientWithClientsets(vcfake.NewSimpleClientset(), s.kube, testNamespace))
+	s.syncer = scheduler.NewGPUInventorySyncer(s.clusters, s.nodes, s.devices, func(cluster *model.VtGpuClusters) (*volcano.GPUManager, error) {
+		return manager, nil
+	}, scheduler.GPUInventoryConfig{})
+}
+
+// addNode 创建K8s节点，gpus为nvidia.com/gpu容量，0表示没有GPU
+func (s *TestGpuInventorySuite) addNode(name string, gpus int64, ready bool, labels, annotations map[string]string) {
+	status := corev1.ConditionTrue
+	if !ready {
+		status = corev1.ConditionFalse
+	}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels, Annotations: annotations},
+		Status: corev1.NodeStatus{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU:              resource.MustParse("64"),
+				corev1.ResourceMemory:           resource.MustParse("512Gi"),
+				corev1.ResourceEphemeralStorage: resource.MustParse("1Ti"),
+			},
+			Allocatable: corev1.ResourceList{},
+			Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}},
+			Addresses:   []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "10.0.0." + name[len(name)-1:]}},
+			NodeInfo:    corev1.NodeSystemInfo{OSImage: "Ubuntu 22.04.4 LTS", KernelVersion: "5.15.0-105-generic"},
+		},
+	}
+	if gpus > 0 {
+		node.Status.Capacity["nvidia.com/gpu"] = *resource.NewQuantity(gpus, resource.DecimalSI)
+		node.Status.Allocatable["nvidia.com/gpu"] = *resource.NewQuantity(gpus, resource.DecimalSI)
+	}
+	_, err := s.kube.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{})
+	s.Require().NoError(err)
+}
+
+func (s *TestGpuInventorySuite) deleteNode(name string) {
+	s.Require().NoError(s.kube.CoreV1().Nodes().Delete(context.Background(), name, metav1.DeleteOptions{}))
+}
+
+func (s *TestGpuInventorySuite) sync() *scheduler.GPUInventoryReport {
+	cluster, err := s.clusters.FindOne(1)
+	s.Require().NoError(err)
+	report, err := s.syncer.SyncCluster(context.Background(), cluster, s.now)
+	s.Require().NoError(err)
+	return report
+}
+
+func a100Labels() map[string]string {
+	return map[string]string{
+		volcano.GPUProductLabel:       "NVIDIA-A100-SXM4-80GB",
+		volcano.GPUMemoryLabel:        "81920",
+		volcano.GPUFamilyLabel:        "ampere",
+		volcano.CUDADriverMajorLabel:  "535",
+		volcano.CUDADriverMinorLabel:  "104",
+		volcano.CUDADriverRevLabel:    "05",
+		volcano.CUDARuntimeMajorLabel: "12",
+		volcano.CUDARuntimeMinorLabel: "2",
+	}
+}
+
+// TestDiscoverNodesAndDevices 从节点标签和注解读取设备型号、显存、驱动和CUDA版本及UUID，没有GPU的节点不登记
+func (s *TestGpuInventorySuite) TestDiscoverNodesAndDevices() {
+	s.addNode("gpu-1", 2, true, a100Labels(), map[string]string{volcano.GPUUUIDsAnnotation: "GPU-aaa, GPU-bbb"})
+	s.addNode("cpu-2", 0, true, nil, nil)
+
+	report := s.sync()
+	s.Equal(1, report.Nodes)
+	s.Equal(1, report.NodesCreated)
+	s.Equal(2, report.DevicesCreated)
+
+	node := s.nodes.byName("gpu-1")
+	s.Require().NotNil(node)
+	s.Nil(s.nodes.byName("cpu-2"))
+	s.Equal(model.GpuNodeStatusOnline, node.Status)
+	s.Equal("physical", node.NodeType)
+	s.Equal("10.0.0.1", node.InternalIp)
+	s.Equal(64, node.CpuCores)
+	s.Equal(512, node.MemoryGb)
+	s.Equal(2, node.GpuCount)
+	s.Equal(2, node.AvailableGpus)
+	s.Require().NotNil(node.LastHeartbeat)
+	s.Equal(s.now, *node.LastHeartbeat)
+
+	devices := s.devices.byNode(node.Id)
+	s.Require().Len(devices, 2)
+	s.Equal("GPU-bbb", devices[1].DeviceUuid)
+	s.Equal(1, devices[1].DeviceIndex)
+	s.Equal("NVIDIA-A100-SXM4-80GB", devices[0].Model)
+	s.Equal("ampere", devices[0].Architecture)
+	s.Equal(81920, devices[0].MemoryTotalMb)
+	s.Equal("535.104.05", devices[0].DriverVersion)
+	s.Equal("12.2", devices[0].CudaVersion)
+	s.Equal(model.GpuDeviceStatusAvailable, devices[0].Status)
+	s.Equal(model.GpuHealthHealthy, devices[0].HealthStatus)
+
+	cluster, err := s.clusters.FindOne(1)
+	s.Require().NoError(err)
+	s.Equal(1, cluster.TotalNodes)
+	s.Equal(2, cluster.TotalGpus)
+	s.Equal(2, cluster.AvailableGpus)
+
+	// 再次同步只更新已有记录
+	report = s.sync()
+	s.Equal(0, report.NodesCreated)
+	s.Equal(0, report.DevicesCreated)
+	s.Len(s.devices.byNode(node.Id), 2)
+}
+
+// TestTimeSlicingUsesPhysicalCount 开启时间片共享时按nvidia.com/gpu.count登记物理GPU
+func (s *TestGpuInventorySuite) TestTimeSlicingUsesPhysicalCount() {
+	labels := a100Labels()
+	labels[volcano.GPUCountLabel] = "2"
+	s.addNode("gpu-1", 8, true, labels, nil)
+
+	report := s.sync()
+	s.Equal(2, report.Devices)
+	s.Equal(2, s.nodes.byName("gpu-1").GpuCount)
+	for _, device := range s.devices.byNode(s.nodes.byName("gpu-1").Id) {
+		s.Equal(model.GpuSharingModeTimeSlicing, device.SharingMode)
+		s.Equal(4, device.ShareCapacity)
+	}
+}
+
+// addSharedNode 创建只上报MIG或vGPU资源的节点
+func (s *TestGpuInventorySuite) addSharedNode(name string, labels map[string]string, resources corev1.ResourceList) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Status: corev1.NodeStatus{
+			Capacity:    resources,
+			Allocatable: resources,
+			Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	_, err := s.kube.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{})
+	s.Require().NoError(err)
+}
+
+// TestMigAndVGPUDevices MIG和vGPU节点按物理卡登记设备，节点上报的实例数和vGPU数按卡均分
+func (s *TestGpuInventorySuite) TestMigAndVGPUDevices() {
+	labels := a100Labels()
+	labels[volcano.GPUCountLabel] = "2"
+	labels[volcano.MIGStrategyLabel] = "mixed"
+	s.addSharedNode("mig-1", labels, corev1.ResourceList{
+		"nvidia.com/mig-1g.10gb": resource.MustParse("8"),
+		"nvidia.com/mig-3g.40gb": resource.MustParse("2"),
+	})
+	vgpuLabels := a100Labels()
+	vgpuLabels[volcano.GPUCountLabel] = "2"
+	s.addSharedNode("vgpu-2", vgpuLabels, corev1.ResourceList{
+		volcano.VGPUNumberResourceName: resource.MustParse("20"),
+		volcano.VGPUMemoryResourceName: resource.MustParse("163840"),
+	})
+
+	report := s.sync()
+	s.Equal(2, report.Nodes)
+	s.Equal(4, report.Devices)
+
+	mig := s.devices.byNode(s.nodes.byName("mig-1").Id)
+	s.Require().Len(mig, 2)
+	s.Equal(model.GpuSharingModeMig, mig[0].SharingMode)
+	s.Equal(5, mig[0].ShareCapacity)
+	s.Equal(map[string]int{"1g.10gb": 4, "3g.40gb": 1}, mig[1].MigProfiles())
+
+	vgpu := s.devices.byNode(s.nodes.byName("vgpu-2").Id)
+	s.Require().Len(vgpu, 2)
+	s.Equal(model.GpuSharingModeVgpu, vgpu[0].SharingMode)
+	s.Equal(10, vgpu[0].ShareCapacity)
+	s.Empty(vgpu[0].MigDevices)
+	s.Equal(81920, vgpu[0].MemoryTotalMb)
+}
+
+// TestVanishedNodeGoesOffline 集群中已删除的节点及其设备标记为offline，节点恢复后设备重新可用，其他状态保持不变
+func (s *TestGpuInventorySuite) TestVanishedNodeGoesOffline() {
+	s.addNode("gpu-1", 2, true, a100Labels(), nil)
+	s.addNode("gpu-2", 1, true, a100Labels(), nil)
+	s.sync()
+	node := s.nodes.byName("gpu-1")
+	devices := s.devices.byNode(node.Id)
+	devices[1].Status = "maintenance"
+	s.Require().NoError(s.devices.Update(devices[1]))
+
+	s.deleteNode("gpu-1")
+	s.now = s.now.Add(time.Minute)
+	report := s.sync()
+	s.Equal(1, report.NodesOffline)
+	s.Equal(2, report.DevicesOffline)
+
+	node = s.nodes.byName("gpu-1")
+	s.Equal(model.GpuNodeStatusOffline, node.Status)
+	s.Equal(0, node.AvailableGpus)
+	for _, device := range s.devices.byNode(node.Id) {
+		s.Equal(model.GpuDeviceStatusOffline, device.Status)
+	}
+	cluster, err := s.clusters.FindOne(1)
+	s.Require().NoError(err)
+	s.Equal(2, cluster.TotalNodes)
+	s.Equal(1, cluster.ActiveNodes)
+	s.Equal(1, cluster.TotalGpus)
+
+	// 已离线的节点不重复计数
+	s.Equal(0, s.sync().NodesOffline)
+
+	s.addNode("gpu-1", 2, true, a100Labels(), nil)
+	s.sync()
+	node = s.nodes.byName("gpu-1")
+	s.Equal(model.GpuNodeStatusOnline, node.Status)
+	devices = s.devices.byNode(node.Id)
+	s.Equal(model.GpuDeviceStatusAvailable, devices[0].Status)
+	s.Equal(model.GpuDeviceStatusAvailable, devices[1].Status)
+	s.Equal(2, node.AvailableGpus)
+}
+
+// TestRemovedDeviceAndNotReadyNode 节点不再上报的设备标记为offline，未就绪节点标记为not_ready，维护中的节点保持维护状态
+func (s *TestGpuInventorySuite) TestRemovedDeviceAndNotReadyNode() {
+	s.addNode("gpu-1", 2, true, a100Labels(), nil)
+	s.addNode("gpu-2", 1, false, a100Labels(), nil)
+	s.sync()
+	s.Equal(model.GpuNodeStatusNotReady, s.nodes.byName("gpu-2").Status)
+	s.Equal(model.GpuHealthUnknown, s.devices.byNode(s.nodes.byName("gpu-2").Id)[0].HealthStatus)
+
+	maintained := s.nodes.byName("gpu-2")
+	maintained.Status = model.GpuNodeStatusMaintenance
+	s.Require().NoError(s.nodes.Update(maintained))
+
+	s.deleteNode("gpu-1")
+	s.addNode("gpu-1", 1, true, a100Labels(), nil)
+	report := s.sync()
+	s.Equal(1, report.DevicesOffline)
+	node := s.nodes.byName("gpu-1")
+	s.Equal(1, node.GpuCount)
+	devices := s.devices.byNode(node.Id)
+	s.Equal(model.GpuDeviceStatusAvailable, devices[0].Status)
+	s.Equal(model.GpuDeviceStatusOffline, devices[1].Status)
+	s.Equal(model.GpuNodeStatusMaintenance, s.nodes.byName("gpu-2").Status)
+}
+
+// TestSyncOnceSkipsOtherClusterTypes 定期同步只处理启用的K8s集群
+func (s *TestGpuInventorySuite) TestSyncOnceSkipsOtherClusterTypes() {
+	s.addNode("gpu-1", 1, true, a100Labels(), nil)
+	synced, err := s.syncer.SyncOnce(s.now)
+	s.Require().NoError(err)
+	s.Equal(1, synced)
+}
+
+func TestRunGpuInventoryTests(t *testing.T) {
+	suite.Run(t, new(TestGpuInventorySuite))
+}
@@ -0,0 +1,276 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"api/internal/logic/gpu_device"
+	"api/internal/svc"
+	"api/internal/types"
+	"api/model"
+	bizerrors "api/pkg/errors"
+	"api/pkg/scheduler"
+	"api/pkg/volcano"
+
+	"github.com/stretchr/testify/suite"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	vcfake "volcano.sh/apis/pkg/client/clientset/versioned/fake"
+)
+
+// TestGpuSharingSuite MIG、时间片和vGPU共享测试套件
+type TestGpuSharingSuite struct {
+	suite.Suite
+	devices *fakeGpuDevicesModel
+	svcCtx  *svc.ServiceContext
+}
+
+func (s *TestGpuSharingSuite) SetupTest() {
+	clusters := &fakeGpuClustersModel{}
+	nodes := &fakeGpuNodesModel{}
+	s.devices = &fakeGpuDevicesModel{}
+
+	_, err := clusters.Insert(&model.VtGpuClusters{Name: "gpu-a", ClusterType: model.GpuClusterTypeK8s, Status: model.GpuClusterStatusActive})
+	s.Require().NoError(err)
+	_, err = nodes.Insert(&model.VtGpuNodes{ClusterId: 1, Name: "gpu-1", Status: model.GpuNodeStatusOnline})
+	s.Require().NoError(err)
+	for i, device := range []*model.VtGpuDevices{
+		{SharingMode: model.GpuSharingModeMig, ShareCapacity: 3, MigDevices: `{"1g.10gb":2,"3g.40gb":1}`, MemoryTotalMb: 81920},
+		{SharingMode: model.GpuSharingModeVgpu, ShareCapacity: 4, MemoryTotalMb: 16384},
+		{SharingMode: model.GpuSharingModeExclusive, ShareCapacity: 1, MemoryTotalMb: 81920},
+	} {
+		device.ClusterId, device.NodeId, device.DeviceIndex = 1, 1, i
+		device.DeviceName = fmt.Sprintf("gpu-1-gpu%d", i)
+		device.Status, device.HealthStatus = model.GpuDeviceStatusAvailable, model.GpuHealthHealthy
+		_, err := s.devices.Insert(device)
+		s.Require().NoError(err)
+	}
+
+	s.svcCtx = &svc.ServiceContext{
+		VtTrainingJobsModel: newFakeTrainingJobsModel(
+			&model.VtTrainingJobs{Id: 5, Name: "notebook", Status: "queued"},
+			&model.VtTrainingJobs{Id: 6, Name: "debug", Status: "queued"},
+		),
+		VtGpuClustersModel:          clusters,
+		VtGpuNodesModel:             nodes,
+		VtGpuDevicesModel:           s.devices,
+		VtGpuDeviceAllocationsModel: &fakeGpuAllocationsModel{devices: s.devices},
+	}
+}
+
+func (s *TestGpuSharingSuite) allocate(req *types.AllocateGpuDeviceReq) (*types.GpuAllocationInfo, error) {
+	req.UserId, req.QueueName = 9, "default"
+	if req.JobId == 0 {
+		req.JobId = 5
+	}
+	resp, err := gpu_device.NewAllocateGpuDeviceLogic(context.Background(), s.svcCtx).AllocateGpuDevice(req)
+	if err != nil {
+		return nil, err
+	}
+	s.Require().Len(resp.Allocations, 1)
+	return &resp.Allocations[0], nil
+}
+
+func (s *TestGpuSharingSuite) status(id int64) string {
+	device, err := s.devices.FindOne(id)
+	s.Require().NoError(err)
+	return device.Status
+}
+
+func (s *TestGpuSharingSuite) bizError(err error) *bizerrors.BizError {
+	bizErr := bizerrors.GetBizError(err)
+	s.Require().NotNil(bizErr, "%v", err)
+	return bizErr
+}
+
+// TestJobSpecResources 按作业的共享方式申请整卡、MIG实例、时间片副本或vGPU
+func (s *TestGpuSharingSuite) TestJobSpecResources() {
+	cases := []struct {
+		mode, profile, memoryGb string
+		resources               corev1.ResourceList
+		nodeSelector            map[string]string
+	}{
+		{resources: corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("2")}},
+		{mode: "mig", profile: "1g.10gb", resources: corev1.ResourceList{"nvidia.com/mig-1g.10gb": resource.MustParse("2")}},
+		{mode: "time_slicing", resources: corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("2")},
+			nodeSelector: map[string]string{volcano.GPUSharingStrategyLabel: "time-slicing"}},
+		{mode: "vgpu", memoryGb: "7.5", resources: corev1.ResourceList{
+			"volcano.sh/vgpu-number": resource.MustParse("2"), "volcano.sh/vgpu-memory": resource.MustParse("7680")}},
+	}
+	for _, c := range cases {
+		job := newDistributedJob("notebook", "pytorch", "single", 1, 0, 0, 2)
+		job.GpuType, job.GpuSharingMode, job.GpuMigProfile, job.GpuMemoryGb = "A100", c.mode, c.profile, c.memoryGb
+		spec, err := scheduler.BuildTrainingJobSpec(job, testNamespace)
+		s.Require().NoError(err, c.mode)
+		vcSpec := volcano.NewJobManager(nil).BuildVolcanoJobSpec(spec)
+		s.Require().NotEmpty(vcSpec.Tasks)
+
+		task := vcSpec.Tasks[0]
+		resources := task.Template.Spec.Containers[0].Resources
+		for name, quantity := range c.resources {
+			limit := resources.Limits[name]
+			s.Equal(quantity.String(), limit.String(), "%s %s", c.mode, name)
+			s.Contains(resources.Requests, name, c.mode)
+		}
+		s.Len(resources.Limits, len(c.resources)+2, c.mode) // 另有cpu和memory
+		for key, value := range c.nodeSelector {
+			s.Equal(value, task.Template.NodeSelector[key], c.mode)
+		}
+		if c.nodeSelector == nil {
+			s.NotContains(task.Template.NodeSelector, volcano.GPUSharingStrategyLabel, c.mode)
+		}
+	}
+
+	// 共享配置不完整时拒绝构建
+	for _, job := range []*model.VtTrainingJobs{
+		{GpuCount: 1, GpuSharingMode: "mig"},
+		{GpuCount: 1, GpuSharingMode: "mig", GpuMigProfile: "10gb"},
+		{GpuCount: 1, GpuSharingMode: "vgpu"},
+		{GpuCount: 1, GpuSharingMode: "time_slicing", GpuMigProfile: "1g.10gb"},
+		{GpuCount: 1, GpuSharingMode: "mps"},
+	} {
+		job.Name, job.Framework, job.Image, job.EntryPoint = "bad", "pytorch", "train:latest", "train.py"
+		_, err := scheduler.BuildTrainingJobSpec(job, testNamespace)
+		s.Error(err, "%s/%s", job.GpuSharingMode, job.GpuMigProfile)
+	}
+}
+
+// TestAllocateByNodeSharingMode GPU管理器只在共享方式一致的节点上按共享单元分配
+func (s *TestGpuSharingSuite) TestAllocateByNodeSharingMode() {
+	kube := k8sfake.NewSimpleClientset()
+	addNode := func(name string, labels map[string]string, resources corev1.ResourceList) {
+		_, err := kube.CoreV1().Nodes().Create(context.Background(), &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Status: corev1.NodeStatus{
+				Capacity:    resources,
+				Allocatable: resources,
+				Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		}, metav1.CreateOptions{})
+		s.Require().NoError(err)
+	}
+	addNode("a100-whole", nil, corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("8")})
+	addNode("a100-mig", map[string]string{volcano.GPUCountLabel: "2", volcano.MIGStrategyLabel: "mixed"},
+		corev1.ResourceList{"nvidia.com/mig-1g.10gb": resource.MustParse("14")})
+	addNode("t4-shared", map[string]string{volcano.GPUCountLabel: "4", volcano.GPUReplicasLabel: "4", volcano.GPUSharingStrategyLabel: "time-slicing"},
+		corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("16")})
+	addNode("v100-vgpu", map[string]string{volcano.GPUCountLabel: "2"}, corev1.ResourceList{
+		"volcano.sh/vgpu-number": resource.MustParse("20"), "volcano.sh/vgpu-memory": resource.MustParse("65536")})
+	manager := volcano.NewGPUManager(volcano.NewClientWithClientsets(vcfake.NewSimpleClientset(), kube, testNamespace))
+
+	resources, err := manager.GetClusterGPUResources()
+	s.Require().NoError(err)
+	s.Require().Len(resources, 4)
+	modes := make(map[string]string)
+	for _, info := range resources {
+		modes[info.NodeName] = info.SharingMode
+	}
+	s.Equal(map[string]string{"a100-mig": "mig", "a100-whole": "exclusive", "t4-shared": "time_slicing", "v100-vgpu": "vgpu"}, modes)
+
+	cases := []struct {
+		sharing  volcano.GPUSharing
+		count    int32
+		node     string
+		resource string
+	}{
+		{count: 4, node: "a100-whole", resource: "nvidia.com/gpu"},
+		{sharing: volcano.GPUSharing{Mode: "mig", MIGProfile: "1g.10gb"}, count: 3, node: "a100-mig", resource: "nvidia.com/mig-1g.10gb"},
+		{sharing: volcano.GPUSharing{Mode: "time_slicing"}, count: 2, node: "t4-shared", resource: "nvidia.com/gpu"},
+		{sharing: volcano.GPUSharing{Mode: "vgpu", MemoryMB: 8192}, count: 8, node: "v100-vgpu", resource: "volcano.sh/vgpu-number"},
+	}
+	for _, c := range cases {
+		result, err := manager.AllocateGPUs(&volcano.GPUAllocationRequest{JobName: "notebook", TaskName: "worker", GPUCount: c.count, Sharing: c.sharing})
+		s.Require().NoError(err)
+		s.Require().True(result.Success, "%s: %s", c.sharing.Mode, result.Message)
+		s.Len(result.AllocatedGPUs, int(c.count))
+		for _, gpu := range result.AllocatedGPUs {
+			s.Equal(c.node, gpu.NodeName, c.sharing.Mode)
+			s.Equal(c.resource, gpu.Resource, c.sharing.Mode)
+		}
+	}
+
+	// 没有对应规格的MIG实例，或vGPU显存只够分配一个
+	result, err := manager.AllocateGPUs(&volcano.GPUAllocationRequest{GPUCount: 1, Sharing: volcano.GPUSharing{Mode: "mig", MIGProfile: "3g.40gb"}})
+	s.Require().NoError(err)
+	s.False(result.Success)
+	result, err = manager.AllocateGPUs(&volcano.GPUAllocationRequest{GPUCount: 2, Sharing: volcano.GPUSharing{Mode: "vgpu", MemoryMB: 40960}})
+	s.Require().NoError(err)
+	s.False(result.Success)
+	s.Contains(result.Message, "只能分配 1 个GPU")
+
+	result, err = manager.AllocateGPUs(&volcano.GPUAllocationRequest{GPUCount: 1, Sharing: volcano.GPUSharing{Mode: "mig"}})
+	s.Require().NoError(err)
+	s.False(result.Success)
+	s.Equal("共享配置错误", result.Reason)
+}
+
+// TestSharedAllocation 共享设备按MIG规格和vGPU显存记账，共享单元用完后才标记为已分配
+func (s *TestGpuSharingSuite) TestSharedAllocation() {
+	first, err := s.allocate(&types.AllocateGpuDeviceReq{DeviceIds: []int64{1}, SharingMode: "mig", MigProfile: "1g.10gb"})
+	s.Require().NoError(err)
+	s.Equal(model.GpuAllocationTypeShared, first.AllocationType)
+	s.Equal("mig", first.SharingMode)
+	s.Equal("1g.10gb", first.MigProfile)
+	s.Equal(model.GpuDeviceStatusAvailable, s.status(1))
+	device, err := s.devices.FindOne(1)
+	s.Require().NoError(err)
+	s.Zero(device.AllocationId)
+
+	_, err = s.allocate(&types.AllocateGpuDeviceReq{DeviceIds: []int64{1}, JobId: 6, SharingMode: "mig", MigProfile: "1g.10gb"})
+	s.Require().NoError(err)
+	_, err = s.allocate(&types.AllocateGpuDeviceReq{DeviceIds: []int64{1}, SharingMode: "mig", MigProfile: "1g.10gb"})
+	s.Equal(http.StatusConflict, s.bizError(err).GetHTTPStatus())
+	s.Contains(s.bizError(err).Message, "1g.10gb规格的MIG实例已用完(2/2)")
+	_, err = s.allocate(&types.AllocateGpuDeviceReq{DeviceIds: []int64{1}, SharingMode: "mig", MigProfile: "2g.20gb"})
+	s.Contains(s.bizError(err).Message, "设备未划分2g.20gb规格的MIG实例")
+
+	// 最后一个实例分配后设备已满，释放任一实例后恢复可用
+	_, err = s.allocate(&types.AllocateGpuDeviceReq{DeviceIds: []int64{1}, SharingMode: "mig", MigProfile: "3g.40gb"})
+	s.Require().NoError(err)
+	s.Equal(model.GpuDeviceStatusOccupied, s.status(1))
+	_, err = gpu_device.NewReleaseGpuDeviceLogic(context.Background(), s.svcCtx).ReleaseGpuDevice(&types.ReleaseGpuDeviceReq{ID: first.ID})
+	s.Require().NoError(err)
+	s.Equal(model.GpuDeviceStatusAvailable, s.status(1))
+
+	// vGPU按显存记账
+	_, err = s.allocate(&types.AllocateGpuDeviceReq{DeviceIds: []int64{2}, SharingMode: "vgpu", MemoryMb: 10240})
+	s.Require().NoError(err)
+	_, err = s.allocate(&types.AllocateGpuDeviceReq{DeviceIds: []int64{2}, SharingMode: "vgpu", MemoryMb: 8192})
+	s.Contains(s.bizError(err).Message, "显存不足，剩余6144MB，需要8192MB")
+	info, err := s.allocate(&types.AllocateGpuDeviceReq{DeviceIds: []int64{2}, SharingMode: "vgpu", MemoryMb: 6144})
+	s.Require().NoError(err)
+	s.Equal(6144, info.MemoryMb)
+	s.Equal(model.GpuDeviceStatusAvailable, s.status(2))
+
+	// 共享方式必须与设备一致
+	_, err = s.allocate(&types.AllocateGpuDeviceReq{DeviceIds: []int64{2}})
+	s.Contains(s.bizError(err).Message, "设备共享方式为vgpu，不支持exclusive分配")
+	_, err = s.allocate(&types.AllocateGpuDeviceReq{DeviceIds: []int64{3}, SharingMode: "time_slicing"})
+	s.Equal(http.StatusConflict, s.bizError(err).GetHTTPStatus())
+	info, err = s.allocate(&types.AllocateGpuDeviceReq{DeviceIds: []int64{3}, SharingMode: "exclusive"})
+	s.Require().NoError(err)
+	s.Equal(model.GpuAllocationTypeExclusive, info.AllocationType)
+	s.Equal(model.GpuDeviceStatusOccupied, s.status(3))
+}
+
+// TestInvalidSharingRequests 共享方式与参数不匹配时返回参数错误
+func (s *TestGpuSharingSuite) TestInvalidSharingRequests() {
+	for _, req := range []*types.AllocateGpuDeviceReq{
+		{DeviceIds: []int64{1}, SharingMode: "mig"},
+		{DeviceIds: []int64{1}, SharingMode: "mig", MigProfile: "1g"},
+		{DeviceIds: []int64{2}, SharingMode: "vgpu"},
+		{DeviceIds: []int64{3}, MemoryMb: 1024},
+		{DeviceIds: []int64{3}, SharingMode: "mps"},
+	} {
+		_, err := s.allocate(req)
+		s.Equal(http.StatusBadRequest, s.bizError(err).GetHTTPStatus(), "%+v", req)
+	}
+}
+
+func TestRunGpuSharingTests(t *testing.T) {
+	suite.Run(t, new(TestGpuSharingSuite))
+}
@@ -0,0 +1,80 @@
+package test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"api/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+// TestGpuTelemetryModelSuite 用sqlmock执行GPU监控采集使用的模型语句，并按建表脚本校验引用的列
+type TestGpuTelemetryModelSuite struct {
+	suite.Suite
+	db      *sql.DB
+	mock    sqlmock.Sqlmock
+	devices model.VtGpuDevicesModel
+	metrics model.VtMonitorMetricsModel
+	data    model.VtMonitorDataModel
+	now     time.Time
+}
+
+func (s *TestGpuTelemetryModelSuite) SetupTest() {
+	matcher, err := schemaMatcher()
+	s.Require().NoError(err)
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(matcher))
+	s.Require().NoError(err)
+	s.db = db
+	s.mock = mock
+	s.devices = model.NewVtGpuDevicesModel(db)
+	s.metrics = model.NewVtMonitorMetricsModel(db)
+	s.data = model.NewVtMonitorDataModel(db)
+	s.now = time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)
+}
+
+func (s *TestGpuTelemetryModelSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+	s.db.Close()
+}
+
+// TestUpdateTelemetry 只写入监控数据、心跳、UUID和PCIe总线ID，UUID未上报时写入NULL
+func (s *TestGpuTelemetryModelSuite) TestUpdateTelemetry() {
+	s.mock.ExpectExec(`UPDATE vt_gpu_devices SET device_uuid = NULLIF\(\?, ''\), pcie_bus_id = \?`).
+		WithArgs("", "0000:05:00.0", 81920, 21920, 60000, 312, 61, 98, 73, s.now, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := s.devices.UpdateTelemetry(&model.VtGpuDevices{Id: 1, PcieBusId: "0000:05:00.0", MemoryTotalMb: 81920, MemoryFreeMb: 21920,
+		MemoryUsedMb: 60000, PowerDrawW: 312, TemperatureC: 61, UtilizationGpu: 98, UtilizationMem: 73, LastHeartbeat: &s.now})
+	s.Require().NoError(err)
+}
+
+// TestCollectQueries 采集时查询在线设备和指标定义、批量写入监控数据的语句与建表脚本一致
+func (s *TestGpuTelemetryModelSuite) TestCollectQueries() {
+	s.mock.ExpectQuery(`FROM vt_gpu_devices WHERE status != \?`).WithArgs(model.GpuDeviceStatusOffline).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	devices, err := s.devices.FindAllOnline()
+	s.Require().NoError(err)
+	s.Empty(devices)
+
+	s.mock.ExpectQuery(`FROM vt_monitor_metrics WHERE name = \?`).WithArgs("gpu_usage_percent").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	_, err = s.metrics.FindOneByName("gpu_usage_percent")
+	s.ErrorIs(err, sql.ErrNoRows)
+
+	resourceId := int64(1)
+	args := make([]driver.Value, 20)
+	for i := range args {
+		args[i] = sqlmock.AnyArg()
+	}
+	s.mock.ExpectExec(`INSERT INTO vt_monitor_data`).WithArgs(args...).WillReturnResult(sqlmock.NewResult(1, 1))
+	s.Require().NoError(s.data.BatchInsert([]*model.VtMonitorData{{MetricId: 3, ResourceType: "gpu", ResourceId: &resourceId,
+		Value: 98, Timestamp: s.now, CollectionTime: s.now}}))
+}
+
+func TestRunGpuTelemetryModelTests(t *testing.T) {
+	suite.Run(t, new(TestGpuTelemetryModelSuite))
+}
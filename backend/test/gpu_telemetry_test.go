@@ -0,0 +1,318 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"api/model"
+	"api/pkg/monitoring"
+	"api/pkg/scheduler"
+	"api/pkg/volcano"
+
+	"github.com/stretchr/testify/suite"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	vcfake "volcano.sh/apis/pkg/client/clientset/versioned/fake"
+)
+
+// dcgmGPU DCGM exporter导出的一个GPU
+type dcgmGPU struct {
+	host, uuid, pci string
+	index           int
+	pod             string
+	util, memUtil   float64
+	fbUsed, fbFree  float64
+	power, temp     float64
+	xid, ecc, remap float64
+}
+
+func (g dcgmGPU) labels() string {
+	labels := fmt.Sprintf(`gpu="%d",UUID="%s",pci_bus_id="%s",device="nvidia%d",modelName="NVIDIA A100-SXM4-80GB",Hostname="%s"`,
+		g.index, g.uuid, g.pci, g.index, g.host)
+	if g.pod != "" {
+		labels += fmt.Sprintf(`,container="trainer",namespace="%s",pod="%s"`, testNamespace, g.pod)
+	}
+	return labels
+}
+
+// dcgmExporterText 生成DCGM exporter /metrics的Prometheus文本格式
+func dcgmExporterText(gpus ...dcgmGPU) string {
+	var b strings.Builder
+	fields := []struct {
+		name  string
+		value func(dcgmGPU) float64
+	}{
+		{monitoring.DCGMGPUUtil, func(g dcgmGPU) float64 { return g.util }},
+		{monitoring.DCGMMemCopyUtil, func(g dcgmGPU) float64 { return g.memUtil }},
+		{monitoring.DCGMFBUsed, func(g dcgmGPU) float64 { return g.fbUsed }},
+		{monitoring.DCGMFBFree, func(g dcgmGPU) float64 { return g.fbFree }},
+		{monitoring.DCGMPowerUsage, func(g dcgmGPU) float64 { return g.power }},
+		{monitoring.DCGMGPUTemp, func(g dcgmGPU) float64 { return g.temp }},
+		{monitoring.DCGMXIDErrors, func(g dcgmGPU) float64 { return g.xid }},
+		{monitoring.DCGMECCDBEVolTotal, func(g dcgmGPU) float64 { return g.ecc }},
+		{monitoring.DCGMRowRemapFailure, func(g dcgmGPU) float64 { return g.remap }},
+	}
+	for _, field := range fields {
+		fmt.Fprintf(&b, "# HELP %s DCGM field.\n# TYPE %s gauge\n", field.name, field.name)
+		for _, g := range gpus {
+			fmt.Fprintf(&b, "%s{%s} %g\n", field.name, g.labels(), field.value(g))
+		}
+	}
+	// exporter导出的其他指标会被忽略
+	b.WriteString("# HELP DCGM_FI_DEV_SM_CLOCK SM clock frequency (in MHz).\n# TYPE DCGM_FI_DEV_SM_CLOCK gauge\n")
+	fmt.Fprintf(&b, "DCGM_FI_DEV_SM_CLOCK{%s} 1410\n", gpus[0].labels())
+	return b.String()
+}
+
+type TestGpuTelemetrySuite struct {
+	suite.Suite
+	nodes   *fakeGpuNodesModel
+	devices *fakeGpuDevicesModel
+	metrics *fakeMonitorMetricsModel
+	data    *fakeMonitorDataModel
+	gpus    []dcgmGPU
+	server  *httptest.Server
+}
+
+func (s *TestGpuTelemetrySuite) SetupTest() {
+	s.nodes = &fakeGpuNodesModel{}
+	s.devices = &fakeGpuDevicesModel{}
+	s.metrics = &fakeMonitorMetricsModel{}
+	s.data = &fakeMonitorDataModel{}
+
+	// 温度指标未登记，不写入该指标
+	s.metrics.register(scheduler.GPUUsageMetric, scheduler.GPUMemoryUsageMetric, scheduler.GPUMemoryUsedMetric, scheduler.GPUPowerMetric)
+
+	_, err := s.nodes.Insert(&model.VtGpuNodes{ClusterId: 1, Name: "gpu-1", Status: model.GpuNodeStatusOnline})
+	s.Require().NoError(err)
+	devices := []*model.VtGpuDevices{
+		// 库存同步已从节点标签得到UUID
+		{ClusterId: 1, NodeId: 1, DeviceIndex: 0, DeviceName: "gpu-1-gpu0", DeviceUuid: "GPU-aaa", Status: model.GpuDeviceStatusAvailable},
+		// 只登记了4位域号的PCIe总线ID
+		{ClusterId: 1, NodeId: 1, DeviceIndex: 1, DeviceName: "gpu-1-gpu1", PcieBusId: "0000:07:00.0", Status: model.GpuDeviceStatusOccupied},
+		// 只能按设备索引匹配
+		{ClusterId: 1, NodeId: 1, DeviceIndex: 2, DeviceName: "gpu-1-gpu2", MemoryTotalMb: 81920, Status: model.GpuDeviceStatusAvailable},
+		{ClusterId: 1, NodeId: 1, DeviceIndex: 3, DeviceName: "gpu-1-gpu3", DeviceUuid: "GPU-ddd", Status: model.GpuDeviceStatusOffline},
+	}
+	for _, device := range devices {
+		_, err := s.devices.Insert(device)
+		s.Require().NoError(err)
+	}
+
+	s.gpus = []dcgmGPU{
+		{host: "gpu-1", uuid: "GPU-aaa", pci: "00000000:05:00.0", index: 0, util: 90, memUtil: 40, fbUsed: 60000, fbFree: 21920, power: 312.4, temp: 61},
+		{host: "gpu-1", uuid: "GPU-bbb", pci: "00000000:07:00.0", index: 1, pod: "llama-worker-0", util: 70, memUtil: 30, fbUsed: 20480, fbFree: 61440, power: 250.6, temp: 66},
+		{host: "gpu-1", uuid: "GPU-ccc", pci: "00000000:0A:00.0", index: 2, util: 20, memUtil: 5, fbUsed: 1024, fbFree: 80896, power: 80, temp: 40},
+		{host: "gpu-9", uuid: "GPU-zzz", pci: "00000000:05:00.0", index: 0, util: 10, memUtil: 1, fbUsed: 0, fbFree: 81920, power: 60, temp: 35},
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		fmt.Fprint(w, dcgmExporterText(s.gpus...))
+	}))
+}
+
+func (s *TestGpuTelemetrySuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *TestGpuTelemetrySuite) collector(source monitoring.DCGMSource) *scheduler.GPUTelemetryCollector {
+	return scheduler.NewGPUTelemetryCollector(source, s.devices, s.nodes, s.metrics, s.data, scheduler.GPUTelemetryConfig{})
+}
+
+func (s *TestGpuTelemetrySuite) metricId(name string) int64 {
+	metric, err := s.metrics.FindOneByName(name)
+	s.Require().NoError(err)
+	return metric.Id
+}
+
+// TestParseDCGMMetrics 按UUID合并同一GPU的各指标，规范化PCIe总线ID并保留使用GPU的Pod
+func (s *TestGpuTelemetrySuite) TestParseDCGMMetrics() {
+	samples, err := monitoring.ParseDCGMMetrics(strings.NewReader(dcgmExporterText(s.gpus...)))
+	s.Require().NoError(err)
+	s.Require().Len(samples, 4)
+
+	sample := samples[2]
+	s.Equal("GPU-ccc", sample.UUID)
+	s.Equal("0000:0a:00.0", sample.PCIBusID)
+	s.Equal("gpu-1", sample.Hostname)
+	s.Equal(2, sample.GPUIndex)
+	s.Equal("NVIDIA A100-SXM4-80GB", sample.ModelName)
+	s.Len(sample.Values, len(monitoring.DCGMFields))
+	s.Equal("llama-worker-0", samples[1].Pod)
+	s.Equal(testNamespace, samples[1].Namespace)
+	s.Equal("gpu-9", samples[3].Hostname)
+
+	_, err = monitoring.ParseDCGMMetrics(strings.NewReader("DCGM_FI_DEV_GPU_UTIL{gpu=\"0\" 1\n"))
+	s.Error(err)
+}
+
+// TestCollectFromExporter 依次按UUID、PCIe总线ID和设备索引匹配设备，更新监控字段和心跳并写入监控数据
+func (s *TestGpuTelemetrySuite) TestCollectFromExporter() {
+	now := time.Now()
+	collector := s.collector(monitoring.NewDCGMExporterSource([]string{s.server.URL + "/metrics"}, time.Second))
+	report, err := collector.CollectOnce(now)
+	s.Require().NoError(err)
+	s.Equal(4, report.Samples)
+	s.Equal(3, report.Matched)
+	s.Equal(1, report.Unmatched)
+	s.Equal(3*4, report.Points)
+
+	byUUID, err := s.devices.FindOne(1)
+	s.Require().NoError(err)
+	s.Equal(90, byUUID.UtilizationGpu)
+	s.Equal(40, byUUID.UtilizationMem)
+	s.Equal(60000, byUUID.MemoryUsedMb)
+	s.Equal(21920, byUUID.MemoryFreeMb)
+	s.Equal(81920, byUUID.MemoryTotalMb)
+	s.Equal(312, byUUID.PowerDrawW)
+	s.Equal(61, byUUID.TemperatureC)
+	s.Equal("0000:05:00.0", byUUID.PcieBusId)
+	s.Require().NotNil(byUUID.LastHeartbeat)
+	s.True(byUUID.LastHeartbeat.Equal(now))
+
+	byPCI, err := s.devices.FindOne(2)
+	s.Require().NoError(err)
+	s.Equal("GPU-bbb", byPCI.DeviceUuid)
+	s.Equal("0000:07:00.0", byPCI.PcieBusId)
+	s.Equal(70, byPCI.UtilizationGpu)
+	s.Equal(251, byPCI.PowerDrawW)
+	s.Equal(model.GpuDeviceStatusOccupied, byPCI.Status)
+
+	byIndex, err := s.devices.FindOne(3)
+	s.Require().NoError(err)
+	s.Equal("GPU-ccc", byIndex.DeviceUuid)
+	s.Equal("0000:0a:00.0", byIndex.PcieBusId)
+	s.Equal(20, byIndex.UtilizationGpu)
+
+	offline, err := s.devices.FindOne(4)
+	s.Require().NoError(err)
+	s.Zero(offline.UtilizationGpu)
+	s.Nil(offline.LastHeartbeat)
+
+	values := s.data.byResource(2)
+	s.Equal(70.0, values[s.metricId(scheduler.GPUUsageMetric)])
+	s.Equal(20480.0, values[s.metricId(scheduler.GPUMemoryUsedMetric)])
+	s.Equal(25.0, values[s.metricId(scheduler.GPUMemoryUsageMetric)])
+	s.Equal(250.6, values[s.metricId(scheduler.GPUPowerMetric)])
+	s.Len(values, 4)
+
+	var point *model.VtMonitorData
+	for _, p := range s.data.points {
+		if *p.ResourceId == 2 {
+			point = p
+			break
+		}
+	}
+	s.Require().NotNil(point)
+	s.Equal("gpu", point.ResourceType)
+	s.Equal("gpu-1-gpu1", point.ResourceName)
+	s.Equal("GPU-bbb", point.InstanceId)
+	labels, err := point.GetLabelsMap()
+	s.Require().NoError(err)
+	s.Equal("gpu-1", labels["node"])
+	s.Equal("llama-worker-0", labels["pod"])
+	s.Equal(testNamespace, labels["namespace"])
+}
+
+// TestCollectFromPrometheus 从Prometheus查询DCGM指标，节点名优先使用重标记得到的kubernetes_node标签
+func (s *TestGpuTelemetrySuite) TestCollectFromPrometheus() {
+	var queries []string
+	prom := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Require().NoError(r.ParseForm())
+		queries = append(queries, r.Form.Get("query"))
+		var results []string
+		for _, field := range []string{monitoring.DCGMGPUUtil, monitoring.DCGMFBUsed, monitoring.DCGMFBFree} {
+			results = append(results, fmt.Sprintf(`{"metric":{"__name__":"%s","gpu":"0","UUID":"GPU-aaa","pci_bus_id":"00000000:05:00.0",`+
+				`"Hostname":"dcgm-exporter-7x2kq","kubernetes_node":"gpu-1","instance":"10.0.0.1:9400"},"value":[%d,"%d"]}`,
+				field, time.Now().Unix(), map[string]int{monitoring.DCGMGPUUtil: 55, monitoring.DCGMFBUsed: 4096, monitoring.DCGMFBFree: 77824}[field]))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[%s]}}`, strings.Join(results, ","))
+	}))
+	defer prom.Close()
+
+	client, err := monitoring.NewPrometheusClient(prom.URL)
+	s.Require().NoError(err)
+	report, err := s.collector(monitoring.NewPrometheusDCGMSource(client)).CollectOnce(time.Now())
+	s.Require().NoError(err)
+	s.Require().Len(queries, 1)
+	s.Contains(queries[0], monitoring.DCGMGPUUtil)
+	s.Equal(1, report.Matched)
+	s.Equal(3, report.Points)
+
+	device, err := s.devices.FindOne(1)
+	s.Require().NoError(err)
+	s.Equal(55, device.UtilizationGpu)
+	s.Equal(4096, device.MemoryUsedMb)
+	// 未导出的指标保持原值
+	s.Zero(device.TemperatureC)
+	s.Equal(5.0, s.data.byResource(1)[s.metricId(scheduler.GPUMemoryUsageMetric)])
+}
+
+// TestExporterUnavailable exporter全部不可用时返回错误，部分不可用时使用其余exporter的数据
+func (s *TestGpuTelemetrySuite) TestExporterUnavailable() {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	_, err := s.collector(monitoring.NewDCGMExporterSource([]string{down.URL}, time.Second)).CollectOnce(time.Now())
+	s.Error(err)
+	s.Empty(s.data.points)
+
+	report, err := s.collector(monitoring.NewDCGMExporterSource([]string{down.URL, s.server.URL}, time.Second)).CollectOnce(time.Now())
+	s.Require().NoError(err)
+	s.Equal(3, report.Matched)
+}
+
+// TestGPUManagerTelemetry 节点GPU使用率、显存、功耗和温度取自最近一次采集的汇总数据
+func (s *TestGpuTelemetrySuite) TestGPUManagerTelemetry() {
+	kube := k8sfake.NewSimpleClientset(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "gpu-1"},
+		Status: corev1.NodeStatus{
+			Capacity:    corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("4")},
+			Allocatable: corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("4")},
+			Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	})
+	manager := volcano.NewGPUManager(volcano.NewClientWithClientsets(vcfake.NewSimpleClientset(), kube, testNamespace))
+
+	collector := s.collector(monitoring.NewDCGMExporterSource([]string{s.server.URL}, time.Second))
+	manager.SetTelemetry(collector)
+	resources, err := manager.GetClusterGPUResources()
+	s.Require().NoError(err)
+	s.Require().Len(resources, 1)
+	s.Zero(resources[0].GPUUtilization)
+	s.Equal("N/A", resources[0].Temperature)
+
+	_, err = collector.CollectOnce(time.Now())
+	s.Require().NoError(err)
+	resources, err = manager.GetClusterGPUResources()
+	s.Require().NoError(err)
+	info := resources[0]
+	s.Equal(60.0, info.GPUUtilization)
+	s.Equal("81504", info.MemoryUsed)
+	s.Equal("164256", info.MemoryFree)
+	s.Equal("643.0W", info.PowerDraw)
+	s.Equal("66℃", info.Temperature)
+
+	stats, ok := collector.NodeGPUStats("gpu-9")
+	s.True(ok)
+	s.Equal(10.0, stats.Utilization)
+
+	// 超过3个采集周期未更新的数据不再使用
+	_, err = collector.CollectOnce(time.Now().Add(-5 * time.Minute))
+	s.Require().NoError(err)
+	_, ok = collector.NodeGPUStats("gpu-1")
+	s.False(ok)
+}
+
+func TestRunGpuTelemetryTests(t *testing.T) {
+	suite.Run(t, new(TestGpuTelemetrySuite))
+}
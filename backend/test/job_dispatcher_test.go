@@ -0,0 +1,200 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"api/model"
+	"api/pkg/scheduler"
+	"api/pkg/volcano"
+
+	"github.com/stretchr/testify/suite"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	vcjob "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+	vcfake "volcano.sh/apis/pkg/client/clientset/versioned/fake"
+)
+
+const testNamespace = "volctrain-test"
+
+// TestJobDispatcherSuite 作业派发器测试套件
+type TestJobDispatcherSuite struct {
+	suite.Suite
+	vcClient *vcfake.Clientset
+	jobModel *fakeTrainingJobsModel
+}
+
+// SetupTest 每个用例使用独立的fake客户端
+func (s *TestJobDispatcherSuite) SetupTest() {
+	s.vcClient = vcfake.NewSimpleClientset()
+	s.jobModel = newFakeTrainingJobsModel()
+}
+
+func (s *TestJobDispatcherSuite) newDispatcher() *scheduler.JobDispatcher {
+	client := volcano.NewClientWithClientsets(s.vcClient, k8sfake.NewSimpleClientset(), testNamespace)
+	machine := scheduler.NewJobStateMachine(s.jobModel, s.jobModel.transitions, client)
+	return scheduler.NewJobDispatcher(s.jobModel, machine, volcano.NewJobManager(client), nil, scheduler.DispatcherConfig{
+		Namespace:         testNamespace,
+		MaxSubmitAttempts: 3,
+		BackoffBase:       time.Nanosecond,
+		BackoffMax:        time.Nanosecond,
+	})
+}
+
+func newPendingJob(id int64, name string) *model.VtTrainingJobs {
+	return &model.VtTrainingJobs{
+		Id:           id,
+		Name:         name,
+		JobType:      "distributed",
+		Framework:    "pytorch",
+		EntryPoint:   "train.py",
+		Image:        "pytorch/pytorch:2.1.0-cuda11.8-cudnn8-runtime",
+		CpuCores:     "4.000",
+		MemoryGb:     "16.00",
+		GpuCount:     1,
+		GpuType:      "A100",
+		MasterCount:  1,
+		WorkerCount:  2,
+		MinAvailable: 0,
+		QueueName:    "high_priority",
+		EnvVars:      `{"EPOCHS":"10"}`,
+		CommandArgs:  `["--lr","0.01"]`,
+		Status:       "pending",
+		SubmittedAt:  time.Now(),
+	}
+}
+
+// TestDispatchPendingJob 测试pending作业提交到Volcano并回写状态
+func (s *TestJobDispatcherSuite) TestDispatchPendingJob() {
+	s.jobModel = newFakeTrainingJobsModel(newPendingJob(1, "ResNet_50 Train"))
+	dispatcher := s.newDispatcher()
+
+	submitted, err := dispatcher.DispatchOnce()
+	s.NoError(err)
+	s.Equal(1, submitted)
+
+	job := s.jobModel.get(1)
+	s.Equal("queued", job.Status)
+	s.Equal("resnet-50-train-1", job.VolcanoJobName)
+	s.Equal(testNamespace, job.Namespace)
+	s.NotNil(job.QueuedAt)
+	s.NotNil(job.ScheduledAt)
+
+	vcJob, err := s.vcClient.BatchV1alpha1().Jobs(testNamespace).Get(context.Background(), job.VolcanoJobName, metav1.GetOptions{})
+	s.Require().NoError(err)
+	s.Equal("high-priority", vcJob.Spec.Queue)
+	s.Equal(int32(3), vcJob.Spec.MinAvailable)
+	s.Equal("1", vcJob.Labels[scheduler.JobIDLabelKey])
+	s.Len(vcJob.Spec.Tasks, 2)
+
+	// 已提交的作业不会被重复派发
+	submitted, err = dispatcher.DispatchOnce()
+	s.NoError(err)
+	s.Equal(0, submitted)
+}
+
+// TestResumeAfterRestart 测试重启后继续处理已入队未确认的作业
+func (s *TestJobDispatcherSuite) TestResumeAfterRestart() {
+	job := newPendingJob(2, "bert")
+	job.Status = "queued"
+	job.VolcanoJobName = "bert-2"
+	s.jobModel = newFakeTrainingJobsModel(job)
+
+	// 上次进程已创建Volcano作业但未来得及回写
+	_, err := s.vcClient.BatchV1alpha1().Jobs(testNamespace).Create(context.Background(), &vcjob.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "bert-2", Namespace: testNamespace},
+	}, metav1.CreateOptions{})
+	s.Require().NoError(err)
+
+	submitted, err := s.newDispatcher().DispatchOnce()
+	s.NoError(err)
+	s.Equal(1, submitted)
+	s.NotNil(s.jobModel.get(2).ScheduledAt)
+}
+
+// TestRetryTransientError 测试瞬时错误重试
+func (s *TestJobDispatcherSuite) TestRetryTransientError() {
+	s.jobModel = newFakeTrainingJobsModel(newPendingJob(3, "gpt"))
+	failures := 1
+	s.vcClient.PrependReactor("create", "jobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if failures > 0 {
+			failures--
+			return true, nil, apierrors.NewServiceUnavailable("apiserver is restarting")
+		}
+		return false, nil, nil
+	})
+	dispatcher := s.newDispatcher()
+
+	submitted, err := dispatcher.DispatchOnce()
+	s.NoError(err)
+	s.Equal(0, submitted)
+	job := s.jobModel.get(3)
+	s.Equal("queued", job.Status)
+	s.Nil(job.ScheduledAt)
+
+	time.Sleep(time.Millisecond)
+	submitted, err = dispatcher.DispatchOnce()
+	s.NoError(err)
+	s.Equal(1, submitted)
+	s.NotNil(s.jobModel.get(3).ScheduledAt)
+}
+
+// TestRejectedJobFails 测试不可重试的错误直接标记失败
+func (s *TestJobDispatcherSuite) TestRejectedJobFails() {
+	s.jobModel = newFakeTrainingJobsModel(newPendingJob(4, "bad"))
+	s.vcClient.PrependReactor("create", "jobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewInvalid(schema.GroupKind{Group: "batch.volcano.sh", Kind: "Job"}, "bad-4", nil)
+	})
+
+	submitted, err := s.newDispatcher().DispatchOnce()
+	s.NoError(err)
+	s.Equal(0, submitted)
+
+	job := s.jobModel.get(4)
+	s.Equal("failed", job.Status)
+	s.Equal("SUBMIT_REJECTED", job.ErrorCode)
+	s.NotNil(job.EndTime)
+}
+
+// TestInvalidSpecFails 测试作业配置无法解析时标记失败
+func (s *TestJobDispatcherSuite) TestInvalidSpecFails() {
+	job := newPendingJob(5, "broken")
+	job.EnvVars = "not-json"
+	s.jobModel = newFakeTrainingJobsModel(job)
+
+	_, err := s.newDispatcher().DispatchOnce()
+	s.NoError(err)
+	s.Equal("INVALID_SPEC", s.jobModel.get(5).ErrorCode)
+}
+
+// TestCancelledDuringSubmit 测试提交期间作业被取消时删除已创建的Volcano作业
+func (s *TestJobDispatcherSuite) TestCancelledDuringSubmit() {
+	s.jobModel = newFakeTrainingJobsModel(newPendingJob(6, "cancel"))
+	s.vcClient.PrependReactor("create", "jobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		s.jobModel.mu.Lock()
+		s.jobModel.jobs[6].Status = "cancelled"
+		s.jobModel.mu.Unlock()
+		return false, nil, nil
+	})
+
+	submitted, err := s.newDispatcher().DispatchOnce()
+	s.NoError(err)
+	s.Equal(0, submitted)
+
+	job := s.jobModel.get(6)
+	s.Equal("cancelled", job.Status)
+	s.Nil(job.ScheduledAt)
+
+	_, err = s.vcClient.BatchV1alpha1().Jobs(testNamespace).Get(context.Background(), job.VolcanoJobName, metav1.GetOptions{})
+	s.True(apierrors.IsNotFound(err))
+}
+
+// TestRunJobDispatcherTests 运行作业派发器测试
+func TestRunJobDispatcherTests(t *testing.T) {
+	suite.Run(t, new(TestJobDispatcherSuite))
+}
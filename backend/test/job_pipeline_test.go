@@ -0,0 +1,169 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"api/model"
+	"api/pkg/scheduler"
+	"api/pkg/volcano"
+
+	"github.com/stretchr/testify/suite"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	vcfake "volcano.sh/apis/pkg/client/clientset/versioned/fake"
+)
+
+// TestJobPipelineSuite 作业流水线测试套件
+type TestJobPipelineSuite struct {
+	suite.Suite
+	vcClient        *vcfake.Clientset
+	jobModel        *fakeTrainingJobsModel
+	relationModel   *fakeRelationsModel
+	checkpointModel *fakeCheckpointsModel
+	pipeline        *scheduler.JobPipeline
+	dispatcher      *scheduler.JobDispatcher
+}
+
+// setup 创建 preprocess -> train -> evaluate 三个节点的流水线
+func (s *TestJobPipelineSuite) setup(statuses ...string) {
+	s.vcClient = vcfake.NewSimpleClientset()
+	s.jobModel = newFakeTrainingJobsModel()
+	for i, name := range []string{"preprocess", "train", "evaluate"} {
+		job := newPendingJob(int64(i+1), name)
+		job.Status = statuses[i]
+		s.jobModel.jobs[job.Id] = job
+	}
+	s.relationModel = &fakeRelationsModel{}
+	s.depend(2, 1, "")
+	s.depend(3, 2, `{"alias":"model","outputs":{"MODEL_DIR":"checkpoint_path"}}`)
+	s.checkpointModel = &fakeCheckpointsModel{}
+
+	client := volcano.NewClientWithClientsets(s.vcClient, k8sfake.NewSimpleClientset(), testNamespace)
+	machine := scheduler.NewJobStateMachine(s.jobModel, s.jobModel.transitions, client)
+	s.pipeline = scheduler.NewJobPipeline(s.jobModel, s.relationModel, s.checkpointModel, machine)
+	s.dispatcher = scheduler.NewJobDispatcher(s.jobModel, machine, volcano.NewJobManager(client), s.pipeline, scheduler.DispatcherConfig{
+		Namespace: testNamespace,
+	})
+}
+
+func (s *TestJobPipelineSuite) depend(childId, parentId int64, metadata string) {
+	_, err := s.relationModel.Insert(&model.VtTrainingJobRelations{
+		JobId:        childId,
+		EntityType:   scheduler.JobEntityType,
+		EntityId:     parentId,
+		RelationType: scheduler.DependsOnRelation,
+		Metadata:     metadata,
+	})
+	s.Require().NoError(err)
+}
+
+func (s *TestJobPipelineSuite) succeed(jobId int64, outputPath string) {
+	s.jobModel.mu.Lock()
+	defer s.jobModel.mu.Unlock()
+	s.jobModel.jobs[jobId].Status = "succeeded"
+	s.jobModel.jobs[jobId].OutputPath = outputPath
+}
+
+// containerEnv 读取已提交Volcano作业第一个容器的环境变量
+func (s *TestJobPipelineSuite) containerEnv(jobId int64) map[string]string {
+	job := s.jobModel.get(jobId)
+	vcJob, err := s.vcClient.BatchV1alpha1().Jobs(testNamespace).Get(context.Background(), job.VolcanoJobName, metav1.GetOptions{})
+	s.Require().NoError(err)
+
+	env := make(map[string]string)
+	for _, e := range vcJob.Spec.Tasks[0].Template.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	return env
+}
+
+// TestChildWaitsForParents 子作业在上游成功后才派发，并获得上游输出
+func (s *TestJobPipelineSuite) TestChildWaitsForParents() {
+	s.setup("pending", "pending", "pending")
+
+	submitted, err := s.dispatcher.DispatchOnce()
+	s.Require().NoError(err)
+	s.Equal(1, submitted)
+	s.Equal("queued", s.jobModel.get(1).Status)
+	s.Equal("pending", s.jobModel.get(2).Status)
+
+	s.succeed(1, "/data/preprocess/out")
+	submitted, err = s.dispatcher.DispatchOnce()
+	s.Require().NoError(err)
+	s.Equal(1, submitted)
+	s.Equal("pending", s.jobModel.get(3).Status)
+
+	env := s.containerEnv(2)
+	s.Equal("/data/preprocess/out", env["UPSTREAM_PREPROCESS_OUTPUT_PATH"])
+	s.Equal("/data/preprocess/out", env["UPSTREAM_OUTPUT_PATH"])
+	s.Equal("1", env["UPSTREAM_PREPROCESS_JOB_ID"])
+	s.Equal("10", env["EPOCHS"], "作业自身的环境变量保留")
+
+	s.checkpointModel.checkpoints = []*model.VtTrainingCheckpoints{
+		{Id: 1, JobId: 2, StoragePath: "/ckpt/step-200", GlobalStep: 200, Status: "saved", IsBest: true},
+		{Id: 2, JobId: 2, StoragePath: "/ckpt/step-300", GlobalStep: 300, Status: "saved"},
+	}
+	s.succeed(2, "/data/train/out")
+	submitted, err = s.dispatcher.DispatchOnce()
+	s.Require().NoError(err)
+	s.Equal(1, submitted)
+
+	env = s.containerEnv(3)
+	s.Equal("/ckpt/step-200", env["UPSTREAM_MODEL_CHECKPOINT_PATH"], "传递最佳检查点")
+	s.Equal("/ckpt/step-200", env["MODEL_DIR"])
+	s.Equal("/data/train/out", env["UPSTREAM_MODEL_OUTPUT_PATH"])
+}
+
+// TestPipelineStatusAndRerun 上游失败时下游阻塞，重新运行失败节点后流水线继续
+func (s *TestJobPipelineSuite) TestPipelineStatusAndRerun() {
+	s.setup("succeeded", "failed", "pending")
+
+	pipeline, err := s.pipeline.Graph(3)
+	s.Require().NoError(err)
+	s.Equal("failed", pipeline.Status)
+	s.Require().Len(pipeline.Nodes, 3)
+	s.Equal([]int64{1, 2, 3}, []int64{pipeline.Nodes[0].JobId, pipeline.Nodes[1].JobId, pipeline.Nodes[2].JobId})
+	s.Equal(scheduler.PipelineNodeBlocked, pipeline.Nodes[2].State)
+	s.Equal([]int64{2}, pipeline.Nodes[2].DependsOn)
+
+	restarted, err := s.pipeline.Rerun(2, false, scheduler.SystemOperator, "修复数据后重跑")
+	s.Require().NoError(err)
+	s.Equal([]int64{2}, restarted)
+	s.Equal("succeeded", s.jobModel.get(1).Status, "上游作业不受影响")
+
+	pipeline, err = s.pipeline.Graph(1)
+	s.Require().NoError(err)
+	s.Equal("running", pipeline.Status)
+	s.Equal(scheduler.PipelineNodeReady, pipeline.Nodes[1].State)
+	s.Equal(scheduler.PipelineNodeWaiting, pipeline.Nodes[2].State)
+}
+
+// TestRerunDownstream 重新运行上游时可选择同时重跑已结束的下游作业
+func (s *TestJobPipelineSuite) TestRerunDownstream() {
+	s.setup("succeeded", "succeeded", "failed")
+
+	restarted, err := s.pipeline.Rerun(1, true, scheduler.SystemOperator, "数据更新")
+	s.Require().NoError(err)
+	s.Equal([]int64{1, 2, 3}, restarted)
+	for _, id := range restarted {
+		s.Equal("pending", s.jobModel.get(id).Status)
+	}
+}
+
+// TestCheckDependencyRejectsCycle 新增依赖不能形成环
+func (s *TestJobPipelineSuite) TestCheckDependencyRejectsCycle() {
+	s.setup("pending", "pending", "pending")
+
+	s.Error(s.pipeline.CheckDependency(1, 3))
+	s.Error(s.pipeline.CheckDependency(2, 2))
+	s.NoError(s.pipeline.CheckDependency(3, 1))
+
+	_, err := scheduler.ParseDependencyMetadata(`{"outputs":{"MODEL_DIR":"weights"}}`)
+	s.Error(err)
+}
+
+// TestRunJobPipelineTests 运行作业流水线测试
+func TestRunJobPipelineTests(t *testing.T) {
+	suite.Run(t, new(TestJobPipelineSuite))
+}
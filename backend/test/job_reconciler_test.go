@@ -0,0 +1,205 @@
+package test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"api/model"
+	"api/pkg/scheduler"
+	"api/pkg/volcano"
+
+	"github.com/stretchr/testify/suite"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	vcjob "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+	vcfake "volcano.sh/apis/pkg/client/clientset/versioned/fake"
+)
+
+// TestJobReconcilerSuite 作业状态同步器测试套件
+type TestJobReconcilerSuite struct {
+	suite.Suite
+	vcClient      *vcfake.Clientset
+	kubeClient    *k8sfake.Clientset
+	jobModel      *fakeTrainingJobsModel
+	instanceModel *fakeInstancesModel
+	reconciler    *scheduler.JobReconciler
+}
+
+// SetupTest 每个用例使用独立的fake客户端
+func (s *TestJobReconcilerSuite) SetupTest() {
+	s.vcClient = vcfake.NewSimpleClientset()
+	s.kubeClient = k8sfake.NewSimpleClientset()
+	s.jobModel = newFakeTrainingJobsModel()
+	s.instanceModel = &fakeInstancesModel{}
+	s.reconciler = nil
+}
+
+// TearDownTest 停止同步器
+func (s *TestJobReconcilerSuite) TearDownTest() {
+	if s.reconciler != nil {
+		s.reconciler.Stop()
+	}
+}
+
+func (s *TestJobReconcilerSuite) startReconciler() {
+	client := volcano.NewClientWithClientsets(s.vcClient, s.kubeClient, testNamespace)
+	machine := scheduler.NewJobStateMachine(s.jobModel, s.jobModel.transitions, client)
+	s.reconciler = scheduler.NewJobReconciler(s.jobModel, s.instanceModel, machine, s.vcClient, s.kubeClient, scheduler.ReconcilerConfig{
+		Namespace:      testNamespace,
+		ResyncInterval: 50 * time.Millisecond,
+	})
+	s.reconciler.Start()
+}
+
+// newSubmittedJob 在数据库和集群中创建已提交的作业
+func (s *TestJobReconcilerSuite) newSubmittedJob(id int64, name, status string, phase vcjob.JobPhase) *model.VtTrainingJobs {
+	job := newPendingJob(id, name)
+	now := time.Now()
+	job.Status = status
+	job.Namespace = testNamespace
+	job.VolcanoJobName = scheduler.BuildVolcanoJobName(job)
+	job.QueuedAt = &now
+	job.ScheduledAt = &now
+	s.jobModel.jobs[id] = job
+
+	_, err := s.vcClient.BatchV1alpha1().Jobs(testNamespace).Create(context.Background(), &vcjob.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      job.VolcanoJobName,
+			Namespace: testNamespace,
+			Labels:    map[string]string{scheduler.JobIDLabelKey: strconv.FormatInt(id, 10)},
+		},
+		Status: vcjob.JobStatus{State: vcjob.JobState{Phase: phase}},
+	}, metav1.CreateOptions{})
+	s.Require().NoError(err)
+	return job
+}
+
+// createPod 创建属于作业的Pod
+func (s *TestJobReconcilerSuite) createPod(job *model.VtTrainingJobs, task string, index int, status corev1.PodStatus) {
+	start := metav1.NewTime(time.Now().Add(-time.Minute))
+	status.StartTime = &start
+
+	_, err := s.kubeClient.CoreV1().Pods(testNamespace).Create(context.Background(), &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      job.VolcanoJobName + "-" + task + "-" + strconv.Itoa(index),
+			Namespace: testNamespace,
+			Labels: map[string]string{
+				scheduler.JobIDLabelKey: strconv.FormatInt(job.Id, 10),
+				vcjob.JobNameKey:        job.VolcanoJobName,
+				vcjob.TaskSpecKey:       task,
+			},
+			Annotations: map[string]string{vcjob.TaskIndex: strconv.Itoa(index)},
+		},
+		Spec:   corev1.PodSpec{NodeName: "gpu-node-1"},
+		Status: status,
+	}, metav1.CreateOptions{})
+	s.Require().NoError(err)
+}
+
+func (s *TestJobReconcilerSuite) waitForStatus(id int64, status string) *model.VtTrainingJobs {
+	s.Require().Eventually(func() bool {
+		return s.jobModel.get(id).Status == status
+	}, 3*time.Second, 10*time.Millisecond, "作业状态未变为 %s", status)
+	return s.jobModel.get(id)
+}
+
+// TestSyncRunningJob 测试运行中的作业回写开始时间和实例
+func (s *TestJobReconcilerSuite) TestSyncRunningJob() {
+	job := s.newSubmittedJob(1, "resnet", "queued", vcjob.Running)
+	running := corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.0.0.1", HostIP: "192.168.1.10"}
+	s.createPod(job, "master", 0, running)
+	s.createPod(job, "worker", 1, running)
+	s.startReconciler()
+
+	synced := s.waitForStatus(1, "running")
+	s.Equal("training", synced.Phase)
+	s.NotNil(synced.StartTime)
+
+	s.Eventually(func() bool {
+		instances, _ := s.instanceModel.FindByJobId(1)
+		return len(instances) == 2
+	}, 3*time.Second, 10*time.Millisecond)
+	instances, _ := s.instanceModel.FindByJobId(1)
+	types := map[string]*model.VtTrainingJobInstances{}
+	for _, instance := range instances {
+		types[instance.InstanceType] = instance
+	}
+	s.Require().Contains(types, "worker")
+	s.Equal(1, types["worker"].InstanceIndex)
+	s.Equal("running", types["worker"].Status)
+	s.Equal("gpu-node-1", types["master"].NodeName)
+	s.Equal("10.0.0.1", types["master"].PodIp)
+}
+
+// TestSyncOOMKilledJob 测试失败作业识别内存溢出并记录退出码
+func (s *TestJobReconcilerSuite) TestSyncOOMKilledJob() {
+	job := s.newSubmittedJob(2, "llama", "running", vcjob.Failed)
+	s.createPod(job, "master", 0, corev1.PodStatus{
+		Phase: corev1.PodFailed,
+		ContainerStatuses: []corev1.ContainerStatus{{
+			Name: "master",
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				ExitCode: 137,
+				Reason:   "OOMKilled",
+			}},
+		}},
+	})
+	s.startReconciler()
+
+	synced := s.waitForStatus(2, "oom_killed")
+	s.Equal(137, synced.ExitCode)
+	s.Equal("oom_killed", synced.FailureReason)
+	s.NotNil(synced.EndTime)
+}
+
+// TestSyncCompletedJob 测试完成的作业标记成功
+func (s *TestJobReconcilerSuite) TestSyncCompletedJob() {
+	s.newSubmittedJob(3, "bert", "running", vcjob.Completed)
+	s.startReconciler()
+
+	synced := s.waitForStatus(3, "succeeded")
+	s.Equal("completed", synced.Phase)
+	s.Equal(0, synced.ExitCode)
+}
+
+// TestOutOfBandDelete 测试在平台外删除的作业被标记为失败
+func (s *TestJobReconcilerSuite) TestOutOfBandDelete() {
+	job := s.newSubmittedJob(4, "gpt", "queued", vcjob.Running)
+	s.createPod(job, "master", 0, corev1.PodStatus{Phase: corev1.PodRunning})
+	s.startReconciler()
+	s.waitForStatus(4, "running")
+
+	ctx := context.Background()
+	s.Require().NoError(s.vcClient.BatchV1alpha1().Jobs(testNamespace).Delete(ctx, job.VolcanoJobName, metav1.DeleteOptions{}))
+	s.Require().NoError(s.kubeClient.CoreV1().Pods(testNamespace).Delete(ctx, job.VolcanoJobName+"-master-0", metav1.DeleteOptions{}))
+
+	synced := s.waitForStatus(4, "failed")
+	s.Equal("deleted", synced.FailureReason)
+	s.Equal("VOLCANO_JOB_DELETED", synced.ErrorCode)
+
+	s.Eventually(func() bool {
+		instances, _ := s.instanceModel.FindByJobId(4)
+		return len(instances) == 1 && instances[0].Status == "killed"
+	}, 3*time.Second, 10*time.Millisecond)
+}
+
+// TestCleanupCancelledJob 测试已取消作业残留的Volcano作业会被删除
+func (s *TestJobReconcilerSuite) TestCleanupCancelledJob() {
+	job := s.newSubmittedJob(5, "vit", "cancelled", vcjob.Running)
+	s.startReconciler()
+
+	s.Eventually(func() bool {
+		_, err := s.vcClient.BatchV1alpha1().Jobs(testNamespace).Get(context.Background(), job.VolcanoJobName, metav1.GetOptions{})
+		return apierrors.IsNotFound(err)
+	}, 3*time.Second, 10*time.Millisecond)
+	s.Equal("cancelled", s.jobModel.get(5).Status)
+}
+
+// TestRunJobReconcilerTests 运行作业状态同步器测试
+func TestRunJobReconcilerTests(t *testing.T) {
+	suite.Run(t, new(TestJobReconcilerSuite))
+}
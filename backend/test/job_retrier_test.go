@@ -0,0 +1,193 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"api/model"
+	"api/pkg/scheduler"
+	"api/pkg/volcano"
+
+	"github.com/stretchr/testify/suite"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	vcjob "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+	vcfake "volcano.sh/apis/pkg/client/clientset/versioned/fake"
+)
+
+// TestJobRetrierSuite 作业自动重试器测试套件
+type TestJobRetrierSuite struct {
+	suite.Suite
+	vcClient        *vcfake.Clientset
+	jobModel        *fakeTrainingJobsModel
+	checkpointModel *fakeCheckpointsModel
+	dispatcher      *scheduler.JobDispatcher
+	retrier         *scheduler.JobRetrier
+}
+
+// SetupTest 每个用例使用独立的fake客户端
+func (s *TestJobRetrierSuite) SetupTest() {
+	s.vcClient = vcfake.NewSimpleClientset()
+	s.jobModel = newFakeTrainingJobsModel()
+	s.checkpointModel = &fakeCheckpointsModel{}
+
+	client := volcano.NewClientWithClientsets(s.vcClient, k8sfake.NewSimpleClientset(), testNamespace)
+	machine := scheduler.NewJobStateMachine(s.jobModel, s.jobModel.transitions, client)
+	machine.SetCheckpointResumer(scheduler.NewCheckpointResumer(s.checkpointModel, nil, scheduler.ResumeConfig{}))
+	s.dispatcher = scheduler.NewJobDispatcher(s.jobModel, machine, volcano.NewJobManager(client), nil, scheduler.DispatcherConfig{
+		Namespace: testNamespace,
+	})
+	s.retrier = scheduler.NewJobRetrier(s.jobModel, s.jobModel.retries, machine, s.dispatcher, scheduler.RetrierConfig{
+		BackoffBase: time.Minute,
+		BackoffMax:  10 * time.Minute,
+	})
+}
+
+// newFailedJob 创建已因指定原因失败的作业及其残留的Volcano作业
+func (s *TestJobRetrierSuite) newFailedJob(id int64, name, reason string, failedAgo time.Duration) *model.VtTrainingJobs {
+	job := newPendingJob(id, name)
+	endTime := time.Now().Add(-failedAgo)
+	job.Status = "failed"
+	job.Namespace = testNamespace
+	job.VolcanoJobName = scheduler.BuildVolcanoJobName(job)
+	job.AutoRestart = true
+	job.MaxRetryCount = 2
+	job.FailureReason = reason
+	job.ExitCode = 1
+	job.EndTime = &endTime
+	s.jobModel.jobs[id] = job
+
+	_, err := s.vcClient.BatchV1alpha1().Jobs(testNamespace).Create(context.Background(), &vcjob.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: job.VolcanoJobName, Namespace: testNamespace},
+	}, metav1.CreateOptions{})
+	s.Require().NoError(err)
+	return job
+}
+
+// TestClassifyFailure 测试失败原因归类
+func (s *TestJobRetrierSuite) TestClassifyFailure() {
+	cases := []struct {
+		reason  string
+		message string
+		want    string
+	}{
+		{"OOMKilled", "", scheduler.FailureReasonOOMKilled},
+		{"NodeLost", "", scheduler.FailureReasonNodeLost},
+		{"DeletionByTaintManager", "", scheduler.FailureReasonNodeLost},
+		{"PreemptionByScheduler", "", scheduler.FailureReasonPreempted},
+		{"Error", "Watchdog caught collective operation timeout: WorkNCCL(OpType=ALLREDUCE) ran for 1800000 milliseconds", scheduler.FailureReasonNCCLTimeout},
+		{"Error", "NCCL communicator timed out", scheduler.FailureReasonNCCLTimeout},
+		{"Error", "pod was preempted by higher priority job", scheduler.FailureReasonPreempted},
+		{"Error", "Traceback: ZeroDivisionError", ""},
+	}
+	for _, c := range cases {
+		s.Equal(c.want, scheduler.ClassifyFailure(c.reason, c.message), "reason=%s message=%s", c.reason, c.message)
+	}
+}
+
+// TestRetryFromLatestCheckpoint 测试重试从最新检查点恢复
+func (s *TestJobRetrierSuite) TestRetryFromLatestCheckpoint() {
+	failedRun := s.newFailedJob(1, "llama", scheduler.FailureReasonNodeLost, 2*time.Minute).VolcanoJobName
+	s.checkpointModel.checkpoints = []*model.VtTrainingCheckpoints{
+		{Id: 10, JobId: 1, CheckpointName: "step-100", GlobalStep: 100, StoragePath: "s3://ckpt/llama/step-100", Status: "saved"},
+		{Id: 11, JobId: 1, CheckpointName: "step-200", GlobalStep: 200, StoragePath: "s3://ckpt/llama/step-200", Status: "saved"},
+		{Id: 12, JobId: 1, CheckpointName: "step-300", GlobalStep: 300, StoragePath: "s3://ckpt/llama/step-300", Status: "saving"},
+	}
+
+	retried, err := s.retrier.RetryOnce()
+	s.NoError(err)
+	s.Equal(1, retried)
+
+	job := s.jobModel.get(1)
+	s.Equal("pending", job.Status)
+	s.Equal(failedRun+"-r1", job.VolcanoJobName)
+	s.Equal(int64(11), job.ResumeCheckpointId)
+	s.Equal("s3://ckpt/llama/step-200", job.ResumeCheckpointPath)
+	s.Empty(job.FailureReason)
+
+	records, _ := s.jobModel.retries.FindByJobId(1)
+	s.Require().Len(records, 1)
+	s.Equal(1, records[0].Attempt)
+	s.Equal(scheduler.FailureReasonNodeLost, records[0].FailureReason)
+	s.Equal(failedRun, records[0].FailedVolcanoJobName)
+	s.Equal(job.VolcanoJobName, records[0].RetryVolcanoJobName)
+	s.Equal(int64(11), records[0].CheckpointId)
+
+	submitted, err := s.dispatcher.DispatchOnce()
+	s.NoError(err)
+	s.Equal(1, submitted)
+
+	vcJob, err := s.vcClient.BatchV1alpha1().Jobs(testNamespace).Get(context.Background(), job.VolcanoJobName, metav1.GetOptions{})
+	s.Require().NoError(err)
+	env := map[string]string{}
+	for _, e := range vcJob.Spec.Tasks[0].Template.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	s.Equal("s3://ckpt/llama/step-200", env[scheduler.ResumeCheckpointEnv])
+}
+
+// TestBackoffNotElapsed 测试退避时间未到时不重试
+func (s *TestJobRetrierSuite) TestBackoffNotElapsed() {
+	s.newFailedJob(2, "bert", scheduler.FailureReasonPreempted, 10*time.Second)
+
+	retried, err := s.retrier.RetryOnce()
+	s.NoError(err)
+	s.Equal(0, retried)
+	s.Equal("failed", s.jobModel.get(2).Status)
+}
+
+// TestBackoffDoesNotStarveBatch 测试退避中的作业不占用每轮的处理名额
+func (s *TestJobRetrierSuite) TestBackoffDoesNotStarveBatch() {
+	waiting := s.newFailedJob(6, "opt", scheduler.FailureReasonPreempted, 2*time.Minute)
+	waiting.MaxRetryCount = 5
+	for attempt := 1; attempt <= 3; attempt++ {
+		s.jobModel.retries.Insert(&model.VtTrainingJobRetries{JobId: 6, Attempt: attempt})
+	}
+	s.newFailedJob(7, "mae", scheduler.FailureReasonPreempted, 90*time.Second)
+
+	client := volcano.NewClientWithClientsets(s.vcClient, k8sfake.NewSimpleClientset(), testNamespace)
+	machine := scheduler.NewJobStateMachine(s.jobModel, s.jobModel.transitions, client)
+	retrier := scheduler.NewJobRetrier(s.jobModel, s.jobModel.retries, machine, nil, scheduler.RetrierConfig{
+		BatchSize:   1,
+		BackoffBase: time.Minute,
+		BackoffMax:  10 * time.Minute,
+	})
+
+	retried, err := retrier.RetryOnce()
+	s.NoError(err)
+	s.Equal(1, retried)
+	s.Equal("failed", s.jobModel.get(6).Status)
+	s.Equal("pending", s.jobModel.get(7).Status)
+}
+
+// TestRetryLimitExhausted 测试达到最大重试次数后不再重试
+func (s *TestJobRetrierSuite) TestRetryLimitExhausted() {
+	s.newFailedJob(3, "gpt", scheduler.FailureReasonNCCLTimeout, time.Hour)
+	for attempt := 1; attempt <= 2; attempt++ {
+		s.jobModel.retries.Insert(&model.VtTrainingJobRetries{JobId: 3, Attempt: attempt})
+	}
+
+	retried, err := s.retrier.RetryOnce()
+	s.NoError(err)
+	s.Equal(0, retried)
+	s.Equal("failed", s.jobModel.get(3).Status)
+}
+
+// TestSkipNonRetriableJobs 测试不可重试原因或未开启自动重启的作业不会重试
+func (s *TestJobRetrierSuite) TestSkipNonRetriableJobs() {
+	s.newFailedJob(4, "vit", "error", time.Hour)
+	disabled := s.newFailedJob(5, "t5", scheduler.FailureReasonOOMKilled, time.Hour)
+	disabled.AutoRestart = false
+
+	retried, err := s.retrier.RetryOnce()
+	s.NoError(err)
+	s.Equal(0, retried)
+	s.Equal("failed", s.jobModel.get(4).Status)
+	s.Equal("failed", s.jobModel.get(5).Status)
+}
+
+// TestRunJobRetrierTests 运行作业自动重试器测试
+func TestRunJobRetrierTests(t *testing.T) {
+	suite.Run(t, new(TestJobRetrierSuite))
+}
@@ -0,0 +1,175 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	bizerrors "api/pkg/errors"
+	"api/pkg/scheduler"
+	"api/pkg/volcano"
+
+	"github.com/stretchr/testify/suite"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	vcjob "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+	busv1alpha1 "volcano.sh/apis/pkg/apis/bus/v1alpha1"
+	vcfake "volcano.sh/apis/pkg/client/clientset/versioned/fake"
+)
+
+// TestJobStateMachineSuite 训练作业状态机测试套件
+type TestJobStateMachineSuite struct {
+	suite.Suite
+	vcClient *vcfake.Clientset
+	jobModel *fakeTrainingJobsModel
+	operator scheduler.JobOperator
+}
+
+// SetupTest 每个用例使用独立的fake客户端
+func (s *TestJobStateMachineSuite) SetupTest() {
+	s.vcClient = vcfake.NewSimpleClientset()
+	s.jobModel = newFakeTrainingJobsModel()
+	s.operator = scheduler.JobOperator{UserID: 1001, Username: "alice"}
+}
+
+func (s *TestJobStateMachineSuite) newMachine() *scheduler.JobStateMachine {
+	client := volcano.NewClientWithClientsets(s.vcClient, k8sfake.NewSimpleClientset(), testNamespace)
+	return scheduler.NewJobStateMachine(s.jobModel, s.jobModel.transitions, client)
+}
+
+// newSubmittedJob 创建已提交到Volcano的作业
+func (s *TestJobStateMachineSuite) newSubmittedJob(id int64, name, status string) {
+	job := newPendingJob(id, name)
+	now := time.Now()
+	job.Status = status
+	job.Namespace = testNamespace
+	job.VolcanoJobName = scheduler.BuildVolcanoJobName(job)
+	job.QueuedAt = &now
+	job.ScheduledAt = &now
+	s.jobModel = newFakeTrainingJobsModel(job)
+
+	_, err := s.vcClient.BatchV1alpha1().Jobs(testNamespace).Create(context.Background(), &vcjob.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: job.VolcanoJobName, Namespace: testNamespace},
+	}, metav1.CreateOptions{})
+	s.Require().NoError(err)
+}
+
+// TestNextJobStatus 测试状态变更规则
+func (s *TestJobStateMachineSuite) TestNextJobStatus() {
+	cases := []struct {
+		from   string
+		action string
+		to     string
+		ok     bool
+	}{
+		{"pending", scheduler.JobActionSubmit, "queued", true},
+		{"running", scheduler.JobActionSuspend, "suspended", true},
+		{"suspended", scheduler.JobActionResume, "queued", true},
+		{"failed", scheduler.JobActionRestart, "pending", true},
+		{"running", scheduler.JobActionCancel, "cancelled", true},
+		{"running", scheduler.JobActionResume, "", false},
+		{"succeeded", scheduler.JobActionCancel, "", false},
+		{"pending", scheduler.JobActionRestart, "", false},
+		{"running", "unknown", "", false},
+	}
+
+	for _, c := range cases {
+		to, ok := scheduler.NextJobStatus(c.from, c.action)
+		s.Equal(c.ok, ok, "%s -%s->", c.from, c.action)
+		s.Equal(c.to, to, "%s -%s->", c.from, c.action)
+	}
+}
+
+// TestInvalidTransition 测试非法变更返回带错误码的业务错误
+func (s *TestJobStateMachineSuite) TestInvalidTransition() {
+	s.newSubmittedJob(1, "done", "succeeded")
+
+	_, err := s.newMachine().Suspend(1, s.operator, "")
+	bizErr := bizerrors.GetBizError(err)
+	s.Require().NotNil(bizErr)
+	s.Equal(bizerrors.ErrCodeJobInvalidTransition, bizErr.Code)
+	s.Equal("succeeded", s.jobModel.get(1).Status)
+
+	_, err = s.newMachine().Cancel(404, s.operator, "")
+	s.Equal(bizerrors.ErrJobNotFound, err)
+}
+
+// TestSuspendAndResume 测试暂停恢复会向Volcano发送命令并记录操作人
+func (s *TestJobStateMachineSuite) TestSuspendAndResume() {
+	s.newSubmittedJob(2, "bert", "running")
+	machine := s.newMachine()
+
+	status, err := machine.Suspend(2, s.operator, "释放资源")
+	s.Require().NoError(err)
+	s.Equal("suspended", status)
+
+	status, err = machine.Resume(2, s.operator, "")
+	s.Require().NoError(err)
+	s.Equal("queued", status)
+
+	commands, err := s.vcClient.BusV1alpha1().Commands(testNamespace).List(context.Background(), metav1.ListOptions{})
+	s.Require().NoError(err)
+	s.Require().Len(commands.Items, 2)
+	actions := make([]string, 0, len(commands.Items))
+	for _, command := range commands.Items {
+		actions = append(actions, command.Action)
+		s.Equal("bert-2", command.TargetObject.Name)
+	}
+	s.ElementsMatch([]string{string(busv1alpha1.AbortJobAction), string(busv1alpha1.ResumeJobAction)}, actions)
+
+	records, _ := s.jobModel.transitions.FindByJobId(2)
+	s.Require().Len(records, 2)
+	s.Equal("running", records[0].FromStatus)
+	s.Equal("suspended", records[0].ToStatus)
+	s.Equal(int64(1001), records[0].OperatorId)
+	s.Equal("alice", records[0].OperatorName)
+	s.Equal("释放资源", records[0].Reason)
+}
+
+// TestCancel 测试取消作业会删除Volcano作业
+func (s *TestJobStateMachineSuite) TestCancel() {
+	s.newSubmittedJob(3, "gpt", "running")
+
+	status, err := s.newMachine().Cancel(3, s.operator, "")
+	s.Require().NoError(err)
+	s.Equal("cancelled", status)
+	s.NotNil(s.jobModel.get(3).EndTime)
+
+	_, err = s.vcClient.BatchV1alpha1().Jobs(testNamespace).Get(context.Background(), "gpt-3", metav1.GetOptions{})
+	s.True(apierrors.IsNotFound(err))
+
+	// 已取消的作业不能再次取消
+	_, err = s.newMachine().Cancel(3, s.operator, "")
+	bizErr := bizerrors.GetBizError(err)
+	s.Require().NotNil(bizErr)
+	s.Equal(bizerrors.ErrCodeJobInvalidTransition, bizErr.Code)
+}
+
+// TestRestart 测试重启会重置作业并使用新的Volcano作业名
+func (s *TestJobStateMachineSuite) TestRestart() {
+	s.newSubmittedJob(4, "vit", "failed")
+	s.jobModel.jobs[4].ErrorCode = "SUBMIT_REJECTED"
+	machine := s.newMachine()
+
+	status, err := machine.Restart(4, s.operator, "修复镜像后重跑", scheduler.RestartOptions{})
+	s.Require().NoError(err)
+	s.Equal("pending", status)
+
+	job := s.jobModel.get(4)
+	s.Equal("vit-4-r1", job.VolcanoJobName)
+	s.Nil(job.ScheduledAt)
+	s.Nil(job.EndTime)
+	s.Empty(job.ErrorCode)
+
+	_, err = s.vcClient.BatchV1alpha1().Jobs(testNamespace).Get(context.Background(), "vit-4", metav1.GetOptions{})
+	s.True(apierrors.IsNotFound(err))
+
+	count, _ := s.jobModel.transitions.CountByActions(4, scheduler.JobActionRestart)
+	s.Equal(int64(1), count)
+}
+
+// TestRunJobStateMachineTests 运行作业状态机测试
+func TestRunJobStateMachineTests(t *testing.T) {
+	suite.Run(t, new(TestJobStateMachineSuite))
+}
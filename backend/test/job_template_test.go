@@ -0,0 +1,139 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"api/internal/types"
+	"api/pkg/jobtemplate"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/zeromicro/go-zero/core/mapping"
+)
+
+// TestJobTemplateSuite 训练作业模板渲染测试套件
+type TestJobTemplateSuite struct {
+	suite.Suite
+	spec   string
+	params []jobtemplate.Parameter
+}
+
+func (s *TestJobTemplateSuite) SetupTest() {
+	s.spec = `{
+		"name": "resnet-${dataset}",
+		"framework": "pytorch",
+		"image": "pytorch/pytorch:${torchVersion}",
+		"entryPoint": "train.py",
+		"gpuCount": "${gpus}",
+		"gpuType": "A100",
+		"autoRestart": "${autoRestart}",
+		"envVars": "{\"LR\": \"${lr}\", \"DATASET\": \"${dataset}\"}"
+	}`
+	s.params = []jobtemplate.Parameter{
+		{Name: "dataset", Type: jobtemplate.ParamTypeString, Required: true},
+		{Name: "torchVersion", Type: jobtemplate.ParamTypeString, Default: "2.1.0"},
+		{Name: "gpus", Type: jobtemplate.ParamTypeInt, Default: "1"},
+		{Name: "lr", Type: jobtemplate.ParamTypeFloat, Default: "0.001"},
+		{Name: "autoRestart", Type: jobtemplate.ParamTypeBool},
+	}
+}
+
+func (s *TestJobTemplateSuite) render(values map[string]interface{}) map[string]interface{} {
+	rendered, err := jobtemplate.Render(s.spec, s.params, values)
+	s.Require().NoError(err)
+
+	var object map[string]interface{}
+	s.Require().NoError(json.Unmarshal(rendered, &object))
+	return object
+}
+
+// TestRenderTypedValues 整个值为占位符时保留参数类型，其余按文本替换
+func (s *TestJobTemplateSuite) TestRenderTypedValues() {
+	object := s.render(map[string]interface{}{"dataset": "imagenet", "gpus": float64(4), "lr": "0.01", "autoRestart": true})
+
+	s.Equal("resnet-imagenet", object["name"])
+	s.Equal(float64(4), object["gpuCount"])
+	s.Equal(true, object["autoRestart"])
+	s.Equal("pytorch/pytorch:2.1.0", object["image"])
+	s.Equal(`{"LR": "0.01", "DATASET": "imagenet"}`, object["envVars"])
+}
+
+// TestRenderDefaults 未传入的参数使用默认值或类型零值
+func (s *TestJobTemplateSuite) TestRenderDefaults() {
+	object := s.render(map[string]interface{}{"dataset": "cifar"})
+
+	s.Equal(float64(1), object["gpuCount"])
+	s.Equal(false, object["autoRestart"])
+	s.Equal(`{"LR": "0.001", "DATASET": "cifar"}`, object["envVars"])
+}
+
+// TestRenderIntoCreateRequest 渲染结果可以解析为创建作业请求并填充接口默认值
+func (s *TestJobTemplateSuite) TestRenderIntoCreateRequest() {
+	rendered, err := jobtemplate.Render(s.spec, s.params, map[string]interface{}{"dataset": "coco", "gpus": "8"})
+	s.Require().NoError(err)
+
+	var req types.CreateTrainingJobReq
+	s.Require().NoError(mapping.UnmarshalJsonBytes(rendered, &req))
+	s.Equal("resnet-coco", req.Name)
+	s.Equal(int64(8), req.GpuCount)
+	s.Equal("default", req.QueueName)
+	s.Equal(int64(86400), req.MaxRuntimeSeconds)
+}
+
+// TestRenderErrors 缺少必填参数、传入未声明参数或类型不匹配时返回错误
+func (s *TestJobTemplateSuite) TestRenderErrors() {
+	_, err := jobtemplate.Render(s.spec, s.params, nil)
+	s.ErrorContains(err, "dataset")
+
+	_, err = jobtemplate.Render(s.spec, s.params, map[string]interface{}{"dataset": "coco", "epochs": 10})
+	s.ErrorContains(err, "epochs")
+
+	_, err = jobtemplate.Render(s.spec, s.params, map[string]interface{}{"dataset": "coco", "gpus": 1.5})
+	s.ErrorContains(err, "gpus")
+}
+
+// TestValidate 校验模板内容与参数定义
+func (s *TestJobTemplateSuite) TestValidate() {
+	s.NoError(jobtemplate.Validate(s.spec, s.params))
+	s.Equal([]string{"autoRestart", "dataset", "gpus", "lr", "torchVersion"}, jobtemplate.Placeholders(s.spec))
+
+	s.ErrorContains(jobtemplate.Validate(s.spec, s.params[1:]), "dataset")
+	s.Error(jobtemplate.Validate(`["not", "an", "object"]`, nil))
+	s.ErrorContains(jobtemplate.Validate(`{"gpuCount": "${gpus}"}`,
+		[]jobtemplate.Parameter{{Name: "gpus", Type: jobtemplate.ParamTypeInt, Default: "two"}}), "gpus")
+	s.Error(jobtemplate.Validate(`{}`, []jobtemplate.Parameter{{Name: "x", Type: "list"}}))
+	s.Error(jobtemplate.Validate(`{}`, []jobtemplate.Parameter{{Name: "x"}, {Name: "x"}}))
+}
+
+// TestMergePatch 合并补丁删除null字段、逐层合并对象，JSON字符串字段可以用对象逐项修改
+func (s *TestJobTemplateSuite) TestMergePatch() {
+	target := `{"name": "resnet", "gpuCount": 1, "description": "baseline", "envVars": "",
+		"hyperparameters": "{\"lr\": 0.1, \"epochs\": 10, \"seed\": 12345678901234567}"}`
+	patch := `{"gpuCount": 4, "description": null, "envVars": {"DATASET": "coco"},
+		"hyperparameters": {"lr": 0.01, "epochs": null}}`
+
+	merged, err := jobtemplate.MergePatch([]byte(target), []byte(patch))
+	s.Require().NoError(err)
+
+	var spec map[string]interface{}
+	s.Require().NoError(json.Unmarshal(merged, &spec))
+	s.Equal("resnet", spec["name"])
+	s.Equal(float64(4), spec["gpuCount"])
+	s.NotContains(spec, "description")
+	s.JSONEq(`{"DATASET": "coco"}`, spec["envVars"].(string))
+	s.JSONEq(`{"lr": 0.01, "seed": 12345678901234567}`, spec["hyperparameters"].(string))
+	s.Contains(spec["hyperparameters"], "12345678901234567")
+
+	// 非JSON对象的字符串字段直接替换
+	merged, err = jobtemplate.MergePatch([]byte(target), []byte(`{"name": {"value": "x"}}`))
+	s.Require().NoError(err)
+	s.Contains(string(merged), `"name":{"value":"x"}`)
+
+	_, err = jobtemplate.MergePatch([]byte(target), []byte(`{"gpuCount": `))
+	s.Error(err)
+}
+
+// TestRunJobTemplateTests 运行训练作业模板测试
+func TestRunJobTemplateTests(t *testing.T) {
+	suite.Run(t, new(TestJobTemplateSuite))
+}
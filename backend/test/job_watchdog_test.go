@@ -0,0 +1,231 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"api/model"
+	"api/pkg/alerting"
+	"api/pkg/monitoring"
+	"api/pkg/scheduler"
+	"api/pkg/volcano"
+
+	"github.com/stretchr/testify/suite"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	vcjob "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+	vcfake "volcano.sh/apis/pkg/client/clientset/versioned/fake"
+)
+
+// fakeNotifier 记录看门狗发送的通知
+type fakeNotifier struct {
+	mu     sync.Mutex
+	alerts []*alerting.AlertNotification
+}
+
+func (n *fakeNotifier) SendNotification(alert *alerting.AlertNotification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.alerts = append(n.alerts, alert)
+	return nil
+}
+
+func (n *fakeNotifier) levels() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	var levels []string
+	for _, alert := range n.alerts {
+		levels = append(levels, alert.Alert.AlertLevel)
+	}
+	return levels
+}
+
+// TestJobWatchdogSuite 作业看门狗测试套件
+type TestJobWatchdogSuite struct {
+	suite.Suite
+	vcClient      *vcfake.Clientset
+	jobModel      *fakeTrainingJobsModel
+	instanceModel *fakeInstancesModel
+	notifier      *fakeNotifier
+	watchdog      *scheduler.JobWatchdog
+	now           time.Time
+}
+
+// SetupTest 每个用例使用独立的fake客户端
+func (s *TestJobWatchdogSuite) SetupTest() {
+	s.vcClient = vcfake.NewSimpleClientset()
+	s.jobModel = newFakeTrainingJobsModel()
+	s.instanceModel = &fakeInstancesModel{}
+	s.notifier = &fakeNotifier{}
+	s.now = time.Now()
+
+	client := volcano.NewClientWithClientsets(s.vcClient, k8sfake.NewSimpleClientset(), testNamespace)
+	machine := scheduler.NewJobStateMachine(s.jobModel, s.jobModel.transitions, client)
+	s.watchdog = scheduler.NewJobWatchdog(s.jobModel, s.instanceModel, machine, s.notifier, scheduler.WatchdogConfig{
+		WarnBefore:        5 * time.Minute,
+		IdleGPUThreshold:  5,
+		UsageSampleMaxAge: 2 * time.Minute,
+	})
+}
+
+// newRunningJob 创建运行中的作业及其Volcano作业
+func (s *TestJobWatchdogSuite) newRunningJob(id int64, name string, startedAgo time.Duration) *model.VtTrainingJobs {
+	job := newPendingJob(id, name)
+	startTime := s.now.Add(-startedAgo)
+	job.Status = "running"
+	job.Namespace = testNamespace
+	job.VolcanoJobName = scheduler.BuildVolcanoJobName(job)
+	job.StartTime = &startTime
+	job.MaxRuntimeSeconds = 0
+	job.MaxIdleSeconds = 0
+	job.NotificationConfig = `{"channels":["ops-email"],"recipients":["owner@example.com"]}`
+	s.jobModel.jobs[id] = job
+
+	_, err := s.vcClient.BatchV1alpha1().Jobs(testNamespace).Create(context.Background(), &vcjob.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: job.VolcanoJobName, Namespace: testNamespace},
+	}, metav1.CreateOptions{})
+	s.Require().NoError(err)
+	return job
+}
+
+// check 在now之后offset时刻执行一轮检查
+func (s *TestJobWatchdogSuite) check(offset time.Duration) int {
+	terminated, err := s.watchdog.CheckOnce(s.now.Add(offset))
+	s.Require().NoError(err)
+	return terminated
+}
+
+func (s *TestJobWatchdogSuite) volcanoJobDeleted(name string) bool {
+	_, err := s.vcClient.BatchV1alpha1().Jobs(testNamespace).Get(context.Background(), name, metav1.GetOptions{})
+	return apierrors.IsNotFound(err)
+}
+
+// TestRuntimeExceeded 测试超过最大运行时长的作业先预警后终止
+func (s *TestJobWatchdogSuite) TestRuntimeExceeded() {
+	job := s.newRunningJob(1, "llama", 56*time.Minute)
+	job.MaxRuntimeSeconds = 3600
+
+	s.Equal(0, s.check(0))
+	s.Equal(0, s.check(time.Minute))
+	s.Equal([]string{"warning"}, s.notifier.levels())
+	s.Equal("running", s.jobModel.get(1).Status)
+
+	s.Equal(1, s.check(4*time.Minute))
+	terminated := s.jobModel.get(1)
+	s.Equal("timeout", terminated.Status)
+	s.Equal(scheduler.FailureReasonTimeout, terminated.FailureReason)
+	s.Equal("RUNTIME_EXCEEDED", terminated.ErrorCode)
+	s.NotNil(terminated.EndTime)
+	s.True(s.volcanoJobDeleted(job.VolcanoJobName))
+
+	s.Equal([]string{"warning", "critical"}, s.notifier.levels())
+	alert := s.notifier.alerts[0]
+	s.Equal([]string{"ops-email"}, alert.RuleInfo.NotificationChannels)
+	s.Equal([]string{"owner@example.com"}, alert.Alert.Context["recipients"])
+	s.Equal(int64(1), alert.Alert.ResourceID)
+}
+
+// TestIdleGPU 测试GPU持续空闲的作业被终止，使用率恢复后重新计时
+func (s *TestJobWatchdogSuite) TestIdleGPU() {
+	job := s.newRunningJob(2, "bert", time.Hour)
+	job.MaxIdleSeconds = 600
+
+	idleAt := func(offset time.Duration, percent float64) int {
+		s.instanceModel.setGpuUsage(2, percent, s.now.Add(offset))
+		return s.check(offset)
+	}
+
+	s.Equal(0, idleAt(0, 1))
+	s.Equal(0, idleAt(8*time.Minute, 2))
+	s.Equal([]string{"warning"}, s.notifier.levels())
+
+	// 使用率恢复后空闲时间重新计算
+	s.Equal(0, idleAt(9*time.Minute, 85))
+	s.Equal(0, idleAt(12*time.Minute, 0))
+	s.Equal(0, idleAt(20*time.Minute, 0))
+	s.Equal("running", s.jobModel.get(2).Status)
+
+	s.Equal(1, idleAt(22*time.Minute, 0))
+	terminated := s.jobModel.get(2)
+	s.Equal("timeout", terminated.Status)
+	s.Equal(scheduler.FailureReasonIdle, terminated.FailureReason)
+	s.Equal("IDLE_TIMEOUT", terminated.ErrorCode)
+	s.True(s.volcanoJobDeleted(job.VolcanoJobName))
+}
+
+// TestIdleWithoutSamples 测试没有GPU使用率数据时不会判定为空闲
+func (s *TestJobWatchdogSuite) TestIdleWithoutSamples() {
+	job := s.newRunningJob(3, "gpt", time.Hour)
+	job.MaxIdleSeconds = 60
+	s.instanceModel.setGpuUsage(3, 0, s.now.Add(-time.Hour))
+
+	s.Equal(0, s.check(0))
+	s.Equal(0, s.check(10*time.Minute))
+	s.Equal("running", s.jobModel.get(3).Status)
+	s.Empty(s.notifier.levels())
+}
+
+// TestIdleGPUFromDCGMTelemetry 测试GPU监控数据采集按Pod写入实例使用率，看门狗据此终止空闲作业
+func (s *TestJobWatchdogSuite) TestIdleGPUFromDCGMTelemetry() {
+	job := s.newRunningJob(4, "t5", time.Hour)
+	job.MaxIdleSeconds = 600
+	for i, instance := range []*model.VtTrainingJobInstances{
+		// 上一次运行留下的同名Pod不会被写入
+		{JobId: 9, InstanceName: "t5-worker-0", PodName: "t5-worker-0", Namespace: testNamespace, Status: "failed"},
+		{JobId: 4, InstanceName: "t5-worker-0", PodName: "t5-worker-0", Namespace: testNamespace, Status: "running"},
+		{JobId: 4, InstanceName: "t5-worker-1", PodName: "t5-worker-1", Namespace: testNamespace, Status: "running"},
+	} {
+		instance.InstanceIndex = i
+		s.Require().NoError(s.instanceModel.Upsert(instance))
+	}
+
+	var gpus []dcgmGPU
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, dcgmExporterText(gpus...))
+	}))
+	defer server.Close()
+	collector := scheduler.NewGPUTelemetryCollector(monitoring.NewDCGMExporterSource([]string{server.URL + "/metrics"}, time.Second),
+		&fakeGpuDevicesModel{}, &fakeGpuNodesModel{}, &fakeMonitorMetricsModel{}, &fakeMonitorDataModel{}, scheduler.GPUTelemetryConfig{})
+	collector.SetInstanceUsageRecorder(s.instanceModel)
+
+	collectAt := func(offset time.Duration, worker0, worker1 float64) int {
+		gpus = []dcgmGPU{
+			{host: "gpu-1", uuid: "GPU-aaa", index: 0, pod: "t5-worker-0", util: worker0},
+			{host: "gpu-1", uuid: "GPU-bbb", index: 1, pod: "t5-worker-1", util: worker1},
+			{host: "gpu-1", uuid: "GPU-ccc", index: 2, util: 99},
+		}
+		report, err := collector.CollectOnce(s.now.Add(offset))
+		s.Require().NoError(err)
+		s.Equal(2, report.Pods)
+		return s.check(offset)
+	}
+
+	s.Equal(0, collectAt(0, 1, 2))
+	// 只要有一个实例在使用GPU，作业就不是空闲的
+	s.Equal(0, collectAt(5*time.Minute, 0, 85))
+	s.Equal(0, collectAt(14*time.Minute, 0, 3))
+	s.Equal("running", s.jobModel.get(4).Status)
+	_, sampled, err := s.instanceModel.GetGpuUsage(9, s.now)
+	s.Require().NoError(err)
+	s.False(sampled)
+
+	s.Equal(0, collectAt(20*time.Minute, 1, 0))
+	s.Equal([]string{"warning"}, s.notifier.levels())
+	s.Equal(1, collectAt(24*time.Minute, 0, 0))
+	terminated := s.jobModel.get(4)
+	s.Equal(scheduler.FailureReasonIdle, terminated.FailureReason)
+	s.True(s.volcanoJobDeleted(job.VolcanoJobName))
+}
+
+// TestRunJobWatchdogTests 运行作业看门狗测试
+func TestRunJobWatchdogTests(t *testing.T) {
+	suite.Run(t, new(TestJobWatchdogSuite))
+}
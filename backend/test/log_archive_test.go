@@ -0,0 +1,164 @@
+package test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"api/model"
+	"api/pkg/logstream"
+	"api/pkg/scheduler"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestLogArchiveSuite struct {
+	suite.Suite
+	source    *fakePodSource
+	logs      *fakeLogsModel
+	archive   *logstream.Archive
+	archiver  *scheduler.LogArchiver
+	job       *model.VtTrainingJobs
+	instances *fakeInstancesModel
+}
+
+func (s *TestLogArchiveSuite) SetupTest() {
+	s.source = newFakePodSource()
+	s.source.addPod("bert-1-master-0", "master", 0)
+	s.source.addPod("bert-1-worker-0", "worker", 0)
+
+	s.job = &model.VtTrainingJobs{Id: 1, Name: "bert", Namespace: "training", Status: "running", VolcanoJobName: "bert-1"}
+	s.instances = &fakeInstancesModel{}
+	s.Require().NoError(s.instances.Upsert(&model.VtTrainingJobInstances{JobId: 1, InstanceName: "bert-1-master-0"}))
+	s.Require().NoError(s.instances.Upsert(&model.VtTrainingJobInstances{JobId: 1, InstanceName: "bert-1-worker-0"}))
+
+	s.logs = &fakeLogsModel{}
+	s.archive = logstream.NewArchive(s.T().TempDir())
+	streamer := logstream.NewStreamer(s.source, logstream.Config{PollInterval: 10 * time.Millisecond})
+	s.archiver = scheduler.NewLogArchiver(newFakeTrainingJobsModel(s.job), s.instances, s.logs, streamer, s.archive,
+		scheduler.LogArchiverConfig{ChunkLines: 4})
+}
+
+func (s *TestLogArchiveSuite) archiveJob() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.Require().NoError(s.archiver.ArchiveJob(ctx, s.job))
+}
+
+// TestChunksAndIndex 按实例和分片行数切分压缩分片，每行日志写入索引
+func (s *TestLogArchiveSuite) TestChunksAndIndex() {
+	for i := 0; i < 10; i++ {
+		s.source.write("bert-1-worker-0", i, fmt.Sprintf("step %d", i))
+	}
+	s.source.write("bert-1-master-0", 0, `{"level":"error","msg":"rendezvous failed","trace_id":"abc-123"}`)
+	s.source.write("bert-1-master-0", 1, "retrying request_id=req-9")
+
+	s.archiveJob()
+
+	chunks, err := s.archive.Chunks(1)
+	s.Require().NoError(err)
+	s.Len(chunks["bert-1-worker-0"], 3)
+	s.Len(chunks["bert-1-master-0"], 1)
+	s.Len(s.logs.logs, 12)
+
+	var master []*model.VtTrainingLogs
+	for _, l := range s.logs.logs {
+		if l.LogSource == "bert-1-master-0" {
+			master = append(master, l)
+		}
+	}
+	s.Require().Len(master, 2)
+	s.Equal(int64(1), master[0].InstanceId)
+	s.Equal("master", master[0].Category)
+	s.Equal(logstream.LevelError, master[0].LogLevel)
+	s.Equal("json", master[0].LogFormat)
+	s.Equal("abc-123", master[0].CorrelationId)
+	s.Equal("text", master[1].LogFormat)
+	s.Equal("req-9", master[1].CorrelationId)
+
+	var position struct {
+		Chunk string `json:"chunk"`
+		Line  int    `json:"line"`
+	}
+	s.Require().NoError(json.Unmarshal([]byte(master[1].Context), &position))
+	s.Equal(chunks["bert-1-master-0"][0].Path, position.Chunk)
+	s.Equal(2, position.Line)
+}
+
+// TestResumeWithoutDuplicates 再次归档时从已写入的分片之后继续，索引失败的分片被回滚后重新归档
+func (s *TestLogArchiveSuite) TestResumeWithoutDuplicates() {
+	s.source.write("bert-1-worker-0", 0, "epoch 1")
+	s.source.write("bert-1-worker-0", 1, "epoch 2")
+	s.archiveJob()
+
+	s.source.write("bert-1-worker-0", 2, "epoch 3")
+	s.archiveJob()
+	s.Equal([]string{
+		"bert-1-worker-0: epoch 1",
+		"bert-1-worker-0: epoch 2",
+		"bert-1-worker-0: epoch 3",
+	}, s.logs.contents())
+
+	s.source.write("bert-1-worker-0", 3, "epoch 4")
+	s.logs.failNext = true
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.Error(s.archiver.ArchiveJob(ctx, s.job))
+
+	chunks, err := s.archive.Chunks(1)
+	s.Require().NoError(err)
+	s.Len(chunks["bert-1-worker-0"], 2)
+
+	s.archiveJob()
+	s.Len(s.logs.logs, 4)
+	s.Equal("bert-1-worker-0: epoch 4", s.logs.contents()[3])
+}
+
+// TestTarball 每个实例一个日志文件，按时间顺序拼接全部分片
+func (s *TestLogArchiveSuite) TestTarball() {
+	for i := 0; i < 6; i++ {
+		s.source.write("bert-1-worker-0", i, fmt.Sprintf("step %d", i))
+	}
+	s.source.write("bert-1-master-0", 0, "master ready")
+	s.archiveJob()
+
+	var buf bytes.Buffer
+	s.Require().NoError(s.archive.WriteTarball(&buf, 1, "bert"))
+
+	gz, err := gzip.NewReader(&buf)
+	s.Require().NoError(err)
+	tr := tar.NewReader(gz)
+	files := map[string]string{}
+	var names []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		s.Require().NoError(err)
+		data, err := io.ReadAll(tr)
+		s.Require().NoError(err)
+		names = append(names, header.Name)
+		files[header.Name] = string(data)
+	}
+
+	s.Equal([]string{"bert/bert-1-master-0.log", "bert/bert-1-worker-0.log"}, names)
+	s.Equal("2026-03-02T02:30:00Z master ready\n", files["bert/bert-1-master-0.log"])
+
+	lines := strings.Split(strings.TrimSuffix(files["bert/bert-1-worker-0.log"], "\n"), "\n")
+	s.Require().Len(lines, 6)
+	for i, line := range lines {
+		s.True(strings.HasSuffix(line, fmt.Sprintf(" step %d", i)), line)
+	}
+}
+
+func TestRunLogArchiveTests(t *testing.T) {
+	suite.Run(t, new(TestLogArchiveSuite))
+}
@@ -0,0 +1,358 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"api/pkg/logstream"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/websocket"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	vcjob "volcano.sh/apis/pkg/apis/batch/v1alpha1"
+)
+
+// fakePodSource 基于内存的Pod日志来源，每次打开日志流返回当前已写入的全部日志后结束
+type fakePodSource struct {
+	mu     sync.Mutex
+	pods   []corev1.Pod
+	logs   map[string][]string
+	opened []corev1.PodLogOptions
+}
+
+func newFakePodSource() *fakePodSource {
+	return &fakePodSource{logs: make(map[string][]string)}
+}
+
+// addPod 添加运行中的Pod
+func (f *fakePodSource) addPod(name, task string, index int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pods = append(f.pods, corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "training",
+			UID:         types.UID(name),
+			Labels:      map[string]string{vcjob.TaskSpecKey: task},
+			Annotations: map[string]string{vcjob.TaskIndex: fmt.Sprint(index)},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "main", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+			},
+		},
+	})
+}
+
+// finishPod 将Pod标记为已结束
+func (f *fakePodSource) finishPod(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.pods {
+		if f.pods[i].Name == name {
+			f.pods[i].Status.Phase = corev1.PodSucceeded
+		}
+	}
+}
+
+// write 以kubelet的时间戳格式写入日志，second为相对基准时间的秒数
+func (f *fakePodSource) write(pod string, second int, content string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t := time.Date(2026, time.March, 2, 2, 30, 0, 0, time.UTC).Add(time.Duration(second) * 100 * time.Millisecond)
+	f.logs[pod] = append(f.logs[pod], t.Format(time.RFC3339Nano)+" "+content)
+}
+
+func (f *fakePodSource) ListPods(ctx context.Context, namespace, selector string) ([]corev1.Pod, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]corev1.Pod(nil), f.pods...), nil
+}
+
+func (f *fakePodSource) GetPod(ctx context.Context, namespace, name string) (*corev1.Pod, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.pods {
+		if f.pods[i].Name == name {
+			pod := f.pods[i]
+			return &pod, nil
+		}
+	}
+	return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, name)
+}
+
+func (f *fakePodSource) OpenLogs(ctx context.Context, namespace, name string, options *corev1.PodLogOptions) (io.ReadCloser, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.opened = append(f.opened, *options)
+
+	var lines []string
+	for _, line := range f.logs[name] {
+		t, _ := time.Parse(time.RFC3339Nano, strings.SplitN(line, " ", 2)[0])
+		if options.SinceTime != nil && t.Before(options.SinceTime.Time) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if options.TailLines != nil && int(*options.TailLines) < len(lines) {
+		lines = lines[len(lines)-int(*options.TailLines):]
+	}
+	if len(lines) == 0 {
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+	return io.NopCloser(strings.NewReader(strings.Join(lines, "\n") + "\n")), nil
+}
+
+// lineRecorder 记录推送的日志行
+type lineRecorder struct {
+	lines []*logstream.Line
+}
+
+func (r *lineRecorder) WriteLine(line *logstream.Line) error {
+	r.lines = append(r.lines, line)
+	return nil
+}
+
+func (r *lineRecorder) Ping() error { return nil }
+
+func (r *lineRecorder) WriteEnd(string) error { return nil }
+
+// contents 返回 "实例: 内容" 形式的日志
+func (r *lineRecorder) contents() []string {
+	var result []string
+	for _, line := range r.lines {
+		result = append(result, line.Pod+": "+line.Content)
+	}
+	return result
+}
+
+type TestLogStreamSuite struct {
+	suite.Suite
+	source   *fakePodSource
+	streamer *logstream.Streamer
+}
+
+func (s *TestLogStreamSuite) SetupTest() {
+	s.source = newFakePodSource()
+	s.source.addPod("bert-1-worker-0", "worker", 0)
+	s.source.addPod("bert-1-master-0", "master", 0)
+	s.streamer = logstream.NewStreamer(s.source, logstream.Config{PollInterval: 10 * time.Millisecond})
+}
+
+func (s *TestLogStreamSuite) stream(opts logstream.Options) *lineRecorder {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	recorder := &lineRecorder{}
+	s.Require().NoError(s.streamer.Stream(ctx, opts, recorder))
+	return recorder
+}
+
+// TestDetectLevel 识别结构化日志、glog格式、关键字，traceback的缩进行沿用错误级别
+func (s *TestLogStreamSuite) TestDetectLevel() {
+	detector := &logstream.LevelDetector{}
+	cases := []struct {
+		line  string
+		level string
+	}{
+		{"epoch 1 step 100 loss=0.31 error_rate=0.02", "INFO"},
+		{"2026-03-02 02:30:00 WARNING: retrying after error", "WARN"},
+		{`{"level":"debug","msg":"batch loaded"}`, "DEBUG"},
+		{"E0302 02:30:00.123456  1234 nccl.cc:42] NCCL timeout", "ERROR"},
+		{"Traceback (most recent call last):", "ERROR"},
+		{`  File "train.py", line 12, in <module>`, "ERROR"},
+		{"    main()", "ERROR"},
+		{"RuntimeError: CUDA out of memory", "ERROR"},
+		{"  while handling step 12", "ERROR"},
+		{"[rank0] torch.distributed PANIC", "FATAL"},
+		{"  rank 3 exited", "FATAL"},
+		{"saving checkpoint", "INFO"},
+		{"  step=200", "INFO"},
+	}
+	for _, c := range cases {
+		s.Equal(c.level, detector.Detect(c.line), c.line)
+	}
+	s.Equal([]string{"WARN", "ERROR", "FATAL"}, logstream.LevelsAtLeast("warning"))
+	s.Nil(logstream.LevelsAtLeast(""))
+}
+
+// TestMergesReplicasWithFilters 合并全部副本的日志，支持按实例、级别和行数过滤
+func (s *TestLogStreamSuite) TestMergesReplicasWithFilters() {
+	s.source.write("bert-1-master-0", 1, "INFO master starting")
+	s.source.write("bert-1-master-0", 2, "WARNING master slow")
+	s.source.write("bert-1-worker-0", 1, "worker step 1")
+	s.source.write("bert-1-worker-0", 3, "ERROR worker nccl failure")
+
+	all := s.stream(logstream.Options{})
+	s.ElementsMatch([]string{
+		"bert-1-master-0: INFO master starting", "bert-1-master-0: WARNING master slow",
+		"bert-1-worker-0: worker step 1", "bert-1-worker-0: ERROR worker nccl failure",
+	}, all.contents())
+	for _, line := range all.lines {
+		s.False(line.Time.IsZero())
+		s.NotEmpty(line.Offset)
+	}
+
+	workers := s.stream(logstream.Options{Task: "worker", MinLevel: "WARN"})
+	s.Equal([]string{"bert-1-worker-0: ERROR worker nccl failure"}, workers.contents())
+	s.Equal("worker", workers.lines[0].Task)
+	s.Equal("ERROR", workers.lines[0].Level)
+
+	tail := s.stream(logstream.Options{Pod: "bert-1-master-0", TailLines: 1})
+	s.Equal([]string{"bert-1-master-0: WARNING master slow"}, tail.contents())
+}
+
+// TestResumeFromOffset 按续传游标重连时不重复推送已推送的日志
+func (s *TestLogStreamSuite) TestResumeFromOffset() {
+	for i := 1; i <= 4; i++ {
+		s.source.write("bert-1-master-0", i, fmt.Sprintf("master line %d", i))
+		s.source.write("bert-1-worker-0", i, fmt.Sprintf("worker line %d", i))
+	}
+
+	first := s.stream(logstream.Options{})
+	index := -1
+	for i, line := range first.lines {
+		if line.Content == "worker line 2" {
+			index = i
+		}
+	}
+	s.Require().GreaterOrEqual(index, 0)
+
+	// 重连后只推送游标之后的日志，与第一次连接中该行之后推送的日志一致
+	cursor, err := logstream.ParseCursor(first.lines[index].Offset)
+	s.Require().NoError(err)
+	resumed := s.stream(logstream.Options{Cursor: cursor})
+	s.ElementsMatch(first.contents()[index+1:], resumed.contents())
+	s.NotContains(resumed.contents(), "bert-1-worker-0: worker line 2")
+
+	_, err = logstream.ParseCursor("not-a-cursor!")
+	s.Error(err)
+}
+
+// TestFollowReconnectsAndDiscoversPods 跟随模式下容器重启后继续读取，发现新Pod，作业结束后停止
+func (s *TestLogStreamSuite) TestFollowReconnectsAndDiscoversPods() {
+	s.source.write("bert-1-master-0", 1, "master line 1")
+	s.source.write("bert-1-worker-0", 1, "worker line 1")
+
+	var finished atomic.Bool
+	recorder := &syncRecorder{}
+	done := make(chan error, 1)
+	go func() {
+		done <- s.streamer.Stream(context.Background(), logstream.Options{
+			Follow:   true,
+			Finished: finished.Load,
+		}, recorder)
+	}()
+
+	s.Eventually(func() bool { return recorder.count() == 2 }, 2*time.Second, 5*time.Millisecond)
+	// 容器重启后产生的新日志
+	s.source.write("bert-1-master-0", 2, "master line 2")
+	// 弹性扩容出的新副本
+	s.source.addPod("bert-1-worker-1", "worker", 1)
+	s.source.write("bert-1-worker-1", 1, "worker-1 line 1")
+	s.Eventually(func() bool { return recorder.count() == 4 }, 2*time.Second, 5*time.Millisecond)
+
+	for _, pod := range []string{"bert-1-master-0", "bert-1-worker-0", "bert-1-worker-1"} {
+		s.source.finishPod(pod)
+	}
+	finished.Store(true)
+
+	select {
+	case err := <-done:
+		s.NoError(err)
+	case <-time.After(2 * time.Second):
+		s.Fail("作业结束后日志流未停止")
+	}
+	s.ElementsMatch([]string{
+		"bert-1-master-0: master line 1", "bert-1-worker-0: worker line 1",
+		"bert-1-master-0: master line 2", "bert-1-worker-1: worker-1 line 1",
+	}, recorder.contents())
+}
+
+// TestSSEAndWebSocketTransport SSE以游标作为事件ID，WebSocket推送JSON消息，结束时发送end
+func (s *TestLogStreamSuite) TestSSEAndWebSocketTransport() {
+	s.source.write("bert-1-master-0", 1, "master line 1")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logstream.Serve(w, r, func(ctx context.Context, writer logstream.Writer) {
+			s.NoError(s.streamer.Stream(ctx, logstream.Options{Pod: "bert-1-master-0"}, writer))
+			s.NoError(writer.WriteEnd("completed"))
+		})
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	s.True(logstream.IsStreamRequest(req))
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	s.Equal("text/event-stream", resp.Header.Get("Content-Type"))
+	s.Contains(string(body), "event: log\ndata: {\"instance\":\"bert-1-master-0\"")
+	s.Regexp(`id: \S+\nevent: log`, string(body))
+	s.Contains(string(body), "event: end\ndata: {\"reason\":\"completed\"}")
+
+	conn, err := websocket.Dial(strings.Replace(server.URL, "http", "ws", 1), "", server.URL)
+	s.Require().NoError(err)
+	defer conn.Close()
+	var messages []map[string]interface{}
+	for {
+		var message map[string]interface{}
+		if websocket.JSON.Receive(conn, &message) != nil {
+			break
+		}
+		messages = append(messages, message)
+		if message["type"] == "end" {
+			break
+		}
+	}
+	s.Require().Len(messages, 2)
+	s.Equal("log", messages[0]["type"])
+	s.Equal("master line 1", messages[0]["line"].(map[string]interface{})["content"])
+	s.Equal("completed", messages[1]["reason"])
+}
+
+// syncRecorder 并发安全的日志记录器，供跟随模式测试读取
+type syncRecorder struct {
+	mu       sync.Mutex
+	recorder lineRecorder
+}
+
+func (r *syncRecorder) WriteLine(line *logstream.Line) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.recorder.WriteLine(line)
+}
+
+func (r *syncRecorder) Ping() error { return nil }
+
+func (r *syncRecorder) WriteEnd(string) error { return nil }
+
+func (r *syncRecorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.recorder.lines)
+}
+
+func (r *syncRecorder) contents() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.recorder.contents()
+}
+
+func TestRunLogStreamTests(t *testing.T) {
+	suite.Run(t, new(TestLogStreamSuite))
+}
@@ -0,0 +1,144 @@
+package test
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"api/internal/config"
+	"api/internal/logic/training"
+	"api/internal/svc"
+	"api/internal/types"
+	"api/model"
+	bizerrors "api/pkg/errors"
+	"api/pkg/metrics"
+	"api/pkg/scheduler"
+	"api/pkg/volcano"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestMetricsIngestSuite struct {
+	suite.Suite
+	metrics *fakeMetricsModel
+	svcCtx  *svc.ServiceContext
+	start   time.Time
+}
+
+func (s *TestMetricsIngestSuite) SetupTest() {
+	s.start = time.Date(2026, 3, 2, 2, 0, 0, 0, time.UTC)
+	job := &model.VtTrainingJobs{Id: 7, Name: "resnet", Status: "running", StartTime: &s.start}
+
+	instances := &fakeInstancesModel{}
+	s.Require().NoError(instances.Upsert(&model.VtTrainingJobInstances{JobId: 7, InstanceName: "resnet-7-master-0"}))
+
+	var cfg config.Config
+	cfg.Training.MetricsTokenSecret = "metrics-secret"
+	cfg.Training.MaxMetricsPerPush = 10
+	s.metrics = &fakeMetricsModel{}
+	s.svcCtx = &svc.ServiceContext{
+		Config:                      cfg,
+		VtTrainingJobsModel:         newFakeTrainingJobsModel(job),
+		VtTrainingJobInstancesModel: instances,
+		VtTrainingMetricsModel:      s.metrics,
+	}
+}
+
+func (s *TestMetricsIngestSuite) push(req *types.PushJobMetricsReq) (*types.PushJobMetricsResp, error) {
+	return training.NewPushJobMetricsLogic(context.Background(), s.svcCtx).PushJobMetrics(req)
+}
+
+// TestJobToken 令牌与作业绑定，其他作业的令牌和空密钥都无法通过校验
+func (s *TestMetricsIngestSuite) TestJobToken() {
+	token := metrics.JobToken("metrics-secret", 7)
+	s.True(strings.HasPrefix(token, "vtm_"))
+	s.True(metrics.VerifyJobToken("metrics-secret", 7, token))
+	s.True(metrics.VerifyJobToken("metrics-secret", 7, "Bearer "+token))
+	s.False(metrics.VerifyJobToken("metrics-secret", 8, token))
+	s.False(metrics.VerifyJobToken("other-secret", 7, token))
+	s.False(metrics.VerifyJobToken("", 7, metrics.JobToken("", 7)))
+
+	spec := &volcano.TrainingJobSpec{}
+	scheduler.InjectMetricsEnv(spec, 7, "http://volctrain-api:8888/", "metrics-secret")
+	s.Equal("7", spec.EnvVars[scheduler.JobIDEnv])
+	s.Equal(token, spec.EnvVars[scheduler.MetricsTokenEnv])
+	s.Equal("http://volctrain-api:8888", spec.EnvVars[scheduler.MetricsEndpointEnv])
+}
+
+// TestRejectInvalidToken 令牌错误时返回401且不写入指标
+func (s *TestMetricsIngestSuite) TestRejectInvalidToken() {
+	_, err := s.push(&types.PushJobMetricsReq{
+		JobId:         7,
+		Authorization: "Bearer " + metrics.JobToken("metrics-secret", 8),
+		Metrics:       []types.MetricSample{{Name: "loss", Type: "scalar", Value: 0.5}},
+	})
+	bizErr := bizerrors.GetBizError(err)
+	s.Require().NotNil(bizErr)
+	s.Equal(401, bizErr.GetHTTPStatus())
+	s.Empty(s.metrics.inserted)
+}
+
+// TestPushBatch 合法指标批量写入，不合法的指标被跳过并返回原因
+func (s *TestMetricsIngestSuite) TestPushBatch() {
+	wallTime := float64(s.start.Add(90*time.Second).UnixMilli()) / 1000
+	resp, err := s.push(&types.PushJobMetricsReq{
+		JobId:         7,
+		Authorization: "Bearer " + metrics.JobToken("metrics-secret", 7),
+		Instance:      "resnet-7-master-0",
+		Metrics: []types.MetricSample{
+			{Name: "loss", Type: "scalar", Value: 0.25, Step: 100, Epoch: 1, Phase: "training", WallTime: wallTime},
+			{Name: "weights", Type: "histogram", Step: 100, Histogram: &types.MetricHistogram{
+				Min: -1, Max: 3, Num: 4, Sum: 4, SumSquares: 12, BucketLimits: []float64{0, 2, 4}, BucketCounts: []float64{1, 2, 1},
+			}},
+			{Name: "samples", Type: "text", Text: "predicted: cat", Phase: "eval"},
+			{Name: "loss", Type: "scalar", Value: math.NaN()},
+			{Name: "accuracy", Type: "scalar", Value: 0.9, Phase: "holdout"},
+			{Name: "weights", Type: "histogram"},
+		},
+	})
+	s.Require().NoError(err)
+	s.Equal(int64(3), resp.Accepted)
+	s.Equal(int64(3), resp.Rejected)
+	s.Require().Len(resp.Errors, 3)
+	s.True(strings.HasPrefix(resp.Errors[0], "metrics[3]: "))
+
+	s.Require().Len(s.metrics.inserted, 3)
+	loss := s.metrics.inserted[0]
+	s.Equal(int64(1), loss.InstanceId)
+	s.Equal("0.25", loss.MetricValue)
+	s.Equal("train", loss.Phase)
+	s.Equal("90.000", loss.RelativeTimeSeconds)
+	s.True(loss.MetricTime.Equal(s.start.Add(90 * time.Second)))
+
+	weights := s.metrics.inserted[1]
+	s.Equal(model.MetricTypeHistogram, weights.MetricType)
+	s.Equal("1", weights.AvgValue)
+	s.Equal("1.4142135623730951", weights.StdValue)
+	s.Contains(weights.MetricData, `"bucketCounts":[1,2,1]`)
+
+	s.Equal("val", s.metrics.inserted[2].Phase)
+	s.JSONEq(`{"text":"predicted: cat"}`, s.metrics.inserted[2].MetricData)
+}
+
+// TestPushLimit 超过单次上报上限时整批拒绝
+func (s *TestMetricsIngestSuite) TestPushLimit() {
+	samples := make([]types.MetricSample, 11)
+	for i := range samples {
+		samples[i] = types.MetricSample{Name: "loss", Type: "scalar", Value: 1, Step: int64(i)}
+	}
+	_, err := s.push(&types.PushJobMetricsReq{
+		JobId:         7,
+		Authorization: metrics.JobToken("metrics-secret", 7),
+		Metrics:       samples,
+	})
+	bizErr := bizerrors.GetBizError(err)
+	s.Require().NotNil(bizErr)
+	s.Equal(400, bizErr.GetHTTPStatus())
+	s.Empty(s.metrics.inserted)
+}
+
+func TestRunMetricsIngestTests(t *testing.T) {
+	suite.Run(t, new(TestMetricsIngestSuite))
+}
@@ -0,0 +1,236 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// schemaDir 建表脚本目录，00_create_schema.sql按顺序SOURCE其余脚本
+const schemaDir = "../sql"
+
+var (
+	schemaOnce    sync.Once
+	schemaTables  map[string]map[string]bool
+	schemaLoadErr error
+
+	sourcePattern      = regexp.MustCompile(`(?m)^SOURCE\s+(\S+);`)
+	createTablePattern = regexp.MustCompile(`(?is)^CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?(\w+)\s*\((.*)\)`)
+	alterTablePattern  = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(\w+)\s+(.*)$`)
+	stringLiteral      = regexp.MustCompile(`'[^']*'`)
+	qualifiedColumn    = regexp.MustCompile(`\b(\w+)\.(\w+)\b`)
+	tableReference     = regexp.MustCompile(`(?i)\b(?:FROM|UPDATE|INTO|JOIN)\s+(\w+)`)
+	aliasPattern       = regexp.MustCompile(`(?i)\bAS\s+(\w+)`)
+	identifierPattern  = regexp.MustCompile(`[A-Za-z_]\w*(\s*\()?`)
+)
+
+// sqlKeywords 查询中出现的SQL关键字，不作为列名校验
+var sqlKeywords = map[string]bool{
+	"select": true, "from": true, "where": true, "and": true, "or": true, "not": true, "in": true, "is": true,
+	"null": true, "update": true, "set": true, "insert": true, "into": true, "values": true, "order": true,
+	"by": true, "asc": true, "desc": true, "limit": true, "offset": true, "for": true, "case": true, "when": true,
+	"then": true, "else": true, "end": true, "as": true, "join": true, "left": true, "inner": true, "on": true,
+	"like": true, "interval": true, "second": true, "distinct": true, "group": true, "having": true,
+	"delete": true, "duplicate": true, "key": true, "between": true,
+}
+
+// loadSchema 按00_create_schema.sql中SOURCE的顺序解析建表和ALTER TABLE语句，得到各表最终的列
+func loadSchema() (map[string]map[string]bool, error) {
+	schemaOnce.Do(func() {
+		schemaTables, schemaLoadErr = parseSchema(schemaDir)
+	})
+	return schemaTables, schemaLoadErr
+}
+
+func parseSchema(dir string) (map[string]map[string]bool, error) {
+	main, err := os.ReadFile(filepath.Join(dir, "00_create_schema.sql"))
+	if err != nil {
+		return nil, err
+	}
+	tables := make(map[string]map[string]bool)
+	for _, match := range sourcePattern.FindAllStringSubmatch(string(main), -1) {
+		content, err := os.ReadFile(filepath.Join(dir, match[1]))
+		if err != nil {
+			return nil, err
+		}
+		for _, statement := range splitStatements(string(content)) {
+			if err := applyStatement(tables, statement); err != nil {
+				return nil, fmt.Errorf("%s: %w", match[1], err)
+			}
+		}
+	}
+	return tables, nil
+}
+
+// splitStatements 去掉注释后按分号拆分语句
+func splitStatements(content string) []string {
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "--"); i >= 0 {
+			line = line[:i]
+		}
+		lines = append(lines, line)
+	}
+	var statements []string
+	for _, statement := range strings.Split(strings.Join(lines, "\n"), ";") {
+		if statement = strings.TrimSpace(statement); statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	return statements
+}
+
+// splitTopLevel 按不在括号内的逗号拆分列定义或ALTER子句
+func splitTopLevel(body string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range body {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(body[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(body[start:]))
+}
+
+func applyStatement(tables map[string]map[string]bool, statement string) error {
+	statement = stringLiteral.ReplaceAllString(statement, "''")
+	if match := createTablePattern.FindStringSubmatch(statement); match != nil {
+		columns := make(map[string]bool)
+		for _, definition := range splitTopLevel(match[2]) {
+			fields := strings.Fields(definition)
+			if len(fields) == 0 {
+				continue
+			}
+			switch strings.ToUpper(fields[0]) {
+			case "PRIMARY", "UNIQUE", "INDEX", "KEY", "CONSTRAINT", "FOREIGN", "FULLTEXT":
+				continue
+			}
+			columns[strings.Trim(fields[0], "`")] = true
+		}
+		tables[match[1]] = columns
+		return nil
+	}
+
+	match := alterTablePattern.FindStringSubmatch(statement)
+	if match == nil {
+		return nil
+	}
+	columns, ok := tables[match[1]]
+	if !ok {
+		return fmt.Errorf("ALTER TABLE %s: 表不存在", match[1])
+	}
+	for _, clause := range splitTopLevel(match[2]) {
+		fields := strings.Fields(clause)
+		if len(fields) < 2 {
+			continue
+		}
+		action := strings.ToUpper(fields[0])
+		fields = fields[1:]
+		if strings.ToUpper(fields[0]) == "COLUMN" {
+			fields = fields[1:]
+		} else {
+			switch strings.ToUpper(fields[0]) {
+			case "PRIMARY", "UNIQUE", "INDEX", "KEY", "CONSTRAINT", "FOREIGN", "FULLTEXT":
+				continue
+			}
+		}
+		switch action {
+		case "ADD":
+			columns[fields[0]] = true
+		case "DROP":
+			if !columns[fields[0]] {
+				return fmt.Errorf("ALTER TABLE %s DROP %s: 列不存在", match[1], fields[0])
+			}
+			delete(columns, fields[0])
+		case "CHANGE":
+			if !columns[fields[0]] {
+				return fmt.Errorf("ALTER TABLE %s CHANGE %s: 列不存在", match[1], fields[0])
+			}
+			delete(columns, fields[0])
+			columns[fields[1]] = true
+		}
+	}
+	return nil
+}
+
+// checkColumns 校验语句引用的表和列在建表脚本中存在，函数名、关键字、字符串和别名不校验
+func checkColumns(tables map[string]map[string]bool, query string) error {
+	query = stringLiteral.ReplaceAllString(query, "''")
+
+	referenced := make(map[string]bool)
+	for _, match := range tableReference.FindAllStringSubmatch(query, -1) {
+		if _, ok := tables[match[1]]; !ok {
+			return fmt.Errorf("表%s不存在", match[1])
+		}
+		referenced[match[1]] = true
+	}
+	for _, match := range qualifiedColumn.FindAllStringSubmatch(query, -1) {
+		if columns, ok := tables[match[1]]; ok && !columns[match[2]] {
+			return fmt.Errorf("表%s没有列%s", match[1], match[2])
+		}
+	}
+	query = qualifiedColumn.ReplaceAllString(query, "")
+
+	aliases := make(map[string]bool)
+	for _, match := range aliasPattern.FindAllStringSubmatch(query, -1) {
+		aliases[match[1]] = true
+	}
+	for _, token := range identifierPattern.FindAllString(query, -1) {
+		if strings.HasSuffix(token, "(") {
+			continue
+		}
+		if sqlKeywords[strings.ToLower(token)] || referenced[token] || aliases[token] {
+			continue
+		}
+		found := false
+		for table := range referenced {
+			if tables[table][token] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("列%s不在表%v中", token, tableNames(referenced))
+		}
+	}
+	return nil
+}
+
+func tableNames(set map[string]bool) []string {
+	result := make([]string, 0, len(set))
+	for key := range set {
+		result = append(result, key)
+	}
+	return result
+}
+
+// schemaMatcher 按正则匹配语句，并校验实际执行的语句只引用建表脚本中存在的表和列
+func schemaMatcher() (sqlmock.QueryMatcher, error) {
+	tables, err := loadSchema()
+	if err != nil {
+		return nil, err
+	}
+	matcher := sqlmock.QueryMatcherFunc(func(expectedSQL, actualSQL string) error {
+		if err := sqlmock.QueryMatcherRegexp.Match(expectedSQL, actualSQL); err != nil {
+			return err
+		}
+		if err := checkColumns(tables, actualSQL); err != nil {
+			return fmt.Errorf("语句与建表脚本不一致: %w\n%s", err, actualSQL)
+		}
+		return nil
+	})
+	return matcher, nil
+}
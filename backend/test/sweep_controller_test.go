@@ -0,0 +1,237 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"api/model"
+	"api/pkg/scheduler"
+	"api/pkg/sweep"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeJobCreator 将试验作业写入内存作业模型
+type fakeJobCreator struct {
+	mu     sync.Mutex
+	jobs   *fakeTrainingJobsModel
+	nextId int64
+	specs  []map[string]interface{}
+}
+
+func (c *fakeJobCreator) CreateTrainingJob(ctx context.Context, spec []byte) (int64, error) {
+	var object map[string]interface{}
+	if err := json.Unmarshal(spec, &object); err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	c.nextId++
+	id := c.nextId
+	c.specs = append(c.specs, object)
+	c.mu.Unlock()
+
+	job := newPendingJob(id, object["name"].(string))
+	job.Hyperparameters, _ = object["hyperparameters"].(string)
+	c.jobs.mu.Lock()
+	c.jobs.jobs[id] = job
+	c.jobs.mu.Unlock()
+	return id, nil
+}
+
+// TestSweepControllerSuite 超参数搜索测试套件
+type TestSweepControllerSuite struct {
+	suite.Suite
+	jobModel      *fakeTrainingJobsModel
+	sweepModel    *fakeSweepsModel
+	relationModel *fakeRelationsModel
+	metricsModel  *fakeMetricsModel
+	creator       *fakeJobCreator
+	controller    *scheduler.SweepController
+}
+
+func (s *TestSweepControllerSuite) setup(sw *model.VtTrainingSweeps) {
+	s.jobModel = newFakeTrainingJobsModel()
+	s.sweepModel = newFakeSweepsModel(sw)
+	s.relationModel = &fakeRelationsModel{}
+	s.metricsModel = &fakeMetricsModel{}
+	s.creator = &fakeJobCreator{jobs: s.jobModel, nextId: 100}
+
+	machine := scheduler.NewJobStateMachine(s.jobModel, s.jobModel.transitions, nil)
+	tracker := scheduler.NewSweepTracker(s.jobModel, s.relationModel, s.metricsModel)
+	s.controller = scheduler.NewSweepController(s.sweepModel, s.relationModel, tracker, machine, s.creator, scheduler.SweepConfig{})
+}
+
+func newSweep(algorithm string, maxTrials, parallelism int) *model.VtTrainingSweeps {
+	return &model.VtTrainingSweeps{
+		Id:              7,
+		Name:            "resnet-sweep",
+		Algorithm:       algorithm,
+		ObjectiveMetric: "val_loss",
+		ObjectiveGoal:   sweep.GoalMinimize,
+		SearchSpace:     `{"lr":{"type":"choice","values":[0.1,0.01]},"layers":{"type":"int","min":2,"max":4,"step":2}}`,
+		BaseSpec:        `{"framework":"pytorch","image":"pytorch/pytorch:2.1.0","entryPoint":"train.py","hyperparameters":"{\"epochs\":10}"}`,
+		MaxTrials:       maxTrials,
+		Parallelism:     parallelism,
+		Status:          model.SweepStatusRunning,
+	}
+}
+
+// finish 将作业标记为运行结束
+func (s *TestSweepControllerSuite) finish(jobId int64, status string) {
+	s.jobModel.mu.Lock()
+	defer s.jobModel.mu.Unlock()
+	s.jobModel.jobs[jobId].Status = status
+}
+
+// TestGridSweepRespectsParallelism 网格搜索按并行上限逐步创建试验，全部结束后选出最优试验
+func (s *TestSweepControllerSuite) TestGridSweepRespectsParallelism() {
+	s.setup(newSweep(sweep.AlgorithmGrid, 10, 2))
+
+	s.Require().NoError(s.controller.ReconcileOnce())
+	s.Len(s.creator.specs, 2)
+	s.Equal("resnet-sweep-7-trial-1", s.creator.specs[0]["name"])
+	s.JSONEq(`{"epochs":10,"layers":2,"lr":0.1}`, s.creator.specs[0]["hyperparameters"].(string))
+	s.JSONEq(`{"epochs":10,"layers":2,"lr":0.01}`, s.creator.specs[1]["hyperparameters"].(string))
+
+	// 并行上限已满时不创建新试验
+	s.Require().NoError(s.controller.ReconcileOnce())
+	s.Len(s.creator.specs, 2)
+
+	s.metricsModel.add(101, "val_loss", 0.9, 0.5)
+	s.finish(101, "succeeded")
+	s.Require().NoError(s.controller.ReconcileOnce())
+	s.Len(s.creator.specs, 3)
+
+	s.metricsModel.add(102, "val_loss", 0.8, 0.3)
+	s.metricsModel.add(103, "val_loss", 0.7, 0.4)
+	s.finish(102, "succeeded")
+	s.finish(103, "failed")
+	s.Require().NoError(s.controller.ReconcileOnce())
+	s.Len(s.creator.specs, 4, "网格共4个组合")
+
+	s.metricsModel.add(104, "val_loss", 0.6)
+	s.finish(104, "succeeded")
+	s.Require().NoError(s.controller.ReconcileOnce())
+	s.Len(s.creator.specs, 4)
+
+	result := s.sweepModel.get(7)
+	s.Equal(model.SweepStatusCompleted, result.Status)
+	s.Equal(4, result.TrialCount)
+	s.Equal(int64(102), result.BestJobId)
+	s.InDelta(0.3, *result.BestValue, 1e-9)
+
+	relations, _ := s.relationModel.FindByEntity(scheduler.SweepEntityType, 7, scheduler.SweepTrialRelation)
+	s.Len(relations, 4)
+}
+
+// TestMedianStoppingPrunesTrial 中位数规则终止表现较差的运行中试验
+func (s *TestSweepControllerSuite) TestMedianStoppingPrunesTrial() {
+	sw := newSweep(sweep.AlgorithmRandom, 3, 3)
+	sw.EarlyStopping = `{"type":"median","minTrials":2}`
+	s.setup(sw)
+
+	s.Require().NoError(s.controller.ReconcileOnce())
+	s.Require().Len(s.creator.specs, 3)
+	for _, id := range []int64{101, 102, 103} {
+		s.finish(id, "running")
+	}
+	s.metricsModel.add(101, "val_loss", 0.5, 0.4, 0.3)
+	s.metricsModel.add(102, "val_loss", 0.6, 0.5, 0.4)
+	s.metricsModel.add(103, "val_loss", 2.0, 1.9)
+
+	s.Require().NoError(s.controller.ReconcileOnce())
+	pruned := s.jobModel.get(103)
+	s.Equal("cancelled", pruned.Status)
+	s.Equal(scheduler.FailureReasonPruned, pruned.FailureReason)
+	s.Equal("running", s.jobModel.get(101).Status)
+	s.Equal("running", s.jobModel.get(102).Status)
+
+	s.finish(101, "succeeded")
+	s.finish(102, "succeeded")
+	s.Require().NoError(s.controller.ReconcileOnce())
+
+	result := s.sweepModel.get(7)
+	s.Equal(model.SweepStatusCompleted, result.Status)
+	s.Equal(1, result.PrunedCount)
+	s.Equal(int64(101), result.BestJobId)
+}
+
+// TestLeaderboard 排行榜按目标值排序，未上报指标的试验排在最后
+func (s *TestSweepControllerSuite) TestLeaderboard() {
+	s.setup(newSweep(sweep.AlgorithmRandom, 3, 3))
+	s.Require().NoError(s.controller.ReconcileOnce())
+	s.metricsModel.add(101, "val_loss", 0.5)
+	s.metricsModel.add(103, "val_loss", 0.2)
+
+	tracker := scheduler.NewSweepTracker(s.jobModel, s.relationModel, s.metricsModel)
+	trials, err := tracker.Trials(s.sweepModel.get(7))
+	s.Require().NoError(err)
+
+	ranked := scheduler.Leaderboard(trials, sweep.GoalMinimize)
+	s.Equal([]int64{103, 101, 102}, []int64{ranked[0].JobId, ranked[1].JobId, ranked[2].JobId})
+	s.Nil(ranked[2].Objective)
+}
+
+// TestSearchAlgorithms 随机和贝叶斯搜索的取值落在搜索范围内，网格遍历完后停止
+func (s *TestSweepControllerSuite) TestSearchAlgorithms() {
+	space, err := sweep.ParseSearchSpace(`{"lr":{"type":"loguniform","min":0.0001,"max":0.1},"batch":{"type":"choice","values":[16,32,64]},"layers":{"type":"int","min":2,"max":8}}`)
+	s.Require().NoError(err)
+	rng := rand.New(rand.NewSource(1))
+
+	var history []sweep.Observation
+	for i := 0; i < 12; i++ {
+		params, ok, err := sweep.Suggest(sweep.AlgorithmBayesian, space, i, history, sweep.GoalMinimize, rng)
+		s.Require().NoError(err)
+		s.True(ok)
+
+		lr := params["lr"].(float64)
+		s.True(lr >= 0.0001 && lr <= 0.1, "lr=%v", lr)
+		layers := params["layers"].(int64)
+		s.True(layers >= 2 && layers <= 8, "layers=%v", layers)
+		s.Contains([]interface{}{float64(16), float64(32), float64(64)}, params["batch"])
+		history = append(history, sweep.Observation{Params: params, Value: lr})
+	}
+
+	_, err = space.GridSize()
+	s.Error(err, "连续维度未指定取点数时不能网格搜索")
+
+	grid, err := sweep.ParseSearchSpace(`{"a":{"type":"choice","values":["x","y"]},"b":{"type":"uniform","min":0,"max":1,"points":3}}`)
+	s.Require().NoError(err)
+	size, err := grid.GridSize()
+	s.Require().NoError(err)
+	s.Equal(6, size)
+	_, ok, err := sweep.Suggest(sweep.AlgorithmGrid, grid, 6, nil, sweep.GoalMinimize, rng)
+	s.NoError(err)
+	s.False(ok)
+}
+
+// TestHyperband Hyperband在检查点只保留前1/eta的试验
+func (s *TestSweepControllerSuite) TestHyperband() {
+	policy, err := sweep.ParseEarlyStopping(`{"type":"hyperband","minResource":100,"eta":2}`)
+	s.Require().NoError(err)
+
+	series := func(values ...float64) []sweep.Point {
+		points := make([]sweep.Point, len(values))
+		for i, v := range values {
+			points[i] = sweep.Point{Step: int64(i+1) * 100, Value: v}
+		}
+		return points
+	}
+	others := [][]sweep.Point{series(0.5, 0.4), series(0.6)}
+
+	stop, _ := policy.ShouldStop(series(0.9), others, sweep.GoalMinimize)
+	s.True(stop)
+	stop, _ = policy.ShouldStop(series(0.3), others, sweep.GoalMinimize)
+	s.False(stop)
+	stop, _ = policy.ShouldStop(series(0.9), nil, sweep.GoalMinimize)
+	s.False(stop, "到达检查点的试验数不足eta时不终止")
+}
+
+// TestRunSweepControllerTests 运行超参数搜索测试
+func TestRunSweepControllerTests(t *testing.T) {
+	suite.Run(t, new(TestSweepControllerSuite))
+}
@@ -0,0 +1,130 @@
+package test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"api/model"
+	"api/pkg/scheduler"
+	"api/pkg/volcano"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/zeromicro/go-zero/core/logx"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	vcfake "volcano.sh/apis/pkg/client/clientset/versioned/fake"
+)
+
+type TestTensorboardSuite struct {
+	suite.Suite
+	kube       *k8sfake.Clientset
+	jobModel   *fakeTrainingJobsModel
+	controller *scheduler.TensorboardController
+}
+
+func (s *TestTensorboardSuite) SetupTest() {
+	s.kube = k8sfake.NewSimpleClientset()
+	s.jobModel = newFakeTrainingJobsModel(&model.VtTrainingJobs{
+		Id:                9,
+		Name:              "bert",
+		Status:            "running",
+		EnableTensorboard: true,
+		TensorboardPath:   "/output/tb",
+		VolumeMounts:      `[{"Name":"output","MountPath":"/output","Type":"pvc","PVCName":"output-bert"}]`,
+	}, &model.VtTrainingJobs{Id: 10, Name: "plain", Status: "running", TensorboardPath: "/output/tb"})
+
+	client := volcano.NewClientWithClientsets(vcfake.NewSimpleClientset(), s.kube, testNamespace)
+	s.controller = scheduler.NewTensorboardController(s.jobModel, volcano.NewJobManager(client), scheduler.TensorboardConfig{
+		TTL:   time.Hour,
+		Image: "tensorflow/tensorflow:2.15.0",
+	})
+}
+
+func (s *TestTensorboardSuite) deployments() []string {
+	list, err := s.kube.AppsV1().Deployments(testNamespace).List(context.Background(), metav1.ListOptions{})
+	s.Require().NoError(err)
+	var names []string
+	for _, d := range list.Items {
+		names = append(names, d.Name)
+	}
+	return names
+}
+
+// TestCreateForRunningJob 只为开启TensorBoard的作业创建，挂载作业存储卷并以代理路径为前缀
+func (s *TestTensorboardSuite) TestCreateForRunningJob() {
+	s.Require().NoError(s.controller.ReconcileOnce())
+	s.Require().NoError(s.controller.ReconcileOnce())
+
+	s.Equal([]string{"bert-9-tensorboard"}, s.deployments())
+	deployment, err := s.kube.AppsV1().Deployments(testNamespace).Get(context.Background(), "bert-9-tensorboard", metav1.GetOptions{})
+	s.Require().NoError(err)
+	s.Equal("9", deployment.Labels[scheduler.JobIDLabelKey])
+
+	container := deployment.Spec.Template.Spec.Containers[0]
+	s.Contains(container.Args, "--logdir=/output/tb")
+	s.Contains(container.Args, "--path_prefix=/api/v1/training/jobs/9/tensorboard")
+	s.Require().Len(container.VolumeMounts, 1)
+	s.True(container.VolumeMounts[0].ReadOnly)
+	s.Equal("output-bert", deployment.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+
+	service, err := s.kube.CoreV1().Services(testNamespace).Get(context.Background(), "bert-9-tensorboard", metav1.GetOptions{})
+	s.Require().NoError(err)
+	s.Equal(int32(volcano.TensorboardPort), service.Spec.Ports[0].Port)
+}
+
+// TestTeardownAfterTTL 作业结束未超过TTL时保留，超过TTL后删除Deployment和Service
+func (s *TestTensorboardSuite) TestTeardownAfterTTL() {
+	s.Require().NoError(s.controller.ReconcileOnce())
+
+	job := s.jobModel.jobs[9]
+	ended := time.Now().Add(-10 * time.Minute)
+	job.Status, job.EndTime = "succeeded", &ended
+	s.Require().NoError(s.controller.ReconcileOnce())
+	s.Equal([]string{"bert-9-tensorboard"}, s.deployments())
+
+	ended = time.Now().Add(-2 * time.Hour)
+	s.Require().NoError(s.controller.ReconcileOnce())
+	s.Empty(s.deployments())
+	services, err := s.kube.CoreV1().Services(testNamespace).List(context.Background(), metav1.ListOptions{})
+	s.Require().NoError(err)
+	s.Empty(services.Items)
+}
+
+// TestProxy 路径原样转发，用户的令牌和Cookie不转发给TensorBoard
+func (s *TestTensorboardSuite) TestProxy() {
+	var received *http.Request
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		io.WriteString(w, "tensorboard")
+	}))
+	defer backend.Close()
+
+	target, err := url.Parse(backend.URL)
+	s.Require().NoError(err)
+	proxy := scheduler.NewTensorboardProxy(target, logx.WithContext(context.Background()))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/training/jobs/9/tensorboard/data/plugin/scalars/scalars?run=train&token=secret",
+		strings.NewReader("tag=loss"))
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Cookie", "access_token=secret")
+	w := httptest.NewRecorder()
+	proxy.ServeHTTP(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("tensorboard", w.Body.String())
+	s.Require().NotNil(received)
+	s.Equal("/api/v1/training/jobs/9/tensorboard/data/plugin/scalars/scalars", received.URL.Path)
+	s.Equal("run=train", received.URL.RawQuery)
+	s.Empty(received.Header.Get("Authorization"))
+	s.Empty(received.Header.Get("Cookie"))
+}
+
+func TestRunTensorboardTests(t *testing.T) {
+	suite.Run(t, new(TestTensorboardSuite))
+}
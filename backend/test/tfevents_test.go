@@ -0,0 +1,246 @@
+package test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"api/model"
+	"api/pkg/scheduler"
+	"api/pkg/tfevents"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// 以下函数按tensorflow.Event的protobuf定义编码测试数据
+
+func encodeEvent(wallTime float64, step int64, values ...[]byte) []byte {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, math.Float64bits(wallTime))
+	b = protowire.AppendTag(b, 2, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(step))
+	var summary []byte
+	for _, v := range values {
+		summary = protowire.AppendTag(summary, 1, protowire.BytesType)
+		summary = protowire.AppendBytes(summary, v)
+	}
+	b = protowire.AppendTag(b, 5, protowire.BytesType)
+	return protowire.AppendBytes(b, summary)
+}
+
+func simpleValue(tag string, value float32) []byte {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, tag)
+	b = protowire.AppendTag(b, 2, protowire.Fixed32Type)
+	return protowire.AppendFixed32(b, math.Float32bits(value))
+}
+
+func histoValue(tag string, min, max, num, sum, sumSquares float64, limits, counts []float64) []byte {
+	var h []byte
+	for i, v := range []float64{min, max, num, sum, sumSquares} {
+		h = protowire.AppendTag(h, protowire.Number(i+1), protowire.Fixed64Type)
+		h = protowire.AppendFixed64(h, math.Float64bits(v))
+	}
+	for num, values := range map[protowire.Number][]float64{6: limits, 7: counts} {
+		var packed []byte
+		for _, v := range values {
+			packed = binary.LittleEndian.AppendUint64(packed, math.Float64bits(v))
+		}
+		h = protowire.AppendTag(h, num, protowire.BytesType)
+		h = protowire.AppendBytes(h, packed)
+	}
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, tag)
+	b = protowire.AppendTag(b, 5, protowire.BytesType)
+	return protowire.AppendBytes(b, h)
+}
+
+// tensorValue TF2写入的张量摘要，dims为张量形状
+func tensorValue(tag, plugin string, dtype uint64, content []byte, strs []string, dims ...int64) []byte {
+	var t []byte
+	t = protowire.AppendTag(t, 1, protowire.VarintType)
+	t = protowire.AppendVarint(t, dtype)
+	var shape []byte
+	for _, d := range dims {
+		var dim []byte
+		dim = protowire.AppendTag(dim, 1, protowire.VarintType)
+		dim = protowire.AppendVarint(dim, uint64(d))
+		shape = protowire.AppendTag(shape, 2, protowire.BytesType)
+		shape = protowire.AppendBytes(shape, dim)
+	}
+	t = protowire.AppendTag(t, 2, protowire.BytesType)
+	t = protowire.AppendBytes(t, shape)
+	if content != nil {
+		t = protowire.AppendTag(t, 4, protowire.BytesType)
+		t = protowire.AppendBytes(t, content)
+	}
+	for _, s := range strs {
+		t = protowire.AppendTag(t, 8, protowire.BytesType)
+		t = protowire.AppendString(t, s)
+	}
+
+	var pluginData, metadata []byte
+	pluginData = protowire.AppendTag(pluginData, 1, protowire.BytesType)
+	pluginData = protowire.AppendString(pluginData, plugin)
+	metadata = protowire.AppendTag(metadata, 1, protowire.BytesType)
+	metadata = protowire.AppendBytes(metadata, pluginData)
+
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, tag)
+	b = protowire.AppendTag(b, 9, protowire.BytesType)
+	b = protowire.AppendBytes(b, metadata)
+	b = protowire.AppendTag(b, 8, protowire.BytesType)
+	return protowire.AppendBytes(b, t)
+}
+
+func float32Content(values ...float32) []byte {
+	var b []byte
+	for _, v := range values {
+		b = binary.LittleEndian.AppendUint32(b, math.Float32bits(v))
+	}
+	return b
+}
+
+type TestTfeventsSuite struct {
+	suite.Suite
+}
+
+// TestRecordReader 末尾未写完的记录等待下次读取，数据校验失败的记录被跳过，记录头校验失败时停止
+func (s *TestTfeventsSuite) TestRecordReader() {
+	first := tfevents.AppendRecord(nil, []byte("first"))
+	second := tfevents.AppendRecord(nil, []byte("second"))
+	data := append(append([]byte{}, first...), second[:len(second)-3]...)
+
+	reader := tfevents.NewReader(bytes.NewReader(data), 0)
+	record, err := reader.Next()
+	s.Require().NoError(err)
+	s.Equal("first", string(record))
+	_, err = reader.Next()
+	s.Equal(io.EOF, err)
+	s.Equal(int64(len(first)), reader.Offset())
+
+	corrupted := append([]byte{}, second...)
+	corrupted[len(corrupted)-6] ^= 0xff
+	reader = tfevents.NewReader(bytes.NewReader(append(corrupted, first...)), 0)
+	_, err = reader.Next()
+	s.ErrorIs(err, tfevents.ErrDataCorrupt)
+	record, err = reader.Next()
+	s.Require().NoError(err)
+	s.Equal("first", string(record))
+
+	corrupted = append([]byte{}, second...)
+	corrupted[0] ^= 0xff
+	_, err = tfevents.NewReader(bytes.NewReader(corrupted), 0).Next()
+	s.ErrorIs(err, tfevents.ErrCorrupt)
+}
+
+// TestParseEvent 解码PyTorch写入的simple_value、histo和TF2按插件写入的张量摘要
+func (s *TestTfeventsSuite) TestParseEvent() {
+	event, err := tfevents.ParseEvent(encodeEvent(1772416800.5, 42,
+		simpleValue("loss", 0.25),
+		histoValue("weights", -1, 3, 4, 4, 12, []float64{0, 2, 4}, []float64{1, 2, 1}),
+		tensorValue("accuracy", "scalars", 1, float32Content(0.75), nil),
+		tensorValue("grads", "histograms", 1, float32Content(0, 1, 1, 1, 2, 2), nil, 2, 3),
+		tensorValue("sample", "text", 7, nil, []string{"hello"}),
+		tensorValue("image", "images", 7, nil, []string{"png"}),
+	))
+	s.Require().NoError(err)
+	s.Equal(1772416800.5, event.WallTime)
+	s.Equal(int64(42), event.Step)
+	s.Require().Len(event.Values, 5)
+
+	s.Equal(tfevents.KindScalar, event.Values[0].Kind)
+	s.Equal(0.25, event.Values[0].Scalar)
+
+	s.Equal(tfevents.KindHistogram, event.Values[1].Kind)
+	s.Equal([]float64{1, 2, 1}, event.Values[1].Histogram.BucketCounts)
+
+	s.Equal(tfevents.KindScalar, event.Values[2].Kind)
+	s.Equal(0.75, event.Values[2].Scalar)
+
+	grads := event.Values[3].Histogram
+	s.Require().NotNil(grads)
+	s.Equal(0.0, grads.Min)
+	s.Equal(2.0, grads.Max)
+	s.Equal(3.0, grads.Num)
+	s.Equal([]float64{1, 2}, grads.BucketLimits)
+
+	s.Equal(tfevents.KindText, event.Values[4].Kind)
+	s.Equal("hello", event.Values[4].Text)
+}
+
+// TestImportJob 按run目录推断阶段，增量导入新追加的事件，重启后不重复导入
+func (s *TestTfeventsSuite) TestImportJob() {
+	root := s.T().TempDir()
+	stateDir := s.T().TempDir()
+	start := time.Unix(1772416800, 0)
+	job := &model.VtTrainingJobs{Id: 3, EnableTensorboard: true, TensorboardPath: "jobs/3/tb", StartTime: &start}
+
+	trainFile := filepath.Join(root, "jobs/3/tb/train/events.out.tfevents.1772416800.host")
+	valFile := filepath.Join(root, "jobs/3/tb/validation/events.out.tfevents.1772416800.host")
+	s.Require().NoError(os.MkdirAll(filepath.Dir(trainFile), 0755))
+	s.Require().NoError(os.MkdirAll(filepath.Dir(valFile), 0755))
+
+	var train []byte
+	train = tfevents.AppendRecord(train, encodeEvent(1772416800, 0)) // 文件头事件，没有摘要
+	train = tfevents.AppendRecord(train, encodeEvent(1772416810, 100, simpleValue("loss", 0.5)))
+	s.Require().NoError(os.WriteFile(trainFile, train, 0644))
+	val := tfevents.AppendRecord(nil, encodeEvent(1772416820, 100, simpleValue("loss", 0.75), simpleValue("bad", float32(math.Inf(1)))))
+	s.Require().NoError(os.WriteFile(valFile, val, 0644))
+
+	metrics := &fakeMetricsModel{}
+	config := scheduler.TfeventsImporterConfig{Root: root, StateDir: stateDir}
+	importer := scheduler.NewTfeventsImporter(newFakeTrainingJobsModel(job), metrics, config)
+	s.Require().NoError(importer.ImportJob(job))
+
+	s.Require().Len(metrics.inserted, 2)
+	s.Equal("loss", metrics.inserted[0].MetricName)
+	s.Equal("0.5", metrics.inserted[0].MetricValue)
+	s.Equal(int64(100), metrics.inserted[0].Step)
+	s.Equal("train", metrics.inserted[0].Phase)
+	s.Equal("train", metrics.inserted[0].Tag)
+	s.Equal(scheduler.TfeventsCategory, metrics.inserted[0].Category)
+	s.Equal("10.000", metrics.inserted[0].RelativeTimeSeconds)
+	s.Equal("val", metrics.inserted[1].Phase)
+	s.Equal("0.75", metrics.inserted[1].MetricValue)
+
+	// 追加一条完整记录和一条未写完的记录
+	next := tfevents.AppendRecord(nil, encodeEvent(1772416830, 200, simpleValue("loss", 0.25)))
+	partial := tfevents.AppendRecord(nil, encodeEvent(1772416840, 300, simpleValue("loss", 0.125)))
+	f, err := os.OpenFile(trainFile, os.O_APPEND|os.O_WRONLY, 0644)
+	s.Require().NoError(err)
+	_, err = f.Write(append(next, partial[:10]...))
+	s.Require().NoError(err)
+	s.Require().NoError(f.Close())
+
+	s.Require().NoError(importer.ImportJob(job))
+	s.Require().Len(metrics.inserted, 3)
+	s.Equal(int64(200), metrics.inserted[2].Step)
+
+	// 重启后从保存的位置继续
+	f, err = os.OpenFile(trainFile, os.O_APPEND|os.O_WRONLY, 0644)
+	s.Require().NoError(err)
+	_, err = f.Write(partial[10:])
+	s.Require().NoError(err)
+	s.Require().NoError(f.Close())
+
+	restarted := scheduler.NewTfeventsImporter(newFakeTrainingJobsModel(job), metrics, config)
+	s.Require().NoError(restarted.ImportJob(job))
+	s.Require().Len(metrics.inserted, 4)
+	s.Equal(int64(300), metrics.inserted[3].Step)
+	s.Equal("0.125", metrics.inserted[3].MetricValue)
+}
+
+func TestRunTfeventsTests(t *testing.T) {
+	suite.Run(t, new(TestTfeventsSuite))
+}
@@ -0,0 +1,1016 @@
+package test
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"api/model"
+	"api/pkg/scheduler"
+)
+
+// fakeTrainingJobsModel 基于内存的训练作业模型，仅实现测试用到的方法
+type fakeTrainingJobsModel struct {
+	model.VtTrainingJobsModel
+
+	mu          sync.Mutex
+	jobs        map[int64]*model.VtTrainingJobs
+	transitions *fakeTransitionsModel
+	retries     *fakeRetriesModel
+}
+
+func newFakeTrainingJobsModel(jobs ...*model.VtTrainingJobs) *fakeTrainingJobsModel {
+	m := &fakeTrainingJobsModel{
+		jobs:        make(map[int64]*model.VtTrainingJobs),
+		transitions: &fakeTransitionsModel{},
+		retries:     &fakeRetriesModel{},
+	}
+	for _, job := range jobs {
+		m.jobs[job.Id] = job
+	}
+	return m
+}
+
+// get 获取作业副本
+func (m *fakeTrainingJobsModel) get(id int64) *model.VtTrainingJobs {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil
+	}
+	copied := *job
+	return &copied
+}
+
+func (m *fakeTrainingJobsModel) FindOneDetail(id int64) (*model.VtTrainingJobs, error) {
+	job := m.get(id)
+	if job == nil {
+		return nil, sql.ErrNoRows
+	}
+	return job, nil
+}
+
+func (m *fakeTrainingJobsModel) FindOneByName(name string) (*model.VtTrainingJobs, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, job := range m.jobs {
+		if job.Name == name && job.DeletedAt == nil {
+			copied := *job
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *fakeTrainingJobsModel) FindDispatchable(limit int) ([]*model.VtTrainingJobs, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var jobs []*model.VtTrainingJobs
+	for _, job := range m.jobs {
+		if job.Status == "pending" || (job.Status == "queued" && job.ScheduledAt == nil) {
+			copied := *job
+			jobs = append(jobs, &copied)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Id < jobs[j].Id })
+	if len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	return jobs, nil
+}
+
+func (m *fakeTrainingJobsModel) FindSubmitted() ([]*model.VtTrainingJobs, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var jobs []*model.VtTrainingJobs
+	for _, job := range m.jobs {
+		switch job.Status {
+		case "queued", "scheduling", "running", "suspended":
+			if job.ScheduledAt != nil {
+				copied := *job
+				jobs = append(jobs, &copied)
+			}
+		}
+	}
+	return jobs, nil
+}
+
+func (m *fakeTrainingJobsModel) FindFinishedSince(since time.Time) ([]*model.VtTrainingJobs, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var jobs []*model.VtTrainingJobs
+	for _, job := range m.jobs {
+		if scheduler.IsTerminalJobStatus(job.Status) && job.EndTime != nil && !job.EndTime.Before(since) {
+			copied := *job
+			jobs = append(jobs, &copied)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Id < jobs[j].Id })
+	return jobs, nil
+}
+
+func (m *fakeTrainingJobsModel) FindRunning() ([]*model.VtTrainingJobs, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var jobs []*model.VtTrainingJobs
+	for _, job := range m.jobs {
+		if job.Status == "running" {
+			copied := *job
+			jobs = append(jobs, &copied)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Id < jobs[j].Id })
+	return jobs, nil
+}
+
+func (m *fakeTrainingJobsModel) FindRetryCandidates(failureReasons []string, backoffBase, backoffMax time.Duration, now time.Time, limit int) ([]*model.VtTrainingJobs, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var jobs []*model.VtTrainingJobs
+	for _, job := range m.jobs {
+		if !job.AutoRestart || (job.Status != "failed" && job.Status != "oom_killed") {
+			continue
+		}
+		retriable := false
+		for _, reason := range failureReasons {
+			if job.FailureReason == reason {
+				retriable = true
+			}
+		}
+		attempts, _ := m.retries.CountByJobId(job.Id)
+		if !retriable || attempts >= int64(job.MaxRetryCount) || job.EndTime == nil {
+			continue
+		}
+		if now.Before(job.EndTime.Add(scheduler.RetryBackoff(backoffBase, backoffMax, int(attempts)))) {
+			continue
+		}
+		copied := *job
+		jobs = append(jobs, &copied)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].EndTime.Before(*jobs[j].EndTime) })
+	if len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	return jobs, nil
+}
+
+func (m *fakeTrainingJobsModel) MarkScheduled(id int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok || job.Status != "queued" || job.ScheduledAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	job.ScheduledAt = &now
+	job.ErrorCode = ""
+	job.ErrorMessage = ""
+	return true, nil
+}
+
+func (m *fakeTrainingJobsModel) TransitionStatus(id int64, fromStatus, toStatus string, fields map[string]interface{}, record *model.VtTrainingJobTransitions, retry *model.VtTrainingJobRetries) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok || job.Status != fromStatus {
+		return false, nil
+	}
+	job.Status = toStatus
+	for column, value := range fields {
+		applyJobField(job, column, value)
+	}
+	if m.transitions != nil && record != nil {
+		m.transitions.Insert(record)
+	}
+	if m.retries != nil && retry != nil {
+		m.retries.Insert(retry)
+	}
+	return true, nil
+}
+
+// applyJobField 按列名更新作业字段，nil表示清空
+func applyJobField(job *model.VtTrainingJobs, column string, value interface{}) {
+	str, _ := value.(string)
+	var ts *time.Time
+	if t, ok := value.(time.Time); ok {
+		ts = &t
+	}
+
+	switch column {
+	case "phase":
+		job.Phase = str
+	case "volcano_job_name":
+		job.VolcanoJobName = str
+	case "namespace":
+		job.Namespace = str
+	case "error_code":
+		job.ErrorCode = str
+	case "error_message":
+		job.ErrorMessage = str
+	case "failure_reason":
+		job.FailureReason = str
+	case "queued_at":
+		job.QueuedAt = ts
+	case "scheduled_at":
+		job.ScheduledAt = ts
+	case "start_time":
+		job.StartTime = ts
+	case "end_time":
+		job.EndTime = ts
+	case "resume_checkpoint_path":
+		job.ResumeCheckpointPath = str
+	case "resume_checkpoint_id":
+		id, _ := value.(int64)
+		job.ResumeCheckpointId = id
+	case "duration_seconds":
+		if seconds, ok := value.(int64); ok {
+			job.DurationSeconds = int(seconds)
+		}
+	case "exit_code":
+		if code, ok := value.(int); ok {
+			job.ExitCode = code
+		} else {
+			job.ExitCode = 0
+		}
+	}
+}
+
+// fakeTransitionsModel 基于内存的状态变更记录模型
+type fakeTransitionsModel struct {
+	mu      sync.Mutex
+	records []*model.VtTrainingJobTransitions
+}
+
+func (m *fakeTransitionsModel) Insert(data *model.VtTrainingJobTransitions) (sql.Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	copied := *data
+	copied.Id = int64(len(m.records) + 1)
+	copied.CreatedAt = time.Now()
+	m.records = append(m.records, &copied)
+	return nil, nil
+}
+
+func (m *fakeTransitionsModel) FindByJobId(jobId int64) ([]*model.VtTrainingJobTransitions, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var records []*model.VtTrainingJobTransitions
+	for _, record := range m.records {
+		if record.JobId == jobId {
+			copied := *record
+			records = append(records, &copied)
+		}
+	}
+	return records, nil
+}
+
+func (m *fakeTransitionsModel) CountByActions(jobId int64, actions ...string) (int64, error) {
+	records, _ := m.FindByJobId(jobId)
+
+	var count int64
+	for _, record := range records {
+		for _, action := range actions {
+			if record.Action == action {
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+// fakeInstancesModel 基于内存的训练任务实例模型
+type fakeInstancesModel struct {
+	mu        sync.Mutex
+	instances []*model.VtTrainingJobInstances
+	gpuUsage  map[int64]gpuUsageSample
+}
+
+// gpuUsageSample 作业最近一次采集的GPU使用率
+type gpuUsageSample struct {
+	percent   float64
+	sampledAt time.Time
+}
+
+func (m *fakeInstancesModel) Upsert(data *model.VtTrainingJobInstances) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	copied := *data
+	for i, instance := range m.instances {
+		if instance.JobId == data.JobId && instance.InstanceName == data.InstanceName {
+			copied.Id = instance.Id
+			m.instances[i] = &copied
+			return nil
+		}
+	}
+	copied.Id = int64(len(m.instances) + 1)
+	m.instances = append(m.instances, &copied)
+	return nil
+}
+
+func (m *fakeInstancesModel) FindOne(id int64) (*model.VtTrainingJobInstances, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, instance := range m.instances {
+		if instance.Id == id {
+			copied := *instance
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *fakeInstancesModel) FindByJobId(jobId int64) ([]*model.VtTrainingJobInstances, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var instances []*model.VtTrainingJobInstances
+	for _, instance := range m.instances {
+		if instance.JobId == jobId {
+			copied := *instance
+			instances = append(instances, &copied)
+		}
+	}
+	return instances, nil
+}
+
+func (m *fakeInstancesModel) UpdateStatus(id int64, status, reason string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, instance := range m.instances {
+		if instance.Id == id {
+			instance.Status = status
+			instance.Reason = reason
+		}
+	}
+	return nil
+}
+
+func (m *fakeInstancesModel) UpdateGpuUsageByPod(namespace, podName string, gpuPercent float64, sampledAt time.Time) error {
+	m.mu.Lock()
+	var jobIds []int64
+	for _, instance := range m.instances {
+		if instance.Namespace == namespace && instance.PodName == podName && instance.Status == "running" {
+			jobIds = append(jobIds, instance.JobId)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, jobId := range jobIds {
+		m.setGpuUsage(jobId, gpuPercent, sampledAt)
+	}
+	return nil
+}
+
+// setGpuUsage 按指定采集时间写入作业的GPU使用率，同一次采集的多个实例取最大值
+func (m *fakeInstancesModel) setGpuUsage(jobId int64, percent float64, sampledAt time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.gpuUsage == nil {
+		m.gpuUsage = make(map[int64]gpuUsageSample)
+	}
+	if sample, ok := m.gpuUsage[jobId]; ok && sample.sampledAt.Equal(sampledAt) && sample.percent > percent {
+		return
+	}
+	m.gpuUsage[jobId] = gpuUsageSample{percent: percent, sampledAt: sampledAt}
+}
+
+func (m *fakeInstancesModel) GetGpuUsage(jobId int64, since time.Time) (float64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sample, ok := m.gpuUsage[jobId]
+	if !ok || sample.sampledAt.Before(since) {
+		return 0, false, nil
+	}
+	return sample.percent, true, nil
+}
+
+// fakeRetriesModel 基于内存的自动重试记录模型
+type fakeRetriesModel struct {
+	mu      sync.Mutex
+	records []*model.VtTrainingJobRetries
+}
+
+func (m *fakeRetriesModel) Insert(data *model.VtTrainingJobRetries) (sql.Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	copied := *data
+	copied.Id = int64(len(m.records) + 1)
+	copied.CreatedAt = time.Now()
+	m.records = append(m.records, &copied)
+	return nil, nil
+}
+
+func (m *fakeRetriesModel) FindByJobId(jobId int64) ([]*model.VtTrainingJobRetries, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var records []*model.VtTrainingJobRetries
+	for _, record := range m.records {
+		if record.JobId == jobId {
+			copied := *record
+			records = append(records, &copied)
+		}
+	}
+	return records, nil
+}
+
+func (m *fakeRetriesModel) CountByJobId(jobId int64) (int64, error) {
+	records, _ := m.FindByJobId(jobId)
+	return int64(len(records)), nil
+}
+
+// fakeCheckpointsModel 基于内存的检查点模型，仅实现测试用到的方法
+type fakeCheckpointsModel struct {
+	model.VtTrainingCheckpointsModel
+
+	mu          sync.Mutex
+	checkpoints []*model.VtTrainingCheckpoints
+}
+
+// fakeResult 返回指定自增ID的sql.Result
+type fakeResult struct {
+	id int64
+}
+
+func (r fakeResult) LastInsertId() (int64, error) { return r.id, nil }
+func (r fakeResult) RowsAffected() (int64, error) { return 1, nil }
+
+// Insert 与表的唯一键一致，同一作业的同名检查点覆盖原记录
+func (m *fakeCheckpointsModel) Insert(data *model.VtTrainingCheckpoints) (sql.Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	copied := *data
+	for i, c := range m.checkpoints {
+		if c.JobId == data.JobId && c.CheckpointName == data.CheckpointName {
+			copied.Id = c.Id
+			m.checkpoints[i] = &copied
+			return fakeResult{id: c.Id}, nil
+		}
+	}
+	copied.Id = int64(len(m.checkpoints) + 1)
+	m.checkpoints = append(m.checkpoints, &copied)
+	return fakeResult{id: copied.Id}, nil
+}
+
+func (m *fakeCheckpointsModel) find(match func(*model.VtTrainingCheckpoints) bool) (*model.VtTrainingCheckpoints, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, c := range m.checkpoints {
+		if match(c) {
+			copied := *c
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *fakeCheckpointsModel) FindOne(id int64) (*model.VtTrainingCheckpoints, error) {
+	return m.find(func(c *model.VtTrainingCheckpoints) bool { return c.Id == id && c.Status != "deleted" })
+}
+
+func (m *fakeCheckpointsModel) FindOneByName(jobId int64, name string) (*model.VtTrainingCheckpoints, error) {
+	return m.find(func(c *model.VtTrainingCheckpoints) bool { return c.JobId == jobId && c.CheckpointName == name })
+}
+
+func (m *fakeCheckpointsModel) update(id int64, apply func(*model.VtTrainingCheckpoints)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, c := range m.checkpoints {
+		if c.Id == id {
+			apply(c)
+		}
+	}
+	return nil
+}
+
+func (m *fakeCheckpointsModel) UpdateStatus(id int64, status string) error {
+	return m.update(id, func(c *model.VtTrainingCheckpoints) { c.Status = status })
+}
+
+func (m *fakeCheckpointsModel) Delete(id int64) error {
+	return m.update(id, func(c *model.VtTrainingCheckpoints) { c.Status, c.IsBest, c.IsLatest = "deleted", false, false })
+}
+
+func (m *fakeCheckpointsModel) MarkLatest(jobId, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, c := range m.checkpoints {
+		if c.JobId == jobId {
+			c.IsLatest = c.Id == id
+		}
+	}
+	return nil
+}
+
+func (m *fakeCheckpointsModel) MarkBest(jobId, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, c := range m.checkpoints {
+		if c.JobId == jobId {
+			c.IsBest = c.Id == id
+		}
+	}
+	return nil
+}
+
+func (m *fakeCheckpointsModel) FindLatest(jobId int64) (*model.VtTrainingCheckpoints, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var latest *model.VtTrainingCheckpoints
+	for _, checkpoint := range m.checkpoints {
+		if checkpoint.JobId != jobId || checkpoint.Status != "saved" {
+			continue
+		}
+		if latest == nil || checkpoint.GlobalStep > latest.GlobalStep {
+			latest = checkpoint
+		}
+	}
+	if latest == nil {
+		return nil, sql.ErrNoRows
+	}
+	copied := *latest
+	return &copied, nil
+}
+
+func (m *fakeCheckpointsModel) FindBest(jobId int64) (*model.VtTrainingCheckpoints, error) {
+	m.mu.Lock()
+	for _, checkpoint := range m.checkpoints {
+		if checkpoint.JobId == jobId && checkpoint.Status == "saved" && checkpoint.IsBest {
+			copied := *checkpoint
+			m.mu.Unlock()
+			return &copied, nil
+		}
+	}
+	m.mu.Unlock()
+	return m.FindLatest(jobId)
+}
+
+// FindByJobId 与表查询一致，按步数从大到小排序
+func (m *fakeCheckpointsModel) FindByJobId(jobId int64) ([]*model.VtTrainingCheckpoints, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var checkpoints []*model.VtTrainingCheckpoints
+	for _, c := range m.checkpoints {
+		if c.JobId == jobId && c.Status != "deleted" {
+			copied := *c
+			checkpoints = append(checkpoints, &copied)
+		}
+	}
+	sort.SliceStable(checkpoints, func(i, j int) bool {
+		if checkpoints[i].GlobalStep != checkpoints[j].GlobalStep {
+			return checkpoints[i].GlobalStep > checkpoints[j].GlobalStep
+		}
+		return checkpoints[i].Id > checkpoints[j].Id
+	})
+	return checkpoints, nil
+}
+
+func (m *fakeCheckpointsModel) FindJobIds() ([]int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seen := make(map[int64]bool)
+	var jobIds []int64
+	for _, c := range m.checkpoints {
+		if c.Status != "deleted" && !seen[c.JobId] {
+			seen[c.JobId] = true
+			jobIds = append(jobIds, c.JobId)
+		}
+	}
+	sort.Slice(jobIds, func(i, j int) bool { return jobIds[i] < jobIds[j] })
+	return jobIds, nil
+}
+
+// fakeRetentionPoliciesModel 基于内存的检查点保留策略模型
+type fakeRetentionPoliciesModel struct {
+	model.VtCheckpointRetentionPoliciesModel
+
+	mu       sync.Mutex
+	policies []*model.VtCheckpointRetentionPolicies
+}
+
+func (m *fakeRetentionPoliciesModel) Insert(data *model.VtCheckpointRetentionPolicies) (sql.Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	copied := *data
+	copied.Id = int64(len(m.policies) + 1)
+	m.policies = append(m.policies, &copied)
+	return fakeResult{id: copied.Id}, nil
+}
+
+func (m *fakeRetentionPoliciesModel) find(match func(*model.VtCheckpointRetentionPolicies) bool) (*model.VtCheckpointRetentionPolicies, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, p := range m.policies {
+		if match(p) {
+			copied := *p
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *fakeRetentionPoliciesModel) FindOne(id int64) (*model.VtCheckpointRetentionPolicies, error) {
+	return m.find(func(p *model.VtCheckpointRetentionPolicies) bool { return p.Id == id })
+}
+
+func (m *fakeRetentionPoliciesModel) FindOneByScope(scopeType string, scopeId int64) (*model.VtCheckpointRetentionPolicies, error) {
+	return m.find(func(p *model.VtCheckpointRetentionPolicies) bool {
+		return p.ScopeType == scopeType && p.ScopeId == scopeId
+	})
+}
+
+func (m *fakeRetentionPoliciesModel) FindEnabled() ([]*model.VtCheckpointRetentionPolicies, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var policies []*model.VtCheckpointRetentionPolicies
+	for _, p := range m.policies {
+		if p.Enabled {
+			copied := *p
+			policies = append(policies, &copied)
+		}
+	}
+	return policies, nil
+}
+
+func (m *fakeRetentionPoliciesModel) Update(data *model.VtCheckpointRetentionPolicies) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, p := range m.policies {
+		if p.Id == data.Id {
+			copied := *data
+			m.policies[i] = &copied
+		}
+	}
+	return nil
+}
+
+// fakeSweepsModel 基于内存的超参数搜索模型
+type fakeSweepsModel struct {
+	model.VtTrainingSweepsModel
+
+	mu     sync.Mutex
+	sweeps map[int64]*model.VtTrainingSweeps
+}
+
+func newFakeSweepsModel(sweeps ...*model.VtTrainingSweeps) *fakeSweepsModel {
+	m := &fakeSweepsModel{sweeps: make(map[int64]*model.VtTrainingSweeps)}
+	for _, s := range sweeps {
+		m.sweeps[s.Id] = s
+	}
+	return m
+}
+
+func (m *fakeSweepsModel) get(id int64) *model.VtTrainingSweeps {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	copied := *m.sweeps[id]
+	return &copied
+}
+
+func (m *fakeSweepsModel) FindRunning() ([]*model.VtTrainingSweeps, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var sweeps []*model.VtTrainingSweeps
+	for _, s := range m.sweeps {
+		if s.Status == model.SweepStatusRunning {
+			copied := *s
+			sweeps = append(sweeps, &copied)
+		}
+	}
+	sort.Slice(sweeps, func(i, j int) bool { return sweeps[i].Id < sweeps[j].Id })
+	return sweeps, nil
+}
+
+func (m *fakeSweepsModel) UpdateProgress(id int64, progress *model.SweepProgress) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.sweeps[id]
+	s.TrialCount = progress.TrialCount
+	s.CompletedCount = progress.CompletedCount
+	s.PrunedCount = progress.PrunedCount
+	s.BestJobId = progress.BestJobId
+	s.BestValue = progress.BestValue
+	return nil
+}
+
+func (m *fakeSweepsModel) Finish(id int64, status, errorMessage string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.sweeps[id]
+	if s.Status != model.SweepStatusRunning {
+		return false, nil
+	}
+	now := time.Now()
+	s.Status = status
+	s.ErrorMessage = errorMessage
+	s.FinishedAt = &now
+	return true, nil
+}
+
+// fakeRelationsModel 基于内存的作业关联关系模型
+type fakeRelationsModel struct {
+	model.VtTrainingJobRelationsModel
+
+	mu        sync.Mutex
+	relations []*model.VtTrainingJobRelations
+}
+
+func (m *fakeRelationsModel) Insert(data *model.VtTrainingJobRelations) (sql.Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	copied := *data
+	copied.Id = int64(len(m.relations) + 1)
+	copied.Status = "active"
+	m.relations = append(m.relations, &copied)
+	return nil, nil
+}
+
+func (m *fakeRelationsModel) FindOne(id int64) (*model.VtTrainingJobRelations, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, r := range m.relations {
+		if r.Id == id && r.Status != "deleted" {
+			copied := *r
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *fakeRelationsModel) FindByJobId(jobId int64, entityType, relationType string) ([]*model.VtTrainingJobRelations, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var relations []*model.VtTrainingJobRelations
+	for _, r := range m.relations {
+		if r.JobId == jobId && (entityType == "" || r.EntityType == entityType) &&
+			(relationType == "" || r.RelationType == relationType) && r.Status != "deleted" {
+			copied := *r
+			relations = append(relations, &copied)
+		}
+	}
+	return relations, nil
+}
+
+func (m *fakeRelationsModel) Delete(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, r := range m.relations {
+		if r.Id == id {
+			r.Status = "deleted"
+		}
+	}
+	return nil
+}
+
+func (m *fakeRelationsModel) FindByEntity(entityType string, entityId int64, relationType string) ([]*model.VtTrainingJobRelations, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var relations []*model.VtTrainingJobRelations
+	for _, r := range m.relations {
+		if r.EntityType == entityType && r.EntityId == entityId && r.RelationType == relationType && r.Status != "deleted" {
+			copied := *r
+			relations = append(relations, &copied)
+		}
+	}
+	return relations, nil
+}
+
+// fakeMetricsModel 基于内存的训练指标模型
+type fakeMetricsModel struct {
+	model.VtTrainingMetricsModel
+
+	mu       sync.Mutex
+	series   map[int64]map[string][]model.MetricPoint
+	inserted []*model.VtTrainingMetrics
+}
+
+func (m *fakeMetricsModel) add(jobId int64, name string, values ...float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.series == nil {
+		m.series = make(map[int64]map[string][]model.MetricPoint)
+	}
+	if m.series[jobId] == nil {
+		m.series[jobId] = make(map[string][]model.MetricPoint)
+	}
+	for _, value := range values {
+		points := m.series[jobId][name]
+		m.series[jobId][name] = append(points, model.MetricPoint{Step: int64(len(points)+1) * 100, Value: value})
+	}
+}
+
+func (m *fakeMetricsModel) FindScalarSeries(jobId int64, metricName string) ([]model.MetricPoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]model.MetricPoint(nil), m.series[jobId][metricName]...), nil
+}
+
+// FindSeries 返回未区分阶段的完整曲线，忽略时间条件
+func (m *fakeMetricsModel) FindSeries(filter model.TrainingMetricFilter, maxPoints int) ([]*model.MetricSeries, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	points := m.series[filter.JobId][filter.MetricName]
+	if len(points) == 0 {
+		return nil, nil
+	}
+	series := &model.MetricSeries{MetricName: filter.MetricName, Total: int64(len(points))}
+	for _, point := range points {
+		series.Points = append(series.Points, model.MetricSeriesPoint{Step: point.Step, Value: point.Value, Min: point.Value, Max: point.Value})
+	}
+	return []*model.MetricSeries{series}, nil
+}
+
+func (m *fakeMetricsModel) BatchInsert(metrics []*model.VtTrainingMetrics) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.inserted = append(m.inserted, metrics...)
+	return nil
+}
+
+// fakeTriggersModel 基于内存的定时触发器模型
+type fakeTriggersModel struct {
+	model.VtTrainingTriggersModel
+
+	mu       sync.Mutex
+	triggers map[int64]*model.VtTrainingTriggers
+}
+
+func newFakeTriggersModel(triggers ...*model.VtTrainingTriggers) *fakeTriggersModel {
+	m := &fakeTriggersModel{triggers: make(map[int64]*model.VtTrainingTriggers)}
+	for _, t := range triggers {
+		m.triggers[t.Id] = t
+	}
+	return m
+}
+
+func (m *fakeTriggersModel) get(id int64) *model.VtTrainingTriggers {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	copied := *m.triggers[id]
+	return &copied
+}
+
+func (m *fakeTriggersModel) FindDue(now time.Time, limit int) ([]*model.VtTrainingTriggers, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var triggers []*model.VtTrainingTriggers
+	for _, t := range m.triggers {
+		if t.Enabled && t.NextRunAt != nil && !t.NextRunAt.After(now) {
+			copied := *t
+			triggers = append(triggers, &copied)
+		}
+	}
+	sort.Slice(triggers, func(i, j int) bool { return triggers[i].Id < triggers[j].Id })
+	return triggers, nil
+}
+
+func (m *fakeTriggersModel) Advance(id int64, from time.Time, to *time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t := m.triggers[id]
+	if !t.Enabled || t.NextRunAt == nil || !t.NextRunAt.Equal(from) {
+		return false, nil
+	}
+	t.NextRunAt = to
+	return true, nil
+}
+
+func (m *fakeTriggersModel) RecordJob(id, jobId int64, runAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t := m.triggers[id]
+	t.LastRunAt = &runAt
+	t.LastJobId = jobId
+	t.RunCount++
+	return nil
+}
+
+// fakeTriggerRunsModel 基于内存的定时触发记录模型
+type fakeTriggerRunsModel struct {
+	model.VtTrainingTriggerRunsModel
+
+	mu   sync.Mutex
+	runs []*model.VtTrainingTriggerRuns
+}
+
+func (m *fakeTriggerRunsModel) Insert(data *model.VtTrainingTriggerRuns) (sql.Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	copied := *data
+	copied.Id = int64(len(m.runs) + 1)
+	m.runs = append(m.runs, &copied)
+	return nil, nil
+}
+
+func (m *fakeTriggerRunsModel) FindRecentJobs(triggerId int64, limit int) ([]*model.VtTrainingTriggerRuns, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var runs []*model.VtTrainingTriggerRuns
+	for i := len(m.runs) - 1; i >= 0 && len(runs) < limit; i-- {
+		if r := m.runs[i]; r.TriggerId == triggerId && r.Status == model.TriggerRunCreated {
+			copied := *r
+			runs = append(runs, &copied)
+		}
+	}
+	return runs, nil
+}
+
+// statuses 按写入顺序返回触发结果
+func (m *fakeTriggerRunsModel) statuses() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	statuses := make([]string, 0, len(m.runs))
+	for _, r := range m.runs {
+		statuses = append(statuses, r.Status)
+	}
+	return statuses
+}
+
+// fakeLogsModel 基于内存的训练日志模型，failNext为true时下一次批量写入失败
+type fakeLogsModel struct {
+	model.VtTrainingLogsModel
+
+	mu       sync.Mutex
+	logs     []*model.VtTrainingLogs
+	failNext bool
+}
+
+func (m *fakeLogsModel) BatchInsert(logs []*model.VtTrainingLogs) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.failNext {
+		m.failNext = false
+		return errors.New("database unavailable")
+	}
+	for _, l := range logs {
+		copied := *l
+		copied.Id = int64(len(m.logs) + 1)
+		m.logs = append(m.logs, &copied)
+	}
+	return nil
+}
+
+// contents 返回 "实例: 内容" 形式的已索引日志
+func (m *fakeLogsModel) contents() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make([]string, 0, len(m.logs))
+	for _, l := range m.logs {
+		result = append(result, l.LogSource+": "+l.LogContent)
+	}
+	return result
+}
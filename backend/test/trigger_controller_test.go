@@ -0,0 +1,189 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"api/model"
+	"api/pkg/cron"
+	"api/pkg/scheduler"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// CreateTriggeredJob 按触发器生成的作业名称创建作业
+func (c *fakeJobCreator) CreateTriggeredJob(ctx context.Context, trigger *model.VtTrainingTriggers, name string) (int64, error) {
+	spec, _ := json.Marshal(map[string]interface{}{"name": name})
+	return c.CreateTrainingJob(ctx, spec)
+}
+
+type TestTriggerControllerSuite struct {
+	suite.Suite
+	loc          *time.Location
+	jobModel     *fakeTrainingJobsModel
+	triggerModel *fakeTriggersModel
+	runModel     *fakeTriggerRunsModel
+	creator      *fakeJobCreator
+	controller   *scheduler.TriggerController
+}
+
+func (s *TestTriggerControllerSuite) SetupTest() {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	s.Require().NoError(err)
+	s.loc = loc
+}
+
+func (s *TestTriggerControllerSuite) setup(t *model.VtTrainingTriggers) {
+	s.jobModel = newFakeTrainingJobsModel()
+	s.triggerModel = newFakeTriggersModel(t)
+	s.runModel = &fakeTriggerRunsModel{}
+	s.creator = &fakeJobCreator{jobs: s.jobModel, nextId: 100}
+
+	machine := scheduler.NewJobStateMachine(s.jobModel, s.jobModel.transitions, nil)
+	s.controller = scheduler.NewTriggerController(s.triggerModel, s.runModel, s.jobModel, machine, s.creator, scheduler.TriggerConfig{})
+}
+
+// at 返回上海时区的时间
+func (s *TestTriggerControllerSuite) at(day, hour, minute int) time.Time {
+	return time.Date(2026, time.March, day, hour, minute, 0, 0, s.loc)
+}
+
+// newTrigger 每天2:30触发的作业触发器
+func (s *TestTriggerControllerSuite) newTrigger(policy string, nextRunAt time.Time) *model.VtTrainingTriggers {
+	return &model.VtTrainingTriggers{
+		Id:                1,
+		Name:              "nightly",
+		TargetType:        model.TriggerTargetJob,
+		TargetId:          10,
+		Schedule:          "30 2 * * *",
+		Timezone:          "Asia/Shanghai",
+		ConcurrencyPolicy: policy,
+		Enabled:           true,
+		NextRunAt:         &nextRunAt,
+	}
+}
+
+// TestCronScheduleInTimezone 按触发器时区计算触发时间
+func (s *TestTriggerControllerSuite) TestCronScheduleInTimezone() {
+	next, err := scheduler.NextTriggerTime("30 2 * * *", "Asia/Shanghai", time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC))
+	s.Require().NoError(err)
+	s.True(next.Equal(s.at(2, 2, 30)), "UTC 0点已过上海2:30，下一次为次日")
+	s.True(next.Equal(time.Date(2026, time.March, 1, 18, 30, 0, 0, time.UTC)))
+
+	next, err = scheduler.NextTriggerTime("@hourly", "UTC", time.Date(2026, time.March, 1, 10, 0, 0, 0, time.UTC))
+	s.Require().NoError(err)
+	s.True(next.Equal(time.Date(2026, time.March, 1, 11, 0, 0, 0, time.UTC)))
+
+	_, err = scheduler.NextTriggerTime("30 2 * * *", "Mars/Olympus", time.Now())
+	s.Error(err)
+	for _, spec := range []string{"* * *", "61 * * * *", "0 0 * 13 *", "5-1 * * * *", "*/0 * * * *"} {
+		_, err := cron.Parse(spec)
+		s.Error(err, spec)
+	}
+}
+
+// TestCronDayOfMonthOrWeekday 日和星期都有限定时满足任意一个即触发
+func (s *TestTriggerControllerSuite) TestCronDayOfMonthOrWeekday() {
+	schedule, err := cron.Parse("0 9 13 * FRI")
+	s.Require().NoError(err)
+
+	var days []int
+	t := time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		t = schedule.Next(t)
+		days = append(days, t.Day())
+		s.Equal(9, t.Hour())
+	}
+	s.Equal([]int{6, 13, 20, 27}, days, "2月13日既是13号也是周五，只触发一次")
+
+	schedule, err = cron.Parse("0 0 29 2 *")
+	s.Require().NoError(err)
+	s.Equal(2028, schedule.Next(time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)).Year(), "下一个闰年")
+
+	schedule, err = cron.Parse("0 0 30 2 *")
+	s.Require().NoError(err)
+	s.True(schedule.Next(time.Now()).IsZero(), "2月30日永远不会触发")
+}
+
+// TestAllowCreatesJobOncePerSchedule 到期时创建作业并推进触发时间，同一触发时间只处理一次
+func (s *TestTriggerControllerSuite) TestAllowCreatesJobOncePerSchedule() {
+	s.setup(s.newTrigger(model.TriggerConcurrencyAllow, s.at(2, 2, 30)))
+
+	s.Require().NoError(s.controller.ReconcileAt(s.at(2, 2, 30).Add(10 * time.Second)))
+	s.Require().Len(s.creator.specs, 1)
+	s.Equal("nightly-202603020230", s.creator.specs[0]["name"])
+
+	trigger := s.triggerModel.get(1)
+	s.True(trigger.NextRunAt.Equal(s.at(3, 2, 30)))
+	s.Equal(int64(101), trigger.LastJobId)
+	s.Equal(1, trigger.RunCount)
+
+	s.Require().NoError(s.controller.ReconcileAt(s.at(2, 2, 31)))
+	s.Len(s.creator.specs, 1)
+
+	// Allow策略下上一次运行未结束也会创建新作业
+	s.Require().NoError(s.controller.ReconcileAt(s.at(3, 2, 30)))
+	s.Len(s.creator.specs, 2)
+	s.Equal([]string{model.TriggerRunCreated, model.TriggerRunCreated}, s.runModel.statuses())
+}
+
+// TestForbidSkipsWhileRunning Forbid策略在上一次运行未结束时跳过本次触发
+func (s *TestTriggerControllerSuite) TestForbidSkipsWhileRunning() {
+	s.setup(s.newTrigger(model.TriggerConcurrencyForbid, s.at(2, 2, 30)))
+
+	s.Require().NoError(s.controller.ReconcileAt(s.at(2, 2, 30)))
+	s.Require().NoError(s.controller.ReconcileAt(s.at(3, 2, 30)))
+	s.Len(s.creator.specs, 1)
+	s.Equal([]string{model.TriggerRunCreated, model.TriggerRunSkipped}, s.runModel.statuses())
+
+	s.jobModel.mu.Lock()
+	s.jobModel.jobs[101].Status = "succeeded"
+	s.jobModel.mu.Unlock()
+
+	s.Require().NoError(s.controller.ReconcileAt(s.at(4, 2, 30)))
+	s.Len(s.creator.specs, 2)
+	s.Equal(model.TriggerRunCreated, s.runModel.statuses()[2])
+}
+
+// TestReplaceCancelsRunningJob Replace策略取消未结束的运行后创建新作业
+func (s *TestTriggerControllerSuite) TestReplaceCancelsRunningJob() {
+	s.setup(s.newTrigger(model.TriggerConcurrencyReplace, s.at(2, 2, 30)))
+
+	s.Require().NoError(s.controller.ReconcileAt(s.at(2, 2, 30)))
+	s.Require().NoError(s.controller.ReconcileAt(s.at(3, 2, 30)))
+	s.Len(s.creator.specs, 2)
+
+	old, err := s.jobModel.FindOneDetail(101)
+	s.Require().NoError(err)
+	s.Equal("cancelled", old.Status)
+
+	s.Require().Len(s.runModel.runs, 2)
+	s.Equal(int64(102), s.runModel.runs[1].JobId)
+	s.JSONEq(`[101]`, s.runModel.runs[1].ReplacedJobIds)
+}
+
+// TestMissedRunsAfterDowntime 停机期间错过的触发只补触发最近一次，超过补触发期限时全部记为错过
+func (s *TestTriggerControllerSuite) TestMissedRunsAfterDowntime() {
+	s.setup(s.newTrigger(model.TriggerConcurrencyAllow, s.at(2, 2, 30)))
+
+	s.Require().NoError(s.controller.ReconcileAt(s.at(5, 9, 0)))
+	s.Require().Len(s.creator.specs, 1)
+	s.Equal("nightly-202603050230", s.creator.specs[0]["name"])
+	s.Equal([]string{model.TriggerRunMissed, model.TriggerRunMissed, model.TriggerRunMissed, model.TriggerRunCreated}, s.runModel.statuses())
+	s.True(s.triggerModel.get(1).NextRunAt.Equal(s.at(6, 2, 30)))
+
+	trigger := s.newTrigger(model.TriggerConcurrencyAllow, s.at(2, 2, 30))
+	trigger.StartingDeadlineSeconds = 3600
+	s.setup(trigger)
+
+	s.Require().NoError(s.controller.ReconcileAt(s.at(3, 9, 0)))
+	s.Empty(s.creator.specs)
+	s.Equal([]string{model.TriggerRunMissed, model.TriggerRunMissed}, s.runModel.statuses())
+	s.True(s.triggerModel.get(1).NextRunAt.Equal(s.at(4, 2, 30)))
+}
+
+func TestRunTriggerControllerTests(t *testing.T) {
+	suite.Run(t, new(TestTriggerControllerSuite))
+}